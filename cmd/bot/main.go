@@ -10,27 +10,10 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/Shikachuu/wap-bot/internal/app"
 	"github.com/Shikachuu/wap-bot/internal/config"
-	"github.com/Shikachuu/wap-bot/internal/domain"
-	"github.com/Shikachuu/wap-bot/internal/services"
-	"github.com/Shikachuu/wap-bot/internal/telemetry"
-	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
-	"github.com/slack-go/slack"
-	"github.com/slack-go/slack/socketmode"
 )
 
-var urlProcessors = map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
-	musicextractors.SpotifyProvider:       musicextractors.SpotifyURLExtractor,
-	musicextractors.YouTubeProvider:       musicextractors.YouTubeURLExtractor,
-	musicextractors.YoutTubeMusicProvider: musicextractors.YouTubeMusicURLExtractor,
-}
-
-var titleExtractors = map[musicextractors.ExtractProvider]musicextractors.TitleExtractorFunc{
-	musicextractors.SpotifyProvider:       musicextractors.SpotifyTitleExtractor,
-	musicextractors.YouTubeProvider:       musicextractors.YouTubeTitleExtractor,
-	musicextractors.YoutTubeMusicProvider: musicextractors.YouTubeTitleExtractor,
-}
-
 func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	if err := run(ctx, cancel); err != nil {
@@ -42,46 +25,20 @@ func main() {
 func run(ctx context.Context, cancel context.CancelFunc) error {
 	defer cancel()
 
-	inDebug := config.InDebugMode()
-
-	telemetry.SetupLogger(inDebug)
-
-	tShutdown, err := telemetry.SetupOTel(ctx)
-	if err != nil {
-		return fmt.Errorf("setting up otel: %w", err)
-	}
-
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
 
-	botToken, appToken, err := config.GetConfig()
+	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("parsing config: %w", err)
 	}
 
-	api := slack.New(
-		botToken,
-		slack.OptionAppLevelToken(appToken),
-		slack.OptionDebug(inDebug),
-	)
-
-	client := socketmode.New(api)
-
-	smp := domain.NewSlackMessageProcessor(urlProcessors, titleExtractors)
-
-	sb := services.NewSlackBot(smp, client)
-
-	slog.InfoContext(ctx, "starting event handler...")
-
-	go sb.HandleEvents(ctx)
-
-	go func() {
-		slog.Info("starting slack socket connection...")
+	a, err := app.Build(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("building app: %w", err)
+	}
 
-		if rErr := client.RunContext(ctx); rErr != nil {
-			slog.Error("slack client error", "error", rErr)
-		}
-	}()
+	a.Start(ctx)
 
 	// Wait for shutdown signal
 	<-sigCh
@@ -92,8 +49,8 @@ func run(ctx context.Context, cancel context.CancelFunc) error {
 	defer shutdownCancel()
 
 	//nolint:contextcheck // we cannot inherit the context here, it canceled above
-	if sErr := tShutdown(shutdownCtx); sErr != nil {
-		return fmt.Errorf("shutdown otel: %w", sErr)
+	if sErr := a.Shutdown(shutdownCtx); sErr != nil {
+		return fmt.Errorf("shutdown app: %w", sErr)
 	}
 
 	slog.InfoContext(ctx, "shutdown complete")
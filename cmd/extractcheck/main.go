@@ -0,0 +1,230 @@
+/*
+Package main is the extractcheck tool, it prints what every registered provider extracts from sample messages,
+so regex and extractor changes can be validated against real-world samples before a release.
+
+Usage:
+
+	extractcheck [-titles=false] [file|url ...]
+
+Every argument is either a file with one sample message per line or a single URL,
+without arguments the samples are read from the standard input.
+The providers are configured from the same environment variables as the bot, without the Slack credentials.
+*/
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/Shikachuu/wap-bot/internal/app"
+	"github.com/Shikachuu/wap-bot/internal/config"
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+)
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	if err := run(ctx, cancel, os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		slog.Error("failed to check extractors", "error", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, cancel context.CancelFunc, args []string, stdin io.Reader, stdout io.Writer) error {
+	defer cancel()
+
+	flags := flag.NewFlagSet("extractcheck", flag.ContinueOnError)
+	titles := flags.Bool("titles", true, "look up the title of every extracted URL, disable to only check the regexes offline")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+
+	cfg, err := config.LoadSettings()
+	if err != nil {
+		return fmt.Errorf("parsing config: %w", err)
+	}
+
+	urlExtractors, metadataExtractors, err := app.Extractors(cfg)
+	if err != nil {
+		return fmt.Errorf("building extractors: %w", err)
+	}
+
+	if !*titles {
+		metadataExtractors = nil
+	}
+
+	samples, err := readSamples(flags.Args(), stdin)
+	if err != nil {
+		return err
+	}
+
+	c := checker{urlExtractors: urlExtractors, metadataExtractors: metadataExtractors}
+
+	var rows []row
+
+	for i, sample := range samples {
+		rows = append(rows, c.check(ctx, i+1, sample)...)
+	}
+
+	return writeTable(stdout, rows)
+}
+
+// readSamples reads the sample messages of every argument, a file with one sample per line or a URL,
+// and the standard input if there are no arguments. Blank lines are skipped.
+func readSamples(args []string, stdin io.Reader) ([]string, error) {
+	if len(args) == 0 {
+		return readLines(stdin)
+	}
+
+	var samples []string
+
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "http://") || strings.HasPrefix(arg, "https://") {
+			samples = append(samples, arg)
+			continue
+		}
+
+		lines, err := readFile(arg)
+		if err != nil {
+			return nil, err
+		}
+
+		samples = append(samples, lines...)
+	}
+
+	return samples, nil
+}
+
+// readFile reads the samples of a file, one per line.
+func readFile(path string) ([]string, error) {
+	f, err := os.Open(path) //nolint:gosec // reading the files given on the command line is the point of the tool
+	if err != nil {
+		return nil, fmt.Errorf("opening samples: %w", err)
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	return readLines(f)
+}
+
+// readLines returns the non-blank lines of r.
+func readLines(r io.Reader) ([]string, error) {
+	var lines []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading samples: %w", err)
+	}
+
+	return lines, nil
+}
+
+// row is a line of the output table, the outcome of a provider on a sample.
+type row struct {
+	provider   string
+	url        string
+	normalized string
+	title      string
+	err        string
+	sample     int
+}
+
+// checker runs every registered provider on the samples.
+type checker struct {
+	urlExtractors map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc
+	// metadataExtractors is nil when the titles are not looked up.
+	metadataExtractors map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc
+}
+
+// check runs the URL extractor of every provider on sample, the same way the bot does it with Slack messages.
+//
+// Returns a row for every provider that found a URL or failed with something else than no URL,
+// or a single row without a provider if none of them matched.
+func (c checker) check(ctx context.Context, index int, sample string) []row {
+	text := musicextractors.UnwrapSlackLinks(sample)
+
+	var rows []row
+
+	for _, p := range slices.Sorted(maps.Keys(c.urlExtractors)) {
+		url, _, err := c.urlExtractors[p](text)
+		if errors.Is(err, musicextractors.ErrNoURLFound) {
+			continue
+		}
+
+		r := row{sample: index, provider: string(p), url: url}
+
+		if err != nil {
+			r.err = err.Error()
+			rows = append(rows, r)
+
+			continue
+		}
+
+		normalized, nErr := musicextractors.NormalizeURL(url)
+		if nErr != nil {
+			r.err = nErr.Error()
+		}
+
+		r.normalized = normalized
+
+		if extract, ok := c.metadataExtractors[p]; ok && r.err == "" {
+			m, mErr := extract(ctx, url)
+			if mErr != nil {
+				r.err = mErr.Error()
+			}
+
+			r.title = m.DisplayTitle()
+		}
+
+		rows = append(rows, r)
+	}
+
+	if len(rows) == 0 {
+		return []row{{sample: index, provider: "-", err: musicextractors.ErrNoURLFound.Error()}}
+	}
+
+	return rows
+}
+
+// writeTable prints the rows as an aligned table.
+func writeTable(w io.Writer, rows []row) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	lines := [][]string{{"SAMPLE", "PROVIDER", "URL", "NORMALIZED", "TITLE", "ERROR"}}
+	for _, r := range rows {
+		lines = append(lines, []string{strconv.Itoa(r.sample), r.provider, r.url, r.normalized, r.title, r.err})
+	}
+
+	for _, l := range lines {
+		if _, err := fmt.Fprintln(tw, strings.Join(l, "\t")); err != nil {
+			return fmt.Errorf("writing table: %w", err)
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("writing table: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecker_Check(t *testing.T) {
+	t.Parallel()
+
+	c := checker{
+		urlExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+			musicextractors.YouTubeProvider: musicextractors.YouTubeURLExtractor,
+		},
+		metadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: func(context.Context, string) (musicextractors.TrackMetadata, error) {
+				return musicextractors.TrackMetadata{Title: "Never Gonna Give You Up", Artist: "Rick Astley"}, nil
+			},
+			musicextractors.YouTubeProvider: func(context.Context, string) (musicextractors.TrackMetadata, error) {
+				return musicextractors.TrackMetadata{}, musicextractors.ErrNoTitleFound
+			},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		sample string
+		want   []row
+	}{
+		{
+			name:   "slack formatted link",
+			sample: "<https://open.spotify.com/intl-de/track/4cOdK2wGLETKBW3PvgPWqT?si=abc|spotify>",
+			want: []row{{
+				sample:     1,
+				provider:   "spotify",
+				url:        "https://open.spotify.com/intl-de/track/4cOdK2wGLETKBW3PvgPWqT?si=abc",
+				normalized: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT",
+				title:      "Rick Astley - Never Gonna Give You Up",
+			}},
+		},
+		{
+			name:   "title lookup failure",
+			sample: "https://youtu.be/dQw4w9WgXcQ",
+			want: []row{{
+				sample:     1,
+				provider:   "youtube",
+				url:        "https://youtu.be/dQw4w9WgXcQ",
+				normalized: "https://youtu.be/dQw4w9WgXcQ",
+				err:        musicextractors.ErrNoTitleFound.Error(),
+			}},
+		},
+		{
+			name:   "several links of a provider",
+			sample: "https://youtu.be/dQw4w9WgXcQ https://youtu.be/yPYZpwSpKmA",
+			want:   []row{{sample: 1, provider: "youtube", err: musicextractors.ErrMultipleResult.Error()}},
+		},
+		{
+			name:   "no link",
+			sample: "what a tune",
+			want:   []row{{sample: 1, provider: "-", err: musicextractors.ErrNoURLFound.Error()}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, c.check(t.Context(), 1, tt.sample))
+		})
+	}
+}
+
+func TestRun_OfflineTable(t *testing.T) {
+	t.Setenv("ENABLED_PROVIDERS", "spotify,shazam")
+
+	samples := filepath.Join(t.TempDir(), "samples.txt")
+	require.NoError(t, os.WriteFile(samples, []byte("listen https://www.shazam.com/track/5933917/never-gonna-give-you-up\n\nno link here\n"), 0o600))
+
+	var out bytes.Buffer
+
+	ctx, cancel := context.WithCancel(t.Context())
+	require.NoError(t, run(ctx, cancel, []string{"-titles=false", samples, "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}, strings.NewReader(""), &out))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, []string{"SAMPLE", "PROVIDER", "URL", "NORMALIZED", "TITLE", "ERROR"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"1", "shazam", "https://www.shazam.com/track/5933917/never-gonna-give-you-up",
+		"https://www.shazam.com/track/5933917/never-gonna-give-you-up"}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{"2", "-", "no", "URL", "found", "in", "text"}, strings.Fields(lines[2]))
+	assert.Equal(t, []string{"3", "spotify", "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT",
+		"https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}, strings.Fields(lines[3]))
+}
@@ -0,0 +1,293 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/internal/config"
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/Shikachuu/wap-bot/internal/faults"
+	"github.com/Shikachuu/wap-bot/internal/services"
+	"github.com/Shikachuu/wap-bot/internal/storage"
+	"github.com/Shikachuu/wap-bot/internal/telemetry"
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/socketmode"
+)
+
+// App is the fully wired bot, ready to be started.
+type App struct {
+	bot               *services.SlackBot
+	socketClient      *socketmode.Client
+	telemetryShutdown func(context.Context) error
+	// scheduler is nil when no channel has scheduled threads.
+	scheduler *services.ThreadScheduler
+	// probeInterval is how often the providers are probed in the background, zero disables it.
+	probeInterval time.Duration
+	// eventsDone is closed once the event handler and every handler it started returned,
+	// it is nil until Start is called.
+	eventsDone chan struct{}
+}
+
+// usageFlushInterval is how often the usage statistics are written to the state file,
+// the rest is written on shutdown.
+const usageFlushInterval = time.Minute
+
+// Build assembles every component of the application from the given config.
+//
+// ctx is only used to set up the telemetry providers, it does not control the lifetime of the App.
+//
+// Returns the wired App or an error if any of the components failed to initialize.
+func Build(ctx context.Context, cfg config.Config) (*App, error) {
+	telemetry.SetupLogger(cfg.Debug)
+
+	tShutdown, err := telemetry.SetupOTel(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("setting up otel: %w", err)
+	}
+
+	metrics, err := telemetry.NewMetrics()
+	if err != nil {
+		return nil, fmt.Errorf("creating metrics: %w", err)
+	}
+
+	api := slack.New(
+		cfg.BotToken,
+		slack.OptionAppLevelToken(cfg.AppToken),
+		slack.OptionDebug(cfg.Debug),
+		slack.OptionHTTPClient(&http.Client{Transport: faults.SlackTransport(http.DefaultTransport)}),
+	)
+
+	client := socketmode.New(api, socketmode.OptionDialer(faults.SocketDialer()))
+
+	urlExtractors, titleExtractors, err := Extractors(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	dedupe, channelDedupe, err := dedupeStrategies(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("dedupe setup: %w", err)
+	}
+
+	csv, err := csvOptions(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("SUMMARY_CSV_DELIMITER: %w", err)
+	}
+
+	templates, err := domain.ParseSummaryTemplates(cfg.SummaryCommentTemplate, cfg.SummaryFileNameTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("summary templates: %w", err)
+	}
+
+	health := providerHealth(cfg)
+
+	for p, fn := range titleExtractors {
+		titleExtractors[p] = musicextractors.WrapTitleExtractor(p, fn, withHealth(health))
+	}
+
+	instrument(metrics, titleExtractors)
+
+	smp := domain.NewSlackMessageProcessor(domain.ProcessorConfig{
+		URLExtractors:         urlExtractors,
+		MetadataExtractors:    titleExtractors,
+		Priority:              providerPriority(cfg),
+		ChannelDisabled:       channelDisabledProviders(cfg),
+		CrossLinker:           crossLinker(cfg),
+		Enricher:              enricher(cfg),
+		TextSummarizer:        textSummarizer(cfg),
+		ShortURLResolver:      shortURLResolver(cfg),
+		PlaylistExpander:      playlistExpander(cfg),
+		Dedupe:                dedupe,
+		ChannelDedupe:         channelDedupe,
+		DedupeAcrossProviders: cfg.DedupeAcrossProviders,
+		Format:                domain.ExportFormat(cfg.SummaryFormat),
+		Artwork:               cfg.SummaryArtwork,
+		TimesShared:           cfg.SummaryTimesShared,
+		Genre:                 cfg.SummaryGenre,
+		CSV:                   csv,
+		Stats:                 cfg.SummaryStats,
+		SkippedReport:         cfg.SummarySkippedReport,
+		Templates:             templates,
+		Compression: domain.Compression{
+			Kind:           domain.CompressionKind(cfg.ExportCompression),
+			ThresholdBytes: cfg.ExportCompressionThreshold,
+		},
+		Concurrency:        cfg.ExtractionConcurrency,
+		PoolObserver:       newPoolMetrics(ctx, metrics, cfg.ExtractionConcurrency),
+		ExtractionObserver: extractionMetrics{metrics: metrics},
+		SpillThreshold:     cfg.ExtractionSpillThreshold,
+		FileSpillBytes:     cfg.SummarySpillBytes,
+		// The anonymous summaries never look up the users who shared the tracks
+		UserResolver: services.NewUserNames(api),
+	})
+
+	store, err := storage.NewFileStore(cfg.StorageFile)
+	if err != nil {
+		return nil, fmt.Errorf("storage setup: %w", err)
+	}
+
+	logStateCompatibility(ctx, store.Compatibility())
+
+	var archive storage.ArchiveSink
+
+	if cfg.ArchiveDir != "" {
+		if archive, err = storage.NewDirArchive(cfg.ArchiveDir); err != nil {
+			return nil, fmt.Errorf("archive setup: %w", err)
+		}
+	}
+
+	bot := services.NewSlackBot(services.BotConfig{
+		Processor:           smp,
+		Client:              client,
+		Prober:              providerProbes(cfg),
+		Health:              health,
+		Metrics:             metrics,
+		Store:               store,
+		Archive:             archive,
+		Playlists:           playlistCreators(cfg),
+		Admins:              cfg.AdminUsers,
+		SilentChannels:      cfg.SilentChannels,
+		Incremental:         cfg.SummaryIncremental,
+		ReplacePrevious:     cfg.SummaryReplacePrevious,
+		ProgressThreshold:   cfg.SummaryProgressThreshold,
+		UserSummaryLimit:    cfg.SummaryUserLimit,
+		ChannelSummaryLimit: cfg.SummaryChannelLimit,
+		EventConcurrency:    cfg.EventConcurrency,
+	})
+
+	recordSummaryLimits(ctx, metrics, cfg.SummaryUserLimit, cfg.SummaryChannelLimit)
+
+	var scheduler *services.ThreadScheduler
+
+	if len(cfg.ScheduledThreadChannels) > 0 {
+		scheduler, err = services.NewThreadScheduler(bot, services.ThreadSchedule{
+			Prompt:    cfg.ScheduledThreadPrompt,
+			Channels:  cfg.ScheduledThreadChannels,
+			Weekday:   cfg.ScheduledThreadWeekday,
+			Hour:      cfg.ScheduledThreadHour,
+			Usergroup: cfg.ScheduledThreadUsergroup,
+			Silent:    cfg.ScheduledThreadSilent,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("scheduler setup: %w", err)
+		}
+	}
+
+	return &App{
+		bot:               bot,
+		scheduler:         scheduler,
+		socketClient:      client,
+		probeInterval:     cfg.ProviderProbeInterval,
+		telemetryShutdown: tShutdown,
+	}, nil
+}
+
+// Start launches the event handler and the Slack socket connection in the background.
+//
+// Both of them are stopped when ctx gets canceled.
+func (a *App) Start(ctx context.Context) {
+	slog.InfoContext(ctx, "starting event handler...")
+
+	a.eventsDone = make(chan struct{})
+
+	go func() {
+		defer close(a.eventsDone)
+
+		a.bot.HandleEvents(ctx)
+	}()
+	go a.bot.FlushUsage(ctx, usageFlushInterval)
+
+	if a.scheduler != nil {
+		slog.InfoContext(ctx, "starting thread scheduler...")
+
+		go a.scheduler.Run(ctx)
+	}
+
+	if a.probeInterval > 0 {
+		slog.InfoContext(ctx, "starting provider monitor...", "interval", a.probeInterval)
+
+		go a.bot.MonitorProviders(ctx, a.probeInterval)
+	}
+
+	go func() {
+		slog.InfoContext(ctx, "starting slack socket connection...")
+
+		if rErr := a.socketClient.RunContext(ctx); rErr != nil {
+			slog.ErrorContext(ctx, "slack client error", "error", rErr)
+		}
+	}()
+}
+
+// Shutdown flushes and stops every component that needs a graceful shutdown.
+func (a *App) Shutdown(ctx context.Context) error {
+	// The telemetry is still flushed when the usage can't be written
+	var errs []error
+
+	// The handlers still running record their usage, so they have to finish before the last save
+	if err := a.waitForEvents(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := a.bot.SaveUsage(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("shutdown bot: %w", err))
+	}
+
+	if err := a.telemetryShutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("shutdown otel: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// waitForEvents blocks until the event handler started by Start returned or ctx is done.
+func (a *App) waitForEvents(ctx context.Context) error {
+	if a.eventsDone == nil {
+		return nil
+	}
+
+	select {
+	case <-a.eventsDone:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("wait for event handlers: %w", ctx.Err())
+	}
+}
+
+// logStateCompatibility reports the outcome of the compatibility check of the state file,
+// a state file the bot can't fully read is logged as a warning since it means lost state until the next upgrade.
+func logStateCompatibility(ctx context.Context, c storage.Compatibility) {
+	switch {
+	case c.QuarantineFile != "":
+		slog.WarnContext(ctx, "state file written by a newer release, starting with an empty state",
+			"file_version", c.FileVersion, "quarantine_file", c.QuarantineFile)
+	case c.Quarantined > 0:
+		slog.WarnContext(ctx, "quarantined unreadable state records", "count", c.Quarantined)
+	case c.Migrated:
+		slog.InfoContext(ctx, "migrated state file", "from_version", c.FileVersion)
+	}
+}
+
+// csvOptions returns the configured options of the CSV summaries.
+//
+// Returns domain.ErrInvalidCSVDelimiter if the delimiter can't separate CSV columns.
+func csvOptions(cfg config.Config) (domain.CSVOptions, error) {
+	opts := domain.CSVOptions{BOM: cfg.SummaryCSVBOM, CRLF: cfg.SummaryCSVCRLF}
+
+	if cfg.SummaryCSVDelimiter == "" {
+		return opts, nil
+	}
+
+	delimiter, err := domain.ParseCSVDelimiter(cfg.SummaryCSVDelimiter)
+	if err != nil {
+		return domain.CSVOptions{}, err //nolint:wrapcheck // wrapped with the variable name by the caller
+	}
+
+	opts.Delimiter = delimiter
+
+	return opts, nil
+}
@@ -0,0 +1,252 @@
+package app
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/internal/config"
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/Shikachuu/wap-bot/internal/telemetry"
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuild_WiresEveryComponent(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "none")
+	t.Setenv("OTEL_METRICS_EXPORTER", "none")
+
+	a, err := Build(t.Context(), config.Config{
+		BotToken: "xoxb-test",
+		AppToken: "xapp-test",
+	})
+	require.NoError(t, err)
+
+	assert.NotNil(t, a.bot)
+	assert.NotNil(t, a.socketClient)
+	require.NoError(t, a.Shutdown(t.Context()))
+}
+
+func TestBuild_ScheduledThreads(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "none")
+	t.Setenv("OTEL_METRICS_EXPORTER", "none")
+
+	a, err := Build(t.Context(), config.Config{
+		BotToken:                "xoxb-test",
+		AppToken:                "xapp-test",
+		ScheduledThreadChannels: []string{"C123"},
+		ScheduledThreadPrompt:   config.DefaultScheduledThreadPrompt,
+	})
+	require.NoError(t, err)
+	assert.NotNil(t, a.scheduler)
+
+	require.NoError(t, a.Shutdown(t.Context()))
+}
+
+func TestShutdown_WaitsForEventHandlers(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "none")
+	t.Setenv("OTEL_METRICS_EXPORTER", "none")
+
+	a, err := Build(t.Context(), config.Config{
+		BotToken: "xoxb-test",
+		AppToken: "xapp-test",
+	})
+	require.NoError(t, err)
+
+	a.eventsDone = make(chan struct{})
+
+	var finished atomic.Bool
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		finished.Store(true)
+		close(a.eventsDone)
+	}()
+
+	require.NoError(t, a.Shutdown(t.Context()))
+	assert.True(t, finished.Load())
+}
+
+func TestShutdown_EventHandlersTimeout(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "none")
+	t.Setenv("OTEL_METRICS_EXPORTER", "none")
+
+	a, err := Build(t.Context(), config.Config{
+		BotToken: "xoxb-test",
+		AppToken: "xapp-test",
+	})
+	require.NoError(t, err)
+
+	// The handlers never return, the usage and the telemetry are still flushed
+	a.eventsDone = make(chan struct{})
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, a.Shutdown(ctx), context.DeadlineExceeded)
+}
+
+func TestBuild_InvalidCSVDelimiter(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "none")
+	t.Setenv("OTEL_METRICS_EXPORTER", "none")
+
+	_, err := Build(t.Context(), config.Config{
+		BotToken:            "xoxb-test",
+		AppToken:            "xapp-test",
+		SummaryCSVDelimiter: "\"",
+	})
+	require.ErrorIs(t, err, domain.ErrInvalidCSVDelimiter)
+}
+
+func TestPlaylistCreators_SpotifyFirst(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, playlistCreators(config.Config{SpotifyClientID: "id", SpotifyClientSecret: "secret"}))
+
+	creators := playlistCreators(config.Config{
+		SpotifyClientID:     "id",
+		SpotifyClientSecret: "secret",
+		SpotifyRefreshToken: "refresh",
+		YouTubeClientID:     "id",
+		YouTubeClientSecret: "secret",
+		YouTubeRefreshToken: "refresh",
+	})
+	require.Len(t, creators, 2)
+	assert.Equal(t, musicextractors.SpotifyProvider, creators[0].Provider())
+	assert.Equal(t, musicextractors.YouTubeProvider, creators[1].Provider())
+}
+
+func TestCustomProviders_Valid(t *testing.T) {
+	t.Parallel()
+
+	providers, err := customProviders(config.Config{CustomProviders: []config.CustomProvider{
+		{Name: "jellyfin", Pattern: `https://media\.internal/items/\w+`, Title: "none"},
+	}})
+	require.NoError(t, err)
+	require.Len(t, providers, 1)
+
+	custom := []musicextractors.RegexProvider{providers[0]}
+	assert.Contains(t, urlProcessors(config.Config{}, custom), musicextractors.ExtractProvider("jellyfin"))
+	assert.Contains(t, metadataExtractors(config.Config{}, custom), musicextractors.ExtractProvider("jellyfin"))
+}
+
+func TestExtractors_EnabledAndCustomProviders(t *testing.T) {
+	t.Parallel()
+
+	urls, titles, err := Extractors(config.Config{
+		EnabledProviders: []string{"spotify"},
+		CustomProviders:  []config.CustomProvider{{Name: "jellyfin", Pattern: `https://media\.internal/items/\w+`, Title: "none"}},
+	})
+	require.NoError(t, err)
+
+	want := []musicextractors.ExtractProvider{musicextractors.SpotifyProvider, "jellyfin"}
+	assert.ElementsMatch(t, want, slices.Collect(maps.Keys(urls)))
+	assert.ElementsMatch(t, want, slices.Collect(maps.Keys(titles)))
+}
+
+func TestURLProcessors_EveryBuiltInProvider(t *testing.T) {
+	t.Parallel()
+
+	// The config validates the provider names against musicextractors.Providers
+	got := slices.Collect(maps.Keys(urlProcessors(config.Config{}, nil)))
+	assert.ElementsMatch(t, musicextractors.Providers(), got)
+}
+
+func TestCustomProviders_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		provider config.CustomProvider
+	}{
+		{name: "shadows built-in", provider: config.CustomProvider{Name: "spotify", Pattern: `https://x\.com/\w+`}},
+		{name: "invalid pattern", provider: config.CustomProvider{Name: "jellyfin", Pattern: `(`}},
+		{name: "unknown title strategy", provider: config.CustomProvider{Name: "jellyfin", Pattern: `https://x\.com/\w+`, Title: "scrape"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := customProviders(config.Config{CustomProviders: []config.CustomProvider{tt.provider}})
+			require.ErrorIs(t, err, musicextractors.ErrInvalidProvider)
+		})
+	}
+}
+
+func TestInstrument_PassesResultsThrough(t *testing.T) {
+	t.Parallel()
+
+	metrics, err := telemetry.NewMetrics()
+	require.NoError(t, err)
+
+	titles := map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+		musicextractors.SpotifyProvider: func(context.Context, string) (musicextractors.TrackMetadata, error) {
+			return musicextractors.TrackMetadata{}, musicextractors.ErrRequestFailed
+		},
+	}
+
+	instrument(metrics, titles)
+
+	_, err = titles[musicextractors.SpotifyProvider](t.Context(), "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT")
+	require.ErrorIs(t, err, musicextractors.ErrRequestFailed)
+}
+
+func TestExtractionMetrics_URLMatched(t *testing.T) {
+	t.Parallel()
+
+	metrics, err := telemetry.NewMetrics()
+	require.NoError(t, err)
+
+	smp := domain.NewSlackMessageProcessor(domain.ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+		},
+		ExtractionObserver: extractionMetrics{metrics: metrics},
+	})
+
+	assert.True(t, smp.ContainsMusicURL(t.Context(), "C1", "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"))
+	assert.False(t, smp.ContainsMusicURL(t.Context(), "C1", "https://example.com"))
+}
+
+func TestExtractionOutcome(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{err: nil, want: "ok"},
+		{err: musicextractors.ErrNoURLFound, want: "no_url"},
+		{err: musicextractors.ErrCircuitOpen, want: "unavailable"},
+		{err: musicextractors.ErrRateLimited, want: "rate_limited"},
+		{err: musicextractors.ErrNoTitleFound, want: "title_not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, extractionOutcome(tt.err))
+		})
+	}
+}
+
+func TestDedupeStrategies(t *testing.T) {
+	t.Parallel()
+
+	def, channels, err := dedupeStrategies(config.Config{
+		DedupeStrategy:          "url",
+		ChannelDedupeStrategies: map[string]string{"C123": "none"},
+	})
+	require.NoError(t, err)
+	assert.NotNil(t, def)
+	assert.Contains(t, channels, "C123")
+
+	_, _, err = dedupeStrategies(config.Config{ChannelDedupeStrategies: map[string]string{"C123": "fuzzy"}})
+	require.ErrorIs(t, err, domain.ErrUnsupportedDedupeStrategy)
+}
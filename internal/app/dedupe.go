@@ -0,0 +1,35 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/Shikachuu/wap-bot/internal/config"
+	"github.com/Shikachuu/wap-bot/internal/domain"
+)
+
+// dedupeStrategies returns the default dedupe strategy and the per-channel overrides,
+// the default is nil when none is configured, leaving the choice to the processor.
+//
+// Returns domain.ErrUnsupportedDedupeStrategy if any of the names is unknown.
+func dedupeStrategies(cfg config.Config) (domain.DedupeStrategy, map[string]domain.DedupeStrategy, error) {
+	var (
+		def domain.DedupeStrategy
+		err error
+	)
+
+	if cfg.DedupeStrategy != "" {
+		if def, err = domain.NewDedupeStrategy(domain.DedupeStrategyName(cfg.DedupeStrategy)); err != nil {
+			return nil, nil, fmt.Errorf("default: %w", err)
+		}
+	}
+
+	channels := make(map[string]domain.DedupeStrategy, len(cfg.ChannelDedupeStrategies))
+
+	for channelID, name := range cfg.ChannelDedupeStrategies {
+		if channels[channelID], err = domain.NewDedupeStrategy(domain.DedupeStrategyName(name)); err != nil {
+			return nil, nil, fmt.Errorf("channel %s: %w", channelID, err)
+		}
+	}
+
+	return def, channels, nil
+}
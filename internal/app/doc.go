@@ -0,0 +1,8 @@
+/*
+Package app is the composition root of the bot.
+
+It wires the configuration, telemetry, providers, domain and service layers together,
+so the entrypoint only has to load the config and run the returned App, while
+integration tests can construct the full application in-process.
+*/
+package app
@@ -0,0 +1,36 @@
+//go:build faultinject
+
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/internal/config"
+	"github.com/Shikachuu/wap-bot/internal/faults"
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadataExtractors_ProviderTimeoutsOpenBreaker(t *testing.T) {
+	t.Cleanup(faults.Reset)
+
+	extract := metadataExtractors(config.Config{
+		EnabledProviders: []string{string(musicextractors.SpotifyProvider)},
+		ProviderTimeout:  10 * time.Millisecond,
+		BreakerThreshold: 2,
+		BreakerCooldown:  time.Minute,
+	}, nil)[musicextractors.SpotifyProvider]
+
+	faults.Arm(faults.ProviderTimeout, 0)
+
+	const url = "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"
+
+	for range 2 {
+		_, err := extract(t.Context(), url)
+		require.ErrorIs(t, err, musicextractors.ErrRequestFailed)
+	}
+
+	_, err := extract(t.Context(), url)
+	require.ErrorIs(t, err, musicextractors.ErrCircuitOpen)
+}
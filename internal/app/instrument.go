@@ -0,0 +1,143 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/Shikachuu/wap-bot/internal/telemetry"
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// extractionOutcome classifies the result of an extraction for the metrics, "ok" or the kind of the failure.
+func extractionOutcome(err error) string {
+	var extErr *musicextractors.ExtractionError
+	if !errors.As(musicextractors.NewExtractionError("", "", err), &extErr) {
+		return "ok"
+	}
+
+	return string(extErr.Kind)
+}
+
+// recordExtraction counts an extraction of provider and records its duration.
+func recordExtraction(
+	ctx context.Context,
+	metrics *telemetry.Metrics,
+	provider musicextractors.ExtractProvider,
+	stage string,
+	elapsed time.Duration,
+	err error,
+) {
+	attrs := metric.WithAttributes(
+		attribute.String("provider", string(provider)),
+		attribute.String("stage", stage),
+		attribute.String("outcome", extractionOutcome(err)),
+	)
+
+	metrics.Extractions.Add(ctx, 1, attrs)
+	metrics.ExtractionDuration.Record(ctx, elapsed.Seconds(), attrs)
+}
+
+// instrumentTitleExtractor wraps the title lookup of every provider in a span, counts them and records their duration.
+func instrumentTitleExtractor(metrics *telemetry.Metrics) musicextractors.TitleExtractorMiddleware {
+	return func(provider musicextractors.ExtractProvider, next musicextractors.MetadataExtractorFunc) musicextractors.MetadataExtractorFunc {
+		return func(bCtx context.Context, url string) (musicextractors.TrackMetadata, error) {
+			ctx, t := telemetry.Tracer.Start(bCtx, "musicextractors.extract_title")
+			defer t.End()
+
+			t.SetAttributes(attribute.String("provider", string(provider)), attribute.String("url", url))
+
+			start := time.Now()
+
+			m, err := next(ctx, url)
+			recordExtraction(ctx, metrics, provider, "title", time.Since(start), err)
+
+			if err != nil {
+				_ = telemetry.WrapErrorWithTrace(t, "", err)
+			}
+
+			return m, err
+		}
+	}
+}
+
+// instrument decorates the title lookups of every provider with the metrics and spans,
+// the URL matches are recorded by extractionMetrics.
+func instrument(metrics *telemetry.Metrics, titleExtractors map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc) {
+	for p, fn := range titleExtractors {
+		titleExtractors[p] = musicextractors.WrapTitleExtractor(p, fn, instrumentTitleExtractor(metrics))
+	}
+}
+
+// extractionMetrics counts the URL matches of every provider and records their duration.
+type extractionMetrics struct {
+	metrics *telemetry.Metrics
+}
+
+var _ domain.ExtractionObserver = extractionMetrics{}
+
+func (e extractionMetrics) URLMatched(ctx context.Context, provider musicextractors.ExtractProvider, elapsed time.Duration, err error) {
+	recordExtraction(ctx, e.metrics, provider, "url", elapsed, err)
+}
+
+// poolMetrics records the extraction worker pool as metrics.
+type poolMetrics struct {
+	metrics *telemetry.Metrics
+}
+
+var _ domain.PoolObserver = poolMetrics{}
+
+// newPoolMetrics returns the pool observer of the processor, recording the configured pool size right away.
+func newPoolMetrics(ctx context.Context, metrics *telemetry.Metrics, limit int) poolMetrics {
+	metrics.ExtractionConcurrencyLimit.Record(ctx, int64(limit))
+
+	return poolMetrics{metrics: metrics}
+}
+
+func (p poolMetrics) WorkersChanged(ctx context.Context, delta int) {
+	p.metrics.ExtractionWorkers.Add(ctx, int64(delta))
+}
+
+func (p poolMetrics) QueueChanged(ctx context.Context, delta int) {
+	p.metrics.ExtractionQueueDepth.Add(ctx, int64(delta))
+}
+
+func (p poolMetrics) JobStarted(ctx context.Context, wait time.Duration) {
+	p.metrics.ExtractionQueueWait.Record(ctx, wait.Seconds())
+	p.metrics.ExtractionBusyWorkers.Add(ctx, 1)
+}
+
+func (p poolMetrics) JobDone(ctx context.Context) {
+	p.metrics.ExtractionBusyWorkers.Add(ctx, -1)
+}
+
+// withBreaker guards the title lookup of every provider with its own circuit breaker,
+// so an outage of one doesn't affect the others.
+func withBreaker(threshold int, cooldown time.Duration) musicextractors.TitleExtractorMiddleware {
+	return func(_ musicextractors.ExtractProvider, next musicextractors.MetadataExtractorFunc) musicextractors.MetadataExtractorFunc {
+		return musicextractors.WithCircuitBreaker(musicextractors.NewCircuitBreaker(threshold, cooldown), next)
+	}
+}
+
+// withTimeout limits every title lookup to d.
+func withTimeout(d time.Duration) musicextractors.TitleExtractorMiddleware {
+	return func(_ musicextractors.ExtractProvider, next musicextractors.MetadataExtractorFunc) musicextractors.MetadataExtractorFunc {
+		return musicextractors.WithTimeout(d, next)
+	}
+}
+
+// withHealth skips the title lookups of the providers that are down according to health.
+func withHealth(health *musicextractors.ProviderHealth) musicextractors.TitleExtractorMiddleware {
+	return func(provider musicextractors.ExtractProvider, next musicextractors.MetadataExtractorFunc) musicextractors.MetadataExtractorFunc {
+		return musicextractors.WithProviderHealth(health, provider, next)
+	}
+}
+
+// recordSummaryLimits records the configured number of summaries a user and a channel run at the same time.
+func recordSummaryLimits(ctx context.Context, metrics *telemetry.Metrics, userLimit, channelLimit int) {
+	metrics.SummaryLimit.Record(ctx, int64(userLimit), metric.WithAttributes(attribute.String("scope", "user")))
+	metrics.SummaryLimit.Record(ctx, int64(channelLimit), metric.WithAttributes(attribute.String("scope", "channel")))
+}
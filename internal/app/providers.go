@@ -0,0 +1,260 @@
+package app
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/internal/config"
+	"github.com/Shikachuu/wap-bot/internal/faults"
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/Shikachuu/wap-bot/pkg/textsummarizer"
+)
+
+// urlProcessors returns the music URL extractors of every enabled and custom provider.
+func urlProcessors(
+	cfg config.Config,
+	custom []musicextractors.RegexProvider,
+) map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc {
+	processors := enabledOnly(cfg, map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+		musicextractors.SpotifyProvider:       musicextractors.SpotifyURLExtractor,
+		musicextractors.YouTubeProvider:       musicextractors.YouTubeURLExtractor,
+		musicextractors.YoutTubeMusicProvider: musicextractors.YouTubeMusicURLExtractor,
+		musicextractors.MixcloudProvider:      musicextractors.MixcloudURLExtractor,
+		musicextractors.AudiomackProvider:     musicextractors.AudiomackURLExtractor,
+		musicextractors.AmazonMusicProvider:   musicextractors.AmazonMusicURLExtractor,
+		musicextractors.AppleMusicProvider:    musicextractors.AppleMusicURLExtractor,
+		musicextractors.ShazamProvider:        musicextractors.ShazamURLExtractor,
+		musicextractors.VimeoProvider:         musicextractors.VimeoURLExtractor,
+		musicextractors.DailymotionProvider:   musicextractors.DailymotionURLExtractor,
+		musicextractors.LastFMProvider:        musicextractors.LastFMURLExtractor,
+		musicextractors.DiscogsProvider:       musicextractors.DiscogsURLExtractor,
+	})
+
+	for _, p := range custom {
+		processors[p.Name] = p.URLExtractor
+	}
+
+	return processors
+}
+
+// Extractors returns the URL and metadata extractors of every enabled and custom provider, the same the bot matches
+// the messages with, for the tools validating the providers without starting the bot.
+//
+// Returns the extractors or an error if a custom provider definition is invalid.
+func Extractors(cfg config.Config) (
+	map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc,
+	map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc,
+	error,
+) {
+	custom, err := customProviders(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("providers setup: %w", err)
+	}
+
+	return urlProcessors(cfg, custom), metadataExtractors(cfg, custom), nil
+}
+
+// customProviders builds the providers defined by the operator.
+//
+// Returns the providers or an error if a definition is invalid or shadows a built-in provider.
+func customProviders(cfg config.Config) ([]musicextractors.RegexProvider, error) {
+	// The zero config enables every built-in provider
+	builtin := urlProcessors(config.Config{}, nil)
+	providers := make([]musicextractors.RegexProvider, 0, len(cfg.CustomProviders))
+
+	for _, cp := range cfg.CustomProviders {
+		name := musicextractors.ExtractProvider(cp.Name)
+		if _, ok := builtin[name]; ok {
+			return nil, fmt.Errorf("%w: %s is a built-in provider", musicextractors.ErrInvalidProvider, name)
+		}
+
+		p, err := musicextractors.NewRegexProvider(name, cp.Pattern, musicextractors.TitleStrategy(cp.Title))
+		if err != nil {
+			return nil, fmt.Errorf("custom provider: %w", err)
+		}
+
+		providers = append(providers, p)
+	}
+
+	return providers, nil
+}
+
+// enabledOnly drops every provider from m that is not enabled in the global config.
+func enabledOnly[T any](cfg config.Config, m map[musicextractors.ExtractProvider]T) map[musicextractors.ExtractProvider]T {
+	if len(cfg.EnabledProviders) == 0 {
+		return m
+	}
+
+	maps.DeleteFunc(m, func(p musicextractors.ExtractProvider, _ T) bool {
+		return !slices.Contains(cfg.EnabledProviders, string(p))
+	})
+
+	return m
+}
+
+// channelDisabledProviders converts the per-channel provider overrides to provider types.
+func channelDisabledProviders(cfg config.Config) map[string][]musicextractors.ExtractProvider {
+	channels := make(map[string][]musicextractors.ExtractProvider, len(cfg.ChannelDisabledProviders))
+
+	for channelID, providers := range cfg.ChannelDisabledProviders {
+		for _, p := range providers {
+			channels[channelID] = append(channels[channelID], musicextractors.ExtractProvider(p))
+		}
+	}
+
+	return channels
+}
+
+// providerPriority converts the configured provider priority to provider types.
+func providerPriority(cfg config.Config) []musicextractors.ExtractProvider {
+	priority := make([]musicextractors.ExtractProvider, 0, len(cfg.ProviderPriority))
+
+	for _, p := range cfg.ProviderPriority {
+		priority = append(priority, musicextractors.ExtractProvider(p))
+	}
+
+	return priority
+}
+
+// metadataExtractors returns the metadata extractors of every enabled and custom provider.
+//
+// Spotify titles are resolved via the Web API when credentials are configured, otherwise by scraping the track page
+// in the configured language.
+// YouTube titles are resolved via the Data API when an API key is configured, otherwise via oEmbed.
+// Mixcloud, Audiomack, Vimeo and Dailymotion titles are always resolved via oEmbed, Amazon Music, Apple Music, Shazam,
+// Last.fm and Discogs titles by scraping the track page.
+// Every lookup is limited by the provider timeout and guarded by a per-provider circuit breaker.
+func metadataExtractors(
+	cfg config.Config,
+	custom []musicextractors.RegexProvider,
+) map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc {
+	spotifyMetadata := musicextractors.NewSpotifyMetadataExtractor(cfg.SpotifyAcceptLanguage)
+	if cfg.SpotifyAPIEnabled() {
+		spotifyMetadata = musicextractors.NewSpotifyAPIMetadataExtractor(cfg.SpotifyClientID, cfg.SpotifyClientSecret)
+	}
+
+	youTubeMetadata := musicextractors.YouTubeMetadataExtractor
+	if cfg.YouTubeAPIKey != "" {
+		youTubeMetadata = musicextractors.NewYouTubeDataAPIClient(cfg.YouTubeAPIKey).MetadataExtractor()
+	}
+
+	extractors := enabledOnly(cfg, map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+		musicextractors.SpotifyProvider:       spotifyMetadata,
+		musicextractors.YouTubeProvider:       youTubeMetadata,
+		musicextractors.YoutTubeMusicProvider: youTubeMetadata,
+		musicextractors.MixcloudProvider:      musicextractors.MixcloudMetadataExtractor,
+		musicextractors.AudiomackProvider:     musicextractors.AudiomackMetadataExtractor,
+		musicextractors.AmazonMusicProvider:   musicextractors.AmazonMusicMetadataExtractor,
+		musicextractors.AppleMusicProvider:    musicextractors.AppleMusicMetadataExtractor,
+		musicextractors.ShazamProvider:        musicextractors.ShazamMetadataExtractor,
+		musicextractors.VimeoProvider:         musicextractors.VimeoMetadataExtractor,
+		musicextractors.DailymotionProvider:   musicextractors.DailymotionMetadataExtractor,
+		musicextractors.LastFMProvider:        musicextractors.LastFMMetadataExtractor,
+		musicextractors.DiscogsProvider:       musicextractors.DiscogsMetadataExtractor,
+	})
+
+	for _, p := range custom {
+		extractors[p.Name] = p.MetadataExtractor
+	}
+
+	for p, extract := range extractors {
+		extractors[p] = musicextractors.WrapTitleExtractor(p, extract,
+			withBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+			withTimeout(cfg.ProviderTimeout),
+			faults.ProviderTimeouts,
+		)
+	}
+
+	return extractors
+}
+
+// defaultProviderHealthTTL is how long a provider that failed the on-demand probe of the providers command is skipped,
+// when the providers aren't probed in the background.
+const defaultProviderHealthTTL = 5 * time.Minute
+
+// providerProbes returns the availability checks of every enabled built-in provider.
+func providerProbes(cfg config.Config) *musicextractors.Prober {
+	return musicextractors.NewProber(enabledOnly(cfg, maps.Clone(musicextractors.DefaultProbes)))
+}
+
+// providerHealth returns the registry of the providers that are down.
+//
+// A failed probe is trusted for two probe intervals, so a single missed background probe doesn't leave the provider
+// skipped for good. Without background probes only the providers command updates it.
+func providerHealth(cfg config.Config) *musicextractors.ProviderHealth {
+	if cfg.ProviderProbeInterval > 0 {
+		return musicextractors.NewProviderHealth(2 * cfg.ProviderProbeInterval)
+	}
+
+	return musicextractors.NewProviderHealth(defaultProviderHealthTTL)
+}
+
+// crossLinker returns the cross-provider matcher, or nil if it's disabled.
+func crossLinker(cfg config.Config) musicextractors.CrossLinker {
+	if !cfg.OdesliEnabled {
+		return nil
+	}
+
+	return musicextractors.NewOdesliCrossLinker(cfg.OdesliAPIKey)
+}
+
+// enricher returns the metadata enricher, or nil if it's disabled.
+func enricher(cfg config.Config) musicextractors.Enricher {
+	if !cfg.MusicBrainzEnabled {
+		return nil
+	}
+
+	return musicextractors.NewMusicBrainzEnricher()
+}
+
+// textSummarizer returns the language model that recaps the threads, or nil if it's disabled.
+func textSummarizer(cfg config.Config) textsummarizer.TextSummarizer {
+	switch cfg.DigestProvider {
+	case "openai":
+		return textsummarizer.NewOpenAISummarizer(cfg.DigestAPIKey, cfg.DigestAPIURL, cfg.DigestModel)
+	case "ollama":
+		return textsummarizer.NewOllamaSummarizer(cfg.DigestAPIURL, cfg.DigestModel)
+	default:
+		return nil
+	}
+}
+
+// playlistExpander returns the playlist expander, or nil if it's disabled.
+//
+// Only the playlists of the providers with API credentials are expanded,
+// the tracks of disabled providers are dropped like any other link of theirs.
+func playlistExpander(cfg config.Config) musicextractors.PlaylistExpander {
+	if !cfg.PlaylistExpansionEnabled {
+		return nil
+	}
+
+	return musicextractors.NewPlaylistExpander(cfg.SpotifyClientID, cfg.SpotifyClientSecret, cfg.YouTubeAPIKey, cfg.PlaylistExpansionLimit)
+}
+
+// playlistCreators returns the playlist creators of the providers with an account configured, empty if there is none.
+//
+// Spotify comes first, so it's the provider of the playlist button and of "playlist" without a provider.
+func playlistCreators(cfg config.Config) []musicextractors.PlaylistCreator {
+	var creators []musicextractors.PlaylistCreator
+
+	if cfg.SpotifyPlaylistsEnabled() {
+		creators = append(creators, musicextractors.NewSpotifyPlaylistCreator(cfg.SpotifyClientID, cfg.SpotifyClientSecret, cfg.SpotifyRefreshToken))
+	}
+
+	if cfg.YouTubePlaylistsEnabled() {
+		creators = append(creators, musicextractors.NewYouTubePlaylistCreator(cfg.YouTubeClientID, cfg.YouTubeClientSecret, cfg.YouTubeRefreshToken))
+	}
+
+	return creators
+}
+
+// shortURLResolver returns the short link resolver, or nil if it's disabled.
+func shortURLResolver(cfg config.Config) musicextractors.ShortURLResolver {
+	if !cfg.ShortURLResolverEnabled {
+		return nil
+	}
+
+	return musicextractors.NewRedirectResolver()
+}
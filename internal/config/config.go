@@ -2,27 +2,80 @@
 package config
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"slices"
+	"strconv"
 	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+)
+
+const (
+	// defaultCompressionThreshold is the summary size above which summaries get compressed, when compression is enabled.
+	defaultCompressionThreshold = 1 << 20
+	// defaultExtractionConcurrency is the number of messages processed in parallel during a summary.
+	defaultExtractionConcurrency = 8
+	// defaultPlaylistExpansionLimit is the number of tracks a shared playlist is expanded into.
+	defaultPlaylistExpansionLimit = 100
+	// defaultSummaryProgressThreshold is the number of messages above which a summary posts a progress message.
+	defaultSummaryProgressThreshold = 200
+	// defaultSummaryUserLimit and defaultSummaryChannelLimit are the number of summaries a user and a channel
+	// run at the same time.
+	defaultSummaryUserLimit    = 2
+	defaultSummaryChannelLimit = 4
+	// defaultEventConcurrency is the number of Slack events handled at the same time.
+	defaultEventConcurrency = 4
+	// defaultExtractionSpillThreshold is the number of links kept in memory during a summary before spilling to disk.
+	defaultExtractionSpillThreshold = 10000
+	// defaultSummarySpillBytes is the size of a summary file kept in memory before it's written to disk.
+	defaultSummarySpillBytes = 8 << 20
+	// defaultProviderTimeoutSeconds is the maximum duration of a single provider lookup.
+	defaultProviderTimeoutSeconds = 10
+	// defaultBreakerThreshold is the number of consecutive provider failures that open its circuit breaker.
+	defaultBreakerThreshold = 5
+	// defaultBreakerCooldownSeconds is how long an open circuit breaker rejects calls before trying again.
+	defaultBreakerCooldownSeconds = 30
+	// defaultDedupeStrategy only merges the links of the same recording, recognized by their ISRC.
+	defaultDedupeStrategy = "isrc"
+	// defaultSpotifyAcceptLanguage keeps the scraped Spotify pages in English, whatever the region of the server.
+	defaultSpotifyAcceptLanguage = "en"
+	// defaultScheduledThreadHour is the hour of the day (UTC) scheduled threads are opened at.
+	defaultScheduledThreadHour = 9
+	// DefaultScheduledThreadPrompt is the text of the scheduled threads, a text/template rendered with the date.
+	DefaultScheduledThreadPrompt = ":notes: *New Music Friday* {{.Date}} :notes:\n" +
+		"Share what you've been listening to this week in this thread, I'll summarize it when the next one starts!"
 )
 
+// dedupeStrategies are the names of the dedupe strategies of the summaries.
+var dedupeStrategies = []string{"isrc", "url", "title", "none"}
+
 var (
 	// ErrMissingVariable is returned by GetConfig if some of the required variables are missing.
 	ErrMissingVariable = errors.New("required variable is missing")
 	// ErrMissingPrefix is returned by GetConfig if some of the variables prefix is incorrect.
 	ErrMissingPrefix = errors.New("mandatory prefix is missing")
+	// ErrInvalidValue is returned by Load if some of the optional variables are malformed.
+	ErrInvalidValue = errors.New("invalid variable value")
 )
 
 // InDebugMode determines if the application is running in debug mode base.
 //
 // Returns true if the environment variable `DEBUG` has a value of either "1", "true" or "enable", false in every other case.
 func InDebugMode() bool {
-	debugEnabledOptions := []string{"1", "true", "enable"}
+	return boolFromEnv("DEBUG")
+}
 
-	return slices.Contains(debugEnabledOptions, strings.ToLower(os.Getenv("DEBUG")))
+// boolFromEnv reports if the given environment variable has a value of either "1", "true" or "enable".
+func boolFromEnv(key string) bool {
+	enabledOptions := []string{"1", "true", "enable"}
+
+	return slices.Contains(enabledOptions, strings.ToLower(os.Getenv(key)))
 }
 
 // GetConfig parses the Slack Bot's required credentials from the environment.
@@ -52,3 +105,547 @@ func GetConfig() (string, string, error) {
 
 	return botToken, appToken, nil
 }
+
+// CustomProvider is an operator defined provider, matched by a URL regex instead of code.
+type CustomProvider struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+	// Title is how titles are resolved: opengraph (default), none or oembed:<endpoint URL>.
+	Title string `json:"title"`
+}
+
+// Config contains every environment based setting the application needs to start.
+type Config struct {
+	BotToken string
+	AppToken string
+	// SpotifyClientID and SpotifyClientSecret are optional, when both set titles are resolved via the Spotify Web API.
+	SpotifyClientID     string
+	SpotifyClientSecret string
+	// SpotifyRefreshToken is optional, when set along with the credentials the playlist command creates Spotify playlists
+	// on the account that authorized it.
+	SpotifyRefreshToken string
+	// SpotifyAcceptLanguage is the Accept-Language of the scraped Spotify track pages.
+	SpotifyAcceptLanguage string
+	// YouTubeAPIKey is optional, when set YouTube titles are resolved via the YouTube Data API v3.
+	YouTubeAPIKey string
+	// YouTubeClientID, YouTubeClientSecret and YouTubeRefreshToken are optional, when every one is set
+	// "playlist youtube" creates YouTube playlists on the channel that authorized the refresh token.
+	YouTubeClientID     string
+	YouTubeClientSecret string
+	YouTubeRefreshToken string
+	// OdesliEnabled turns on cross-provider matching via song.link, OdesliAPIKey is optional.
+	OdesliEnabled bool
+	OdesliAPIKey  string
+	// ShortURLResolverEnabled turns on following the redirects of short links like spotify.link and bit.ly.
+	ShortURLResolverEnabled bool
+	// PlaylistExpansionEnabled turns on summarizing shared Spotify and YouTube playlists as their individual tracks,
+	// at most PlaylistExpansionLimit of them. It needs the Spotify credentials or the YouTube API key.
+	PlaylistExpansionEnabled bool
+	PlaylistExpansionLimit   int
+	// MusicBrainzEnabled turns on looking up the release year and genres of every track on MusicBrainz.
+	MusicBrainzEnabled bool
+	// DigestProvider is the language model that recaps the discussion of a thread in the summary comment,
+	// openai or ollama, empty disables the recap. DigestAPIKey, DigestAPIURL and DigestModel are optional.
+	DigestProvider string
+	DigestAPIKey   string
+	DigestAPIURL   string
+	DigestModel    string
+	// EnabledProviders lists the globally enabled providers, empty means every implemented provider.
+	EnabledProviders []string
+	// ProviderPriority orders the providers whose link is used when a message has links of several providers,
+	// unlisted providers follow in the order of the summary columns.
+	ProviderPriority []string
+	// CustomProviders are extra providers defined by the operator, they are always enabled.
+	CustomProviders []CustomProvider
+	// ChannelDisabledProviders maps channel IDs to the providers ignored in that channel.
+	ChannelDisabledProviders map[string][]string
+	// DedupeStrategy decides which links of a summary are merged into one row, isrc, url, title or none.
+	DedupeStrategy string
+	// ChannelDedupeStrategies maps channel IDs to the dedupe strategy used instead of DedupeStrategy in that channel.
+	ChannelDedupeStrategies map[string]string
+	// DedupeAcrossProviders also merges the links of the same song on different providers into one row,
+	// recognized by their track IDs, their Odesli cross-links and their ISRC.
+	DedupeAcrossProviders bool
+	// SummaryFormat is the file format of the uploaded summaries, csv, json, jsonl, md, html, xlsx, m3u8, xspf, inline or transcript.
+	SummaryFormat string
+	// SummaryArtwork adds the artwork URL of every track to the CSV summaries.
+	SummaryArtwork bool
+	// SummaryTimesShared adds the number of times every track was shared in the thread to the CSV summaries.
+	SummaryTimesShared bool
+	// SummaryGenre adds the genre tags of every track to the CSV summaries.
+	SummaryGenre bool
+	// SummaryStats appends the unique tracks, the links per provider and the top sharers to the summary comment.
+	SummaryStats bool
+	// SummarySkippedReport lists the links whose title couldn't be resolved with the reason in the XLSX,
+	// Markdown, HTML and JSON summaries.
+	SummarySkippedReport bool
+	// SummaryCommentTemplate and SummaryFileNameTemplate are text/template templates of the headline of the summary comment
+	// and the name of the summary file, empty keeps the built-in texts.
+	SummaryCommentTemplate  string
+	SummaryFileNameTemplate string
+	// SummaryIncremental only resolves the messages posted since the last summary of a thread summarized before,
+	// the earlier tracks are taken from the track index.
+	SummaryIncremental bool
+	// SummaryReplacePrevious deletes the file and the messages of the last summary of a thread when it's summarized again.
+	SummaryReplacePrevious bool
+	// SummaryProgressThreshold is the number of messages above which a summary posts a progress message
+	// that's updated while it runs, 0 disables it.
+	SummaryProgressThreshold int
+	// SummaryUserLimit and SummaryChannelLimit are the number of summaries a user and a channel run at the same time,
+	// the rest of them are asked to wait, 0 disables the limit.
+	SummaryUserLimit    int
+	SummaryChannelLimit int
+	// SummaryCSVDelimiter is the column delimiter of the CSV summaries, a character or tab, comma, semicolon or pipe,
+	// empty means a semicolon. SummaryCSVBOM and SummaryCSVCRLF add a UTF-8 byte order mark and \r\n line endings
+	// to them, both needed by Excel.
+	SummaryCSVDelimiter string
+	SummaryCSVBOM       bool
+	SummaryCSVCRLF      bool
+	// ExportCompression is the compression of summaries above ExportCompressionThreshold bytes, none, gzip or zip.
+	ExportCompression          string
+	ExportCompressionThreshold int
+	// ExtractionConcurrency is the number of messages whose titles are fetched in parallel.
+	ExtractionConcurrency int
+	// EventConcurrency is the number of Slack events handled at the same time, like summaries of different threads.
+	EventConcurrency int
+	// ExtractionSpillThreshold is the number of links kept in memory during a summary, the rest is spilled to a
+	// temporary file, 0 keeps every link in memory.
+	ExtractionSpillThreshold int
+	// SummarySpillBytes is the size of a summary file kept in memory, larger files are written to a temporary file
+	// and uploaded from there, 0 keeps every file in memory.
+	SummarySpillBytes int
+	// ProviderTimeout is the maximum duration of a single provider lookup.
+	ProviderTimeout time.Duration
+	// BreakerThreshold consecutive failures of a provider open its circuit breaker for BreakerCooldown.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+	// ProviderProbeInterval is how often the availability of the providers is checked in the background,
+	// the lookups of the providers that are down are skipped until they recover. Zero disables the checks.
+	ProviderProbeInterval time.Duration
+	// SilentChannels are the channels whose summaries are uploaded without a comment and without the follow-up buttons,
+	// the same as requesting "summarize silent" there.
+	SilentChannels []string
+	// AdminUsers are the IDs of the Slack users allowed to use the admin commands, like the usage dashboard.
+	AdminUsers []string
+	// ScheduledThreadChannels lists the channels where a themed thread is opened every ScheduledThreadWeekday
+	// at ScheduledThreadHour (UTC), the previous one gets summarized at the same time. Empty disables the feature.
+	ScheduledThreadChannels []string
+	ScheduledThreadWeekday  time.Weekday
+	ScheduledThreadHour     int
+	// ScheduledThreadPrompt is the text/template of the scheduled threads' parent message.
+	ScheduledThreadPrompt string
+	// ScheduledThreadUsergroup is the handle of the usergroup mentioned in the digests of the scheduled threads,
+	// ScheduledThreadSilent names it without notifying its members.
+	ScheduledThreadUsergroup string
+	ScheduledThreadSilent    bool
+	// StorageFile is the path of the state file (user preferences etc.), empty keeps the state in memory only.
+	StorageFile string
+	// ArchiveDir is the directory closed threads are archived to, empty disables archiving.
+	ArchiveDir string
+	Debug      bool
+}
+
+// Load parses the whole application configuration from the environment.
+//
+// Returns the parsed config and an error if any of the required variables are missing or invalid.
+func Load() (Config, error) {
+	botToken, appToken, err := GetConfig()
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg, err := LoadSettings()
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg.BotToken, cfg.AppToken = botToken, appToken
+
+	return cfg, nil
+}
+
+// LoadSettings parses every optional setting from the environment, without the Slack credentials,
+// for the tools that use the providers without connecting to Slack.
+//
+// Returns the parsed config and an error if any of the variables are invalid.
+func LoadSettings() (Config, error) {
+	channelDisabled, err := parseChannelProviders(os.Getenv("CHANNEL_DISABLED_PROVIDERS"))
+	if err != nil {
+		return Config{}, fmt.Errorf("CHANNEL_DISABLED_PROVIDERS: %w", err)
+	}
+
+	customProviders, err := parseCustomProviders(os.Getenv("CUSTOM_PROVIDERS"))
+	if err != nil {
+		return Config{}, fmt.Errorf("CUSTOM_PROVIDERS: %w", err)
+	}
+
+	known := knownProviders(customProviders)
+
+	enabledProviders := splitList(os.Getenv("ENABLED_PROVIDERS"))
+	if err = validateProviders(enabledProviders, known); err != nil {
+		return Config{}, fmt.Errorf("ENABLED_PROVIDERS: %w", err)
+	}
+
+	providerPriority := splitList(os.Getenv("PROVIDER_PRIORITY"))
+	if err = validateProviders(providerPriority, known); err != nil {
+		return Config{}, fmt.Errorf("PROVIDER_PRIORITY: %w", err)
+	}
+
+	for _, channelID := range slices.Sorted(maps.Keys(channelDisabled)) {
+		if err = validateProviders(channelDisabled[channelID], known); err != nil {
+			return Config{}, fmt.Errorf("CHANNEL_DISABLED_PROVIDERS: %s: %w", channelID, err)
+		}
+	}
+
+	dedupe := strings.ToLower(os.Getenv("DEDUPE_STRATEGY"))
+	if dedupe == "" {
+		dedupe = defaultDedupeStrategy
+	}
+
+	if !slices.Contains(dedupeStrategies, dedupe) {
+		return Config{}, fmt.Errorf("DEDUPE_STRATEGY: %w: %q", ErrInvalidValue, dedupe)
+	}
+
+	channelDedupe, err := parseChannelDedupe(os.Getenv("CHANNEL_DEDUPE_STRATEGIES"))
+	if err != nil {
+		return Config{}, fmt.Errorf("CHANNEL_DEDUPE_STRATEGIES: %w", err)
+	}
+
+	summaryFormat := strings.ToLower(os.Getenv("SUMMARY_FORMAT"))
+	if summaryFormat == "" {
+		summaryFormat = "csv"
+	}
+
+	if !slices.Contains([]string{"csv", "json", "jsonl", "md", "html", "xlsx", "m3u8", "xspf", "inline", "transcript"}, summaryFormat) {
+		return Config{}, fmt.Errorf("SUMMARY_FORMAT: %w: %q", ErrInvalidValue, summaryFormat)
+	}
+
+	compression := strings.ToLower(os.Getenv("EXPORT_COMPRESSION"))
+	if compression == "" {
+		compression = "none"
+	}
+
+	if !slices.Contains([]string{"none", "gzip", "zip"}, compression) {
+		return Config{}, fmt.Errorf("EXPORT_COMPRESSION: %w: %q", ErrInvalidValue, compression)
+	}
+
+	digestProvider := strings.ToLower(os.Getenv("DIGEST_PROVIDER"))
+	if !slices.Contains([]string{"", "openai", "ollama"}, digestProvider) {
+		return Config{}, fmt.Errorf("DIGEST_PROVIDER: %w: %q", ErrInvalidValue, digestProvider)
+	}
+
+	threshold, err := intFromEnv("EXPORT_COMPRESSION_THRESHOLD_BYTES", defaultCompressionThreshold)
+	if err != nil {
+		return Config{}, err
+	}
+
+	concurrency, err := intFromEnv("EXTRACTION_CONCURRENCY", defaultExtractionConcurrency)
+	if err != nil {
+		return Config{}, err
+	}
+
+	eventConcurrency, err := intFromEnv("EVENT_CONCURRENCY", defaultEventConcurrency)
+	if err != nil {
+		return Config{}, err
+	}
+
+	progressThreshold, err := intFromEnv("SUMMARY_PROGRESS_THRESHOLD", defaultSummaryProgressThreshold)
+	if err != nil {
+		return Config{}, err
+	}
+
+	userLimit, err := intFromEnv("SUMMARY_LIMIT_PER_USER", defaultSummaryUserLimit)
+	if err != nil {
+		return Config{}, err
+	}
+
+	channelLimit, err := intFromEnv("SUMMARY_LIMIT_PER_CHANNEL", defaultSummaryChannelLimit)
+	if err != nil {
+		return Config{}, err
+	}
+
+	playlistLimit, err := intFromEnv("PLAYLIST_EXPANSION_LIMIT", defaultPlaylistExpansionLimit)
+	if err != nil {
+		return Config{}, err
+	}
+
+	spillThreshold, err := intFromEnv("EXTRACTION_SPILL_THRESHOLD", defaultExtractionSpillThreshold)
+	if err != nil {
+		return Config{}, err
+	}
+
+	summarySpillBytes, err := intFromEnv("SUMMARY_SPILL_BYTES", defaultSummarySpillBytes)
+	if err != nil {
+		return Config{}, err
+	}
+
+	providerTimeout, err := intFromEnv("PROVIDER_TIMEOUT_SECONDS", defaultProviderTimeoutSeconds)
+	if err != nil {
+		return Config{}, err
+	}
+
+	breakerThreshold, err := intFromEnv("PROVIDER_BREAKER_THRESHOLD", defaultBreakerThreshold)
+	if err != nil {
+		return Config{}, err
+	}
+
+	breakerCooldown, err := intFromEnv("PROVIDER_BREAKER_COOLDOWN_SECONDS", defaultBreakerCooldownSeconds)
+	if err != nil {
+		return Config{}, err
+	}
+
+	probeInterval, err := intFromEnv("PROVIDER_PROBE_INTERVAL_SECONDS", 0)
+	if err != nil {
+		return Config{}, err
+	}
+
+	weekday, err := weekdayFromEnv("SCHEDULED_THREAD_WEEKDAY", time.Friday)
+	if err != nil {
+		return Config{}, err
+	}
+
+	hour, err := intFromEnv("SCHEDULED_THREAD_HOUR", defaultScheduledThreadHour)
+	if err != nil {
+		return Config{}, err
+	}
+
+	if hour > 23 {
+		return Config{}, fmt.Errorf("SCHEDULED_THREAD_HOUR: %w: %d", ErrInvalidValue, hour)
+	}
+
+	spotifyLanguage := os.Getenv("SPOTIFY_ACCEPT_LANGUAGE")
+	if spotifyLanguage == "" {
+		spotifyLanguage = defaultSpotifyAcceptLanguage
+	}
+
+	prompt := os.Getenv("SCHEDULED_THREAD_PROMPT")
+	if prompt == "" {
+		prompt = DefaultScheduledThreadPrompt
+	}
+
+	if _, err = template.New("prompt").Parse(prompt); err != nil {
+		return Config{}, fmt.Errorf("SCHEDULED_THREAD_PROMPT: %w: %w", ErrInvalidValue, err)
+	}
+
+	return Config{
+		SpotifyClientID:            os.Getenv("SPOTIFY_CLIENT_ID"),
+		SpotifyClientSecret:        os.Getenv("SPOTIFY_CLIENT_SECRET"),
+		SpotifyRefreshToken:        os.Getenv("SPOTIFY_REFRESH_TOKEN"),
+		SpotifyAcceptLanguage:      spotifyLanguage,
+		YouTubeAPIKey:              os.Getenv("YOUTUBE_API_KEY"),
+		YouTubeClientID:            os.Getenv("YOUTUBE_CLIENT_ID"),
+		YouTubeClientSecret:        os.Getenv("YOUTUBE_CLIENT_SECRET"),
+		YouTubeRefreshToken:        os.Getenv("YOUTUBE_REFRESH_TOKEN"),
+		OdesliEnabled:              boolFromEnv("ODESLI_ENABLED"),
+		OdesliAPIKey:               os.Getenv("ODESLI_API_KEY"),
+		MusicBrainzEnabled:         boolFromEnv("MUSICBRAINZ_ENABLED"),
+		DigestProvider:             digestProvider,
+		DigestAPIKey:               os.Getenv("DIGEST_API_KEY"),
+		DigestAPIURL:               os.Getenv("DIGEST_API_URL"),
+		DigestModel:                os.Getenv("DIGEST_MODEL"),
+		ShortURLResolverEnabled:    boolFromEnv("SHORT_URL_RESOLVER_ENABLED"),
+		PlaylistExpansionEnabled:   boolFromEnv("PLAYLIST_EXPANSION_ENABLED"),
+		PlaylistExpansionLimit:     playlistLimit,
+		EnabledProviders:           enabledProviders,
+		AdminUsers:                 splitList(os.Getenv("ADMIN_USERS")),
+		SilentChannels:             splitList(os.Getenv("SILENT_CHANNELS")),
+		ProviderPriority:           providerPriority,
+		CustomProviders:            customProviders,
+		ChannelDisabledProviders:   channelDisabled,
+		DedupeStrategy:             dedupe,
+		ChannelDedupeStrategies:    channelDedupe,
+		DedupeAcrossProviders:      boolFromEnv("DEDUPE_ACROSS_PROVIDERS"),
+		SummaryFormat:              summaryFormat,
+		SummaryArtwork:             boolFromEnv("SUMMARY_ARTWORK"),
+		SummaryTimesShared:         boolFromEnv("SUMMARY_TIMES_SHARED"),
+		SummaryGenre:               boolFromEnv("SUMMARY_GENRE"),
+		SummaryStats:               boolFromEnv("SUMMARY_STATS"),
+		SummarySkippedReport:       boolFromEnv("SUMMARY_SKIPPED_REPORT"),
+		SummaryCommentTemplate:     os.Getenv("SUMMARY_COMMENT_TEMPLATE"),
+		SummaryFileNameTemplate:    os.Getenv("SUMMARY_FILENAME_TEMPLATE"),
+		SummaryIncremental:         boolFromEnv("SUMMARY_INCREMENTAL"),
+		SummaryReplacePrevious:     boolFromEnv("SUMMARY_REPLACE_PREVIOUS"),
+		SummaryProgressThreshold:   progressThreshold,
+		SummaryUserLimit:           userLimit,
+		SummaryChannelLimit:        channelLimit,
+		SummaryCSVDelimiter:        os.Getenv("SUMMARY_CSV_DELIMITER"),
+		SummaryCSVBOM:              boolFromEnv("SUMMARY_CSV_BOM"),
+		SummaryCSVCRLF:             boolFromEnv("SUMMARY_CSV_CRLF"),
+		ExportCompression:          compression,
+		ExportCompressionThreshold: threshold,
+		ExtractionConcurrency:      concurrency,
+		EventConcurrency:           eventConcurrency,
+		ExtractionSpillThreshold:   spillThreshold,
+		SummarySpillBytes:          summarySpillBytes,
+		ProviderTimeout:            time.Duration(providerTimeout) * time.Second,
+		BreakerThreshold:           breakerThreshold,
+		BreakerCooldown:            time.Duration(breakerCooldown) * time.Second,
+		ProviderProbeInterval:      time.Duration(probeInterval) * time.Second,
+		ScheduledThreadChannels:    splitList(os.Getenv("SCHEDULED_THREAD_CHANNELS")),
+		ScheduledThreadWeekday:     weekday,
+		ScheduledThreadHour:        hour,
+		ScheduledThreadPrompt:      prompt,
+		ScheduledThreadUsergroup:   os.Getenv("SCHEDULED_THREAD_USERGROUP"),
+		ScheduledThreadSilent:      boolFromEnv("SCHEDULED_THREAD_SILENT"),
+		StorageFile:                os.Getenv("STORAGE_FILE"),
+		ArchiveDir:                 os.Getenv("ARCHIVE_DIR"),
+		Debug:                      InDebugMode(),
+	}, nil
+}
+
+// intFromEnv parses a non-negative integer environment variable, falling back to def if it's unset.
+func intFromEnv(key string, def int) (int, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def, nil
+	}
+
+	v, err := strconv.Atoi(raw)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("%s: %w: %q", key, ErrInvalidValue, raw)
+	}
+
+	return v, nil
+}
+
+// weekdayFromEnv parses the English name of a weekday like "friday", falling back to def if it's unset.
+func weekdayFromEnv(key string, def time.Weekday) (time.Weekday, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def, nil
+	}
+
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(raw, d.String()) {
+			return d, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%s: %w: %q", key, ErrInvalidValue, raw)
+}
+
+// splitList splits a comma separated list, trimming whitespace and dropping empty items.
+func splitList(raw string) []string {
+	items := []string{}
+
+	for item := range strings.SplitSeq(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// parseChannelProviders parses per-channel provider lists in the `C123=youtube,spotify;C456=youtube` format.
+func parseChannelProviders(raw string) (map[string][]string, error) {
+	channels := map[string][]string{}
+
+	for entry := range strings.SplitSeq(raw, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		channelID, providers, found := strings.Cut(entry, "=")
+
+		channelID = strings.TrimSpace(channelID)
+		if !found || channelID == "" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidValue, entry)
+		}
+
+		channels[channelID] = append(channels[channelID], splitList(providers)...)
+	}
+
+	return channels, nil
+}
+
+// knownProviders returns the names of every built-in provider and of the custom ones.
+func knownProviders(custom []CustomProvider) []string {
+	known := make([]string, 0, len(musicextractors.Providers())+len(custom))
+
+	for _, p := range musicextractors.Providers() {
+		known = append(known, string(p))
+	}
+
+	for _, p := range custom {
+		known = append(known, p.Name)
+	}
+
+	return known
+}
+
+// validateProviders checks that every name of a provider list is one of the known providers.
+//
+// Returns ErrInvalidValue naming the first unknown provider.
+func validateProviders(names, known []string) error {
+	for _, name := range names {
+		if !slices.Contains(known, name) {
+			return fmt.Errorf("%w: unknown provider %q", ErrInvalidValue, name)
+		}
+	}
+
+	return nil
+}
+
+// parseChannelDedupe parses per-channel dedupe strategies in the `C123=title;C456=none` format.
+func parseChannelDedupe(raw string) (map[string]string, error) {
+	channels, err := parseChannelProviders(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	strategies := make(map[string]string, len(channels))
+
+	for channelID, values := range channels {
+		if len(values) != 1 || !slices.Contains(dedupeStrategies, strings.ToLower(values[0])) {
+			return nil, fmt.Errorf("%w: %s=%s", ErrInvalidValue, channelID, strings.Join(values, ","))
+		}
+
+		strategies[channelID] = strings.ToLower(values[0])
+	}
+
+	return strategies, nil
+}
+
+// parseCustomProviders parses a JSON array of provider definitions,
+// like `[{"name":"plex","pattern":"https://plex\\.example\\.com/track/\\d+","title":"opengraph"}]`.
+//
+// Only the shape is validated here, the patterns and title strategies are validated when the providers are built.
+func parseCustomProviders(raw string) ([]CustomProvider, error) {
+	providers := []CustomProvider{}
+
+	if strings.TrimSpace(raw) == "" {
+		return providers, nil
+	}
+
+	if err := json.Unmarshal([]byte(raw), &providers); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidValue, err)
+	}
+
+	for i, p := range providers {
+		if p.Name == "" || p.Pattern == "" {
+			return nil, fmt.Errorf("%w: every provider needs a name and a pattern", ErrInvalidValue)
+		}
+
+		if p.Title == "" {
+			providers[i].Title = "opengraph"
+		}
+	}
+
+	return providers, nil
+}
+
+// SpotifyAPIEnabled reports if both Spotify Web API credentials are configured.
+func (c Config) SpotifyAPIEnabled() bool {
+	return c.SpotifyClientID != "" && c.SpotifyClientSecret != ""
+}
+
+// SpotifyPlaylistsEnabled reports if the Spotify Web API credentials and the refresh token of an account are configured.
+func (c Config) SpotifyPlaylistsEnabled() bool {
+	return c.SpotifyAPIEnabled() && c.SpotifyRefreshToken != ""
+}
+
+// YouTubePlaylistsEnabled reports if the Google OAuth client and the refresh token of a YouTube channel are configured.
+func (c Config) YouTubePlaylistsEnabled() bool {
+	return c.YouTubeClientID != "" && c.YouTubeClientSecret != "" && c.YouTubeRefreshToken != ""
+}
@@ -0,0 +1,331 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChannelProviders(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr error
+		want    map[string][]string
+		name    string
+		raw     string
+	}{
+		{
+			name: "empty",
+			raw:  "",
+			want: map[string][]string{},
+		},
+		{
+			name: "multiple channels",
+			raw:  "C123=youtube, youtube-music; C456=spotify",
+			want: map[string][]string{
+				"C123": {"youtube", "youtube-music"},
+				"C456": {"spotify"},
+			},
+		},
+		{
+			name: "trailing separator",
+			raw:  "C123=youtube;",
+			want: map[string][]string{"C123": {"youtube"}},
+		},
+		{
+			name:    "missing channel",
+			raw:     "=youtube",
+			wantErr: ErrInvalidValue,
+		},
+		{
+			name:    "missing separator",
+			raw:     "C123",
+			wantErr: ErrInvalidValue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseChannelProviders(tt.raw)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLoad_OdesliSettings(t *testing.T) {
+	t.Setenv("SLACK_BOT_TOKEN", "xoxb-test")
+	t.Setenv("SLACK_APP_TOKEN", "xapp-test")
+	t.Setenv("ODESLI_ENABLED", "true")
+	t.Setenv("ODESLI_API_KEY", "odesli-key")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.True(t, cfg.OdesliEnabled)
+	assert.Equal(t, "odesli-key", cfg.OdesliAPIKey)
+}
+
+func TestLoad_DigestSettings(t *testing.T) {
+	t.Setenv("SLACK_BOT_TOKEN", "xoxb-test")
+	t.Setenv("SLACK_APP_TOKEN", "xapp-test")
+	t.Setenv("DIGEST_PROVIDER", "Ollama")
+	t.Setenv("DIGEST_API_URL", "http://ollama:11434")
+	t.Setenv("DIGEST_MODEL", "mistral")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "ollama", cfg.DigestProvider)
+	assert.Equal(t, "http://ollama:11434", cfg.DigestAPIURL)
+	assert.Equal(t, "mistral", cfg.DigestModel)
+
+	t.Setenv("DIGEST_PROVIDER", "claude")
+
+	_, err = Load()
+	require.ErrorIs(t, err, ErrInvalidValue)
+}
+
+func TestLoadSettings_WithoutSlackCredentials(t *testing.T) {
+	t.Setenv("SLACK_BOT_TOKEN", "")
+	t.Setenv("SLACK_APP_TOKEN", "")
+	t.Setenv("ENABLED_PROVIDERS", "spotify, shazam")
+
+	cfg, err := LoadSettings()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"spotify", "shazam"}, cfg.EnabledProviders)
+	assert.Empty(t, cfg.BotToken)
+
+	_, err = Load()
+	require.ErrorIs(t, err, ErrMissingVariable)
+}
+
+func TestLoadSettings_ProviderNames(t *testing.T) {
+	tests := []struct {
+		env     map[string]string
+		wantErr string
+		name    string
+	}{
+		{
+			name: "built-in providers",
+			env: map[string]string{
+				"ENABLED_PROVIDERS":          "spotify, youtube-music",
+				"PROVIDER_PRIORITY":          "youtube-music",
+				"CHANNEL_DISABLED_PROVIDERS": "C123=spotify",
+			},
+		},
+		{
+			name: "custom provider",
+			env: map[string]string{
+				"CUSTOM_PROVIDERS":           `[{"name":"plex","pattern":"https://plex\\.example\\.com/track/\\d+"}]`,
+				"PROVIDER_PRIORITY":          "plex, spotify",
+				"CHANNEL_DISABLED_PROVIDERS": "C123=plex",
+			},
+		},
+		{
+			name:    "unknown enabled provider",
+			env:     map[string]string{"ENABLED_PROVIDERS": "spotify, tidal"},
+			wantErr: `ENABLED_PROVIDERS: invalid variable value: unknown provider "tidal"`,
+		},
+		{
+			name:    "unknown prioritized provider",
+			env:     map[string]string{"PROVIDER_PRIORITY": "Spotify"},
+			wantErr: `PROVIDER_PRIORITY: invalid variable value: unknown provider "Spotify"`,
+		},
+		{
+			name:    "unknown channel disabled provider",
+			env:     map[string]string{"CHANNEL_DISABLED_PROVIDERS": "C123=youtube;C456=soundcloud"},
+			wantErr: `CHANNEL_DISABLED_PROVIDERS: C456: invalid variable value: unknown provider "soundcloud"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			_, err := LoadSettings()
+			if tt.wantErr != "" {
+				require.ErrorIs(t, err, ErrInvalidValue)
+				assert.EqualError(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestLoad_ScheduledThreadSettings(t *testing.T) {
+	tests := []struct {
+		env         map[string]string
+		wantErr     error
+		name        string
+		wantWeekday time.Weekday
+		wantHour    int
+	}{
+		{
+			name:        "defaults",
+			env:         map[string]string{},
+			wantWeekday: time.Friday,
+			wantHour:    9,
+		},
+		{
+			name:        "custom schedule",
+			env:         map[string]string{"SCHEDULED_THREAD_WEEKDAY": "Monday", "SCHEDULED_THREAD_HOUR": "17"},
+			wantWeekday: time.Monday,
+			wantHour:    17,
+		},
+		{
+			name:    "unknown weekday",
+			env:     map[string]string{"SCHEDULED_THREAD_WEEKDAY": "funday"},
+			wantErr: ErrInvalidValue,
+		},
+		{
+			name:    "hour out of range",
+			env:     map[string]string{"SCHEDULED_THREAD_HOUR": "24"},
+			wantErr: ErrInvalidValue,
+		},
+		{
+			name:    "broken prompt template",
+			env:     map[string]string{"SCHEDULED_THREAD_PROMPT": "{{.Date"},
+			wantErr: ErrInvalidValue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SLACK_BOT_TOKEN", "xoxb-test")
+			t.Setenv("SLACK_APP_TOKEN", "xapp-test")
+			t.Setenv("SCHEDULED_THREAD_CHANNELS", "C123, C456")
+
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			cfg, err := Load()
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, []string{"C123", "C456"}, cfg.ScheduledThreadChannels)
+			assert.Equal(t, tt.wantWeekday, cfg.ScheduledThreadWeekday)
+			assert.Equal(t, tt.wantHour, cfg.ScheduledThreadHour)
+			assert.Equal(t, DefaultScheduledThreadPrompt, cfg.ScheduledThreadPrompt)
+		})
+	}
+}
+
+func TestParseCustomProviders(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr error
+		name    string
+		raw     string
+		want    []CustomProvider
+	}{
+		{
+			name: "empty",
+			raw:  "",
+			want: []CustomProvider{},
+		},
+		{
+			name: "single provider",
+			raw:  `[{"name":"plex","pattern":"https://plex\\.example\\.com/track/\\d+","title":"opengraph"}]`,
+			want: []CustomProvider{{Name: "plex", Pattern: `https://plex\.example\.com/track/\d+`, Title: "opengraph"}},
+		},
+		{
+			name: "default title strategy",
+			raw:  `[{"name":"plex","pattern":"https://plex\\.example\\.com/track/\\d+"}]`,
+			want: []CustomProvider{{Name: "plex", Pattern: `https://plex\.example\.com/track/\d+`, Title: "opengraph"}},
+		},
+		{
+			name:    "not json",
+			raw:     "plex=https://plex.example.com",
+			wantErr: ErrInvalidValue,
+		},
+		{
+			name:    "missing pattern",
+			raw:     `[{"name":"plex"}]`,
+			wantErr: ErrInvalidValue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseCustomProviders(tt.raw)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLoad_SpotifyAcceptLanguage(t *testing.T) {
+	t.Setenv("SLACK_BOT_TOKEN", "xoxb-test")
+	t.Setenv("SLACK_APP_TOKEN", "xapp-test")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "en", cfg.SpotifyAcceptLanguage)
+
+	t.Setenv("SPOTIFY_ACCEPT_LANGUAGE", "de-DE, en;q=0.8")
+
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "de-DE, en;q=0.8", cfg.SpotifyAcceptLanguage)
+}
+
+func TestParseChannelDedupe(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr error
+		want    map[string]string
+		name    string
+		raw     string
+	}{
+		{name: "empty", raw: "", want: map[string]string{}},
+		{name: "multiple channels", raw: "C123=title; C456=NONE", want: map[string]string{"C123": "title", "C456": "none"}},
+		{name: "unknown strategy", raw: "C123=fuzzy", wantErr: ErrInvalidValue},
+		{name: "several strategies", raw: "C123=title,url", wantErr: ErrInvalidValue},
+		{name: "missing channel", raw: "=title", wantErr: ErrInvalidValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseChannelDedupe(tt.raw)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// CompressionKind is the archive format large summaries are compressed with before upload.
+type CompressionKind string
+
+const (
+	// CompressionNone uploads the summary as is, regardless of its size.
+	CompressionNone CompressionKind = "none"
+	// CompressionGzip compresses the summary into a .gz file.
+	CompressionGzip CompressionKind = "gzip"
+	// CompressionZip puts the summary into a .zip archive.
+	CompressionZip CompressionKind = "zip"
+)
+
+// Compression configures when and how summaries get compressed.
+type Compression struct {
+	Kind CompressionKind
+	// ThresholdBytes is the summary size above which the summary is compressed.
+	ThresholdBytes int
+}
+
+// compress compresses the given summary file if it's larger than the configured threshold,
+// the compressed file is spooled to disk above spillBytes like the original one.
+//
+// Returns the new file, its size and its name reflecting the compression, or the original ones if no compression was needed.
+func (c Compression) compress(f io.Reader, size int, fileName string, spillBytes int) (io.Reader, int, string, error) {
+	if size <= c.ThresholdBytes || c.Kind == CompressionNone {
+		return f, size, fileName, nil
+	}
+
+	// Only the compressed file is uploaded, a spooled original is removed right away
+	if closer, ok := f.(io.Closer); ok {
+		defer func() { _ = closer.Close() }()
+	}
+
+	buff := newFileSpool(spillBytes)
+
+	err := func() error {
+		switch c.Kind {
+		case CompressionGzip:
+			w := gzip.NewWriter(buff)
+			w.Name = fileName
+
+			if _, err := io.Copy(w, f); err != nil {
+				return fmt.Errorf("writing gzip stream: %w", err)
+			}
+
+			if err := w.Close(); err != nil {
+				return fmt.Errorf("closing gzip stream: %w", err)
+			}
+
+			fileName += ".gz"
+		case CompressionZip:
+			w := zip.NewWriter(buff)
+
+			entry, err := w.Create(fileName)
+			if err != nil {
+				return fmt.Errorf("creating zip entry: %w", err)
+			}
+
+			if _, err = io.Copy(entry, f); err != nil {
+				return fmt.Errorf("writing zip entry: %w", err)
+			}
+
+			if err = w.Close(); err != nil {
+				return fmt.Errorf("closing zip archive: %w", err)
+			}
+
+			fileName += ".zip"
+		default:
+			return fmt.Errorf("%w: %s", ErrUnsupportedCompression, c.Kind)
+		}
+
+		return nil
+	}()
+	if err != nil {
+		_ = buff.discard()
+
+		return nil, 0, "", err
+	}
+
+	r, n, err := buff.reader()
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	return r, n, fileName, nil
+}
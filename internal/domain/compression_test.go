@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompression_Compress(t *testing.T) {
+	t.Parallel()
+
+	const content = "Title;Spotify URL;YouTube URL;YouTube Music URL\n"
+
+	tests := []struct {
+		wantErr  error
+		name     string
+		wantName string
+		c        Compression
+	}{
+		{
+			name:     "below threshold",
+			c:        Compression{Kind: CompressionGzip, ThresholdBytes: len(content)},
+			wantName: "summary.csv",
+		},
+		{
+			name:     "disabled",
+			c:        Compression{Kind: CompressionNone},
+			wantName: "summary.csv",
+		},
+		{
+			name:     "gzip",
+			c:        Compression{Kind: CompressionGzip},
+			wantName: "summary.csv.gz",
+		},
+		{
+			name:     "zip",
+			c:        Compression{Kind: CompressionZip},
+			wantName: "summary.csv.zip",
+		},
+		{
+			name:    "unknown",
+			c:       Compression{Kind: "rar"},
+			wantErr: ErrUnsupportedCompression,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			f, size, name, err := tt.c.compress(strings.NewReader(content), len(content), "summary.csv", 0)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantName, name)
+
+			raw, err := io.ReadAll(f)
+			require.NoError(t, err)
+			assert.Len(t, raw, size)
+
+			var got []byte
+
+			switch {
+			case strings.HasSuffix(name, ".gz"):
+				r, gErr := gzip.NewReader(bytes.NewReader(raw))
+				require.NoError(t, gErr)
+
+				got, err = io.ReadAll(r)
+			case strings.HasSuffix(name, ".zip"):
+				r, zErr := zip.NewReader(bytes.NewReader(raw), int64(len(raw)))
+				require.NoError(t, zErr)
+				require.Len(t, r.File, 1)
+				assert.Equal(t, "summary.csv", r.File[0].Name)
+
+				entry, oErr := r.File[0].Open()
+				require.NoError(t, oErr)
+
+				got, err = io.ReadAll(entry)
+			default:
+				got = raw
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, content, string(got))
+		})
+	}
+}
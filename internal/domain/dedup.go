@@ -0,0 +1,254 @@
+package domain
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+)
+
+// DedupeStrategy decides which links of a summary are shares of the same track.
+type DedupeStrategy interface {
+	// Key returns the identity of the track shared as url, links with the same non-empty key are merged
+	// into their first occurrence. An empty key keeps the link as is.
+	Key(url string, metadata musicextractors.TrackMetadata) string
+}
+
+// DedupeKeyFunc adapts a function to a DedupeStrategy.
+type DedupeKeyFunc func(url string, metadata musicextractors.TrackMetadata) string
+
+var _ DedupeStrategy = DedupeKeyFunc(nil)
+
+// Key calls f.
+func (f DedupeKeyFunc) Key(url string, metadata musicextractors.TrackMetadata) string {
+	return f(url, metadata)
+}
+
+// DedupeStrategyName names one of the built-in strategies, as used in the configuration.
+type DedupeStrategyName string
+
+const (
+	// DedupeISRC merges the links of the same recording, recognized by their ISRC.
+	// Links without a known ISRC are never merged, so it has no false merges but misses most duplicates
+	// unless the provider APIs or MusicBrainz are enabled.
+	DedupeISRC DedupeStrategyName = "isrc"
+	// DedupeURL merges the links of the same track ID, like a track shared twice or a video shared on YouTube
+	// and YouTube Music, other links are compared by their normalized URL.
+	DedupeURL DedupeStrategyName = "url"
+	// DedupeTitle merges the links with the same title, ignoring case, diacritics, punctuation and bracketed
+	// suffixes like "(Official Video)". It catches the most duplicates, but merges different recordings
+	// of the same song, like a live version and the studio one.
+	DedupeTitle DedupeStrategyName = "title"
+	// DedupeNone keeps every link.
+	DedupeNone DedupeStrategyName = "none"
+)
+
+// titleNoiseRegex matches the bracketed suffixes and punctuation ignored by DedupeTitle.
+var titleNoiseRegex = regexp.MustCompile(`[(\[][^)\]]*[)\]]|[^\p{L}\p{N}\s]`)
+
+// dedupeStrategies are the built-in strategies by name.
+var dedupeStrategies = map[DedupeStrategyName]DedupeStrategy{
+	DedupeISRC: DedupeKeyFunc(func(_ string, md musicextractors.TrackMetadata) string {
+		return md.ISRC
+	}),
+	DedupeURL: DedupeKeyFunc(func(url string, _ musicextractors.TrackMetadata) string {
+		if provider, id, err := musicextractors.ExtractTrackID(url); err == nil {
+			return string(provider) + ":" + id
+		}
+
+		if canonical, err := musicextractors.NormalizeURL(url); err == nil {
+			return canonical
+		}
+
+		return url
+	}),
+	DedupeTitle: DedupeKeyFunc(func(_ string, md musicextractors.TrackMetadata) string {
+		return musicextractors.TitleKey(titleNoiseRegex.ReplaceAllString(md.DisplayTitle(), " "), true)
+	}),
+	DedupeNone: DedupeKeyFunc(func(string, musicextractors.TrackMetadata) string {
+		return ""
+	}),
+}
+
+// DedupeStrategyNames returns the names of every built-in strategy.
+func DedupeStrategyNames() []DedupeStrategyName {
+	return []DedupeStrategyName{DedupeISRC, DedupeURL, DedupeTitle, DedupeNone}
+}
+
+// NewDedupeStrategy returns the built-in strategy with the given name.
+//
+// Returns ErrUnsupportedDedupeStrategy if there is no strategy with that name.
+func NewDedupeStrategy(name DedupeStrategyName) (DedupeStrategy, error) {
+	s, ok := dedupeStrategies[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedDedupeStrategy, name)
+	}
+
+	return s, nil
+}
+
+// rowsFunc calls yield with every row of an export in order, stopping at the first error.
+type rowsFunc func(yield func(parsedMusicLink) error) error
+
+// dedupeKeys returns the identities of a link, links sharing any of them are merged into one row.
+type dedupeKeys func(pml parsedMusicLink) []string
+
+// strategyKeys identifies the links by the key of strategy, across providers they are also identified
+// by the track ID of their URL and of every cross-link, and by their ISRC, so the shares of the same song
+// on different providers are merged even if strategy can't tell they are the same.
+func strategyKeys(strategy DedupeStrategy, acrossProviders bool) dedupeKeys {
+	return func(pml parsedMusicLink) []string {
+		var keys []string
+
+		if key := strategy.Key(pml.URL, pml.Metadata); key != "" {
+			keys = append(keys, key)
+		}
+
+		if !acrossProviders {
+			return keys
+		}
+
+		if pml.Metadata.ISRC != "" {
+			keys = append(keys, "isrc:"+pml.Metadata.ISRC)
+		}
+
+		for _, url := range slices.Concat([]string{pml.URL}, slices.Collect(maps.Values(pml.CrossLinks))) {
+			if provider, id, err := musicextractors.ExtractTrackID(url); err == nil {
+				keys = append(keys, "track:"+string(provider)+":"+id)
+			}
+		}
+
+		return keys
+	}
+}
+
+// mergeDuplicates merges the links sharing a key into the first occurrence of their track.
+//
+// Links without a key are kept as is. The URLs and the cross-links of a merged link fill the provider columns
+// of the first occurrence, unless it already has a link for that provider.
+// Every row counts the links merged into it, itself included, in TimesShared.
+// The links are read twice instead of being kept in memory, only the links of every track are collected.
+func mergeDuplicates(links *linkBuffer, keys dedupeKeys) rowsFunc {
+	return func(yield func(parsedMusicLink) error) error {
+		type track struct {
+			crossLinks map[musicextractors.ExtractProvider]string
+			provider   musicextractors.ExtractProvider
+			url        string
+			shares     int
+			merged     bool
+			// first is the position of the first occurrence, parent the track this one was merged into, nil for the root.
+			first  int
+			parent *track
+		}
+
+		root := func(t *track) *track {
+			for t.parent != nil {
+				t = t.parent
+			}
+
+			return t
+		}
+
+		// fill adds the shared URL and the cross-links of another share to the empty provider columns of r
+		fill := func(r *track, provider musicextractors.ExtractProvider, url string, crossLinks map[musicextractors.ExtractProvider]string) {
+			set := func(p musicextractors.ExtractProvider, u string) {
+				if p == r.provider || u == "" || r.crossLinks[p] != "" {
+					return
+				}
+
+				// Clone before writing, the cross-links map may be shared with the unmerged link
+				if !r.merged {
+					r.crossLinks, r.merged = maps.Clone(r.crossLinks), true
+					if r.crossLinks == nil {
+						r.crossLinks = make(map[musicextractors.ExtractProvider]string, len(crossLinks)+1)
+					}
+				}
+
+				r.crossLinks[p] = u
+			}
+
+			// The shared URL wins over the cross-links of the same share
+			set(provider, url)
+
+			for p, u := range crossLinks {
+				set(p, u)
+			}
+		}
+
+		tracks := map[string]*track{}
+		count := 0
+
+		err := links.each(func(pml parsedMusicLink) error {
+			linkKeys := keys(pml)
+			if len(linkKeys) == 0 {
+				return nil
+			}
+
+			var r *track
+
+			// A link known by several tracks shows they are the same, the later one is merged into the first one
+			for _, key := range linkKeys {
+				t, ok := tracks[key]
+				if !ok {
+					continue
+				}
+
+				t = root(t)
+
+				switch {
+				case r == nil:
+					r = t
+				case t != r:
+					if t.first < r.first {
+						r, t = t, r
+					}
+
+					t.parent = r
+					r.shares += t.shares
+					fill(r, t.provider, t.url, t.crossLinks)
+				}
+			}
+
+			if r == nil {
+				r = &track{crossLinks: pml.CrossLinks, provider: pml.Type, url: pml.URL, first: count}
+				count++
+			} else {
+				fill(r, pml.Type, pml.URL, pml.CrossLinks)
+			}
+
+			r.shares++
+
+			for _, key := range linkKeys {
+				if _, ok := tracks[key]; !ok {
+					tracks[key] = r
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		seen := make(map[*track]struct{}, count)
+
+		return links.each(func(pml parsedMusicLink) error {
+			pml.TimesShared = 1
+
+			// Every key of a link leads to the same track after the merges
+			if linkKeys := keys(pml); len(linkKeys) > 0 {
+				r := root(tracks[linkKeys[0]])
+				if _, dup := seen[r]; dup {
+					return nil
+				}
+
+				seen[r] = struct{}{}
+				pml.CrossLinks, pml.TimesShared = r.crossLinks, r.shares
+			}
+
+			return yield(pml)
+		})
+	}
+}
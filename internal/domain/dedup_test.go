@@ -0,0 +1,311 @@
+package domain
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDedupeStrategies_Key(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		strategy DedupeStrategyName
+		a, b     string
+		mdA, mdB musicextractors.TrackMetadata
+		same     bool
+	}{
+		{
+			name:     "isrc",
+			strategy: DedupeISRC,
+			a:        "https://open.spotify.com/track/a",
+			b:        "https://www.youtube.com/watch?v=b",
+			mdA:      musicextractors.TrackMetadata{ISRC: "GBARL9300135"},
+			mdB:      musicextractors.TrackMetadata{ISRC: "GBARL9300135"},
+			same:     true,
+		},
+		{
+			name:     "isrc unknown",
+			strategy: DedupeISRC,
+			a:        "https://open.spotify.com/track/a",
+			b:        "https://open.spotify.com/track/a",
+		},
+		{
+			name:     "url youtube music",
+			strategy: DedupeURL,
+			a:        "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+			b:        "https://music.youtube.com/watch?v=dQw4w9WgXcQ&si=share",
+			same:     true,
+		},
+		{
+			name:     "url different tracks",
+			strategy: DedupeURL,
+			a:        "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT",
+			b:        "https://open.spotify.com/track/7GhIk7Il098yCjg4BQjzvb",
+		},
+		{
+			name:     "title",
+			strategy: DedupeTitle,
+			a:        "https://open.spotify.com/track/a",
+			b:        "https://www.youtube.com/watch?v=b",
+			mdA:      musicextractors.TrackMetadata{Title: "Never Gonna Give You Up", Artist: "Rick Astley"},
+			mdB:      musicextractors.TrackMetadata{Title: "Rick Astley - Never Gonna Give You Up (Official Video)"},
+			same:     true,
+		},
+		{
+			name:     "title diacritics",
+			strategy: DedupeTitle,
+			a:        "https://open.spotify.com/track/a",
+			b:        "https://www.youtube.com/watch?v=b",
+			mdA:      musicextractors.TrackMetadata{Title: "Déjà Vu", Artist: "Beyoncé"},
+			mdB:      musicextractors.TrackMetadata{Title: "BEYONCE - Deja Vu [Audio]"},
+			same:     true,
+		},
+		{
+			name:     "none",
+			strategy: DedupeNone,
+			a:        "https://open.spotify.com/track/a",
+			b:        "https://open.spotify.com/track/a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := NewDedupeStrategy(tt.strategy)
+			require.NoError(t, err)
+
+			keyA, keyB := s.Key(tt.a, tt.mdA), s.Key(tt.b, tt.mdB)
+			if tt.same {
+				assert.NotEmpty(t, keyA)
+				assert.Equal(t, keyA, keyB)
+
+				return
+			}
+
+			assert.True(t, keyA == "" || keyA != keyB, "%q and %q shouldn't be merged", keyA, keyB)
+		})
+	}
+}
+
+func TestNewDedupeStrategy_Unknown(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewDedupeStrategy("fuzzy")
+	require.ErrorIs(t, err, ErrUnsupportedDedupeStrategy)
+}
+
+func TestMessageProcessor_SummarizeThread_ChannelDedupe(t *testing.T) {
+	t.Parallel()
+
+	none, err := NewDedupeStrategy(DedupeNone)
+	require.NoError(t, err)
+
+	title, err := NewDedupeStrategy(DedupeTitle)
+	require.NoError(t, err)
+
+	smp := NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+			musicextractors.YouTubeProvider: musicextractors.YouTubeURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: func(context.Context, string) (musicextractors.TrackMetadata, error) {
+				return musicextractors.TrackMetadata{Title: "Never Gonna Give You Up", Artist: "Rick Astley"}, nil
+			},
+			musicextractors.YouTubeProvider: staticTitle("Rick Astley - Never Gonna Give You Up (Official Video)"),
+		},
+		Dedupe:        title,
+		ChannelDedupe: map[string]DedupeStrategy{"C-ALL": none},
+		Format:        ExportFormatCSV,
+		Compression:   Compression{Kind: CompressionNone},
+	})
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+		{Msg: slack.Msg{Text: "https://www.youtube.com/watch?v=dQw4w9WgXcQ"}},
+	}
+
+	summarize := func(channelID string) string {
+		summary, sErr := smp.SummarizeThread(t.Context(), msgs, channelID, "1700000000.000100")
+		require.NoError(t, sErr)
+
+		got, rErr := io.ReadAll(summary.Upload.Reader)
+		require.NoError(t, rErr)
+
+		return string(got)
+	}
+
+	const header = "Title;Duration;Shared by;Shared at;Spotify URL;YouTube URL;YouTube Music URL;Mixcloud URL;Audiomack URL;Amazon Music URL;Apple Music URL;Shazam URL;Other video URL;Reference URL\n"
+
+	assert.Equal(t, header+
+		"Rick Astley - Never Gonna Give You Up;;;;https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT;"+
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ;;;;;;;;\n", summarize("C-MERGED"))
+	assert.Equal(t, header+
+		"Rick Astley - Never Gonna Give You Up;;;;https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT;;;;;;;;;\n"+
+		"Rick Astley - Never Gonna Give You Up (Official Video);;;;;https://www.youtube.com/watch?v=dQw4w9WgXcQ;;;;;;;;\n",
+		summarize("C-ALL"))
+}
+
+func TestMessageProcessor_SummarizeThread_TimesSharedColumn(t *testing.T) {
+	t.Parallel()
+
+	url, err := NewDedupeStrategy(DedupeURL)
+	require.NoError(t, err)
+
+	smp := NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider:       musicextractors.SpotifyURLExtractor,
+			musicextractors.YoutTubeMusicProvider: musicextractors.YouTubeMusicURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider:       staticTitle("Spotify Song"),
+			musicextractors.YoutTubeMusicProvider: staticTitle("YouTube Music Song"),
+		},
+		Dedupe:      url,
+		Format:      ExportFormatCSV,
+		TimesShared: true,
+		Compression: Compression{Kind: CompressionNone},
+	})
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+		{Msg: slack.Msg{Text: "https://music.youtube.com/watch?v=dQw4w9WgXcQ"}},
+		{Msg: slack.Msg{Text: "again https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT?si=abc"}},
+		{Msg: slack.Msg{Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+	}
+
+	summary, err := smp.SummarizeThread(t.Context(), msgs, "C-ANY", "1700000000.000100")
+	require.NoError(t, err)
+
+	got, err := io.ReadAll(summary.Upload.Reader)
+	require.NoError(t, err)
+	assert.Equal(t, "Title;Duration;Shared by;Shared at;Times shared;Spotify URL;YouTube URL;YouTube Music URL;Mixcloud URL;Audiomack URL;"+
+		"Amazon Music URL;Apple Music URL;Shazam URL;Other video URL;Reference URL\n"+
+		"Spotify Song;;;;3;https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT;;;;;;;;;\n"+
+		"YouTube Music Song;;;;1;;;https://music.youtube.com/watch?v=dQw4w9WgXcQ;;;;;;;\n", string(got))
+	// Every share stays in the tracks of the summary
+	assert.Len(t, summary.Tracks, 4)
+}
+
+type crossLinkerFunc func(url string) map[musicextractors.ExtractProvider]string
+
+func (f crossLinkerFunc) CrossLink(_ context.Context, url string) (map[musicextractors.ExtractProvider]string, error) {
+	return f(url), nil
+}
+
+func TestMessageProcessor_SummarizeThread_DedupeAcrossProviders(t *testing.T) {
+	t.Parallel()
+
+	const (
+		spotifyURL = "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"
+		youtubeURL = "https://youtu.be/dQw4w9WgXcQ"
+	)
+
+	newProcessor := func(acrossProviders bool) MessageProcessorDomain {
+		return NewSlackMessageProcessor(ProcessorConfig{
+			URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+				musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+				musicextractors.YouTubeProvider: musicextractors.YouTubeURLExtractor,
+			},
+			MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+				musicextractors.SpotifyProvider: staticTitle("Spotify Song"),
+				musicextractors.YouTubeProvider: staticTitle("YouTube Song"),
+			},
+			// Only the Spotify track is known to Odesli, the video is recognized by its ID in the cross-links
+			CrossLinker: crossLinkerFunc(func(url string) map[musicextractors.ExtractProvider]string {
+				if url != spotifyURL {
+					return nil
+				}
+
+				return map[musicextractors.ExtractProvider]string{
+					musicextractors.YouTubeProvider:       "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+					musicextractors.YoutTubeMusicProvider: "https://music.youtube.com/watch?v=dQw4w9WgXcQ",
+				}
+			}),
+			DedupeAcrossProviders: acrossProviders,
+			Format:                ExportFormatCSV,
+			TimesShared:           true,
+			Compression:           Compression{Kind: CompressionNone},
+		})
+	}
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{Text: youtubeURL}},
+		{Msg: slack.Msg{Text: "https://open.spotify.com/track/7GhIk7Il098yCjg4BQjzvb"}},
+		{Msg: slack.Msg{Text: spotifyURL}},
+	}
+
+	summarize := func(acrossProviders bool) string {
+		summary, err := newProcessor(acrossProviders).SummarizeThread(t.Context(), msgs, "C123", "1700000000.000100")
+		require.NoError(t, err)
+
+		got, err := io.ReadAll(summary.Upload.Reader)
+		require.NoError(t, err)
+
+		return string(got)
+	}
+
+	const header = "Title;Duration;Shared by;Shared at;Times shared;Spotify URL;YouTube URL;YouTube Music URL;Mixcloud URL;Audiomack URL;" +
+		"Amazon Music URL;Apple Music URL;Shazam URL;Other video URL;Reference URL\n"
+
+	assert.Equal(t, header+
+		"YouTube Song;;;;1;;https://youtu.be/dQw4w9WgXcQ;;;;;;;;\n"+
+		"Spotify Song;;;;1;https://open.spotify.com/track/7GhIk7Il098yCjg4BQjzvb;;;;;;;;;\n"+
+		"Spotify Song;;;;1;"+spotifyURL+";https://www.youtube.com/watch?v=dQw4w9WgXcQ;https://music.youtube.com/watch?v=dQw4w9WgXcQ;;;;;;;\n",
+		summarize(false))
+	// The song is merged into its first share, the columns nobody shared are filled from the cross-links
+	assert.Equal(t, header+
+		"YouTube Song;;;;2;"+spotifyURL+";https://youtu.be/dQw4w9WgXcQ;https://music.youtube.com/watch?v=dQw4w9WgXcQ;;;;;;;\n"+
+		"Spotify Song;;;;1;https://open.spotify.com/track/7GhIk7Il098yCjg4BQjzvb;;;;;;;;;\n",
+		summarize(true))
+}
+
+func TestMergeDuplicates_JoinsTracksLinkedLater(t *testing.T) {
+	t.Parallel()
+
+	links := newLinkBuffer(0)
+	t.Cleanup(func() { _ = links.close() })
+
+	// The first two links look different until the third one shows both are the same song
+	for _, pml := range []parsedMusicLink{
+		{URL: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT", Type: musicextractors.SpotifyProvider},
+		{URL: "https://www.youtube.com/watch?v=dQw4w9WgXcQ", Type: musicextractors.YouTubeProvider},
+		{
+			URL:      "https://music.apple.com/us/album/whenever-you-need-somebody/1558533900?i=1558534271",
+			Type:     musicextractors.AppleMusicProvider,
+			Metadata: musicextractors.TrackMetadata{ISRC: "GBARL9300135"},
+			CrossLinks: map[musicextractors.ExtractProvider]string{
+				musicextractors.SpotifyProvider: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT",
+				musicextractors.YouTubeProvider: "https://youtu.be/dQw4w9WgXcQ",
+			},
+		},
+		{URL: "https://open.spotify.com/track/7GhIk7Il098yCjg4BQjzvb", Type: musicextractors.SpotifyProvider},
+	} {
+		require.NoError(t, links.add(pml))
+	}
+
+	var rows []parsedMusicLink
+
+	require.NoError(t, mergeDuplicates(links, strategyKeys(dedupeStrategies[DedupeISRC], true))(func(pml parsedMusicLink) error {
+		rows = append(rows, pml)
+
+		return nil
+	}))
+
+	require.Len(t, rows, 2)
+	assert.Equal(t, 3, rows[0].TimesShared)
+	assert.Equal(t, map[musicextractors.ExtractProvider]string{
+		musicextractors.SpotifyProvider:    "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT",
+		musicextractors.YouTubeProvider:    "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		musicextractors.AppleMusicProvider: "https://music.apple.com/us/album/whenever-you-need-somebody/1558533900?i=1558534271",
+	}, rows[0].links())
+	assert.Equal(t, 1, rows[1].TimesShared)
+}
@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack"
+)
+
+// digestMaxBytes caps the conversation sent to the text summarizer, so a huge thread doesn't exceed the context
+// of the model, the newest messages are kept.
+const digestMaxBytes = 24 << 10
+
+// conversation returns the messages as "author: text" lines for the text summarizer, the newest ones within digestMaxBytes.
+// The lines have no author if userName is nil.
+func conversation(msgs []slack.Message, userName func(string) string) string {
+	lines := make([]string, 0, len(msgs))
+	size := 0
+
+	for i := len(msgs) - 1; i >= 0; i-- {
+		m := msgs[i]
+		if skipMessage(m) {
+			continue
+		}
+
+		line := strings.Join(strings.Fields(musicextractors.UnwrapSlackLinks(m.Text)), " ")
+
+		if userName != nil {
+			author := m.Username
+			if m.User != "" {
+				author = userName(m.User)
+			}
+
+			line = author + ": " + line
+		}
+
+		if size += len(line) + 1; size > digestMaxBytes && len(lines) > 0 {
+			break
+		}
+
+		lines = append(lines, line)
+	}
+
+	// The lines were collected from the newest one
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// digest returns a prose recap of the thread with the text summarizer, empty if there's none configured.
+// The recap of an anonymous summary is made without the names of the users, so it can't name them.
+//
+// The recap is best-effort, the summary is posted without it if the model fails.
+func (s *messageProcessorDomain) digest(ctx context.Context, msgs []slack.Message, anonymous bool) string {
+	if s.textSummarizer == nil {
+		return ""
+	}
+
+	var userName func(string) string
+	if !anonymous {
+		userName = s.userNameFunc(ctx)
+	}
+
+	text := conversation(msgs, userName)
+	if text == "" {
+		return ""
+	}
+
+	recap, err := s.textSummarizer.Summarize(ctx, text)
+	if err != nil {
+		return ""
+	}
+
+	return recap
+}
+
+// digestNote formats the recap for the summary comment, empty if there's none.
+func digestNote(recap string) string {
+	if recap == "" {
+		return ""
+	}
+
+	return "\n\n" + recap
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/Shikachuu/wap-bot/pkg/textsummarizer"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type textSummarizerFunc func(ctx context.Context, conversation string) (string, error)
+
+func (f textSummarizerFunc) Summarize(ctx context.Context, conversation string) (string, error) {
+	return f(ctx, conversation)
+}
+
+func TestConversation_KeepsNewestMessages(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("a", digestMaxBytes/2)
+	msgs := []slack.Message{
+		{Msg: slack.Msg{User: "U1", Text: "oldest " + long}},
+		{Msg: slack.Msg{User: "U1", Text: "older " + long}},
+		{Msg: slack.Msg{Text: "   "}},
+		{Msg: slack.Msg{Username: "bot", Text: "check <https://open.spotify.com/track/1|this>\n  out"}},
+	}
+
+	got := conversation(msgs, func(id string) string { return "name-" + id })
+
+	assert.Equal(t, "name-U1: older "+long+"\nbot: check https://open.spotify.com/track/1 out", got)
+}
+
+func TestConversation_Anonymous(t *testing.T) {
+	t.Parallel()
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{User: "U1", Text: "check <https://open.spotify.com/track/1|this>"}},
+		{Msg: slack.Msg{Username: "bot", Text: "nice"}},
+	}
+
+	assert.Equal(t, "check https://open.spotify.com/track/1\nnice", conversation(msgs, nil))
+}
+
+func TestMessageProcessor_SummarizeThread_Digest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		summarizer  textsummarizer.TextSummarizer
+		wantDigest  string
+		wantComment string
+	}{
+		{
+			name:        "no summarizer",
+			wantComment: "Found 1 music URLs in this thread",
+		},
+		{
+			name: "recap appended",
+			summarizer: textSummarizerFunc(func(_ context.Context, conversation string) (string, error) {
+				if conversation != "U1: https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT banger" {
+					return "", textsummarizer.ErrEmptySummary
+				}
+
+				return "Everyone loved the banger.", nil
+			}),
+			wantDigest:  "Everyone loved the banger.",
+			wantComment: "Found 1 music URLs in this thread\n\nEveryone loved the banger.",
+		},
+		{
+			name: "failed model",
+			summarizer: textSummarizerFunc(func(context.Context, string) (string, error) {
+				return "", textsummarizer.ErrRequestFailed
+			}),
+			wantComment: "Found 1 music URLs in this thread",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			smp := NewSlackMessageProcessor(ProcessorConfig{
+				URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+					musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+				},
+				MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+					musicextractors.SpotifyProvider: staticTitle("Never Gonna Give You Up"),
+				},
+				TextSummarizer: tt.summarizer,
+				Format:         ExportFormatCSV,
+				Compression:    Compression{Kind: CompressionNone},
+			})
+
+			msgs := []slack.Message{
+				{Msg: slack.Msg{User: "U1", Text: "<https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT> banger"}},
+			}
+
+			summary, err := smp.SummarizeThread(t.Context(), msgs, "C1", "1700000000.000100")
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantDigest, summary.Digest)
+			assert.Equal(t, tt.wantComment, summary.Upload.InitialComment)
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "errors"
+
+var (
+	// ErrUnsupportedFormat returned by SummarizeThread if the processor is configured with an unknown export format.
+	ErrUnsupportedFormat = errors.New("unsupported export format")
+	// ErrUnsupportedCompression returned by SummarizeThread if the processor is configured with an unknown compression.
+	ErrUnsupportedCompression = errors.New("unsupported compression")
+	// ErrUnsupportedDedupeStrategy returned by NewDedupeStrategy if there is no built-in strategy with the given name.
+	ErrUnsupportedDedupeStrategy = errors.New("unsupported dedupe strategy")
+)
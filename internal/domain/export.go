@@ -0,0 +1,247 @@
+package domain
+
+import (
+	"context"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+)
+
+// SummaryRow is a row of a summary file, a track with every share of it merged into its first occurrence.
+type SummaryRow struct {
+	// Links are the URLs of the track by provider, the shared ones and the cross-linked ones.
+	Links map[musicextractors.ExtractProvider]string
+	// Title is the display title of the track, empty if it couldn't be resolved.
+	Title    string
+	URL      string
+	Provider musicextractors.ExtractProvider
+	// MessageTS and UserID identify the message the track was first shared in.
+	MessageTS string
+	UserID    string
+	Metadata  musicextractors.TrackMetadata
+	// TimesShared is the number of times the track was shared in the thread.
+	TimesShared int
+}
+
+// SummaryRows calls yield with every row of a summary in order, stopping at the first error.
+type SummaryRows func(yield func(SummaryRow) error) error
+
+// SummaryFile is the content of a summary file.
+type SummaryFile struct {
+	Rows      SummaryRows
+	ChannelID string
+	ThreadTS  string
+	// UserName returns the name of the user who shared a track, the user ID if it couldn't be resolved.
+	UserName func(userID string) string
+	// Anonymous leaves out who shared the tracks and when, the encoders drop the columns and fields of them.
+	Anonymous bool
+	// Skipped are the links whose title couldn't be resolved, only set if the skipped links report is enabled.
+	// The formats with room for a second table list them after the rows, the rest ignores them.
+	Skipped []FailedTitle
+}
+
+// SummaryEncoder writes a summary into a file of its format.
+type SummaryEncoder interface {
+	// Encode writes every row of the summary into w one at a time, so the file is never built in memory at once.
+	//
+	// Returns an error if any. Writes may go unchecked, the spool w writes to keeps the first failed one and fails the summary with it.
+	Encode(w io.Writer, file SummaryFile) error
+}
+
+// summaryRows converts the merged links to their exported rows.
+func summaryRows(rows rowsFunc) SummaryRows {
+	return func(yield func(SummaryRow) error) error {
+		return rows(func(pml parsedMusicLink) error {
+			return yield(SummaryRow{
+				Links:       pml.links(),
+				Title:       pml.Title,
+				URL:         pml.URL,
+				Provider:    pml.Type,
+				MessageTS:   pml.MessageTS,
+				UserID:      pml.UserID,
+				Metadata:    pml.Metadata,
+				TimesShared: pml.TimesShared,
+			})
+		})
+	}
+}
+
+// summaryTable is the layout of the tabular formats, like CSV and XLSX, every row is a track with a column per provider.
+type summaryTable struct {
+	// columns are the providers of every URL column, the first one with a link fills the cell.
+	columns     [][]musicextractors.ExtractProvider
+	header      []string
+	artwork     bool
+	timesShared bool
+	genre       bool
+	// anonymous leaves out the shared by and shared at columns.
+	anonymous bool
+}
+
+// newSummaryTable creates the layout of the configured processor, the header starts with the title, duration,
+// poster, time shared and optional artwork, times shared and genre columns, followed by the fixed provider columns
+// and a column for every other configured provider ordered by name.
+func newSummaryTable(
+	processors map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc,
+	artwork, timesShared, genre bool,
+) summaryTable {
+	t := summaryTable{
+		columns:     make([][]musicextractors.ExtractProvider, 0, len(csvColumns)),
+		header:      []string{"Title", "Duration", "Shared by", "Shared at"},
+		artwork:     artwork,
+		timesShared: timesShared,
+	}
+
+	if artwork {
+		t.header = append(t.header, "Artwork URL")
+	}
+
+	if timesShared {
+		t.header = append(t.header, "Times shared")
+	}
+
+	if genre {
+		t = t.withGenre()
+	}
+
+	for _, c := range csvColumns {
+		t.columns = append(t.columns, c.providers)
+		t.header = append(t.header, c.header)
+	}
+
+	custom := slices.Sorted(maps.Keys(processors))
+	custom = slices.DeleteFunc(custom, func(p musicextractors.ExtractProvider) bool {
+		return slices.ContainsFunc(csvColumns, func(c csvColumn) bool { return slices.Contains(c.providers, p) })
+	})
+
+	for _, p := range custom {
+		t.columns = append(t.columns, []musicextractors.ExtractProvider{p})
+		t.header = append(t.header, string(p)+" URL")
+	}
+
+	return t
+}
+
+// withGenre returns the layout with the genre column after the optional columns before it, if it's not there already.
+func (t summaryTable) withGenre() summaryTable {
+	if t.genre {
+		return t
+	}
+
+	at := 4
+	if t.anonymous {
+		at -= 2
+	}
+
+	if t.artwork {
+		at++
+	}
+
+	if t.timesShared {
+		at++
+	}
+
+	t.header = slices.Insert(slices.Clone(t.header), at, "Genre")
+	t.genre = true
+
+	return t
+}
+
+// forFile returns the layout of file, without the shared by and shared at columns if it's anonymous.
+func (t summaryTable) forFile(file SummaryFile) summaryTable {
+	if !file.Anonymous || t.anonymous {
+		return t
+	}
+
+	// The attribution columns follow the title and the duration
+	t.header = slices.Delete(slices.Clone(t.header), 2, 4)
+	t.anonymous = true
+
+	return t
+}
+
+// appendCells appends the cells of a row to dst in the order of the header.
+func (t summaryTable) appendCells(dst []string, row SummaryRow, userName func(string) string) []string {
+	dst = append(dst, row.Title, formatDuration(row.Metadata.DurationSeconds))
+
+	if !t.anonymous {
+		sharedBy := ""
+		if row.UserID != "" {
+			sharedBy = userName(row.UserID)
+		}
+
+		dst = append(dst, sharedBy, sharedAt(row.MessageTS))
+	}
+
+	if t.artwork {
+		dst = append(dst, row.Metadata.ArtworkURL)
+	}
+
+	if t.timesShared {
+		dst = append(dst, strconv.Itoa(row.TimesShared))
+	}
+
+	if t.genre {
+		dst = append(dst, strings.Join(row.Metadata.Genres, ", "))
+	}
+
+	for _, column := range t.columns {
+		dst = append(dst, columnLink(row.Links, column))
+	}
+
+	return dst
+}
+
+// sharedAt formats the time of a Slack message timestamp for the summaries, malformed timestamps are empty.
+func sharedAt(ts string) string {
+	t := messageTime(ts)
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(messageTimeLayout)
+}
+
+// builtInEncoders returns the encoders of the tabular, JSON and playlist formats,
+// the transcript renders the whole thread instead of the rows, so it has none.
+func (s *messageProcessorDomain) builtInEncoders() map[ExportFormat]SummaryEncoder {
+	table := newSummaryTable(s.processors, s.artwork, s.timesShared, s.genre)
+
+	return map[ExportFormat]SummaryEncoder{
+		ExportFormatCSV:      csvEncoder{table: table, options: s.csv},
+		ExportFormatJSON:     jsonEncoder{},
+		ExportFormatJSONL:    jsonlEncoder{},
+		ExportFormatMarkdown: markdownEncoder{table: table},
+		ExportFormatHTML:     htmlEncoder{table: table},
+		ExportFormatXLSX:     xlsxEncoder{table: table},
+		ExportFormatM3U8:     m3uEncoder{},
+		ExportFormatXSPF:     xspfEncoder{},
+	}
+}
+
+// userNameFunc returns a lookup of the names of the users who shared the tracks of a summary,
+// every user is resolved once, users without a resolvable name are listed by their ID.
+func (s *messageProcessorDomain) userNameFunc(ctx context.Context) func(string) string {
+	names := map[string]string{}
+
+	return func(userID string) string {
+		if name, ok := names[userID]; ok {
+			return name
+		}
+
+		names[userID] = userID
+
+		// A missing name doesn't fail the summary, the ID still tells the curators who shared the track
+		if s.users != nil {
+			if name, err := s.users.UserName(ctx, userID); err == nil && name != "" {
+				names[userID] = name
+			}
+		}
+
+		return names[userID]
+	}
+}
@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// utf8BOM is the byte order mark Excel needs to read a CSV file as UTF-8 instead of the legacy code page.
+const utf8BOM = "\uFEFF"
+
+// ErrInvalidCSVDelimiter returned by ParseCSVDelimiter if the delimiter can't separate CSV columns.
+var ErrInvalidCSVDelimiter = errors.New("invalid csv delimiter")
+
+// CSVOptions are the settings of the CSV summaries, so spreadsheet apps like Excel open them correctly.
+type CSVOptions struct {
+	// Delimiter separates the columns, a semicolon if zero.
+	Delimiter rune
+	// BOM starts the file with a UTF-8 byte order mark, without it Excel mangles the non-ASCII titles.
+	BOM bool
+	// CRLF ends the lines with \r\n instead of \n.
+	CRLF bool
+}
+
+// with returns the options with the ones set in override on top, flags can only be turned on.
+func (o CSVOptions) with(override CSVOptions) CSVOptions {
+	if override.Delimiter != 0 {
+		o.Delimiter = override.Delimiter
+	}
+
+	o.BOM = o.BOM || override.BOM
+	o.CRLF = o.CRLF || override.CRLF
+
+	return o
+}
+
+// ParseCSVDelimiter parses a single character delimiter, or one of the names tab, comma, semicolon and pipe.
+//
+// Returns the delimiter or ErrInvalidCSVDelimiter.
+func ParseCSVDelimiter(raw string) (rune, error) {
+	names := map[string]rune{"tab": '\t', "comma": ',', "semicolon": ';', "pipe": '|'}
+
+	if r, ok := names[strings.ToLower(raw)]; ok {
+		return r, nil
+	}
+
+	r, size := utf8.DecodeRuneInString(raw)
+	if size == 0 || size != len(raw) || r == utf8.RuneError || strings.ContainsRune("\"\r\n", r) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidCSVDelimiter, raw)
+	}
+
+	return r, nil
+}
+
+// csvEncoder writes the summaries as a CSV with a column per provider, semicolon separated by default.
+type csvEncoder struct {
+	table   summaryTable
+	options CSVOptions
+}
+
+var _ SummaryEncoder = csvEncoder{}
+
+// Encode writes every row into a CSV file with the title, the duration, who shared it and when unless the file is anonymous,
+// and the URL of every provider column.
+func (e csvEncoder) Encode(out io.Writer, file SummaryFile) error {
+	if e.options.BOM {
+		_, _ = io.WriteString(out, utf8BOM)
+	}
+
+	w := csv.NewWriter(out)
+	w.Comma = ';'
+	w.UseCRLF = e.options.CRLF
+
+	if e.options.Delimiter != 0 {
+		w.Comma = e.options.Delimiter
+	}
+
+	table := e.table.forFile(file)
+
+	err := w.Write(table.header)
+	if err != nil {
+		return fmt.Errorf("appending csv line: %w", err)
+	}
+
+	row, _ := rowPool.Get().(*[]string)
+	defer rowPool.Put(row)
+
+	err = file.Rows(func(r SummaryRow) error {
+		*row = table.appendCells((*row)[:0], r, file.UserName)
+
+		if lErr := w.Write(*row); lErr != nil {
+			return fmt.Errorf("appending csv line: %w", lErr)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	w.Flush()
+
+	if err = w.Error(); err != nil {
+		return fmt.Errorf("flushing csv buffer: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageProcessor_SummarizeThreadAs_CSVOptions(t *testing.T) {
+	t.Parallel()
+
+	columns := []string{
+		"Title", "Duration", "Shared by", "Shared at", "Spotify URL", "YouTube URL", "YouTube Music URL", "Mixcloud URL",
+		"Audiomack URL", "Amazon Music URL", "Apple Music URL", "Shazam URL", "Other video URL", "Reference URL",
+	}
+	row := append([]string{"Spöng", "", "U1", "2023-11-14 22:13 UTC", "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"},
+		make([]string, 9)...)
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{User: "U1", Timestamp: "1700000000.000200", Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+	}
+
+	tests := []struct {
+		name       string
+		sep        string
+		eol        string
+		configured CSVOptions
+		override   CSVOptions
+	}{
+		{
+			name:       "configured options",
+			configured: CSVOptions{Delimiter: ',', BOM: true},
+			sep:        ",",
+			eol:        "\n",
+		},
+		{
+			name:       "options of the summary on top of the configured ones",
+			configured: CSVOptions{Delimiter: ',', BOM: true},
+			override:   CSVOptions{Delimiter: '\t', CRLF: true},
+			sep:        "\t",
+			eol:        "\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			smp := NewSlackMessageProcessor(ProcessorConfig{
+				URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+					musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+				},
+				MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+					musicextractors.SpotifyProvider: staticTitle("Spöng"),
+				},
+				Format:      ExportFormatCSV,
+				CSV:         tt.configured,
+				Compression: Compression{Kind: CompressionNone},
+			})
+
+			summary, err := smp.SummarizeThreadAs(t.Context(), msgs, "C123", "1700000000.000100", SummaryOptions{Format: ExportFormatCSV, CSV: tt.override})
+			require.NoError(t, err)
+
+			got, err := io.ReadAll(summary.Upload.Reader)
+			require.NoError(t, err)
+			// Excel needs the byte order mark to read the title as UTF-8
+			assert.Equal(t, "\uFEFF"+strings.Join(columns, tt.sep)+tt.eol+strings.Join(row, tt.sep)+tt.eol, string(got))
+		})
+	}
+}
+
+func TestParseCSVDelimiter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		raw     string
+		want    rune
+		wantErr bool
+	}{
+		{raw: ",", want: ','},
+		{raw: "tab", want: '\t'},
+		{raw: "Semicolon", want: ';'},
+		{raw: "|", want: '|'},
+		{raw: "", wantErr: true},
+		{raw: ",;", wantErr: true},
+		{raw: `"`, wantErr: true},
+		{raw: "\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseCSVDelimiter(tt.raw)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidCSVDelimiter)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCSVEncoder_Encode_Anonymous(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	require.NoError(t, csvEncoder{table: newSummaryTable(nil, false, true, false)}.Encode(&buf, attributedFile(true)))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], "Title;Duration;Times shared;Spotify URL;"))
+	assert.True(t, strings.HasPrefix(lines[1], "Spöng;;0;https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT;"))
+	assertAnonymous(t, buf.String())
+}
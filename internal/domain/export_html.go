@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+)
+
+// htmlStyle is the stylesheet of the HTML report, inlined so the file is readable on its own.
+const htmlStyle = `body{font-family:system-ui,-apple-system,"Segoe UI",sans-serif;margin:2rem auto;max-width:72rem;padding:0 1rem;color:#1d1c1d;background:#fafafa}
+h1{font-size:1.5rem;margin-bottom:.25rem}h2{font-size:1.2rem;margin-top:2rem}.meta{color:#616061;margin-top:0}
+table{border-collapse:collapse;width:100%;background:#fff;box-shadow:0 1px 3px rgba(0,0,0,.08)}
+th,td{padding:.5rem .75rem;text-align:left;vertical-align:middle;border-bottom:1px solid #eee}th{background:#f4f4f4;font-weight:600}
+td.art{width:56px}td.art img{width:48px;height:48px;object-fit:cover;border-radius:4px;display:block}
+a{color:#1264a3;text-decoration:none}a:hover{text-decoration:underline}.artist{color:#616061;font-size:.9em}
+.badge{display:inline-block;margin:.1rem .2rem .1rem 0;padding:.15rem .5rem;border-radius:999px;font-size:.8em;color:#fff;background:#616061}
+.badge:hover{text-decoration:none;opacity:.85}.badge-spotify{background:#1db954}.badge-youtube{background:#f00}.badge-youtube-music{background:#c00}
+.badge-mixcloud{background:#5000ff}.badge-audiomack{background:#ffa200}.badge-amazon-music{background:#00a8e1}.badge-apple-music{background:#fa243c}
+.badge-shazam{background:#08f}.badge-vimeo{background:#1ab7ea}.badge-dailymotion{background:#0d0d0d}.badge-lastfm{background:#d51007}.badge-discogs{background:#333}`
+
+// htmlEncoder writes the summaries as a standalone HTML report, with artwork thumbnails and a badge per provider link.
+type htmlEncoder struct {
+	table summaryTable
+}
+
+var _ SummaryEncoder = htmlEncoder{}
+
+// htmlURL returns the escaped URL for a link or an image, empty unless it's a web URL, so no script can run from it.
+func htmlURL(u string) string {
+	if !strings.HasPrefix(u, "https://") && !strings.HasPrefix(u, "http://") {
+		return ""
+	}
+
+	return html.EscapeString(u)
+}
+
+// providers returns the providers of the links in the order of the table columns, the rest ordered by name.
+func (e htmlEncoder) providers(links map[musicextractors.ExtractProvider]string) []musicextractors.ExtractProvider {
+	ordered := make([]musicextractors.ExtractProvider, 0, len(links))
+
+	for _, column := range e.table.columns {
+		for _, p := range column {
+			if links[p] != "" {
+				ordered = append(ordered, p)
+			}
+		}
+	}
+
+	for _, p := range slices.Sorted(maps.Keys(links)) {
+		if links[p] != "" && !slices.Contains(ordered, p) {
+			ordered = append(ordered, p)
+		}
+	}
+
+	return ordered
+}
+
+// writeHeader writes the document head and the header row of the tracks table.
+func (e htmlEncoder) writeHeader(w *bufio.Writer, file SummaryFile) {
+	title := html.EscapeString(fmt.Sprintf("Music shared in %s", file.ChannelID))
+
+	fmt.Fprintf(w, "<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n"+
+		"<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n<title>%s</title>\n<style>\n%s\n</style>\n</head>\n<body>\n", title, htmlStyle)
+	fmt.Fprintf(w, "<h1>%s</h1>\n", title)
+
+	if started := sharedAt(file.ThreadTS); started != "" {
+		fmt.Fprintf(w, "<p class=\"meta\">Thread started %s</p>\n", started)
+	}
+
+	w.WriteString("<table>\n<thead><tr><th></th><th>Title</th><th>Duration</th>")
+
+	if !e.table.anonymous {
+		w.WriteString("<th>Shared by</th><th>Shared at</th>")
+	}
+
+	if e.table.timesShared {
+		w.WriteString("<th>Times shared</th>")
+	}
+
+	if e.table.genre {
+		w.WriteString("<th>Genre</th>")
+	}
+
+	w.WriteString("<th>Links</th></tr></thead>\n<tbody>\n")
+}
+
+// writeRow writes a track as a row of the table, its title links to the shared URL.
+func (e htmlEncoder) writeRow(w *bufio.Writer, r SummaryRow, userName func(string) string) {
+	w.WriteString("<tr><td class=\"art\">")
+
+	if src := htmlURL(r.Metadata.ArtworkURL); src != "" {
+		fmt.Fprintf(w, "<img src=\"%s\" alt=\"\" loading=\"lazy\">", src)
+	}
+
+	title := r.Title
+	if title == "" {
+		title = r.URL
+	}
+
+	fmt.Fprintf(w, "</td><td><a href=\"%s\">%s</a>", htmlURL(r.URL), html.EscapeString(title))
+
+	if r.Metadata.Artist != "" {
+		fmt.Fprintf(w, "<div class=\"artist\">%s</div>", html.EscapeString(r.Metadata.Artist))
+	}
+
+	fmt.Fprintf(w, "</td><td>%s</td>", formatDuration(r.Metadata.DurationSeconds))
+
+	if !e.table.anonymous {
+		sharedBy := ""
+		if r.UserID != "" {
+			sharedBy = userName(r.UserID)
+		}
+
+		fmt.Fprintf(w, "<td>%s</td><td>%s</td>", html.EscapeString(sharedBy), sharedAt(r.MessageTS))
+	}
+
+	if e.table.timesShared {
+		w.WriteString("<td>" + strconv.Itoa(r.TimesShared) + "</td>")
+	}
+
+	if e.table.genre {
+		w.WriteString("<td>" + html.EscapeString(strings.Join(r.Metadata.Genres, ", ")) + "</td>")
+	}
+
+	w.WriteString("<td>")
+
+	for _, p := range e.providers(r.Links) {
+		provider := html.EscapeString(string(p))
+		fmt.Fprintf(w, "<a class=\"badge badge-%s\" href=\"%s\">%s</a>", provider, htmlURL(r.Links[p]), provider)
+	}
+
+	w.WriteString("</td></tr>\n")
+}
+
+// Encode writes every row into the table of the report, followed by the table of the skipped links if there are any.
+func (e htmlEncoder) Encode(w io.Writer, file SummaryFile) error {
+	buff := bufio.NewWriter(w)
+	e.table = e.table.forFile(file)
+
+	e.writeHeader(buff, file)
+
+	tracks := 0
+
+	err := file.Rows(func(r SummaryRow) error {
+		tracks++
+
+		e.writeRow(buff, r, file.UserName)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(buff, "</tbody>\n</table>\n<p class=\"meta\">%d tracks</p>\n", tracks)
+
+	if len(file.Skipped) > 0 {
+		buff.WriteString("<h2>Skipped links</h2>\n<table>\n<thead><tr>")
+
+		for _, h := range skippedColumns(file) {
+			buff.WriteString("<th>" + html.EscapeString(h) + "</th>")
+		}
+
+		buff.WriteString("</tr></thead>\n<tbody>\n")
+
+		for _, f := range file.Skipped {
+			buff.WriteString("<tr>")
+
+			for _, c := range skippedCells(f, file) {
+				buff.WriteString("<td>" + html.EscapeString(c) + "</td>")
+			}
+
+			buff.WriteString("</tr>\n")
+		}
+
+		buff.WriteString("</tbody>\n</table>\n")
+	}
+
+	buff.WriteString("</body>\n</html>\n")
+
+	if err = buff.Flush(); err != nil {
+		return fmt.Errorf("flushing html report: %w", err)
+	}
+
+	return nil
+}
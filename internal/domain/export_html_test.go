@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTMLEncoder_Encode(t *testing.T) {
+	t.Parallel()
+
+	rows := []SummaryRow{
+		{
+			Title:    `<script>alert("x")</script>`,
+			URL:      "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT",
+			Provider: musicextractors.SpotifyProvider,
+			Links: map[musicextractors.ExtractProvider]string{
+				musicextractors.YouTubeProvider: "https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=1",
+				musicextractors.SpotifyProvider: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT",
+			},
+			MessageTS: "1700000000.000200",
+			UserID:    "U1",
+			Metadata: musicextractors.TrackMetadata{
+				Artist:          "Rick & Astley",
+				ArtworkURL:      "javascript:alert(1)",
+				DurationSeconds: 213,
+			},
+		},
+		{URL: "https://www.mixcloud.com/dj/set/", Provider: musicextractors.MixcloudProvider, Metadata: musicextractors.TrackMetadata{ArtworkURL: "https://img/a.jpg"}},
+	}
+
+	file := SummaryFile{
+		Rows: func(yield func(SummaryRow) error) error {
+			for _, r := range rows {
+				if err := yield(r); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+		ChannelID: "C123",
+		ThreadTS:  "1700000000.000100",
+		UserName:  func(id string) string { return "<" + id + ">" },
+		Skipped: []FailedTitle{{
+			Track: Track{URL: "https://youtu.be/x?a=1&b=2", Provider: musicextractors.YouTubeProvider},
+			Kind:  musicextractors.ErrorKindTitleNotFound,
+		}},
+	}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, htmlEncoder{table: newSummaryTable(nil, false, false, false)}.Encode(&buf, file))
+
+	got := buf.String()
+
+	assert.Contains(t, got, "<title>Music shared in C123</title>")
+	assert.Contains(t, got, "Thread started 2023-11-14 22:13 UTC")
+	// Text is escaped and only web URLs are linked
+	assert.Contains(t, got, `<a href="https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT">&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;</a>`)
+	assert.Contains(t, got, `<div class="artist">Rick &amp; Astley</div>`)
+	assert.NotContains(t, got, "javascript:")
+	assert.Contains(t, got, "<td>3:33</td><td>&lt;U1&gt;</td><td>2023-11-14 22:13 UTC</td>")
+	// Badges follow the column order
+	assert.Contains(t, got, `<a class="badge badge-spotify" href="https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT">spotify</a>`+
+		`<a class="badge badge-youtube" href="https://www.youtube.com/watch?v=dQw4w9WgXcQ&amp;t=1">youtube</a>`)
+	// An unresolved title shows the URL
+	assert.Contains(t, got, `<img src="https://img/a.jpg" alt="" loading="lazy"></td><td><a href="https://www.mixcloud.com/dj/set/">https://www.mixcloud.com/dj/set/</a>`)
+	assert.Contains(t, got, "<p class=\"meta\">2 tracks</p>")
+	assert.Contains(t, got, "<h2>Skipped links</h2>")
+	assert.Contains(t, got, "<td>https://youtu.be/x?a=1&amp;b=2</td>")
+	assert.True(t, bytes.HasSuffix(buf.Bytes(), []byte("</body>\n</html>\n")))
+}
+
+func TestHTMLEncoder_Encode_Anonymous(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	require.NoError(t, htmlEncoder{table: newSummaryTable(nil, false, false, true)}.Encode(&buf, attributedFile(true)))
+
+	got := buf.String()
+	assert.Contains(t, got, "<th>Title</th><th>Duration</th><th>Genre</th><th>Links</th>")
+	assert.Contains(t, got, "</a></td><td></td><td></td><td>")
+	assert.Contains(t, got, "<th>URL</th><th>Provider</th><th>Reason</th></tr>")
+	assertAnonymous(t, got)
+}
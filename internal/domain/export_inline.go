@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// inlineMessageLimit is the maximum length of an inline summary message, well below the 4000 characters
+// Slack recommends for a message, leaving room for the comment prepended to the first one.
+const inlineMessageLimit = 3000
+
+// inlineRow renders a row as a numbered mrkdwn line, the title links to the shared URL,
+// the sharer is left out if userName is nil.
+func inlineRow(i int, row SummaryRow, userName func(string) string) string {
+	link := "<" + row.URL + ">"
+	if row.Title != "" {
+		link = "<" + row.URL + "|" + EscapeMrkdwn(row.Title) + ">"
+	}
+
+	line := fmt.Sprintf("%d. %s", i, link)
+
+	if d := formatDuration(row.Metadata.DurationSeconds); d != "" {
+		line += " (" + d + ")"
+	}
+
+	if row.UserID != "" && userName != nil {
+		line += " · shared by " + EscapeMrkdwn(userName(row.UserID))
+	}
+
+	if row.TimesShared > 1 {
+		line += fmt.Sprintf(" · %d shares", row.TimesShared)
+	}
+
+	return line
+}
+
+// createInline renders every row as a line of a mrkdwn message, split into several messages
+// of at most inlineMessageLimit characters without splitting a row.
+func createInline(file SummaryFile) ([]string, error) {
+	var (
+		messages []string
+		current  strings.Builder
+		i        int
+	)
+
+	userName := file.UserName
+	if file.Anonymous {
+		userName = nil
+	}
+
+	err := file.Rows(func(row SummaryRow) error {
+		i++
+		line := inlineRow(i, row, userName)
+
+		if current.Len() > 0 && current.Len()+len(line)+1 > inlineMessageLimit {
+			messages = append(messages, current.String())
+			current.Reset()
+		}
+
+		if current.Len() > 0 {
+			current.WriteString("\n")
+		}
+
+		current.WriteString(line)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if current.Len() > 0 {
+		messages = append(messages, current.String())
+	}
+
+	return messages, nil
+}
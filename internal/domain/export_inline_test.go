@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageProcessor_SummarizeThreadAs_Inline(t *testing.T) {
+	t.Parallel()
+
+	smp := NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+			musicextractors.YouTubeProvider: musicextractors.YouTubeURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: func(_ context.Context, _ string) (musicextractors.TrackMetadata, error) {
+				return musicextractors.TrackMetadata{Title: "Salt & Pepper", DurationSeconds: 213}, nil
+			},
+			musicextractors.YouTubeProvider: staticTitle("YouTube Song"),
+		},
+		UserResolver: stubUsers{"U1": "ada"},
+		Format:       ExportFormatCSV,
+		Compression:  Compression{Kind: CompressionNone},
+	})
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{User: "U1", Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+		{Msg: slack.Msg{Text: "https://www.youtube.com/watch?v=dQw4w9WgXcQ"}},
+	}
+
+	summary, err := smp.SummarizeThreadAs(t.Context(), msgs, "C123", "1700000000.000100", SummaryOptions{Format: ExportFormatInline})
+	require.NoError(t, err)
+
+	assert.Nil(t, summary.Upload.Reader)
+	assert.Equal(t, "Found 2 music URLs in this thread, ~4m of music", summary.Upload.InitialComment)
+	assert.Equal(t, []string{
+		"1. <https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT|Salt &amp; Pepper> (3:33) · shared by ada\n" +
+			"2. <https://www.youtube.com/watch?v=dQw4w9WgXcQ|YouTube Song>",
+	}, summary.Messages)
+}
+
+func TestMessageProcessor_SummarizeThreadAs_IgnoresInlineSummaries(t *testing.T) {
+	t.Parallel()
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{User: "U1", Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+		{Msg: slack.Msg{User: "U2", Text: "https://www.youtube.com/watch?v=dQw4w9WgXcQ"}},
+	}
+
+	smp := newTestProcessor(nil)
+
+	first, err := smp.SummarizeThreadAs(t.Context(), msgs, "C123", "1700000000.000100", SummaryOptions{Format: ExportFormatInline})
+	require.NoError(t, err)
+
+	// The inline summary is posted back to the thread by the bot, a later summary reads it with the thread
+	for _, text := range first.Messages {
+		msgs = append(msgs, slack.Message{Msg: slack.Msg{BotID: "B1", SubType: slack.MsgSubTypeBotMessage, Text: text}})
+	}
+
+	again, err := smp.SummarizeThreadAs(t.Context(), msgs, "C123", "1700000000.000100", SummaryOptions{Format: ExportFormatInline})
+	require.NoError(t, err)
+
+	assert.Equal(t, first.Messages, again.Messages)
+	assert.Equal(t, first.Tracks, again.Tracks)
+	assert.Empty(t, again.Skipped)
+	assert.Len(t, again.Stats.Contributors, 2)
+}
+
+func TestCreateInline_SplitsLongSummaries(t *testing.T) {
+	t.Parallel()
+
+	const count = 100
+
+	rows := func(yield func(SummaryRow) error) error {
+		for i := range count {
+			url := fmt.Sprintf("https://www.youtube.com/watch?v=video%03d", i)
+			if err := yield(SummaryRow{URL: url, Title: strings.Repeat("x", 40)}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	messages, err := createInline(SummaryFile{Rows: rows, UserName: func(id string) string { return id }})
+	require.NoError(t, err)
+	require.Greater(t, len(messages), 1)
+
+	lines := 0
+
+	for _, m := range messages {
+		assert.LessOrEqual(t, len(m), inlineMessageLimit)
+
+		lines += len(strings.Split(m, "\n"))
+	}
+
+	// Rows are never split between messages
+	assert.Equal(t, count, lines)
+	assert.True(t, strings.HasPrefix(messages[len(messages)-1], fmt.Sprintf("%d. ", count-len(strings.Split(messages[len(messages)-1], "\n"))+1)))
+}
+
+func TestCreateInline_Anonymous(t *testing.T) {
+	t.Parallel()
+
+	messages, err := createInline(attributedFile(false))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1. <https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT|Spöng> · shared by Alice U1"}, messages)
+
+	messages, err = createInline(attributedFile(true))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1. <https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT|Spöng>"}, messages)
+}
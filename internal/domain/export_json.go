@@ -0,0 +1,158 @@
+package domain
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed" // the JSON schema of the export is embedded for consumers and tests
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+)
+
+// JSONExportSchemaVersion is the version of the JSON export format embedded in every payload.
+//
+// Only bump the minor or patch version for backward-compatible changes (new optional fields),
+// breaking changes need a new major version and a new schema file.
+const JSONExportSchemaVersion = "1.12.0"
+
+// JSONExportSchema is the JSON schema that every JSON export conforms to.
+//
+//go:embed schemas/summary-export.v1.json
+var JSONExportSchema []byte
+
+// jsonExport is the header of the export, the tracks are streamed after it one by one.
+type jsonExport struct {
+	SchemaVersion string    `json:"schema_version"`
+	ChannelID     string    `json:"channel_id"`
+	ThreadTS      string    `json:"thread_ts"`
+	GeneratedAt   time.Time `json:"generated_at"`
+}
+
+type jsonTrack struct {
+	Title           string                                     `json:"title"`
+	URL             string                                     `json:"url"`
+	Provider        musicextractors.ExtractProvider            `json:"provider"`
+	Artist          string                                     `json:"artist,omitempty"`
+	Album           string                                     `json:"album,omitempty"`
+	ArtworkURL      string                                     `json:"artwork_url,omitempty"`
+	ProviderID      string                                     `json:"provider_id,omitempty"`
+	DurationSeconds int                                        `json:"duration_seconds,omitempty"`
+	Links           map[musicextractors.ExtractProvider]string `json:"links"`
+	ReleaseYear     int                                        `json:"release_year,omitempty"`
+	Genres          []string                                   `json:"genres,omitempty"`
+	ISRC            string                                     `json:"isrc,omitempty"`
+	TimesShared     int                                        `json:"times_shared"`
+}
+
+// newJSONTrack converts a row to its exported JSON object.
+func newJSONTrack(r SummaryRow) jsonTrack {
+	return jsonTrack{
+		Title:           r.Title,
+		URL:             r.URL,
+		Provider:        r.Provider,
+		Artist:          r.Metadata.Artist,
+		Album:           r.Metadata.Album,
+		ArtworkURL:      r.Metadata.ArtworkURL,
+		ProviderID:      r.Metadata.ProviderID,
+		DurationSeconds: r.Metadata.DurationSeconds,
+		Links:           r.Links,
+		ReleaseYear:     r.Metadata.ReleaseYear,
+		Genres:          r.Metadata.Genres,
+		ISRC:            r.Metadata.ISRC,
+		TimesShared:     r.TimesShared,
+	}
+}
+
+// jsonSkipped is a link of the skipped links report.
+type jsonSkipped struct {
+	URL       string                          `json:"url"`
+	Provider  musicextractors.ExtractProvider `json:"provider"`
+	Kind      musicextractors.ErrorKind       `json:"kind"`
+	Reason    string                          `json:"reason"`
+	UserID    string                          `json:"user_id,omitempty"`
+	MessageTS string                          `json:"message_ts,omitempty"`
+}
+
+// jsonEncoder writes the summaries as a versioned JSON document described by JSONExportSchema.
+type jsonEncoder struct{}
+
+var _ SummaryEncoder = jsonEncoder{}
+
+// Encode writes every row into a JSON export, encoding one track at a time instead of the whole export at once.
+func (jsonEncoder) Encode(w io.Writer, file SummaryFile) error {
+	header, err := json.MarshalIndent(jsonExport{
+		SchemaVersion: JSONExportSchemaVersion,
+		ChannelID:     file.ChannelID,
+		ThreadTS:      file.ThreadTS,
+		GeneratedAt:   time.Now().UTC(),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding json export: %w", err)
+	}
+
+	buff := bufio.NewWriter(w)
+
+	// The header ends with the closing brace of the object, the tracks are the last field
+	buff.Write(bytes.TrimSuffix(header, []byte("\n}")))
+	buff.WriteString(",\n  \"tracks\": [")
+
+	empty := true
+
+	err = file.Rows(func(r SummaryRow) error {
+		raw, mErr := json.MarshalIndent(newJSONTrack(r), "    ", "  ")
+		if mErr != nil {
+			return fmt.Errorf("encoding json track: %w", mErr)
+		}
+
+		if !empty {
+			buff.WriteString(",")
+		}
+
+		buff.WriteString("\n    ")
+		buff.Write(raw)
+
+		empty = false
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if !empty {
+		buff.WriteString("\n  ")
+	}
+
+	buff.WriteString("]")
+
+	if len(file.Skipped) > 0 {
+		skipped := make([]jsonSkipped, 0, len(file.Skipped))
+		for _, f := range file.Skipped {
+			s := jsonSkipped{URL: f.URL, Provider: f.Provider, Kind: f.Kind, Reason: skippedReason(f.Kind)}
+			if !file.Anonymous {
+				s.UserID, s.MessageTS = f.UserID, f.MessageTS
+			}
+
+			skipped = append(skipped, s)
+		}
+
+		raw, mErr := json.MarshalIndent(skipped, "  ", "  ")
+		if mErr != nil {
+			return fmt.Errorf("encoding json skipped links: %w", mErr)
+		}
+
+		buff.WriteString(",\n  \"skipped\": ")
+		buff.Write(raw)
+	}
+
+	buff.WriteString("\n}\n")
+
+	if err = buff.Flush(); err != nil {
+		return fmt.Errorf("flushing json export: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticEnricher struct {
+	err    error
+	year   int
+	genres []string
+}
+
+func (e staticEnricher) Enrich(_ context.Context, m musicextractors.TrackMetadata) (musicextractors.TrackMetadata, error) {
+	m.ReleaseYear, m.Genres = e.year, e.genres
+
+	return m, e.err
+}
+
+type schemaObject struct {
+	Properties map[string]json.RawMessage `json:"properties"`
+	Items      *schemaObject              `json:"items"`
+	Required   []string                   `json:"required"`
+}
+
+// assertConformsTo checks that obj has every required property of the schema and nothing that the schema doesn't know about.
+func assertConformsTo(t *testing.T, schema schemaObject, obj map[string]json.RawMessage) {
+	t.Helper()
+
+	for _, r := range schema.Required {
+		assert.Contains(t, obj, r)
+	}
+
+	for k := range obj {
+		assert.Contains(t, slices.Collect(maps.Keys(schema.Properties)), k)
+	}
+}
+
+func TestMessageProcessor_SummarizeThread_JSONConformsToSchema(t *testing.T) {
+	t.Parallel()
+
+	smp := NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: staticTitle("Spotify Song"),
+		},
+		Enricher:    staticEnricher{year: 1987, genres: []string{"pop"}},
+		Format:      ExportFormatJSON,
+		Compression: Compression{Kind: CompressionNone},
+	})
+
+	summary, err := smp.SummarizeThread(
+		t.Context(),
+		[]slack.Message{{Msg: slack.Msg{Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}}},
+		"C123",
+		"1700000000.000100",
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "C123-1700000000.000100.json", summary.Upload.Filename)
+
+	raw, err := io.ReadAll(summary.Upload.Reader)
+	require.NoError(t, err)
+
+	var schema schemaObject
+	require.NoError(t, json.Unmarshal(JSONExportSchema, &schema))
+
+	var export map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(raw, &export))
+	assertConformsTo(t, schema, export)
+
+	var version string
+	require.NoError(t, json.Unmarshal(export["schema_version"], &version))
+	assert.Equal(t, JSONExportSchemaVersion, version)
+
+	var trackSchema schemaObject
+	require.NoError(t, json.Unmarshal(schema.Properties["tracks"], &trackSchema))
+	require.NotNil(t, trackSchema.Items)
+
+	var tracks []map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(export["tracks"], &tracks))
+	require.Len(t, tracks, 1)
+	assertConformsTo(t, *trackSchema.Items, tracks[0])
+	assert.JSONEq(t, `["pop"]`, string(tracks[0]["genres"]))
+	assert.JSONEq(t, `1987`, string(tracks[0]["release_year"]))
+	assert.JSONEq(t, `1`, string(tracks[0]["times_shared"]))
+}
+
+func TestMessageProcessor_SummarizeThread_FailedEnrichmentKeepsTrack(t *testing.T) {
+	t.Parallel()
+
+	smp := NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: staticTitle("Spotify Song"),
+		},
+		Enricher:    staticEnricher{err: musicextractors.ErrRequestFailed, year: 1987},
+		Format:      ExportFormatJSON,
+		Compression: Compression{Kind: CompressionNone},
+	})
+
+	summary, err := smp.SummarizeThread(
+		t.Context(),
+		[]slack.Message{{Msg: slack.Msg{Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}}},
+		"C123",
+		"1700000000.000100",
+	)
+	require.NoError(t, err)
+	require.Len(t, summary.Tracks, 1)
+
+	raw, err := io.ReadAll(summary.Upload.Reader)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "release_year")
+}
+
+func TestJSONEncoder_Encode_Anonymous(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	require.NoError(t, jsonEncoder{}.Encode(&buf, attributedFile(true)))
+	require.True(t, json.Valid(buf.Bytes()))
+
+	var export struct {
+		Skipped []map[string]any `json:"skipped"`
+	}
+
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &export))
+	require.Len(t, export.Skipped, 1)
+	assert.Equal(t, "https://audiomack.com/burna-boy/song/last-last", export.Skipped[0]["url"])
+	assertAnonymous(t, buf.String())
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// jsonlTrack is a line of the JSON Lines export, a track of the JSON export with the thread and the share it came from,
+// so every line can be ingested on its own.
+type jsonlTrack struct {
+	ChannelID string `json:"channel_id"`
+	ThreadTS  string `json:"thread_ts"`
+	UserID    string `json:"user_id,omitempty"`
+	UserName  string `json:"user_name,omitempty"`
+	MessageTS string `json:"message_ts,omitempty"`
+	// SharedAt is the time of MessageTS in RFC 3339, empty if it's malformed.
+	SharedAt string `json:"shared_at,omitempty"`
+	jsonTrack
+}
+
+// jsonlEncoder writes the summaries as JSON Lines, a JSON object per track, for data pipelines and warehouses.
+type jsonlEncoder struct{}
+
+var _ SummaryEncoder = jsonlEncoder{}
+
+// Encode writes every row as a line of the export, the skipped links are left out so every line is a track.
+// The lines of an anonymous file have no user and no message.
+func (jsonlEncoder) Encode(w io.Writer, file SummaryFile) error {
+	buff := bufio.NewWriter(w)
+
+	// The encoder ends every value with a newline, HTML escaping would only mangle the URLs and titles
+	enc := json.NewEncoder(buff)
+	enc.SetEscapeHTML(false)
+
+	err := file.Rows(func(r SummaryRow) error {
+		line := jsonlTrack{
+			ChannelID: file.ChannelID,
+			ThreadTS:  file.ThreadTS,
+			jsonTrack: newJSONTrack(r),
+		}
+
+		if !file.Anonymous {
+			line.UserID, line.MessageTS = r.UserID, r.MessageTS
+
+			if r.UserID != "" {
+				line.UserName = file.UserName(r.UserID)
+			}
+
+			if t := messageTime(r.MessageTS); !t.IsZero() {
+				line.SharedAt = t.Format(time.RFC3339)
+			}
+		}
+
+		if err := enc.Encode(line); err != nil {
+			return fmt.Errorf("encoding jsonl track: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = buff.Flush(); err != nil {
+		return fmt.Errorf("flushing jsonl export: %w", err)
+	}
+
+	return nil
+}
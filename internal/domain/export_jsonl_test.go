@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageProcessor_SummarizeThreadAs_JSONL(t *testing.T) {
+	t.Parallel()
+
+	smp := NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+			musicextractors.YouTubeProvider: musicextractors.YouTubeURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: staticTitle("Spotify Song"),
+			musicextractors.YouTubeProvider: staticTitle("Rock & Roll"),
+		},
+		Enricher:     staticEnricher{year: 1987, genres: []string{"pop"}},
+		UserResolver: stubUsers{"U1": "ada"},
+		Format:       ExportFormatCSV,
+		Compression:  Compression{Kind: CompressionNone},
+	})
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{User: "U1", Timestamp: "1700000000.000200", Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+		{Msg: slack.Msg{Timestamp: "1700000060.000300", Text: "https://www.youtube.com/watch?v=dQw4w9WgXcQ"}},
+	}
+
+	summary, err := smp.SummarizeThreadAs(t.Context(), msgs, "C123", "1700000000.000100", SummaryOptions{Format: ExportFormatJSONL})
+	require.NoError(t, err)
+	assert.Equal(t, "C123-1700000000.000100.jsonl", summary.Upload.Filename)
+
+	raw, err := io.ReadAll(summary.Upload.Reader)
+	require.NoError(t, err)
+
+	var lines []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	require.Len(t, lines, 2)
+	assert.JSONEq(t, `{
+		"channel_id": "C123",
+		"thread_ts": "1700000000.000100",
+		"user_id": "U1",
+		"user_name": "ada",
+		"message_ts": "1700000000.000200",
+		"shared_at": "2023-11-14T22:13:20Z",
+		"title": "Spotify Song",
+		"url": "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT",
+		"provider": "spotify",
+		"links": {"spotify": "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"},
+		"release_year": 1987,
+		"genres": ["pop"],
+		"times_shared": 1
+	}`, lines[0])
+
+	// Text isn't HTML escaped and a share without a user has no poster
+	assert.Contains(t, lines[1], `"title":"Rock & Roll"`)
+
+	var second map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+	assert.NotContains(t, second, "user_id")
+	assert.JSONEq(t, `"2023-11-14T22:14:20Z"`, string(second["shared_at"]))
+}
+
+func TestJSONLEncoder_Encode_Anonymous(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	require.NoError(t, jsonlEncoder{}.Encode(&buf, attributedFile(true)))
+
+	var line map[string]any
+
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "C123", line["channel_id"])
+	assert.Equal(t, "1700000000.000100", line["thread_ts"])
+	assert.Equal(t, "Spöng", line["title"])
+	assert.NotContains(t, line, "user_name")
+	assert.NotContains(t, line, "shared_at")
+	assertAnonymous(t, buf.String())
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// markdownCell escapes the characters that would end a Markdown table cell or row early.
+var markdownCell = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r\n", " ", "\n", " ")
+
+// markdownEncoder writes the summaries as a Markdown table with the same columns as the CSV export.
+type markdownEncoder struct {
+	table summaryTable
+}
+
+var _ SummaryEncoder = markdownEncoder{}
+
+// writeMarkdownRow writes the cells as a row of a Markdown table.
+func writeMarkdownRow(w io.StringWriter, cells []string) {
+	_, _ = w.WriteString("|")
+
+	for _, c := range cells {
+		_, _ = w.WriteString(" " + markdownCell.Replace(c) + " |")
+	}
+
+	_, _ = w.WriteString("\n")
+}
+
+// writeMarkdownHeader writes the header row and the separator row of a Markdown table.
+func writeMarkdownHeader(w io.StringWriter, header []string) {
+	writeMarkdownRow(w, header)
+
+	separator := make([]string, len(header))
+	for i := range separator {
+		separator[i] = "---"
+	}
+
+	writeMarkdownRow(w, separator)
+}
+
+// Encode writes every row into a Markdown table, followed by the table of the skipped links if there are any,
+// ready to be pasted into docs or rendered by Slack's file preview.
+func (e markdownEncoder) Encode(w io.Writer, file SummaryFile) error {
+	buff := bufio.NewWriter(w)
+	table := e.table.forFile(file)
+
+	writeMarkdownHeader(buff, table.header)
+
+	row, _ := rowPool.Get().(*[]string)
+	defer rowPool.Put(row)
+
+	err := file.Rows(func(r SummaryRow) error {
+		*row = table.appendCells((*row)[:0], r, file.UserName)
+		writeMarkdownRow(buff, *row)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(file.Skipped) > 0 {
+		_, _ = buff.WriteString("\n## Skipped links\n\n")
+		writeMarkdownHeader(buff, skippedColumns(file))
+
+		for _, f := range file.Skipped {
+			writeMarkdownRow(buff, skippedCells(f, file))
+		}
+	}
+
+	if err = buff.Flush(); err != nil {
+		return fmt.Errorf("flushing markdown table: %w", err)
+	}
+
+	return nil
+}
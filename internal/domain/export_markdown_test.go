@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageProcessor_SummarizeThreadAs_Markdown(t *testing.T) {
+	t.Parallel()
+
+	smp := NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: staticTitle("Song | Live"),
+		},
+		Format:      ExportFormatCSV,
+		Compression: Compression{Kind: CompressionNone},
+	})
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{User: "U1", Timestamp: "1700000000.000200", Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+	}
+
+	summary, err := smp.SummarizeThreadAs(t.Context(), msgs, "C123", "1700000000.000100", SummaryOptions{Format: ExportFormatMarkdown})
+	require.NoError(t, err)
+	assert.Equal(t, "C123-1700000000.000100.md", summary.Upload.Filename)
+
+	got, err := io.ReadAll(summary.Upload.Reader)
+	require.NoError(t, err)
+	// Pipes in the cells are escaped, so they don't split the row
+	assert.Equal(t, "| Title | Duration | Shared by | Shared at | Spotify URL | YouTube URL | YouTube Music URL | Mixcloud URL | "+
+		"Audiomack URL | Amazon Music URL | Apple Music URL | Shazam URL | Other video URL | Reference URL |\n"+
+		"| --- | --- | --- | --- | --- | --- | --- | --- | --- | --- | --- | --- | --- | --- |\n"+
+		`| Song \| Live |  | U1 | 2023-11-14 22:13 UTC | https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT |  |  |  |  |  |  |  |  |  |`+"\n",
+		string(got))
+}
+
+func TestMarkdownEncoder_Encode_Anonymous(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	require.NoError(t, markdownEncoder{table: newSummaryTable(nil, false, false, false)}.Encode(&buf, attributedFile(true)))
+
+	got := buf.String()
+	assert.Contains(t, got, "| Title | Duration | Spotify URL |")
+	assert.Contains(t, got, "| URL | Provider | Reason |\n| --- | --- | --- |\n| https://audiomack.com/burna-boy/song/last-last | audiomack | title not found |")
+	assertAnonymous(t, got)
+}
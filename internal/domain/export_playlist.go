@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// playlistLine replaces the line breaks that would end an M3U directive early.
+var playlistLine = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ")
+
+// m3uEncoder writes the summaries as an extended M3U8 playlist, every track with its duration and title.
+type m3uEncoder struct{}
+
+var _ SummaryEncoder = m3uEncoder{}
+
+// Encode writes every row as an entry of the playlist, unknown durations are -1 as the format expects.
+func (m3uEncoder) Encode(w io.Writer, file SummaryFile) error {
+	buff := bufio.NewWriter(w)
+	buff.WriteString("#EXTM3U\n")
+
+	err := file.Rows(func(r SummaryRow) error {
+		duration := r.Metadata.DurationSeconds
+		if duration <= 0 {
+			duration = -1
+		}
+
+		title := r.Title
+		if title == "" {
+			title = r.URL
+		}
+
+		fmt.Fprintf(buff, "#EXTINF:%d,%s\n%s\n", duration, playlistLine.Replace(title), r.URL)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = buff.Flush(); err != nil {
+		return fmt.Errorf("flushing m3u8 playlist: %w", err)
+	}
+
+	return nil
+}
+
+// xspfTrack is a track of an XSPF playlist, see https://xspf.org/spec.
+type xspfTrack struct {
+	XMLName  xml.Name `xml:"track"`
+	Location string   `xml:"location"`
+	Title    string   `xml:"title,omitempty"`
+	Creator  string   `xml:"creator,omitempty"`
+	Album    string   `xml:"album,omitempty"`
+	Image    string   `xml:"image,omitempty"`
+	Duration int      `xml:"duration,omitempty"`
+}
+
+// xspfEncoder writes the summaries as an XSPF playlist, keeping the artist and album apart from the title.
+type xspfEncoder struct{}
+
+var _ SummaryEncoder = xspfEncoder{}
+
+// Encode writes every row as a track of the playlist, one track at a time.
+func (xspfEncoder) Encode(w io.Writer, file SummaryFile) error {
+	buff := bufio.NewWriter(w)
+	buff.WriteString(xml.Header + `<playlist version="1" xmlns="http://xspf.org/ns/0/">` + "\n  <title>")
+
+	if err := xml.EscapeText(buff, fmt.Appendf(nil, "Thread %s in %s", file.ThreadTS, file.ChannelID)); err != nil {
+		return fmt.Errorf("encoding xspf title: %w", err)
+	}
+
+	buff.WriteString("</title>\n  <trackList>\n")
+
+	enc := xml.NewEncoder(buff)
+	enc.Indent("    ", "  ")
+
+	empty := true
+
+	err := file.Rows(func(r SummaryRow) error {
+		// The display title already has the artist, the playlist keeps them apart when it's known
+		title := r.Metadata.Title
+		if title == "" {
+			title = r.Title
+		}
+
+		if eErr := enc.Encode(xspfTrack{
+			Location: r.URL,
+			Title:    title,
+			Creator:  r.Metadata.Artist,
+			Album:    r.Metadata.Album,
+			Image:    r.Metadata.ArtworkURL,
+			Duration: r.Metadata.DurationSeconds * 1000,
+		}); eErr != nil {
+			return fmt.Errorf("encoding xspf track: %w", eErr)
+		}
+
+		empty = false
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = enc.Flush(); err != nil {
+		return fmt.Errorf("flushing xspf tracks: %w", err)
+	}
+
+	// The encoder starts every track on a new line, only the last one needs to be ended
+	if !empty {
+		buff.WriteString("\n")
+	}
+
+	buff.WriteString("  </trackList>\n</playlist>\n")
+
+	if err = buff.Flush(); err != nil {
+		return fmt.Errorf("flushing xspf playlist: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPlaylistTestProcessor() MessageProcessorDomain {
+	return NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+			musicextractors.YouTubeProvider: musicextractors.YouTubeURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: func(context.Context, string) (musicextractors.TrackMetadata, error) {
+				return musicextractors.TrackMetadata{Title: "Rock & Roll", Artist: "Led Zeppelin", DurationSeconds: 220}, nil
+			},
+			musicextractors.YouTubeProvider: staticTitle("YouTube Song"),
+		},
+		Format:      ExportFormatCSV,
+		Compression: Compression{Kind: CompressionNone},
+	})
+}
+
+func TestMessageProcessor_SummarizeThreadAs_Playlists(t *testing.T) {
+	t.Parallel()
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+		{Msg: slack.Msg{Text: "https://www.youtube.com/watch?v=dQw4w9WgXcQ"}},
+	}
+
+	tests := []struct {
+		format   ExportFormat
+		fileName string
+		want     string
+	}{
+		{
+			format:   ExportFormatM3U8,
+			fileName: "C123-1700000000.000100.m3u8",
+			want: "#EXTM3U\n" +
+				"#EXTINF:220,Led Zeppelin - Rock & Roll\nhttps://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT\n" +
+				"#EXTINF:-1,YouTube Song\nhttps://www.youtube.com/watch?v=dQw4w9WgXcQ\n",
+		},
+		{
+			format:   ExportFormatXSPF,
+			fileName: "C123-1700000000.000100.xspf",
+			want: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<playlist version="1" xmlns="http://xspf.org/ns/0/">` + "\n" +
+				"  <title>Thread 1700000000.000100 in C123</title>\n" +
+				"  <trackList>\n" +
+				"    <track>\n" +
+				"      <location>https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT</location>\n" +
+				"      <title>Rock &amp; Roll</title>\n" +
+				"      <creator>Led Zeppelin</creator>\n" +
+				"      <duration>220000</duration>\n" +
+				"    </track>\n" +
+				"    <track>\n" +
+				"      <location>https://www.youtube.com/watch?v=dQw4w9WgXcQ</location>\n" +
+				"      <title>YouTube Song</title>\n" +
+				"    </track>\n" +
+				"  </trackList>\n" +
+				"</playlist>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			t.Parallel()
+
+			summary, err := newPlaylistTestProcessor().SummarizeThreadAs(t.Context(), msgs, "C123", "1700000000.000100", SummaryOptions{Format: tt.format})
+			require.NoError(t, err)
+			assert.Equal(t, tt.fileName, summary.Upload.Filename)
+
+			got, err := io.ReadAll(summary.Upload.Reader)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestPlaylistEncoders_Encode_Anonymous(t *testing.T) {
+	t.Parallel()
+
+	for _, encoder := range []SummaryEncoder{m3uEncoder{}, xspfEncoder{}} {
+		var anonymous, attributed bytes.Buffer
+
+		require.NoError(t, encoder.Encode(&anonymous, attributedFile(true)))
+		require.NoError(t, encoder.Encode(&attributed, attributedFile(false)))
+
+		// The playlists never had any attribution, an anonymous one is the same playlist
+		assert.Equal(t, attributed.String(), anonymous.String())
+		assert.Contains(t, anonymous.String(), "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT")
+		assertAnonymous(t, anonymous.String())
+	}
+}
@@ -0,0 +1,42 @@
+package domain
+
+import "github.com/Shikachuu/wap-bot/pkg/musicextractors"
+
+// skippedHeader is the header of the skipped links report of the tabular formats.
+var skippedHeader = []string{"URL", "Provider", "Reason", "Shared by", "Shared at"}
+
+// skippedAnonymousColumns is the number of columns of the skipped links report kept in the anonymous summaries,
+// the ones before the shared by and shared at columns.
+const skippedAnonymousColumns = 3
+
+// skippedReason describes the kind of failure of a skipped link, the kind itself if it has no description.
+func skippedReason(kind musicextractors.ErrorKind) string {
+	if reason, ok := skippedReasons[kind]; ok {
+		return reason
+	}
+
+	return string(kind)
+}
+
+// skippedColumns returns the header of the skipped links report of file.
+func skippedColumns(file SummaryFile) []string {
+	if file.Anonymous {
+		return skippedHeader[:skippedAnonymousColumns]
+	}
+
+	return skippedHeader
+}
+
+// skippedCells returns the cells of a link of the skipped links report of file in the order of skippedColumns.
+func skippedCells(f FailedTitle, file SummaryFile) []string {
+	if file.Anonymous {
+		return []string{f.URL, string(f.Provider), skippedReason(f.Kind)}
+	}
+
+	sharedBy := ""
+	if f.UserID != "" {
+		sharedBy = file.UserName(f.UserID)
+	}
+
+	return []string{f.URL, string(f.Provider), skippedReason(f.Kind), sharedBy, sharedAt(f.MessageTS)}
+}
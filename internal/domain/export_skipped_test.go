@@ -0,0 +1,190 @@
+package domain
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func skippedReportProcessor(enabled bool) MessageProcessorDomain {
+	return NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider:   musicextractors.SpotifyURLExtractor,
+			musicextractors.AudiomackProvider: musicextractors.AudiomackURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider:   staticTitle("Spotify Song"),
+			musicextractors.AudiomackProvider: failingTitle(musicextractors.ErrNoTitleFound),
+		},
+		Format:        ExportFormatCSV,
+		SkippedReport: enabled,
+		Compression:   Compression{Kind: CompressionNone},
+	})
+}
+
+var skippedReportMessages = []slack.Message{
+	{Msg: slack.Msg{User: "U1", Timestamp: "1700000000.000200", Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+	{Msg: slack.Msg{User: "U2", Timestamp: "1700000000.000300", Text: "https://audiomack.com/burna-boy/song/last-last"}},
+}
+
+func TestMessageProcessor_SummarizeThreadAs_SkippedReport(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		check  func(t *testing.T, raw []byte)
+		name   string
+		format ExportFormat
+	}{
+		{
+			name:   "markdown section",
+			format: ExportFormatMarkdown,
+			check: func(t *testing.T, raw []byte) {
+				t.Helper()
+
+				assert.Contains(t, string(raw), "\n## Skipped links\n\n"+
+					"| URL | Provider | Reason | Shared by | Shared at |\n"+
+					"| --- | --- | --- | --- | --- |\n"+
+					"| https://audiomack.com/burna-boy/song/last-last | audiomack | title not found | U2 | 2023-11-14 22:13 UTC |\n")
+			},
+		},
+		{
+			name:   "xlsx sheet",
+			format: ExportFormatXLSX,
+			check: func(t *testing.T, raw []byte) {
+				t.Helper()
+
+				zr, err := zip.NewReader(bytes.NewReader(raw), int64(len(raw)))
+				require.NoError(t, err)
+
+				f, err := zr.Open(xlsxSkippedSheetPath)
+				require.NoError(t, err)
+
+				defer f.Close()
+
+				var sheet xlsxSheet
+				require.NoError(t, xml.NewDecoder(f).Decode(&sheet))
+				require.Len(t, sheet.Rows, 2)
+				assert.Equal(t, "URL", sheet.Rows[0].Cells[0].Text)
+				assert.Equal(t, "https://audiomack.com/burna-boy/song/last-last", sheet.Rows[1].Cells[0].Text)
+				assert.Equal(t, "title not found", sheet.Rows[1].Cells[2].Text)
+
+				wb, err := zr.Open("xl/workbook.xml")
+				require.NoError(t, err)
+
+				defer wb.Close()
+
+				workbook, err := io.ReadAll(wb)
+				require.NoError(t, err)
+				assert.Contains(t, string(workbook), `<sheet name="Skipped" sheetId="2" r:id="rId2"/>`)
+			},
+		},
+		{
+			name:   "json array",
+			format: ExportFormatJSON,
+			check: func(t *testing.T, raw []byte) {
+				t.Helper()
+
+				var schema schemaObject
+				require.NoError(t, json.Unmarshal(JSONExportSchema, &schema))
+
+				var export map[string]json.RawMessage
+				require.NoError(t, json.Unmarshal(raw, &export))
+				assertConformsTo(t, schema, export)
+
+				var skippedSchema schemaObject
+				require.NoError(t, json.Unmarshal(schema.Properties["skipped"], &skippedSchema))
+				require.NotNil(t, skippedSchema.Items)
+
+				var skipped []map[string]json.RawMessage
+				require.NoError(t, json.Unmarshal(export["skipped"], &skipped))
+				require.Len(t, skipped, 1)
+				assertConformsTo(t, *skippedSchema.Items, skipped[0])
+				assert.JSONEq(t, `"title_not_found"`, string(skipped[0]["kind"]))
+				assert.JSONEq(t, `"U2"`, string(skipped[0]["user_id"]))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			summary, err := skippedReportProcessor(true).
+				SummarizeThreadAs(t.Context(), skippedReportMessages, "C123", "1700000000.000100", SummaryOptions{Format: tt.format})
+			require.NoError(t, err)
+
+			raw, err := io.ReadAll(summary.Upload.Reader)
+			require.NoError(t, err)
+			tt.check(t, raw)
+		})
+	}
+}
+
+func TestMessageProcessor_SummarizeThreadAs_SkippedReportDisabled(t *testing.T) {
+	t.Parallel()
+
+	for _, format := range []ExportFormat{ExportFormatMarkdown, ExportFormatJSON} {
+		summary, err := skippedReportProcessor(false).
+			SummarizeThreadAs(t.Context(), skippedReportMessages, "C123", "1700000000.000100", SummaryOptions{Format: format})
+		require.NoError(t, err)
+
+		raw, err := io.ReadAll(summary.Upload.Reader)
+		require.NoError(t, err)
+		assert.NotContains(t, string(raw), "audiomack.com", format)
+	}
+}
+
+// attributedFile returns a file with a row and a skipped link shared by users at 2024-03-09 16:00 UTC,
+// the anonymous tests check that neither the users nor the time end up in the export.
+func attributedFile(anonymous bool) SummaryFile {
+	row := SummaryRow{
+		Title:     "Spöng",
+		URL:       "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT",
+		Provider:  musicextractors.SpotifyProvider,
+		Links:     map[musicextractors.ExtractProvider]string{musicextractors.SpotifyProvider: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"},
+		MessageTS: "1710000000.000200",
+		UserID:    "U1",
+	}
+
+	return SummaryFile{
+		Rows:      func(yield func(SummaryRow) error) error { return yield(row) },
+		ChannelID: "C123",
+		ThreadTS:  "1700000000.000100",
+		UserName:  func(id string) string { return "Alice " + id },
+		Skipped: []FailedTitle{{
+			Track: Track{URL: "https://audiomack.com/burna-boy/song/last-last", Provider: musicextractors.AudiomackProvider, MessageTS: "1710000000.000300", UserID: "U2"},
+			Kind:  musicextractors.ErrorKindTitleNotFound,
+		}},
+		Anonymous: anonymous,
+	}
+}
+
+// assertAnonymous checks that the export of attributedFile has no user and no share time.
+func assertAnonymous(t *testing.T, got string) {
+	t.Helper()
+
+	for _, attribution := range []string{"U1", "U2", "Alice", "1710000000", "2024-03-09", "Shared by", "Shared at", "user_id", "message_ts"} {
+		assert.NotContains(t, got, attribution)
+	}
+}
+
+func TestSkippedCells_Anonymous(t *testing.T) {
+	t.Parallel()
+
+	file := attributedFile(false)
+	assert.Equal(t, skippedHeader, skippedColumns(file))
+	assert.Equal(t, []string{"https://audiomack.com/burna-boy/song/last-last", "audiomack", "title not found", "Alice U2", "2024-03-09 16:00 UTC"},
+		skippedCells(file.Skipped[0], file))
+
+	file = attributedFile(true)
+	assert.Equal(t, []string{"URL", "Provider", "Reason"}, skippedColumns(file))
+	assert.Equal(t, []string{"https://audiomack.com/burna-boy/song/last-last", "audiomack", "title not found"}, skippedCells(file.Skipped[0], file))
+}
@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack"
+)
+
+// transcriptLinkRegex matches the links of a message, after Slack's link markup is unwrapped.
+var transcriptLinkRegex = regexp.MustCompile(`https?://[^\s<>|]+`)
+
+// markdownLinkText escapes the characters that would end the text of a Markdown link early.
+var markdownLinkText = strings.NewReplacer(`\`, `\\`, `[`, `\[`, `]`, `\]`)
+
+// messageTimeLayout is how the time of the messages is shown in the summaries.
+const messageTimeLayout = "2006-01-02 15:04 UTC"
+
+// messageTime converts a Slack message timestamp to its time, the zero time if it's malformed.
+func messageTime(ts string) time.Time {
+	seconds, _, _ := strings.Cut(ts, ".")
+
+	unix, err := strconv.ParseInt(seconds, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return time.Unix(unix, 0).UTC()
+}
+
+// linkAnnotation renders a music link as a Markdown link titled with the resolved title, followed by a provider badge.
+func linkAnnotation(pml parsedMusicLink) string {
+	return fmt.Sprintf("[%s](<%s>) `%s`", markdownLinkText.Replace(pml.Title), pml.URL, pml.Type)
+}
+
+// annotateLinks annotates the music links of a message, a single link is annotated inline,
+// the tracks of an expanded playlist are listed under the message.
+func annotateLinks(text string, links []parsedMusicLink) string {
+	if len(links) == 1 {
+		return annotateLink(text, links[0])
+	}
+
+	if len(links) > 1 {
+		text += "\n"
+	}
+
+	for _, pml := range links {
+		text += "\n- " + linkAnnotation(pml)
+	}
+
+	return text
+}
+
+// annotateLink replaces the music link in text with its resolved title and a provider badge.
+//
+// The shared link may carry extra query parameters the extracted URL doesn't, so the first link starting with it
+// is replaced, the annotation is appended if there is none, like for a resolved short link.
+func annotateLink(text string, pml parsedMusicLink) string {
+	annotation := linkAnnotation(pml)
+	replaced := false
+
+	text = transcriptLinkRegex.ReplaceAllStringFunc(text, func(link string) string {
+		if replaced || !strings.HasPrefix(link, pml.URL) {
+			return link
+		}
+
+		replaced = true
+
+		return annotation
+	})
+
+	if !replaced {
+		text += " → " + annotation
+	}
+
+	return text
+}
+
+// writeTranscript writes the whole thread into w as Markdown, every message under its author and time
+// with its music link annotated inline. An anonymous transcript separates the messages by a rule instead.
+//
+// Unlike the other formats every shared link is kept where it was posted, the same recording isn't merged.
+func writeTranscript(w io.Writer, msgs []slack.Message, links *linkBuffer, channelID, threadTS string, anonymous bool) error {
+	byMessage := make(map[string][]parsedMusicLink, links.len())
+
+	err := links.each(func(pml parsedMusicLink) error {
+		byMessage[pml.MessageTS] = append(byMessage[pml.MessageTS], pml)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	buff := bufio.NewWriter(w)
+	fmt.Fprintf(buff, "# Thread transcript\n\nChannel `%s`, thread `%s`, %d music links.\n", channelID, threadTS, links.len())
+
+	for _, m := range msgs {
+		if skipMessage(m) {
+			continue
+		}
+
+		if anonymous {
+			buff.WriteString("\n---")
+		} else {
+			author := m.User
+			if author == "" {
+				author = m.Username
+			}
+
+			fmt.Fprintf(buff, "\n**@%s**", author)
+
+			if t := messageTime(m.Timestamp); !t.IsZero() {
+				fmt.Fprintf(buff, " · %s", t.Format(messageTimeLayout))
+			}
+		}
+
+		text := musicextractors.UnwrapSlackLinks(strings.TrimSpace(m.Text))
+		text = annotateLinks(text, byMessage[m.Timestamp])
+
+		fmt.Fprintf(buff, "\n\n%s\n", text)
+	}
+
+	if err = buff.Flush(); err != nil {
+		return fmt.Errorf("flushing transcript: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"io"
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageProcessor_SummarizeThreadAs_Transcript(t *testing.T) {
+	t.Parallel()
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{User: "U1", Timestamp: "1700000000.000100", Text: "Friday picks, post your songs!"}},
+		{Msg: slack.Msg{
+			User:      "U2",
+			Timestamp: "1700000060.000200",
+			Text:      "this one <https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT?si=share-token|spotify> slaps",
+		}},
+		{Msg: slack.Msg{User: "U3", Timestamp: "1700000120.000300", Text: "https://www.youtube.com/watch?v=dQw4w9WgXcQ"}},
+		{Msg: slack.Msg{Timestamp: "1700000180.000400", SubType: slack.MsgSubTypeMessageDeleted}},
+	}
+
+	summary, err := newTestProcessor(nil).SummarizeThreadAs(t.Context(), msgs, "C-ANY", "1700000000.000100", SummaryOptions{Format: ExportFormatTranscript})
+	require.NoError(t, err)
+	assert.Equal(t, "C-ANY-1700000000.000100.md", summary.Upload.Filename)
+
+	got, err := io.ReadAll(summary.Upload.Reader)
+	require.NoError(t, err)
+	assert.Equal(t, "# Thread transcript\n\nChannel `C-ANY`, thread `1700000000.000100`, 2 music links.\n"+
+		"\n**@U1** · 2023-11-14 22:13 UTC\n\nFriday picks, post your songs!\n"+
+		"\n**@U2** · 2023-11-14 22:14 UTC\n\n"+
+		"this one [Spotify Song](<https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT>) `spotify` slaps\n"+
+		"\n**@U3** · 2023-11-14 22:15 UTC\n\n"+
+		"[YouTube Song](<https://www.youtube.com/watch?v=dQw4w9WgXcQ>) `youtube`\n", string(got))
+}
+
+func TestAnnotateLink(t *testing.T) {
+	t.Parallel()
+
+	pml := parsedMusicLink{
+		Title: "Artist - Song [Live]",
+		URL:   "https://open.spotify.com/track/abc",
+		Type:  musicextractors.SpotifyProvider,
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "inline",
+			text: "listen https://open.spotify.com/track/abc now",
+			want: "listen [Artist - Song \\[Live\\]](<https://open.spotify.com/track/abc>) `spotify` now",
+		},
+		{
+			name: "resolved short link",
+			text: "listen https://spotify.link/xyz",
+			want: "listen https://spotify.link/xyz → [Artist - Song \\[Live\\]](<https://open.spotify.com/track/abc>) `spotify`",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, annotateLink(tt.text, pml))
+		})
+	}
+}
+
+func TestMessageProcessor_SummarizeThreadAs_AnonymousTranscript(t *testing.T) {
+	t.Parallel()
+
+	smp := NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: staticTitle("Spöng"),
+		},
+		Format:      ExportFormatCSV,
+		Compression: Compression{Kind: CompressionNone},
+	})
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{User: "U1", Timestamp: "1710000000.000200", Text: "listen <https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT>"}},
+		{Msg: slack.Msg{Username: "dj-bot", Timestamp: "1710000000.000300", Text: "nice one"}},
+	}
+
+	summary, err := smp.SummarizeThreadAs(t.Context(), msgs, "C123", "1700000000.000100", SummaryOptions{Format: ExportFormatTranscript, Anonymous: true})
+	require.NoError(t, err)
+
+	got, err := io.ReadAll(summary.Upload.Reader)
+	require.NoError(t, err)
+
+	assert.Equal(t, "# Thread transcript\n\nChannel `C123`, thread `1700000000.000100`, 1 music links.\n"+
+		"\n---\n\nlisten [Spöng](<https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT>) `spotify`\n"+
+		"\n---\n\nnice one\n", string(got))
+	assert.NotContains(t, string(got), "dj-bot")
+	assertAnonymous(t, string(got))
+}
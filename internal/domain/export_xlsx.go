@@ -0,0 +1,203 @@
+package domain
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// The static parts of the workbook, the sheets are listed by xlsxWorkbookParts and written row by row.
+const (
+	xlsxRootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	xlsxSheetPath        = "xl/worksheets/sheet1.xml"
+	xlsxSkippedSheetPath = "xl/worksheets/sheet2.xml"
+)
+
+// xlsxWorkbookParts returns the content types, the workbook and its relationships for the named sheets,
+// the n-th sheet is stored at xl/worksheets/sheet<n>.xml.
+func xlsxWorkbookParts(sheets []string) (string, string, string) {
+	var types, workbook, rels strings.Builder
+
+	types.WriteString(xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	workbook.WriteString(xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	rels.WriteString(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+
+	for i, name := range sheets {
+		n := strconv.Itoa(i + 1)
+
+		types.WriteString(`<Override PartName="/xl/worksheets/sheet` + n + `.xml" ` +
+			`ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`)
+		workbook.WriteString(`<sheet name="` + name + `" sheetId="` + n + `" r:id="rId` + n + `"/>`)
+		rels.WriteString(`<Relationship Id="rId` + n + `" ` +
+			`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet` + n + `.xml"/>`)
+	}
+
+	types.WriteString(`</Types>`)
+	workbook.WriteString(`</sheets></workbook>`)
+	rels.WriteString(`</Relationships>`)
+
+	return types.String(), workbook.String(), rels.String()
+}
+
+// xlsxEncoder writes the summaries as an Excel workbook with the same columns as the CSV export,
+// so spreadsheets open them without an import dialog picking the separator.
+type xlsxEncoder struct {
+	table summaryTable
+}
+
+var _ SummaryEncoder = xlsxEncoder{}
+
+// xlsxColumn returns the name of the zero based column, A to Z, then AA and so on.
+func xlsxColumn(i int) string {
+	name := ""
+
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+
+	return name
+}
+
+// writeXLSXRow writes the cells as the row with the one based index, every cell is an inline string.
+func writeXLSXRow(w io.Writer, index int, cells []string) error {
+	r := strconv.Itoa(index)
+
+	if _, err := io.WriteString(w, `<row r="`+r+`">`); err != nil {
+		return err //nolint:wrapcheck // wrapped by the caller
+	}
+
+	for i, c := range cells {
+		if c == "" {
+			continue
+		}
+
+		if _, err := io.WriteString(w, `<c r="`+xlsxColumn(i)+r+`" t="inlineStr"><is><t xml:space="preserve">`); err != nil {
+			return err //nolint:wrapcheck // wrapped by the caller
+		}
+
+		if err := xml.EscapeText(w, []byte(c)); err != nil {
+			return err //nolint:wrapcheck // wrapped by the caller
+		}
+
+		if _, err := io.WriteString(w, `</t></is></c>`); err != nil {
+			return err //nolint:wrapcheck // wrapped by the caller
+		}
+	}
+
+	_, err := io.WriteString(w, `</row>`)
+
+	return err //nolint:wrapcheck // wrapped by the caller
+}
+
+// writeXLSXSheet writes a worksheet, the header is the first row and rows calls write with the cells of every other row.
+func writeXLSXSheet(zw *zip.Writer, path string, header []string, rows func(write func(cells []string) error) error) error {
+	sheet, err := zw.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating xlsx sheet: %w", err)
+	}
+
+	_, err = io.WriteString(sheet, xml.Header+`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	if err != nil {
+		return fmt.Errorf("writing xlsx sheet: %w", err)
+	}
+
+	if err = writeXLSXRow(sheet, 1, header); err != nil {
+		return fmt.Errorf("writing xlsx header: %w", err)
+	}
+
+	index := 1
+
+	err = rows(func(cells []string) error {
+		index++
+
+		if rErr := writeXLSXRow(sheet, index, cells); rErr != nil {
+			return fmt.Errorf("writing xlsx row: %w", rErr)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.WriteString(sheet, `</sheetData></worksheet>`); err != nil {
+		return fmt.Errorf("writing xlsx sheet: %w", err)
+	}
+
+	return nil
+}
+
+// Encode writes every row into the sheet of a workbook, the header is the first row.
+// The skipped links get a second sheet if there are any.
+func (e xlsxEncoder) Encode(w io.Writer, file SummaryFile) error {
+	zw := zip.NewWriter(w)
+
+	sheets := []string{"Summary"}
+	if len(file.Skipped) > 0 {
+		sheets = append(sheets, "Skipped")
+	}
+
+	types, workbook, workbookRels := xlsxWorkbookParts(sheets)
+
+	for _, part := range []struct{ name, content string }{
+		{"[Content_Types].xml", types},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", workbook},
+		{"xl/_rels/workbook.xml.rels", workbookRels},
+	} {
+		pw, err := zw.Create(part.name)
+		if err != nil {
+			return fmt.Errorf("creating xlsx part %s: %w", part.name, err)
+		}
+
+		if _, err = io.WriteString(pw, part.content); err != nil {
+			return fmt.Errorf("writing xlsx part %s: %w", part.name, err)
+		}
+	}
+
+	row, _ := rowPool.Get().(*[]string)
+	defer rowPool.Put(row)
+
+	table := e.table.forFile(file)
+
+	err := writeXLSXSheet(zw, xlsxSheetPath, table.header, func(write func([]string) error) error {
+		return file.Rows(func(r SummaryRow) error {
+			*row = table.appendCells((*row)[:0], r, file.UserName)
+
+			return write(*row)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(file.Skipped) > 0 {
+		err = writeXLSXSheet(zw, xlsxSkippedSheetPath, skippedColumns(file), func(write func([]string) error) error {
+			for _, f := range file.Skipped {
+				if wErr := write(skippedCells(f, file)); wErr != nil {
+					return wErr
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err = zw.Close(); err != nil {
+		return fmt.Errorf("closing xlsx: %w", err)
+	}
+
+	return nil
+}
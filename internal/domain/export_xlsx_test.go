@@ -0,0 +1,144 @@
+package domain
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// xlsxSheet is the part of a worksheet the tests read back.
+type xlsxSheet struct {
+	Rows []struct {
+		R     string `xml:"r,attr"`
+		Cells []struct {
+			R    string `xml:"r,attr"`
+			Text string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func TestXLSXColumn(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		want  string
+		index int
+	}{
+		{index: 0, want: "A"},
+		{index: 25, want: "Z"},
+		{index: 26, want: "AA"},
+		{index: 27, want: "AB"},
+		{index: 701, want: "ZZ"},
+		{index: 702, want: "AAA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, xlsxColumn(tt.index))
+		})
+	}
+}
+
+func TestMessageProcessor_SummarizeThreadAs_XLSX(t *testing.T) {
+	t.Parallel()
+
+	smp := NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: staticTitle("Rock & Roll <Live>"),
+		},
+		Format:      ExportFormatCSV,
+		Compression: Compression{Kind: CompressionNone},
+	})
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+	}
+
+	summary, err := smp.SummarizeThreadAs(t.Context(), msgs, "C123", "1700000000.000100", SummaryOptions{Format: ExportFormatXLSX})
+	require.NoError(t, err)
+	assert.Equal(t, "C123-1700000000.000100.xlsx", summary.Upload.Filename)
+
+	raw, err := io.ReadAll(summary.Upload.Reader)
+	require.NoError(t, err)
+
+	zr, err := zip.NewReader(bytes.NewReader(raw), int64(len(raw)))
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(zr.File))
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+
+	assert.ElementsMatch(t, []string{
+		"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml",
+	}, names)
+
+	f, err := zr.Open(xlsxSheetPath)
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	var sheet xlsxSheet
+	require.NoError(t, xml.NewDecoder(f).Decode(&sheet))
+	require.Len(t, sheet.Rows, 2)
+
+	assert.Equal(t, "1", sheet.Rows[0].R)
+	assert.Equal(t, "Title", sheet.Rows[0].Cells[0].Text)
+	assert.Equal(t, "N1", sheet.Rows[0].Cells[13].R)
+	assert.Equal(t, "Reference URL", sheet.Rows[0].Cells[13].Text)
+
+	// Empty cells are left out, the others keep their column
+	require.Len(t, sheet.Rows[1].Cells, 2)
+	assert.Equal(t, "A2", sheet.Rows[1].Cells[0].R)
+	assert.Equal(t, "Rock & Roll <Live>", sheet.Rows[1].Cells[0].Text)
+	assert.Equal(t, "E2", sheet.Rows[1].Cells[1].R)
+	assert.Equal(t, "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT", sheet.Rows[1].Cells[1].Text)
+}
+
+func TestXLSXEncoder_Encode_Anonymous(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	require.NoError(t, xlsxEncoder{table: newSummaryTable(nil, false, false, false)}.Encode(&buf, attributedFile(true)))
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	var sheets strings.Builder
+
+	for _, f := range zr.File {
+		rc, oErr := f.Open()
+		require.NoError(t, oErr)
+
+		raw, rErr := io.ReadAll(rc)
+		require.NoError(t, rErr)
+		require.NoError(t, rc.Close())
+
+		if f.Name == xlsxSheetPath {
+			var sheet xlsxSheet
+			require.NoError(t, xml.Unmarshal(raw, &sheet))
+			require.NotEmpty(t, sheet.Rows)
+			assert.Equal(t, "Title", sheet.Rows[0].Cells[0].Text)
+			assert.Equal(t, "Duration", sheet.Rows[0].Cells[1].Text)
+			assert.Equal(t, "Spotify URL", sheet.Rows[0].Cells[2].Text)
+		}
+
+		sheets.Write(raw)
+	}
+
+	assertAnonymous(t, sheets.String())
+}
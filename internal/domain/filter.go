@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"slices"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+)
+
+// ProviderFilter restricts the providers of a single summary, on top of the ones disabled in its channel.
+type ProviderFilter struct {
+	// Only are the providers whose links are summarized, empty means every enabled provider.
+	Only []musicextractors.ExtractProvider
+	// Except are the providers whose links are left out of the summary.
+	Except []musicextractors.ExtractProvider
+}
+
+// IsZero reports if the filter keeps every provider.
+func (f ProviderFilter) IsZero() bool {
+	return len(f.Only) == 0 && len(f.Except) == 0
+}
+
+// EnabledProviders returns the providers with a configured extractor in matching order.
+func (s *messageProcessorDomain) EnabledProviders() []musicextractors.ExtractProvider {
+	return slices.Clone(s.priority)
+}
+
+// disabled returns the providers left out of a summary in channelID with the filter.
+func (s *messageProcessorDomain) disabled(channelID string, filter ProviderFilter) []musicextractors.ExtractProvider {
+	if filter.IsZero() {
+		return s.channelDisabled[channelID]
+	}
+
+	disabled := slices.Concat(s.channelDisabled[channelID], filter.Except)
+
+	if len(filter.Only) > 0 {
+		for _, p := range s.priority {
+			if !slices.Contains(filter.Only, p) {
+				disabled = append(disabled, p)
+			}
+		}
+	}
+
+	return disabled
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageProcessor_SummarizeThreadAs_ProviderFilter(t *testing.T) {
+	t.Parallel()
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{Timestamp: "1", Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+		{Msg: slack.Msg{Timestamp: "2", Text: "https://www.youtube.com/watch?v=dQw4w9WgXcQ"}},
+	}
+
+	tests := []struct {
+		name    string
+		channel string
+		filter  ProviderFilter
+		want    []musicextractors.ExtractProvider
+	}{
+		{name: "no filter", channel: "C1", want: []musicextractors.ExtractProvider{musicextractors.SpotifyProvider, musicextractors.YouTubeProvider}},
+		{
+			name:    "only",
+			channel: "C1",
+			filter:  ProviderFilter{Only: []musicextractors.ExtractProvider{musicextractors.YouTubeProvider}},
+			want:    []musicextractors.ExtractProvider{musicextractors.YouTubeProvider},
+		},
+		{
+			name:    "except",
+			channel: "C1",
+			filter:  ProviderFilter{Except: []musicextractors.ExtractProvider{musicextractors.YouTubeProvider}},
+			want:    []musicextractors.ExtractProvider{musicextractors.SpotifyProvider},
+		},
+		{
+			name:    "only a provider disabled in the channel",
+			channel: "C-NO-SPOTIFY",
+			filter:  ProviderFilter{Only: []musicextractors.ExtractProvider{musicextractors.SpotifyProvider}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			smp := newTestProcessor(map[string][]musicextractors.ExtractProvider{"C-NO-SPOTIFY": {musicextractors.SpotifyProvider}})
+
+			summary, err := smp.SummarizeThreadAs(t.Context(), msgs, tt.channel, "0", SummaryOptions{Providers: tt.filter})
+			require.NoError(t, err)
+
+			var got []musicextractors.ExtractProvider
+			for _, track := range summary.Tracks {
+				got = append(got, track.Provider)
+			}
+
+			assert.Equal(t, tt.want, got)
+			assert.Empty(t, summary.Skipped)
+		})
+	}
+}
+
+func TestMessageProcessor_EnabledProviders(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []musicextractors.ExtractProvider{musicextractors.SpotifyProvider, musicextractors.YouTubeProvider},
+		newTestProcessor(nil).EnabledProviders())
+}
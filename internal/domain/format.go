@@ -0,0 +1,41 @@
+package domain
+
+// ExportFormat is the file format of the summary uploaded to the thread.
+type ExportFormat string
+
+const (
+	// ExportFormatCSV is a semicolon separated CSV with a column per provider.
+	ExportFormatCSV ExportFormat = "csv"
+	// ExportFormatJSON is a versioned JSON document described by JSONExportSchema.
+	ExportFormatJSON ExportFormat = "json"
+	// ExportFormatJSONL is JSON Lines, a JSON object per track with the thread and the share it came from, for data pipelines.
+	ExportFormatJSONL ExportFormat = "jsonl"
+	// ExportFormatMarkdown is a Markdown table with the same columns as the CSV.
+	ExportFormatMarkdown ExportFormat = "md"
+	// ExportFormatHTML is a standalone HTML report with artwork thumbnails and a badge per provider link, for sharing outside Slack.
+	ExportFormatHTML ExportFormat = "html"
+	// ExportFormatXLSX is an Excel workbook with the same columns as the CSV.
+	ExportFormatXLSX ExportFormat = "xlsx"
+	// ExportFormatM3U8 is an extended M3U playlist, ready to be imported into media players.
+	ExportFormatM3U8 ExportFormat = "m3u8"
+	// ExportFormatXSPF is an XSPF playlist, ready to be imported into media players.
+	ExportFormatXSPF ExportFormat = "xspf"
+	// ExportFormatInline posts the summary as mrkdwn messages in the thread instead of a file, easier to read on mobile.
+	ExportFormatInline ExportFormat = "inline"
+	// ExportFormatTranscript is the whole thread as Markdown, with the music links annotated inline.
+	ExportFormatTranscript ExportFormat = "transcript"
+)
+
+// ExportFormats returns every supported export format.
+func ExportFormats() []ExportFormat {
+	return []ExportFormat{ExportFormatCSV, ExportFormatJSON, ExportFormatJSONL, ExportFormatMarkdown, ExportFormatHTML, ExportFormatXLSX, ExportFormatM3U8, ExportFormatXSPF, ExportFormatInline, ExportFormatTranscript}
+}
+
+// extension returns the file extension of the format.
+func (f ExportFormat) extension() string {
+	if f == ExportFormatTranscript {
+		return "md"
+	}
+
+	return string(f)
+}
@@ -0,0 +1,66 @@
+package domain
+
+// GroupBy clusters the rows of a summary file, the rows of a group stay in their sort order.
+type GroupBy string
+
+const (
+	// GroupNone doesn't cluster the rows, the default.
+	GroupNone GroupBy = "none"
+	// GroupGenre clusters the rows by their main genre ordered by name, the ones without a genre come last,
+	// the tabular formats get the genre column so the groups are visible.
+	GroupGenre GroupBy = "genre"
+)
+
+// GroupBys returns every supported grouping.
+func GroupBys() []GroupBy {
+	return []GroupBy{GroupNone, GroupGenre}
+}
+
+// mainGenre returns the first, most relevant genre of pml, empty if it has none.
+func mainGenre(pml parsedMusicLink) string {
+	if len(pml.Metadata.Genres) == 0 {
+		return ""
+	}
+
+	return pml.Metadata.Genres[0]
+}
+
+// groupRows clusters the sorted rows by group, GroupNone keeps them as they are.
+//
+// Grouping keeps the rows in memory like sorting them does.
+func groupRows(rows rowsFunc, group GroupBy) rowsFunc {
+	if group != GroupGenre {
+		return rows
+	}
+
+	return sortedRows(rows, func(a, b parsedMusicLink) int { return compareText(mainGenre(a), mainGenre(b)) })
+}
+
+// withGroupColumns returns the encoder with the columns that show the groups of the rows,
+// only the tabular encoders have room for them.
+func withGroupColumns(encoder SummaryEncoder, group GroupBy) SummaryEncoder {
+	if group != GroupGenre {
+		return encoder
+	}
+
+	switch e := encoder.(type) {
+	case csvEncoder:
+		e.table = e.table.withGenre()
+
+		return e
+	case markdownEncoder:
+		e.table = e.table.withGenre()
+
+		return e
+	case xlsxEncoder:
+		e.table = e.table.withGenre()
+
+		return e
+	case htmlEncoder:
+		e.table = e.table.withGenre()
+
+		return e
+	default:
+		return encoder
+	}
+}
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageProcessor_SummarizeThreadAs_GroupGenre(t *testing.T) {
+	t.Parallel()
+
+	metadata := map[string]musicextractors.TrackMetadata{
+		"https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT": {Title: "Teardrop", Genres: []string{"trip hop", "electronic"}},
+		"https://open.spotify.com/track/67WTwafOMgegV6ABnBQxcE": {Title: "Untagged"},
+		"https://open.spotify.com/track/1pKYYY0dkg23sQQXi0Q5zN": {Title: "Around the World", Genres: []string{"House"}},
+		"https://open.spotify.com/track/7uv632EkfwYhXoqf8rhYrg": {Title: "Angel", Genres: []string{"trip hop"}},
+	}
+
+	newProcessor := func(genre bool) MessageProcessorDomain {
+		return NewSlackMessageProcessor(ProcessorConfig{
+			URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+				musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+			},
+			MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+				musicextractors.SpotifyProvider: func(_ context.Context, url string) (musicextractors.TrackMetadata, error) {
+					return metadata[url], nil
+				},
+			},
+			Genre:       genre,
+			Format:      ExportFormatCSV,
+			Compression: Compression{Kind: CompressionNone},
+		})
+	}
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+		{Msg: slack.Msg{Text: "https://open.spotify.com/track/67WTwafOMgegV6ABnBQxcE"}},
+		{Msg: slack.Msg{Text: "https://open.spotify.com/track/1pKYYY0dkg23sQQXi0Q5zN"}},
+		{Msg: slack.Msg{Text: "https://open.spotify.com/track/7uv632EkfwYhXoqf8rhYrg"}},
+	}
+
+	const header = "Title;Duration;Shared by;Shared at;Genre;Spotify URL;YouTube URL;YouTube Music URL;Mixcloud URL;Audiomack URL;Amazon Music URL;Apple Music URL;Shazam URL;Other video URL;Reference URL\n"
+
+	tests := []struct {
+		name  string
+		genre bool
+		order SortOrder
+		group GroupBy
+		want  string
+	}{
+		{
+			name:  "genre column",
+			genre: true,
+			group: GroupNone,
+			want: header +
+				"Teardrop;;;;trip hop, electronic;https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT;;;;;;;;;\n" +
+				"Untagged;;;;;https://open.spotify.com/track/67WTwafOMgegV6ABnBQxcE;;;;;;;;;\n" +
+				"Around the World;;;;House;https://open.spotify.com/track/1pKYYY0dkg23sQQXi0Q5zN;;;;;;;;;\n" +
+				"Angel;;;;trip hop;https://open.spotify.com/track/7uv632EkfwYhXoqf8rhYrg;;;;;;;;;\n",
+		},
+		{
+			name:  "grouped in sort order",
+			order: SortTitle,
+			group: GroupGenre,
+			want: header +
+				"Around the World;;;;House;https://open.spotify.com/track/1pKYYY0dkg23sQQXi0Q5zN;;;;;;;;;\n" +
+				"Angel;;;;trip hop;https://open.spotify.com/track/7uv632EkfwYhXoqf8rhYrg;;;;;;;;;\n" +
+				"Teardrop;;;;trip hop, electronic;https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT;;;;;;;;;\n" +
+				"Untagged;;;;;https://open.spotify.com/track/67WTwafOMgegV6ABnBQxcE;;;;;;;;;\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			summary, err := newProcessor(tt.genre).
+				SummarizeThreadAs(t.Context(), msgs, "C123", "1700000000.000100", SummaryOptions{Order: tt.order, Group: tt.group})
+			require.NoError(t, err)
+
+			got, err := io.ReadAll(summary.Upload.Reader)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestSummaryTable_WithGenre(t *testing.T) {
+	t.Parallel()
+
+	table := newSummaryTable(nil, true, false, false).withGenre()
+
+	assert.Equal(t, []string{"Title", "Duration", "Shared by", "Shared at", "Artwork URL", "Genre"}, table.header[:6])
+	assert.Equal(t, table.header, table.withGenre().header)
+	assert.Len(t, newSummaryTable(nil, true, false, false).header, len(table.header)-1)
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+)
+
+// providerOrder returns the configured providers in matching order,
+// the prioritized ones come first, followed by the others in the order of the CSV columns and by name.
+func providerOrder(
+	priority []musicextractors.ExtractProvider,
+	processors map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc,
+) []musicextractors.ExtractProvider {
+	order := make([]musicextractors.ExtractProvider, 0, len(processors))
+
+	appendConfigured := func(p musicextractors.ExtractProvider) {
+		if _, ok := processors[p]; ok && !slices.Contains(order, p) {
+			order = append(order, p)
+		}
+	}
+
+	for _, p := range priority {
+		appendConfigured(p)
+	}
+
+	for _, c := range csvColumns {
+		for _, p := range c.providers {
+			appendConfigured(p)
+		}
+	}
+
+	for _, p := range slices.Sorted(maps.Keys(processors)) {
+		appendConfigured(p)
+	}
+
+	return order
+}
+
+// matchURL runs the extractors of every provider except the disabled ones on text,
+// the first provider in priority order with a URL or an error wins.
+//
+// Returns the found URL, its provider and an error if any, ErrNoURLFound when no provider matched.
+func (s *messageProcessorDomain) matchURL(
+	ctx context.Context,
+	text string,
+	disabled []musicextractors.ExtractProvider,
+) (string, musicextractors.ExtractProvider, error) {
+	// Most messages are chat only, every provider pattern starts with the scheme
+	if !strings.Contains(text, "http") {
+		return "", "", musicextractors.ErrNoURLFound
+	}
+
+	return s.matchProviders(ctx, text, disabled)
+}
+
+// matchProviders runs the extractors one after the other in priority order, stopping at the first one
+// that finds a URL or fails with something else than no URL, every run is reported to the extraction observer.
+func (s *messageProcessorDomain) matchProviders(
+	ctx context.Context,
+	text string,
+	disabled []musicextractors.ExtractProvider,
+) (string, musicextractors.ExtractProvider, error) {
+	for _, p := range s.priority {
+		if slices.Contains(disabled, p) {
+			continue
+		}
+
+		start := time.Now()
+
+		url, provider, err := s.processors[p](text)
+		s.extractions.URLMatched(ctx, p, time.Since(start), err)
+
+		if !errors.Is(err, musicextractors.ErrNoURLFound) {
+			return url, provider, err
+		}
+	}
+
+	return "", "", musicextractors.ErrNoURLFound
+}
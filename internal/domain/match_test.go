@@ -0,0 +1,269 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// builtinURLExtractors are the URL extractors of every built-in provider.
+var builtinURLExtractors = map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+	musicextractors.SpotifyProvider:       musicextractors.SpotifyURLExtractor,
+	musicextractors.YouTubeProvider:       musicextractors.YouTubeURLExtractor,
+	musicextractors.YoutTubeMusicProvider: musicextractors.YouTubeMusicURLExtractor,
+	musicextractors.MixcloudProvider:      musicextractors.MixcloudURLExtractor,
+	musicextractors.AudiomackProvider:     musicextractors.AudiomackURLExtractor,
+	musicextractors.AmazonMusicProvider:   musicextractors.AmazonMusicURLExtractor,
+}
+
+// longMessage pads text to a message of about 20KB, like a pasted tracklist.
+func longMessage(text string) string {
+	return strings.Repeat("lorem ipsum dolor sit amet ", 800) + text
+}
+
+// newMatcher returns a processor matching the built-in providers, the given ones first.
+func newMatcher(priority []musicextractors.ExtractProvider) *messageProcessorDomain {
+	return &messageProcessorDomain{
+		processors:  builtinURLExtractors,
+		priority:    providerOrder(priority, builtinURLExtractors),
+		extractions: noopExtractionObserver{},
+	}
+}
+
+// ctxKey tags the context of a test, to tell it apart from another one.
+type ctxKey struct{}
+
+// recordingObserver keeps the providers the URL extractors ran for, with the error of each.
+type recordingObserver struct {
+	errs      map[musicextractors.ExtractProvider]error
+	providers []musicextractors.ExtractProvider
+	tagged    bool
+}
+
+func (o *recordingObserver) URLMatched(ctx context.Context, provider musicextractors.ExtractProvider, _ time.Duration, err error) {
+	o.providers = append(o.providers, provider)
+	o.errs[provider] = err
+	o.tagged = ctx.Value(ctxKey{}) != nil
+}
+
+func TestProviderOrder(t *testing.T) {
+	t.Parallel()
+
+	processors := map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+		musicextractors.SpotifyProvider:   musicextractors.SpotifyURLExtractor,
+		musicextractors.YouTubeProvider:   musicextractors.YouTubeURLExtractor,
+		musicextractors.AudiomackProvider: musicextractors.AudiomackURLExtractor,
+		"jellyfin":                        musicextractors.SpotifyURLExtractor,
+		"bandcamp":                        musicextractors.SpotifyURLExtractor,
+	}
+
+	got := providerOrder([]musicextractors.ExtractProvider{"jellyfin", musicextractors.YouTubeProvider, "tidal"}, processors)
+
+	assert.Equal(t, []musicextractors.ExtractProvider{
+		"jellyfin",
+		musicextractors.YouTubeProvider,
+		musicextractors.SpotifyProvider,
+		musicextractors.AudiomackProvider,
+		"bandcamp",
+	}, got)
+}
+
+func TestMatchURL_Priority(t *testing.T) {
+	t.Parallel()
+
+	both := "https://www.youtube.com/watch?v=dQw4w9WgXcQ and https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"
+
+	tests := []struct {
+		name     string
+		text     string
+		want     musicextractors.ExtractProvider
+		priority []musicextractors.ExtractProvider
+		disabled []musicextractors.ExtractProvider
+	}{
+		{name: "default order", text: both, want: musicextractors.SpotifyProvider},
+		{
+			name:     "configured priority",
+			text:     both,
+			priority: []musicextractors.ExtractProvider{musicextractors.YouTubeProvider},
+			want:     musicextractors.YouTubeProvider,
+		},
+		{
+			name:     "disabled provider is skipped",
+			text:     both,
+			disabled: []musicextractors.ExtractProvider{musicextractors.SpotifyProvider},
+			want:     musicextractors.YouTubeProvider,
+		},
+		{name: "long message default order", text: longMessage(both), want: musicextractors.SpotifyProvider},
+		{
+			name:     "long message configured priority",
+			text:     longMessage(both),
+			priority: []musicextractors.ExtractProvider{musicextractors.YouTubeProvider},
+			want:     musicextractors.YouTubeProvider,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			smp := newMatcher(tt.priority)
+
+			_, got, err := smp.matchURL(t.Context(), tt.text, tt.disabled)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMatchURL_ObservesExtractions(t *testing.T) {
+	t.Parallel()
+
+	observer := &recordingObserver{errs: map[musicextractors.ExtractProvider]error{}}
+	smp := newMatcher([]musicextractors.ExtractProvider{musicextractors.YouTubeProvider, musicextractors.SpotifyProvider})
+	smp.extractions = observer
+
+	ctx := context.WithValue(t.Context(), ctxKey{}, true)
+
+	_, _, err := smp.matchURL(ctx, "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT", nil)
+	require.NoError(t, err)
+
+	// Matching stops at the first provider with a URL, the request context reaches the observer
+	assert.Equal(t, []musicextractors.ExtractProvider{musicextractors.YouTubeProvider, musicextractors.SpotifyProvider}, observer.providers)
+	require.ErrorIs(t, observer.errs[musicextractors.YouTubeProvider], musicextractors.ErrNoURLFound)
+	require.NoError(t, observer.errs[musicextractors.SpotifyProvider])
+	assert.True(t, observer.tagged)
+}
+
+func TestMatchURL_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr error
+		name    string
+		text    string
+	}{
+		{name: "no url", text: "just chatting", wantErr: musicextractors.ErrNoURLFound},
+		{name: "long message without url", text: longMessage("just chatting"), wantErr: musicextractors.ErrNoURLFound},
+		{
+			name:    "multiple links of the winning provider",
+			text:    longMessage("https://open.spotify.com/track/abc and https://open.spotify.com/track/def"),
+			wantErr: musicextractors.ErrMultipleResult,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			smp := newMatcher(nil)
+
+			_, _, err := smp.matchURL(t.Context(), tt.text, nil)
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func BenchmarkMatchURL_LongMessage(b *testing.B) {
+	smp := newMatcher(nil)
+	text := strings.Repeat(longMessage(""), 8) + "https://music.amazon.com/tracks/B0DTK1ZQ3L"
+
+	for b.Loop() {
+		_, _, _ = smp.matchURL(b.Context(), text, nil)
+	}
+}
+
+func BenchmarkMatchURL_ShortMessage(b *testing.B) {
+	smp := newMatcher(nil)
+	text := "Check out https://music.amazon.com/tracks/B0DTK1ZQ3L it's great"
+
+	for b.Loop() {
+		_, _, _ = smp.matchURL(b.Context(), text, nil)
+	}
+}
+
+func TestSkipMessage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		msg  slack.Msg
+		want bool
+	}{
+		{name: "link", msg: slack.Msg{Text: "https://open.spotify.com/track/abc"}, want: false},
+		{name: "chat", msg: slack.Msg{Text: "nice one"}, want: false},
+		{name: "empty", msg: slack.Msg{Text: ""}, want: true},
+		{name: "whitespace", msg: slack.Msg{Text: " \n"}, want: true},
+		{name: "deleted parent", msg: slack.Msg{Text: "This message was deleted.", SubType: msgSubTypeTombstone}, want: true},
+		{name: "deleted", msg: slack.Msg{SubType: slack.MsgSubTypeMessageDeleted}, want: true},
+		{name: "bot", msg: slack.Msg{Text: "https://open.spotify.com/track/abc", BotID: "B1"}, want: true},
+		{name: "bot subtype", msg: slack.Msg{Text: "https://open.spotify.com/track/abc", SubType: slack.MsgSubTypeBotMessage}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, skipMessage(slack.Message{Msg: tt.msg}))
+		})
+	}
+}
+
+// chatThread is a thread of mostly chat messages, every tenth message shares a link.
+func chatThread() []slack.Message {
+	msgs := make([]slack.Message, 0, 1000)
+
+	for i := range cap(msgs) {
+		text := "haha that drop is insane, who is coming to the gig next week?"
+		if i%10 == 0 {
+			text = "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"
+		}
+
+		msgs = append(msgs, slack.Message{Msg: slack.Msg{Text: text}})
+	}
+
+	return msgs
+}
+
+func BenchmarkMatchURL_ChatMessage(b *testing.B) {
+	smp := newMatcher(nil)
+	text := "haha that drop is insane, who is coming to the gig next week?"
+
+	for b.Loop() {
+		_, _, _ = smp.matchURL(b.Context(), text, nil)
+	}
+}
+
+func BenchmarkMatchURL_ChatMessageWithoutPrefilter(b *testing.B) {
+	smp := newMatcher(nil)
+	text := "haha that drop is insane, who is coming to the gig next week?"
+
+	for b.Loop() {
+		_, _, _ = smp.matchProviders(b.Context(), text, nil)
+	}
+}
+
+func BenchmarkExtractAll_ChatThread(b *testing.B) {
+	smp := &messageProcessorDomain{
+		processors: builtinURLExtractors,
+		priority:   providerOrder(nil, builtinURLExtractors),
+		metadataParser: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: staticTitle("Spotify Song"),
+		},
+		concurrency: 8,
+		observer:    noopPoolObserver{},
+		extractions: noopExtractionObserver{},
+	}
+	msgs := chatThread()
+
+	for b.Loop() {
+		links, _, err := smp.extractAll(b.Context(), msgs, extractScope{concurrency: smp.concurrency})
+		require.NoError(b, err)
+		require.NoError(b, links.close())
+	}
+}
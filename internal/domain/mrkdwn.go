@@ -0,0 +1,12 @@
+package domain
+
+import "strings"
+
+// mrkdwnText escapes the control characters of Slack's mrkdwn.
+var mrkdwnText = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// EscapeMrkdwn escapes text from outside of the bot, like the titles the providers return,
+// so it can't break the links or trigger the mentions of the mrkdwn messages it's put in.
+func EscapeMrkdwn(text string) string {
+	return mrkdwnText.Replace(text)
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+)
+
+// PoolObserver is notified about the worker pool extracting the links of a summary,
+// like to record its size, queue depth and saturation as metrics.
+type PoolObserver interface {
+	// WorkersChanged is called with the number of workers started for a batch of messages,
+	// and with the negated number once they stopped.
+	WorkersChanged(ctx context.Context, delta int)
+	// QueueChanged is called with the number of messages waiting for a worker when a batch starts,
+	// and with -1 every time a worker picks up one of them.
+	QueueChanged(ctx context.Context, delta int)
+	// JobStarted is called when a worker picks up a message, with how long the message waited for it.
+	JobStarted(ctx context.Context, wait time.Duration)
+	// JobDone is called when a worker finished a message.
+	JobDone(ctx context.Context)
+}
+
+// noopPoolObserver is the PoolObserver of processors without one.
+type noopPoolObserver struct{}
+
+var _ PoolObserver = noopPoolObserver{}
+
+func (noopPoolObserver) WorkersChanged(context.Context, int)       {}
+func (noopPoolObserver) QueueChanged(context.Context, int)         {}
+func (noopPoolObserver) JobStarted(context.Context, time.Duration) {}
+func (noopPoolObserver) JobDone(context.Context)                   {}
+
+// ExtractionObserver is notified about every provider tried on a message, like to record the URL matches as metrics.
+type ExtractionObserver interface {
+	// URLMatched is called after the URL extractor of provider ran on a message, with how long it took
+	// and its error, ErrNoURLFound when the message has no link of the provider.
+	URLMatched(ctx context.Context, provider musicextractors.ExtractProvider, elapsed time.Duration, err error)
+}
+
+// noopExtractionObserver is the ExtractionObserver of processors without one.
+type noopExtractionObserver struct{}
+
+var _ ExtractionObserver = noopExtractionObserver{}
+
+func (noopExtractionObserver) URLMatched(context.Context, musicextractors.ExtractProvider, time.Duration, error) {
+}
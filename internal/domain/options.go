@@ -0,0 +1,66 @@
+package domain
+
+import "github.com/Shikachuu/wap-bot/pkg/musicextractors"
+
+// SummaryOptions are the settings of a single summary, the zero value summarizes with the configured ones.
+type SummaryOptions struct {
+	// Format is the file format of the summary, empty means the configured one.
+	Format ExportFormat
+	// CSV are the CSV options applied on top of the configured ones, only the set fields override them.
+	CSV CSVOptions
+	// Providers restricts the providers of the summary on top of the ones disabled in the channel.
+	Providers ProviderFilter
+	// Order is the order of the rows, empty means the order they were shared in.
+	Order SortOrder
+	// Group clusters the rows, empty means they aren't.
+	Group GroupBy
+	// Dedupe is the built-in strategy that decides which links are merged into a row,
+	// empty means the strategy of the channel or the configured one.
+	Dedupe DedupeStrategyName
+	// Anonymous leaves out who shared the tracks and when from the summary, for summaries shared outside the channel
+	// they were made in. The tracks of the Summary keep them, only what is posted is anonymous.
+	Anonymous bool
+	// Concurrency is the number of messages processed in parallel, values below 1 mean the configured one.
+	Concurrency int
+	// Progress is optional, when set it's called every time a message of the summary was processed.
+	Progress ProgressFunc
+}
+
+// ProgressFunc is called with the number of processed messages of a summary and the number of its messages,
+// including the tracks of the expanded playlists. It's called by the extraction workers, so it has to be safe for concurrent use.
+type ProgressFunc func(done, total int)
+
+// IsZero reports if the options summarize with the configured settings and without reporting their progress.
+func (o SummaryOptions) IsZero() bool {
+	return o.Format == "" && o.CSV == (CSVOptions{}) && o.Providers.IsZero() && o.Order == "" && o.Group == "" &&
+		o.Dedupe == "" && !o.Anonymous && o.Concurrency < 1 && o.Progress == nil
+}
+
+// extractScope is what the extraction of a summary looks at, the providers left out,
+// the number of messages processed in parallel and who is told about its progress.
+type extractScope struct {
+	progress    ProgressFunc
+	disabled    []musicextractors.ExtractProvider
+	concurrency int
+}
+
+// extractScope returns the scope of a summary of the channel with opts.
+func (s *messageProcessorDomain) extractScope(channelID string, opts SummaryOptions) extractScope {
+	scope := extractScope{disabled: s.disabled(channelID, opts.Providers), concurrency: s.concurrency, progress: opts.Progress}
+	if opts.Concurrency > 0 {
+		scope.concurrency = opts.Concurrency
+	}
+
+	return scope
+}
+
+// dedupeFor returns the dedupe strategy of a summary of the channel with opts.
+//
+// Returns ErrUnsupportedDedupeStrategy if the options name an unknown strategy.
+func (s *messageProcessorDomain) dedupeFor(channelID string, opts SummaryOptions) (DedupeStrategy, error) {
+	if opts.Dedupe == "" {
+		return s.dedupeStrategy(channelID), nil
+	}
+
+	return NewDedupeStrategy(opts.Dedupe)
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"io"
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummaryOptions_IsZero(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, SummaryOptions{}.IsZero())
+	assert.True(t, SummaryOptions{Concurrency: -1}.IsZero())
+	assert.False(t, SummaryOptions{Format: ExportFormatJSON}.IsZero())
+	assert.False(t, SummaryOptions{CSV: CSVOptions{BOM: true}}.IsZero())
+	assert.False(t, SummaryOptions{Providers: ProviderFilter{Except: []musicextractors.ExtractProvider{musicextractors.YouTubeProvider}}}.IsZero())
+	assert.False(t, SummaryOptions{Dedupe: DedupeNone}.IsZero())
+	assert.False(t, SummaryOptions{Anonymous: true}.IsZero())
+	assert.False(t, SummaryOptions{Concurrency: 2}.IsZero())
+	assert.False(t, SummaryOptions{Progress: func(int, int) {}}.IsZero())
+}
+
+func TestMessageProcessor_SummarizeThreadAs_Options(t *testing.T) {
+	t.Parallel()
+
+	smp := NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: staticTitle("Never Gonna Give You Up"),
+		},
+		Dedupe:      dedupeStrategies[DedupeURL],
+		Format:      ExportFormatM3U8,
+		Compression: Compression{Kind: CompressionNone},
+	})
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+		{Msg: slack.Msg{Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+	}
+
+	tests := []struct {
+		name string
+		opts SummaryOptions
+		want string
+	}{
+		{
+			name: "configured",
+			want: "#EXTM3U\n#EXTINF:-1,Never Gonna Give You Up\nhttps://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT\n",
+		},
+		{
+			name: "dedupe and concurrency",
+			opts: SummaryOptions{Dedupe: DedupeNone, Concurrency: 4},
+			want: "#EXTM3U\n" +
+				"#EXTINF:-1,Never Gonna Give You Up\nhttps://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT\n" +
+				"#EXTINF:-1,Never Gonna Give You Up\nhttps://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			summary, err := smp.SummarizeThreadAs(t.Context(), msgs, "C123", "1700000000.000100", tt.opts)
+			require.NoError(t, err)
+
+			got, err := io.ReadAll(summary.Upload.Reader)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+
+	_, err := smp.SummarizeThreadAs(t.Context(), msgs, "C123", "1700000000.000100", SummaryOptions{Dedupe: "fuzzy"})
+	require.ErrorIs(t, err, ErrUnsupportedDedupeStrategy)
+}
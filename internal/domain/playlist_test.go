@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePlaylistExpander expands the playlist links of its map, every other text has no playlist.
+type fakePlaylistExpander map[string][]string
+
+func (f fakePlaylistExpander) ExpandPlaylist(_ context.Context, text string) ([]string, error) {
+	if text == "https://open.spotify.com/playlist/broken" {
+		return nil, musicextractors.ErrRateLimited
+	}
+
+	tracks, ok := f[text]
+	if !ok {
+		return nil, musicextractors.ErrNoURLFound
+	}
+
+	return tracks, nil
+}
+
+func newPlaylistProcessor() MessageProcessorDomain {
+	return NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+			musicextractors.YouTubeProvider: musicextractors.YouTubeURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: func(_ context.Context, url string) (musicextractors.TrackMetadata, error) {
+				return musicextractors.TrackMetadata{Title: url[len(url)-1:]}, nil
+			},
+			musicextractors.YouTubeProvider: staticTitle("YouTube Song"),
+		},
+		PlaylistExpander: fakePlaylistExpander{
+			"https://open.spotify.com/playlist/mix": {
+				"https://open.spotify.com/track/1",
+				"https://open.spotify.com/track/2",
+			},
+		},
+		Format:      ExportFormatCSV,
+		Compression: Compression{Kind: CompressionNone},
+	})
+}
+
+func TestMessageProcessor_SummarizeThread_ExpandsPlaylists(t *testing.T) {
+	t.Parallel()
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{User: "U1", Timestamp: "1700000001.000100", Text: "<https://open.spotify.com/playlist/mix>"}},
+		{Msg: slack.Msg{User: "U2", Timestamp: "1700000002.000100", Text: "https://www.youtube.com/watch?v=dQw4w9WgXcQ"}},
+		{Msg: slack.Msg{User: "U3", Timestamp: "1700000003.000100", Text: "https://open.spotify.com/playlist/broken"}},
+	}
+
+	summary, err := newPlaylistProcessor().SummarizeThread(t.Context(), msgs, "C-ANY", "1700000000.000100")
+	require.NoError(t, err)
+
+	require.Len(t, summary.Tracks, 3)
+	assert.Equal(t, "1", summary.Tracks[0].Title)
+	assert.Equal(t, "U1", summary.Tracks[0].UserID)
+	assert.Equal(t, "2", summary.Tracks[1].Title)
+	assert.Equal(t, "1700000001.000100", summary.Tracks[1].MessageTS)
+	assert.Equal(t, "YouTube Song", summary.Tracks[2].Title)
+	assert.Equal(t, map[musicextractors.ErrorKind]int{musicextractors.ErrorKindRateLimited: 1}, summary.Skipped)
+}
+
+func TestMessageProcessor_SummarizeThreadAs_TranscriptListsPlaylistTracks(t *testing.T) {
+	t.Parallel()
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{User: "U1", Timestamp: "1700000000.000100", Text: "https://open.spotify.com/playlist/mix"}},
+	}
+
+	summary, err := newPlaylistProcessor().SummarizeThreadAs(t.Context(), msgs, "C-ANY", "1700000000.000100", SummaryOptions{Format: ExportFormatTranscript})
+	require.NoError(t, err)
+
+	got, err := io.ReadAll(summary.Upload.Reader)
+	require.NoError(t, err)
+	assert.Contains(t, string(got), "https://open.spotify.com/playlist/mix\n\n"+
+		"- [1](<https://open.spotify.com/track/1>) `spotify`\n"+
+		"- [2](<https://open.spotify.com/track/2>) `spotify`\n")
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"bytes"
+	"sync"
+)
+
+// maxPooledBufferBytes caps the buffers kept for reuse, so a single huge summary doesn't pin its memory.
+const maxPooledBufferBytes = 4 << 20
+
+// bufferPool reuses the scratch buffers the summaries are encoded and compressed in.
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// rowPool reuses the scratch rows of the CSV export.
+var rowPool = sync.Pool{
+	New: func() any { return new([]string) },
+}
+
+// getBuffer returns an empty scratch buffer from the pool.
+func getBuffer() *bytes.Buffer {
+	b, _ := bufferPool.Get().(*bytes.Buffer)
+	b.Reset()
+
+	return b
+}
+
+// detachBuffer copies the content of b into a right-sized reader and returns b to the pool.
+//
+// Returns the reader and its size.
+func detachBuffer(b *bytes.Buffer) (*bytes.Reader, int) {
+	raw := bytes.Clone(b.Bytes())
+	releaseBuffer(b)
+
+	return bytes.NewReader(raw), len(raw)
+}
+
+// releaseBuffer returns b to the pool, unless it grew too large to be kept.
+func releaseBuffer(b *bytes.Buffer) {
+	if b.Cap() <= maxPooledBufferBytes {
+		bufferPool.Put(b)
+	}
+}
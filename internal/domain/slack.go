@@ -2,136 +2,967 @@
 package domain
 
 import (
-	"bytes"
-	"encoding/csv"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/Shikachuu/wap-bot/pkg/textsummarizer"
 	"github.com/slack-go/slack"
 )
 
 type parsedMusicLink struct {
-	Title string
-	URL   string
-	Type  musicextractors.ExtractProvider
+	// CrossLinks contains the URL of the same track on other providers, if cross-linking is enabled.
+	CrossLinks map[musicextractors.ExtractProvider]string
+	Title      string
+	URL        string
+	Type       musicextractors.ExtractProvider
+	// MessageTS and UserID identify the message the link was shared in.
+	MessageTS string
+	UserID    string
+	Metadata  musicextractors.TrackMetadata
+	// TimesShared is the number of links of the thread merged into this one, only set on the rows of the exports.
+	TimesShared int
+}
+
+// links returns the URL of the track for every known provider, the originally shared URL takes precedence.
+func (pml parsedMusicLink) links() map[musicextractors.ExtractProvider]string {
+	links := make(map[musicextractors.ExtractProvider]string, len(pml.CrossLinks)+1)
+	maps.Copy(links, pml.CrossLinks)
+	links[pml.Type] = pml.URL
+
+	return links
 }
 
 // MessageProcessorDomain contains the core business logic to iterate over a thread and pull every implemented music related info from them.
 type MessageProcessorDomain interface {
-	SummarizeThread(msgs []slack.Message, channelID, threadTS string) (slack.UploadFileV2Parameters, error)
+	SummarizeThread(ctx context.Context, msgs []slack.Message, channelID, threadTS string) (Summary, error)
+	// SummarizeThreadAs is SummarizeThread with the given options on top of the configured ones.
+	SummarizeThreadAs(ctx context.Context, msgs []slack.Message, channelID, threadTS string, opts SummaryOptions) (Summary, error)
+	// SummarizeChannel is SummarizeThreadAs for the top-level messages of a channel, the summary has no thread.
+	SummarizeChannel(ctx context.Context, msgs []slack.Message, channelID string, opts SummaryOptions) (Summary, error)
+	// ContainsMusicURL reports if text has a link of a provider that is enabled in the channel, without resolving it.
+	ContainsMusicURL(ctx context.Context, channelID, text string) bool
+	// RetryTitles looks up the failed titles of a previous summary again and creates an updated summary of the thread
+	// from them and the tracks the previous summary resolved, without looking the latter up again.
+	RetryTitles(ctx context.Context, channelID, threadTS string, resolved []Track, failed []FailedTitle) (Summary, error)
+	// SummarizeThreadSince creates an updated summary of a thread from the tracks and the failed titles of its previous summary
+	// and the messages posted since, only resolving the latter, with the given options on top of the configured ones.
+	SummarizeThreadSince(
+		ctx context.Context,
+		msgs []slack.Message,
+		channelID, threadTS string,
+		previous []Track,
+		failed []FailedTitle,
+		opts SummaryOptions,
+	) (Summary, error)
+	// EnabledProviders returns the providers with a configured extractor in matching order.
+	EnabledProviders() []musicextractors.ExtractProvider
+	// ExtractTracks resolves the tracks of the messages without creating a summary, like for a playlist of the thread.
+	ExtractTracks(ctx context.Context, msgs []slack.Message, channelID string) ([]Track, error)
+}
+
+// ProcessorConfig contains the dependencies and settings of the message processor.
+type ProcessorConfig struct {
+	// URLExtractors finds the music URLs of every enabled provider.
+	URLExtractors map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc
+	// MetadataExtractors resolves the metadata of the URLs found by URLExtractors.
+	MetadataExtractors map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc
+	// Priority orders the providers whose link is used when a message has links of several providers,
+	// unlisted providers follow in the order of the CSV columns.
+	Priority []musicextractors.ExtractProvider
+	// ChannelDisabled maps channel IDs to providers that are ignored during extraction in that channel,
+	// on top of the globally enabled extractors.
+	ChannelDisabled map[string][]musicextractors.ExtractProvider
+	// CrossLinker is optional, when set every track is looked up on the other providers as well.
+	CrossLinker musicextractors.CrossLinker
+	// ShortURLResolver is optional, when set short links are replaced with the URL they redirect to before matching.
+	ShortURLResolver musicextractors.ShortURLResolver
+	// PlaylistExpander is optional, when set shared playlists are summarized as their individual tracks.
+	PlaylistExpander musicextractors.PlaylistExpander
+	// Enricher is optional, when set the metadata of every track is extended with it, like the release year and genres.
+	Enricher musicextractors.Enricher
+	// TextSummarizer is optional, when set the summaries of whole threads have a prose recap of the discussion
+	// in their comment.
+	TextSummarizer textsummarizer.TextSummarizer
+	// Dedupe decides which links of a summary are merged into one row, DedupeISRC is used if nil.
+	Dedupe DedupeStrategy
+	// ChannelDedupe maps channel IDs to the strategy used instead of Dedupe in that channel.
+	ChannelDedupe map[string]DedupeStrategy
+	// DedupeAcrossProviders also merges the links of the same song on different providers into one row, recognized by
+	// their track IDs, the ones of their cross-links and their ISRC, so the provider columns of a song are filled together.
+	DedupeAcrossProviders bool
+	// Format is the file format of every summary.
+	Format ExportFormat
+	// Artwork adds the artwork URL of every track to the CSV summaries, the JSON export always has it.
+	Artwork bool
+	// SkippedReport lists the links whose title couldn't be resolved with the reason in the XLSX, Markdown, HTML
+	// and JSON summaries, so they can be fixed or shared again.
+	SkippedReport bool
+	// Stats appends the unique tracks, the links per provider and the top sharers of the thread to the summary comment.
+	Stats bool
+	// CSV configures the delimiter, byte order mark and line endings of the CSV summaries.
+	CSV CSVOptions
+	// TimesShared adds the number of times every track was shared in the thread to the CSV summaries,
+	// the JSON export always has it.
+	TimesShared bool
+	// Genre adds the genres of every track to the CSV summaries, the JSON export always has them.
+	Genre bool
+	// Templates customize the headline of the summary comments and the name of the summary files.
+	Templates SummaryTemplates
+	// Compression configures how large summaries are compressed.
+	Compression Compression
+	// Concurrency is the number of messages processed in parallel, values below 1 mean sequential processing.
+	Concurrency int
+	// PoolObserver is optional, when set it's notified about the worker pool of every summary.
+	PoolObserver PoolObserver
+	// ExtractionObserver is optional, when set it's notified about every URL extractor run on a message.
+	ExtractionObserver ExtractionObserver
+	// UserResolver is optional, when set the CSV summaries list who shared every track by name instead of their user ID.
+	// The anonymous summaries never resolve any name.
+	UserResolver UserResolver
+	// SpillThreshold is the number of links kept in memory during a summary, the rest is spilled to a temporary file,
+	// values below 1 keep every link in memory.
+	SpillThreshold int
+	// FileSpillBytes is the size of a summary file kept in memory, larger files are written to a temporary file
+	// and uploaded from there, values below 1 keep every file in memory.
+	FileSpillBytes int
 }
 
 type messageProcessorDomain struct {
-	processors  map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc
-	titleParser map[musicextractors.ExtractProvider]musicextractors.TitleExtractorFunc
+	processors      map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc
+	priority        []musicextractors.ExtractProvider
+	metadataParser  map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc
+	channelDisabled map[string][]musicextractors.ExtractProvider
+	crossLinker     musicextractors.CrossLinker
+	enricher        musicextractors.Enricher
+	shortURLs       musicextractors.ShortURLResolver
+	playlists       musicextractors.PlaylistExpander
+	dedupe          DedupeStrategy
+	channelDedupe   map[string]DedupeStrategy
+	// dedupeAcrossProviders also merges the links of the same song on different providers.
+	dedupeAcrossProviders bool
+	format                ExportFormat
+	csv                   CSVOptions
+	stats                 bool
+	skippedReport         bool
+	artwork               bool
+	timesShared           bool
+	genre                 bool
+	templates             SummaryTemplates
+	compression           Compression
+	concurrency           int
+	spillThreshold        int
+	fileSpillBytes        int
+	textSummarizer        textsummarizer.TextSummarizer
+	observer              PoolObserver
+	extractions           ExtractionObserver
+	users                 UserResolver
+	encoders              map[ExportFormat]SummaryEncoder
 }
 
 var _ MessageProcessorDomain = (*messageProcessorDomain)(nil)
 
-func (s *messageProcessorDomain) extractMusicURL(text string) (parsedMusicLink, error) {
-	for _, process := range s.processors {
-		url, p, err := process(text)
-		if err != nil {
-			if errors.Is(err, musicextractors.ErrNoURLFound) {
-				continue
+// extractMusicURL runs every provider's extractor on text, except the disabled ones.
+func (s *messageProcessorDomain) extractMusicURL(ctx context.Context, text string, disabled []musicextractors.ExtractProvider) (parsedMusicLink, error) {
+	// Slack wraps links as <url|label>, which would otherwise end up in the extracted URLs
+	text = musicextractors.UnwrapSlackLinks(text)
+
+	if s.shortURLs != nil {
+		text = s.shortURLs.ResolveText(ctx, text)
+	}
+
+	url, p, err := s.matchURL(ctx, text, disabled)
+	if err != nil {
+		return parsedMusicLink{}, musicextractors.NewExtractionError(p, "", err)
+	}
+
+	// The canonical form keeps the same track shared with different share tokens on the same URL
+	if canonical, nErr := musicextractors.NormalizeURL(url); nErr == nil {
+		url = canonical
+	}
+
+	return s.resolve(ctx, url, p)
+}
+
+// resolve looks up the metadata and the cross-links of a link of provider p.
+func (s *messageProcessorDomain) resolve(ctx context.Context, url string, p musicextractors.ExtractProvider) (parsedMusicLink, error) {
+	md, err := s.metadataParser[p](ctx, url)
+	// An open circuit means the provider is down, the link is still listed, only without a title
+	if err != nil && !errors.Is(err, musicextractors.ErrCircuitOpen) {
+		return parsedMusicLink{}, musicextractors.NewExtractionError(p, url, err)
+	}
+
+	md = s.enrich(ctx, md)
+
+	return parsedMusicLink{
+		Title:      musicextractors.NormalizeTitle(md.DisplayTitle()),
+		URL:        url,
+		Type:       p,
+		Metadata:   md,
+		CrossLinks: s.crossLink(ctx, url),
+	}, nil
+}
+
+// ContainsMusicURL reports if text has a link of a provider that is enabled in the channel, without resolving it.
+func (s *messageProcessorDomain) ContainsMusicURL(ctx context.Context, channelID, text string) bool {
+	_, _, err := s.matchURL(ctx, musicextractors.UnwrapSlackLinks(text), s.channelDisabled[channelID])
+
+	return err == nil
+}
+
+// extractAll runs extractMusicURL on every message in batches of the spill threshold,
+// so only a single batch of results is kept in memory on top of the links buffer.
+//
+// Returns the found links in the order of the messages and the failures of the others,
+// messages without a link are skipped silently.
+func (s *messageProcessorDomain) extractAll(
+	ctx context.Context,
+	msgs []slack.Message,
+	scope extractScope,
+) (*linkBuffer, *summaryCounts, error) {
+	links := newLinkBuffer(s.spillThreshold)
+	counts := newSummaryCounts()
+	counts.messages = len(msgs)
+
+	if err := s.extractInto(ctx, links, counts, msgs, scope); err != nil {
+		_ = links.close()
+
+		return nil, nil, err
+	}
+
+	return links, counts, nil
+}
+
+// extractInto is extractAll adding the links and the failures of the messages to the given ones.
+func (s *messageProcessorDomain) extractInto(
+	ctx context.Context,
+	links *linkBuffer,
+	counts *summaryCounts,
+	msgs []slack.Message,
+	scope extractScope,
+) error {
+	msgs = s.expandPlaylists(ctx, msgs, counts)
+	progress := &progressCounter{report: scope.progress, total: len(msgs)}
+
+	batchSize := len(msgs)
+	if s.spillThreshold > 0 {
+		batchSize = s.spillThreshold
+	}
+
+	for batch := range slices.Chunk(msgs, max(1, batchSize)) {
+		for _, r := range s.extractBatch(ctx, batch, scope, progress) {
+			if err := counts.collect(links, r); err != nil {
+				return err
 			}
+		}
+	}
+
+	return nil
+}
+
+// collect adds the link of a successful extraction to links, or records the failed one as skipped.
+//
+// Links whose title couldn't be resolved are added to the failed titles too, including the ones listed without a title
+// because the circuit of their provider was open.
+func (c *summaryCounts) collect(links *linkBuffer, r extractResult) error {
+	if r.err != nil {
+		c.skip(r.link.MessageTS, r.link.UserID, r.err)
+
+		var extErr *musicextractors.ExtractionError
+		if errors.As(r.err, &extErr) && extErr.URL != "" {
+			t := r.link.track()
+			t.URL, t.Provider = extErr.URL, extErr.Provider
+
+			c.failed = append(c.failed, FailedTitle{Track: t, Kind: extErr.Kind})
+		}
+
+		return nil
+	}
+
+	if r.link.Title == "" {
+		c.failed = append(c.failed, FailedTitle{Track: r.link.track(), Kind: musicextractors.ErrorKindUnavailable})
+	}
 
-			return parsedMusicLink{}, fmt.Errorf("url parsing: %w", err)
+	return links.add(r.link)
+}
+
+// expandPlaylists replaces every message sharing a playlist with a copy of the message per track of the playlist,
+// so every track gets its own row, attributed to the message the playlist was shared in.
+//
+// Playlists that can't be expanded are recorded as skipped by the kind of the failure, their message is kept as is.
+func (s *messageProcessorDomain) expandPlaylists(
+	ctx context.Context,
+	msgs []slack.Message,
+	counts *summaryCounts,
+) []slack.Message {
+	if s.playlists == nil {
+		return msgs
+	}
+
+	expanded := make([]slack.Message, 0, len(msgs))
+
+	for _, m := range msgs {
+		if skipMessage(m) {
+			expanded = append(expanded, m)
+			continue
 		}
 
-		title, err := s.titleParser[p](url)
+		tracks, err := s.playlists.ExpandPlaylist(ctx, musicextractors.UnwrapSlackLinks(m.Text))
 		if err != nil {
-			return parsedMusicLink{}, fmt.Errorf("title parsing: %w", err)
+			if !errors.Is(err, musicextractors.ErrNoURLFound) {
+				counts.skip(m.Timestamp, m.User, musicextractors.NewExtractionError("", "", err))
+			}
+
+			expanded = append(expanded, m)
+
+			continue
 		}
 
-		return parsedMusicLink{
-			Title: title,
-			URL:   url,
-			Type:  p,
-		}, nil
+		for _, track := range tracks {
+			m.Text = track
+			expanded = append(expanded, m)
+		}
 	}
 
-	return parsedMusicLink{}, musicextractors.ErrNoURLFound
+	return expanded
 }
 
-// SummarizeThread iterates over every message and creates a summarized response.
+// skip counts a failed extraction of the message by its kind and records its failure,
+// messages without a link are not counted.
+func (c *summaryCounts) skip(messageTS, userID string, err error) {
+	failure := MessageFailure{MessageTS: messageTS, UserID: userID, Kind: musicextractors.ErrorKindUnknown, Err: err}
+
+	var extErr *musicextractors.ExtractionError
+	if errors.As(err, &extErr) {
+		failure.URL, failure.Provider, failure.Kind = extErr.URL, extErr.Provider, extErr.Kind
+	}
+
+	if failure.Kind == musicextractors.ErrorKindNoURL {
+		return
+	}
+
+	c.skipped[failure.Kind]++
+	c.failures = append(c.failures, failure)
+}
+
+// extractResult is the outcome of extracting the link of a single message.
+type extractResult struct {
+	err  error
+	link parsedMusicLink
+}
+
+// progressCounter counts the processed messages of a summary for its ProgressFunc, it's safe for concurrent use.
+type progressCounter struct {
+	report ProgressFunc
+	total  int
+	done   atomic.Int64
+}
+
+// add counts n more processed messages and reports the progress, it does nothing without a ProgressFunc.
+func (p *progressCounter) add(n int) {
+	if p.report == nil || n == 0 {
+		return
+	}
+
+	p.report(int(p.done.Add(int64(n))), p.total)
+}
+
+// extractBatch runs extractMusicURL on every message with a bounded pool of workers, every processed message
+// is added to progress.
 //
-// Returns the response file or an error if any.
-func (s *messageProcessorDomain) SummarizeThread(msgs []slack.Message, channelID, threadTS string) (slack.UploadFileV2Parameters, error) {
-	pmls := []parsedMusicLink{}
+// Returns the result of every message in their order, skipped messages have no URL found as their error.
+func (s *messageProcessorDomain) extractBatch(
+	ctx context.Context,
+	msgs []slack.Message,
+	scope extractScope,
+	progress *progressCounter,
+) []extractResult {
+	results := make([]extractResult, len(msgs))
+	queued := make([]int, 0, len(msgs))
 
-	for i := range msgs {
-		m, eErr := s.extractMusicURL(msgs[i].Text)
-		if eErr != nil {
+	for i, m := range msgs {
+		if skipMessage(m) {
+			results[i].err = musicextractors.NewExtractionError("", "", musicextractors.ErrNoURLFound)
 			continue
 		}
 
-		pmls = append(pmls, m)
+		queued = append(queued, i)
+	}
+
+	progress.add(len(msgs) - len(queued))
+
+	workers := max(1, min(scope.concurrency, len(queued)))
+	jobs := make(chan int)
+	start := time.Now()
+
+	s.observer.WorkersChanged(ctx, workers)
+	s.observer.QueueChanged(ctx, len(queued))
+
+	var wg sync.WaitGroup
+
+	for range workers {
+		wg.Go(func() {
+			for i := range jobs {
+				s.observer.QueueChanged(ctx, -1)
+				s.observer.JobStarted(ctx, time.Since(start))
+
+				m, err := s.extractMusicURL(ctx, msgs[i].Text, scope.disabled)
+				m.MessageTS, m.UserID = msgs[i].Timestamp, msgs[i].User
+				results[i] = extractResult{link: m, err: err}
+
+				s.observer.JobDone(ctx)
+				progress.add(1)
+			}
+		})
+	}
+
+	for _, i := range queued {
+		jobs <- i
+	}
+
+	close(jobs)
+	wg.Wait()
+	s.observer.WorkersChanged(ctx, -workers)
+
+	return results
+}
+
+// msgSubTypeTombstone is the subtype of the placeholder left behind by a deleted thread parent,
+// slack-go has no constant for it.
+const msgSubTypeTombstone = "tombstone"
+
+// skipMessage reports if m can't have a link, like empty messages and the placeholders of deleted ones,
+// or isn't shared by a user, like the inline summaries the bot posted to the thread before.
+func skipMessage(m slack.Message) bool {
+	return strings.TrimSpace(m.Text) == "" || m.SubType == slack.MsgSubTypeMessageDeleted || m.SubType == msgSubTypeTombstone ||
+		m.BotID != "" || m.SubType == slack.MsgSubTypeBotMessage
+}
+
+// enrich extends the metadata with the enricher.
+//
+// Enrichment is best-effort, a failed lookup leaves the metadata as the provider returned it.
+func (s *messageProcessorDomain) enrich(ctx context.Context, md musicextractors.TrackMetadata) musicextractors.TrackMetadata {
+	if s.enricher == nil {
+		return md
 	}
 
-	csvF, size, err := s.createCSV(pmls)
+	enriched, err := s.enricher.Enrich(ctx, md)
 	if err != nil {
-		return slack.UploadFileV2Parameters{}, fmt.Errorf("create csv: %w", err)
+		return md
 	}
 
-	fileName := fmt.Sprintf("%s-%s.csv", channelID, threadTS)
+	return enriched
+}
 
-	return slack.UploadFileV2Parameters{
-		Reader:          csvF,
-		Filename:        fileName,
-		Title:           fileName,
-		InitialComment:  fmt.Sprintf("Found %d music URLs in this thread", len(pmls)),
-		Channel:         channelID,
-		ThreadTimestamp: threadTS,
-		FileSize:        size,
-	}, nil
+// crossLink looks up the track on the other providers.
+//
+// Cross-links are best-effort, a failed lookup only leaves the other provider columns empty.
+func (s *messageProcessorDomain) crossLink(ctx context.Context, url string) map[musicextractors.ExtractProvider]string {
+	if s.crossLinker == nil {
+		return nil
+	}
+
+	links, err := s.crossLinker.CrossLink(ctx, url)
+	if err != nil {
+		return nil
+	}
+
+	return links
+}
+
+// dedupeStrategy returns the dedupe strategy of a channel.
+func (s *messageProcessorDomain) dedupeStrategy(channelID string) DedupeStrategy {
+	if d, ok := s.channelDedupe[channelID]; ok {
+		return d
+	}
+
+	return s.dedupe
+}
+
+// SummarizeThread iterates over every message and creates a summarized response in the configured format.
+//
+// Returns the summary or an error if any.
+func (s *messageProcessorDomain) SummarizeThread(
+	ctx context.Context,
+	msgs []slack.Message,
+	channelID, threadTS string,
+) (Summary, error) {
+	return s.SummarizeThreadAs(ctx, msgs, channelID, threadTS, SummaryOptions{})
+}
+
+// SummarizeThreadAs iterates over every message and creates a summarized response with the options, see SummaryOptions.
+// The links of the providers left out by the filter of the options are skipped like the ones of the providers
+// disabled in the channel.
+//
+// Returns the summary or an error if any.
+func (s *messageProcessorDomain) SummarizeThreadAs(
+	ctx context.Context,
+	msgs []slack.Message,
+	channelID, threadTS string,
+	opts SummaryOptions,
+) (Summary, error) {
+	return s.summarizeMessages(ctx, msgs, channelID, threadTS, opts, "in this thread")
+}
+
+// SummarizeChannel iterates over the top-level messages of a channel and creates a summarized response with the options
+// like SummarizeThreadAs, the summary isn't posted in a thread.
+//
+// Returns the summary or an error if any.
+func (s *messageProcessorDomain) SummarizeChannel(
+	ctx context.Context,
+	msgs []slack.Message,
+	channelID string,
+	opts SummaryOptions,
+) (Summary, error) {
+	return s.summarizeMessages(ctx, msgs, channelID, "", opts, fmt.Sprintf("in the last %d messages of this channel", len(msgs)))
+}
+
+// summarizeMessages creates the summary of msgs with the options, where describes the messages in the default comment,
+// like "in this thread".
+func (s *messageProcessorDomain) summarizeMessages(
+	ctx context.Context,
+	msgs []slack.Message,
+	channelID, threadTS string,
+	opts SummaryOptions,
+	where string,
+) (Summary, error) {
+	if opts.Format == "" {
+		opts.Format = s.format
+	}
+
+	links, counts, err := s.extractAll(ctx, msgs, s.extractScope(channelID, opts))
+	if err != nil {
+		return Summary{}, fmt.Errorf("extract links: %w", err)
+	}
+
+	defer func() { _ = links.close() }()
+
+	comment, err := s.templates.comment(templateData(channelID, threadTS, links.len()), fmt.Sprintf("Found %d music URLs %s", links.len(), where))
+	if err != nil {
+		return Summary{}, err
+	}
+
+	summary, err := s.summary(ctx, msgs, links, channelID, threadTS, opts, *counts, comment)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	// Only the whole thread or channel is recapped, the retried and the incremental summaries don't read it
+	summary.Digest = s.digest(ctx, msgs, opts.Anonymous)
+	summary.Upload.InitialComment += digestNote(summary.Digest)
+
+	return summary, nil
+}
+
+// ExtractTracks resolves the tracks of the messages in their order, the links that couldn't be resolved are left out.
+//
+// Returns the tracks or an error if any.
+func (s *messageProcessorDomain) ExtractTracks(ctx context.Context, msgs []slack.Message, channelID string) ([]Track, error) {
+	links, _, err := s.extractAll(ctx, msgs, s.extractScope(channelID, SummaryOptions{}))
+	if err != nil {
+		return nil, fmt.Errorf("extract links: %w", err)
+	}
+
+	defer func() { _ = links.close() }()
+
+	return tracks(links)
+}
+
+// summaryCounts are the failures of a summary besides its links, and the size of its thread.
+type summaryCounts struct {
+	skipped map[musicextractors.ErrorKind]int
+	failed  []FailedTitle
+	// failures are the skipped links with the messages they were shared in.
+	failures []MessageFailure
+	// messages is the number of messages of the thread, zero if the summary didn't read the thread.
+	messages int
+}
+
+// newSummaryCounts returns the counts of a summary without failures.
+func newSummaryCounts() *summaryCounts {
+	return &summaryCounts{skipped: map[musicextractors.ErrorKind]int{}, failed: []FailedTitle{}}
 }
 
-func (s *messageProcessorDomain) createCSV(pmls []parsedMusicLink) (io.Reader, int, error) {
-	buff := bytes.NewBuffer(nil)
-	w := csv.NewWriter(buff)
-	w.Comma = ';'
+// summary creates the summary of the extracted links with comment as its comment, the format of opts must be set.
+//
+// The links go through the same stages for every kind of summary: the dedupe stage merges the same recording,
+// the stats are counted, and the encode stage writes the summary file or the inline messages.
+// msgs are only used by the transcript, which follows the thread instead of the order of the options.
+func (s *messageProcessorDomain) summary(
+	ctx context.Context,
+	msgs []slack.Message,
+	links *linkBuffer,
+	channelID, threadTS string,
+	opts SummaryOptions,
+	counts summaryCounts,
+	comment string,
+) (Summary, error) {
+	format := opts.Format
+
+	rows, err := s.dedupeRows(links, channelID, opts)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	t, err := tracks(links)
+	if err != nil {
+		return Summary{}, fmt.Errorf("collect tracks: %w", err)
+	}
 
-	err := w.Write([]string{"Title", "Spotify URL", "YouTube URL", "YouTube Music URL"})
+	// The stats count the rows in any order, so they read the unsorted ones
+	stats, err := summaryStats(links, rows, counts)
 	if err != nil {
-		return nil, 0, fmt.Errorf("appending csv line: %w", err)
+		return Summary{}, fmt.Errorf("collect stats: %w", err)
+	}
+
+	// An anonymous summary never looks up the names of the users who shared the tracks
+	userName := s.userNameFunc(ctx)
+	if opts.Anonymous {
+		userName = nil
 	}
 
-	for _, pml := range pmls {
-		var lErr error
+	comment += runtimeNote(stats.DurationSeconds) + skippedNote(counts.skipped)
+	if s.stats {
+		comment += stats.note(userName)
+	}
+
+	summary := Summary{
+		Format: format,
+		Upload: slack.UploadFileV2Parameters{
+			InitialComment:  comment,
+			Channel:         channelID,
+			ThreadTimestamp: threadTS,
+		},
+		Tracks:       t,
+		Skipped:      counts.skipped,
+		FailedTitles: counts.failed,
+		Failures:     counts.failures,
+		Stats:        stats,
+	}
+
+	file := SummaryFile{
+		Rows:      summaryRows(groupRows(s.sortRows(rows, opts.Order), opts.Group)),
+		ChannelID: channelID,
+		ThreadTS:  threadTS,
+		UserName:  userName,
+		Anonymous: opts.Anonymous,
+	}
 
-		switch pml.Type {
-		case musicextractors.SpotifyProvider:
-			lErr = w.Write([]string{pml.Title, pml.URL, "", ""})
-		case musicextractors.YouTubeProvider:
-			lErr = w.Write([]string{pml.Title, "", pml.URL, ""})
-		case musicextractors.YoutTubeMusicProvider:
-			lErr = w.Write([]string{pml.Title, "", "", pml.URL})
+	if format == ExportFormatInline {
+		if summary.Messages, err = createInline(file); err != nil {
+			return Summary{}, fmt.Errorf("create %s: %w", format, err)
 		}
 
-		if lErr != nil {
-			return nil, 0, fmt.Errorf("appending csv line: %w", err)
+		return summary, nil
+	}
+
+	if s.skippedReport {
+		file.Skipped = counts.failed
+	}
+
+	fileName, err := s.templates.fileName(templateData(channelID, threadTS, stats.Links))
+	if err != nil {
+		return Summary{}, err
+	}
+
+	f, size, fileName, err := s.encodeFile(msgs, links, file, opts, fileName)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	summary.Upload.Reader, summary.Upload.FileSize = f, size
+	summary.Upload.Filename, summary.Upload.Title = fileName, fileName
+
+	return summary, nil
+}
+
+// dedupeRows is the dedupe stage of a summary, it merges the links of the same recording into one row
+// with the strategy of the options or the channel.
+//
+// Every shared link stays in the summary tracks, only the rows are merged.
+func (s *messageProcessorDomain) dedupeRows(links *linkBuffer, channelID string, opts SummaryOptions) (rowsFunc, error) {
+	dedupe, err := s.dedupeFor(channelID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeDuplicates(links, strategyKeys(dedupe, s.dedupeAcrossProviders)), nil
+}
+
+// encodeFile is the encode stage of a summary, it writes file in the format of the options and compresses it
+// when it's large, fileName is the name of the file without its extension.
+//
+// Returns the file, its size and its name with the extension, or an error if any.
+func (s *messageProcessorDomain) encodeFile(
+	msgs []slack.Message,
+	links *linkBuffer,
+	file SummaryFile,
+	opts SummaryOptions,
+	fileName string,
+) (io.Reader, int, string, error) {
+	format := opts.Format
+
+	encoder, encoded := s.encoders[format]
+	encoder = withGroupColumns(encoder, opts.Group)
+
+	if e, ok := encoder.(csvEncoder); ok {
+		e.options = e.options.with(opts.CSV)
+		encoder = e
+	}
+
+	// The file is written one row at a time, a huge one goes to disk and is uploaded from there
+	spool := newFileSpool(s.fileSpillBytes)
+
+	var err error
+
+	switch {
+	case format == ExportFormatTranscript:
+		err = writeTranscript(spool, msgs, links, file.ChannelID, file.ThreadTS, file.Anonymous)
+	case encoded:
+		err = encoder.Encode(spool, file)
+	default:
+		err = ErrUnsupportedFormat
+	}
+
+	if err != nil {
+		_ = spool.discard()
+
+		return nil, 0, "", fmt.Errorf("create %s: %w", format, err)
+	}
+
+	f, size, err := spool.reader()
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("create %s: %w", format, err)
+	}
+
+	f, size, fileName, err = s.compression.compress(f, size, fileName+"."+format.extension(), s.fileSpillBytes)
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("compress %s: %w", format, err)
+	}
+
+	return f, size, fileName, nil
+}
+
+// RetryTitles looks up the failed titles of a previous summary again and creates an updated summary of the thread
+// from them and the tracks the previous summary resolved, in the order of their messages.
+//
+// The resolved tracks are exported as they are, only with the metadata the Track keeps.
+// The summary is in the configured format, or CSV if that's the transcript, which needs the whole thread.
+//
+// Returns the summary, with the titles that failed again in FailedTitles, or an error if any.
+func (s *messageProcessorDomain) RetryTitles(
+	ctx context.Context,
+	channelID, threadTS string,
+	resolved []Track,
+	failed []FailedTitle,
+) (Summary, error) {
+	results := make([]extractResult, 0, len(resolved)+len(failed))
+
+	for _, t := range resolved {
+		results = append(results, extractResult{link: t.link()})
+	}
+
+	for _, f := range failed {
+		link, err := s.resolve(ctx, f.URL, f.Provider)
+		link.MessageTS, link.UserID = f.MessageTS, f.UserID
+		results = append(results, extractResult{link: link, err: err})
+	}
+
+	// Slack timestamps have a fixed width, so they order like the messages
+	slices.SortStableFunc(results, func(a, b extractResult) int { return strings.Compare(a.link.MessageTS, b.link.MessageTS) })
+
+	links := newLinkBuffer(s.spillThreshold)
+	defer func() { _ = links.close() }()
+
+	counts := newSummaryCounts()
+
+	for _, r := range results {
+		if err := counts.collect(links, r); err != nil {
+			return Summary{}, fmt.Errorf("collect links: %w", err)
 		}
 	}
 
-	w.Flush()
+	format := s.format
+	if format == ExportFormatTranscript {
+		format = ExportFormatCSV
+	}
+
+	return s.summary(ctx, nil, links, channelID, threadTS, SummaryOptions{Format: format}, *counts,
+		fmt.Sprintf("Resolved %d of %d failed titles in this thread", len(failed)-len(counts.failed), len(failed)))
+}
+
+// SummarizeThreadSince creates an updated summary of a thread summarized before, only resolving the messages posted since.
+// previous and failed are the resolved tracks and the failed titles of the earlier summary, msgs are the new messages.
+//
+// The previous tracks are exported as they are, only with the metadata the Track keeps, the failed titles stay failed
+// until RetryTitles looks them up again. The summary is in the format of the options, empty means the configured one,
+// or CSV if that's the transcript, which needs the whole thread. The provider filter of the options only applies
+// to the new messages.
+//
+// Returns the summary, with the previous and the new tracks and failures, or an error if any.
+func (s *messageProcessorDomain) SummarizeThreadSince(
+	ctx context.Context,
+	msgs []slack.Message,
+	channelID, threadTS string,
+	previous []Track,
+	failed []FailedTitle,
+	opts SummaryOptions,
+) (Summary, error) {
+	if opts.Format == "" {
+		opts.Format = s.format
+	}
+
+	if opts.Format == ExportFormatTranscript {
+		opts.Format = ExportFormatCSV
+	}
+
+	results := make([]extractResult, 0, len(previous)+len(failed))
+
+	for _, t := range previous {
+		results = append(results, extractResult{link: t.link()})
+	}
 
-	if err = w.Error(); err != nil {
-		return nil, 0, fmt.Errorf("flushing csv buffer: %w", err)
+	for _, f := range failed {
+		results = append(results, extractResult{
+			link: f.link(),
+			err:  &musicextractors.ExtractionError{Err: musicextractors.ErrRequestFailed, Provider: f.Provider, URL: f.URL, Kind: f.Kind},
+		})
 	}
 
-	return bytes.NewReader(buff.Bytes()), buff.Len(), nil
+	// Slack timestamps have a fixed width, so they order like the messages
+	slices.SortStableFunc(results, func(a, b extractResult) int { return strings.Compare(a.link.MessageTS, b.link.MessageTS) })
+
+	links := newLinkBuffer(s.spillThreshold)
+	defer func() { _ = links.close() }()
+
+	counts := newSummaryCounts()
+
+	for _, r := range results {
+		if err := counts.collect(links, r); err != nil {
+			return Summary{}, fmt.Errorf("collect links: %w", err)
+		}
+	}
+
+	known := links.len()
+
+	if err := s.extractInto(ctx, links, counts, msgs, s.extractScope(channelID, opts)); err != nil {
+		return Summary{}, fmt.Errorf("extract links: %w", err)
+	}
+
+	comment, err := s.templates.comment(templateData(channelID, threadTS, links.len()),
+		fmt.Sprintf("Found %d music URLs in this thread, %d of them in the %d messages since the last summary",
+			links.len(), links.len()-known, len(msgs)))
+	if err != nil {
+		return Summary{}, err
+	}
+
+	return s.summary(ctx, nil, links, channelID, threadTS, opts, *counts, comment)
+}
+
+// csvColumn is a fixed URL column of the CSV export.
+//
+// Columns shared by several providers, like the other video platforms and the reference sites, hold the link of the first provider that has one.
+type csvColumn struct {
+	header    string
+	providers []musicextractors.ExtractProvider
+}
+
+// csvColumns are the fixed URL columns of the CSV export in column order.
+var csvColumns = []csvColumn{
+	{"Spotify URL", []musicextractors.ExtractProvider{musicextractors.SpotifyProvider}},
+	{"YouTube URL", []musicextractors.ExtractProvider{musicextractors.YouTubeProvider}},
+	{"YouTube Music URL", []musicextractors.ExtractProvider{musicextractors.YoutTubeMusicProvider}},
+	{"Mixcloud URL", []musicextractors.ExtractProvider{musicextractors.MixcloudProvider}},
+	{"Audiomack URL", []musicextractors.ExtractProvider{musicextractors.AudiomackProvider}},
+	{"Amazon Music URL", []musicextractors.ExtractProvider{musicextractors.AmazonMusicProvider}},
+	{"Apple Music URL", []musicextractors.ExtractProvider{musicextractors.AppleMusicProvider}},
+	{"Shazam URL", []musicextractors.ExtractProvider{musicextractors.ShazamProvider}},
+	{"Other video URL", []musicextractors.ExtractProvider{musicextractors.VimeoProvider, musicextractors.DailymotionProvider}},
+	{"Reference URL", []musicextractors.ExtractProvider{musicextractors.LastFMProvider, musicextractors.DiscogsProvider}},
 }
 
-// NewSlackMessageProcessor creates a new processor with the given url and title extractors.
-func NewSlackMessageProcessor(
-	urlP map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc,
-	tp map[musicextractors.ExtractProvider]musicextractors.TitleExtractorFunc,
-) MessageProcessorDomain {
-	return &messageProcessorDomain{
-		processors:  urlP,
-		titleParser: tp,
+// columnLink returns the link of the first provider of a column that has one.
+func columnLink(links map[musicextractors.ExtractProvider]string, column []musicextractors.ExtractProvider) string {
+	for _, p := range column {
+		if link := links[p]; link != "" {
+			return link
+		}
 	}
+
+	return ""
+}
+
+// formatDuration formats a track duration as m:ss, or h:mm:ss for an hour or longer,
+// unknown durations are empty.
+func formatDuration(seconds int) string {
+	if seconds <= 0 {
+		return ""
+	}
+
+	h, m, sec := seconds/3600, seconds/60%60, seconds%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, sec)
+	}
+
+	return fmt.Sprintf("%d:%02d", m, sec)
+}
+
+// NewSlackMessageProcessor creates a new processor from the given config.
+func NewSlackMessageProcessor(cfg ProcessorConfig) MessageProcessorDomain {
+	var observer PoolObserver = noopPoolObserver{}
+	if cfg.PoolObserver != nil {
+		observer = cfg.PoolObserver
+	}
+
+	var extractions ExtractionObserver = noopExtractionObserver{}
+	if cfg.ExtractionObserver != nil {
+		extractions = cfg.ExtractionObserver
+	}
+
+	dedupe := cfg.Dedupe
+	if dedupe == nil {
+		dedupe = dedupeStrategies[DedupeISRC]
+	}
+
+	s := &messageProcessorDomain{
+		processors:            cfg.URLExtractors,
+		priority:              providerOrder(cfg.Priority, cfg.URLExtractors),
+		metadataParser:        cfg.MetadataExtractors,
+		channelDisabled:       cfg.ChannelDisabled,
+		crossLinker:           cfg.CrossLinker,
+		enricher:              cfg.Enricher,
+		shortURLs:             cfg.ShortURLResolver,
+		playlists:             cfg.PlaylistExpander,
+		dedupe:                dedupe,
+		channelDedupe:         cfg.ChannelDedupe,
+		dedupeAcrossProviders: cfg.DedupeAcrossProviders,
+		format:                cfg.Format,
+		csv:                   cfg.CSV,
+		stats:                 cfg.Stats,
+		skippedReport:         cfg.SkippedReport,
+		artwork:               cfg.Artwork,
+		timesShared:           cfg.TimesShared,
+		genre:                 cfg.Genre,
+		templates:             cfg.Templates,
+		compression:           cfg.Compression,
+		concurrency:           cfg.Concurrency,
+		spillThreshold:        cfg.SpillThreshold,
+		fileSpillBytes:        cfg.FileSpillBytes,
+		textSummarizer:        cfg.TextSummarizer,
+		observer:              observer,
+		extractions:           extractions,
+		users:                 cfg.UserResolver,
+	}
+
+	s.encoders = s.builtInEncoders()
+
+	return s
 }
@@ -0,0 +1,1098 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func failingTitle(err error) musicextractors.MetadataExtractorFunc {
+	return func(context.Context, string) (musicextractors.TrackMetadata, error) {
+		return musicextractors.TrackMetadata{}, err
+	}
+}
+
+func staticTitle(title string) musicextractors.MetadataExtractorFunc {
+	return func(context.Context, string) (musicextractors.TrackMetadata, error) {
+		return musicextractors.TrackMetadata{Title: title}, nil
+	}
+}
+
+func newTestProcessor(channelDisabled map[string][]musicextractors.ExtractProvider) MessageProcessorDomain {
+	return NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+			musicextractors.YouTubeProvider: musicextractors.YouTubeURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: staticTitle("Spotify Song"),
+			musicextractors.YouTubeProvider: staticTitle("YouTube Song"),
+		},
+		ChannelDisabled: channelDisabled,
+		Format:          ExportFormatCSV,
+		Compression:     Compression{Kind: CompressionNone},
+	})
+}
+
+func TestMessageProcessor_SummarizeThread_ChannelDisabledProviders(t *testing.T) {
+	t.Parallel()
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT?si=share-token"}},
+		{Msg: slack.Msg{Text: "https://www.youtube.com/watch?v=dQw4w9WgXcQ"}},
+		{Msg: slack.Msg{Text: "no links here"}},
+	}
+
+	smp := newTestProcessor(map[string][]musicextractors.ExtractProvider{
+		"C-VINYL": {musicextractors.YouTubeProvider},
+	})
+
+	tests := []struct {
+		name      string
+		channelID string
+		want      string
+	}{
+		{
+			name:      "channel without overrides",
+			channelID: "C-ANY",
+			want: "Title;Duration;Shared by;Shared at;Spotify URL;YouTube URL;YouTube Music URL;Mixcloud URL;Audiomack URL;Amazon Music URL;Apple Music URL;Shazam URL;Other video URL;Reference URL\n" +
+				"Spotify Song;;;;https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT;;;;;;;;;\n" +
+				"YouTube Song;;;;;https://www.youtube.com/watch?v=dQw4w9WgXcQ;;;;;;;;\n",
+		},
+		{
+			name:      "channel ignoring youtube",
+			channelID: "C-VINYL",
+			want: "Title;Duration;Shared by;Shared at;Spotify URL;YouTube URL;YouTube Music URL;Mixcloud URL;Audiomack URL;Amazon Music URL;Apple Music URL;Shazam URL;Other video URL;Reference URL\n" +
+				"Spotify Song;;;;https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT;;;;;;;;;\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			summary, err := smp.SummarizeThread(t.Context(), msgs, tt.channelID, "1700000000.000100")
+			require.NoError(t, err)
+
+			got, err := io.ReadAll(summary.Upload.Reader)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+			assert.Equal(t, tt.channelID+"-1700000000.000100.csv", summary.Upload.Filename)
+		})
+	}
+}
+
+type staticCrossLinker map[musicextractors.ExtractProvider]string
+
+func (s staticCrossLinker) CrossLink(context.Context, string) (map[musicextractors.ExtractProvider]string, error) {
+	return s, nil
+}
+
+func TestMessageProcessor_SummarizeThread_CrossLinksFillEveryColumn(t *testing.T) {
+	t.Parallel()
+
+	smp := NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: staticTitle("Spotify Song"),
+		},
+		CrossLinker: staticCrossLinker{
+			musicextractors.SpotifyProvider:       "https://open.spotify.com/track/from-odesli",
+			musicextractors.YouTubeProvider:       "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+			musicextractors.YoutTubeMusicProvider: "https://music.youtube.com/watch?v=dQw4w9WgXcQ",
+		},
+		Format:      ExportFormatCSV,
+		Compression: Compression{Kind: CompressionNone},
+	})
+
+	summary, err := smp.SummarizeThread(
+		t.Context(),
+		[]slack.Message{{Msg: slack.Msg{Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}}},
+		"C123",
+		"1700000000.000100",
+	)
+	require.NoError(t, err)
+
+	got, err := io.ReadAll(summary.Upload.Reader)
+	require.NoError(t, err)
+	assert.Equal(t, "Title;Duration;Shared by;Shared at;Spotify URL;YouTube URL;YouTube Music URL;Mixcloud URL;Audiomack URL;Amazon Music URL;Apple Music URL;Shazam URL;Other video URL;Reference URL\n"+
+		"Spotify Song;;;;https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT;"+
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ;https://music.youtube.com/watch?v=dQw4w9WgXcQ;;;;;;;\n", string(got))
+}
+
+func TestMessageProcessor_SummarizeThread_SlackFormattedLinks(t *testing.T) {
+	t.Parallel()
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{Text: "<https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT?si=abc|Never Gonna Give You Up>"}},
+		{Msg: slack.Msg{Text: "<@U123> <https://www.youtube.com/watch?v=dQw4w9WgXcQ&amp;t=10>"}},
+	}
+
+	summary, err := newTestProcessor(nil).SummarizeThread(t.Context(), msgs, "C-ANY", "1700000000.000100")
+	require.NoError(t, err)
+
+	got, err := io.ReadAll(summary.Upload.Reader)
+	require.NoError(t, err)
+	assert.Equal(t, "Title;Duration;Shared by;Shared at;Spotify URL;YouTube URL;YouTube Music URL;Mixcloud URL;Audiomack URL;Amazon Music URL;Apple Music URL;Shazam URL;Other video URL;Reference URL\n"+
+		"Spotify Song;;;;https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT;;;;;;;;;\n"+
+		"YouTube Song;;;;;https://www.youtube.com/watch?v=dQw4w9WgXcQ;;;;;;;;\n", string(got))
+}
+
+func TestMessageProcessor_SummarizeThread_OtherVideoColumn(t *testing.T) {
+	t.Parallel()
+
+	smp := NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.VimeoProvider:       musicextractors.VimeoURLExtractor,
+			musicextractors.DailymotionProvider: musicextractors.DailymotionURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.VimeoProvider:       staticTitle("Vimeo Video"),
+			musicextractors.DailymotionProvider: staticTitle("Dailymotion Video"),
+		},
+		Format:      ExportFormatCSV,
+		Compression: Compression{Kind: CompressionNone},
+	})
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{Text: "https://vimeo.com/76979871"}},
+		{Msg: slack.Msg{Text: "https://www.dailymotion.com/video/x7tgad0"}},
+	}
+
+	summary, err := smp.SummarizeThread(t.Context(), msgs, "C-ANY", "1700000000.000100")
+	require.NoError(t, err)
+
+	got, err := io.ReadAll(summary.Upload.Reader)
+	require.NoError(t, err)
+	assert.Equal(t, "Title;Duration;Shared by;Shared at;Spotify URL;YouTube URL;YouTube Music URL;Mixcloud URL;Audiomack URL;Amazon Music URL;Apple Music URL;Shazam URL;Other video URL;Reference URL\n"+
+		"Vimeo Video;;;;;;;;;;;;https://vimeo.com/76979871;\n"+
+		"Dailymotion Video;;;;;;;;;;;;https://www.dailymotion.com/video/x7tgad0;\n", string(got))
+}
+
+func TestMessageProcessor_SummarizeThreadAs_OverridesFormat(t *testing.T) {
+	t.Parallel()
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{Text: "https://www.youtube.com/watch?v=dQw4w9WgXcQ"}},
+	}
+
+	summary, err := newTestProcessor(nil).SummarizeThreadAs(t.Context(), msgs, "C-ANY", "1700000000.000100", SummaryOptions{Format: ExportFormatJSON})
+	require.NoError(t, err)
+	assert.Equal(t, "C-ANY-1700000000.000100.json", summary.Upload.Filename)
+
+	_, err = newTestProcessor(nil).SummarizeThreadAs(t.Context(), msgs, "C-ANY", "1700000000.000100", SummaryOptions{Format: "xml"})
+	require.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestMessageProcessor_SummarizeThread_ConcurrentExtractionKeepsOrder(t *testing.T) {
+	t.Parallel()
+
+	var inFlight, maxInFlight atomic.Int32
+
+	slowTitle := func(_ context.Context, url string) (musicextractors.TrackMetadata, error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+
+		return musicextractors.TrackMetadata{Title: url[len(url)-2:]}, nil
+	}
+
+	smp := NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.YouTubeProvider: musicextractors.YouTubeURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.YouTubeProvider: slowTitle,
+		},
+		Format:      ExportFormatCSV,
+		Compression: Compression{Kind: CompressionNone},
+		Concurrency: 3,
+	})
+
+	msgs := make([]slack.Message, 0, 12)
+	want := "Title;Duration;Shared by;Shared at;Spotify URL;YouTube URL;YouTube Music URL;Mixcloud URL;Audiomack URL;Amazon Music URL;Apple Music URL;Shazam URL;Other video URL;Reference URL\n"
+
+	for i := range 12 {
+		url := fmt.Sprintf("https://www.youtube.com/watch?v=video%02d", i)
+		msgs = append(msgs, slack.Message{Msg: slack.Msg{Text: url}})
+		want += fmt.Sprintf("%02d;;;;;%s;;;;;;;;\n", i, url)
+	}
+
+	summary, err := smp.SummarizeThread(t.Context(), msgs, "C-ANY", "1700000000.000100")
+	require.NoError(t, err)
+
+	got, err := io.ReadAll(summary.Upload.Reader)
+	require.NoError(t, err)
+	assert.Equal(t, want, string(got))
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(3))
+	assert.Greater(t, maxInFlight.Load(), int32(1))
+}
+
+func TestMessageProcessor_SummarizeThread_OpenCircuitKeepsURL(t *testing.T) {
+	t.Parallel()
+
+	smp := NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: func(context.Context, string) (musicextractors.TrackMetadata, error) {
+				return musicextractors.TrackMetadata{}, musicextractors.ErrCircuitOpen
+			},
+		},
+		Format:      ExportFormatCSV,
+		Compression: Compression{Kind: CompressionNone},
+	})
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+	}
+
+	summary, err := smp.SummarizeThread(t.Context(), msgs, "C-ANY", "1700000000.000100")
+	require.NoError(t, err)
+
+	got, err := io.ReadAll(summary.Upload.Reader)
+	require.NoError(t, err)
+	assert.Equal(t, "Title;Duration;Shared by;Shared at;Spotify URL;YouTube URL;YouTube Music URL;Mixcloud URL;Audiomack URL;Amazon Music URL;Apple Music URL;Shazam URL;Other video URL;Reference URL\n"+
+		";;;;https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT;;;;;;;;;\n", string(got))
+}
+
+func TestMessageProcessor_SummarizeThread_MergesSameISRC(t *testing.T) {
+	t.Parallel()
+
+	withISRC := func(title, isrc string) musicextractors.MetadataExtractorFunc {
+		return func(context.Context, string) (musicextractors.TrackMetadata, error) {
+			return musicextractors.TrackMetadata{Title: title, ISRC: isrc}, nil
+		}
+	}
+
+	smp := NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+			musicextractors.YouTubeProvider: musicextractors.YouTubeURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: withISRC("Rick Astley - Never Gonna Give You Up", "GBARL9300135"),
+			musicextractors.YouTubeProvider: withISRC("Never Gonna Give You Up (Official Video)", "GBARL9300135"),
+		},
+		Format:      ExportFormatCSV,
+		Compression: Compression{Kind: CompressionNone},
+	})
+
+	summary, err := smp.SummarizeThread(
+		t.Context(),
+		[]slack.Message{
+			{Msg: slack.Msg{Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+			{Msg: slack.Msg{Text: "https://www.youtube.com/watch?v=dQw4w9WgXcQ"}},
+		},
+		"C123",
+		"1700000000.000100",
+	)
+	require.NoError(t, err)
+
+	got, err := io.ReadAll(summary.Upload.Reader)
+	require.NoError(t, err)
+	assert.Equal(t, "Title;Duration;Shared by;Shared at;Spotify URL;YouTube URL;YouTube Music URL;Mixcloud URL;Audiomack URL;Amazon Music URL;Apple Music URL;Shazam URL;Other video URL;Reference URL\n"+
+		"Rick Astley - Never Gonna Give You Up;;;;https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT;"+
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ;;;;;;;;\n", string(got))
+
+	// Both shares are still reported as tracks
+	assert.Len(t, summary.Tracks, 2)
+}
+
+func TestMessageProcessor_ContainsMusicURL(t *testing.T) {
+	t.Parallel()
+
+	smp := newTestProcessor(map[string][]musicextractors.ExtractProvider{
+		"C-VINYL": {musicextractors.YouTubeProvider},
+	})
+
+	tests := []struct {
+		name      string
+		channelID string
+		text      string
+		want      bool
+	}{
+		{name: "spotify link", channelID: "C-ANY", text: "check <https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT|this>", want: true},
+		{name: "youtube link", channelID: "C-ANY", text: "https://www.youtube.com/watch?v=dQw4w9WgXcQ", want: true},
+		{name: "disabled provider", channelID: "C-VINYL", text: "https://www.youtube.com/watch?v=dQw4w9WgXcQ"},
+		{name: "no link", channelID: "C-ANY", text: "great pick!"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, smp.ContainsMusicURL(t.Context(), tt.channelID, tt.text))
+		})
+	}
+}
+
+func TestMessageProcessor_SummarizeThread_CustomProviderColumn(t *testing.T) {
+	t.Parallel()
+
+	jellyfin, err := musicextractors.NewRegexProvider("jellyfin", `https://media\.internal/items/\w+`, musicextractors.TitleStrategyNone)
+	require.NoError(t, err)
+
+	smp := NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+			jellyfin.Name:                   jellyfin.URLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: staticTitle("Spotify Song"),
+			jellyfin.Name:                   jellyfin.MetadataExtractor,
+		},
+		Format:      ExportFormatCSV,
+		Compression: Compression{Kind: CompressionNone},
+	})
+
+	summary, err := smp.SummarizeThread(
+		t.Context(),
+		[]slack.Message{{Msg: slack.Msg{Text: "from our server https://media.internal/items/abc123"}}},
+		"C123",
+		"1700000000.000100",
+	)
+	require.NoError(t, err)
+
+	got, err := io.ReadAll(summary.Upload.Reader)
+	require.NoError(t, err)
+	assert.Equal(t, "Title;Duration;Shared by;Shared at;Spotify URL;YouTube URL;YouTube Music URL;Mixcloud URL;Audiomack URL;Amazon Music URL;Apple Music URL;Shazam URL;Other video URL;Reference URL;jellyfin URL\n"+
+		"https://media.internal/items/abc123;;;;;;;;;;;;;;https://media.internal/items/abc123\n", string(got))
+}
+
+func TestMessageProcessor_SummarizeThread_SpillKeepsOutput(t *testing.T) {
+	t.Parallel()
+
+	msgs := make([]slack.Message, 0, 20)
+	for i := range 20 {
+		msgs = append(msgs, slack.Message{Msg: slack.Msg{
+			Text:      fmt.Sprintf("https://open.spotify.com/track/track%02d", i),
+			Timestamp: fmt.Sprintf("1700000000.%06d", i),
+		}})
+	}
+
+	summarize := func(spillThreshold int, format ExportFormat) (string, []Track) {
+		smp := NewSlackMessageProcessor(ProcessorConfig{
+			URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+				musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+			},
+			MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+				musicextractors.SpotifyProvider: staticTitle("Spotify Song"),
+			},
+			Format:         format,
+			Compression:    Compression{Kind: CompressionNone},
+			Concurrency:    4,
+			SpillThreshold: spillThreshold,
+		})
+
+		summary, err := smp.SummarizeThread(t.Context(), msgs, "C123", "1700000000.000100")
+		require.NoError(t, err)
+
+		got, err := io.ReadAll(summary.Upload.Reader)
+		require.NoError(t, err)
+
+		return string(got), summary.Tracks
+	}
+
+	wantCSV, wantTracks := summarize(0, ExportFormatCSV)
+	gotCSV, gotTracks := summarize(3, ExportFormatCSV)
+
+	assert.Equal(t, wantCSV, gotCSV)
+	assert.Equal(t, wantTracks, gotTracks)
+	assert.Len(t, gotTracks, 20)
+
+	gotJSON, _ := summarize(3, ExportFormatJSON)
+	assert.Contains(t, gotJSON, "https://open.spotify.com/track/track19")
+}
+
+func TestMessageProcessor_SummarizeThread_ReportsSkippedLinks(t *testing.T) {
+	t.Parallel()
+
+	smp := NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider:   musicextractors.SpotifyURLExtractor,
+			musicextractors.YouTubeProvider:   musicextractors.YouTubeURLExtractor,
+			musicextractors.AudiomackProvider: musicextractors.AudiomackURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider:   staticTitle("Spotify Song"),
+			musicextractors.YouTubeProvider:   failingTitle(musicextractors.ErrRateLimited),
+			musicextractors.AudiomackProvider: failingTitle(musicextractors.ErrNoTitleFound),
+		},
+		Format:      ExportFormatCSV,
+		Compression: Compression{Kind: CompressionNone},
+	})
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+		{Msg: slack.Msg{Text: "https://open.spotify.com/track/abc and https://open.spotify.com/track/def"}},
+		{Msg: slack.Msg{Text: "https://www.youtube.com/watch?v=dQw4w9WgXcQ"}},
+		{Msg: slack.Msg{Text: "https://www.youtube.com/watch?v=9bZkp7q19f0"}},
+		{Msg: slack.Msg{Text: "https://audiomack.com/burna-boy/song/last-last"}},
+		{Msg: slack.Msg{Text: "no links here"}},
+	}
+
+	summary, err := smp.SummarizeThread(t.Context(), msgs, "C123", "1700000000.000100")
+	require.NoError(t, err)
+
+	assert.Equal(t, map[musicextractors.ErrorKind]int{
+		musicextractors.ErrorKindMultipleURLs:  1,
+		musicextractors.ErrorKindRateLimited:   2,
+		musicextractors.ErrorKindTitleNotFound: 1,
+	}, summary.Skipped)
+	assert.Equal(t,
+		"Found 1 music URLs in this thread, skipped 4 "+
+			"(1 several links in one message, 2 rate limited by the provider, 1 title not found)",
+		summary.Upload.InitialComment,
+	)
+}
+
+func TestMessageProcessor_SummarizeThread_ReportsMessageFailures(t *testing.T) {
+	t.Parallel()
+
+	smp := NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+			musicextractors.YouTubeProvider: musicextractors.YouTubeURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: staticTitle("Spotify Song"),
+			musicextractors.YouTubeProvider: failingTitle(musicextractors.ErrRateLimited),
+		},
+		Format:      ExportFormatCSV,
+		Compression: Compression{Kind: CompressionNone},
+	})
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{Timestamp: "1700000001.000100", User: "U1", Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+		{Msg: slack.Msg{Timestamp: "1700000002.000100", User: "U2", Text: "https://www.youtube.com/watch?v=dQw4w9WgXcQ"}},
+		{Msg: slack.Msg{Timestamp: "1700000003.000100", User: "U3", Text: "no links here"}},
+	}
+
+	summary, err := smp.SummarizeThread(t.Context(), msgs, "C123", "1700000000.000100")
+	require.NoError(t, err)
+
+	require.Len(t, summary.Failures, 1)
+
+	failure := summary.Failures[0]
+	assert.Equal(t, "1700000002.000100", failure.MessageTS)
+	assert.Equal(t, "U2", failure.UserID)
+	assert.Equal(t, "https://www.youtube.com/watch?v=dQw4w9WgXcQ", failure.URL)
+	assert.Equal(t, musicextractors.YouTubeProvider, failure.Provider)
+	assert.Equal(t, musicextractors.ErrorKindRateLimited, failure.Kind)
+	require.ErrorIs(t, failure.Err, musicextractors.ErrRateLimited)
+	assert.Len(t, summary.Tracks, 1)
+}
+
+func TestMessageProcessor_SummarizeThreadAs_ReportsProgress(t *testing.T) {
+	t.Parallel()
+
+	smp := NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: staticTitle("Spotify Song"),
+		},
+		Format:      ExportFormatCSV,
+		Compression: Compression{Kind: CompressionNone},
+		Concurrency: 2,
+	})
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{Timestamp: "1700000001.000100", Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+		{Msg: slack.Msg{Timestamp: "1700000002.000100", Text: "no links here"}},
+		{Msg: slack.Msg{Timestamp: "1700000003.000100"}},
+		{Msg: slack.Msg{Timestamp: "1700000004.000100", Text: "https://open.spotify.com/track/0VjIjW4GlUZAMYd2vXMi3b"}},
+	}
+
+	var (
+		mu    sync.Mutex
+		dones []int
+	)
+
+	_, err := smp.SummarizeThreadAs(t.Context(), msgs, "C123", "1700000000.000100", SummaryOptions{
+		Progress: func(done, total int) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			assert.Equal(t, len(msgs), total)
+
+			dones = append(dones, done)
+		},
+	})
+	require.NoError(t, err)
+
+	slices.Sort(dones)
+	// The empty message is skipped without a worker, the other three are reported one by one
+	assert.Equal(t, []int{1, 2, 3, 4}, dones)
+}
+
+func TestMessageProcessor_SummarizeChannel(t *testing.T) {
+	t.Parallel()
+
+	smp := NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: staticTitle("Spotify Song"),
+		},
+		Format:      ExportFormatCSV,
+		Compression: Compression{Kind: CompressionNone},
+	})
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{Timestamp: "1700000001.000100", Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+		{Msg: slack.Msg{Timestamp: "1700000002.000100", Text: "good morning"}},
+	}
+
+	summary, err := smp.SummarizeChannel(t.Context(), msgs, "C123", SummaryOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Found 1 music URLs in the last 2 messages of this channel", summary.Upload.InitialComment)
+	assert.Equal(t, "C123.csv", summary.Upload.Filename)
+	assert.Empty(t, summary.Upload.ThreadTimestamp)
+	assert.Len(t, summary.Tracks, 1)
+}
+
+func TestMessageProcessor_RetryTitles_ResolvesOnlyFailedTitles(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	smp := NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+			musicextractors.YouTubeProvider: musicextractors.YouTubeURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: staticTitle("Spotify Song"),
+			// The first lookup fails, like a provider that was rate limited during the summary
+			musicextractors.YouTubeProvider: func(context.Context, string) (musicextractors.TrackMetadata, error) {
+				if calls.Add(1) == 1 {
+					return musicextractors.TrackMetadata{}, musicextractors.ErrRateLimited
+				}
+
+				return musicextractors.TrackMetadata{Title: "YouTube Song"}, nil
+			},
+		},
+		Format:      ExportFormatCSV,
+		Compression: Compression{Kind: CompressionNone},
+	})
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{Timestamp: "1700000000.000200", Text: "https://www.youtube.com/watch?v=dQw4w9WgXcQ"}},
+		{Msg: slack.Msg{Timestamp: "1700000000.000300", Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+	}
+
+	summary, err := smp.SummarizeThread(t.Context(), msgs, "C123", "1700000000.000100")
+	require.NoError(t, err)
+	require.Len(t, summary.FailedTitles, 1)
+	assert.Equal(t, "https://www.youtube.com/watch?v=dQw4w9WgXcQ", summary.FailedTitles[0].URL)
+	assert.Equal(t, musicextractors.ErrorKindRateLimited, summary.FailedTitles[0].Kind)
+
+	retried, err := smp.RetryTitles(t.Context(), "C123", "1700000000.000100", summary.Tracks, summary.FailedTitles)
+	require.NoError(t, err)
+	assert.Empty(t, retried.FailedTitles)
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Equal(t, "Resolved 1 of 1 failed titles in this thread", retried.Upload.InitialComment)
+
+	require.Len(t, retried.Tracks, 2)
+	assert.Equal(t, "YouTube Song", retried.Tracks[0].Title)
+	assert.Equal(t, "Spotify Song", retried.Tracks[1].Title)
+}
+
+func TestMessageProcessor_SummarizeThreadSince_ResolvesOnlyNewMessages(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	smp := NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider:   musicextractors.SpotifyURLExtractor,
+			musicextractors.AudiomackProvider: musicextractors.AudiomackURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: func(context.Context, string) (musicextractors.TrackMetadata, error) {
+				calls.Add(1)
+
+				return musicextractors.TrackMetadata{Title: "New Song"}, nil
+			},
+			musicextractors.AudiomackProvider: failingTitle(musicextractors.ErrNoTitleFound),
+		},
+		Format:      ExportFormatTranscript,
+		Compression: Compression{Kind: CompressionNone},
+	})
+
+	previous := []Track{{Title: "Old Song", URL: "https://open.spotify.com/track/old", Provider: musicextractors.SpotifyProvider, MessageTS: "1700000000.000200"}}
+	failed := []FailedTitle{{
+		Track: Track{URL: "https://audiomack.com/burna-boy/song/last-last", Provider: musicextractors.AudiomackProvider, MessageTS: "1700000000.000300"},
+		Kind:  musicextractors.ErrorKindTitleNotFound,
+	}}
+	msgs := []slack.Message{
+		{Msg: slack.Msg{Timestamp: "1700000000.000400", Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+		{Msg: slack.Msg{Timestamp: "1700000000.000500", Text: "no links here"}},
+	}
+
+	summary, err := smp.SummarizeThreadSince(t.Context(), msgs, "C123", "1700000000.000100", previous, failed, SummaryOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// The transcript needs the whole thread, so the update falls back to CSV
+	assert.Equal(t, ExportFormatCSV, summary.Format)
+	assert.Equal(t, "Found 2 music URLs in this thread, 1 of them in the 2 messages since the last summary, skipped 1 (1 title not found)",
+		summary.Upload.InitialComment)
+
+	require.Len(t, summary.Tracks, 2)
+	assert.Equal(t, "Old Song", summary.Tracks[0].Title)
+	assert.Equal(t, "New Song", summary.Tracks[1].Title)
+
+	// The failed title stays in the failure report for retry-titles
+	require.Len(t, summary.FailedTitles, 1)
+	assert.Equal(t, failed[0].URL, summary.FailedTitles[0].URL)
+	assert.Equal(t, musicextractors.ErrorKindTitleNotFound, summary.FailedTitles[0].Kind)
+}
+
+// countingObserver sums every PoolObserver call.
+type countingObserver struct {
+	workers, queue, started, done, maxWorkers atomic.Int32
+}
+
+func (o *countingObserver) WorkersChanged(_ context.Context, delta int) {
+	if o.workers.Add(int32(delta)) > o.maxWorkers.Load() {
+		o.maxWorkers.Store(o.workers.Load())
+	}
+}
+
+func (o *countingObserver) QueueChanged(_ context.Context, delta int) { o.queue.Add(int32(delta)) }
+func (o *countingObserver) JobStarted(context.Context, time.Duration) { o.started.Add(1) }
+func (o *countingObserver) JobDone(context.Context)                   { o.done.Add(1) }
+
+func TestMessageProcessor_ExtractTracks(t *testing.T) {
+	t.Parallel()
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{Timestamp: "1", User: "U1", Text: "https://www.youtube.com/watch?v=dQw4w9WgXcQ"}},
+		{Msg: slack.Msg{Timestamp: "2", Text: "no links here"}},
+		{Msg: slack.Msg{Timestamp: "3", User: "U2", Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT?si=share-token"}},
+	}
+
+	got, err := newTestProcessor(nil).ExtractTracks(t.Context(), msgs, "C1")
+	require.NoError(t, err)
+
+	assert.Equal(t, []Track{
+		{
+			Title:     "YouTube Song",
+			URL:       "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+			Provider:  musicextractors.YouTubeProvider,
+			MessageTS: "1",
+			UserID:    "U1",
+		},
+		{
+			Title:     "Spotify Song",
+			URL:       "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT",
+			Provider:  musicextractors.SpotifyProvider,
+			MessageTS: "3",
+			UserID:    "U2",
+		},
+	}, got)
+	assert.Equal(t, got[1].URL, got[1].ProviderURL(musicextractors.SpotifyProvider))
+	assert.Empty(t, got[0].ProviderURL(musicextractors.SpotifyProvider))
+}
+
+func TestMessageProcessor_SummarizeThread_ObservesPool(t *testing.T) {
+	t.Parallel()
+
+	observer := &countingObserver{}
+	smp := NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: staticTitle("Spotify Song"),
+		},
+		Format:       ExportFormatCSV,
+		Compression:  Compression{Kind: CompressionNone},
+		Concurrency:  4,
+		PoolObserver: observer,
+	})
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+		{Msg: slack.Msg{Text: "no links here"}},
+		{Msg: slack.Msg{Text: ""}},
+	}
+
+	_, err := smp.SummarizeThread(t.Context(), msgs, "C123", "1700000000.000100")
+	require.NoError(t, err)
+
+	// Empty messages never reach the queue, the pool is sized to the queued messages
+	assert.Equal(t, int32(2), observer.started.Load())
+	assert.Equal(t, int32(2), observer.done.Load())
+	assert.Equal(t, int32(2), observer.maxWorkers.Load())
+	assert.Zero(t, observer.workers.Load())
+	assert.Zero(t, observer.queue.Load())
+}
+
+// stubResolver replaces short links with a fixed target.
+type stubResolver map[string]string
+
+func (r stubResolver) ResolveText(_ context.Context, text string) string {
+	for short, target := range r {
+		text = strings.ReplaceAll(text, short, target)
+	}
+
+	return text
+}
+
+func TestMessageProcessor_SummarizeThread_ResolvesShortURLs(t *testing.T) {
+	t.Parallel()
+
+	smp := NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: staticTitle("Spotify Song"),
+		},
+		ShortURLResolver: stubResolver{
+			"https://spotify.link/xyz": "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT?si=abc",
+		},
+		Format:      ExportFormatCSV,
+		Compression: Compression{Kind: CompressionNone},
+	})
+
+	summary, err := smp.SummarizeThread(
+		t.Context(),
+		[]slack.Message{{Msg: slack.Msg{Text: "from my phone <https://spotify.link/xyz>"}}},
+		"C123",
+		"1700000000.000100",
+	)
+	require.NoError(t, err)
+	require.Len(t, summary.Tracks, 1)
+	assert.Equal(t, "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT", summary.Tracks[0].URL)
+}
+
+func TestMessageProcessor_SummarizeThread_DurationColumn(t *testing.T) {
+	t.Parallel()
+
+	smp := NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: func(context.Context, string) (musicextractors.TrackMetadata, error) {
+				return musicextractors.TrackMetadata{Title: "Spotify Song", DurationSeconds: 213}, nil
+			},
+		},
+		Format:      ExportFormatCSV,
+		Compression: Compression{Kind: CompressionNone},
+	})
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+	}
+
+	summary, err := smp.SummarizeThread(t.Context(), msgs, "C-ANY", "1700000000.000100")
+	require.NoError(t, err)
+
+	got, err := io.ReadAll(summary.Upload.Reader)
+	require.NoError(t, err)
+	assert.Equal(t, "Title;Duration;Shared by;Shared at;Spotify URL;YouTube URL;YouTube Music URL;Mixcloud URL;Audiomack URL;Amazon Music URL;Apple Music URL;Shazam URL;Other video URL;Reference URL\n"+
+		"Spotify Song;3:33;;;https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT;;;;;;;;;\n", string(got))
+}
+
+// stubUsers resolves the names of the listed users, every other user fails.
+type stubUsers map[string]string
+
+type userResolverFunc func(ctx context.Context, userID string) (string, error)
+
+func (f userResolverFunc) UserName(ctx context.Context, userID string) (string, error) {
+	return f(ctx, userID)
+}
+
+func (u stubUsers) UserName(_ context.Context, userID string) (string, error) {
+	if name, ok := u[userID]; ok {
+		return name, nil
+	}
+
+	return "", assert.AnError
+}
+
+func TestMessageProcessor_SummarizeThread_PosterColumns(t *testing.T) {
+	t.Parallel()
+
+	smp := NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+			musicextractors.YouTubeProvider: musicextractors.YouTubeURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: staticTitle("Spotify Song"),
+			musicextractors.YouTubeProvider: staticTitle("YouTube Song"),
+		},
+		UserResolver: stubUsers{"U1": "ada"},
+		Format:       ExportFormatCSV,
+		Compression:  Compression{Kind: CompressionNone},
+	})
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{User: "U1", Timestamp: "1700000000.000200", Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+		{Msg: slack.Msg{User: "U2", Timestamp: "1700003600.000300", Text: "https://www.youtube.com/watch?v=dQw4w9WgXcQ"}},
+	}
+
+	summary, err := smp.SummarizeThread(t.Context(), msgs, "C123", "1700000000.000100")
+	require.NoError(t, err)
+
+	got, err := io.ReadAll(summary.Upload.Reader)
+	require.NoError(t, err)
+	// Users without a resolvable name are listed by their ID
+	assert.Equal(t, "Title;Duration;Shared by;Shared at;Spotify URL;YouTube URL;YouTube Music URL;Mixcloud URL;Audiomack URL;Amazon Music URL;Apple Music URL;Shazam URL;Other video URL;Reference URL\n"+
+		"Spotify Song;;ada;2023-11-14 22:13 UTC;https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT;;;;;;;;;\n"+
+		"YouTube Song;;U2;2023-11-14 23:13 UTC;;https://www.youtube.com/watch?v=dQw4w9WgXcQ;;;;;;;;\n", string(got))
+}
+
+func TestMessageProcessor_SummarizeThreadAs_PosterColumnsAnonymous(t *testing.T) {
+	t.Parallel()
+
+	var lookups atomic.Int32
+
+	smp := NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: staticTitle("Spotify Song"),
+		},
+		UserResolver: userResolverFunc(func(context.Context, string) (string, error) {
+			lookups.Add(1)
+
+			return "ada", nil
+		}),
+		Format:      ExportFormatCSV,
+		Compression: Compression{Kind: CompressionNone},
+	})
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{User: "U1", Timestamp: "1700000000.000200", Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+	}
+
+	summary, err := smp.SummarizeThreadAs(t.Context(), msgs, "C123", "1700000000.000100", SummaryOptions{Anonymous: true})
+	require.NoError(t, err)
+
+	got, err := io.ReadAll(summary.Upload.Reader)
+	require.NoError(t, err)
+	// The poster columns are left out and the users are never looked up in Slack
+	assert.Equal(t, "Title;Duration;Spotify URL;YouTube URL;YouTube Music URL;Mixcloud URL;Audiomack URL;Amazon Music URL;Apple Music URL;Shazam URL;Other video URL;Reference URL\n"+
+		"Spotify Song;;https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT;;;;;;;;;\n", string(got))
+	assert.Zero(t, lookups.Load())
+}
+
+func TestFormatDuration(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		want    string
+		seconds int
+	}{
+		{name: "unknown", seconds: 0, want: ""},
+		{name: "seconds", seconds: 7, want: "0:07"},
+		{name: "minutes", seconds: 213, want: "3:33"},
+		{name: "hours", seconds: 3725, want: "1:02:05"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, formatDuration(tt.seconds))
+		})
+	}
+}
+
+func TestMessageProcessor_SummarizeThread_ArtworkColumn(t *testing.T) {
+	t.Parallel()
+
+	smp := NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: func(context.Context, string) (musicextractors.TrackMetadata, error) {
+				return musicextractors.TrackMetadata{Title: "Spotify Song", ArtworkURL: "https://i.scdn.co/image/cover"}, nil
+			},
+		},
+		Format:      ExportFormatCSV,
+		Artwork:     true,
+		Compression: Compression{Kind: CompressionNone},
+	})
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+	}
+
+	summary, err := smp.SummarizeThread(t.Context(), msgs, "C-ANY", "1700000000.000100")
+	require.NoError(t, err)
+
+	got, err := io.ReadAll(summary.Upload.Reader)
+	require.NoError(t, err)
+	assert.Equal(t, "Title;Duration;Shared by;Shared at;Artwork URL;Spotify URL;YouTube URL;YouTube Music URL;Mixcloud URL;Audiomack URL;Amazon Music URL;Apple Music URL;Shazam URL;Other video URL;Reference URL\n"+
+		"Spotify Song;;;;https://i.scdn.co/image/cover;https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT;;;;;;;;;\n", string(got))
+
+	require.Len(t, summary.Tracks, 1)
+	assert.Equal(t, "https://i.scdn.co/image/cover", summary.Tracks[0].ArtworkURL)
+}
+
+func TestMessageProcessor_SummarizeThread_ConcurrentRequests(t *testing.T) {
+	t.Parallel()
+
+	smp := newTestProcessor(map[string][]musicextractors.ExtractProvider{"C2": {musicextractors.SpotifyProvider}})
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT", Timestamp: "1700000001.000100"}},
+		{Msg: slack.Msg{Text: "https://www.youtube.com/watch?v=dQw4w9WgXcQ", Timestamp: "1700000002.000100"}},
+	}
+
+	want := map[string]int{"C1": 2, "C2": 1}
+	errs := make(chan error, 20)
+
+	// The same processor serves every summary of the bot, run under -race to catch shared state
+	var wg sync.WaitGroup
+
+	for i := range 20 {
+		channelID := fmt.Sprintf("C%d", i%2+1)
+
+		wg.Go(func() {
+			summary, err := smp.SummarizeThreadAs(t.Context(), msgs, channelID, "1700000000.000100", SummaryOptions{Format: ExportFormats()[i%len(ExportFormats())]})
+			if err == nil && len(summary.Tracks) != want[channelID] {
+				err = fmt.Errorf("summary of %s has %d tracks, want %d", channelID, len(summary.Tracks), want[channelID])
+			}
+
+			errs <- err
+		})
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+}
+
+func TestMessageProcessor_DedupeRows(t *testing.T) {
+	t.Parallel()
+
+	smp, ok := newTestProcessor(nil).(*messageProcessorDomain)
+	require.True(t, ok)
+
+	links := newLinkBuffer(0)
+	t.Cleanup(func() { _ = links.close() })
+
+	for range 2 {
+		require.NoError(t, links.add(parsedMusicLink{URL: "https://youtu.be/dQw4w9WgXcQ", Type: musicextractors.YouTubeProvider}))
+	}
+
+	tests := []struct {
+		wantErr error
+		name    string
+		dedupe  DedupeStrategyName
+		want    []int
+	}{
+		{name: "merged", dedupe: DedupeURL, want: []int{2}},
+		{name: "kept apart", dedupe: DedupeNone, want: []int{1, 1}},
+		{name: "unknown strategy", dedupe: "fuzzy", wantErr: ErrUnsupportedDedupeStrategy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rows, err := smp.dedupeRows(links, "C1", SummaryOptions{Dedupe: tt.dedupe})
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+
+			var shared []int
+
+			require.NoError(t, rows(func(pml parsedMusicLink) error {
+				shared = append(shared, pml.TimesShared)
+
+				return nil
+			}))
+			assert.Equal(t, tt.want, shared)
+		})
+	}
+}
+
+func TestMessageProcessor_EncodeFile(t *testing.T) {
+	t.Parallel()
+
+	smp, ok := newTestProcessor(nil).(*messageProcessorDomain)
+	require.True(t, ok)
+
+	tests := []struct {
+		wantErr  error
+		name     string
+		format   ExportFormat
+		wantName string
+	}{
+		{name: "csv", format: ExportFormatCSV, wantName: "summary.csv"},
+		{name: "json", format: ExportFormatJSON, wantName: "summary.json"},
+		{name: "unsupported", format: "xml", wantErr: ErrUnsupportedFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			links := newLinkBuffer(0)
+			t.Cleanup(func() { _ = links.close() })
+
+			require.NoError(t, links.add(parsedMusicLink{Title: "YouTube Song", URL: "https://youtu.be/dQw4w9WgXcQ", Type: musicextractors.YouTubeProvider}))
+
+			file := SummaryFile{Rows: summaryRows(links.each), ChannelID: "C1", ThreadTS: "1700000000.000100"}
+
+			f, size, name, err := smp.encodeFile(nil, links, file, SummaryOptions{Format: tt.format}, "summary")
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantName, name)
+
+			got, err := io.ReadAll(f)
+			require.NoError(t, err)
+			assert.Len(t, got, size)
+			assert.Contains(t, string(got), "YouTube Song")
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// SortOrder is the order of the rows of a summary file.
+type SortOrder string
+
+const (
+	// SortShared keeps the rows in the order they were first shared in, the default.
+	SortShared SortOrder = "shared"
+	// SortTitle orders the rows by their title, ignoring case, the unresolved titles come last.
+	SortTitle SortOrder = "title"
+	// SortArtist orders the rows by their artist and then their title, the ones without an artist come last.
+	SortArtist SortOrder = "artist"
+	// SortProvider groups the rows by their provider in matching order.
+	SortProvider SortOrder = "provider"
+	// SortPopularity orders the rows by the number of times they were shared, the most shared first.
+	SortPopularity SortOrder = "popularity"
+)
+
+// SortOrders returns every supported sort order.
+func SortOrders() []SortOrder {
+	return []SortOrder{SortShared, SortTitle, SortArtist, SortProvider, SortPopularity}
+}
+
+// compareText orders a and b ignoring case, with the empty ones last.
+func compareText(a, b string) int {
+	switch {
+	case a == "" || b == "":
+		return cmp.Compare(b, a)
+	default:
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	}
+}
+
+// sortRows orders the merged rows by order, ties and SortShared keep the order they were first shared in.
+//
+// Every order but SortShared keeps the rows in memory, so a spilled summary is only streamed in the order it was shared in.
+func (s *messageProcessorDomain) sortRows(rows rowsFunc, order SortOrder) rowsFunc {
+	var compare func(a, b parsedMusicLink) int
+
+	switch order {
+	case SortTitle:
+		compare = func(a, b parsedMusicLink) int { return compareText(a.Title, b.Title) }
+	case SortArtist:
+		compare = func(a, b parsedMusicLink) int {
+			return cmp.Or(compareText(a.Metadata.Artist, b.Metadata.Artist), compareText(a.Title, b.Title))
+		}
+	case SortProvider:
+		compare = func(a, b parsedMusicLink) int {
+			return cmp.Compare(s.providerRank(a), s.providerRank(b))
+		}
+	case SortPopularity:
+		compare = func(a, b parsedMusicLink) int { return cmp.Compare(b.TimesShared, a.TimesShared) }
+	default:
+		return rows
+	}
+
+	return sortedRows(rows, compare)
+}
+
+// sortedRows returns the rows ordered by compare, keeping the order of the ties, the rows are kept in memory.
+func sortedRows(rows rowsFunc, compare func(a, b parsedMusicLink) int) rowsFunc {
+	return func(yield func(parsedMusicLink) error) error {
+		var sorted []parsedMusicLink
+
+		err := rows(func(pml parsedMusicLink) error {
+			sorted = append(sorted, pml)
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		slices.SortStableFunc(sorted, compare)
+
+		for _, pml := range sorted {
+			if err := yield(pml); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// providerRank returns the position of the provider of pml in matching order, the unlisted providers come last.
+func (s *messageProcessorDomain) providerRank(pml parsedMusicLink) int {
+	if i := slices.Index(s.priority, pml.Type); i >= 0 {
+		return i
+	}
+
+	return len(s.priority)
+}
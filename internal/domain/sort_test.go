@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageProcessor_SortRows(t *testing.T) {
+	t.Parallel()
+
+	links := []parsedMusicLink{
+		{Title: "halo", URL: "a", Type: musicextractors.YouTubeProvider, TimesShared: 1, Metadata: musicextractors.TrackMetadata{Artist: "Beyoncé"}},
+		{Title: "", URL: "b", Type: musicextractors.SpotifyProvider, TimesShared: 3},
+		{Title: "Angel", URL: "c", Type: musicextractors.MixcloudProvider, TimesShared: 3, Metadata: musicextractors.TrackMetadata{Artist: "Massive Attack"}},
+		{Title: "Crazy", URL: "d", Type: musicextractors.SpotifyProvider, TimesShared: 2, Metadata: musicextractors.TrackMetadata{Artist: "beyoncé"}},
+	}
+
+	rows := func(yield func(parsedMusicLink) error) error {
+		for _, pml := range links {
+			if err := yield(pml); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	s := &messageProcessorDomain{priority: []musicextractors.ExtractProvider{musicextractors.SpotifyProvider, musicextractors.YouTubeProvider}}
+
+	tests := []struct {
+		order SortOrder
+		want  []string
+	}{
+		{order: "", want: []string{"a", "b", "c", "d"}},
+		{order: SortShared, want: []string{"a", "b", "c", "d"}},
+		{order: SortTitle, want: []string{"c", "d", "a", "b"}},
+		{order: SortArtist, want: []string{"d", "a", "c", "b"}},
+		{order: SortProvider, want: []string{"b", "d", "a", "c"}},
+		{order: SortPopularity, want: []string{"b", "c", "d", "a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.order), func(t *testing.T) {
+			t.Parallel()
+
+			var got []string
+
+			require.NoError(t, s.sortRows(rows, tt.order)(func(pml parsedMusicLink) error {
+				got = append(got, pml.URL)
+
+				return nil
+			}))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
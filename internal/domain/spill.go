@@ -0,0 +1,285 @@
+package domain
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// linkBuffer collects the parsed links of a summary in message order, keeping at most limit of them in memory
+// and spilling the rest to a temporary file, so extreme runs stay within the memory limit of the container.
+//
+// A non-positive limit keeps every link in memory.
+type linkBuffer struct {
+	file  *os.File
+	w     *bufio.Writer
+	mem   []parsedMusicLink
+	limit int
+	n     int
+}
+
+// newLinkBuffer creates an empty buffer that spills to disk above limit links.
+func newLinkBuffer(limit int) *linkBuffer {
+	return &linkBuffer{limit: limit}
+}
+
+// add appends pml to the buffer, spilling it to disk if the in-memory part is full.
+func (b *linkBuffer) add(pml parsedMusicLink) error {
+	b.n++
+
+	if b.limit <= 0 || len(b.mem) < b.limit {
+		b.mem = append(b.mem, pml)
+		return nil
+	}
+
+	if b.file == nil {
+		f, err := os.CreateTemp("", "wap-bot-links-*.jsonl")
+		if err != nil {
+			return fmt.Errorf("creating spill file: %w", err)
+		}
+
+		b.file, b.w = f, bufio.NewWriter(f)
+	}
+
+	if err := json.NewEncoder(b.w).Encode(pml); err != nil {
+		return fmt.Errorf("spilling link: %w", err)
+	}
+
+	return nil
+}
+
+// len returns the number of links in the buffer.
+func (b *linkBuffer) len() int {
+	return b.n
+}
+
+// each calls fn with every link in order, stopping at the first error.
+func (b *linkBuffer) each(fn func(parsedMusicLink) error) error {
+	for _, pml := range b.mem {
+		if err := fn(pml); err != nil {
+			return err
+		}
+	}
+
+	if b.file == nil {
+		return nil
+	}
+
+	if err := b.w.Flush(); err != nil {
+		return fmt.Errorf("flushing spill file: %w", err)
+	}
+
+	if _, err := b.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("rewinding spill file: %w", err)
+	}
+
+	// Later links are appended to the end of the file, wherever the decoder stopped
+	defer func() { _, _ = b.file.Seek(0, io.SeekEnd) }()
+
+	dec := json.NewDecoder(bufio.NewReader(b.file))
+
+	for {
+		var pml parsedMusicLink
+
+		err := dec.Decode(&pml)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("reading spill file: %w", err)
+		}
+
+		if err = fn(pml); err != nil {
+			return err
+		}
+	}
+}
+
+// close releases the buffer and removes its spill file, if any.
+func (b *linkBuffer) close() error {
+	b.mem = nil
+
+	if b.file == nil {
+		return nil
+	}
+
+	name := b.file.Name()
+	cErr := b.file.Close()
+	b.file, b.w = nil, nil
+
+	if err := os.Remove(name); err != nil {
+		return fmt.Errorf("removing spill file: %w", err)
+	}
+
+	if cErr != nil {
+		return fmt.Errorf("closing spill file: %w", cErr)
+	}
+
+	return nil
+}
+
+// fileSpool collects an encoded summary file, keeping at most limit bytes in memory and moving the file
+// to a temporary file above that, so huge summaries are uploaded from disk instead of being built in memory.
+//
+// A failed write is kept and returned by every later write and by reader, so the encoders can write
+// without checking every call. A non-positive limit keeps the whole file in memory.
+type fileSpool struct {
+	mem   *bytes.Buffer
+	file  *os.File
+	w     *bufio.Writer
+	err   error
+	limit int
+	size  int
+}
+
+// newFileSpool creates an empty spool that moves to disk above limit bytes.
+func newFileSpool(limit int) *fileSpool {
+	return &fileSpool{mem: getBuffer(), limit: limit}
+}
+
+// Write appends p to the file, moving it to disk once it's larger than the limit.
+func (s *fileSpool) Write(p []byte) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+
+	if s.file == nil && (s.limit <= 0 || s.mem.Len()+len(p) <= s.limit) {
+		s.size += len(p)
+
+		return s.mem.Write(p) //nolint:wrapcheck // writing to a bytes.Buffer never fails
+	}
+
+	if s.file == nil {
+		f, err := os.CreateTemp("", "wap-bot-summary-*")
+		if err != nil {
+			s.err = fmt.Errorf("creating spool file: %w", err)
+
+			return 0, s.err
+		}
+
+		s.file, s.w = f, bufio.NewWriter(f)
+
+		_, _ = s.w.Write(s.mem.Bytes())
+		releaseBuffer(s.mem)
+		s.mem = nil
+	}
+
+	n, err := s.w.Write(p)
+	s.size += n
+
+	if err != nil {
+		s.err = fmt.Errorf("writing spool file: %w", err)
+
+		return n, s.err
+	}
+
+	return n, nil
+}
+
+// reader returns the spooled file and its size, the spool can't be written anymore.
+//
+// A file on disk is read from a spooledFile, which removes it once it's read to the end or closed.
+func (s *fileSpool) reader() (io.Reader, int, error) {
+	if s.err != nil {
+		_ = s.discard()
+
+		return nil, 0, s.err
+	}
+
+	if s.file == nil {
+		r, n := detachBuffer(s.mem)
+		s.mem = nil
+
+		return r, n, nil
+	}
+
+	if err := s.w.Flush(); err != nil {
+		_ = s.discard()
+
+		return nil, 0, fmt.Errorf("flushing spool file: %w", err)
+	}
+
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		_ = s.discard()
+
+		return nil, 0, fmt.Errorf("rewinding spool file: %w", err)
+	}
+
+	f := &spooledFile{file: s.file}
+	s.file, s.w = nil, nil
+
+	return f, s.size, nil
+}
+
+// discard releases the spool and removes its temporary file, if any.
+func (s *fileSpool) discard() error {
+	if s.mem != nil {
+		releaseBuffer(s.mem)
+		s.mem = nil
+	}
+
+	if s.file == nil {
+		return nil
+	}
+
+	f := &spooledFile{file: s.file}
+	s.file, s.w = nil, nil
+
+	return f.Close()
+}
+
+// spooledFile reads a summary spooled to disk and removes it once it's read to the end or closed.
+type spooledFile struct {
+	file *os.File
+	eof  bool
+}
+
+var _ io.ReadCloser = (*spooledFile)(nil)
+
+// Read reads the next part of the file, removing it at the end.
+func (f *spooledFile) Read(p []byte) (int, error) {
+	if f.file == nil {
+		if f.eof {
+			return 0, io.EOF
+		}
+
+		return 0, os.ErrClosed
+	}
+
+	n, err := f.file.Read(p)
+	if errors.Is(err, io.EOF) {
+		f.eof = true
+
+		if cErr := f.Close(); cErr != nil {
+			return n, cErr
+		}
+	}
+
+	return n, err //nolint:wrapcheck // io.EOF has to reach the caller as is
+}
+
+// Close closes and removes the file, it's safe to call more than once.
+func (f *spooledFile) Close() error {
+	if f.file == nil {
+		return nil
+	}
+
+	name := f.file.Name()
+	cErr := f.file.Close()
+	f.file = nil
+
+	if err := os.Remove(name); err != nil {
+		return fmt.Errorf("removing spool file: %w", err)
+	}
+
+	if cErr != nil {
+		return fmt.Errorf("closing spool file: %w", cErr)
+	}
+
+	return nil
+}
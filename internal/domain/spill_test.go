@@ -0,0 +1,224 @@
+package domain
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func collectLinks(t *testing.T, b *linkBuffer) []string {
+	t.Helper()
+
+	var urls []string
+
+	require.NoError(t, b.each(func(pml parsedMusicLink) error {
+		urls = append(urls, pml.URL)
+		return nil
+	}))
+
+	return urls
+}
+
+func TestLinkBuffer_SpillsAboveLimit(t *testing.T) {
+	t.Parallel()
+
+	b := newLinkBuffer(2)
+
+	for _, url := range []string{"https://a", "https://b", "https://c", "https://d"} {
+		require.NoError(t, b.add(parsedMusicLink{
+			URL:        url,
+			Type:       musicextractors.SpotifyProvider,
+			CrossLinks: map[musicextractors.ExtractProvider]string{musicextractors.YouTubeProvider: url + "/yt"},
+			Metadata:   musicextractors.TrackMetadata{Title: "Song", Genres: []string{"pop"}},
+		}))
+	}
+
+	require.NotNil(t, b.file)
+	assert.Len(t, b.mem, 2)
+	assert.Equal(t, 4, b.len())
+	assert.Equal(t, []string{"https://a", "https://b", "https://c", "https://d"}, collectLinks(t, b))
+
+	// Links added after reading are appended, not written over the spilled ones
+	require.NoError(t, b.add(parsedMusicLink{URL: "https://e"}))
+	assert.Equal(t, []string{"https://a", "https://b", "https://c", "https://d", "https://e"}, collectLinks(t, b))
+
+	// Spilled links round-trip with their metadata
+	var last parsedMusicLink
+
+	require.NoError(t, b.each(func(pml parsedMusicLink) error {
+		if pml.URL == "https://d" {
+			last = pml
+		}
+
+		return nil
+	}))
+	assert.Equal(t, "https://d/yt", last.CrossLinks[musicextractors.YouTubeProvider])
+	assert.Equal(t, []string{"pop"}, last.Metadata.Genres)
+
+	name := b.file.Name()
+	require.NoError(t, b.close())
+
+	_, err := os.Stat(name)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestLinkBuffer_NoLimitKeepsEverythingInMemory(t *testing.T) {
+	t.Parallel()
+
+	b := newLinkBuffer(0)
+
+	for range 100 {
+		require.NoError(t, b.add(parsedMusicLink{URL: "https://a"}))
+	}
+
+	assert.Nil(t, b.file)
+	assert.Len(t, b.mem, 100)
+	require.NoError(t, b.close())
+}
+
+func TestLinkBuffer_EachStopsAtError(t *testing.T) {
+	t.Parallel()
+
+	errStop := errors.New("stop")
+	b := newLinkBuffer(1)
+
+	require.NoError(t, b.add(parsedMusicLink{URL: "https://a"}))
+	require.NoError(t, b.add(parsedMusicLink{URL: "https://b"}))
+
+	calls := 0
+	err := b.each(func(parsedMusicLink) error {
+		calls++
+		return errStop
+	})
+
+	require.ErrorIs(t, err, errStop)
+	assert.Equal(t, 1, calls)
+	require.NoError(t, b.close())
+}
+
+func TestFileSpool_SpillsAboveLimit(t *testing.T) {
+	t.Parallel()
+
+	s := newFileSpool(8)
+
+	_, err := io.WriteString(s, "Title;URL\n")
+	require.NoError(t, err)
+	require.NotNil(t, s.file)
+
+	_, err = io.WriteString(s, "Song;https://a\n")
+	require.NoError(t, err)
+
+	name := s.file.Name()
+
+	r, size, err := s.reader()
+	require.NoError(t, err)
+	assert.Equal(t, 25, size)
+
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "Title;URL\nSong;https://a\n", string(got))
+
+	// The file is removed once it's read to the end, closing it again is a no-op
+	_, err = os.Stat(name)
+	require.ErrorIs(t, err, os.ErrNotExist)
+	require.NoError(t, r.(io.Closer).Close())
+}
+
+func TestFileSpool_SmallFileStaysInMemory(t *testing.T) {
+	t.Parallel()
+
+	s := newFileSpool(1 << 10)
+
+	_, err := io.WriteString(s, "Title;URL\n")
+	require.NoError(t, err)
+
+	r, size, err := s.reader()
+	require.NoError(t, err)
+	assert.Nil(t, s.file)
+	assert.IsType(t, &bytes.Reader{}, r)
+	assert.Equal(t, 10, size)
+}
+
+func TestSummary_Close_RemovesUnreadSpool(t *testing.T) {
+	t.Parallel()
+
+	s := newFileSpool(1)
+
+	_, err := io.WriteString(s, "Title;URL\n")
+	require.NoError(t, err)
+
+	name := s.file.Name()
+
+	r, size, err := s.reader()
+	require.NoError(t, err)
+
+	summary := Summary{Upload: slack.UploadFileV2Parameters{Reader: r, FileSize: size}}
+	require.NoError(t, summary.Close())
+
+	_, err = os.Stat(name)
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = r.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
+
+func TestMessageProcessor_SummarizeThreadAs_SpilledFile(t *testing.T) {
+	t.Parallel()
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{Timestamp: "1700000000.000200", Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+		{Msg: slack.Msg{Timestamp: "1700000000.000300", Text: "https://www.youtube.com/watch?v=dQw4w9WgXcQ"}},
+	}
+
+	summarize := func(spillBytes int, format ExportFormat) []byte {
+		smp := NewSlackMessageProcessor(ProcessorConfig{
+			URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+				musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+				musicextractors.YouTubeProvider: musicextractors.YouTubeURLExtractor,
+			},
+			MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+				musicextractors.SpotifyProvider: staticTitle("Spotify Song"),
+				musicextractors.YouTubeProvider: staticTitle("YouTube Song"),
+			},
+			Format:         ExportFormatCSV,
+			Compression:    Compression{Kind: CompressionGzip, ThresholdBytes: 1},
+			FileSpillBytes: spillBytes,
+		})
+
+		summary, err := smp.SummarizeThreadAs(t.Context(), msgs, "C123", "1700000000.000100", SummaryOptions{Format: format})
+		require.NoError(t, err)
+
+		t.Cleanup(func() { assert.NoError(t, summary.Close()) })
+
+		gz, err := gzip.NewReader(summary.Upload.Reader)
+		require.NoError(t, err)
+
+		got, err := io.ReadAll(gz)
+		require.NoError(t, err)
+
+		return got
+	}
+
+	for _, format := range []ExportFormat{ExportFormatCSV, ExportFormatJSON, ExportFormatXSPF, ExportFormatTranscript} {
+		t.Run(string(format), func(t *testing.T) {
+			t.Parallel()
+
+			inMemory, spilled := summarize(0, format), summarize(16, format)
+
+			// The JSON export has its generation time, only the tracks have to match
+			if format == ExportFormatJSON {
+				inMemory, spilled = inMemory[bytes.Index(inMemory, []byte(`"tracks"`)):], spilled[bytes.Index(spilled, []byte(`"tracks"`)):]
+			}
+
+			assert.Equal(t, string(inMemory), string(spilled))
+		})
+	}
+}
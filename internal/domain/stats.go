@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+)
+
+// statsTopContributors is the number of users listed in the stats section of the summary comment.
+const statsTopContributors = 3
+
+// Contributor is a user who shared links in a thread.
+type Contributor struct {
+	UserID string
+	// Links is the number of links the user shared, duplicates included.
+	Links int
+}
+
+// SummaryStats are the statistics of a summary, listed in its comment when enabled.
+type SummaryStats struct {
+	// Providers counts the links of the summary by provider.
+	Providers map[musicextractors.ExtractProvider]int
+	// Contributors are the users who shared the links, most links first.
+	Contributors []Contributor
+	// Links is the number of summarized links, UniqueTracks the number of rows they were merged into.
+	Links        int
+	UniqueTracks int
+	// Messages is the number of messages of the thread, zero if the summary didn't read the thread, like retried titles.
+	Messages int
+	// MessagesWithLinks is the number of messages with a summarized link.
+	MessagesWithLinks int
+	// Skipped is the number of links left out of the summary.
+	Skipped int
+	// DurationSeconds is the total runtime of the unique tracks with a known duration.
+	DurationSeconds int
+}
+
+// summaryStats collects the statistics of the links and their merged rows.
+func summaryStats(links *linkBuffer, rows rowsFunc, counts summaryCounts) (SummaryStats, error) {
+	stats := SummaryStats{
+		Providers: map[musicextractors.ExtractProvider]int{},
+		Links:     links.len(),
+		Messages:  counts.messages,
+	}
+
+	for _, n := range counts.skipped {
+		stats.Skipped += n
+	}
+
+	messages := map[string]struct{}{}
+	users := map[string]int{}
+
+	err := links.each(func(pml parsedMusicLink) error {
+		stats.Providers[pml.Type]++
+		messages[pml.MessageTS] = struct{}{}
+
+		if pml.UserID != "" {
+			users[pml.UserID]++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return SummaryStats{}, err
+	}
+
+	err = rows(func(pml parsedMusicLink) error {
+		stats.UniqueTracks++
+		stats.DurationSeconds += max(pml.Metadata.DurationSeconds, 0)
+
+		return nil
+	})
+	if err != nil {
+		return SummaryStats{}, err
+	}
+
+	stats.MessagesWithLinks = len(messages)
+
+	for userID, n := range users {
+		stats.Contributors = append(stats.Contributors, Contributor{UserID: userID, Links: n})
+	}
+
+	slices.SortFunc(stats.Contributors, func(a, b Contributor) int {
+		return cmp.Or(cmp.Compare(b.Links, a.Links), cmp.Compare(a.UserID, b.UserID))
+	})
+
+	return stats, nil
+}
+
+// runtimeNote returns the total runtime of the tracks for the summary comment, like ", ~2h 51m of music",
+// rounded to minutes, empty if no track has a known duration.
+func runtimeNote(seconds int) string {
+	if seconds <= 0 {
+		return ""
+	}
+
+	minutes := max((seconds+30)/60, 1)
+
+	switch h, m := minutes/60, minutes%60; {
+	case h == 0:
+		return fmt.Sprintf(", ~%dm of music", m)
+	case m == 0:
+		return fmt.Sprintf(", ~%dh of music", h)
+	default:
+		return fmt.Sprintf(", ~%dh %dm of music", h, m)
+	}
+}
+
+// note renders the stats as the mrkdwn section of the summary comment, the users are listed by their name,
+// the top sharers are left out if userName is nil.
+func (st SummaryStats) note(userName func(string) string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "\n*Stats:* %d unique tracks from %d links", st.UniqueTracks, st.Links)
+
+	if st.Messages > 0 {
+		fmt.Fprintf(&b, " in %d of %d messages", st.MessagesWithLinks, st.Messages)
+	}
+
+	if len(st.Providers) > 0 {
+		providers := slices.SortedFunc(maps.Keys(st.Providers), func(a, b musicextractors.ExtractProvider) int {
+			return cmp.Or(cmp.Compare(st.Providers[b], st.Providers[a]), cmp.Compare(a, b))
+		})
+
+		counts := make([]string, 0, len(providers))
+		for _, p := range providers {
+			counts = append(counts, fmt.Sprintf("%s %d", p, st.Providers[p]))
+		}
+
+		b.WriteString("\n*Providers:* " + strings.Join(counts, ", "))
+	}
+
+	if len(st.Contributors) > 0 && userName != nil {
+		top := make([]string, 0, statsTopContributors)
+		for _, c := range st.Contributors[:min(statsTopContributors, len(st.Contributors))] {
+			top = append(top, fmt.Sprintf("%s (%d)", EscapeMrkdwn(userName(c.UserID)), c.Links))
+		}
+
+		b.WriteString("\n*Top sharers:* " + strings.Join(top, ", "))
+	}
+
+	return b.String()
+}
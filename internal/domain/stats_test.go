@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageProcessor_SummarizeThread_Stats(t *testing.T) {
+	t.Parallel()
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{User: "U1", Timestamp: "1", Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+		{Msg: slack.Msg{User: "U2", Timestamp: "2", Text: "https://www.youtube.com/watch?v=dQw4w9WgXcQ"}},
+		{Msg: slack.Msg{User: "U1", Timestamp: "3", Text: "again https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+		{Msg: slack.Msg{User: "U3", Timestamp: "4", Text: "no links here"}},
+		{Msg: slack.Msg{User: "U2", Timestamp: "5", Text: "https://open.spotify.com/track/7ouMYWpwJ422jRcDASZB7P"}},
+	}
+
+	tests := []struct {
+		name  string
+		want  string
+		stats bool
+	}{
+		{name: "disabled", want: "Found 4 music URLs in this thread"},
+		{
+			name:  "enabled",
+			stats: true,
+			want: "Found 4 music URLs in this thread\n" +
+				"*Stats:* 3 unique tracks from 4 links in 4 of 5 messages\n" +
+				"*Providers:* spotify 3, youtube 1\n" +
+				"*Top sharers:* ada (2), U2 (2)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			smp := NewSlackMessageProcessor(ProcessorConfig{
+				URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+					musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+					musicextractors.YouTubeProvider: musicextractors.YouTubeURLExtractor,
+				},
+				MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+					musicextractors.SpotifyProvider: staticTitle("Spotify Song"),
+					musicextractors.YouTubeProvider: staticTitle("YouTube Song"),
+				},
+				Dedupe:       dedupeStrategies[DedupeURL],
+				UserResolver: stubUsers{"U1": "ada"},
+				Stats:        tt.stats,
+				Format:       ExportFormatCSV,
+				Compression:  Compression{Kind: CompressionNone},
+			})
+
+			summary, err := smp.SummarizeThread(t.Context(), msgs, "C123", "1700000000.000100")
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, summary.Upload.InitialComment)
+			assert.Equal(t, []Contributor{{UserID: "U1", Links: 2}, {UserID: "U2", Links: 2}}, summary.Stats.Contributors)
+		})
+	}
+}
+
+func TestMessageProcessor_SummarizeThreadAs_AnonymousStats(t *testing.T) {
+	t.Parallel()
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{User: "U1", Timestamp: "1", Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+		{Msg: slack.Msg{User: "U2", Timestamp: "2", Text: "https://open.spotify.com/track/7ouMYWpwJ422jRcDASZB7P"}},
+	}
+
+	var recapped string
+
+	smp := NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: staticTitle("Spotify Song"),
+		},
+		TextSummarizer: textSummarizerFunc(func(_ context.Context, conversation string) (string, error) {
+			recapped = conversation
+
+			return "Two songs.", nil
+		}),
+		// Every name lookup fails the test, an anonymous summary must not resolve any
+		UserResolver: userResolverFunc(func(context.Context, string) (string, error) {
+			t.Error("anonymous summary resolved a user name")
+
+			return "", assert.AnError
+		}),
+		Stats:       true,
+		Format:      ExportFormatCSV,
+		Compression: Compression{Kind: CompressionNone},
+	})
+
+	summary, err := smp.SummarizeThreadAs(t.Context(), msgs, "C123", "1700000000.000100", SummaryOptions{Anonymous: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Found 2 music URLs in this thread\n"+
+		"*Stats:* 2 unique tracks from 2 links in 2 of 2 messages\n"+
+		"*Providers:* spotify 2\n\nTwo songs.", summary.Upload.InitialComment)
+	assert.NotContains(t, recapped, "U1")
+	// The tracks are kept for the bot itself, with who shared them
+	assert.Equal(t, "U1", summary.Tracks[0].UserID)
+}
+
+func TestRuntimeNote(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		seconds int
+		want    string
+	}{
+		{seconds: 0, want: ""},
+		{seconds: 12, want: ", ~1m of music"},
+		{seconds: 42*60 + 29, want: ", ~42m of music"},
+		{seconds: 2 * 3600, want: ", ~2h of music"},
+		{seconds: 2*3600 + 50*60 + 31, want: ", ~2h 51m of music"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, runtimeNote(tt.seconds))
+	}
+}
@@ -0,0 +1,175 @@
+package domain
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack"
+)
+
+// Summary is the outcome of summarizing a thread.
+type Summary struct {
+	// Format is the format the summary was created in.
+	Format ExportFormat
+	// Tracks contains every track found in the thread, in the order of the messages.
+	Tracks []Track
+	// Upload is the summary file, ready to be uploaded to the thread.
+	// Inline summaries have no file, only the comment, channel and thread of the upload are set.
+	Upload slack.UploadFileV2Parameters
+	// Messages are the mrkdwn messages of inline summaries, posted to the thread in order instead of the file.
+	Messages []string
+	// Skipped counts the links left out of the summary by the kind of failure, like rate limits or broken title lookups.
+	Skipped map[musicextractors.ErrorKind]int
+	// FailedTitles are the links whose title couldn't be resolved, the failure report RetryTitles picks up later.
+	FailedTitles []FailedTitle
+	// Failures are the links left out of the summary with the message they were shared in and the error,
+	// the diagnostics behind the Skipped counts, so they can be logged or reported.
+	Failures []MessageFailure
+	// Stats are the statistics of the summary, they are only listed in the comment if enabled.
+	Stats SummaryStats
+	// Digest is the prose recap of the discussion at the end of the comment, empty without a text summarizer.
+	Digest string
+}
+
+// Close releases the summary file, like the temporary file of a huge summary, it's safe to call after the upload read it.
+func (s Summary) Close() error {
+	if closer, ok := s.Upload.Reader.(io.Closer); ok {
+		return closer.Close() //nolint:wrapcheck // the spooled file wraps its errors
+	}
+
+	return nil
+}
+
+// MessageFailure is a link of a message left out of the summary because it couldn't be extracted.
+type MessageFailure struct {
+	// MessageTS and UserID identify the message the link was shared in.
+	MessageTS string
+	UserID    string
+	// URL and Provider are the failed link, empty if it failed before its provider was recognized.
+	URL      string
+	Provider musicextractors.ExtractProvider
+	// Kind is the kind of the failure, like a rate limit.
+	Kind musicextractors.ErrorKind
+	// Err is the error of the extraction.
+	Err error
+}
+
+// FailedTitle is a link whose title couldn't be resolved, either left out of the summary
+// or listed without a title because the circuit of its provider was open.
+type FailedTitle struct {
+	Track
+	Kind musicextractors.ErrorKind
+}
+
+// Track is a single music link found in a thread message.
+type Track struct {
+	Title     string
+	Artist    string
+	URL       string
+	Provider  musicextractors.ExtractProvider
+	MessageTS string
+	UserID    string
+	// ArtworkURL is the album artwork or thumbnail of the track, empty if the provider has none.
+	ArtworkURL string
+	// DurationSeconds is the length of the track, zero if it's unknown.
+	DurationSeconds int
+	// Genres are the genre tags of the track, the most relevant first, empty if it has none.
+	Genres []string
+	// CrossLinks contains the URL of the same track on other providers, if cross-linking is enabled.
+	CrossLinks map[musicextractors.ExtractProvider]string
+}
+
+// track converts the parsed link to its exported form.
+func (pml parsedMusicLink) track() Track {
+	return Track{
+		Title:           pml.Title,
+		Artist:          pml.Metadata.Artist,
+		URL:             pml.URL,
+		Provider:        pml.Type,
+		MessageTS:       pml.MessageTS,
+		UserID:          pml.UserID,
+		ArtworkURL:      pml.Metadata.ArtworkURL,
+		Genres:          pml.Metadata.Genres,
+		DurationSeconds: pml.Metadata.DurationSeconds,
+		CrossLinks:      pml.CrossLinks,
+	}
+}
+
+// ProviderURL returns the URL of the track on provider, the shared URL or its cross-link, empty if there is none.
+func (t Track) ProviderURL(provider musicextractors.ExtractProvider) string {
+	if t.Provider == provider {
+		return t.URL
+	}
+
+	return t.CrossLinks[provider]
+}
+
+// link converts the track back to a parsed link, with the metadata the track keeps.
+func (t Track) link() parsedMusicLink {
+	// The title of the track is the display title, the artist is only a prefix of it
+	title := t.Title
+	if t.Artist != "" {
+		title = strings.TrimPrefix(title, t.Artist+" - ")
+	}
+
+	return parsedMusicLink{
+		CrossLinks: t.CrossLinks,
+		Title:      t.Title,
+		URL:        t.URL,
+		Type:       t.Provider,
+		MessageTS:  t.MessageTS,
+		UserID:     t.UserID,
+		Metadata: musicextractors.TrackMetadata{
+			Title:           title,
+			Artist:          t.Artist,
+			ArtworkURL:      t.ArtworkURL,
+			Genres:          t.Genres,
+			DurationSeconds: t.DurationSeconds,
+		},
+	}
+}
+
+// tracks converts the parsed links to their exported form.
+func tracks(links *linkBuffer) ([]Track, error) {
+	t := make([]Track, 0, links.len())
+
+	err := links.each(func(pml parsedMusicLink) error {
+		t = append(t, pml.track())
+
+		return nil
+	})
+
+	return t, err
+}
+
+// skippedReasons describe the kinds of failures in the summary comment.
+var skippedReasons = map[musicextractors.ErrorKind]string{
+	musicextractors.ErrorKindMultipleURLs:  "several links in one message",
+	musicextractors.ErrorKindRateLimited:   "rate limited by the provider",
+	musicextractors.ErrorKindUnavailable:   "provider unavailable",
+	musicextractors.ErrorKindTitleNotFound: "title not found",
+	musicextractors.ErrorKindUnknown:       "unknown error",
+}
+
+// skippedNote describes the skipped links for the summary comment, ordered by kind.
+//
+// Returns an empty string if no link was skipped.
+func skippedNote(skipped map[musicextractors.ErrorKind]int) string {
+	total := 0
+	reasons := make([]string, 0, len(skipped))
+
+	for _, kind := range slices.Sorted(maps.Keys(skipped)) {
+		total += skipped[kind]
+		reasons = append(reasons, fmt.Sprintf("%d %s", skipped[kind], skippedReasons[kind]))
+	}
+
+	if total == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(", skipped %d (%s)", total, strings.Join(reasons, ", "))
+}
@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// ErrInvalidTemplate returned by ParseSummaryTemplates if a template can't be parsed or rendered.
+var ErrInvalidTemplate = errors.New("invalid summary template")
+
+// TemplateData are the variables of the summary templates, like {{.Count}}.
+type TemplateData struct {
+	// Channel and Thread are the IDs of the summarized channel and the timestamp of the thread, empty for a channel summary.
+	Channel string
+	Thread  string
+	// Count is the number of summarized links.
+	Count int
+	// Date is the day of the summary in UTC, like 2026-01-31.
+	Date string
+}
+
+// SummaryTemplates customize the texts of the summaries, so they can be localized or branded,
+// the nil templates keep the built-in texts.
+type SummaryTemplates struct {
+	// Comment renders the headline of the summary comment, the notes like the skipped links are appended to it.
+	Comment *template.Template
+	// FileName renders the name of the summary file, the extension of the format is appended to it.
+	FileName *template.Template
+}
+
+// ParseSummaryTemplates parses the comment and the file name templates, empty ones keep the built-in texts.
+//
+// Returns the templates or ErrInvalidTemplate if one can't be parsed or renders with an unknown variable.
+func ParseSummaryTemplates(comment, fileName string) (SummaryTemplates, error) {
+	var templates SummaryTemplates
+
+	for _, t := range []struct {
+		name string
+		text string
+		dst  **template.Template
+	}{
+		{name: "comment", text: comment, dst: &templates.Comment},
+		{name: "file name", text: fileName, dst: &templates.FileName},
+	} {
+		if t.text == "" {
+			continue
+		}
+
+		tmpl, err := parseSummaryTemplate(t.name, t.text)
+		if err != nil {
+			return SummaryTemplates{}, err
+		}
+
+		*t.dst = tmpl
+	}
+
+	return templates, nil
+}
+
+// parseSummaryTemplate parses text and renders it once, so the unknown variables fail at startup instead of every summary.
+func parseSummaryTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidTemplate, name, err)
+	}
+
+	if _, err = render(tmpl, TemplateData{}); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidTemplate, name, err)
+	}
+
+	return tmpl, nil
+}
+
+// render executes tmpl with data.
+func render(tmpl *template.Template, data TemplateData) (string, error) {
+	var b strings.Builder
+
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("rendering %s template: %w", tmpl.Name(), err)
+	}
+
+	return b.String(), nil
+}
+
+// templateData returns the variables of the summary of a thread with count links.
+func templateData(channelID, threadTS string, count int) TemplateData {
+	return TemplateData{Channel: channelID, Thread: threadTS, Count: count, Date: time.Now().UTC().Format(time.DateOnly)}
+}
+
+// comment renders the comment template, fallback if there's none.
+func (t SummaryTemplates) comment(data TemplateData, fallback string) (string, error) {
+	if t.Comment == nil {
+		return fallback, nil
+	}
+
+	return render(t.Comment, data)
+}
+
+// fileName renders the file name template without the extension, the channel and thread joined by a dash if there's none,
+// only the channel for the summaries of a channel.
+//
+// The path separators are replaced, so the name can't point to another directory, and an empty name keeps the built-in one.
+func (t SummaryTemplates) fileName(data TemplateData) (string, error) {
+	fallback := data.Channel
+	if data.Thread != "" {
+		fallback += "-" + data.Thread
+	}
+	if t.FileName == nil {
+		return fallback, nil
+	}
+
+	name, err := render(t.FileName, data)
+	if err != nil {
+		return "", err
+	}
+
+	name = strings.TrimSpace(strings.NewReplacer("/", "-", `\`, "-").Replace(name))
+	if name == "" {
+		return fallback, nil
+	}
+
+	return name, nil
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"io"
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSummaryTemplates(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		comment  string
+		fileName string
+		wantErr  bool
+	}{
+		{name: "empty"},
+		{name: "valid", comment: "{{.Count}} tracks in {{.Channel}}", fileName: "{{.Date}}-{{.Thread}}"},
+		{name: "syntax error", comment: "{{.Count", wantErr: true},
+		{name: "unknown variable", fileName: "{{.Author}}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			templates, err := ParseSummaryTemplates(tt.comment, tt.fileName)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidTemplate)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.comment != "", templates.Comment != nil)
+			assert.Equal(t, tt.fileName != "", templates.FileName != nil)
+		})
+	}
+}
+
+func TestSummaryTemplates_FileName(t *testing.T) {
+	t.Parallel()
+
+	data := TemplateData{Channel: "C123", Thread: "1700000000.000100", Count: 2, Date: "2026-01-31"}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{name: "built-in", want: "C123-1700000000.000100"},
+		{name: "custom", template: "mixtape {{.Date}} ({{.Count}})", want: "mixtape 2026-01-31 (2)"},
+		{name: "path separators", template: "../{{.Channel}}\\x", want: "..-C123-x"},
+		{name: "empty result", template: "{{if false}}x{{end}} ", want: "C123-1700000000.000100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			templates, err := ParseSummaryTemplates("", tt.template)
+			require.NoError(t, err)
+
+			got, err := templates.fileName(data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSummaryTemplates_FileName_Channel(t *testing.T) {
+	t.Parallel()
+
+	got, err := SummaryTemplates{}.fileName(TemplateData{Channel: "C123"})
+	require.NoError(t, err)
+	assert.Equal(t, "C123", got)
+}
+
+func TestMessageProcessor_SummarizeThread_Templates(t *testing.T) {
+	t.Parallel()
+
+	templates, err := ParseSummaryTemplates("{{.Count}} tracks in <#{{.Channel}}>", "mixtape-{{.Date}}")
+	require.NoError(t, err)
+
+	smp := NewSlackMessageProcessor(ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.SpotifyProvider: musicextractors.SpotifyURLExtractor,
+		},
+		MetadataExtractors: map[musicextractors.ExtractProvider]musicextractors.MetadataExtractorFunc{
+			musicextractors.SpotifyProvider: staticTitle("Never Gonna Give You Up"),
+		},
+		Templates:   templates,
+		Format:      ExportFormatCSV,
+		Compression: Compression{Kind: CompressionNone},
+	})
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{Text: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"}},
+	}
+
+	summary, err := smp.SummarizeThread(t.Context(), msgs, "C123", "1700000000.000100")
+	require.NoError(t, err)
+
+	_, err = io.ReadAll(summary.Upload.Reader)
+	require.NoError(t, err)
+
+	assert.Equal(t, "1 tracks in <#C123>", summary.Upload.InitialComment)
+	assert.Regexp(t, `^mixtape-\d{4}-\d{2}-\d{2}\.csv$`, summary.Upload.Filename)
+}
@@ -0,0 +1,9 @@
+package domain
+
+import "context"
+
+// UserResolver resolves the display name of the Slack users who shared the tracks of a summary.
+type UserResolver interface {
+	// UserName returns the name the user is shown with in Slack.
+	UserName(ctx context.Context, userID string) (string, error)
+}
@@ -0,0 +1,33 @@
+//go:build !faultinject
+
+package faults
+
+import (
+	"net/http"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/gorilla/websocket"
+)
+
+// Enabled reports if the binary was built with the faultinject tag.
+func Enabled() bool { return false }
+
+// Arm does nothing without the faultinject tag.
+func Arm(Fault, int) {}
+
+// Disarm does nothing without the faultinject tag.
+func Disarm(Fault) {}
+
+// Reset does nothing without the faultinject tag.
+func Reset() {}
+
+// SlackTransport returns next as is without the faultinject tag.
+func SlackTransport(next http.RoundTripper) http.RoundTripper { return next }
+
+// SocketDialer returns nil without the faultinject tag, so Socket Mode uses its default dialer.
+func SocketDialer() *websocket.Dialer { return nil }
+
+// ProviderTimeouts returns next as is without the faultinject tag.
+func ProviderTimeouts(_ musicextractors.ExtractProvider, next musicextractors.MetadataExtractorFunc) musicextractors.MetadataExtractorFunc {
+	return next
+}
@@ -0,0 +1,9 @@
+/*
+Package faults injects failures into the bot on demand, so integration tests can exercise the retry,
+circuit breaker and backoff paths without a misbehaving Slack or provider.
+
+The faults are only compiled in with the faultinject build tag (go test -tags faultinject ./...).
+In every other build the hooks return what they wrap as is and arming a fault does nothing,
+so production binaries carry no trace of the injection layer.
+*/
+package faults
@@ -0,0 +1,203 @@
+//go:build faultinject
+
+package faults
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/gorilla/websocket"
+)
+
+// slackRetryAfter is the Retry-After of the simulated rate limits, in seconds.
+const slackRetryAfter = "1"
+
+// errSocketDropped is returned by reads of a dropped Socket Mode connection.
+var errSocketDropped = errors.New("socket dropped by fault injection")
+
+var (
+	mu sync.Mutex
+	// armed holds the remaining triggers of every armed fault, negative values never run out.
+	armed = map[Fault]int{}
+	// sockets are the open Socket Mode connections.
+	sockets = map[*droppableConn]struct{}{}
+)
+
+// Enabled reports if the binary was built with the faultinject tag.
+func Enabled() bool { return true }
+
+// Arm makes f trigger the next times calls, a non-positive times keeps it triggering until it's disarmed.
+//
+// Arming SocketDrop closes the open connections right away, every closed connection counts as a trigger.
+func Arm(f Fault, times int) {
+	mu.Lock()
+
+	if times <= 0 {
+		times = -1
+	}
+
+	armed[f] = times
+
+	var drop []*droppableConn
+
+	if f == SocketDrop {
+		for c := range sockets {
+			if !takeLocked(SocketDrop) {
+				break
+			}
+
+			drop = append(drop, c)
+		}
+	}
+
+	mu.Unlock()
+
+	for _, c := range drop {
+		_ = c.Close()
+	}
+}
+
+// Disarm stops f from triggering.
+func Disarm(f Fault) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	delete(armed, f)
+}
+
+// Reset disarms every fault, call it in the cleanup of tests arming any.
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	clear(armed)
+}
+
+// take reports if f is armed, consuming one of its triggers.
+func take(f Fault) bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return takeLocked(f)
+}
+
+func takeLocked(f Fault) bool {
+	n, ok := armed[f]
+	if !ok {
+		return false
+	}
+
+	switch {
+	case n == 1:
+		delete(armed, f)
+	case n > 1:
+		armed[f] = n - 1
+	}
+
+	return true
+}
+
+// slackTransport answers the requests with a rate limit while SlackRateLimit is armed.
+type slackTransport struct {
+	next http.RoundTripper
+}
+
+func (t slackTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !take(SlackRateLimit) {
+		return t.next.RoundTrip(req) //nolint:wrapcheck // a transport must return the errors of the underlying transport as is
+	}
+
+	body := `{"ok":false,"error":"ratelimited"}`
+
+	return &http.Response{
+		Status:        "429 Too Many Requests",
+		StatusCode:    http.StatusTooManyRequests,
+		Proto:         req.Proto,
+		ProtoMajor:    req.ProtoMajor,
+		ProtoMinor:    req.ProtoMinor,
+		Header:        http.Header{"Retry-After": {slackRetryAfter}, "Content-Type": {"application/json"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// SlackTransport wraps next to simulate Slack rate limits while SlackRateLimit is armed,
+// http.DefaultTransport is used if next is nil.
+func SlackTransport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return slackTransport{next: next}
+}
+
+// droppableConn is a Socket Mode connection that can be closed by SocketDrop.
+type droppableConn struct {
+	net.Conn
+	once sync.Once
+}
+
+func (c *droppableConn) Read(b []byte) (int, error) {
+	if take(SocketDrop) {
+		_ = c.Close()
+		return 0, errSocketDropped
+	}
+
+	return c.Conn.Read(b) //nolint:wrapcheck // a connection must return the errors of the underlying connection as is
+}
+
+func (c *droppableConn) Close() error {
+	c.once.Do(func() {
+		mu.Lock()
+		delete(sockets, c)
+		mu.Unlock()
+	})
+
+	return c.Conn.Close() //nolint:wrapcheck // a connection must return the errors of the underlying connection as is
+}
+
+// SocketDialer returns a websocket dialer whose connections are closed while SocketDrop is armed.
+func SocketDialer() *websocket.Dialer {
+	d := *websocket.DefaultDialer
+	d.NetDialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := (&net.Dialer{}).DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err //nolint:wrapcheck // a dialer must return the errors of the underlying dialer as is
+		}
+
+		c := &droppableConn{Conn: conn}
+
+		mu.Lock()
+		sockets[c] = struct{}{}
+		mu.Unlock()
+
+		return c, nil
+	}
+
+	return &d
+}
+
+// ProviderTimeouts is a title extractor middleware making the lookups time out while ProviderTimeout is armed.
+//
+// A timed out lookup waits until its context is done and fails with ErrRequestFailed like a real request would,
+// lookups without a deadline fail right away.
+func ProviderTimeouts(_ musicextractors.ExtractProvider, next musicextractors.MetadataExtractorFunc) musicextractors.MetadataExtractorFunc {
+	return func(ctx context.Context, url string) (musicextractors.TrackMetadata, error) {
+		if !take(ProviderTimeout) {
+			return next(ctx, url)
+		}
+
+		if _, ok := ctx.Deadline(); ok {
+			<-ctx.Done()
+		}
+
+		return musicextractors.TrackMetadata{}, musicextractors.ErrRequestFailed
+	}
+}
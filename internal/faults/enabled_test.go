@@ -0,0 +1,133 @@
+//go:build faultinject
+
+package faults
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/gorilla/websocket"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The faults are process wide, so the tests arming them don't run in parallel.
+
+func TestArm_TriggersGivenTimes(t *testing.T) {
+	t.Cleanup(Reset)
+
+	Arm(ProviderTimeout, 2)
+
+	assert.True(t, take(ProviderTimeout))
+	assert.True(t, take(ProviderTimeout))
+	assert.False(t, take(ProviderTimeout))
+}
+
+func TestArm_UntilDisarmed(t *testing.T) {
+	t.Cleanup(Reset)
+
+	Arm(ProviderTimeout, 0)
+
+	for range 10 {
+		assert.True(t, take(ProviderTimeout))
+	}
+
+	Disarm(ProviderTimeout)
+	assert.False(t, take(ProviderTimeout))
+}
+
+func TestSlackTransport_RateLimited(t *testing.T) {
+	t.Cleanup(Reset)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true,"url":"https://example.slack.com/","team":"T","user":"U","team_id":"T1","user_id":"U1"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	api := slack.New("xoxb-test",
+		slack.OptionAPIURL(srv.URL+"/"),
+		slack.OptionHTTPClient(&http.Client{Transport: SlackTransport(nil)}),
+	)
+
+	Arm(SlackRateLimit, 1)
+
+	_, err := api.AuthTestContext(t.Context())
+
+	var rateLimited *slack.RateLimitedError
+	require.ErrorAs(t, err, &rateLimited)
+	assert.Equal(t, time.Second, rateLimited.RetryAfter)
+
+	resp, err := api.AuthTestContext(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "U1", resp.UserID)
+}
+
+func TestSocketDialer_DropsOpenConnections(t *testing.T) {
+	t.Cleanup(Reset)
+
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	conn, resp, err := SocketDialer().DialContext(t.Context(), "ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+
+	_ = resp.Body.Close()
+
+	t.Cleanup(func() { _ = conn.Close() })
+
+	Arm(SocketDrop, 1)
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err = conn.ReadMessage()
+	require.Error(t, err)
+	assert.ErrorIs(t, err, net.ErrClosed)
+	assert.False(t, take(SocketDrop), "the drop should be consumed by the open connection")
+}
+
+func TestProviderTimeouts(t *testing.T) {
+	t.Cleanup(Reset)
+
+	calls := 0
+	fn := ProviderTimeouts(musicextractors.SpotifyProvider, func(context.Context, string) (musicextractors.TrackMetadata, error) {
+		calls++
+		return musicextractors.TrackMetadata{Title: "Song"}, nil
+	})
+
+	Arm(ProviderTimeout, 1)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := fn(ctx, "https://open.spotify.com/track/1")
+	require.ErrorIs(t, err, musicextractors.ErrRequestFailed)
+	assert.True(t, errors.Is(ctx.Err(), context.DeadlineExceeded), "the lookup should hang until its deadline")
+	assert.Zero(t, calls)
+
+	md, err := fn(t.Context(), "https://open.spotify.com/track/1")
+	require.NoError(t, err)
+	assert.Equal(t, "Song", md.Title)
+	assert.Equal(t, 1, calls)
+}
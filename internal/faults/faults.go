@@ -0,0 +1,13 @@
+package faults
+
+// Fault is a failure that can be armed on demand.
+type Fault string
+
+const (
+	// SlackRateLimit answers Slack Web API calls with a 429 and a Retry-After header.
+	SlackRateLimit Fault = "slack_rate_limit"
+	// ProviderTimeout makes title lookups hang until their context is done and fail like a timed out request.
+	ProviderTimeout Fault = "provider_timeout"
+	// SocketDrop closes the Socket Mode connections, as if the network dropped them.
+	SocketDrop Fault = "socket_drop"
+)
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/internal/storage"
+)
+
+// optionAnonymous is the summarize and close command argument that leaves out who shared the tracks and when,
+// like anonymous=true, for summaries shared outside the channel they were made in.
+const optionAnonymous = "anonymous"
+
+// anonymousOption reports if the arguments of a command ask for an anonymous summary with anonymous=<bool>.
+func anonymousOption(args string) (bool, error) {
+	value, ok := parseArgs(args).option(optionAnonymous)
+	if !ok {
+		return false, nil
+	}
+
+	anonymous, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%w: %q isn't true or false", errInvalidAnonymousOption, value)
+	}
+
+	return anonymous, nil
+}
+
+// anonymousThread returns the archived thread without who closed it, who shared its tracks and when.
+func anonymousThread(thread storage.ArchivedThread) storage.ArchivedThread {
+	thread.ClosedBy = ""
+	thread.Tracks = append([]storage.IndexedTrack(nil), thread.Tracks...)
+
+	for i := range thread.Tracks {
+		thread.Tracks[i].UserID = ""
+		thread.Tracks[i].MessageTS = ""
+		thread.Tracks[i].SharedAt = time.Time{}
+	}
+
+	return thread
+}
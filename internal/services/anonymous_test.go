@@ -0,0 +1,77 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnonymousOption(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		args    string
+		want    bool
+		wantErr bool
+	}{
+		{name: "not given", args: "format=csv"},
+		{name: "true", args: "format=csv anonymous=true", want: true},
+		{name: "case-insensitive key", args: "ANONYMOUS=1", want: true},
+		{name: "false", args: "anonymous=false"},
+		{name: "invalid", args: "anonymous=maybe", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := anonymousOption(tt.args)
+			if tt.wantErr {
+				require.ErrorIs(t, err, errInvalidAnonymousOption)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAnonymousThread(t *testing.T) {
+	t.Parallel()
+
+	closedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tracks := []storage.IndexedTrack{{
+		SharedAt:  time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		ChannelID: "C123",
+		ThreadTS:  "1700000000.000100",
+		MessageTS: "1700000000.000200",
+		UserID:    "U1",
+		Title:     "Song",
+		URL:       "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT",
+		Provider:  "spotify",
+	}}
+
+	got := anonymousThread(storage.ArchivedThread{
+		ClosedAt: closedAt, ChannelID: "C123", ThreadTS: "1700000000.000100", ClosedBy: "U2", Tracks: tracks,
+	})
+
+	assert.Equal(t, storage.ArchivedThread{
+		ClosedAt:  closedAt,
+		ChannelID: "C123",
+		ThreadTS:  "1700000000.000100",
+		Tracks: []storage.IndexedTrack{{
+			ChannelID: "C123",
+			ThreadTS:  "1700000000.000100",
+			Title:     "Song",
+			URL:       "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT",
+			Provider:  "spotify",
+		}},
+	}, got)
+	assert.Equal(t, "U1", tracks[0].UserID, "the tracks of the summary are left as they are")
+}
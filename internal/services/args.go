@@ -0,0 +1,76 @@
+package services
+
+import (
+	"slices"
+	"strings"
+)
+
+// commandArguments are the parsed arguments of a command, key=value options and bare flags.
+//
+// Keys and flags are case-insensitive, the values are kept as they are. A repeated option keeps its first value.
+type commandArguments struct {
+	options map[string]string
+	flags   []string
+}
+
+// parseArgs parses the whitespace separated arguments of a command, like the ones returned by parseCommand.
+func parseArgs(args string) commandArguments {
+	parsed := commandArguments{options: map[string]string{}}
+
+	for _, arg := range strings.Fields(args) {
+		key, value, ok := strings.Cut(arg, "=")
+		key = strings.ToLower(key)
+
+		if !ok {
+			parsed.flags = append(parsed.flags, key)
+
+			continue
+		}
+
+		if _, seen := parsed.options[key]; !seen {
+			parsed.options[key] = value
+		}
+	}
+
+	return parsed
+}
+
+// flag reports if name was given as a bare flag.
+func (a commandArguments) flag(name string) bool {
+	return slices.Contains(a.flags, name)
+}
+
+// option returns the value of the key=value option and whether it was given.
+func (a commandArguments) option(key string) (string, bool) {
+	value, ok := a.options[key]
+
+	return value, ok
+}
+
+// list returns the lower-cased values of a comma separated key=a,b option, nil if it wasn't given.
+func (a commandArguments) list(key string) []string {
+	value, ok := a.options[key]
+	if !ok {
+		return nil
+	}
+
+	var values []string
+
+	for v := range strings.SplitSeq(strings.ToLower(value), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
+
+// optionList returns the supported values of an option separated by |.
+func optionList[T ~string](values []T) string {
+	names := make([]string, 0, len(values))
+	for _, v := range values {
+		names = append(names, string(v))
+	}
+
+	return strings.Join(names, "|")
+}
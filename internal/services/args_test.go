@@ -0,0 +1,54 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseArgs(t *testing.T) {
+	t.Parallel()
+
+	args := parseArgs("Format=CSV silent delimiter=, only=Spotify,,YouTube format=json FULL")
+
+	assert.True(t, args.flag("silent"))
+	assert.True(t, args.flag("full"))
+	assert.False(t, args.flag("format"))
+
+	// Keys are case-insensitive, values are kept and the first one wins
+	format, ok := args.option("format")
+	assert.True(t, ok)
+	assert.Equal(t, "CSV", format)
+
+	delimiter, ok := args.option("delimiter")
+	assert.True(t, ok)
+	assert.Equal(t, ",", delimiter)
+
+	_, ok = args.option("silent")
+	assert.False(t, ok)
+
+	assert.Equal(t, []string{"spotify", "youtube"}, args.list("only"))
+	assert.Nil(t, args.list("except"))
+}
+
+func TestOptionList(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		want string
+		got  string
+	}{
+		{name: "sort orders", got: optionList(domain.SortOrders()), want: "shared|title|artist|provider|popularity"},
+		{name: "empty", got: optionList([]domain.SortOrder{}), want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, tt.got)
+		})
+	}
+}
@@ -3,25 +3,68 @@ package services
 import (
 	"context"
 	"log/slog"
-	"strings"
+	"slices"
+	"time"
 
 	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/Shikachuu/wap-bot/internal/storage"
 	"github.com/Shikachuu/wap-bot/internal/telemetry"
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
 	"github.com/slack-go/slack/socketmode"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// threadRepliesPageSize is the number of replies fetched in one request, the maximum Slack allows.
+const threadRepliesPageSize = 1000
+
 // SlackBot is the main communication layer of the application,
 // contains and handles socket connections and sync Slack API calls.
+//
+// The fields are set once by NewSlackBot and only read afterwards, the events are handled concurrently,
+// so every mutable state lives behind its own lock: the store, the provider health and the summary guard.
 type SlackBot struct {
 	slackMessageProcessor domain.MessageProcessorDomain
 	socketClient          *socketmode.Client
+	prober                *musicextractors.Prober
+	// health is optional, when set the probe results are recorded in it.
+	health  *musicextractors.ProviderHealth
+	metrics *telemetry.Metrics
+	store   storage.Store
+	// archive is optional, when set closed threads are archived to it.
+	archive storage.ArchiveSink
+	// playlists are the playlist creators of the providers with an account configured, the first one is the default.
+	playlists []musicextractors.PlaylistCreator
+	// admins are the IDs of the users allowed to use the admin commands.
+	admins []string
+	// silentChannels are the channels whose summaries are silent by default, see summaryOptions.silent.
+	silentChannels []string
+	// incremental summarizes a thread summarized before from the tracks of its last summary
+	// and the messages posted since, see summaryOptions.full.
+	incremental bool
+	// replacePrevious deletes the file and the messages of the last summary of a thread when it's summarized again.
+	replacePrevious bool
+	// progressThreshold is the number of messages above which a summary posts a progress message, 0 disables it.
+	progressThreshold int
+	// events runs the event handlers, summaries keeps the same thread from being summarized twice at the same time,
+	// limits caps the summaries a user or a channel runs at the same time.
+	events    *eventDispatcher
+	summaries *threadGuard
+	limits    *summaryLimiter
 }
 
 // HandleEvents is the main event loop that listens to Slack Socket Events and handles them based on the event's Type field.
+//
+// The events are handled concurrently by the event dispatcher, HandleEvents waits for the running handlers before returning.
 func (bot *SlackBot) HandleEvents(bCtx context.Context) {
+	defer func() {
+		slog.InfoContext(bCtx, "waiting for event handlers", "running", bot.events.running())
+		bot.events.wait()
+	}()
+
 	for {
 		select {
 		case <-bCtx.Done():
@@ -32,32 +75,43 @@ func (bot *SlackBot) HandleEvents(bCtx context.Context) {
 				return
 			}
 
-			ctx, t := telemetry.Tracer.Start(bCtx, "slackbot.handle_events")
-			t.SetAttributes(
-				attribute.String("event.type", string(evt.Type)),
-			)
-
-			logger := slog.With("event_type", evt.Type)
-			switch evt.Type {
-			case socketmode.EventTypeConnecting:
-				logger.DebugContext(ctx, "connection to slack socket")
-			case socketmode.EventTypeConnectionError:
-				logger.WarnContext(ctx, "socket connection failed")
-			case socketmode.EventTypeConnected:
-				logger.InfoContext(ctx, "connected to slack socket")
-			case socketmode.EventTypeHello:
-				logger.DebugContext(ctx, "greeting message received from slack connection")
-			case socketmode.EventTypeEventsAPI:
-				bot.handleEventsAPI(ctx, logger, &evt)
-			default:
-				logger.WarnContext(ctx, "not implemented event received")
+			if !bot.events.dispatch(bCtx, func() { bot.handleEvent(bCtx, &evt) }) {
+				return
 			}
-
-			t.End()
 		}
 	}
 }
 
+// handleEvent handles a single socket event based on its Type field.
+func (bot *SlackBot) handleEvent(bCtx context.Context, evt *socketmode.Event) {
+	ctx, t := telemetry.Tracer.Start(bCtx, "slackbot.handle_events")
+	defer t.End()
+
+	t.SetAttributes(
+		attribute.String("event.type", string(evt.Type)),
+	)
+
+	logger := slog.With("event_type", evt.Type)
+	switch evt.Type {
+	case socketmode.EventTypeConnecting:
+		logger.DebugContext(ctx, "connection to slack socket")
+	case socketmode.EventTypeConnectionError:
+		logger.WarnContext(ctx, "socket connection failed")
+	case socketmode.EventTypeConnected:
+		logger.InfoContext(ctx, "connected to slack socket")
+	case socketmode.EventTypeHello:
+		logger.DebugContext(ctx, "greeting message received from slack connection")
+	case socketmode.EventTypeEventsAPI:
+		bot.handleEventsAPI(ctx, logger, evt)
+	case socketmode.EventTypeInteractive:
+		bot.handleInteractive(ctx, logger, evt)
+	case socketmode.EventTypeSlashCommand:
+		bot.handleSlashCommand(ctx, logger, evt)
+	default:
+		logger.WarnContext(ctx, "not implemented event received")
+	}
+}
+
 func (bot *SlackBot) handleEventsAPI(bCtx context.Context, logger *slog.Logger, evt *socketmode.Event) {
 	ctx, t := telemetry.Tracer.Start(bCtx, "slackbot.handle_events_api")
 	defer t.End()
@@ -71,9 +125,11 @@ func (bot *SlackBot) handleEventsAPI(bCtx context.Context, logger *slog.Logger,
 		return
 	}
 
-	telemetry.StartEvent(t, telemetry.SendACKEvent)
-	bot.socketClient.Ack(*evt.Request)
-	telemetry.EndEvent(t, telemetry.SendACKEvent)
+	_ = telemetry.Measure(t, telemetry.SendACKEvent, func() error {
+		bot.socketClient.Ack(*evt.Request)
+
+		return nil
+	})
 
 	if eventsAPIEvent.Type != slackevents.CallbackEvent {
 		t.AddEvent("ignored_non_callback_event")
@@ -82,17 +138,25 @@ func (bot *SlackBot) handleEventsAPI(bCtx context.Context, logger *slog.Logger,
 
 	innerEvent := eventsAPIEvent.InnerEvent
 	switch ev := innerEvent.Data.(type) {
+	case *slackevents.MessageEvent:
+		if err := bot.handleMessage(ctx, ev); err != nil {
+			_ = telemetry.WrapErrorWithTrace(t, "", errHandleEvent)
+
+			logger.ErrorContext(ctx, "failed to handle message", "error", err)
+		}
 	case *slackevents.AppMentionEvent:
-		telemetry.StartEvent(t, telemetry.HandleMentionsEvent)
 		t.SetAttributes(attribute.String("user.id", ev.User), attribute.String("slack.channel_id", ev.Channel))
 
-		if err := bot.handleMentions(ctx, ev); err != nil {
+		err := telemetry.Measure(t, telemetry.HandleMentionsEvent, func() error {
+			return bot.handleMentions(ctx, ev)
+		})
+		bot.recordMentionResult(ctx, err)
+
+		if err != nil {
 			_ = telemetry.WrapErrorWithTrace(t, "", errHandleEvent)
 
 			logger.ErrorContext(ctx, "failed to handle event", "error", err)
 		}
-
-		telemetry.EndEvent(t, telemetry.HandleMentionsEvent)
 	default:
 		_ = telemetry.WrapErrorWithTrace(t, "", errNotImplementedEvent)
 
@@ -104,40 +168,180 @@ func (bot *SlackBot) handleMentions(bCtx context.Context, event *slackevents.App
 	ctx, t := telemetry.Tracer.Start(bCtx, "slackbot.handle_mentions")
 	defer t.End()
 
-	if event.ThreadTimeStamp == "" {
-		telemetry.StartEvent(t, telemetry.NonThreadPostEphemeralEvent)
+	routes := bot.commandRoutes()
 
-		_, err := bot.socketClient.PostEphemeralContext(
-			ctx,
-			event.Channel,
-			event.User,
-			slack.MsgOptionText("Bot is only usable in threads to summarize them", false),
-		)
+	known := make([]commandType, 0, len(routes))
+	for _, r := range routes {
+		known = append(known, r.name)
+	}
 
-		telemetry.EndEvent(t, telemetry.NonThreadPostEphemeralEvent)
+	name, args, err := parseCommand(event.Text, known)
+	if err != nil {
+		// Unknown commands aren't counted, the help tells what the bot understands
+		t.AddEvent("unknown_command")
 
-		if err != nil {
+		if err = bot.handleHelp(ctx, event, err); err != nil {
+			return telemetry.WrapErrorWithTrace(t, "posting help", err) //nolint:wrapcheck // this is a function that wraps the error
+		}
+
+		return nil
+	}
+
+	route := routes[slices.Index(known, name)]
+
+	t.SetAttributes(attribute.String("command", string(name)))
+
+	if route.thread && event.ThreadTimeStamp == "" {
+		if err = bot.notifyThreadOnly(ctx, event); err != nil {
 			return telemetry.WrapErrorWithTrace(t, "unable to post ephemeral notification", err) //nolint:wrapcheck // this is a function that wraps the error
 		}
 
 		return nil
 	}
 
-	switch {
-	case strings.Contains(event.Text, string(CommandSummarize)):
-		err := bot.processThread(ctx, event.Channel, event.ThreadTimeStamp)
-		if err != nil {
-			return telemetry.WrapErrorWithTrace(t, "processing thread", err) //nolint:wrapcheck // this is a function that wraps the error
+	bot.countCommand(ctx, name, event)
+
+	if err = route.handle(ctx, event, args); err != nil {
+		return telemetry.WrapErrorWithTrace(t, route.action, err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	return nil
+}
+
+// handleSummarize summarizes the thread of the mention, or the channel with the channel option.
+func (bot *SlackBot) handleSummarize(bCtx context.Context, event *slackevents.AppMentionEvent, args string) error {
+	ctx, t := telemetry.Tracer.Start(bCtx, "slackbot.handle_summarize")
+	defer t.End()
+
+	if hasChannelOption(args) {
+		if err := bot.handleSummarizeChannel(ctx, event, args); err != nil {
+			return telemetry.WrapErrorWithTrace(t, "summarizing channel", err) //nolint:wrapcheck // this is a function that wraps the error
 		}
 
-	default:
-		return telemetry.WrapErrorWithTrace(t, "parsing command", ErrInvalidCommandType) //nolint:wrapcheck // this is a function that wraps the error
+		return nil
+	}
+
+	if event.ThreadTimeStamp == "" {
+		if err := bot.notifyThreadOnly(ctx, event); err != nil {
+			return telemetry.WrapErrorWithTrace(t, "unable to post ephemeral notification", err) //nolint:wrapcheck // this is a function that wraps the error
+		}
+
+		return nil
+	}
+
+	opts, err := bot.summaryDomainOptions(ctx, args, event.User)
+
+	var window messageWindow
+
+	if err == nil {
+		window, err = windowOption(args, time.Now())
+	}
+
+	if err != nil {
+		if err = bot.notifyInvalidOption(ctx, event, err); err != nil {
+			return telemetry.WrapErrorWithTrace(t, "notifying invalid option", err) //nolint:wrapcheck // this is a function that wraps the error
+		}
+
+		return nil
+	}
+
+	_, err = bot.processThread(ctx, event.Channel, event.ThreadTimeStamp, summaryOptions{
+		SummaryOptions: opts,
+		window:         window,
+		silent:         hasSilentOption(args),
+		full:           hasFullOption(args),
+		user:           event.User,
+	})
+	bot.reactToSummary(ctx, event, err)
+
+	err = bot.notifySummaryRejected(ctx, event.Channel, event.User, event.ThreadTimeStamp, err)
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "processing thread", err) //nolint:wrapcheck // this is a function that wraps the error
 	}
 
 	return nil
 }
 
-func (bot *SlackBot) processThread(bCtx context.Context, channelID, threadTS string) error {
+// countCommand records a handled command, the channel and user IDs are guarded to keep the metric's cardinality bounded.
+func (bot *SlackBot) countCommand(ctx context.Context, cmd commandType, event *slackevents.AppMentionEvent) {
+	bot.metrics.Commands.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("command", string(cmd)),
+		telemetry.GuardedAttribute("slack.channel_id", event.Channel),
+		telemetry.GuardedAttribute("slack.user_id", event.User),
+	))
+
+	bot.recordUsage(ctx, storage.Usage{Commands: 1})
+}
+
+// summaryDomainOptions parses the arguments of the summarize command that are settings of the domain,
+// an explicitly requested format wins over the preference of the user.
+//
+// Returns the options or the error of the first invalid argument.
+func (bot *SlackBot) summaryDomainOptions(ctx context.Context, args, userID string) (domain.SummaryOptions, error) {
+	var (
+		opts domain.SummaryOptions
+		err  error
+	)
+
+	opts.Format, err = formatOption(args)
+
+	if err == nil {
+		opts.CSV, err = csvOption(args)
+	}
+
+	if err == nil {
+		opts.Providers, err = providerOption(args, bot.slackMessageProcessor.EnabledProviders())
+	}
+
+	if err == nil {
+		opts.Order, err = sortOption(args)
+	}
+
+	if err == nil {
+		opts.Group, err = groupOption(args)
+	}
+
+	if err == nil {
+		opts.Dedupe, err = dedupeOption(args)
+	}
+
+	if err == nil {
+		opts.Anonymous, err = anonymousOption(args)
+	}
+
+	if err != nil {
+		return domain.SummaryOptions{}, err
+	}
+
+	if opts.Format == "" {
+		opts.Format = bot.userFormat(ctx, userID)
+	}
+
+	return opts, nil
+}
+
+// summaryOptions are the settings of a single summary, the ones of the domain and how it's posted.
+type summaryOptions struct {
+	domain.SummaryOptions
+	// mention is prepended to the comment of the summary, like the usergroup ping of scheduled digests.
+	mention string
+	// silent uploads the summary without the "Found N music URLs" comment and without the follow-up buttons,
+	// the summaries of the silent channels are always silent.
+	silent bool
+	// window only keeps the messages posted in it, the zero value keeps the whole thread.
+	window messageWindow
+	// full resolves every message of the thread again, even if incremental summaries are enabled.
+	full bool
+	// user is who asked for the summary, the summaries running at the same time are limited per user and per channel.
+	// The scheduled summaries and the ones of the close command have no user and aren't limited.
+	user string
+}
+
+// processThread summarizes a thread and uploads the summary with the given options.
+//
+// Returns the uploaded summary or an error if any, ErrSummaryInProgress if the thread is already being summarized,
+// ErrRateLimited if the user or the channel runs too many summaries.
+func (bot *SlackBot) processThread(bCtx context.Context, channelID, threadTS string, opts summaryOptions) (domain.Summary, error) {
 	ctx, t := telemetry.Tracer.Start(bCtx, "slackbot.process_thread")
 	defer t.End()
 
@@ -146,58 +350,279 @@ func (bot *SlackBot) processThread(bCtx context.Context, channelID, threadTS str
 		attribute.String("slack.thread_ts", threadTS),
 	)
 
+	silent := bot.isSilent(channelID, opts.silent)
+	t.SetAttributes(attribute.Bool("summary.silent", silent))
+
+	release, err := bot.startSummary(ctx, channelID, threadTS, opts.user)
+	if err != nil {
+		return domain.Summary{}, telemetry.WrapErrorWithTrace(t, "starting summary", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	defer release()
+
 	logger := slog.With("channel_id", channelID, "thread_ts", threadTS)
 
 	logger.DebugContext(ctx, "processing thread")
 
-	telemetry.StartEvent(t, telemetry.GetConversationRepliesEvent)
+	start := time.Now()
 
-	msgs, _, _, err := bot.socketClient.GetConversationRepliesContext(
-		ctx,
-		&slack.GetConversationRepliesParameters{
-			ChannelID: channelID,
-			Timestamp: threadTS,
-			Limit:     1000,
-		},
-	)
+	var msgs []slack.Message
 
-	telemetry.EndEvent(t, telemetry.GetConversationRepliesEvent)
+	err = telemetry.Measure(t, telemetry.GetConversationRepliesEvent, func() error {
+		var gErr error
 
+		msgs, gErr = bot.threadReplies(ctx, channelID, threadTS)
+
+		return gErr
+	})
 	if err != nil {
-		return telemetry.WrapErrorWithTrace(t, "get slack thread replies", err) //nolint:wrapcheck // this is a function that wraps the error
+		return domain.Summary{}, telemetry.WrapErrorWithTrace(t, "get slack thread replies", err) //nolint:wrapcheck // this is a function that wraps the error
 	}
 
-	telemetry.StartEvent(t, telemetry.SummarizeThreadEvent)
 	t.SetAttributes(attribute.Int("slack.message_count", len(msgs)))
-	reply, err := bot.slackMessageProcessor.SummarizeThread(msgs, channelID, threadTS)
 
-	telemetry.EndEvent(t, telemetry.SummarizeThreadEvent)
+	if !opts.window.isZero() {
+		msgs = opts.window.filter(msgs)
+		t.SetAttributes(attribute.Int("summary.window_message_count", len(msgs)))
+	}
+
+	var (
+		previous    previousSummary
+		incremental bool
+	)
+
+	// The transcript needs the whole thread, the previous summary wasn't filtered and its stored tracks have no genres,
+	// so they are never incremental
+	if bot.incremental && !opts.full && opts.Format != domain.ExportFormatTranscript && opts.Providers.IsZero() && opts.window.isZero() &&
+		opts.Group == "" {
+		err = telemetry.Measure(t, telemetry.GetPreviousSummaryEvent, func() error {
+			var pErr error
 
+			previous, incremental, pErr = bot.previousSummary(ctx, channelID, threadTS)
+
+			return pErr
+		})
+		if err != nil {
+			_ = telemetry.WrapErrorWithTrace(t, "getting previous summary", err)
+
+			logger.WarnContext(ctx, "failed to get the previous summary, summarizing the whole thread", "error", err)
+		}
+	}
+
+	t.SetAttributes(attribute.Bool("summary.incremental", incremental))
+
+	pending := msgs
+	if incremental {
+		pending = messagesSince(msgs, previous.lastMessageTS)
+	}
+
+	progress, stopProgress := bot.trackProgress(ctx, channelID, threadTS, len(pending), silent)
+	defer stopProgress()
+
+	opts.Progress = progress
+
+	var summary domain.Summary
+
+	err = telemetry.Measure(t, telemetry.SummarizeThreadEvent, func() error {
+		var sErr error
+
+		switch {
+		case incremental:
+			summary, sErr = bot.slackMessageProcessor.SummarizeThreadSince(
+				ctx, pending, channelID, threadTS, previous.tracks, previous.failed, opts.SummaryOptions,
+			)
+		case opts.SummaryOptions.IsZero():
+			summary, sErr = bot.slackMessageProcessor.SummarizeThread(ctx, msgs, channelID, threadTS)
+		default:
+			summary, sErr = bot.slackMessageProcessor.SummarizeThreadAs(ctx, msgs, channelID, threadTS, opts.SummaryOptions)
+		}
+
+		return sErr //nolint:wrapcheck // wrapped with the trace below
+	})
 	if err != nil {
-		return telemetry.WrapErrorWithTrace(t, "summarizing thread", err) //nolint:wrapcheck // this is a function that wraps the error
+		return domain.Summary{}, telemetry.WrapErrorWithTrace(t, "summarizing thread", err) //nolint:wrapcheck // this is a function that wraps the error
 	}
 
-	t.SetAttributes(attribute.Int("file.size", reply.FileSize), attribute.String("file.name", reply.Filename))
+	t.SetAttributes(attribute.Int("file.size", summary.Upload.FileSize), attribute.String("file.name", summary.Upload.Filename))
 
-	telemetry.StartEvent(t, telemetry.UploadFileV2Event)
+	summary.Upload.InitialComment = summaryComment(summary.Upload.InitialComment, opts.mention, silent)
 
-	_, err = bot.socketClient.UploadFileV2(reply)
+	post, err := bot.postSummary(ctx, summary)
+	if err != nil {
+		return domain.Summary{}, telemetry.WrapErrorWithTrace(t, "posting summary", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
 
-	telemetry.EndEvent(t, telemetry.UploadFileV2Event)
+	bot.recordUsage(ctx, storage.Usage{Summaries: 1, SummarizeTime: time.Since(start)})
 
+	// The index only powers the find command, the summary itself is already posted
+	err = telemetry.Measure(t, telemetry.IndexTracksEvent, func() error {
+		return bot.indexTracks(ctx, channelID, threadTS, summary.Tracks)
+	})
 	if err != nil {
-		return telemetry.WrapErrorWithTrace(t, "uploading file to reply", err) //nolint:wrapcheck // this is a function that wraps the error
+		_ = telemetry.WrapErrorWithTrace(t, "indexing tracks", err)
+
+		logger.WarnContext(ctx, "failed to index tracks", "error", err)
 	}
 
-	logger.InfoContext(ctx, "summarized thread")
+	// The report only powers the retry-titles command, the summary itself is already posted
+	if err = bot.saveFailureReport(ctx, channelID, threadTS, summary.FailedTitles); err != nil {
+		_ = telemetry.WrapErrorWithTrace(t, "saving failure report", err)
 
-	return nil
+		logger.WarnContext(ctx, "failed to save failure report", "error", err)
+	}
+
+	if !silent {
+		// The buttons are only a shortcut, the summary itself is already posted
+		err = telemetry.Measure(t, telemetry.PostSummaryActionsEvent, func() error {
+			ts, aErr := bot.postSummaryActions(ctx, channelID, threadTS)
+			if aErr != nil {
+				return aErr
+			}
+
+			post.messageTSs = append(post.messageTSs, ts)
+
+			return nil
+		})
+		if err != nil {
+			_ = telemetry.WrapErrorWithTrace(t, "posting summary actions", err)
+
+			logger.WarnContext(ctx, "failed to post summary actions", "error", err)
+		}
+	}
+
+	// The last summary only powers the incremental and the replaced summaries, the summary itself is already posted
+	err = telemetry.Measure(t, telemetry.SaveThreadSummaryEvent, func() error {
+		return bot.saveThreadSummary(ctx, channelID, threadTS, lastMessageTS(msgs, threadTS), post)
+	})
+	if err != nil {
+		_ = telemetry.WrapErrorWithTrace(t, "saving thread summary", err)
+
+		logger.WarnContext(ctx, "failed to save the last summary", "error", err)
+	}
+
+	logFailures(ctx, logger, summary.Failures)
+
+	if silent {
+		logger.InfoContext(ctx, "summarized thread silently", "tracks", len(summary.Tracks), "skipped", len(summary.Failures))
+
+		return summary, nil
+	}
+
+	logger.InfoContext(ctx, "summarized thread", "tracks", len(summary.Tracks), "skipped", len(summary.Failures))
+
+	return summary, nil
+}
+
+// threadReplies returns the messages of a thread, starting with its parent message.
+//
+// The replies are fetched page by page following the cursor until the whole thread is read,
+// every page adds an event with the number of messages read so far to the span of ctx.
+func (bot *SlackBot) threadReplies(ctx context.Context, channelID, threadTS string) ([]slack.Message, error) {
+	t := trace.SpanFromContext(ctx)
+
+	var msgs []slack.Message
+
+	cursor := ""
+
+	for page := 1; ; page++ {
+		replies, hasMore, next, err := bot.socketClient.GetConversationRepliesContext(
+			ctx,
+			&slack.GetConversationRepliesParameters{
+				ChannelID: channelID,
+				Timestamp: threadTS,
+				Cursor:    cursor,
+				Limit:     threadRepliesPageSize,
+			},
+		)
+		if err != nil {
+			return nil, err //nolint:wrapcheck // wrapped with the trace by the caller
+		}
+
+		// Every page starts with the parent message, only the first one keeps it
+		if page > 1 && len(replies) > 0 && replies[0].Timestamp == threadTS {
+			replies = replies[1:]
+		}
+
+		msgs = append(msgs, replies...)
+
+		t.AddEvent(telemetry.GetConversationRepliesPageEvent, trace.WithAttributes(
+			attribute.Int("page", page),
+			attribute.Int("slack.message_count", len(msgs)),
+		))
+
+		if !hasMore || next == "" {
+			return msgs, nil
+		}
+
+		cursor = next
+	}
+}
+
+// BotConfig configures a SlackBot.
+type BotConfig struct {
+	// Processor extracts the tracks of the threads and builds their summaries.
+	Processor domain.MessageProcessorDomain
+	// Client is the Socket Mode client receiving the events and calling the Slack API.
+	Client *socketmode.Client
+	// Prober checks the enabled providers for the providers command.
+	Prober *musicextractors.Prober
+	// Health is optional, when set the probe results are recorded in it.
+	Health  *musicextractors.ProviderHealth
+	Metrics *telemetry.Metrics
+	// Store persists the per-user preferences and the index of the summarized tracks.
+	Store storage.Store
+	// Archive is optional, when set it receives the data of closed threads.
+	Archive storage.ArchiveSink
+	// Playlists create the playlists of the playlist command, the first one is the default.
+	Playlists []musicextractors.PlaylistCreator
+	// Admins are the IDs of the users allowed to use the admin commands.
+	Admins []string
+	// SilentChannels are the channels whose summaries are silent by default.
+	SilentChannels []string
+	// Incremental only resolves the messages posted since the last summary of a thread.
+	Incremental bool
+	// ReplacePrevious deletes the last summary of a thread when it's summarized again.
+	ReplacePrevious bool
+	// ProgressThreshold is the number of messages above which a summary posts a progress message, 0 disables it.
+	ProgressThreshold int
+	// UserSummaryLimit and ChannelSummaryLimit are the number of summaries a user and a channel run at the same time.
+	UserSummaryLimit    int
+	ChannelSummaryLimit int
+	// EventConcurrency is the number of events handled at the same time.
+	EventConcurrency int
 }
 
-// NewSlackBot creates a new slack bot with the given message processor and socket client.
-func NewSlackBot(smp domain.MessageProcessorDomain, sc *socketmode.Client) *SlackBot {
+// NewSlackBot creates a new slack bot from the given config.
+func NewSlackBot(cfg BotConfig) *SlackBot {
 	return &SlackBot{
-		slackMessageProcessor: smp,
-		socketClient:          sc,
+		slackMessageProcessor: cfg.Processor,
+		socketClient:          cfg.Client,
+		prober:                cfg.Prober,
+		health:                cfg.Health,
+		metrics:               cfg.Metrics,
+		store:                 cfg.Store,
+		archive:               cfg.Archive,
+		playlists:             cfg.Playlists,
+		admins:                cfg.Admins,
+		silentChannels:        cfg.SilentChannels,
+		incremental:           cfg.Incremental,
+		replacePrevious:       cfg.ReplacePrevious,
+		progressThreshold:     cfg.ProgressThreshold,
+		events:                newEventDispatcher(cfg.EventConcurrency),
+		summaries:             newThreadGuard(),
+		limits:                newSummaryLimiter(cfg.UserSummaryLimit, cfg.ChannelSummaryLimit),
 	}
 }
+
+// notifySummaryInProgress tells the user that the thread is already being summarized.
+func (bot *SlackBot) notifySummaryInProgress(ctx context.Context, channelID, userID, threadTS string) error {
+	_, err := bot.socketClient.PostEphemeralContext(
+		ctx,
+		channelID,
+		userID,
+		slack.MsgOptionText("This thread is already being summarized, the summary will show up shortly", false),
+		slack.MsgOptionTS(threadTS),
+	)
+
+	return err //nolint:wrapcheck // wrapped with the trace by the caller
+}
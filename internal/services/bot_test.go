@@ -0,0 +1,71 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/socketmode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThreadReplies_FollowsCursor(t *testing.T) {
+	t.Parallel()
+
+	const threadTS = "1700000000.000100"
+
+	// Three pages of two replies, every page starts with the parent message like Slack does
+	pages := map[string]struct {
+		next    string
+		replies []string
+	}{
+		"":   {next: "p2", replies: []string{"1700000000.000200", "1700000000.000300"}},
+		"p2": {next: "p3", replies: []string{"1700000000.000400", "1700000000.000500"}},
+		"p3": {replies: []string{"1700000000.000600"}},
+	}
+
+	var cursors []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/conversations.replies", r.URL.Path)
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, strconv.Itoa(threadRepliesPageSize), r.Form.Get("limit"))
+
+		cursor := r.Form.Get("cursor")
+		cursors = append(cursors, cursor)
+		page := pages[cursor]
+
+		msgs := []map[string]string{{"ts": threadTS, "thread_ts": threadTS}}
+		for _, ts := range page.replies {
+			msgs = append(msgs, map[string]string{"ts": ts, "thread_ts": threadTS})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(map[string]any{
+			"ok":                true,
+			"messages":          msgs,
+			"has_more":          page.next != "",
+			"response_metadata": map[string]string{"next_cursor": page.next},
+		}))
+	}))
+	t.Cleanup(srv.Close)
+
+	bot := &SlackBot{socketClient: socketmode.New(slack.New("xoxb-test", slack.OptionAPIURL(srv.URL+"/")))}
+
+	msgs, err := bot.threadReplies(t.Context(), "C123", threadTS)
+	require.NoError(t, err)
+
+	timestamps := make([]string, 0, len(msgs))
+	for _, m := range msgs {
+		timestamps = append(timestamps, m.Timestamp)
+	}
+
+	assert.Equal(t, []string{"", "p2", "p3"}, cursors)
+	assert.Equal(t, []string{
+		threadTS, "1700000000.000200", "1700000000.000300", "1700000000.000400", "1700000000.000500", "1700000000.000600",
+	}, timestamps)
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/Shikachuu/wap-bot/internal/storage"
+	"github.com/Shikachuu/wap-bot/internal/telemetry"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// channelHistoryPageSize is the number of messages of the channel history fetched in one request, the maximum Slack allows.
+const channelHistoryPageSize = 999
+
+// optionChannel is the summarize and leaderboard command option that reads the whole channel instead of the thread.
+const optionChannel = "channel"
+
+// optionLast is the summarize channel option that sets how far back the history of the channel is read, like last=30d.
+const optionLast = "last"
+
+// defaultChannelWindow is how far back a channel summary reads without the last option.
+const defaultChannelWindow = 30 * 24 * time.Hour
+
+// hasChannelOption reports if the arguments of a command ask for the whole channel instead of the thread.
+func hasChannelOption(args string) bool {
+	return parseArgs(args).flag(optionChannel)
+}
+
+// lastOption returns the oldest time of a channel summary requested by the last=<duration> argument, counted back from now,
+// defaultChannelWindow if there's none.
+func lastOption(args string, now time.Time) (time.Time, error) {
+	value, ok := parseArgs(args).option(optionLast)
+	if !ok {
+		return now.Add(-defaultChannelWindow).UTC(), nil
+	}
+
+	ago, ok := parseRelative(strings.ToLower(value))
+	if !ok {
+		return time.Time{}, fmt.Errorf("%w: %s=%s is not a relative time like 30d", errInvalidWindowOption, optionLast, value)
+	}
+
+	return now.Add(-ago).UTC(), nil
+}
+
+// slackTimestamp converts t to a Slack message timestamp like 1700000000.000000.
+func slackTimestamp(t time.Time) string {
+	return strconv.FormatInt(t.Unix(), 10) + ".000000"
+}
+
+// channelHistory returns the top-level messages of a channel posted since oldest, oldest first,
+// the replies of the threads aren't part of it.
+//
+// The history is fetched page by page following the cursor until the oldest message is read,
+// every page adds an event with the number of messages read so far to the span of ctx.
+func (bot *SlackBot) channelHistory(ctx context.Context, channelID string, oldest time.Time) ([]slack.Message, error) {
+	t := trace.SpanFromContext(ctx)
+
+	var msgs []slack.Message
+
+	cursor := ""
+
+	for page := 1; ; page++ {
+		history, err := bot.socketClient.GetConversationHistoryContext(
+			ctx,
+			&slack.GetConversationHistoryParameters{
+				ChannelID: channelID,
+				Cursor:    cursor,
+				Oldest:    slackTimestamp(oldest),
+				Limit:     channelHistoryPageSize,
+			},
+		)
+		if err != nil {
+			return nil, err //nolint:wrapcheck // wrapped with the trace by the caller
+		}
+
+		msgs = append(msgs, history.Messages...)
+
+		t.AddEvent(telemetry.GetConversationHistoryPageEvent, trace.WithAttributes(
+			attribute.Int("page", page),
+			attribute.Int("slack.message_count", len(msgs)),
+		))
+
+		if !history.HasMore || history.ResponseMetaData.NextCursor == "" {
+			break
+		}
+
+		cursor = history.ResponseMetaData.NextCursor
+	}
+
+	// The history is newest first, the summaries follow the order the messages were posted in
+	slices.Reverse(msgs)
+
+	return msgs, nil
+}
+
+// processChannel summarizes the top-level messages of a channel posted since oldest and uploads the summary
+// to the thread of threadTS, or to the channel if it's empty.
+//
+// Returns the uploaded summary or an error if any, ErrSummaryInProgress if the channel is already being summarized,
+// ErrRateLimited if the user or the channel runs too many summaries.
+func (bot *SlackBot) processChannel(
+	bCtx context.Context,
+	channelID, threadTS string,
+	oldest time.Time,
+	opts summaryOptions,
+) (domain.Summary, error) {
+	ctx, t := telemetry.Tracer.Start(bCtx, "slackbot.process_channel")
+	defer t.End()
+
+	t.SetAttributes(
+		attribute.String("slack.channel_id", channelID),
+		attribute.String("slack.thread_ts", threadTS),
+	)
+
+	silent := bot.isSilent(channelID, opts.silent)
+	t.SetAttributes(attribute.Bool("summary.silent", silent))
+
+	// The summaries of the channel have no thread of their own
+	release, err := bot.startSummary(ctx, channelID, "", opts.user)
+	if err != nil {
+		return domain.Summary{}, telemetry.WrapErrorWithTrace(t, "starting summary", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	defer release()
+
+	logger := slog.With("channel_id", channelID)
+
+	logger.DebugContext(ctx, "processing channel", "oldest", oldest)
+
+	start := time.Now()
+
+	var msgs []slack.Message
+
+	err = telemetry.Measure(t, telemetry.GetConversationHistoryEvent, func() error {
+		var hErr error
+
+		msgs, hErr = bot.channelHistory(ctx, channelID, oldest)
+
+		return hErr
+	})
+	if err != nil {
+		return domain.Summary{}, telemetry.WrapErrorWithTrace(t, "get slack channel history", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	t.SetAttributes(attribute.Int("slack.message_count", len(msgs)))
+
+	progress, stopProgress := bot.trackProgress(ctx, channelID, threadTS, len(msgs), silent)
+	defer stopProgress()
+
+	opts.Progress = progress
+
+	var summary domain.Summary
+
+	err = telemetry.Measure(t, telemetry.SummarizeChannelEvent, func() error {
+		var sErr error
+
+		summary, sErr = bot.slackMessageProcessor.SummarizeChannel(ctx, msgs, channelID, opts.SummaryOptions)
+
+		return sErr //nolint:wrapcheck // wrapped with the trace below
+	})
+	if err != nil {
+		return domain.Summary{}, telemetry.WrapErrorWithTrace(t, "summarizing channel", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	summary.Upload.ThreadTimestamp = threadTS
+	summary.Upload.InitialComment = summaryComment(summary.Upload.InitialComment, opts.mention, silent)
+
+	if _, err = bot.postSummary(ctx, summary); err != nil {
+		return domain.Summary{}, telemetry.WrapErrorWithTrace(t, "posting summary", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	bot.recordUsage(ctx, storage.Usage{Summaries: 1, SummarizeTime: time.Since(start)})
+
+	// The index only powers the find and the leaderboard commands, the summary itself is already posted,
+	// the top-level tracks have no thread
+	err = telemetry.Measure(t, telemetry.IndexTracksEvent, func() error {
+		return bot.indexTracks(ctx, channelID, "", summary.Tracks)
+	})
+	if err != nil {
+		_ = telemetry.WrapErrorWithTrace(t, "indexing tracks", err)
+
+		logger.WarnContext(ctx, "failed to index tracks", "error", err)
+	}
+
+	logFailures(ctx, logger, summary.Failures)
+	logger.InfoContext(ctx, "summarized channel", "messages", len(msgs), "tracks", len(summary.Tracks), "skipped", len(summary.Failures))
+
+	return summary, nil
+}
+
+// handleSummarizeChannel summarizes the top-level messages of the channel posted in the window of the last option,
+// the summary is posted to the thread of the mention, or to the channel outside a thread.
+func (bot *SlackBot) handleSummarizeChannel(bCtx context.Context, event *slackevents.AppMentionEvent, args string) error {
+	ctx, t := telemetry.Tracer.Start(bCtx, "slackbot.handle_summarize_channel")
+	defer t.End()
+
+	opts, err := bot.summaryDomainOptions(ctx, args, event.User)
+
+	var oldest time.Time
+
+	if err == nil {
+		oldest, err = lastOption(args, time.Now())
+	}
+
+	if err != nil {
+		if err = bot.notifyInvalidOption(ctx, event, err); err != nil {
+			return telemetry.WrapErrorWithTrace(t, "notifying invalid option", err) //nolint:wrapcheck // this is a function that wraps the error
+		}
+
+		return nil
+	}
+
+	_, err = bot.processChannel(ctx, event.Channel, event.ThreadTimeStamp, oldest, summaryOptions{
+		SummaryOptions: opts,
+		silent:         hasSilentOption(args),
+		user:           event.User,
+	})
+	bot.reactToSummary(ctx, event, err)
+
+	err = bot.notifySummaryRejected(ctx, event.Channel, event.User, event.ThreadTimeStamp, err)
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "processing channel", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	return nil
+}
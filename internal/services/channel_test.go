@@ -0,0 +1,87 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/socketmode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLastOption(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	got, err := lastOption("channel format=csv", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, -30), got)
+
+	got, err = lastOption("channel LAST=2W", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, -14), got)
+
+	_, err = lastOption("channel last=2026-03-01", now)
+	require.ErrorIs(t, err, errInvalidWindowOption)
+}
+
+func TestChannelHistory_FollowsCursor(t *testing.T) {
+	t.Parallel()
+
+	oldest := time.Unix(1700000000, 0)
+
+	// Two pages of the history, newest message first like Slack returns them
+	pages := map[string]struct {
+		next     string
+		messages []string
+	}{
+		"":   {next: "p2", messages: []string{"1700000400.000100", "1700000300.000100"}},
+		"p2": {messages: []string{"1700000200.000100", "1700000100.000100"}},
+	}
+
+	var cursors []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/conversations.history", r.URL.Path)
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, strconv.Itoa(channelHistoryPageSize), r.Form.Get("limit"))
+		assert.Equal(t, "1700000000.000000", r.Form.Get("oldest"))
+
+		cursor := r.Form.Get("cursor")
+		cursors = append(cursors, cursor)
+		page := pages[cursor]
+
+		msgs := []map[string]string{}
+		for _, ts := range page.messages {
+			msgs = append(msgs, map[string]string{"ts": ts})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(map[string]any{
+			"ok":                true,
+			"messages":          msgs,
+			"has_more":          page.next != "",
+			"response_metadata": map[string]string{"next_cursor": page.next},
+		}))
+	}))
+	t.Cleanup(srv.Close)
+
+	bot := &SlackBot{socketClient: socketmode.New(slack.New("xoxb-test", slack.OptionAPIURL(srv.URL+"/")))}
+
+	msgs, err := bot.channelHistory(t.Context(), "C123", oldest)
+	require.NoError(t, err)
+
+	timestamps := make([]string, 0, len(msgs))
+	for _, m := range msgs {
+		timestamps = append(timestamps, m.Timestamp)
+	}
+
+	assert.Equal(t, []string{"", "p2"}, cursors)
+	assert.Equal(t, []string{"1700000100.000100", "1700000200.000100", "1700000300.000100", "1700000400.000100"}, timestamps)
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/Shikachuu/wap-bot/internal/storage"
+	"github.com/Shikachuu/wap-bot/internal/telemetry"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// closedThreadReply is the reply to links shared in a closed thread, newThreadLink is empty if there is no newer thread.
+func closedThreadReply(newThreadLink string) string {
+	if newThreadLink == "" {
+		return "This thread is closed, it won't be summarized again. Please share new music in a new thread!"
+	}
+
+	return fmt.Sprintf("This thread is closed, it won't be summarized again. Please share new music in <%s|the new one>!", newThreadLink)
+}
+
+// handleClose posts the final summary of the thread of the mention, archives its tracks and marks it as closed.
+// With anonymous=true both the final summary and the archive leave out who shared the tracks and when.
+func (bot *SlackBot) handleClose(bCtx context.Context, event *slackevents.AppMentionEvent, args string) error {
+	ctx, t := telemetry.Tracer.Start(bCtx, "slackbot.handle_close")
+	defer t.End()
+
+	t.SetAttributes(attribute.String("slack.thread_ts", event.ThreadTimeStamp))
+
+	anonymous, err := anonymousOption(args)
+	if err != nil {
+		if err = bot.notifyInvalidOption(ctx, event, err); err != nil {
+			return telemetry.WrapErrorWithTrace(t, "notifying invalid option", err) //nolint:wrapcheck // this is a function that wraps the error
+		}
+
+		return nil
+	}
+
+	summary, err := bot.processThread(ctx, event.Channel, event.ThreadTimeStamp, summaryOptions{
+		SummaryOptions: domain.SummaryOptions{Format: bot.userFormat(ctx, event.User), Anonymous: anonymous},
+	})
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "posting final summary", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	closedAt := time.Now().UTC()
+
+	if bot.archive != nil {
+		thread := storage.ArchivedThread{
+			ClosedAt:  closedAt,
+			ChannelID: event.Channel,
+			ThreadTS:  event.ThreadTimeStamp,
+			ClosedBy:  event.User,
+			Tracks:    indexedTracks(event.ThreadTimeStamp, summary.Tracks),
+		}
+		if anonymous {
+			thread = anonymousThread(thread)
+		}
+
+		err = telemetry.Measure(t, telemetry.ArchiveThreadEvent, func() error {
+			return bot.archive.Archive(ctx, thread) //nolint:wrapcheck // wrapped with the trace below
+		})
+		if err != nil {
+			return telemetry.WrapErrorWithTrace(t, "archiving thread", err) //nolint:wrapcheck // this is a function that wraps the error
+		}
+	}
+
+	err = bot.store.CloseThread(ctx, event.Channel, event.ThreadTimeStamp, storage.ClosedThread{ClosedAt: closedAt, ClosedBy: event.User})
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "marking thread as closed", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	_, _, err = bot.socketClient.PostMessageContext(
+		ctx,
+		event.Channel,
+		slack.MsgOptionText(fmt.Sprintf("<@%s> closed this thread, thanks for sharing!", event.User), false),
+		slack.MsgOptionTS(event.ThreadTimeStamp),
+	)
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "posting close notice", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	return nil
+}
+
+// handleMessage points links shared in a closed thread to the newer thread of the channel, every other message is ignored.
+func (bot *SlackBot) handleMessage(bCtx context.Context, event *slackevents.MessageEvent) error {
+	// Only plain user replies matter, not the parent message, bot messages or edits
+	if event.ThreadTimeStamp == "" || event.ThreadTimeStamp == event.TimeStamp || event.BotID != "" || event.SubType != "" {
+		return nil
+	}
+
+	if !bot.slackMessageProcessor.ContainsMusicURL(bCtx, event.Channel, event.Text) {
+		return nil
+	}
+
+	ctx, t := telemetry.Tracer.Start(bCtx, "slackbot.handle_message")
+	defer t.End()
+
+	_, closed, err := bot.store.ClosedThread(ctx, event.Channel, event.ThreadTimeStamp)
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "reading closed thread", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	if !closed {
+		return nil
+	}
+
+	_, err = bot.socketClient.PostEphemeralContext(
+		ctx,
+		event.Channel,
+		event.User,
+		slack.MsgOptionText(closedThreadReply(bot.newThreadLink(ctx, event.Channel, event.ThreadTimeStamp)), false),
+		slack.MsgOptionTS(event.ThreadTimeStamp),
+	)
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "posting closed thread notice", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	return nil
+}
+
+// newThreadLink returns the link of the current scheduled thread of a channel, if it's not the closed thread itself.
+//
+// The link is best-effort, an empty link only leaves the pointer out of the reply.
+func (bot *SlackBot) newThreadLink(ctx context.Context, channelID, closedTS string) string {
+	scheduled, err := bot.store.ScheduledThread(ctx, channelID)
+	if err != nil || scheduled.ThreadTS == "" || scheduled.ThreadTS == closedTS {
+		return ""
+	}
+
+	link, err := bot.socketClient.GetPermalinkContext(ctx, &slack.PermalinkParameters{Channel: channelID, Ts: scheduled.ThreadTS})
+	if err != nil {
+		slog.WarnContext(ctx, "failed to get new thread permalink", "error", err, "thread_ts", scheduled.ThreadTS)
+		return ""
+	}
+
+	return link
+}
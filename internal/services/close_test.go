@@ -0,0 +1,71 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/Shikachuu/wap-bot/internal/storage"
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMessageProcessor() domain.MessageProcessorDomain {
+	return domain.NewSlackMessageProcessor(domain.ProcessorConfig{
+		URLExtractors: map[musicextractors.ExtractProvider]musicextractors.MusicURLExtractorFunc{
+			musicextractors.YouTubeProvider: musicextractors.YouTubeURLExtractor,
+		},
+		Format: domain.ExportFormatCSV,
+	})
+}
+
+func TestClosedThreadReply(t *testing.T) {
+	t.Parallel()
+
+	assert.NotContains(t, closedThreadReply(""), "<")
+	assert.Contains(t, closedThreadReply("https://example.slack.com/archives/C123/p1700000000000100"),
+		"<https://example.slack.com/archives/C123/p1700000000000100|the new one>")
+}
+
+func TestSlackBot_HandleMessage_IgnoresUnrelatedMessages(t *testing.T) {
+	t.Parallel()
+
+	store, err := storage.NewFileStore("")
+	require.NoError(t, err)
+
+	// Without a Slack client any reply attempt would panic
+	bot := &SlackBot{store: store, slackMessageProcessor: newTestMessageProcessor()}
+
+	tests := []struct {
+		event *slackevents.MessageEvent
+		name  string
+	}{
+		{
+			name:  "not in a thread",
+			event: &slackevents.MessageEvent{Channel: "C123", TimeStamp: "1700000001.000100", Text: "https://youtu.be/dQw4w9WgXcQ"},
+		},
+		{
+			name: "bot message",
+			event: &slackevents.MessageEvent{
+				Channel: "C123", ThreadTimeStamp: "1700000000.000100", TimeStamp: "1700000001.000100",
+				BotID: "B123", Text: "https://youtu.be/dQw4w9WgXcQ",
+			},
+		},
+		{
+			name: "thread is open",
+			event: &slackevents.MessageEvent{
+				Channel: "C123", ThreadTimeStamp: "1700000000.000100", TimeStamp: "1700000001.000100",
+				Text: "https://youtu.be/dQw4w9WgXcQ",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.NoError(t, bot.handleMessage(t.Context(), tt.event))
+		})
+	}
+}
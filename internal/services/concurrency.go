@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// eventDispatcher runs the event handlers in the background, at most limit of them at the same time,
+// so a slow summary doesn't hold up every other event.
+type eventDispatcher struct {
+	slots    chan struct{}
+	wg       sync.WaitGroup
+	inFlight atomic.Int64
+}
+
+// newEventDispatcher creates a dispatcher running at most limit handlers at the same time, at least one.
+func newEventDispatcher(limit int) *eventDispatcher {
+	return &eventDispatcher{slots: make(chan struct{}, max(1, limit))}
+}
+
+// dispatch runs handle in the background as soon as a slot is free.
+//
+// Returns false without running handle if ctx got canceled while waiting for a slot.
+func (d *eventDispatcher) dispatch(ctx context.Context, handle func()) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case d.slots <- struct{}{}:
+	}
+
+	d.inFlight.Add(1)
+
+	d.wg.Go(func() {
+		defer func() {
+			d.inFlight.Add(-1)
+			<-d.slots
+		}()
+
+		handle()
+	})
+
+	return true
+}
+
+// running returns the number of handlers running right now.
+func (d *eventDispatcher) running() int64 {
+	return d.inFlight.Load()
+}
+
+// wait blocks until every dispatched handler returned.
+func (d *eventDispatcher) wait() {
+	d.wg.Wait()
+}
+
+// threadGuard tracks the threads being summarized, so the same thread isn't summarized twice at the same time,
+// like when the summarize command and the re-run button race each other.
+type threadGuard struct {
+	active map[string]struct{}
+	mu     sync.Mutex
+}
+
+// newThreadGuard creates a guard without active threads.
+func newThreadGuard() *threadGuard {
+	return &threadGuard{active: map[string]struct{}{}}
+}
+
+// acquire marks the thread of the channel as active.
+//
+// Returns the function that releases the thread and true, or false if the thread is already active.
+func (g *threadGuard) acquire(channelID, threadTS string) (func(), bool) {
+	key := channelID + "/" + threadTS
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, ok := g.active[key]; ok {
+		return nil, false
+	}
+
+	g.active[key] = struct{}{}
+
+	return func() {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+
+		delete(g.active, key)
+	}, true
+}
+
+// limitScope is what a summary limit applies to.
+type limitScope string
+
+const (
+	limitUser    limitScope = "user"
+	limitChannel limitScope = "channel"
+)
+
+// RateLimitError returned by processThread and processChannel if the user or the channel already runs as many summaries
+// as its limit allows, it wraps ErrRateLimited.
+type RateLimitError struct {
+	// Scope is the limit that was reached, user or channel.
+	Scope limitScope
+	// Limit is the number of summaries allowed at the same time.
+	Limit int
+}
+
+// Error implements the error interface.
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s limit of %d summaries at the same time reached", e.Scope, e.Limit)
+}
+
+// Unwrap returns ErrRateLimited.
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// summaryLimiter caps the summaries running at the same time per user and per channel, so a single user can't start
+// dozens of them. A limit below 1 disables it.
+type summaryLimiter struct {
+	users      map[string]int
+	channels   map[string]int
+	perUser    int
+	perChannel int
+	mu         sync.Mutex
+}
+
+// newSummaryLimiter creates a limiter without running summaries.
+func newSummaryLimiter(perUser, perChannel int) *summaryLimiter {
+	return &summaryLimiter{users: map[string]int{}, channels: map[string]int{}, perUser: perUser, perChannel: perChannel}
+}
+
+// acquire counts a summary of the user in the channel as running.
+//
+// Returns the function that releases it, or a RateLimitError if the user or the channel is at its limit.
+func (l *summaryLimiter) acquire(userID, channelID string) (func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.perUser > 0 && l.users[userID] >= l.perUser {
+		return nil, &RateLimitError{Scope: limitUser, Limit: l.perUser}
+	}
+
+	if l.perChannel > 0 && l.channels[channelID] >= l.perChannel {
+		return nil, &RateLimitError{Scope: limitChannel, Limit: l.perChannel}
+	}
+
+	l.users[userID]++
+	l.channels[channelID]++
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+
+			release(l.users, userID)
+			release(l.channels, channelID)
+		})
+	}, nil
+}
+
+// release decrements the running summaries of key, dropping it once there are none left.
+func release(running map[string]int, key string) {
+	if running[key]--; running[key] <= 0 {
+		delete(running, key)
+	}
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventDispatcher_BoundsConcurrency(t *testing.T) {
+	t.Parallel()
+
+	d := newEventDispatcher(3)
+
+	var (
+		handled               atomic.Int32
+		inFlight, maxInFlight atomic.Int32
+	)
+
+	for range 20 {
+		require.True(t, d.dispatch(t.Context(), func() {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+
+			for {
+				m := maxInFlight.Load()
+				if n <= m || maxInFlight.CompareAndSwap(m, n) {
+					break
+				}
+			}
+
+			time.Sleep(5 * time.Millisecond)
+			handled.Add(1)
+		}))
+	}
+
+	d.wait()
+
+	assert.Equal(t, int32(20), handled.Load())
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(3))
+	assert.Greater(t, maxInFlight.Load(), int32(1))
+	assert.Zero(t, d.running())
+}
+
+func TestEventDispatcher_CanceledWhileWaitingForSlot(t *testing.T) {
+	t.Parallel()
+
+	d := newEventDispatcher(1)
+	block := make(chan struct{})
+
+	require.True(t, d.dispatch(t.Context(), func() { <-block }))
+	assert.Equal(t, int64(1), d.running())
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	assert.False(t, d.dispatch(ctx, func() { t.Error("handler of a canceled dispatch must not run") }))
+
+	close(block)
+	d.wait()
+}
+
+func TestThreadGuard_ConcurrentSummaries(t *testing.T) {
+	t.Parallel()
+
+	g := newThreadGuard()
+
+	var (
+		acquired atomic.Int32
+		wg       sync.WaitGroup
+		releases = make(chan func(), 50)
+	)
+
+	// Concurrent summarize requests of the same thread, only one of them may run
+	for range 50 {
+		wg.Go(func() {
+			if release, ok := g.acquire("C123", "1700000000.000100"); ok {
+				acquired.Add(1)
+				releases <- release
+			}
+		})
+	}
+
+	wg.Wait()
+	close(releases)
+
+	require.Equal(t, int32(1), acquired.Load())
+
+	release, ok := g.acquire("C123", "1700000000.000200")
+	require.True(t, ok, "other threads are summarized independently")
+	release()
+
+	for release := range releases {
+		release()
+	}
+
+	_, ok = g.acquire("C123", "1700000000.000100")
+	assert.True(t, ok, "released threads can be summarized again")
+}
+
+func TestSummaryLimiter_Acquire(t *testing.T) {
+	t.Parallel()
+
+	l := newSummaryLimiter(2, 3)
+
+	releaseFirst, err := l.acquire("U1", "C123")
+	require.NoError(t, err)
+
+	_, err = l.acquire("U1", "C123")
+	require.NoError(t, err)
+
+	_, err = l.acquire("U1", "C456")
+	require.ErrorIs(t, err, ErrRateLimited)
+	assert.Equal(t, &RateLimitError{Scope: limitUser, Limit: 2}, err)
+
+	_, err = l.acquire("U2", "C123")
+	require.NoError(t, err)
+
+	_, err = l.acquire("U3", "C123")
+	assert.Equal(t, &RateLimitError{Scope: limitChannel, Limit: 3}, err)
+
+	// Releasing twice only frees a single summary
+	releaseFirst()
+	releaseFirst()
+
+	_, err = l.acquire("U1", "C456")
+	require.NoError(t, err)
+
+	_, err = l.acquire("U1", "C456")
+	require.ErrorIs(t, err, ErrRateLimited, "the user is at its limit again")
+
+	_, err = l.acquire("U3", "C123")
+	assert.NoError(t, err, "the channel got a slot back")
+}
+
+func TestSummaryLimiter_Disabled(t *testing.T) {
+	t.Parallel()
+
+	l := newSummaryLimiter(0, 0)
+
+	for range 10 {
+		_, err := l.acquire("U1", "C123")
+		require.NoError(t, err)
+	}
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+)
+
+const (
+	// optionDelimiter, optionBOM and optionCRLF are the arguments of the summarize command
+	// that override the CSV options, like for Excel in a locale that expects commas.
+	optionDelimiter = "delimiter"
+	optionBOM       = "bom"
+	optionCRLF      = "crlf"
+)
+
+// csvOption returns the CSV options requested by the delimiter=<char>, bom and crlf arguments of the summarize command,
+// the zero value if there are none.
+func csvOption(args string) (domain.CSVOptions, error) {
+	parsed := parseArgs(args)
+	opts := domain.CSVOptions{BOM: parsed.flag(optionBOM), CRLF: parsed.flag(optionCRLF)}
+
+	if value, ok := parsed.option(optionDelimiter); ok {
+		delimiter, err := domain.ParseCSVDelimiter(value)
+		if err != nil {
+			return domain.CSVOptions{}, fmt.Errorf("%w: %w", errInvalidCSVOption, err)
+		}
+
+		opts.Delimiter = delimiter
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSVOption(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr error
+		name    string
+		args    string
+		want    domain.CSVOptions
+	}{
+		{name: "no options", args: "silent format=csv"},
+		{name: "excel", args: "format=csv delimiter=, BOM crlf", want: domain.CSVOptions{Delimiter: ',', BOM: true, CRLF: true}},
+		{name: "named delimiter", args: "delimiter=tab", want: domain.CSVOptions{Delimiter: '\t'}},
+		{name: "invalid delimiter", args: "delimiter=;;", wantErr: errInvalidCSVOption},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := csvOption(tt.args)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
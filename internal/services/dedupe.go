@@ -0,0 +1,27 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+)
+
+// optionDedupe is the summarize command argument that picks the dedupe strategy of the summary, like dedupe=none.
+const optionDedupe = "dedupe"
+
+// dedupeOption returns the strategy requested by a dedupe=<strategy> argument of the summarize command, empty if there is none.
+func dedupeOption(args string) (domain.DedupeStrategyName, error) {
+	value, ok := parseArgs(args).option(optionDedupe)
+	if !ok {
+		return "", nil
+	}
+
+	name := domain.DedupeStrategyName(strings.ToLower(value))
+	if !slices.Contains(domain.DedupeStrategyNames(), name) {
+		return "", fmt.Errorf("%w: unsupported dedupe strategy %q, the supported ones are %s", errInvalidDedupeOption, value, optionList(domain.DedupeStrategyNames()))
+	}
+
+	return name, nil
+}
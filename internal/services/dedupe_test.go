@@ -0,0 +1,25 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDedupeOption(t *testing.T) {
+	t.Parallel()
+
+	name, err := dedupeOption("format=csv")
+	require.NoError(t, err)
+	assert.Empty(t, name)
+
+	name, err = dedupeOption("DEDUPE=Title")
+	require.NoError(t, err)
+	assert.Equal(t, domain.DedupeTitle, name)
+
+	_, err = dedupeOption("dedupe=fuzzy")
+	require.ErrorIs(t, err, errInvalidDedupeOption)
+	assert.Contains(t, err.Error(), "isrc|url|title|none")
+}
@@ -3,5 +3,9 @@ Package services provides the service layer of the Slack bot.
 
 The service layer encapsulates all network communication and infrastructure related calls and
 responsible to call the correct domain functions in the network code.
+
+Slack events are handled concurrently by a bounded dispatcher, so everything reachable from SlackBot
+is either immutable after NewSlackBot or guarded: the stores and the provider health lock themselves,
+counters are atomic and the summaries of the same thread are serialized by a thread guard.
 */
 package services
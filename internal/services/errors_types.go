@@ -4,14 +4,65 @@ import "errors"
 
 type commandType string
 
-// CommandSummarize is the command that tells handleMentions to run slackMessageProcessor's message handler.
-const CommandSummarize commandType = "summarize"
+const (
+	// CommandSummarize is the command that tells handleMentions to run slackMessageProcessor's message handler.
+	CommandSummarize commandType = "summarize"
+	// CommandProviders is the command that tells handleMentions to report the health of every enabled provider.
+	CommandProviders commandType = "providers"
+	// CommandPrefs is the command that shows or updates the preferences of the mentioning user.
+	CommandPrefs commandType = "prefs"
+	// CommandFind is the command that searches the tracks shared in the channel.
+	CommandFind commandType = "find"
+	// CommandClose is the command that posts a final summary, archives the thread and marks it as closed.
+	CommandClose commandType = "close"
+	// CommandUsage is the command that shows the usage dashboard of the bot to its admins.
+	CommandUsage commandType = "usage"
+	// CommandRetryTitles is the command that looks up the failed titles of the last summary of a thread again.
+	CommandRetryTitles commandType = "retry-titles"
+	// CommandPlaylist is the command that creates or updates a playlist of the tracks of a thread.
+	CommandPlaylist commandType = "playlist"
+	// CommandLeaderboard is the command that ranks the users who shared the most unique tracks of the thread or the channel.
+	CommandLeaderboard commandType = "leaderboard"
+	// CommandPreview is the command that lists the first tracks of a thread without uploading a summary.
+	CommandPreview commandType = "preview"
+	// CommandHelp is the command that lists the commands of the bot, it's also the reply to the unknown commands.
+	CommandHelp commandType = "help"
+)
 
 var (
-	// ErrInvalidCommandType returned by handleMentions in case of an unimplemented CommandType occures.
+	// ErrInvalidCommandType returned by the interaction handlers in case of an unimplemented action or callback occurs,
+	// UnknownCommandError wraps it for the mentions.
 	ErrInvalidCommandType = errors.New("invalid command type")
+	// ErrMissingSignature returned by SignatureVerifier if the Slack signature or timestamp header is missing.
+	ErrMissingSignature = errors.New("missing slack signature headers")
+	// ErrInvalidSignature returned by SignatureVerifier if the request signature doesn't match the signing secret.
+	ErrInvalidSignature = errors.New("invalid slack signature")
+	// ErrExpiredRequest returned by SignatureVerifier if the request timestamp is outside of the tolerance.
+	ErrExpiredRequest = errors.New("expired slack request")
+	// ErrReplayedRequest returned by SignatureVerifier if the same signed request was already received.
+	ErrReplayedRequest = errors.New("replayed slack request")
+	// ErrRequestTooLarge returned by SignatureVerifier if the request body is larger than any Slack payload.
+	ErrRequestTooLarge = errors.New("slack request too large")
+	// ErrUsergroupNotFound returned by the scheduler if the usergroup to mention in the digests doesn't exist.
+	ErrUsergroupNotFound = errors.New("usergroup not found")
+	// ErrSummaryInProgress returned by processThread if the same thread is already being summarized.
+	ErrSummaryInProgress = errors.New("thread is already being summarized")
+	// ErrRateLimited returned by processThread and processChannel if the user or the channel runs too many summaries,
+	// see RateLimitError.
+	ErrRateLimited = errors.New("too many summaries running")
 
-	errIgnoredInvalidAPI   = errors.New("ignored invalid evets api data")
-	errHandleEvent         = errors.New("failed to handle event")
-	errNotImplementedEvent = errors.New("not implemented events api event received")
+	errIgnoredInvalidAPI      = errors.New("ignored invalid evets api data")
+	errHandleEvent            = errors.New("failed to handle event")
+	errNotImplementedEvent    = errors.New("not implemented events api event received")
+	errInvalidActionValue     = errors.New("invalid block action value")
+	errInvalidPreference      = errors.New("invalid preference")
+	errInvalidFormatOption    = errors.New("invalid format option")
+	errInvalidCSVOption       = errors.New("invalid csv option")
+	errInvalidProvider        = errors.New("invalid provider option")
+	errInvalidWindowOption    = errors.New("invalid time window option")
+	errInvalidSortOption      = errors.New("invalid sort option")
+	errInvalidGroupOption     = errors.New("invalid group option")
+	errInvalidDedupeOption    = errors.New("invalid dedupe option")
+	errInvalidAnonymousOption = errors.New("invalid anonymous option")
+	errInvalidThreadLink      = errors.New("invalid thread link")
 )
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+)
+
+const (
+	// optionOnly and optionExcept are the arguments of the summarize command that restrict its providers,
+	// like only=spotify for a channel that only cares about one service.
+	optionOnly   = "only"
+	optionExcept = "except"
+)
+
+// providerOption returns the provider filter requested by the only=<providers> and except=<providers> arguments
+// of the summarize command, the zero value if there are none. The providers are comma separated and must be enabled.
+func providerOption(args string, enabled []musicextractors.ExtractProvider) (domain.ProviderFilter, error) {
+	parsed := parseArgs(args)
+
+	var filter domain.ProviderFilter
+
+	for _, option := range []struct {
+		providers *[]musicextractors.ExtractProvider
+		key       string
+	}{
+		{&filter.Only, optionOnly},
+		{&filter.Except, optionExcept},
+	} {
+		for _, name := range parsed.list(option.key) {
+			p := musicextractors.ExtractProvider(name)
+			if !slices.Contains(enabled, p) {
+				return domain.ProviderFilter{}, fmt.Errorf("%w: unknown provider %q, the enabled ones are %s",
+					errInvalidProvider, name, providerNames(enabled))
+			}
+
+			*option.providers = append(*option.providers, p)
+		}
+	}
+
+	return filter, nil
+}
+
+// providerNames lists the providers separated by commas.
+func providerNames(providers []musicextractors.ExtractProvider) string {
+	names := make([]string, 0, len(providers))
+	for _, p := range providers {
+		names = append(names, string(p))
+	}
+
+	return strings.Join(names, ", ")
+}
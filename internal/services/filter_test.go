@@ -0,0 +1,49 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviderOption(t *testing.T) {
+	t.Parallel()
+
+	enabled := []musicextractors.ExtractProvider{musicextractors.SpotifyProvider, musicextractors.YouTubeProvider, musicextractors.VimeoProvider}
+
+	tests := []struct {
+		wantErr error
+		name    string
+		args    string
+		want    domain.ProviderFilter
+	}{
+		{name: "no filter", args: "format=csv silent"},
+		{name: "only", args: "only=spotify", want: domain.ProviderFilter{Only: []musicextractors.ExtractProvider{musicextractors.SpotifyProvider}}},
+		{
+			name: "except several",
+			args: "except=YouTube,vimeo",
+			want: domain.ProviderFilter{Except: []musicextractors.ExtractProvider{musicextractors.YouTubeProvider, musicextractors.VimeoProvider}},
+		},
+		{name: "disabled provider", args: "only=discogs", wantErr: errInvalidProvider},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := providerOption(tt.args, enabled)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Contains(t, err.Error(), "spotify, youtube, vimeo")
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/Shikachuu/wap-bot/internal/storage"
+	"github.com/Shikachuu/wap-bot/internal/telemetry"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// findResultLimit is the maximum number of tracks listed by the find command.
+const findResultLimit = 5
+
+// slackTimestampTime converts a Slack message timestamp like 1700000000.000100 to time.
+func slackTimestampTime(ts string) time.Time {
+	seconds, _, _ := strings.Cut(ts, ".")
+
+	unix, err := strconv.ParseInt(seconds, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return time.Unix(unix, 0).UTC()
+}
+
+// indexedTracks converts the tracks of a summary to their indexed form.
+func indexedTracks(threadTS string, tracks []domain.Track) []storage.IndexedTrack {
+	indexed := make([]storage.IndexedTrack, 0, len(tracks))
+
+	for _, t := range tracks {
+		indexed = append(indexed, storage.IndexedTrack{
+			SharedAt:  slackTimestampTime(t.MessageTS),
+			ThreadTS:  threadTS,
+			MessageTS: t.MessageTS,
+			UserID:    t.UserID,
+			Title:     t.Title,
+			Artist:    t.Artist,
+			URL:       t.URL,
+			Provider:  string(t.Provider),
+		})
+	}
+
+	return indexed
+}
+
+// indexTracks adds the tracks of a summarized thread to the track index.
+func (bot *SlackBot) indexTracks(ctx context.Context, channelID, threadTS string, tracks []domain.Track) error {
+	if err := bot.store.IndexTracks(ctx, channelID, indexedTracks(threadTS, tracks)); err != nil {
+		return fmt.Errorf("indexing %d tracks: %w", len(tracks), err)
+	}
+
+	return nil
+}
+
+// formatFindResults renders the found tracks as a Slack mrkdwn message, permalinks maps message timestamps to their links.
+func formatFindResults(query string, tracks []storage.IndexedTrack, permalinks map[string]string) string {
+	if len(tracks) == 0 {
+		return fmt.Sprintf("No tracks found for _%s_ in this channel", domain.EscapeMrkdwn(query))
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "*Tracks matching _%s_*", domain.EscapeMrkdwn(query))
+
+	for _, t := range tracks {
+		fmt.Fprintf(&sb, "\n• <%s|%s>", t.URL, domain.EscapeMrkdwn(t.Title))
+
+		if t.UserID != "" {
+			fmt.Fprintf(&sb, " shared by <@%s>", t.UserID)
+		}
+
+		if !t.SharedAt.IsZero() {
+			fmt.Fprintf(&sb, " on %s", t.SharedAt.Format(time.DateOnly))
+		}
+
+		if link, ok := permalinks[t.MessageTS]; ok {
+			fmt.Fprintf(&sb, " (<%s|message>)", link)
+		}
+	}
+
+	return sb.String()
+}
+
+func (bot *SlackBot) handleFind(bCtx context.Context, event *slackevents.AppMentionEvent, query string) error {
+	ctx, t := telemetry.Tracer.Start(bCtx, "slackbot.handle_find")
+	defer t.End()
+
+	reply := "Usage: `find <title or artist>`"
+
+	if query != "" {
+		var tracks []storage.IndexedTrack
+
+		err := telemetry.Measure(t, telemetry.SearchTracksEvent, func() error {
+			var sErr error
+
+			tracks, sErr = bot.store.SearchTracks(ctx, event.Channel, query, findResultLimit)
+
+			return sErr //nolint:wrapcheck // wrapped with the trace below
+		})
+		if err != nil {
+			return telemetry.WrapErrorWithTrace(t, "searching tracks", err) //nolint:wrapcheck // this is a function that wraps the error
+		}
+
+		t.SetAttributes(attribute.Int("find.result_count", len(tracks)))
+
+		reply = formatFindResults(query, tracks, bot.permalinks(ctx, event.Channel, tracks))
+	}
+
+	_, err := bot.socketClient.PostEphemeralContext(
+		ctx,
+		event.Channel,
+		event.User,
+		slack.MsgOptionText(reply, false),
+		slack.MsgOptionTS(event.ThreadTimeStamp),
+	)
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "posting find results", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	return nil
+}
+
+// permalinks looks up the links of the messages the tracks were shared in.
+//
+// The links are best-effort, a failed lookup only leaves the message link out of the results.
+func (bot *SlackBot) permalinks(ctx context.Context, channelID string, tracks []storage.IndexedTrack) map[string]string {
+	links := make(map[string]string, len(tracks))
+
+	for _, t := range tracks {
+		link, err := bot.socketClient.GetPermalinkContext(ctx, &slack.PermalinkParameters{Channel: channelID, Ts: t.MessageTS})
+		if err != nil {
+			slog.WarnContext(ctx, "failed to get message permalink", "error", err, "message_ts", t.MessageTS)
+			continue
+		}
+
+		links[t.MessageTS] = link
+	}
+
+	return links
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/Shikachuu/wap-bot/internal/storage"
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexedTracks_ConvertsMessageTimestamp(t *testing.T) {
+	t.Parallel()
+
+	got := indexedTracks("1700000000.000100", []domain.Track{{
+		Title:     "Rick Astley - Never Gonna Give You Up",
+		URL:       "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		Provider:  musicextractors.YouTubeProvider,
+		MessageTS: "1700003600.000200",
+		UserID:    "U123",
+	}})
+
+	assert.Equal(t, []storage.IndexedTrack{{
+		SharedAt:  time.Unix(1_700_003_600, 0).UTC(),
+		ThreadTS:  "1700000000.000100",
+		MessageTS: "1700003600.000200",
+		UserID:    "U123",
+		Title:     "Rick Astley - Never Gonna Give You Up",
+		URL:       "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		Provider:  "youtube",
+	}}, got)
+}
+
+func TestFormatFindResults(t *testing.T) {
+	t.Parallel()
+
+	tracks := []storage.IndexedTrack{
+		{
+			SharedAt:  time.Unix(1_700_000_000, 0).UTC(),
+			MessageTS: "1700000000.000200",
+			UserID:    "U123",
+			Title:     "Never Gonna Give You Up",
+			URL:       "https://youtu.be/dQw4w9WgXcQ",
+		},
+		{MessageTS: "1700000000.000300", Title: "Halo", URL: "https://example.com/halo"},
+	}
+
+	got := formatFindResults("never", tracks, map[string]string{"1700000000.000200": "https://slack.com/archives/C1/p1700000000000200"})
+
+	assert.Equal(t, "*Tracks matching _never_*\n"+
+		"• <https://youtu.be/dQw4w9WgXcQ|Never Gonna Give You Up> shared by <@U123> on 2023-11-14 (<https://slack.com/archives/C1/p1700000000000200|message>)\n"+
+		"• <https://example.com/halo|Halo>", got)
+
+	assert.Equal(t, "No tracks found for _never_ in this channel", formatFindResults("never", nil, nil))
+}
+
+func TestFormatFindResults_EscapesTitles(t *testing.T) {
+	t.Parallel()
+
+	tracks := []storage.IndexedTrack{{Title: "Salt & Pepper> <!channel>", URL: "https://example.com/salt"}}
+
+	assert.Equal(t, "*Tracks matching _&lt;!here&gt;_*\n• <https://example.com/salt|Salt &amp; Pepper&gt; &lt;!channel&gt;>",
+		formatFindResults("<!here>", tracks, nil))
+}
@@ -0,0 +1,27 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+)
+
+// optionGroup is the summarize command argument that clusters the rows of the summary, like group=genre.
+const optionGroup = "group"
+
+// groupOption returns the grouping requested by a group=<grouping> argument of the summarize command, empty if there is none.
+func groupOption(args string) (domain.GroupBy, error) {
+	value, ok := parseArgs(args).option(optionGroup)
+	if !ok {
+		return "", nil
+	}
+
+	group := domain.GroupBy(strings.ToLower(value))
+	if !slices.Contains(domain.GroupBys(), group) {
+		return "", fmt.Errorf("%w: unsupported grouping %q, the supported ones are %s", errInvalidGroupOption, value, optionList(domain.GroupBys()))
+	}
+
+	return group, nil
+}
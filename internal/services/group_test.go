@@ -0,0 +1,25 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupOption(t *testing.T) {
+	t.Parallel()
+
+	group, err := groupOption("format=csv sort=title")
+	require.NoError(t, err)
+	assert.Empty(t, group)
+
+	group, err = groupOption("GROUP=Genre")
+	require.NoError(t, err)
+	assert.Equal(t, domain.GroupGenre, group)
+
+	_, err = groupOption("group=mood")
+	require.ErrorIs(t, err, errInvalidGroupOption)
+	assert.Contains(t, err.Error(), "none|genre")
+}
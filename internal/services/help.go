@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/Shikachuu/wap-bot/internal/telemetry"
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+// helpBlocks renders the commands of the bot with their arguments and examples as Block Kit sections,
+// followed by the enabled providers.
+func helpBlocks(providers []musicextractors.ExtractProvider) []slack.Block {
+	thread := strings.Join([]string{
+		"*In a thread*",
+		fmt.Sprintf("• %s uploads a summary of the thread, like `summarize format=json sort=artist`", summarizeUsage()),
+		"• `preview` lists the first tracks of the thread only to you, without uploading anything",
+		"• `playlist [youtube]` creates or updates a Spotify or YouTube playlist of the thread",
+		"• `retry-titles` looks up the titles that failed in the last summary again",
+		"• `close [anonymous=true]` posts a final summary, archives the thread and marks it as closed",
+	}, "\n")
+
+	anywhere := strings.Join([]string{
+		"*Anywhere*",
+		"• `summarize channel [last=<30d>]` summarizes the top-level messages of the channel, like `summarize channel last=7d format=md`",
+		"• `leaderboard [channel] [since=<7d|date>] [until=<7d|date>]` ranks the users by the unique tracks they shared, like `leaderboard since=7d`",
+		"• `find <title or artist>` searches the tracks of the summarized threads of the channel, like `find daft punk`",
+		fmt.Sprintf("• `prefs format=<%s|default>` shows or sets your preferred summary format", optionList(domain.ExportFormats())),
+		"• `providers` checks the health of every enabled provider",
+		"• `usage` shows the usage dashboard to the bot admins",
+		"• `help` shows this message",
+	}, "\n")
+
+	footer := fmt.Sprintf("Supported providers: %s\n`/wap <command> <thread link>` runs the thread commands without a mention",
+		providerNames(providers))
+
+	return []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, "WAP Bot commands", false, false)),
+		usageSection(thread),
+		usageSection(anywhere),
+		slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, footer, false, false)),
+	}
+}
+
+// handleHelp lists the commands of the bot only to the mentioning user, it's also the reply to the unknown commands,
+// cmdErr is the error of the unknown command shown above the commands, nil for the help command.
+func (bot *SlackBot) handleHelp(bCtx context.Context, event *slackevents.AppMentionEvent, cmdErr error) error {
+	ctx, t := telemetry.Tracer.Start(bCtx, "slackbot.handle_help")
+	defer t.End()
+
+	blocks := helpBlocks(bot.slackMessageProcessor.EnabledProviders())
+	if cmdErr != nil {
+		blocks = append([]slack.Block{usageSection(fmt.Sprintf("Sorry, %s, here is what I can do:", cmdErr))}, blocks...)
+	}
+
+	err := telemetry.Measure(t, telemetry.PostHelpEvent, func() error {
+		_, pErr := bot.socketClient.PostEphemeralContext(
+			ctx,
+			event.Channel,
+			event.User,
+			slack.MsgOptionText("WAP Bot commands", false),
+			slack.MsgOptionBlocks(blocks...),
+			slack.MsgOptionTS(event.ThreadTimeStamp),
+		)
+
+		return pErr //nolint:wrapcheck // wrapped with the trace below
+	})
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "posting help", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHelpBlocks(t *testing.T) {
+	t.Parallel()
+
+	blocks := helpBlocks([]musicextractors.ExtractProvider{"spotify", "youtube"})
+
+	require.Len(t, blocks, 4)
+
+	var text string
+
+	for _, b := range blocks[1:3] {
+		section, ok := b.(*slack.SectionBlock)
+		require.True(t, ok)
+
+		text += section.Text.Text + "\n"
+	}
+
+	for _, cmd := range []commandType{
+		CommandSummarize, CommandPreview, CommandPlaylist, CommandRetryTitles, CommandClose,
+		CommandLeaderboard, CommandFind, CommandPrefs, CommandProviders, CommandUsage, CommandHelp,
+	} {
+		assert.Contains(t, text, "`"+string(cmd), "command %s isn't listed", cmd)
+	}
+
+	assert.Contains(t, text, "format=<csv|")
+
+	footer, ok := blocks[3].(*slack.ContextBlock)
+	require.True(t, ok)
+
+	providers, ok := footer.ContextElements.Elements[0].(*slack.TextBlockObject)
+	require.True(t, ok)
+	assert.Contains(t, providers.Text, "Supported providers: spotify, youtube")
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"slices"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/slack-go/slack"
+)
+
+// optionFull is the summarize command option that resolves every message again, even if the thread was summarized before.
+const optionFull = "full"
+
+// hasFullOption reports if the arguments of the summarize command ask for a full summary.
+func hasFullOption(args string) bool {
+	return parseArgs(args).flag(optionFull)
+}
+
+// previousSummary is what an incremental summary reuses from the last summary of a thread.
+type previousSummary struct {
+	// lastMessageTS is the timestamp of the newest message the last summary covered.
+	lastMessageTS string
+	tracks        []domain.Track
+	failed        []domain.FailedTitle
+}
+
+// previousSummary returns the tracks and the failed titles of the last summary of a thread,
+// false if the thread wasn't summarized yet.
+func (bot *SlackBot) previousSummary(ctx context.Context, channelID, threadTS string) (previousSummary, bool, error) {
+	last, ok, err := bot.store.ThreadSummary(ctx, channelID, threadTS)
+	if err != nil || !ok {
+		return previousSummary{}, false, err //nolint:wrapcheck // wrapped with the trace by the caller
+	}
+
+	report, err := bot.store.FailureReport(ctx, channelID, threadTS)
+	if err != nil {
+		return previousSummary{}, false, err //nolint:wrapcheck // wrapped with the trace by the caller
+	}
+
+	indexed, err := bot.store.ThreadTracks(ctx, channelID, threadTS)
+	if err != nil {
+		return previousSummary{}, false, err //nolint:wrapcheck // wrapped with the trace by the caller
+	}
+
+	tracks, failed := retryInputs(report, indexed)
+
+	return previousSummary{lastMessageTS: last.LastMessageTS, tracks: tracks, failed: failed}, true, nil
+}
+
+// messagesSince returns the messages posted after the message of ts.
+func messagesSince(msgs []slack.Message, ts string) []slack.Message {
+	// Slack timestamps have a fixed width, so they order like the messages
+	return slices.DeleteFunc(slices.Clone(msgs), func(m slack.Message) bool { return m.Timestamp <= ts })
+}
+
+// lastMessageTS returns the timestamp of the newest message of a thread.
+func lastMessageTS(msgs []slack.Message, threadTS string) string {
+	last := threadTS
+	for _, m := range msgs {
+		last = max(last, m.Timestamp)
+	}
+
+	return last
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/internal/storage"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasFullOption(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, hasFullOption("full"))
+	assert.True(t, hasFullOption("format=csv FULL"))
+	assert.False(t, hasFullOption(""))
+	assert.False(t, hasFullOption("fully"))
+}
+
+func TestMessagesSince(t *testing.T) {
+	t.Parallel()
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{Timestamp: "1700000000.000100"}},
+		{Msg: slack.Msg{Timestamp: "1700000000.000200"}},
+		{Msg: slack.Msg{Timestamp: "1700000000.000300"}},
+	}
+
+	assert.Equal(t, msgs[2:], messagesSince(msgs, "1700000000.000200"))
+	assert.Empty(t, messagesSince(msgs, "1700000000.000300"))
+	assert.Len(t, msgs, 3)
+}
+
+func TestSlackBot_PreviousSummary(t *testing.T) {
+	t.Parallel()
+
+	store, err := storage.NewFileStore("")
+	require.NoError(t, err)
+
+	bot := &SlackBot{store: store, incremental: true}
+
+	_, ok, err := bot.previousSummary(t.Context(), "C123", "1700000000.000100")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, store.IndexTracks(t.Context(), "C123", []storage.IndexedTrack{
+		{ThreadTS: "1700000000.000100", MessageTS: "1700000000.000200", URL: "https://open.spotify.com/track/abc", Provider: "spotify", Title: "Halo"},
+	}))
+	require.NoError(t, bot.saveThreadSummary(t.Context(), "C123", "1700000000.000100", lastMessageTS([]slack.Message{
+		{Msg: slack.Msg{Timestamp: "1700000000.000100"}},
+		{Msg: slack.Msg{Timestamp: "1700000000.000300"}},
+		{Msg: slack.Msg{Timestamp: "1700000000.000200"}},
+	}, "1700000000.000100"), summaryPost{}))
+
+	previous, ok, err := bot.previousSummary(t.Context(), "C123", "1700000000.000100")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "1700000000.000300", previous.lastMessageTS)
+	require.Len(t, previous.tracks, 1)
+	assert.Equal(t, "Halo", previous.tracks[0].Title)
+	assert.Empty(t, previous.failed)
+}
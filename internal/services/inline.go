@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/Shikachuu/wap-bot/internal/telemetry"
+	"github.com/slack-go/slack"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// inlineMessages returns the messages of an inline summary with the comment prepended to the first one,
+// a summary without tracks is just the comment.
+func inlineMessages(messages []string, comment string) []string {
+	if comment == "" {
+		return messages
+	}
+
+	if len(messages) == 0 {
+		return []string{comment}
+	}
+
+	return append([]string{comment + "\n\n" + messages[0]}, messages[1:]...)
+}
+
+// postSummary uploads the summary file to the thread, or posts the messages of inline summaries in order.
+//
+// Returns the uploaded file and the posted messages, or an error if any.
+func (bot *SlackBot) postSummary(bCtx context.Context, summary domain.Summary) (summaryPost, error) {
+	ctx, t := telemetry.Tracer.Start(bCtx, "slackbot.post_summary")
+	defer t.End()
+
+	t.SetAttributes(attribute.String("summary.format", string(summary.Format)))
+
+	// A huge summary file is read from disk, it's removed even if the upload fails halfway
+	defer func() { _ = summary.Close() }()
+
+	if summary.Format != domain.ExportFormatInline {
+		var post summaryPost
+
+		err := telemetry.Measure(t, telemetry.UploadFileV2Event, func() error {
+			file, uErr := bot.socketClient.UploadFileV2Context(ctx, summary.Upload)
+			if uErr != nil {
+				return uErr //nolint:wrapcheck // wrapped with the trace below
+			}
+
+			post.fileID = file.ID
+
+			return nil
+		})
+		if err != nil {
+			return summaryPost{}, telemetry.WrapErrorWithTrace(t, "uploading file to reply", err) //nolint:wrapcheck // this is a function that wraps the error
+		}
+
+		return post, nil
+	}
+
+	messages := inlineMessages(summary.Messages, summary.Upload.InitialComment)
+	t.SetAttributes(attribute.Int("summary.message_count", len(messages)))
+
+	var post summaryPost
+
+	err := telemetry.Measure(t, telemetry.PostInlineSummaryEvent, func() error {
+		for _, m := range messages {
+			// Unfurling every link of the summary would bury the thread under previews
+			_, ts, pErr := bot.socketClient.PostMessageContext(
+				ctx,
+				summary.Upload.Channel,
+				slack.MsgOptionText(m, false),
+				slack.MsgOptionTS(summary.Upload.ThreadTimestamp),
+				slack.MsgOptionDisableLinkUnfurl(),
+				slack.MsgOptionDisableMediaUnfurl(),
+			)
+			if pErr != nil {
+				return pErr //nolint:wrapcheck // wrapped with the trace below
+			}
+
+			post.messageTSs = append(post.messageTSs, ts)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return summaryPost{}, telemetry.WrapErrorWithTrace(t, "posting inline summary", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	return post, nil
+}
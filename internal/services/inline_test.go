@@ -0,0 +1,31 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInlineMessages(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		comment  string
+		messages []string
+		want     []string
+	}{
+		{name: "comment on the first message", comment: "Found 3", messages: []string{"1. a", "2. b"}, want: []string{"Found 3\n\n1. a", "2. b"}},
+		{name: "silent", messages: []string{"1. a"}, want: []string{"1. a"}},
+		{name: "no tracks", comment: "Found 0", want: []string{"Found 0"}},
+		{name: "silent without tracks"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, inlineMessages(tt.messages, tt.comment))
+		})
+	}
+}
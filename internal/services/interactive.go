@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/Shikachuu/wap-bot/internal/telemetry"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/socketmode"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// actionRerun summarizes the thread again with the preferred format of the user or the configured one.
+	actionRerun = "summary_rerun"
+	// actionChangeFormat summarizes the thread again with the selected format.
+	actionChangeFormat = "summary_change_format"
+	// actionCreatePlaylist creates a playlist from the tracks of the thread.
+	actionCreatePlaylist = "summary_create_playlist"
+	// actionOpenOptions opens the summary options modal of the thread.
+	actionOpenOptions = "summary_open_options"
+
+	summaryActionsBlockID = "summary_actions"
+
+	// shortcutSummarize is the callback ID of the message shortcut that summarizes the thread of the message.
+	shortcutSummarize = "summarize_thread"
+	// shortcutSummarizeOptions is the callback ID of the message shortcut that opens the summary options modal of the thread of the message.
+	shortcutSummarizeOptions = "summarize_thread_options"
+)
+
+// messageThread returns the timestamp of the thread of m, the timestamp of m itself if it isn't a reply.
+func messageThread(m slack.Message) string {
+	if m.ThreadTimestamp != "" {
+		return m.ThreadTimestamp
+	}
+
+	return m.Timestamp
+}
+
+// summaryActionsBlocks creates the follow-up buttons posted under every summary,
+// the thread timestamp is carried in the value of every element, the channel comes from the interaction itself.
+func summaryActionsBlocks(threadTS string) []slack.Block {
+	rerun := slack.NewButtonBlockElement(actionRerun, threadTS, slack.NewTextBlockObject(slack.PlainTextType, "Re-run", false, false))
+
+	options := make([]*slack.OptionBlockObject, 0, len(domain.ExportFormats()))
+	for _, f := range domain.ExportFormats() {
+		options = append(options, slack.NewOptionBlockObject(
+			threadTS+"|"+string(f),
+			slack.NewTextBlockObject(slack.PlainTextType, strings.ToUpper(string(f)), false, false),
+			nil,
+		))
+	}
+
+	changeFormat := slack.NewOptionsSelectBlockElement(
+		slack.OptTypeStatic,
+		slack.NewTextBlockObject(slack.PlainTextType, "Change format", false, false),
+		actionChangeFormat,
+		options...,
+	)
+
+	createPlaylist := slack.NewButtonBlockElement(
+		actionCreatePlaylist,
+		threadTS,
+		slack.NewTextBlockObject(slack.PlainTextType, "Create playlist", false, false),
+	)
+
+	openOptions := slack.NewButtonBlockElement(
+		actionOpenOptions,
+		threadTS,
+		slack.NewTextBlockObject(slack.PlainTextType, "Options…", false, false),
+	)
+
+	return []slack.Block{
+		slack.NewActionBlock(summaryActionsBlockID, rerun, changeFormat, createPlaylist, openOptions),
+	}
+}
+
+// parseChangeFormatValue splits the value of a change format option into the thread timestamp and the format.
+func parseChangeFormatValue(value string) (string, domain.ExportFormat, error) {
+	threadTS, format, ok := strings.Cut(value, "|")
+	if !ok || threadTS == "" || format == "" {
+		return "", "", fmt.Errorf("%w: %q", errInvalidActionValue, value)
+	}
+
+	return threadTS, domain.ExportFormat(format), nil
+}
+
+// postSummaryActions posts the follow-up buttons of a summary to the thread.
+//
+// Returns the timestamp of the message or an error if any.
+func (bot *SlackBot) postSummaryActions(ctx context.Context, channelID, threadTS string) (string, error) {
+	_, ts, err := bot.socketClient.PostMessageContext(
+		ctx,
+		channelID,
+		slack.MsgOptionTS(threadTS),
+		slack.MsgOptionText("What's next?", false),
+		slack.MsgOptionBlocks(summaryActionsBlocks(threadTS)...),
+	)
+
+	return ts, err //nolint:wrapcheck // wrapped with the trace by the caller
+}
+
+func (bot *SlackBot) handleInteractive(bCtx context.Context, logger *slog.Logger, evt *socketmode.Event) {
+	ctx, t := telemetry.Tracer.Start(bCtx, "slackbot.handle_interactive")
+	defer t.End()
+
+	callback, ok := evt.Data.(slack.InteractionCallback)
+	if !ok {
+		_ = telemetry.WrapErrorWithTrace(t, "", errIgnoredInvalidAPI)
+
+		logger.WarnContext(ctx, "ignored invalid interactive data")
+
+		return
+	}
+
+	_ = telemetry.Measure(t, telemetry.SendACKEvent, func() error {
+		bot.socketClient.Ack(*evt.Request)
+
+		return nil
+	})
+
+	t.SetAttributes(attribute.String("user.id", callback.User.ID), attribute.String("slack.channel_id", callback.Channel.ID))
+
+	switch callback.Type {
+	case slack.InteractionTypeMessageAction:
+		err := telemetry.Measure(t, telemetry.HandleMessageShortcutEvent, func() error {
+			return bot.handleMessageShortcut(ctx, &callback)
+		})
+		if err != nil {
+			_ = telemetry.WrapErrorWithTrace(t, "", errHandleEvent)
+
+			logger.ErrorContext(ctx, "failed to handle message shortcut", "error", err, "callback_id", callback.CallbackID)
+		}
+	case slack.InteractionTypeViewSubmission:
+		err := telemetry.Measure(t, telemetry.HandleViewSubmissionEvent, func() error {
+			return bot.handleViewSubmission(ctx, &callback)
+		})
+		if err != nil {
+			_ = telemetry.WrapErrorWithTrace(t, "", errHandleEvent)
+
+			logger.ErrorContext(ctx, "failed to handle view submission", "error", err, "callback_id", callback.View.CallbackID)
+		}
+	case slack.InteractionTypeBlockActions:
+		for _, action := range callback.ActionCallback.BlockActions {
+			err := telemetry.Measure(t, telemetry.HandleBlockActionEvent, func() error {
+				return bot.handleBlockAction(ctx, &callback, action)
+			})
+			if err != nil {
+				_ = telemetry.WrapErrorWithTrace(t, "", errHandleEvent)
+
+				logger.ErrorContext(ctx, "failed to handle block action", "error", err, "action_id", action.ActionID)
+			}
+		}
+	default:
+		t.AddEvent("ignored_unsupported_interaction")
+	}
+}
+
+// handleMessageShortcut summarizes the thread of the message the shortcut was used on,
+// with the preferred format of the user or the configured one, or opens the options modal of the thread.
+func (bot *SlackBot) handleMessageShortcut(bCtx context.Context, callback *slack.InteractionCallback) error {
+	ctx, t := telemetry.Tracer.Start(bCtx, "slackbot.handle_message_shortcut")
+	defer t.End()
+
+	t.SetAttributes(attribute.String("slack.callback_id", callback.CallbackID))
+
+	channelID, threadTS := callback.Channel.ID, messageThread(callback.Message)
+
+	if callback.CallbackID == shortcutSummarizeOptions {
+		if err := bot.openOptionsModal(ctx, callback.TriggerID, channelID, threadTS, callback.User.ID); err != nil {
+			return telemetry.WrapErrorWithTrace(t, "opening options modal", err) //nolint:wrapcheck // this is a function that wraps the error
+		}
+
+		return nil
+	}
+
+	if callback.CallbackID != shortcutSummarize {
+		return telemetry.WrapErrorWithTrace(t, "parsing message shortcut", ErrInvalidCommandType) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	_, err := bot.processThread(ctx, channelID, threadTS, summaryOptions{
+		SummaryOptions: domain.SummaryOptions{Format: bot.userFormat(ctx, callback.User.ID)},
+		user:           callback.User.ID,
+	})
+	err = bot.notifySummaryRejected(ctx, channelID, callback.User.ID, threadTS, err)
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "summarizing thread", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	return nil
+}
+
+func (bot *SlackBot) handleBlockAction(bCtx context.Context, callback *slack.InteractionCallback, action *slack.BlockAction) error {
+	ctx, t := telemetry.Tracer.Start(bCtx, "slackbot.handle_block_action")
+	defer t.End()
+
+	t.SetAttributes(attribute.String("slack.action_id", action.ActionID))
+
+	channelID := callback.Channel.ID
+
+	switch action.ActionID {
+	case actionRerun:
+		_, err := bot.processThread(ctx, channelID, action.Value, summaryOptions{
+			SummaryOptions: domain.SummaryOptions{Format: bot.userFormat(ctx, callback.User.ID)},
+			user:           callback.User.ID,
+		})
+		err = bot.notifySummaryRejected(ctx, channelID, callback.User.ID, action.Value, err)
+		if err != nil {
+			return telemetry.WrapErrorWithTrace(t, "re-running summary", err) //nolint:wrapcheck // this is a function that wraps the error
+		}
+	case actionChangeFormat:
+		threadTS, format, err := parseChangeFormatValue(action.SelectedOption.Value)
+		if err != nil {
+			return telemetry.WrapErrorWithTrace(t, "parsing selected format", err) //nolint:wrapcheck // this is a function that wraps the error
+		}
+
+		_, err = bot.processThread(ctx, channelID, threadTS, summaryOptions{
+			SummaryOptions: domain.SummaryOptions{Format: format},
+			user:           callback.User.ID,
+		})
+		err = bot.notifySummaryRejected(ctx, channelID, callback.User.ID, threadTS, err)
+		if err != nil {
+			return telemetry.WrapErrorWithTrace(t, "summarizing with selected format", err) //nolint:wrapcheck // this is a function that wraps the error
+		}
+	case actionCreatePlaylist:
+		if err := bot.handlePlaylist(ctx, channelID, action.Value, callback.User.ID, ""); err != nil {
+			return telemetry.WrapErrorWithTrace(t, "creating playlist", err) //nolint:wrapcheck // this is a function that wraps the error
+		}
+	case actionOpenOptions:
+		if err := bot.openOptionsModal(ctx, callback.TriggerID, channelID, action.Value, callback.User.ID); err != nil {
+			return telemetry.WrapErrorWithTrace(t, "opening options modal", err) //nolint:wrapcheck // this is a function that wraps the error
+		}
+	default:
+		return telemetry.WrapErrorWithTrace(t, "parsing block action", ErrInvalidCommandType) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummaryActionsBlocks_CarryThreadTimestamp(t *testing.T) {
+	t.Parallel()
+
+	blocks := summaryActionsBlocks("1700000000.000100")
+
+	require.Len(t, blocks, 1)
+
+	actions, ok := blocks[0].(*slack.ActionBlock)
+	require.True(t, ok)
+	require.Len(t, actions.Elements.ElementSet, 4)
+
+	rerun, ok := actions.Elements.ElementSet[0].(*slack.ButtonBlockElement)
+	require.True(t, ok)
+	assert.Equal(t, actionRerun, rerun.ActionID)
+	assert.Equal(t, "1700000000.000100", rerun.Value)
+
+	changeFormat, ok := actions.Elements.ElementSet[1].(*slack.SelectBlockElement)
+	require.True(t, ok)
+	require.Len(t, changeFormat.Options, len(domain.ExportFormats()))
+	assert.Equal(t, "1700000000.000100|json", changeFormat.Options[1].Value)
+
+	playlist, ok := actions.Elements.ElementSet[2].(*slack.ButtonBlockElement)
+	require.True(t, ok)
+	assert.Equal(t, actionCreatePlaylist, playlist.ActionID)
+
+	options, ok := actions.Elements.ElementSet[3].(*slack.ButtonBlockElement)
+	require.True(t, ok)
+	assert.Equal(t, actionOpenOptions, options.ActionID)
+	assert.Equal(t, "1700000000.000100", options.Value)
+}
+
+func TestParseChangeFormatValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr    error
+		name       string
+		value      string
+		wantTS     string
+		wantFormat domain.ExportFormat
+	}{
+		{
+			name:       "valid value",
+			value:      "1700000000.000100|json",
+			wantTS:     "1700000000.000100",
+			wantFormat: domain.ExportFormatJSON,
+		},
+		{
+			name:    "missing separator",
+			value:   "1700000000.000100",
+			wantErr: errInvalidActionValue,
+		},
+		{
+			name:    "missing format",
+			value:   "1700000000.000100|",
+			wantErr: errInvalidActionValue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts, format, err := parseChangeFormatValue(tt.value)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTS, ts)
+			assert.Equal(t, tt.wantFormat, format)
+		})
+	}
+}
+
+func TestMessageThread(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "1700000000.000100", messageThread(slack.Message{Msg: slack.Msg{Timestamp: "1700000000.000100"}}))
+	assert.Equal(t, "1700000000.000100", messageThread(slack.Message{Msg: slack.Msg{
+		Timestamp:       "1700000500.000200",
+		ThreadTimestamp: "1700000000.000100",
+	}}))
+}
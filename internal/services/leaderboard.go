@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/Shikachuu/wap-bot/internal/storage"
+	"github.com/Shikachuu/wap-bot/internal/telemetry"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// leaderboardLimit is the maximum number of users ranked by the leaderboard command.
+const leaderboardLimit = 10
+
+// leaderboardPeriodFormat is the format of the window boundaries shown under the leaderboard.
+const leaderboardPeriodFormat = "2006-01-02 15:04"
+
+// leaderboardMedals are the ranks of the first three users of the leaderboard.
+var leaderboardMedals = []string{":first_place_medal:", ":second_place_medal:", ":third_place_medal:"}
+
+// leaderboardPeriod describes the message window of the leaderboard in UTC.
+func leaderboardPeriod(window messageWindow) string {
+	switch {
+	case window.isZero():
+		return "Unique tracks per user, all time"
+	case window.until.IsZero():
+		return fmt.Sprintf("Unique tracks per user since %s (UTC)", window.since.Format(leaderboardPeriodFormat))
+	case window.since.IsZero():
+		return fmt.Sprintf("Unique tracks per user before %s (UTC)", window.until.Format(leaderboardPeriodFormat))
+	default:
+		return fmt.Sprintf("Unique tracks per user from %s to %s (UTC)",
+			window.since.Format(leaderboardPeriodFormat), window.until.Format(leaderboardPeriodFormat))
+	}
+}
+
+// leaderboardBlocks renders the ranked posters as Block Kit sections, scope names what was ranked, like "this thread".
+func leaderboardBlocks(scope string, posters []storage.PosterStats, window messageWindow) []slack.Block {
+	header := slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, "Top posters of "+scope, false, false))
+	period := slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, leaderboardPeriod(window), false, false))
+
+	if len(posters) == 0 {
+		return []slack.Block{header, usageSection("No tracks shared in " + scope + " yet"), period}
+	}
+
+	var sb strings.Builder
+
+	for i, p := range posters {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+
+		rank := fmt.Sprintf("%d.", i+1)
+		if i < len(leaderboardMedals) {
+			rank = leaderboardMedals[i]
+		}
+
+		unit := "tracks"
+		if p.UniqueTracks == 1 {
+			unit = "track"
+		}
+
+		fmt.Fprintf(&sb, "%s <@%s> %d %s", rank, p.UserID, p.UniqueTracks, unit)
+	}
+
+	return []slack.Block{header, usageSection(sb.String()), period}
+}
+
+// threadPosters ranks the users by the unique tracks they shared in the messages of the thread posted in window.
+//
+// The tracks of the thread are resolved like a summary that userID asked for, so it returns ErrSummaryInProgress
+// if the thread is already being summarized, or a RateLimitError if the user or the channel runs too many summaries.
+func (bot *SlackBot) threadPosters(
+	ctx context.Context,
+	channelID, threadTS, userID string,
+	window messageWindow,
+) ([]storage.PosterStats, error) {
+	t := trace.SpanFromContext(ctx)
+
+	release, err := bot.startSummary(ctx, channelID, threadTS, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer release()
+
+	var msgs []slack.Message
+
+	err = telemetry.Measure(t, telemetry.GetConversationRepliesEvent, func() error {
+		var gErr error
+
+		msgs, gErr = bot.threadReplies(ctx, channelID, threadTS)
+
+		return gErr
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get slack thread replies: %w", err)
+	}
+
+	var tracks []domain.Track
+
+	err = telemetry.Measure(t, telemetry.ExtractTracksEvent, func() error {
+		var eErr error
+
+		tracks, eErr = bot.slackMessageProcessor.ExtractTracks(ctx, window.filter(msgs), channelID)
+
+		return eErr //nolint:wrapcheck // wrapped below
+	})
+	if err != nil {
+		return nil, fmt.Errorf("extracting tracks: %w", err)
+	}
+
+	posters := storage.RankPosters(indexedTracks(threadTS, tracks))
+
+	return posters[:min(leaderboardLimit, len(posters))], nil
+}
+
+// handleLeaderboard posts the users who shared the most unique tracks of the thread, or of the channel with the channel option
+// or outside a thread. The channel is ranked from the track index, so only its summarized threads count.
+func (bot *SlackBot) handleLeaderboard(bCtx context.Context, event *slackevents.AppMentionEvent, args string) error {
+	ctx, t := telemetry.Tracer.Start(bCtx, "slackbot.handle_leaderboard")
+	defer t.End()
+
+	window, err := windowOption(args, time.Now())
+	if err != nil {
+		_, err = bot.socketClient.PostEphemeralContext(
+			ctx,
+			event.Channel,
+			event.User,
+			slack.MsgOptionText(fmt.Sprintf("%s\nUsage: `leaderboard [channel] [since=<7d|date>] [until=<7d|date>]`", err), false),
+			slack.MsgOptionTS(event.ThreadTimeStamp),
+		)
+		if err != nil {
+			return telemetry.WrapErrorWithTrace(t, "notifying invalid option", err) //nolint:wrapcheck // this is a function that wraps the error
+		}
+
+		return nil
+	}
+
+	scope := "this thread"
+
+	var posters []storage.PosterStats
+
+	if event.ThreadTimeStamp == "" || hasChannelOption(args) {
+		scope = "this channel"
+
+		err = telemetry.Measure(t, telemetry.GetTopPostersEvent, func() error {
+			var pErr error
+
+			posters, pErr = bot.store.TopPosters(ctx, event.Channel, window.since, window.until, leaderboardLimit)
+
+			return pErr //nolint:wrapcheck // wrapped with the trace below
+		})
+	} else {
+		posters, err = bot.threadPosters(ctx, event.Channel, event.ThreadTimeStamp, event.User, window)
+	}
+
+	if err != nil {
+		// The rejected rankings of a thread are told to the user, every other error is returned as it is
+		if err = bot.notifySummaryRejected(ctx, event.Channel, event.User, event.ThreadTimeStamp, err); err != nil {
+			return telemetry.WrapErrorWithTrace(t, "ranking posters", err) //nolint:wrapcheck // this is a function that wraps the error
+		}
+
+		return nil
+	}
+
+	_, _, err = bot.socketClient.PostMessageContext(
+		ctx,
+		event.Channel,
+		slack.MsgOptionText("Top posters of "+scope, false),
+		slack.MsgOptionBlocks(leaderboardBlocks(scope, posters, window)...),
+		slack.MsgOptionTS(event.ThreadTimeStamp),
+	)
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "posting leaderboard", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	return nil
+}
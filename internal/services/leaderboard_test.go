@@ -0,0 +1,54 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/internal/storage"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeaderboardBlocks(t *testing.T) {
+	t.Parallel()
+
+	posters := []storage.PosterStats{
+		{UserID: "U1", UniqueTracks: 12},
+		{UserID: "U2", UniqueTracks: 7},
+		{UserID: "U3", UniqueTracks: 3},
+		{UserID: "U4", UniqueTracks: 1},
+	}
+
+	blocks := leaderboardBlocks("this thread", posters, messageWindow{})
+
+	require.Len(t, blocks, 3)
+
+	header, ok := blocks[0].(*slack.HeaderBlock)
+	require.True(t, ok)
+	assert.Equal(t, "Top posters of this thread", header.Text.Text)
+
+	ranking, ok := blocks[1].(*slack.SectionBlock)
+	require.True(t, ok)
+	assert.Equal(t,
+		":first_place_medal: <@U1> 12 tracks\n:second_place_medal: <@U2> 7 tracks\n:third_place_medal: <@U3> 3 tracks\n4. <@U4> 1 track",
+		ranking.Text.Text,
+	)
+
+	empty, ok := leaderboardBlocks("this channel", nil, messageWindow{})[1].(*slack.SectionBlock)
+	require.True(t, ok)
+	assert.Equal(t, "No tracks shared in this channel yet", empty.Text.Text)
+}
+
+func TestLeaderboardPeriod(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, time.October, 10, 12, 0, 0, 0, time.UTC)
+	until := time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "Unique tracks per user, all time", leaderboardPeriod(messageWindow{}))
+	assert.Equal(t, "Unique tracks per user since 2026-10-10 12:00 (UTC)", leaderboardPeriod(messageWindow{since: since}))
+	assert.Equal(t, "Unique tracks per user before 2026-10-17 00:00 (UTC)", leaderboardPeriod(messageWindow{until: until}))
+	assert.Equal(t, "Unique tracks per user from 2026-10-10 12:00 to 2026-10-17 00:00 (UTC)",
+		leaderboardPeriod(messageWindow{since: since, until: until}))
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/Shikachuu/wap-bot/internal/telemetry"
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// viewSummaryOptions is the callback ID of the summary options modal.
+const viewSummaryOptions = "summary_options"
+
+// modalSelect creates an optional static select input of the options modal, the block and the action are both named
+// after the argument of the summarize command they set, so the submitted state reads like the arguments of a mention.
+func modalSelect(key, label string, values []string, initial string) *slack.InputBlock {
+	options := make([]*slack.OptionBlockObject, 0, len(values))
+
+	var selected *slack.OptionBlockObject
+
+	for _, v := range values {
+		option := slack.NewOptionBlockObject(v, slack.NewTextBlockObject(slack.PlainTextType, v, false, false), nil)
+		if v == initial {
+			selected = option
+		}
+
+		options = append(options, option)
+	}
+
+	element := slack.NewOptionsSelectBlockElement(slack.OptTypeStatic, slack.NewTextBlockObject(slack.PlainTextType, "Default", false, false), key, options...)
+	element.InitialOption = selected
+
+	return slack.NewInputBlock(key, slack.NewTextBlockObject(slack.PlainTextType, label, false, false), nil, element).WithOptional(true)
+}
+
+// optionsModal creates the modal picking the options of a summary of the thread, format preselects the preferred format of the user.
+// The channel and the thread are carried in the private metadata, the submission has no channel of its own.
+func optionsModal(channelID, threadTS string, format domain.ExportFormat, providers []musicextractors.ExtractProvider) slack.ModalViewRequest {
+	formats := make([]string, 0, len(domain.ExportFormats()))
+	for _, f := range domain.ExportFormats() {
+		formats = append(formats, string(f))
+	}
+
+	strategies := make([]string, 0, len(domain.DedupeStrategyNames()))
+	for _, s := range domain.DedupeStrategyNames() {
+		strategies = append(strategies, string(s))
+	}
+
+	orders := make([]string, 0, len(domain.SortOrders()))
+	for _, o := range domain.SortOrders() {
+		orders = append(orders, string(o))
+	}
+
+	providerOptions := make([]*slack.OptionBlockObject, 0, len(providers))
+	for _, p := range providers {
+		providerOptions = append(providerOptions,
+			slack.NewOptionBlockObject(string(p), slack.NewTextBlockObject(slack.PlainTextType, string(p), false, false), nil))
+	}
+
+	only := slack.NewOptionsMultiSelectBlockElement(
+		slack.MultiOptTypeStatic,
+		slack.NewTextBlockObject(slack.PlainTextType, "Every provider", false, false),
+		optionOnly,
+		providerOptions...,
+	)
+
+	return slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		Title:           slack.NewTextBlockObject(slack.PlainTextType, "Summary options", false, false),
+		Submit:          slack.NewTextBlockObject(slack.PlainTextType, "Summarize", false, false),
+		Close:           slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false),
+		CallbackID:      viewSummaryOptions,
+		PrivateMetadata: channelID + "|" + threadTS,
+		Blocks: slack.Blocks{BlockSet: []slack.Block{
+			modalSelect(prefsFormatKey, "Format", formats, string(format)),
+			modalSelect(optionDedupe, "Deduplicate by", strategies, ""),
+			modalSelect(optionSort, "Sort by", orders, ""),
+			slack.NewInputBlock(optionOnly, slack.NewTextBlockObject(slack.PlainTextType, "Only these providers", false, false), nil, only).
+				WithOptional(true),
+			modalSelect(optionAnonymous, "Anonymous, without who shared the tracks and when", []string{"true", "false"}, ""),
+		}},
+	}
+}
+
+// modalArgs converts the submitted state of the options modal to the arguments of the summarize command,
+// so they go through the same parser as the ones of a mention, the unselected inputs are left out.
+func modalArgs(state *slack.ViewState) string {
+	if state == nil {
+		return ""
+	}
+
+	var args []string
+
+	for _, key := range []string{prefsFormatKey, optionDedupe, optionSort, optionOnly, optionAnonymous} {
+		action, ok := state.Values[key][key]
+		if !ok {
+			continue
+		}
+
+		values := make([]string, 0, len(action.SelectedOptions))
+		for _, o := range action.SelectedOptions {
+			values = append(values, o.Value)
+		}
+
+		if action.SelectedOption.Value != "" {
+			values = append(values, action.SelectedOption.Value)
+		}
+
+		if len(values) > 0 {
+			args = append(args, key+"="+strings.Join(values, ","))
+		}
+	}
+
+	return strings.Join(args, " ")
+}
+
+// parseModalMetadata splits the private metadata of the options modal into the channel ID and the thread timestamp.
+func parseModalMetadata(metadata string) (string, string, error) {
+	channelID, threadTS, ok := strings.Cut(metadata, "|")
+	if !ok || channelID == "" || threadTS == "" {
+		return "", "", fmt.Errorf("%w: %q", errInvalidActionValue, metadata)
+	}
+
+	return channelID, threadTS, nil
+}
+
+// openOptionsModal opens the summary options modal of the thread for the user of the interaction of triggerID.
+func (bot *SlackBot) openOptionsModal(bCtx context.Context, triggerID, channelID, threadTS, userID string) error {
+	ctx, t := telemetry.Tracer.Start(bCtx, "slackbot.open_options_modal")
+	defer t.End()
+
+	t.SetAttributes(attribute.String("slack.channel_id", channelID), attribute.String("slack.thread_ts", threadTS))
+
+	modal := optionsModal(channelID, threadTS, bot.userFormat(ctx, userID), bot.slackMessageProcessor.EnabledProviders())
+
+	err := telemetry.Measure(t, telemetry.OpenOptionsModalEvent, func() error {
+		_, oErr := bot.socketClient.OpenViewContext(ctx, triggerID, modal)
+
+		return oErr //nolint:wrapcheck // wrapped with the trace below
+	})
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "opening options modal", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	return nil
+}
+
+// handleViewSubmission summarizes the thread of a submitted options modal with the selected options.
+func (bot *SlackBot) handleViewSubmission(bCtx context.Context, callback *slack.InteractionCallback) error {
+	ctx, t := telemetry.Tracer.Start(bCtx, "slackbot.handle_view_submission")
+	defer t.End()
+
+	t.SetAttributes(attribute.String("slack.callback_id", callback.View.CallbackID))
+
+	if callback.View.CallbackID != viewSummaryOptions {
+		return telemetry.WrapErrorWithTrace(t, "parsing view submission", ErrInvalidCommandType) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	channelID, threadTS, err := parseModalMetadata(callback.View.PrivateMetadata)
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "parsing modal metadata", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	opts, err := bot.summaryDomainOptions(ctx, modalArgs(callback.View.State), callback.User.ID)
+	if err != nil {
+		_, err = bot.socketClient.PostEphemeralContext(ctx, channelID, callback.User.ID, slack.MsgOptionText(err.Error(), false), slack.MsgOptionTS(threadTS))
+		if err != nil {
+			return telemetry.WrapErrorWithTrace(t, "notifying invalid option", err) //nolint:wrapcheck // this is a function that wraps the error
+		}
+
+		return nil
+	}
+
+	_, err = bot.processThread(ctx, channelID, threadTS, summaryOptions{SummaryOptions: opts, user: callback.User.ID})
+	err = bot.notifySummaryRejected(ctx, channelID, callback.User.ID, threadTS, err)
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "summarizing with selected options", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	return nil
+}
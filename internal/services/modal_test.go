@@ -0,0 +1,108 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionsModal(t *testing.T) {
+	t.Parallel()
+
+	providers := []musicextractors.ExtractProvider{"spotify", "youtube"}
+	modal := optionsModal("C123", "1700000000.000100", domain.ExportFormatJSON, providers)
+
+	assert.Equal(t, viewSummaryOptions, modal.CallbackID)
+	assert.Equal(t, "C123|1700000000.000100", modal.PrivateMetadata)
+	require.Len(t, modal.Blocks.BlockSet, 5)
+
+	format, ok := modal.Blocks.BlockSet[0].(*slack.InputBlock)
+	require.True(t, ok)
+	assert.True(t, format.Optional)
+
+	formatSelect, ok := format.Element.(*slack.SelectBlockElement)
+	require.True(t, ok)
+	assert.Equal(t, prefsFormatKey, formatSelect.ActionID)
+	require.Len(t, formatSelect.Options, len(domain.ExportFormats()))
+	require.NotNil(t, formatSelect.InitialOption)
+	assert.Equal(t, string(domain.ExportFormatJSON), formatSelect.InitialOption.Value)
+
+	dedupe, ok := modal.Blocks.BlockSet[1].(*slack.InputBlock)
+	require.True(t, ok)
+
+	dedupeSelect, ok := dedupe.Element.(*slack.SelectBlockElement)
+	require.True(t, ok)
+	assert.Nil(t, dedupeSelect.InitialOption)
+
+	only, ok := modal.Blocks.BlockSet[3].(*slack.InputBlock)
+	require.True(t, ok)
+
+	onlySelect, ok := only.Element.(*slack.MultiSelectBlockElement)
+	require.True(t, ok)
+	assert.Equal(t, optionOnly, onlySelect.ActionID)
+	require.Len(t, onlySelect.Options, 2)
+	assert.Equal(t, "youtube", onlySelect.Options[1].Value)
+
+	anonymous, ok := modal.Blocks.BlockSet[4].(*slack.InputBlock)
+	require.True(t, ok)
+	assert.Equal(t, optionAnonymous, anonymous.BlockID)
+}
+
+func TestModalArgs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		state *slack.ViewState
+		name  string
+		want  string
+	}{
+		{
+			name: "no state",
+		},
+		{
+			name:  "nothing selected",
+			state: &slack.ViewState{Values: map[string]map[string]slack.BlockAction{prefsFormatKey: {prefsFormatKey: {}}}},
+		},
+		{
+			name: "every input selected",
+			state: &slack.ViewState{Values: map[string]map[string]slack.BlockAction{
+				prefsFormatKey: {prefsFormatKey: {SelectedOption: slack.OptionBlockObject{Value: "json"}}},
+				optionDedupe:   {optionDedupe: {SelectedOption: slack.OptionBlockObject{Value: "title"}}},
+				optionSort:     {optionSort: {SelectedOption: slack.OptionBlockObject{Value: "artist"}}},
+				optionOnly: {optionOnly: {SelectedOptions: []slack.OptionBlockObject{
+					{Value: "spotify"},
+					{Value: "youtube"},
+				}}},
+				optionAnonymous: {optionAnonymous: {SelectedOption: slack.OptionBlockObject{Value: "true"}}},
+			}},
+			want: "format=json dedupe=title sort=artist only=spotify,youtube anonymous=true",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, modalArgs(tt.state))
+		})
+	}
+}
+
+func TestParseModalMetadata(t *testing.T) {
+	t.Parallel()
+
+	channelID, threadTS, err := parseModalMetadata("C123|1700000000.000100")
+	require.NoError(t, err)
+	assert.Equal(t, "C123", channelID)
+	assert.Equal(t, "1700000000.000100", threadTS)
+
+	_, _, err = parseModalMetadata("C123")
+	require.ErrorIs(t, err, errInvalidActionValue)
+
+	_, _, err = parseModalMetadata("|1700000000.000100")
+	require.ErrorIs(t, err, errInvalidActionValue)
+}
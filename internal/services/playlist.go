@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/Shikachuu/wap-bot/internal/storage"
+	"github.com/Shikachuu/wap-bot/internal/telemetry"
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// playlistCreator returns the playlist creator of the provider named in args, the default one if args is empty.
+//
+// Returns false if no creator is configured for the provider.
+func (bot *SlackBot) playlistCreator(args string) (musicextractors.PlaylistCreator, bool) {
+	name := strings.ToLower(strings.TrimSpace(args))
+
+	for _, c := range bot.playlists {
+		if name == "" || string(c.Provider()) == name {
+			return c, true
+		}
+	}
+
+	return nil, false
+}
+
+// playlistTrackURLs returns a URL of every track in order, the link of provider if it's known, the shared URL otherwise.
+//
+// The creators skip the links they can't add, like the Spotify links of a YouTube playlist,
+// while the shared URL of a related provider, like YouTube Music for YouTube, is still added.
+func playlistTrackURLs(tracks []domain.Track, provider musicextractors.ExtractProvider) []string {
+	urls := make([]string, 0, len(tracks))
+
+	for _, t := range tracks {
+		u := t.ProviderURL(provider)
+		if u == "" {
+			u = t.URL
+		}
+
+		urls = append(urls, u)
+	}
+
+	return urls
+}
+
+// playlistName is the name of a new playlist of the thread started at threadTS.
+func playlistName(threadTS string) string {
+	started := slackTimestampTime(threadTS)
+	if started.IsZero() {
+		return "Slack thread"
+	}
+
+	return "Slack thread of " + started.Format("Jan 2, 2006")
+}
+
+// handlePlaylist creates a playlist of the tracks of a thread on the provider named in args,
+// or replaces the tracks of the playlist created by an earlier playlist command, and posts its link in the thread.
+func (bot *SlackBot) handlePlaylist(bCtx context.Context, channelID, threadTS, userID, args string) error {
+	ctx, t := telemetry.Tracer.Start(bCtx, "slackbot.handle_playlist")
+	defer t.End()
+
+	t.SetAttributes(attribute.String("slack.channel_id", channelID), attribute.String("slack.thread_ts", threadTS))
+
+	notify := func(text string) error {
+		_, err := bot.socketClient.PostEphemeralContext(ctx, channelID, userID, slack.MsgOptionText(text, false), slack.MsgOptionTS(threadTS))
+
+		return err //nolint:wrapcheck // wrapped with the trace by the caller
+	}
+
+	creator, ok := bot.playlistCreator(args)
+	if !ok {
+		if err := notify("Creating playlists is not configured for this provider"); err != nil {
+			return telemetry.WrapErrorWithTrace(t, "posting playlist notice", err) //nolint:wrapcheck // this is a function that wraps the error
+		}
+
+		return nil
+	}
+
+	provider := creator.Provider()
+	t.SetAttributes(attribute.String("playlist.provider", string(provider)))
+
+	// The playlist resolves the whole thread like a summary, so it's guarded and limited the same way
+	release, err := bot.startSummary(ctx, channelID, threadTS, userID)
+	if err != nil {
+		if err = bot.notifySummaryRejected(ctx, channelID, userID, threadTS, err); err != nil {
+			return telemetry.WrapErrorWithTrace(t, "starting playlist", err) //nolint:wrapcheck // this is a function that wraps the error
+		}
+
+		return nil
+	}
+
+	defer release()
+
+	var msgs []slack.Message
+
+	err = telemetry.Measure(t, telemetry.GetConversationRepliesEvent, func() error {
+		var gErr error
+
+		msgs, gErr = bot.threadReplies(ctx, channelID, threadTS)
+
+		return gErr
+	})
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "get slack thread replies", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	var tracks []domain.Track
+
+	err = telemetry.Measure(t, telemetry.ExtractTracksEvent, func() error {
+		var eErr error
+
+		tracks, eErr = bot.slackMessageProcessor.ExtractTracks(ctx, msgs, channelID)
+
+		return eErr //nolint:wrapcheck // wrapped with the trace below
+	})
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "extracting tracks", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	previous, _, err := bot.store.ThreadPlaylist(ctx, channelID, threadTS, string(provider))
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "getting thread playlist", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	var playlist musicextractors.Playlist
+
+	err = telemetry.Measure(t, telemetry.SyncPlaylistEvent, func() error {
+		var sErr error
+
+		playlist, sErr = creator.SyncPlaylist(ctx, previous.ID, playlistName(threadTS), playlistTrackURLs(tracks, provider))
+
+		return sErr //nolint:wrapcheck // wrapped with the trace below
+	})
+	if errors.Is(err, musicextractors.ErrNoPlaylistTracks) {
+		if err = notify(fmt.Sprintf("There are no %s tracks in this thread to add to a playlist", provider)); err != nil {
+			return telemetry.WrapErrorWithTrace(t, "posting playlist notice", err) //nolint:wrapcheck // this is a function that wraps the error
+		}
+
+		return nil
+	}
+
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "syncing playlist", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	t.SetAttributes(attribute.Int("playlist.track_count", playlist.Tracks))
+
+	logger := slog.With("channel_id", channelID, "thread_ts", threadTS, "provider", provider)
+
+	// Without the stored ID the next playlist command creates a new playlist instead of updating this one
+	err = bot.store.SetThreadPlaylist(ctx, channelID, threadTS, string(provider), storage.ThreadPlaylist{
+		UpdatedAt: time.Now(),
+		ID:        playlist.ID,
+		URL:       playlist.URL,
+	})
+	if err != nil {
+		_ = telemetry.WrapErrorWithTrace(t, "storing thread playlist", err)
+
+		logger.WarnContext(ctx, "failed to store thread playlist", "error", err)
+	}
+
+	verb := "Created"
+	if previous.ID == playlist.ID {
+		verb = "Updated"
+	}
+
+	err = telemetry.Measure(t, telemetry.PostPlaylistEvent, func() error {
+		_, _, pErr := bot.socketClient.PostMessageContext(
+			ctx,
+			channelID,
+			slack.MsgOptionText(fmt.Sprintf("%s the %s playlist of this thread with %d tracks: %s", verb, provider, playlist.Tracks, playlist.URL), false),
+			slack.MsgOptionTS(threadTS),
+		)
+
+		return pErr //nolint:wrapcheck // wrapped with the trace below
+	})
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "posting playlist", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	logger.InfoContext(ctx, "synced thread playlist", "tracks", playlist.Tracks)
+
+	return nil
+}
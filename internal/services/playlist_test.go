@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/stretchr/testify/assert"
+)
+
+// providerPlaylists is a PlaylistCreator that only reports its provider.
+type providerPlaylists musicextractors.ExtractProvider
+
+func (p providerPlaylists) Provider() musicextractors.ExtractProvider {
+	return musicextractors.ExtractProvider(p)
+}
+
+func (p providerPlaylists) SyncPlaylist(context.Context, string, string, []string) (musicextractors.Playlist, error) {
+	return musicextractors.Playlist{}, nil
+}
+
+func TestPlaylistTrackURLs_PrefersCrossLinks(t *testing.T) {
+	t.Parallel()
+
+	tracks := []domain.Track{
+		{URL: "https://open.spotify.com/track/a", Provider: musicextractors.SpotifyProvider},
+		{URL: "https://www.youtube.com/watch?v=b", Provider: musicextractors.YouTubeProvider},
+		{
+			URL:        "https://www.youtube.com/watch?v=c",
+			Provider:   musicextractors.YouTubeProvider,
+			CrossLinks: map[musicextractors.ExtractProvider]string{musicextractors.SpotifyProvider: "https://open.spotify.com/track/c"},
+		},
+	}
+
+	assert.Equal(t,
+		[]string{"https://open.spotify.com/track/a", "https://www.youtube.com/watch?v=b", "https://open.spotify.com/track/c"},
+		playlistTrackURLs(tracks, musicextractors.SpotifyProvider),
+	)
+}
+
+func TestPlaylistName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "Slack thread of Nov 14, 2023", playlistName("1700000000.000100"))
+	assert.Equal(t, "Slack thread", playlistName("invalid"))
+}
+
+func TestSlackBot_PlaylistCreator(t *testing.T) {
+	t.Parallel()
+
+	bot := &SlackBot{playlists: []musicextractors.PlaylistCreator{
+		providerPlaylists(musicextractors.SpotifyProvider),
+		providerPlaylists(musicextractors.YouTubeProvider),
+	}}
+
+	tests := []struct {
+		name   string
+		args   string
+		want   musicextractors.ExtractProvider
+		wantOK bool
+	}{
+		{name: "default provider", want: musicextractors.SpotifyProvider, wantOK: true},
+		{name: "named provider", args: " YouTube ", want: musicextractors.YouTubeProvider, wantOK: true},
+		{name: "provider without an account", args: "mixcloud"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := bot.playlistCreator(tt.args)
+			assert.Equal(t, tt.wantOK, ok)
+
+			if ok {
+				assert.Equal(t, tt.want, got.Provider())
+			}
+		})
+	}
+
+	_, ok := (&SlackBot{}).playlistCreator("")
+	assert.False(t, ok)
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/Shikachuu/wap-bot/internal/storage"
+	"github.com/Shikachuu/wap-bot/internal/telemetry"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+// prefsFormatKey is the preference key of the summary format, "default" clears the override.
+const prefsFormatKey = "format"
+
+// applyPrefsArgs applies the key=value arguments of the prefs command to prefs.
+//
+// Returns the updated preferences, whether anything was set and an error if an argument is invalid.
+func applyPrefsArgs(prefs storage.UserPreferences, args string) (storage.UserPreferences, bool, error) {
+	fields := strings.Fields(args)
+
+	for _, arg := range fields {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return prefs, false, fmt.Errorf("%w: %q", errInvalidPreference, arg)
+		}
+
+		switch strings.ToLower(key) {
+		case prefsFormatKey:
+			value = strings.ToLower(value)
+
+			switch {
+			case value == "default":
+				prefs.Format = ""
+			case slices.Contains(domain.ExportFormats(), domain.ExportFormat(value)):
+				prefs.Format = value
+			default:
+				return prefs, false, fmt.Errorf("%w: unsupported format %q", errInvalidPreference, value)
+			}
+		default:
+			return prefs, false, fmt.Errorf("%w: unknown preference %q", errInvalidPreference, key)
+		}
+	}
+
+	return prefs, len(fields) > 0, nil
+}
+
+// formatPrefs renders the preferences of a user as a Slack mrkdwn message.
+func formatPrefs(prefs storage.UserPreferences) string {
+	format := prefs.Format
+	if format == "" {
+		format = "default"
+	}
+
+	return fmt.Sprintf("*Your preferences*\n`%s`: %s", prefsFormatKey, format)
+}
+
+// userFormat returns the preferred summary format of a user, empty if the user has no preference.
+//
+// Failing to read the preferences isn't fatal, the configured format is used instead.
+func (bot *SlackBot) userFormat(ctx context.Context, userID string) domain.ExportFormat {
+	prefs, err := bot.store.UserPreferences(ctx, userID)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to read user preferences", "error", err, "user_id", userID)
+		return ""
+	}
+
+	return domain.ExportFormat(prefs.Format)
+}
+
+func (bot *SlackBot) handlePrefs(bCtx context.Context, event *slackevents.AppMentionEvent, args string) error {
+	ctx, t := telemetry.Tracer.Start(bCtx, "slackbot.handle_prefs")
+	defer t.End()
+
+	prefs, err := bot.store.UserPreferences(ctx, event.User)
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "reading user preferences", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	prefs, changed, err := applyPrefsArgs(prefs, args)
+
+	var reply string
+
+	switch {
+	case err != nil:
+		reply = fmt.Sprintf("%s\nUsage: `prefs format=<%s|default>`", err, optionList(domain.ExportFormats()))
+	case changed:
+		if err = bot.store.SetUserPreferences(ctx, event.User, prefs); err != nil {
+			return telemetry.WrapErrorWithTrace(t, "saving user preferences", err) //nolint:wrapcheck // this is a function that wraps the error
+		}
+
+		reply = "Saved! " + formatPrefs(prefs)
+	default:
+		reply = formatPrefs(prefs)
+	}
+
+	_, err = bot.socketClient.PostEphemeralContext(
+		ctx,
+		event.Channel,
+		event.User,
+		slack.MsgOptionText(reply, false),
+		slack.MsgOptionTS(event.ThreadTimeStamp),
+	)
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "posting user preferences", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	return nil
+}
+
+// formatOption returns the format requested by a format=<format> argument of the summarize command,
+// empty if there is none.
+func formatOption(args string) (domain.ExportFormat, error) {
+	value, ok := parseArgs(args).option(prefsFormatKey)
+	if !ok {
+		return "", nil
+	}
+
+	format := domain.ExportFormat(strings.ToLower(value))
+	if !slices.Contains(domain.ExportFormats(), format) {
+		return "", fmt.Errorf("%w: unsupported format %q", errInvalidFormatOption, value)
+	}
+
+	return format, nil
+}
+
+// notifyInvalidOption tells the user that an argument of the summarize command isn't supported.
+func (bot *SlackBot) notifyInvalidOption(ctx context.Context, event *slackevents.AppMentionEvent, err error) error {
+	_, pErr := bot.socketClient.PostEphemeralContext(
+		ctx,
+		event.Channel,
+		event.User,
+		slack.MsgOptionText(fmt.Sprintf("%s\nUsage: %s, `summarize channel [last=<30d>]` takes the same options", err, summarizeUsage()), false),
+		slack.MsgOptionTS(event.ThreadTimeStamp),
+	)
+
+	return pErr //nolint:wrapcheck // wrapped with the trace by the caller
+}
+
+// summarizeUsage returns the arguments of the summarize command as inline code.
+func summarizeUsage() string {
+	return fmt.Sprintf("`summarize format=<%s> [delimiter=<char|tab>] [bom] [crlf] [only=<providers>] [except=<providers>] "+
+		"[since=<7d|date>] [until=<7d|date>] [sort=<%s>] [group=<%s>] [dedupe=<%s>] [anonymous=true] [silent] [full]`",
+		optionList(domain.ExportFormats()), optionList(domain.SortOrders()), optionList(domain.GroupBys()), optionList(domain.DedupeStrategyNames()))
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/Shikachuu/wap-bot/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyPrefsArgs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr     error
+		current     storage.UserPreferences
+		want        storage.UserPreferences
+		name        string
+		text        string
+		wantChanged bool
+	}{
+		{
+			name: "no arguments shows preferences",
+		},
+		{
+			name:        "set format",
+			text:        "format=JSON",
+			want:        storage.UserPreferences{Format: "json"},
+			wantChanged: true,
+		},
+		{
+			name:        "reset format",
+			current:     storage.UserPreferences{Format: "json"},
+			text:        "format=default",
+			want:        storage.UserPreferences{},
+			wantChanged: true,
+		},
+		{
+			name:    "unsupported format",
+			text:    "format=xml",
+			wantErr: errInvalidPreference,
+		},
+		{
+			name:    "unknown preference",
+			text:    "colour=red",
+			wantErr: errInvalidPreference,
+		},
+		{
+			name:    "missing value",
+			text:    "format",
+			wantErr: errInvalidPreference,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, changed, err := applyPrefsArgs(tt.current, tt.text)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantChanged, changed)
+		})
+	}
+}
+
+func TestFormatOption(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr error
+		name    string
+		args    string
+		want    domain.ExportFormat
+	}{
+		{name: "no arguments", args: ""},
+		{name: "other options only", args: "silent"},
+		{name: "requested format", args: "silent format=XLSX", want: domain.ExportFormatXLSX},
+		{name: "markdown table", args: "format=md", want: domain.ExportFormatMarkdown},
+		{name: "unsupported format", args: "format=pdf", wantErr: errInvalidFormatOption},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := formatOption(tt.args)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSlackBot_UserFormat(t *testing.T) {
+	t.Parallel()
+
+	prefs, err := storage.NewFileStore("")
+	require.NoError(t, err)
+	require.NoError(t, prefs.SetUserPreferences(t.Context(), "U123", storage.UserPreferences{Format: "json"}))
+
+	bot := &SlackBot{store: prefs}
+
+	assert.Equal(t, "json", string(bot.userFormat(t.Context(), "U123")))
+	assert.Empty(t, bot.userFormat(t.Context(), "U456"))
+	assert.Empty(t, bot.userFormat(t.Context(), ""))
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/Shikachuu/wap-bot/internal/telemetry"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// previewLimit is the maximum number of tracks listed by the preview command.
+const previewLimit = 10
+
+// formatPreview renders the first limit tracks and the number of every track as a Slack mrkdwn message.
+func formatPreview(tracks []domain.Track, limit int) string {
+	if len(tracks) == 0 {
+		return "No music URLs found in this thread, a summary would be empty"
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "*Found %d music URLs in this thread*", len(tracks))
+
+	if len(tracks) > limit {
+		fmt.Fprintf(&sb, ", the first %d:", limit)
+	}
+
+	for _, t := range tracks[:min(limit, len(tracks))] {
+		title := t.Title
+		if title == "" {
+			title = t.URL
+		}
+
+		if t.Artist != "" {
+			title = t.Artist + " - " + title
+		}
+
+		fmt.Fprintf(&sb, "\n• <%s|%s>", t.URL, domain.EscapeMrkdwn(title))
+
+		if t.UserID != "" {
+			fmt.Fprintf(&sb, " shared by <@%s>", t.UserID)
+		}
+	}
+
+	sb.WriteString("\nMention me with `summarize` to create the summary.")
+
+	return sb.String()
+}
+
+// handlePreview resolves the tracks of the thread like a summary would and lists the first ones only to the mentioning user,
+// without uploading anything. It counts against the summary limits of the user.
+func (bot *SlackBot) handlePreview(bCtx context.Context, event *slackevents.AppMentionEvent) error {
+	ctx, t := telemetry.Tracer.Start(bCtx, "slackbot.handle_preview")
+	defer t.End()
+
+	// A preview resolves the whole thread like a summary, so it's guarded and limited the same way
+	release, err := bot.startSummary(ctx, event.Channel, event.ThreadTimeStamp, event.User)
+	if err != nil {
+		if err = bot.notifySummaryRejected(ctx, event.Channel, event.User, event.ThreadTimeStamp, err); err != nil {
+			return telemetry.WrapErrorWithTrace(t, "starting preview", err) //nolint:wrapcheck // this is a function that wraps the error
+		}
+
+		return nil
+	}
+
+	defer release()
+
+	var msgs []slack.Message
+
+	err = telemetry.Measure(t, telemetry.GetConversationRepliesEvent, func() error {
+		var gErr error
+
+		msgs, gErr = bot.threadReplies(ctx, event.Channel, event.ThreadTimeStamp)
+
+		return gErr
+	})
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "get slack thread replies", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	var tracks []domain.Track
+
+	err = telemetry.Measure(t, telemetry.ExtractTracksEvent, func() error {
+		var eErr error
+
+		tracks, eErr = bot.slackMessageProcessor.ExtractTracks(ctx, msgs, event.Channel)
+
+		return eErr //nolint:wrapcheck // wrapped with the trace below
+	})
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "extracting tracks", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	t.SetAttributes(attribute.Int("preview.track_count", len(tracks)))
+
+	_, err = bot.socketClient.PostEphemeralContext(
+		ctx,
+		event.Channel,
+		event.User,
+		slack.MsgOptionText(formatPreview(tracks, previewLimit), false),
+		slack.MsgOptionTS(event.ThreadTimeStamp),
+	)
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "posting preview", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	return nil
+}
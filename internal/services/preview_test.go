@@ -0,0 +1,46 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatPreview(t *testing.T) {
+	t.Parallel()
+
+	tracks := []domain.Track{
+		{Title: "Never Gonna Give You Up", Artist: "Rick Astley", URL: "https://www.youtube.com/watch?v=dQw4w9WgXcQ", UserID: "U1"},
+		{URL: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"},
+		{Title: "Halo", URL: "https://example.com/halo", UserID: "U2"},
+	}
+
+	assert.Equal(t,
+		"*Found 3 music URLs in this thread*, the first 2:\n"+
+			"• <https://www.youtube.com/watch?v=dQw4w9WgXcQ|Rick Astley - Never Gonna Give You Up> shared by <@U1>\n"+
+			"• <https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT|https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT>\n"+
+			"Mention me with `summarize` to create the summary.",
+		formatPreview(tracks, 2),
+	)
+	assert.Equal(t,
+		"*Found 1 music URLs in this thread*\n• <https://example.com/halo|Halo> shared by <@U2>\nMention me with `summarize` to create the summary.",
+		formatPreview(tracks[2:], 2),
+	)
+	assert.Equal(t, "No music URLs found in this thread, a summary would be empty", formatPreview(nil, 2))
+}
+
+func TestFormatPreview_EscapesTitles(t *testing.T) {
+	t.Parallel()
+
+	tracks := []domain.Track{
+		{Title: "Salt & Pepper> <!channel>", Artist: "A|B", URL: "https://example.com/salt"},
+	}
+
+	assert.Equal(t,
+		"*Found 1 music URLs in this thread*\n"+
+			"• <https://example.com/salt|A|B - Salt &amp; Pepper&gt; &lt;!channel&gt;>\n"+
+			"Mention me with `summarize` to create the summary.",
+		formatPreview(tracks, 2),
+	)
+}
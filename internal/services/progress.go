@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/Shikachuu/wap-bot/internal/telemetry"
+	"github.com/slack-go/slack"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// progressUpdateInterval is how often the progress message of a long summary is updated, chat.update is rate limited.
+const progressUpdateInterval = 3 * time.Second
+
+// summaryProgress is the progress of a summary reported by the extraction workers, it's safe for concurrent use.
+type summaryProgress struct {
+	done  atomic.Int64
+	total atomic.Int64
+}
+
+// report is the domain.ProgressFunc of the summary.
+func (p *summaryProgress) report(done, total int) {
+	p.total.Store(int64(total))
+
+	// The workers finish out of order, the count only goes forward
+	for {
+		current := p.done.Load()
+		if int64(done) <= current || p.done.CompareAndSwap(current, int64(done)) {
+			return
+		}
+	}
+}
+
+// text renders the progress as the text of the progress message.
+func (p *summaryProgress) text() string {
+	return fmt.Sprintf("Working on it — %d/%d messages processed", p.done.Load(), p.total.Load())
+}
+
+// startProgress posts a progress message to the thread of a summary of total messages and updates it periodically
+// until the returned stop is called, which deletes it. Ephemeral messages can't be updated, so the message is visible
+// to everyone while the summary runs.
+//
+// Returns the domain.ProgressFunc of the summary and stop, the progress is only logged if the message can't be posted.
+func (bot *SlackBot) startProgress(ctx context.Context, channelID, threadTS string, total int) (domain.ProgressFunc, func()) {
+	t := trace.SpanFromContext(ctx)
+	logger := slog.With("channel_id", channelID, "thread_ts", threadTS)
+
+	progress := &summaryProgress{}
+	progress.total.Store(int64(total))
+
+	var ts string
+
+	err := telemetry.Measure(t, telemetry.PostProgressEvent, func() error {
+		var pErr error
+
+		_, ts, pErr = bot.socketClient.PostMessageContext(
+			ctx,
+			channelID,
+			slack.MsgOptionText(progress.text(), false),
+			slack.MsgOptionTS(threadTS),
+		)
+
+		return pErr //nolint:wrapcheck // wrapped with the trace below
+	})
+	if err != nil {
+		_ = telemetry.WrapErrorWithTrace(t, "posting progress", err)
+
+		logger.WarnContext(ctx, "failed to post progress message", "error", err)
+
+		return progress.report, func() {}
+	}
+
+	var (
+		wg   sync.WaitGroup
+		done = make(chan struct{})
+	)
+
+	wg.Go(func() {
+		ticker := time.NewTicker(progressUpdateInterval)
+		defer ticker.Stop()
+
+		last := progress.text()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			text := progress.text()
+			if text == last {
+				continue
+			}
+
+			last = text
+
+			// The progress is only a courtesy, a failed update is retried with the next tick
+			if _, _, _, err := bot.socketClient.UpdateMessageContext(ctx, channelID, ts, slack.MsgOptionText(text, false)); err != nil {
+				logger.DebugContext(ctx, "failed to update progress message", "error", err)
+			}
+		}
+	})
+
+	stop := func() {
+		close(done)
+		wg.Wait()
+
+		err := telemetry.Measure(t, telemetry.DeleteProgressEvent, func() error {
+			_, _, dErr := bot.socketClient.DeleteMessageContext(ctx, channelID, ts)
+
+			return dErr //nolint:wrapcheck // wrapped with the trace below
+		})
+		if err != nil && !isGone(err) {
+			_ = telemetry.WrapErrorWithTrace(t, "deleting progress", err)
+
+			logger.WarnContext(ctx, "failed to delete progress message", "error", err)
+		}
+	}
+
+	return progress.report, stop
+}
+
+// trackProgress starts the progress message of a summary of msgs when the thread is longer than the progress threshold
+// and the summary isn't silent.
+//
+// Returns the domain.ProgressFunc of the summary, nil without a progress message, and the stop of the message.
+func (bot *SlackBot) trackProgress(ctx context.Context, channelID, threadTS string, messages int, silent bool) (domain.ProgressFunc, func()) {
+	if silent || bot.progressThreshold <= 0 || messages <= bot.progressThreshold {
+		return nil, func() {}
+	}
+
+	return bot.startProgress(ctx, channelID, threadTS, messages)
+}
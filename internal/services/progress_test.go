@@ -0,0 +1,85 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/socketmode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummaryProgress_OnlyGoesForward(t *testing.T) {
+	t.Parallel()
+
+	var p summaryProgress
+
+	p.total.Store(480)
+	assert.Equal(t, "Working on it — 0/480 messages processed", p.text())
+
+	p.report(120, 490)
+	p.report(90, 490)
+	assert.Equal(t, "Working on it — 120/490 messages processed", p.text())
+}
+
+func TestSlackBot_TrackProgress(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls = append(calls, r.URL.Path)
+
+		resp := map[string]any{"ok": true, "channel": "C123", "ts": "1700000000.000900"}
+
+		switch r.URL.Path {
+		case "/chat.postMessage":
+			assert.Equal(t, "1700000000.000100", r.Form.Get("thread_ts"))
+			assert.Equal(t, "Working on it — 0/300 messages processed", r.Form.Get("text"))
+		case "/chat.delete":
+			assert.Equal(t, "1700000000.000900", r.Form.Get("ts"))
+		default:
+			t.Errorf("unexpected call to %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(resp))
+	}))
+	t.Cleanup(srv.Close)
+
+	bot := &SlackBot{
+		socketClient:      socketmode.New(slack.New("xoxb-test", slack.OptionAPIURL(srv.URL+"/"))),
+		progressThreshold: 200,
+	}
+
+	// Short threads and silent summaries don't get a progress message
+	progress, stop := bot.trackProgress(t.Context(), "C123", "1700000000.000100", 200, false)
+	assert.Nil(t, progress)
+	stop()
+
+	progress, stop = bot.trackProgress(t.Context(), "C123", "1700000000.000100", 300, true)
+	assert.Nil(t, progress)
+	stop()
+
+	assert.Empty(t, calls)
+
+	progress, stop = bot.trackProgress(t.Context(), "C123", "1700000000.000100", 300, false)
+	require.NotNil(t, progress)
+
+	progress(120, 300)
+	stop()
+
+	assert.Equal(t, []string{"/chat.postMessage", "/chat.delete"}, calls)
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/internal/telemetry"
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// probeProviders checks every configured provider and records the results in the provider health,
+// so the summaries skip the providers that are down.
+//
+// Returns the results ordered by provider name.
+func (bot *SlackBot) probeProviders(bCtx context.Context) []musicextractors.ProbeResult {
+	ctx, t := telemetry.Tracer.Start(bCtx, "slackbot.probe_providers")
+	defer t.End()
+
+	results := bot.prober.Probe(ctx)
+
+	if bot.health != nil {
+		bot.health.Update(results)
+	}
+
+	return results
+}
+
+// MonitorProviders probes the providers every interval until ctx gets canceled,
+// keeping the provider health up to date between the on-demand probes of the providers command.
+func (bot *SlackBot) MonitorProviders(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for _, r := range bot.probeProviders(ctx) {
+			if !r.Healthy() {
+				slog.WarnContext(ctx, "provider is down", "provider", r.Provider, "error", r.Err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// formatProviderStatuses renders the probe results as a Slack mrkdwn message.
+func formatProviderStatuses(statuses []musicextractors.ProbeResult) string {
+	var sb strings.Builder
+
+	sb.WriteString("*Provider status*")
+
+	for _, s := range statuses {
+		latency := s.Latency.Round(time.Millisecond)
+
+		if !s.Healthy() {
+			fmt.Fprintf(&sb, "\n:x: `%s` %s (%s)", s.Provider, latency, s.Err)
+			continue
+		}
+
+		fmt.Fprintf(&sb, "\n:white_check_mark: `%s` %s", s.Provider, latency)
+	}
+
+	return sb.String()
+}
+
+func (bot *SlackBot) reportProviderHealth(bCtx context.Context, event *slackevents.AppMentionEvent) error {
+	ctx, t := telemetry.Tracer.Start(bCtx, "slackbot.report_provider_health")
+	defer t.End()
+
+	var statuses []musicextractors.ProbeResult
+
+	_ = telemetry.Measure(t, telemetry.ProbeProvidersEvent, func() error {
+		statuses = bot.probeProviders(ctx)
+
+		return nil
+	})
+
+	t.SetAttributes(attribute.Int("provider.count", len(statuses)))
+
+	_, err := bot.socketClient.PostEphemeralContext(
+		ctx,
+		event.Channel,
+		event.User,
+		slack.MsgOptionText(formatProviderStatuses(statuses), false),
+		slack.MsgOptionTS(event.ThreadTimeStamp),
+	)
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "posting provider status", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	return nil
+}
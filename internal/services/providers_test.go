@@ -0,0 +1,57 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbeProviders_ReportsStatusPerProvider(t *testing.T) {
+	t.Parallel()
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(healthy.Close)
+
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(broken.Close)
+
+	health := musicextractors.NewProviderHealth(time.Minute)
+	bot := &SlackBot{
+		prober: musicextractors.NewProber(map[musicextractors.ExtractProvider]musicextractors.Probe{
+			musicextractors.YouTubeProvider: {URL: broken.URL},
+			musicextractors.SpotifyProvider: {URL: healthy.URL},
+		}),
+		health: health,
+	}
+
+	statuses := bot.probeProviders(t.Context())
+
+	require.Len(t, statuses, 2)
+	assert.Equal(t, musicextractors.SpotifyProvider, statuses[0].Provider)
+	require.NoError(t, statuses[0].Err)
+	assert.Equal(t, musicextractors.YouTubeProvider, statuses[1].Provider)
+	require.ErrorIs(t, statuses[1].Err, musicextractors.ErrRequestFailed)
+
+	assert.False(t, health.Down(musicextractors.SpotifyProvider))
+	assert.True(t, health.Down(musicextractors.YouTubeProvider))
+}
+
+func TestFormatProviderStatuses_MixedResults(t *testing.T) {
+	t.Parallel()
+
+	got := formatProviderStatuses([]musicextractors.ProbeResult{
+		{Provider: musicextractors.SpotifyProvider, Latency: 120 * time.Millisecond},
+		{Provider: musicextractors.YouTubeProvider, Latency: time.Second, Err: musicextractors.ErrRequestFailed},
+	})
+
+	assert.Equal(t, "*Provider status*\n:white_check_mark: `spotify` 120ms\n:x: `youtube` 1s (failed to fetch URL)", got)
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Shikachuu/wap-bot/internal/telemetry"
+	"github.com/slack-go/slack"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// startSummary guards the thread against a second summary and counts it against the limits of userID,
+// like every command resolving the tracks of a whole thread. The summaries without a user aren't limited,
+// the summaries of a channel have no threadTS.
+//
+// Returns the function that releases both, ErrSummaryInProgress if the thread is already being summarized,
+// or a RateLimitError if the user or the channel is at its limit.
+func (bot *SlackBot) startSummary(ctx context.Context, channelID, threadTS, userID string) (func(), error) {
+	release, ok := bot.summaries.acquire(channelID, threadTS)
+	if !ok {
+		return nil, ErrSummaryInProgress
+	}
+
+	if userID == "" {
+		return release, nil
+	}
+
+	releaseSlot, err := bot.acquireSummarySlot(ctx, userID, channelID)
+	if err != nil {
+		release()
+
+		return nil, err
+	}
+
+	return func() {
+		releaseSlot()
+		release()
+	}, nil
+}
+
+// acquireSummarySlot counts a summary the user asked for in the channel against their limits,
+// a rejected summary is counted in the rate limited metric.
+//
+// Returns the function that releases the slot, or a RateLimitError if the user or the channel is at its limit.
+func (bot *SlackBot) acquireSummarySlot(ctx context.Context, userID, channelID string) (func(), error) {
+	release, err := bot.limits.acquire(userID, channelID)
+	if err == nil {
+		return release, nil
+	}
+
+	var limitErr *RateLimitError
+	if errors.As(err, &limitErr) && bot.metrics != nil {
+		bot.metrics.SummariesRateLimited.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("scope", string(limitErr.Scope)),
+			telemetry.GuardedAttribute("slack.channel_id", channelID),
+			telemetry.GuardedAttribute("slack.user_id", userID),
+		))
+	}
+
+	return nil, err
+}
+
+// notifySummaryRejected tells the user why a summary didn't start, if err is ErrSummaryInProgress or ErrRateLimited.
+//
+// Returns nil once the user was told or the error of the notification, err itself for every other error.
+func (bot *SlackBot) notifySummaryRejected(ctx context.Context, channelID, userID, threadTS string, err error) error {
+	var limitErr *RateLimitError
+
+	switch {
+	case errors.Is(err, ErrSummaryInProgress):
+		return bot.notifySummaryInProgress(ctx, channelID, userID, threadTS)
+	case errors.As(err, &limitErr):
+		return bot.notifyRateLimited(ctx, channelID, userID, threadTS, limitErr)
+	default:
+		return err
+	}
+}
+
+// notifyRateLimited tells the user to wait for the running summaries of the limit they reached.
+func (bot *SlackBot) notifyRateLimited(ctx context.Context, channelID, userID, threadTS string, limitErr *RateLimitError) error {
+	reply := fmt.Sprintf("You already have %d summaries running, please wait for them to finish and try again", limitErr.Limit)
+	if limitErr.Scope == limitChannel {
+		reply = fmt.Sprintf("This channel already has %d summaries running, please wait for them to finish and try again", limitErr.Limit)
+	}
+
+	_, err := bot.socketClient.PostEphemeralContext(
+		ctx,
+		channelID,
+		userID,
+		slack.MsgOptionText(reply, false),
+		slack.MsgOptionTS(threadTS),
+	)
+
+	return err //nolint:wrapcheck // wrapped with the trace by the caller
+}
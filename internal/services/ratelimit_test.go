@@ -0,0 +1,138 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlackBot_NotifySummaryRejected(t *testing.T) {
+	t.Parallel()
+
+	errBoom := errors.New("boom")
+
+	tests := []struct {
+		err     error
+		wantErr error
+		name    string
+		want    string
+	}{
+		{
+			name: "summary in progress",
+			err:  ErrSummaryInProgress,
+			want: "This thread is already being summarized, the summary will show up shortly",
+		},
+		{
+			name: "user limit",
+			err:  &RateLimitError{Scope: limitUser, Limit: 2},
+			want: "You already have 2 summaries running, please wait for them to finish and try again",
+		},
+		{
+			name: "channel limit",
+			err:  &RateLimitError{Scope: limitChannel, Limit: 4},
+			want: "This channel already has 4 summaries running, please wait for them to finish and try again",
+		},
+		{
+			name:    "other error",
+			err:     errBoom,
+			wantErr: errBoom,
+		},
+		{
+			name: "no error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var posted string
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.NoError(t, r.ParseForm())
+				assert.Equal(t, "/chat.postEphemeral", r.URL.Path)
+				assert.Equal(t, "U1", r.Form.Get("user"))
+				assert.Equal(t, "1700000000.000100", r.Form.Get("thread_ts"))
+
+				posted = r.Form.Get("text")
+
+				w.Header().Set("Content-Type", "application/json")
+				assert.NoError(t, json.NewEncoder(w).Encode(map[string]any{"ok": true}))
+			}))
+			t.Cleanup(srv.Close)
+
+			bot := &SlackBot{socketClient: socketmode.New(slack.New("xoxb-test", slack.OptionAPIURL(srv.URL+"/")))}
+
+			err := bot.notifySummaryRejected(t.Context(), "C123", "U1", "1700000000.000100", tt.err)
+			require.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, posted)
+		})
+	}
+}
+
+func TestSlackBot_StartSummary(t *testing.T) {
+	t.Parallel()
+
+	bot := &SlackBot{summaries: newThreadGuard(), limits: newSummaryLimiter(1, 4)}
+
+	release, err := bot.startSummary(t.Context(), "C1", "1700000000.000100", "U1")
+	require.NoError(t, err)
+
+	_, err = bot.startSummary(t.Context(), "C1", "1700000000.000100", "U2")
+	require.ErrorIs(t, err, ErrSummaryInProgress)
+
+	// The rejected summary of another thread doesn't keep that thread guarded
+	_, err = bot.startSummary(t.Context(), "C1", "1700000000.000200", "U1")
+	require.ErrorIs(t, err, ErrRateLimited)
+
+	other, err := bot.startSummary(t.Context(), "C1", "1700000000.000200", "")
+	require.NoError(t, err)
+	other()
+
+	release()
+
+	release, err = bot.startSummary(t.Context(), "C1", "1700000000.000200", "U1")
+	require.NoError(t, err)
+	release()
+}
+
+func TestSlackBot_HandlePreview_RejectedWhileSummarizing(t *testing.T) {
+	t.Parallel()
+
+	var posted string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The thread is never read while it's being summarized
+		assert.Equal(t, "/chat.postEphemeral", r.URL.Path)
+		assert.NoError(t, r.ParseForm())
+
+		posted = r.Form.Get("text")
+
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(map[string]any{"ok": true}))
+	}))
+	t.Cleanup(srv.Close)
+
+	bot := &SlackBot{
+		socketClient: socketmode.New(slack.New("xoxb-test", slack.OptionAPIURL(srv.URL+"/"))),
+		summaries:    newThreadGuard(),
+		limits:       newSummaryLimiter(2, 4),
+	}
+
+	release, err := bot.startSummary(t.Context(), "C123", "1700000000.000100", "U2")
+	require.NoError(t, err)
+	t.Cleanup(release)
+
+	require.NoError(t, bot.handlePreview(t.Context(), &slackevents.AppMentionEvent{
+		Channel: "C123", User: "U1", ThreadTimeStamp: "1700000000.000100",
+	}))
+	assert.Equal(t, "This thread is already being summarized, the summary will show up shortly", posted)
+}
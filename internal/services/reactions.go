@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Shikachuu/wap-bot/internal/telemetry"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// reactionDone is added to the mention of a summary once it's posted.
+	reactionDone = "white_check_mark"
+	// reactionFailed is added to the mention of a summary that failed, the error is told to the user ephemerally.
+	reactionFailed = "x"
+)
+
+// reactToSummary reacts to the mention that asked for a summary with its outcome, err is the error of the summary.
+// A summary already in progress gets no reaction, the running one reacts to its own mention, neither does a rate limited one,
+// the user is asked to wait instead. The slash commands have no message to react to, they only get the ephemeral error.
+//
+// The reactions are only a courtesy, failing to add them only gets logged.
+func (bot *SlackBot) reactToSummary(ctx context.Context, event *slackevents.AppMentionEvent, err error) {
+	if errors.Is(err, ErrSummaryInProgress) || errors.Is(err, ErrRateLimited) {
+		return
+	}
+
+	t := trace.SpanFromContext(ctx)
+	logger := slog.With("channel_id", event.Channel, "thread_ts", event.ThreadTimeStamp)
+
+	reaction := reactionDone
+	if err != nil {
+		reaction = reactionFailed
+
+		_, pErr := bot.socketClient.PostEphemeralContext(
+			ctx,
+			event.Channel,
+			event.User,
+			slack.MsgOptionText(fmt.Sprintf("Couldn't create the summary: %s", err), false),
+			slack.MsgOptionTS(event.ThreadTimeStamp),
+		)
+		if pErr != nil {
+			logger.WarnContext(ctx, "failed to post summary error", "error", pErr)
+		}
+	}
+
+	if event.TimeStamp == "" {
+		return
+	}
+
+	rErr := telemetry.Measure(t, telemetry.AddReactionEvent, func() error {
+		return bot.socketClient.AddReactionContext(ctx, reaction, slack.NewRefToMessage(event.Channel, event.TimeStamp)) //nolint:wrapcheck // wrapped with the trace below
+	})
+	if rErr != nil {
+		_ = telemetry.WrapErrorWithTrace(t, "adding reaction", rErr)
+
+		logger.WarnContext(ctx, "failed to add reaction", "error", rErr, "reaction", reaction)
+	}
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlackBot_ReactToSummary(t *testing.T) {
+	t.Parallel()
+
+	mention := &slackevents.AppMentionEvent{
+		User: "U1", Channel: "C123", TimeStamp: "1700000000.000500", ThreadTimeStamp: "1700000000.000100",
+	}
+
+	tests := []struct {
+		err   error
+		event *slackevents.AppMentionEvent
+		name  string
+		want  []string
+	}{
+		{
+			name:  "summary posted",
+			event: mention,
+			want:  []string{"reactions.add:" + reactionDone},
+		},
+		{
+			name:  "summary failed",
+			event: mention,
+			err:   errors.New("boom"),
+			want:  []string{"chat.postEphemeral:Couldn't create the summary: boom", "reactions.add:" + reactionFailed},
+		},
+		{
+			name:  "summary in progress",
+			event: mention,
+			err:   ErrSummaryInProgress,
+		},
+		{
+			name:  "summary rate limited",
+			event: mention,
+			err:   &RateLimitError{Scope: limitUser, Limit: 2},
+		},
+		{
+			name:  "slash command without a message",
+			event: &slackevents.AppMentionEvent{User: "U1", Channel: "C123", ThreadTimeStamp: "1700000000.000100"},
+			err:   errors.New("boom"),
+			want:  []string{"chat.postEphemeral:Couldn't create the summary: boom"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				mu    sync.Mutex
+				calls []string
+			)
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.NoError(t, r.ParseForm())
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				switch r.URL.Path {
+				case "/reactions.add":
+					assert.Equal(t, "1700000000.000500", r.Form.Get("timestamp"))
+					calls = append(calls, "reactions.add:"+r.Form.Get("name"))
+				case "/chat.postEphemeral":
+					assert.Equal(t, "1700000000.000100", r.Form.Get("thread_ts"))
+					calls = append(calls, "chat.postEphemeral:"+r.Form.Get("text"))
+				default:
+					t.Errorf("unexpected call to %s", r.URL.Path)
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				assert.NoError(t, json.NewEncoder(w).Encode(map[string]any{"ok": true}))
+			}))
+			t.Cleanup(srv.Close)
+
+			bot := &SlackBot{socketClient: socketmode.New(slack.New("xoxb-test", slack.OptionAPIURL(srv.URL+"/")))}
+
+			bot.reactToSummary(t.Context(), tt.event, tt.err)
+
+			assert.Equal(t, tt.want, calls)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/internal/storage"
+	"github.com/slack-go/slack"
+)
+
+// summaryPost identifies what the bot posted with a summary, so the next summary of the thread can replace it.
+type summaryPost struct {
+	// fileID is the ID of the uploaded summary file, empty for inline summaries.
+	fileID string
+	// messageTSs are the timestamps of the posted messages, like the inline summary and the follow-up buttons.
+	messageTSs []string
+}
+
+// goneErrors are the Slack errors of deleting a file or a message that's already deleted.
+var goneErrors = []string{"file_not_found", "file_deleted", "message_not_found"}
+
+// isGone reports if err is the Slack error of deleting a file or a message that's already deleted.
+func isGone(err error) bool {
+	var slackErr slack.SlackErrorResponse
+
+	return errors.As(err, &slackErr) && slices.Contains(goneErrors, slackErr.Err)
+}
+
+// deleteSummaryPost deletes the file and the messages of a summary posted to channelID, the ones already deleted are skipped.
+func (bot *SlackBot) deleteSummaryPost(ctx context.Context, channelID string, post storage.ThreadSummary) error {
+	var errs []error
+
+	if post.FileID != "" {
+		if err := bot.socketClient.DeleteFileContext(ctx, post.FileID); err != nil && !isGone(err) {
+			errs = append(errs, fmt.Errorf("deleting file %s: %w", post.FileID, err))
+		}
+	}
+
+	for _, ts := range post.MessageTSs {
+		if _, _, err := bot.socketClient.DeleteMessageContext(ctx, channelID, ts); err != nil && !isGone(err) {
+			errs = append(errs, fmt.Errorf("deleting message %s: %w", ts, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// saveThreadSummary remembers post as the last summary of a thread covering the messages until lastTS,
+// an empty lastTS keeps the one of the previous summary.
+//
+// The previous summary is deleted from the thread if replacing is enabled, the record is only kept
+// if the incremental or the replaced summaries need it.
+func (bot *SlackBot) saveThreadSummary(ctx context.Context, channelID, threadTS, lastTS string, post summaryPost) error {
+	if !bot.incremental && !bot.replacePrevious {
+		return nil
+	}
+
+	previous, ok, err := bot.store.ThreadSummary(ctx, channelID, threadTS)
+	if err != nil {
+		return fmt.Errorf("getting the last summary: %w", err)
+	}
+
+	if lastTS == "" {
+		lastTS = previous.LastMessageTS
+	}
+
+	err = bot.store.SetThreadSummary(ctx, channelID, threadTS, storage.ThreadSummary{
+		SummarizedAt:  time.Now().UTC(),
+		LastMessageTS: lastTS,
+		FileID:        post.fileID,
+		MessageTSs:    post.messageTSs,
+	})
+	if err != nil {
+		return fmt.Errorf("saving the last summary: %w", err)
+	}
+
+	// The new summary is already posted and remembered, so a failed delete only leaves the old one behind
+	if ok && bot.replacePrevious {
+		if err = bot.deleteSummaryPost(ctx, channelID, previous); err != nil {
+			return fmt.Errorf("replacing the last summary: %w", err)
+		}
+	}
+
+	return nil
+}
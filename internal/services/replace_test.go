@@ -0,0 +1,103 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/internal/storage"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/socketmode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsGone(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, isGone(slack.SlackErrorResponse{Err: "file_not_found"}))
+	assert.True(t, isGone(slack.SlackErrorResponse{Err: "message_not_found"}))
+	assert.False(t, isGone(slack.SlackErrorResponse{Err: "cant_delete_file"}))
+	assert.False(t, isGone(errors.New("file_not_found")))
+}
+
+func TestSlackBot_SaveThreadSummary_ReplacesPrevious(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu      sync.Mutex
+		deleted []string
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		resp := map[string]any{"ok": true}
+
+		switch r.URL.Path {
+		case "/files.delete":
+			deleted = append(deleted, r.Form.Get("file"))
+		case "/chat.delete":
+			deleted = append(deleted, r.Form.Get("ts"))
+
+			// Someone already deleted the buttons by hand
+			if r.Form.Get("ts") == "1700000000.000900" {
+				resp = map[string]any{"ok": false, "error": "message_not_found"}
+			}
+		default:
+			t.Errorf("unexpected call to %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(resp))
+	}))
+	t.Cleanup(srv.Close)
+
+	store, err := storage.NewFileStore("")
+	require.NoError(t, err)
+
+	bot := &SlackBot{
+		socketClient:    socketmode.New(slack.New("xoxb-test", slack.OptionAPIURL(srv.URL+"/"))),
+		store:           store,
+		replacePrevious: true,
+	}
+
+	const threadTS = "1700000000.000100"
+
+	// The first summary has nothing to replace
+	require.NoError(t, bot.saveThreadSummary(t.Context(), "C123", threadTS, "1700000000.000500",
+		summaryPost{fileID: "F1", messageTSs: []string{"1700000000.000900"}}))
+	assert.Empty(t, deleted)
+
+	// A retried summary keeps the messages the last one covered
+	require.NoError(t, bot.saveThreadSummary(t.Context(), "C123", threadTS, "", summaryPost{fileID: "F2"}))
+	assert.Equal(t, []string{"F1", "1700000000.000900"}, deleted)
+
+	got, ok, err := store.ThreadSummary(t.Context(), "C123", threadTS)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "1700000000.000500", got.LastMessageTS)
+	assert.Equal(t, "F2", got.FileID)
+	assert.Empty(t, got.MessageTSs)
+}
+
+func TestSlackBot_SaveThreadSummary_DisabledKeepsNothing(t *testing.T) {
+	t.Parallel()
+
+	store, err := storage.NewFileStore("")
+	require.NoError(t, err)
+
+	bot := &SlackBot{store: store}
+
+	require.NoError(t, bot.saveThreadSummary(t.Context(), "C123", "1700000000.000100", "1700000000.000500", summaryPost{fileID: "F1"}))
+
+	_, ok, err := store.ThreadSummary(t.Context(), "C123", "1700000000.000100")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/Shikachuu/wap-bot/internal/storage"
+	"github.com/Shikachuu/wap-bot/internal/telemetry"
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// failureReport converts the failed titles of a summary to their stored form.
+func failureReport(failed []domain.FailedTitle, at time.Time) storage.FailureReport {
+	report := storage.FailureReport{CreatedAt: at, Titles: make([]storage.FailedTitle, 0, len(failed))}
+
+	for _, f := range failed {
+		report.Titles = append(report.Titles, storage.FailedTitle{
+			MessageTS: f.MessageTS,
+			UserID:    f.UserID,
+			URL:       f.URL,
+			Provider:  string(f.Provider),
+			Kind:      string(f.Kind),
+		})
+	}
+
+	return report
+}
+
+// retryInputs converts a failure report and the indexed tracks of its thread to the inputs of RetryTitles,
+// the indexed tracks of the failed links, listed without a title, are left out of the resolved ones.
+func retryInputs(report storage.FailureReport, indexed []storage.IndexedTrack) ([]domain.Track, []domain.FailedTitle) {
+	failed := make([]domain.FailedTitle, 0, len(report.Titles))
+	failedLinks := make(map[string]struct{}, len(report.Titles))
+
+	for _, f := range report.Titles {
+		failed = append(failed, domain.FailedTitle{
+			Track: domain.Track{
+				URL:       f.URL,
+				Provider:  musicextractors.ExtractProvider(f.Provider),
+				MessageTS: f.MessageTS,
+				UserID:    f.UserID,
+			},
+			Kind: musicextractors.ErrorKind(f.Kind),
+		})
+		failedLinks[f.MessageTS+"|"+f.URL] = struct{}{}
+	}
+
+	resolved := make([]domain.Track, 0, len(indexed))
+
+	for _, t := range indexed {
+		if _, ok := failedLinks[t.MessageTS+"|"+t.URL]; ok {
+			continue
+		}
+
+		resolved = append(resolved, domain.Track{
+			Title:     t.Title,
+			Artist:    t.Artist,
+			URL:       t.URL,
+			Provider:  musicextractors.ExtractProvider(t.Provider),
+			MessageTS: t.MessageTS,
+			UserID:    t.UserID,
+		})
+	}
+
+	return resolved, failed
+}
+
+// saveFailureReport stores the failed titles of the last summary of a thread, replacing the previous report.
+func (bot *SlackBot) saveFailureReport(ctx context.Context, channelID, threadTS string, failed []domain.FailedTitle) error {
+	if err := bot.store.SetFailureReport(ctx, channelID, threadTS, failureReport(failed, time.Now().UTC())); err != nil {
+		return fmt.Errorf("saving %d failed titles: %w", len(failed), err)
+	}
+
+	return nil
+}
+
+// logFailures logs every link left out of a summary with the message it was shared in,
+// so the skipped links counted in the summary comment can be traced back.
+func logFailures(ctx context.Context, logger *slog.Logger, failures []domain.MessageFailure) {
+	for _, f := range failures {
+		logger.DebugContext(ctx, "skipped link", "message_ts", f.MessageTS, "user_id", f.UserID,
+			"url", f.URL, "provider", f.Provider, "kind", f.Kind, "error", f.Err)
+	}
+}
+
+// handleRetryTitles looks up the failed titles of the last summary of the thread again
+// and uploads the updated summary, without resolving the rest of the thread again.
+func (bot *SlackBot) handleRetryTitles(bCtx context.Context, event *slackevents.AppMentionEvent) error {
+	ctx, t := telemetry.Tracer.Start(bCtx, "slackbot.handle_retry_titles")
+	defer t.End()
+
+	t.SetAttributes(attribute.String("slack.channel_id", event.Channel), attribute.String("slack.thread_ts", event.ThreadTimeStamp))
+
+	release, ok := bot.summaries.acquire(event.Channel, event.ThreadTimeStamp)
+	if !ok {
+		if err := bot.notifySummaryInProgress(ctx, event.Channel, event.User, event.ThreadTimeStamp); err != nil {
+			return telemetry.WrapErrorWithTrace(t, "notifying summary in progress", err) //nolint:wrapcheck // this is a function that wraps the error
+		}
+
+		return nil
+	}
+
+	defer release()
+
+	var (
+		report  storage.FailureReport
+		indexed []storage.IndexedTrack
+	)
+
+	err := telemetry.Measure(t, telemetry.GetFailureReportEvent, func() error {
+		var rErr error
+
+		if report, rErr = bot.store.FailureReport(ctx, event.Channel, event.ThreadTimeStamp); rErr != nil {
+			return rErr //nolint:wrapcheck // wrapped with the trace below
+		}
+
+		indexed, rErr = bot.store.ThreadTracks(ctx, event.Channel, event.ThreadTimeStamp)
+
+		return rErr //nolint:wrapcheck // wrapped with the trace below
+	})
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "getting failure report", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	t.SetAttributes(attribute.Int("retry.failed_count", len(report.Titles)))
+
+	if len(report.Titles) == 0 {
+		_, err = bot.socketClient.PostEphemeralContext(
+			ctx,
+			event.Channel,
+			event.User,
+			slack.MsgOptionText("There are no failed titles to retry, the last summary of this thread resolved every title", false),
+			slack.MsgOptionTS(event.ThreadTimeStamp),
+		)
+		if err != nil {
+			return telemetry.WrapErrorWithTrace(t, "posting retry notice", err) //nolint:wrapcheck // this is a function that wraps the error
+		}
+
+		return nil
+	}
+
+	resolved, failed := retryInputs(report, indexed)
+
+	var summary domain.Summary
+
+	err = telemetry.Measure(t, telemetry.RetryTitlesEvent, func() error {
+		var sErr error
+
+		summary, sErr = bot.slackMessageProcessor.RetryTitles(ctx, event.Channel, event.ThreadTimeStamp, resolved, failed)
+
+		return sErr //nolint:wrapcheck // wrapped with the trace below
+	})
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "retrying titles", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	post, err := bot.postSummary(ctx, summary)
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "posting summary", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	logger := slog.With("channel_id", event.Channel, "thread_ts", event.ThreadTimeStamp)
+
+	// The index and the report only power later commands, the updated summary is already posted
+	err = telemetry.Measure(t, telemetry.IndexTracksEvent, func() error {
+		return bot.indexTracks(ctx, event.Channel, event.ThreadTimeStamp, summary.Tracks)
+	})
+	if err != nil {
+		_ = telemetry.WrapErrorWithTrace(t, "indexing tracks", err)
+
+		logger.WarnContext(ctx, "failed to index tracks", "error", err)
+	}
+
+	if err = bot.saveFailureReport(ctx, event.Channel, event.ThreadTimeStamp, summary.FailedTitles); err != nil {
+		_ = telemetry.WrapErrorWithTrace(t, "saving failure report", err)
+
+		logger.WarnContext(ctx, "failed to save failure report", "error", err)
+	}
+
+	// The retried summary covers the same messages as the one it replaces
+	err = telemetry.Measure(t, telemetry.SaveThreadSummaryEvent, func() error {
+		return bot.saveThreadSummary(ctx, event.Channel, event.ThreadTimeStamp, "", post)
+	})
+	if err != nil {
+		_ = telemetry.WrapErrorWithTrace(t, "saving thread summary", err)
+
+		logger.WarnContext(ctx, "failed to save the last summary", "error", err)
+	}
+
+	logFailures(ctx, logger, summary.Failures)
+	logger.InfoContext(ctx, "retried failed titles", "failed", len(failed), "still_failing", len(summary.FailedTitles))
+
+	return nil
+}
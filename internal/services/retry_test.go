@@ -0,0 +1,43 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/Shikachuu/wap-bot/internal/storage"
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryInputs_LeavesOutFailedLinks(t *testing.T) {
+	t.Parallel()
+
+	failed := []domain.FailedTitle{{
+		Track: domain.Track{
+			URL:       "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+			Provider:  musicextractors.YouTubeProvider,
+			MessageTS: "1700000000.000200",
+			UserID:    "U1",
+		},
+		Kind: musicextractors.ErrorKindRateLimited,
+	}}
+
+	report := failureReport(failed, time.Date(2026, time.October, 17, 12, 0, 0, 0, time.UTC))
+
+	resolved, gotFailed := retryInputs(report, []storage.IndexedTrack{
+		// An open circuit keeps the URL in the index without a title
+		{MessageTS: "1700000000.000200", URL: "https://www.youtube.com/watch?v=dQw4w9WgXcQ", Provider: "youtube"},
+		{MessageTS: "1700000000.000300", URL: "https://open.spotify.com/track/abc", Provider: "spotify", Title: "Halo", Artist: "Beyoncé", UserID: "U2"},
+	})
+
+	assert.Equal(t, failed, gotFailed)
+	assert.Equal(t, []domain.Track{{
+		Title:     "Halo",
+		Artist:    "Beyoncé",
+		URL:       "https://open.spotify.com/track/abc",
+		Provider:  musicextractors.SpotifyProvider,
+		MessageTS: "1700000000.000300",
+		UserID:    "U2",
+	}}, resolved)
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Shikachuu/wap-bot/internal/telemetry"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// mentionRegex matches the user mentions of a message, like the one of the bot at the start of every app mention.
+var mentionRegex = regexp.MustCompile(`^<@[A-Z0-9]+(\|[^>]*)?>$`)
+
+// UnknownCommandError returned by parseCommand if a mention has no registered command, it wraps ErrInvalidCommandType.
+type UnknownCommandError struct {
+	// Name is the first word of the mention, empty if the bot was mentioned without any text.
+	Name string
+}
+
+// Error implements the error interface.
+func (e *UnknownCommandError) Error() string {
+	if e.Name == "" {
+		return "no command given"
+	}
+
+	return fmt.Sprintf("unknown command %q", e.Name)
+}
+
+// Unwrap returns ErrInvalidCommandType.
+func (e *UnknownCommandError) Unwrap() error {
+	return ErrInvalidCommandType
+}
+
+// parseCommand tokenizes the text of a mention and strips the user mentions like the one of the bot, the first word left
+// is the command, matched against the known commands case-insensitively, and the rest of the text are its arguments
+// for parseArgs. Only the first word counts, so a sentence like "can you help me summarize this" never runs
+// a command it happens to contain.
+//
+// Returns the command and its arguments, or an UnknownCommandError if the first word isn't a known command.
+func parseCommand(text string, known []commandType) (commandType, string, error) {
+	fields := slices.DeleteFunc(strings.Fields(text), mentionRegex.MatchString)
+	if len(fields) == 0 {
+		return "", "", &UnknownCommandError{}
+	}
+
+	cmd := commandType(strings.ToLower(fields[0]))
+	if !slices.Contains(known, cmd) {
+		return "", "", &UnknownCommandError{Name: fields[0]}
+	}
+
+	return cmd, strings.Join(fields[1:], " "), nil
+}
+
+// commandRoute is a command of the mentions and the handler it's routed to.
+type commandRoute struct {
+	handle func(ctx context.Context, event *slackevents.AppMentionEvent, args string) error
+	name   commandType
+	// action describes the handler in the wrapped errors, like "posting help".
+	action string
+	// thread reports if the command only works in a thread, the others work anywhere.
+	thread bool
+}
+
+// commandRoutes returns every command of the mentions with its handler.
+func (bot *SlackBot) commandRoutes() []commandRoute {
+	return []commandRoute{
+		{name: CommandProviders, action: "reporting provider health", handle: func(ctx context.Context, event *slackevents.AppMentionEvent, _ string) error {
+			return bot.reportProviderHealth(ctx, event)
+		}},
+		{name: CommandPrefs, action: "handling preferences", handle: bot.handlePrefs},
+		{name: CommandUsage, action: "showing usage", handle: func(ctx context.Context, event *slackevents.AppMentionEvent, _ string) error {
+			return bot.handleUsage(ctx, event)
+		}},
+		{name: CommandFind, action: "finding tracks", handle: bot.handleFind},
+		{name: CommandHelp, action: "posting help", handle: func(ctx context.Context, event *slackevents.AppMentionEvent, _ string) error {
+			return bot.handleHelp(ctx, event, nil)
+		}},
+		{name: CommandLeaderboard, action: "posting leaderboard", handle: bot.handleLeaderboard},
+		// A channel summary works anywhere, handleSummarize asks for a thread for the thread summaries itself
+		{name: CommandSummarize, action: "summarizing", handle: bot.handleSummarize},
+		{name: CommandRetryTitles, action: "retrying titles", thread: true, handle: func(ctx context.Context, event *slackevents.AppMentionEvent, _ string) error {
+			return bot.handleRetryTitles(ctx, event)
+		}},
+		{name: CommandPreview, action: "previewing thread", thread: true, handle: func(ctx context.Context, event *slackevents.AppMentionEvent, _ string) error {
+			return bot.handlePreview(ctx, event)
+		}},
+		{name: CommandPlaylist, action: "syncing playlist", thread: true, handle: func(ctx context.Context, event *slackevents.AppMentionEvent, args string) error {
+			return bot.handlePlaylist(ctx, event.Channel, event.ThreadTimeStamp, event.User, args)
+		}},
+		{name: CommandClose, action: "closing thread", thread: true, handle: bot.handleClose},
+	}
+}
+
+// notifyThreadOnly tells the user that the command only works in a thread.
+func (bot *SlackBot) notifyThreadOnly(ctx context.Context, event *slackevents.AppMentionEvent) error {
+	err := telemetry.Measure(trace.SpanFromContext(ctx), telemetry.NonThreadPostEphemeralEvent, func() error {
+		_, pErr := bot.socketClient.PostEphemeralContext(
+			ctx,
+			event.Channel,
+			event.User,
+			slack.MsgOptionText("This command is only usable in threads", false),
+		)
+
+		return pErr //nolint:wrapcheck // wrapped with the trace by the caller
+	})
+
+	return err //nolint:wrapcheck // wrapped with the trace by the caller
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCommand(t *testing.T) {
+	t.Parallel()
+
+	known := []commandType{CommandSummarize, CommandFind, CommandPrefs, CommandProviders, CommandHelp, CommandClose, CommandPlaylist}
+
+	tests := []struct {
+		name     string
+		text     string
+		wantCmd  commandType
+		wantArgs string
+		wantName string
+		wantErr  bool
+	}{
+		{name: "with arguments", text: "<@UBOT> find rick  astley", wantCmd: CommandFind, wantArgs: "rick astley"},
+		{name: "without arguments", text: "<@UBOT> find", wantCmd: CommandFind},
+		{name: "key value arguments", text: "<@UBOT> summarize format=json dedupe=title", wantCmd: CommandSummarize, wantArgs: "format=json dedupe=title"},
+		{name: "case insensitive", text: "<@UBOT> Summarize", wantCmd: CommandSummarize},
+		{name: "mention with a label", text: "<@UBOT|wapbot> summarize", wantCmd: CommandSummarize},
+		{name: "command words in the arguments", text: "<@UBOT> find providers", wantCmd: CommandFind, wantArgs: "providers"},
+		{name: "words before the command", text: "hey <@UBOT|wapbot> please summarize", wantName: "hey", wantErr: true},
+		{name: "prose with a command", text: "<@UBOT> can you help me summarize this", wantName: "can", wantErr: true},
+		{name: "prose about closing", text: "<@UBOT> we should close this thread soon", wantName: "we", wantErr: true},
+		{name: "prose about a playlist", text: "<@UBOT> is there a playlist or should I find one", wantName: "is", wantErr: true},
+		{name: "other mentions are stripped", text: "<@UBOT> find <@U123>", wantCmd: CommandFind},
+		{name: "part of another word", text: "<@UBOT> summarized, findings welcome", wantName: "summarized,", wantErr: true},
+		{name: "unknown command", text: "<@UBOT> dance now", wantName: "dance", wantErr: true},
+		{name: "bare mention", text: "<@UBOT>", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd, args, err := parseCommand(tt.text, known)
+
+			if tt.wantErr {
+				var unknown *UnknownCommandError
+				require.ErrorAs(t, err, &unknown)
+				require.ErrorIs(t, err, ErrInvalidCommandType)
+				assert.Equal(t, tt.wantName, unknown.Name)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCmd, cmd)
+			assert.Equal(t, tt.wantArgs, args)
+		})
+	}
+}
+
+func TestSlackBot_CommandRoutes_AreUnique(t *testing.T) {
+	t.Parallel()
+
+	seen := map[commandType]bool{}
+
+	for _, r := range (&SlackBot{}).commandRoutes() {
+		assert.False(t, seen[r.name], "command %s is routed twice", r.name)
+		assert.NotNil(t, r.handle)
+
+		seen[r.name] = true
+	}
+}
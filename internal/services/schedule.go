@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/internal/storage"
+	"github.com/Shikachuu/wap-bot/internal/telemetry"
+	"github.com/slack-go/slack"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ThreadSchedule configures the themed threads the bot opens on its own.
+type ThreadSchedule struct {
+	// Prompt is the text/template of the thread's parent message, rendered with promptData.
+	Prompt string
+	// Channels lists the channels where the threads are opened.
+	Channels []string
+	// Weekday and Hour (UTC) are when a new thread is opened every week.
+	Weekday time.Weekday
+	Hour    int
+	// Usergroup is the handle of the usergroup mentioned in the digests of the previous threads, empty mentions nobody.
+	Usergroup string
+	// Silent names the usergroup in the digests without notifying its members.
+	Silent bool
+}
+
+// promptData is the data the prompt template of scheduled threads is rendered with.
+type promptData struct {
+	// Date is the day of the thread in the YYYY-MM-DD format.
+	Date string
+}
+
+// ThreadScheduler opens a fresh thread every week in the scheduled channels
+// and summarizes the previous one in place at the same time.
+type ThreadScheduler struct {
+	bot      *SlackBot
+	prompt   *template.Template
+	schedule ThreadSchedule
+}
+
+// nextRun returns the first time strictly after now that is on weekday at hour:00 UTC.
+func nextRun(now time.Time, weekday time.Weekday, hour int) time.Time {
+	now = now.UTC()
+
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, time.UTC)
+	next = next.AddDate(0, 0, (int(weekday)-int(next.Weekday())+7)%7)
+
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 7)
+	}
+
+	return next
+}
+
+// renderPrompt renders the parent message of the thread opened at the given time.
+func (s *ThreadScheduler) renderPrompt(at time.Time) (string, error) {
+	var sb strings.Builder
+
+	if err := s.prompt.Execute(&sb, promptData{Date: at.UTC().Format(time.DateOnly)}); err != nil {
+		return "", fmt.Errorf("rendering prompt: %w", err)
+	}
+
+	return sb.String(), nil
+}
+
+// Run opens the scheduled threads every week until ctx gets canceled.
+func (s *ThreadScheduler) Run(ctx context.Context) {
+	for {
+		next := nextRun(time.Now(), s.schedule.Weekday, s.schedule.Hour)
+
+		slog.InfoContext(ctx, "next scheduled thread", "at", next, "channels", s.schedule.Channels)
+
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		for _, channelID := range s.schedule.Channels {
+			if err := s.rotateThread(ctx, channelID, next); err != nil {
+				slog.ErrorContext(ctx, "failed to open scheduled thread", "error", err, "channel_id", channelID)
+			}
+		}
+	}
+}
+
+// rotateThread summarizes the previous scheduled thread of a channel and opens the next one.
+//
+// A failed summary doesn't stop the new thread from being opened, the previous thread can still be summarized by hand.
+func (s *ThreadScheduler) rotateThread(bCtx context.Context, channelID string, at time.Time) error {
+	ctx, t := telemetry.Tracer.Start(bCtx, "slackbot.rotate_scheduled_thread")
+	defer t.End()
+
+	t.SetAttributes(attribute.String("slack.channel_id", channelID))
+
+	logger := slog.With("channel_id", channelID)
+
+	previous, err := s.bot.store.ScheduledThread(ctx, channelID)
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "reading previous scheduled thread", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	// The thread of this run is already open, e.g. the bot restarted right after opening it
+	if previous.PostedAt.Equal(at) {
+		return nil
+	}
+
+	if previous.ThreadTS != "" {
+		if _, err = s.bot.processThread(ctx, channelID, previous.ThreadTS, summaryOptions{mention: s.digestMention(ctx)}); err != nil {
+			_ = telemetry.WrapErrorWithTrace(t, "summarizing previous scheduled thread", err)
+
+			logger.WarnContext(ctx, "failed to summarize previous scheduled thread", "error", err, "thread_ts", previous.ThreadTS)
+		}
+	}
+
+	text, err := s.renderPrompt(at)
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "opening scheduled thread", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	var threadTS string
+
+	err = telemetry.Measure(t, telemetry.PostScheduledThreadEvent, func() error {
+		var pErr error
+
+		_, threadTS, pErr = s.bot.socketClient.PostMessageContext(ctx, channelID, slack.MsgOptionText(text, false))
+
+		return pErr //nolint:wrapcheck // wrapped with the trace below
+	})
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "posting scheduled thread", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	err = s.bot.store.SetScheduledThread(ctx, channelID, storage.ScheduledThread{ThreadTS: threadTS, PostedAt: at})
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "saving scheduled thread", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	logger.InfoContext(ctx, "opened scheduled thread", "thread_ts", threadTS)
+
+	return nil
+}
+
+// usergroupMention returns the mention of the usergroup with the given handle, a leading @ is ignored.
+//
+// Returns ErrUsergroupNotFound if none of the groups has the handle.
+func usergroupMention(groups []slack.UserGroup, handle string) (string, error) {
+	handle = strings.TrimPrefix(handle, "@")
+
+	for _, g := range groups {
+		if g.Handle == handle {
+			return "<!subteam^" + g.ID + ">", nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: @%s", ErrUsergroupNotFound, handle)
+}
+
+// digestMention returns the mention of the configured usergroup for the digest of a scheduled thread.
+//
+// A silent schedule or a usergroup that can't be resolved names the group as plain text, which doesn't notify anyone.
+func (s *ThreadScheduler) digestMention(bCtx context.Context) string {
+	if s.schedule.Usergroup == "" {
+		return ""
+	}
+
+	plain := "@" + strings.TrimPrefix(s.schedule.Usergroup, "@")
+	if s.schedule.Silent {
+		return plain
+	}
+
+	ctx, t := telemetry.Tracer.Start(bCtx, "slackbot.resolve_usergroup")
+	defer t.End()
+
+	var groups []slack.UserGroup
+
+	err := telemetry.Measure(t, telemetry.GetUserGroupsEvent, func() error {
+		var gErr error
+
+		groups, gErr = s.bot.socketClient.GetUserGroupsContext(ctx)
+
+		return gErr //nolint:wrapcheck // wrapped with the trace below
+	})
+	if err != nil {
+		_ = telemetry.WrapErrorWithTrace(t, "listing usergroups", err)
+
+		slog.WarnContext(ctx, "failed to list usergroups, the digest won't notify them", "error", err)
+
+		return plain
+	}
+
+	mention, err := usergroupMention(groups, s.schedule.Usergroup)
+	if err != nil {
+		_ = telemetry.WrapErrorWithTrace(t, "resolving usergroup", err)
+
+		slog.WarnContext(ctx, "failed to resolve usergroup, the digest won't notify them", "error", err)
+
+		return plain
+	}
+
+	return mention
+}
+
+// NewThreadScheduler creates a scheduler that opens the threads of schedule as bot.
+//
+// Returns the scheduler or an error if the prompt isn't a valid template.
+func NewThreadScheduler(bot *SlackBot, schedule ThreadSchedule) (*ThreadScheduler, error) {
+	prompt, err := template.New("prompt").Parse(schedule.Prompt)
+	if err != nil {
+		return nil, fmt.Errorf("parsing prompt: %w", err)
+	}
+
+	return &ThreadScheduler{bot: bot, prompt: prompt, schedule: schedule}, nil
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/internal/config"
+	"github.com/Shikachuu/wap-bot/internal/storage"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextRun(t *testing.T) {
+	t.Parallel()
+
+	// 2023-11-17 is a Friday
+	tests := []struct {
+		now  time.Time
+		want time.Time
+		name string
+	}{
+		{
+			name: "earlier in the week",
+			now:  time.Date(2023, 11, 14, 12, 0, 0, 0, time.UTC),
+			want: time.Date(2023, 11, 17, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "same day before the hour",
+			now:  time.Date(2023, 11, 17, 8, 59, 0, 0, time.UTC),
+			want: time.Date(2023, 11, 17, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "exactly at the hour",
+			now:  time.Date(2023, 11, 17, 9, 0, 0, 0, time.UTC),
+			want: time.Date(2023, 11, 24, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "later in the week",
+			now:  time.Date(2023, 11, 18, 1, 0, 0, 0, time.UTC),
+			want: time.Date(2023, 11, 24, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "other time zone",
+			now:  time.Date(2023, 11, 17, 10, 0, 0, 0, time.FixedZone("CET", 3600)),
+			want: time.Date(2023, 11, 24, 9, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, nextRun(tt.now, time.Friday, 9))
+		})
+	}
+}
+
+func TestThreadScheduler_RenderPrompt(t *testing.T) {
+	t.Parallel()
+
+	s, err := NewThreadScheduler(nil, ThreadSchedule{Prompt: config.DefaultScheduledThreadPrompt})
+	require.NoError(t, err)
+
+	got, err := s.renderPrompt(time.Date(2023, 11, 17, 9, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Contains(t, got, "New Music Friday* 2023-11-17")
+
+	_, err = NewThreadScheduler(nil, ThreadSchedule{Prompt: "{{.Date"})
+	require.Error(t, err)
+}
+
+func TestThreadScheduler_RotateThread_AlreadyOpened(t *testing.T) {
+	t.Parallel()
+
+	store, err := storage.NewFileStore("")
+	require.NoError(t, err)
+
+	at := time.Date(2023, 11, 17, 9, 0, 0, 0, time.UTC)
+	opened := storage.ScheduledThread{ThreadTS: "1700211600.000100", PostedAt: at}
+	require.NoError(t, store.SetScheduledThread(t.Context(), "C123", opened))
+
+	s, err := NewThreadScheduler(&SlackBot{store: store}, ThreadSchedule{Prompt: config.DefaultScheduledThreadPrompt})
+	require.NoError(t, err)
+
+	// Neither summarizes nor posts, the bot has no Slack client to do so
+	require.NoError(t, s.rotateThread(t.Context(), "C123", at))
+
+	got, err := store.ScheduledThread(t.Context(), "C123")
+	require.NoError(t, err)
+	assert.Equal(t, opened, got)
+}
+
+func TestUsergroupMention(t *testing.T) {
+	t.Parallel()
+
+	groups := []slack.UserGroup{
+		{ID: "S111", Handle: "organizers"},
+		{ID: "S222", Handle: "music-club"},
+	}
+
+	tests := []struct {
+		wantErr error
+		name    string
+		handle  string
+		want    string
+	}{
+		{name: "handle", handle: "music-club", want: "<!subteam^S222>"},
+		{name: "leading at", handle: "@music-club", want: "<!subteam^S222>"},
+		{name: "unknown", handle: "jazz", wantErr: ErrUsergroupNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := usergroupMention(groups, tt.handle)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestThreadScheduler_DigestMention_WithoutPing(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		want     string
+		schedule ThreadSchedule
+	}{
+		{name: "no usergroup", schedule: ThreadSchedule{}, want: ""},
+		{name: "silent", schedule: ThreadSchedule{Usergroup: "@music-club", Silent: true}, want: "@music-club"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := &ThreadScheduler{schedule: tt.schedule}
+
+			assert.Equal(t, tt.want, s.digestMention(t.Context()))
+		})
+	}
+}
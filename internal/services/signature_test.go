@@ -0,0 +1,203 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSigningSecret = "8f742231b10e8888abcd99yyyzzz85a5"
+
+func signedRequest(t *testing.T, secret, body string, sent time.Time) *http.Request {
+	t.Helper()
+
+	ts := strconv.FormatInt(sent.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte("v0:" + ts + ":" + body))
+
+	r := httptest.NewRequest(http.MethodPost, "/slack/events", strings.NewReader(body))
+	r.Header.Set(slackTimestampHeader, ts)
+	r.Header.Set(slackSignatureHeader, "v0="+hex.EncodeToString(mac.Sum(nil)))
+
+	return r
+}
+
+func newTestVerifier(now time.Time) *SignatureVerifier {
+	v := NewSignatureVerifier(testSigningSecret, time.Minute)
+	v.now = func() time.Time { return now }
+
+	return v
+}
+
+func TestSignatureVerifier_Verify(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_700_000_000, 0)
+
+	tests := []struct {
+		wantErr error
+		request func(t *testing.T) *http.Request
+		name    string
+	}{
+		{
+			name: "valid request",
+			request: func(t *testing.T) *http.Request {
+				t.Helper()
+				return signedRequest(t, testSigningSecret, `{"type":"event_callback"}`, now)
+			},
+		},
+		{
+			name: "missing headers",
+			request: func(t *testing.T) *http.Request {
+				t.Helper()
+				return httptest.NewRequest(http.MethodPost, "/slack/events", strings.NewReader("{}"))
+			},
+			wantErr: ErrMissingSignature,
+		},
+		{
+			name: "wrong secret",
+			request: func(t *testing.T) *http.Request {
+				t.Helper()
+				return signedRequest(t, "other-secret", "{}", now)
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "malformed timestamp",
+			request: func(t *testing.T) *http.Request {
+				t.Helper()
+				r := signedRequest(t, testSigningSecret, "{}", now)
+				r.Header.Set(slackTimestampHeader, "yesterday")
+
+				return r
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "tampered body",
+			request: func(t *testing.T) *http.Request {
+				t.Helper()
+				r := signedRequest(t, testSigningSecret, "{}", now)
+				r.Body = io.NopCloser(strings.NewReader(`{"evil":true}`))
+
+				return r
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "outside of tolerance",
+			request: func(t *testing.T) *http.Request {
+				t.Helper()
+				return signedRequest(t, testSigningSecret, "{}", now.Add(-2*time.Minute))
+			},
+			wantErr: ErrExpiredRequest,
+		},
+		{
+			name: "body over the limit",
+			request: func(t *testing.T) *http.Request {
+				t.Helper()
+				return signedRequest(t, testSigningSecret, strings.Repeat("x", maxRequestBodyBytes+1), now)
+			},
+			wantErr: ErrRequestTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newTestVerifier(now).Verify(tt.request(t))
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSignatureVerifier_RejectsReplay(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_700_000_000, 0)
+	v := newTestVerifier(now)
+
+	_, err := v.Verify(signedRequest(t, testSigningSecret, "{}", now))
+	require.NoError(t, err)
+
+	_, err = v.Verify(signedRequest(t, testSigningSecret, "{}", now))
+	require.ErrorIs(t, err, ErrReplayedRequest)
+}
+
+func TestSignatureVerifier_ForgetsExpiredSignatures(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_700_000_000, 0)
+	v := newTestVerifier(now)
+
+	for i := range 3 {
+		_, err := v.Verify(signedRequest(t, testSigningSecret, strconv.Itoa(i), now.Add(time.Duration(i)*time.Second)))
+		require.NoError(t, err)
+	}
+
+	// Only the first signature expired, the later ones are still remembered
+	v.now = func() time.Time { return now.Add(time.Minute + 500*time.Millisecond) }
+
+	_, err := v.Verify(signedRequest(t, testSigningSecret, "later", now.Add(time.Minute)))
+	require.NoError(t, err)
+
+	assert.Len(t, v.seen, 3)
+	assert.Len(t, v.expiries, 3)
+}
+
+func TestSignatureVerifier_MiddlewareRejectsLargeBody(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_700_000_000, 0)
+	called := false
+
+	h := newTestVerifier(now).Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { called = true }))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, signedRequest(t, testSigningSecret, strings.Repeat("x", maxRequestBodyBytes+1), now))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.False(t, called)
+}
+
+func TestSignatureVerifier_MiddlewareKeepsBody(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_700_000_000, 0)
+	body := `{"type":"url_verification"}`
+
+	var got string
+
+	h := newTestVerifier(now).Middleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		got = string(b)
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, signedRequest(t, testSigningSecret, body, now))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, body, got)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, signedRequest(t, "other-secret", body, now))
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
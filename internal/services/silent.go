@@ -0,0 +1,33 @@
+package services
+
+import (
+	"slices"
+)
+
+// optionSilent is the summarize command option that uploads the summary without any bot chatter.
+const optionSilent = "silent"
+
+// hasSilentOption reports if the arguments of the summarize command ask for a silent summary.
+func hasSilentOption(args string) bool {
+	return parseArgs(args).flag(optionSilent)
+}
+
+// isSilent reports if the summary of a thread in channelID is silent, either because it was requested
+// or because the channel is silent by default.
+func (bot *SlackBot) isSilent(channelID string, requested bool) bool {
+	return requested || slices.Contains(bot.silentChannels, channelID)
+}
+
+// summaryComment returns the comment of an uploaded summary, a non-empty mention is prepended to it.
+//
+// Silent summaries drop the comment of the bot, an explicitly configured mention is still posted on its own.
+func summaryComment(comment, mention string, silent bool) string {
+	switch {
+	case silent:
+		return mention
+	case mention != "":
+		return mention + " " + comment
+	default:
+		return comment
+	}
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHasSilentOption(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, hasSilentOption("silent"))
+	assert.True(t, hasSilentOption("please SILENT"))
+	assert.False(t, hasSilentOption(""))
+	assert.False(t, hasSilentOption("silently"))
+}
+
+func TestSlackBot_IsSilent(t *testing.T) {
+	t.Parallel()
+
+	bot := &SlackBot{silentChannels: []string{"C-QUIET"}}
+
+	assert.True(t, bot.isSilent("C-QUIET", false))
+	assert.True(t, bot.isSilent("C-LOUD", true))
+	assert.False(t, bot.isSilent("C-LOUD", false))
+}
+
+func TestSummaryComment(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		mention string
+		want    string
+		silent  bool
+	}{
+		{name: "default", want: "Found 2 music URLs in this thread"},
+		{name: "mention", mention: "<!subteam^S222>", want: "<!subteam^S222> Found 2 music URLs in this thread"},
+		{name: "silent", silent: true, want: ""},
+		{name: "silent with mention", mention: "<!subteam^S222>", silent: true, want: "<!subteam^S222>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, summaryComment("Found 2 music URLs in this thread", tt.mention, tt.silent))
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/Shikachuu/wap-bot/internal/telemetry"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// slashUsage is the reply to a slash command that can't be handled, the thread link stands in for the mention in the thread.
+const slashUsage = "Usage: `/wap summarize <thread link> [options]`, the link is the \"Copy link\" of a message of the thread. " +
+	"Every command of the mentions works the same way, like `/wap preview <thread link>` or `/wap leaderboard`"
+
+// threadLink parses a Slack permalink of a message, like https://team.slack.com/archives/C123/p1700000000000100,
+// the thread_ts query parameter of a reply wins over the timestamp of the message.
+//
+// Returns the channel ID and the thread timestamp of the link, or false if arg isn't a permalink.
+func threadLink(arg string) (string, string, bool) {
+	u, err := url.Parse(strings.TrimSuffix(strings.TrimPrefix(arg, "<"), ">"))
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || !strings.HasSuffix(u.Hostname(), ".slack.com") {
+		return "", "", false
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(segments) != 3 || segments[0] != "archives" {
+		return "", "", false
+	}
+
+	channelID, ts := segments[1], strings.TrimPrefix(segments[2], "p")
+	if channelID == "" || len(ts) <= 6 || ts == segments[2] {
+		return "", "", false
+	}
+
+	threadTS := ts[:len(ts)-6] + "." + ts[len(ts)-6:]
+	if parent := u.Query().Get("thread_ts"); parent != "" {
+		threadTS = parent
+	}
+
+	return channelID, threadTS, true
+}
+
+// slashMention converts a slash command to the mention it stands for, so it's handled by the same commands and argument parser.
+// The first thread link of the text is the thread of the mention, it has to be a thread of the channel of the command.
+//
+// Returns the mention or errInvalidThreadLink if the link points to another channel.
+func slashMention(cmd slack.SlashCommand) (*slackevents.AppMentionEvent, error) {
+	mention := &slackevents.AppMentionEvent{User: cmd.UserID, Channel: cmd.ChannelID}
+
+	fields := strings.Fields(cmd.Text)
+
+	for i, f := range fields {
+		channelID, threadTS, ok := threadLink(f)
+		if !ok {
+			continue
+		}
+
+		if channelID != cmd.ChannelID {
+			return nil, fmt.Errorf("%w: it points to another channel", errInvalidThreadLink)
+		}
+
+		mention.ThreadTimeStamp = threadTS
+		fields = append(fields[:i], fields[i+1:]...)
+
+		break
+	}
+
+	mention.Text = strings.Join(fields, " ")
+
+	return mention, nil
+}
+
+func (bot *SlackBot) handleSlashCommand(bCtx context.Context, logger *slog.Logger, evt *socketmode.Event) {
+	ctx, t := telemetry.Tracer.Start(bCtx, "slackbot.handle_slash_command")
+	defer t.End()
+
+	cmd, ok := evt.Data.(slack.SlashCommand)
+	if !ok {
+		_ = telemetry.WrapErrorWithTrace(t, "", errIgnoredInvalidAPI)
+
+		logger.WarnContext(ctx, "ignored invalid slash command data")
+
+		return
+	}
+
+	_ = telemetry.Measure(t, telemetry.SendACKEvent, func() error {
+		bot.socketClient.Ack(*evt.Request)
+
+		return nil
+	})
+
+	t.SetAttributes(attribute.String("user.id", cmd.UserID), attribute.String("slack.channel_id", cmd.ChannelID),
+		attribute.String("slack.command", cmd.Command))
+
+	mention, err := slashMention(cmd)
+	if err != nil || strings.TrimSpace(cmd.Text) == "" {
+		reply := slashUsage
+		if err != nil {
+			reply = fmt.Sprintf("%s\n%s", err, slashUsage)
+		}
+
+		if _, err = bot.socketClient.PostEphemeralContext(ctx, cmd.ChannelID, cmd.UserID, slack.MsgOptionText(reply, false)); err != nil {
+			_ = telemetry.WrapErrorWithTrace(t, "", errHandleEvent)
+
+			logger.ErrorContext(ctx, "failed to post slash command usage", "error", err)
+		}
+
+		return
+	}
+
+	err = telemetry.Measure(t, telemetry.HandleMentionsEvent, func() error {
+		return bot.handleMentions(ctx, mention)
+	})
+	bot.recordMentionResult(ctx, err)
+
+	if err != nil {
+		_ = telemetry.WrapErrorWithTrace(t, "", errHandleEvent)
+
+		logger.ErrorContext(ctx, "failed to handle slash command", "error", err, "command", cmd.Command)
+	}
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThreadLink(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		arg      string
+		channel  string
+		threadTS string
+		ok       bool
+	}{
+		{
+			name:     "parent message",
+			arg:      "https://wap.slack.com/archives/C123/p1700000000000100",
+			channel:  "C123",
+			threadTS: "1700000000.000100",
+			ok:       true,
+		},
+		{
+			name:     "reply",
+			arg:      "<https://wap.slack.com/archives/C123/p1700000500000200?thread_ts=1700000000.000100&cid=C123>",
+			channel:  "C123",
+			threadTS: "1700000000.000100",
+			ok:       true,
+		},
+		{name: "not slack", arg: "https://example.com/archives/C123/p1700000000000100"},
+		{name: "not a message", arg: "https://wap.slack.com/archives/C123"},
+		{name: "not a link", arg: "format=csv"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			channel, threadTS, ok := threadLink(tt.arg)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.channel, channel)
+			assert.Equal(t, tt.threadTS, threadTS)
+		})
+	}
+}
+
+func TestSlashMention(t *testing.T) {
+	t.Parallel()
+
+	mention, err := slashMention(slack.SlashCommand{
+		Command:   "/wap",
+		Text:      "summarize https://wap.slack.com/archives/C123/p1700000000000100 format=json",
+		ChannelID: "C123",
+		UserID:    "U1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &slackevents.AppMentionEvent{
+		User:            "U1",
+		Channel:         "C123",
+		Text:            "summarize format=json",
+		ThreadTimeStamp: "1700000000.000100",
+	}, mention)
+
+	mention, err = slashMention(slack.SlashCommand{Command: "/wap", Text: "leaderboard since=7d", ChannelID: "C123", UserID: "U1"})
+	require.NoError(t, err)
+	assert.Empty(t, mention.ThreadTimeStamp)
+	assert.Equal(t, "leaderboard since=7d", mention.Text)
+
+	_, err = slashMention(slack.SlashCommand{
+		Command:   "/wap",
+		Text:      "summarize https://wap.slack.com/archives/C999/p1700000000000100",
+		ChannelID: "C123",
+	})
+	require.ErrorIs(t, err, errInvalidThreadLink)
+}
@@ -0,0 +1,27 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+)
+
+// optionSort is the summarize command argument that orders the rows of the summary, like sort=title.
+const optionSort = "sort"
+
+// sortOption returns the order requested by a sort=<order> argument of the summarize command, empty if there is none.
+func sortOption(args string) (domain.SortOrder, error) {
+	value, ok := parseArgs(args).option(optionSort)
+	if !ok {
+		return "", nil
+	}
+
+	order := domain.SortOrder(strings.ToLower(value))
+	if !slices.Contains(domain.SortOrders(), order) {
+		return "", fmt.Errorf("%w: unsupported sort order %q, the supported ones are %s", errInvalidSortOption, value, optionList(domain.SortOrders()))
+	}
+
+	return order, nil
+}
@@ -0,0 +1,25 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortOption(t *testing.T) {
+	t.Parallel()
+
+	order, err := sortOption("format=csv")
+	require.NoError(t, err)
+	assert.Empty(t, order)
+
+	order, err = sortOption("SORT=Title")
+	require.NoError(t, err)
+	assert.Equal(t, domain.SortTitle, order)
+
+	_, err = sortOption("sort=random")
+	require.ErrorIs(t, err, errInvalidSortOption)
+	assert.Contains(t, err.Error(), "shared|title|artist|provider|popularity")
+}
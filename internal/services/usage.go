@@ -0,0 +1,287 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/Shikachuu/wap-bot/internal/storage"
+	"github.com/Shikachuu/wap-bot/internal/telemetry"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+// usageWindowDays is the number of days shown by the usage command, today included.
+const usageWindowDays = 30
+
+// trendingTrackLimit is the maximum number of most shared tracks listed by the usage command.
+const trendingTrackLimit = 5
+
+// sparkBars are the bars of the usage sparklines, from the lowest to the highest.
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders values as a single line of bars scaled to the highest value.
+func sparkline(values []float64) string {
+	highest := slices.Max(values)
+
+	var sb strings.Builder
+
+	for _, v := range values {
+		i := 0
+		if highest > 0 {
+			i = int(math.Round(v / highest * float64(len(sparkBars)-1)))
+		}
+
+		sb.WriteRune(sparkBars[i])
+	}
+
+	return sb.String()
+}
+
+// usageWindow spreads the daily usage over the usageWindowDays days ending with the day of now,
+// days without usage are zero.
+func usageWindow(days []storage.DailyUsage, now time.Time) []storage.DailyUsage {
+	today := now.UTC().Truncate(24 * time.Hour)
+	window := make([]storage.DailyUsage, usageWindowDays)
+
+	for i := range window {
+		window[i].Day = today.AddDate(0, 0, i-usageWindowDays+1)
+	}
+
+	for _, d := range days {
+		i := int(d.Day.Sub(window[0].Day).Hours() / 24)
+		if i >= 0 && i < usageWindowDays {
+			window[i].Usage = d.Usage
+		}
+	}
+
+	return window
+}
+
+// usageDashboard renders the usage of the last usageWindowDays days as Block Kit sections,
+// one sparkline per statistic, oldest day first.
+func usageDashboard(days []storage.DailyUsage, now time.Time) []slack.Block {
+	window := usageWindow(days, now)
+
+	header := slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType,
+		fmt.Sprintf("Bot usage, last %d days", usageWindowDays), false, false))
+	period := slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType,
+		fmt.Sprintf("%s – %s (UTC), oldest day first", window[0].Day.Format(time.DateOnly), window[len(window)-1].Day.Format(time.DateOnly)),
+		false, false))
+
+	var (
+		total    storage.Usage
+		busiest  storage.DailyUsage
+		commands = make([]float64, len(window))
+		errRates = make([]float64, len(window))
+		latency  = make([]float64, len(window))
+	)
+
+	for i, d := range window {
+		total.Commands += d.Commands
+		total.Errors += d.Errors
+		total.Summaries += d.Summaries
+		total.SummarizeTime += d.SummarizeTime
+
+		if d.Commands > busiest.Commands {
+			busiest = d
+		}
+
+		commands[i] = float64(d.Commands)
+		errRates[i] = errorRate(d.Usage)
+		latency[i] = averageSummarizeTime(d.Usage).Seconds()
+	}
+
+	if total.Commands == 0 && total.Summaries == 0 {
+		return []slack.Block{header, usageSection(fmt.Sprintf("No usage recorded in the last %d days", usageWindowDays)), period}
+	}
+
+	commandsText := fmt.Sprintf("*Commands per day*\n`%s`\n%d in total", sparkline(commands), total.Commands)
+	if busiest.Commands > 0 {
+		commandsText += fmt.Sprintf(", the busiest day was %s with %d", busiest.Day.Format(time.DateOnly), busiest.Commands)
+	}
+
+	return []slack.Block{
+		header,
+		usageSection(commandsText),
+		usageSection(fmt.Sprintf("*Error rate*\n`%s`\n%.1f%% of the commands failed", sparkline(errRates), errorRate(total)*100)),
+		usageSection(fmt.Sprintf("*Average summarize latency*\n`%s`\n%s over %d summaries",
+			sparkline(latency), averageSummarizeTime(total).Round(time.Millisecond), total.Summaries)),
+		period,
+	}
+}
+
+// trackTrends renders the most shared tracks of the channel and the ones first shared on this day in earlier years
+// as Block Kit sections, sections without tracks are left out.
+func trackTrends(trending, onThisDay []storage.TrackHistory) []slack.Block {
+	blocks := []slack.Block{}
+
+	if len(trending) > 0 {
+		var sb strings.Builder
+
+		fmt.Fprintf(&sb, "*Most shared in this channel, last %d days*", usageWindowDays)
+
+		for _, h := range trending {
+			fmt.Fprintf(&sb, "\n• <%s|%s> shared %d times, first on %s", h.URL, historyTitle(h), h.ShareCount, h.FirstSeen.Format(time.DateOnly))
+		}
+
+		blocks = append(blocks, usageSection(sb.String()))
+	}
+
+	if len(onThisDay) > 0 {
+		var sb strings.Builder
+
+		sb.WriteString("*On this day*")
+
+		for _, h := range onThisDay {
+			fmt.Fprintf(&sb, "\n• <%s|%s> was first shared in %d", h.URL, historyTitle(h), h.FirstSeen.Year())
+		}
+
+		blocks = append(blocks, usageSection(sb.String()))
+	}
+
+	return blocks
+}
+
+// historyTitle formats the title of a track as "Artist - Title", or just the title if the artist is unknown,
+// escaped for mrkdwn.
+func historyTitle(h storage.TrackHistory) string {
+	if h.Artist == "" {
+		return domain.EscapeMrkdwn(h.Title)
+	}
+
+	return domain.EscapeMrkdwn(h.Artist + " - " + h.Title)
+}
+
+// usageSection creates a section block of mrkdwn text.
+func usageSection(text string) *slack.SectionBlock {
+	return slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil)
+}
+
+// errorRate is the ratio of the failed commands, zero without commands.
+func errorRate(u storage.Usage) float64 {
+	if u.Commands == 0 {
+		return 0
+	}
+
+	return min(float64(u.Errors)/float64(u.Commands), 1)
+}
+
+// averageSummarizeTime is the average duration of a summary, zero without summaries.
+func averageSummarizeTime(u storage.Usage) time.Duration {
+	if u.Summaries == 0 {
+		return 0
+	}
+
+	return u.SummarizeTime / time.Duration(u.Summaries)
+}
+
+// recordUsage adds usage to today's statistics.
+//
+// The statistics are best-effort, a failed write only gets logged.
+func (bot *SlackBot) recordUsage(ctx context.Context, usage storage.Usage) {
+	if err := bot.store.AddUsage(ctx, time.Now(), usage); err != nil {
+		slog.WarnContext(ctx, "failed to record usage", "error", err)
+	}
+}
+
+// FlushUsage persists the usage statistics every interval until ctx gets canceled,
+// so the state file isn't rewritten for every recorded command.
+func (bot *SlackBot) FlushUsage(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := bot.store.FlushUsage(ctx); err != nil {
+				slog.WarnContext(ctx, "failed to flush usage", "error", err)
+			}
+		}
+	}
+}
+
+// SaveUsage persists the usage statistics recorded since the last flush, it's meant for the shutdown.
+func (bot *SlackBot) SaveUsage(ctx context.Context) error {
+	if err := bot.store.FlushUsage(ctx); err != nil {
+		return fmt.Errorf("flushing usage: %w", err)
+	}
+
+	return nil
+}
+
+// recordMentionResult counts a failed mention as an error, unknown commands aren't counted since they aren't commands.
+func (bot *SlackBot) recordMentionResult(ctx context.Context, err error) {
+	if err == nil || errors.Is(err, ErrInvalidCommandType) {
+		return
+	}
+
+	bot.recordUsage(ctx, storage.Usage{Errors: 1})
+}
+
+func (bot *SlackBot) handleUsage(bCtx context.Context, event *slackevents.AppMentionEvent) error {
+	ctx, t := telemetry.Tracer.Start(bCtx, "slackbot.handle_usage")
+	defer t.End()
+
+	reply := []slack.MsgOption{slack.MsgOptionText("The usage dashboard is only available to the bot admins", false)}
+
+	if slices.Contains(bot.admins, event.User) {
+		now := time.Now()
+
+		var days []storage.DailyUsage
+
+		err := telemetry.Measure(t, telemetry.GetUsageEvent, func() error {
+			var uErr error
+
+			days, uErr = bot.store.DailyUsage(ctx, now.AddDate(0, 0, -usageWindowDays+1))
+
+			return uErr //nolint:wrapcheck // wrapped with the trace below
+		})
+		if err != nil {
+			return telemetry.WrapErrorWithTrace(t, "getting usage", err) //nolint:wrapcheck // this is a function that wraps the error
+		}
+
+		var trending, onThisDay []storage.TrackHistory
+
+		err = telemetry.Measure(t, telemetry.GetTrackTrendsEvent, func() error {
+			var tErr error
+
+			trending, tErr = bot.store.TrendingTracks(ctx, event.Channel, now.AddDate(0, 0, -usageWindowDays+1), trendingTrackLimit)
+			if tErr != nil {
+				return tErr //nolint:wrapcheck // wrapped with the trace below
+			}
+
+			onThisDay, tErr = bot.store.OnThisDay(ctx, event.Channel, now)
+
+			return tErr //nolint:wrapcheck // wrapped with the trace below
+		})
+		if err != nil {
+			return telemetry.WrapErrorWithTrace(t, "getting track trends", err) //nolint:wrapcheck // this is a function that wraps the error
+		}
+
+		reply = []slack.MsgOption{
+			slack.MsgOptionText(fmt.Sprintf("Bot usage of the last %d days", usageWindowDays), false),
+			slack.MsgOptionBlocks(append(usageDashboard(days, now), trackTrends(trending, onThisDay)...)...),
+		}
+	}
+
+	_, err := bot.socketClient.PostEphemeralContext(
+		ctx,
+		event.Channel,
+		event.User,
+		append(reply, slack.MsgOptionTS(event.ThreadTimeStamp))...,
+	)
+	if err != nil {
+		return telemetry.WrapErrorWithTrace(t, "posting usage", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/internal/storage"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSparkline(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		want   string
+		values []float64
+	}{
+		{name: "scaled to the highest", values: []float64{0, 1, 2, 4}, want: "▁▃▅█"},
+		{name: "all zero", values: []float64{0, 0}, want: "▁▁"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, sparkline(tt.values))
+		})
+	}
+}
+
+func TestUsageWindow_FillsMissingDays(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, time.October, 17, 15, 0, 0, 0, time.UTC)
+
+	window := usageWindow([]storage.DailyUsage{
+		{Day: time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC), Usage: storage.Usage{Commands: 3}},
+		{Day: time.Date(2026, time.September, 18, 0, 0, 0, 0, time.UTC), Usage: storage.Usage{Commands: 1}},
+		{Day: time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC), Usage: storage.Usage{Commands: 9}},
+	}, now)
+
+	require.Len(t, window, usageWindowDays)
+	assert.Equal(t, time.Date(2026, time.September, 18, 0, 0, 0, 0, time.UTC), window[0].Day)
+	assert.Equal(t, 1, window[0].Commands)
+	assert.Equal(t, 0, window[1].Commands)
+	assert.Equal(t, 3, window[usageWindowDays-1].Commands)
+}
+
+func TestUsageDashboard(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, time.October, 17, 15, 0, 0, 0, time.UTC)
+
+	blocks := usageDashboard([]storage.DailyUsage{
+		{Day: time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC), Usage: storage.Usage{
+			Commands: 4, Errors: 1, Summaries: 2, SummarizeTime: 3 * time.Second,
+		}},
+	}, now)
+
+	require.Len(t, blocks, 5)
+
+	commands, ok := blocks[1].(*slack.SectionBlock)
+	require.True(t, ok)
+	assert.Contains(t, commands.Text.Text, "4 in total, the busiest day was 2026-10-16 with 4")
+
+	errRate, ok := blocks[2].(*slack.SectionBlock)
+	require.True(t, ok)
+	assert.Contains(t, errRate.Text.Text, "25.0% of the commands failed")
+
+	latency, ok := blocks[3].(*slack.SectionBlock)
+	require.True(t, ok)
+	assert.Contains(t, latency.Text.Text, "1.5s over 2 summaries")
+}
+
+func TestUsageDashboard_NoUsage(t *testing.T) {
+	t.Parallel()
+
+	blocks := usageDashboard(nil, time.Date(2026, time.October, 17, 15, 0, 0, 0, time.UTC))
+
+	require.Len(t, blocks, 3)
+
+	section, ok := blocks[1].(*slack.SectionBlock)
+	require.True(t, ok)
+	assert.Equal(t, "No usage recorded in the last 30 days", section.Text.Text)
+}
+
+func TestTrackTrends(t *testing.T) {
+	t.Parallel()
+
+	blocks := trackTrends(
+		[]storage.TrackHistory{{
+			FirstSeen:  time.Date(2024, time.October, 17, 20, 0, 0, 0, time.UTC),
+			Title:      "Never Gonna Give You Up",
+			Artist:     "Rick Astley",
+			URL:        "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+			ShareCount: 3,
+		}},
+		[]storage.TrackHistory{{
+			FirstSeen: time.Date(2024, time.October, 17, 20, 0, 0, 0, time.UTC),
+			Title:     "Halo",
+			URL:       "https://example.com/halo",
+		}},
+	)
+
+	require.Len(t, blocks, 2)
+
+	trending, ok := blocks[0].(*slack.SectionBlock)
+	require.True(t, ok)
+	assert.Contains(t, trending.Text.Text,
+		"<https://www.youtube.com/watch?v=dQw4w9WgXcQ|Rick Astley - Never Gonna Give You Up> shared 3 times, first on 2024-10-17")
+
+	onThisDay, ok := blocks[1].(*slack.SectionBlock)
+	require.True(t, ok)
+	assert.Contains(t, onThisDay.Text.Text, "<https://example.com/halo|Halo> was first shared in 2024")
+
+	assert.Empty(t, trackTrends(nil, nil))
+}
+
+func TestTrackTrends_EscapesTitles(t *testing.T) {
+	t.Parallel()
+
+	blocks := trackTrends(nil, []storage.TrackHistory{{Title: "Salt & Pepper> <!channel>", Artist: "A<B", URL: "https://example.com/salt"}})
+
+	require.Len(t, blocks, 1)
+
+	onThisDay, ok := blocks[0].(*slack.SectionBlock)
+	require.True(t, ok)
+	assert.Contains(t, onThisDay.Text.Text, "<https://example.com/salt|A&lt;B - Salt &amp; Pepper&gt; &lt;!channel&gt;>")
+}
+
+func TestSlackBot_SaveUsage(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	store, err := storage.NewFileStore(path)
+	require.NoError(t, err)
+
+	bot := &SlackBot{store: store}
+	bot.recordUsage(t.Context(), storage.Usage{Commands: 1})
+
+	require.NoError(t, bot.SaveUsage(t.Context()))
+
+	reopened, err := storage.NewFileStore(path)
+	require.NoError(t, err)
+
+	got, err := reopened.DailyUsage(t.Context(), time.Now().AddDate(0, 0, -1))
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, 1, got[0].Commands)
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Shikachuu/wap-bot/internal/domain"
+	"github.com/Shikachuu/wap-bot/internal/telemetry"
+	"github.com/slack-go/slack"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// userNameTTL is how long a resolved user name is reused, names rarely change but a summary lists the same posters over and over.
+const userNameTTL = time.Hour
+
+// userInfoGetter looks up a Slack user, implemented by slack.Client.
+type userInfoGetter interface {
+	GetUserInfoContext(ctx context.Context, user string) (*slack.User, error)
+}
+
+// cachedUserName is a resolved user name and when it has to be looked up again.
+type cachedUserName struct {
+	expiresAt time.Time
+	name      string
+}
+
+// UserNames resolves the names of Slack users for the summaries, caching them to avoid a users.info call for every row.
+type UserNames struct {
+	client userInfoGetter
+	now    func() time.Time
+	cache  map[string]cachedUserName
+	mu     sync.Mutex
+}
+
+var _ domain.UserResolver = (*UserNames)(nil)
+
+// NewUserNames creates a user name resolver using the given Slack client.
+func NewUserNames(client *slack.Client) *UserNames {
+	return &UserNames{client: client, now: time.Now, cache: map[string]cachedUserName{}}
+}
+
+// displayName returns the name the user is shown with in Slack, the display name if set, the real name otherwise.
+func displayName(u *slack.User) string {
+	for _, name := range []string{u.Profile.DisplayName, u.Profile.RealName, u.RealName, u.Name} {
+		if name != "" {
+			return name
+		}
+	}
+
+	return u.ID
+}
+
+// UserName returns the name of the user, from the cache if it was resolved within userNameTTL.
+func (u *UserNames) UserName(bCtx context.Context, userID string) (string, error) {
+	u.mu.Lock()
+	cached, ok := u.cache[userID]
+	u.mu.Unlock()
+
+	if ok && u.now().Before(cached.expiresAt) {
+		return cached.name, nil
+	}
+
+	ctx, t := telemetry.Tracer.Start(bCtx, "slackbot.user_name")
+	defer t.End()
+
+	t.SetAttributes(attribute.String("slack.user_id", userID))
+
+	var user *slack.User
+
+	err := telemetry.Measure(t, telemetry.GetUserInfoEvent, func() error {
+		var uErr error
+
+		user, uErr = u.client.GetUserInfoContext(ctx, userID)
+
+		return uErr //nolint:wrapcheck // wrapped with the trace below
+	})
+	if err != nil {
+		return "", telemetry.WrapErrorWithTrace(t, "getting user info", err) //nolint:wrapcheck // this is a function that wraps the error
+	}
+
+	name := displayName(user)
+
+	u.mu.Lock()
+	u.cache[userID] = cachedUserName{name: name, expiresAt: u.now().Add(userNameTTL)}
+	u.mu.Unlock()
+
+	return name, nil
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingUsers returns the listed users and counts the lookups.
+type countingUsers struct {
+	users map[string]*slack.User
+	calls int
+}
+
+func (c *countingUsers) GetUserInfoContext(_ context.Context, user string) (*slack.User, error) {
+	c.calls++
+
+	if u, ok := c.users[user]; ok {
+		return u, nil
+	}
+
+	return nil, assert.AnError
+}
+
+func TestUserNames_CachesNames(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, time.October, 17, 12, 0, 0, 0, time.UTC)
+	client := &countingUsers{users: map[string]*slack.User{
+		"U1": {ID: "U1", Name: "ada", Profile: slack.UserProfile{DisplayName: "Ada", RealName: "Ada Lovelace"}},
+		"U2": {ID: "U2", Name: "grace", Profile: slack.UserProfile{RealName: "Grace Hopper"}},
+	}}
+	names := &UserNames{client: client, now: func() time.Time { return now }, cache: map[string]cachedUserName{}}
+
+	for range 2 {
+		got, err := names.UserName(t.Context(), "U1")
+		require.NoError(t, err)
+		assert.Equal(t, "Ada", got)
+	}
+
+	assert.Equal(t, 1, client.calls)
+
+	got, err := names.UserName(t.Context(), "U2")
+	require.NoError(t, err)
+	assert.Equal(t, "Grace Hopper", got)
+
+	_, err = names.UserName(t.Context(), "U3")
+	require.ErrorIs(t, err, assert.AnError)
+
+	// Expired names are looked up again
+	now = now.Add(userNameTTL)
+
+	_, err = names.UserName(t.Context(), "U1")
+	require.NoError(t, err)
+	assert.Equal(t, 4, client.calls)
+}
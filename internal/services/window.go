@@ -0,0 +1,128 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	// optionSince and optionUntil are the arguments of the summarize command that only keep the messages
+	// posted in a time window, either relative like 7d, 2w or 36h, or absolute dates like 2006-01-02 or RFC 3339 times.
+	optionSince = "since"
+	optionUntil = "until"
+)
+
+// relativeUnits are the units of the relative times besides the ones of time.ParseDuration.
+var relativeUnits = map[byte]time.Duration{
+	'd': 24 * time.Hour,
+	'w': 7 * 24 * time.Hour,
+}
+
+// messageWindow is the time window of the messages of a summary, the zero times leave that side open.
+type messageWindow struct {
+	since time.Time
+	// until is exclusive.
+	until time.Time
+}
+
+// isZero reports if the window keeps every message.
+func (w messageWindow) isZero() bool {
+	return w.since.IsZero() && w.until.IsZero()
+}
+
+// filter returns the messages posted in the window.
+func (w messageWindow) filter(msgs []slack.Message) []slack.Message {
+	if w.isZero() {
+		return msgs
+	}
+
+	return slices.DeleteFunc(slices.Clone(msgs), func(m slack.Message) bool {
+		at := slackTimestampTime(m.Timestamp)
+
+		return (!w.since.IsZero() && at.Before(w.since)) || (!w.until.IsZero() && !at.Before(w.until))
+	})
+}
+
+// windowOption returns the message window requested by the since=<time> and until=<time> arguments of the summarize command,
+// the zero value if there are none. Relative times are counted back from now, an absolute until date includes the whole day.
+func windowOption(args string, now time.Time) (messageWindow, error) {
+	parsed := parseArgs(args)
+
+	var window messageWindow
+
+	for _, option := range []struct {
+		key string
+		at  *time.Time
+	}{
+		{key: optionSince, at: &window.since},
+		{key: optionUntil, at: &window.until},
+	} {
+		value, ok := parsed.option(option.key)
+		if !ok {
+			continue
+		}
+
+		if *option.at, ok = parseWindowTime(value, now, option.key == optionUntil); !ok {
+			return messageWindow{}, fmt.Errorf("%w: %s=%s is neither a relative time like 7d nor a date like %s",
+				errInvalidWindowOption, option.key, value, time.DateOnly)
+		}
+	}
+
+	if !window.since.IsZero() && !window.until.IsZero() && !window.since.Before(window.until) {
+		return messageWindow{}, fmt.Errorf("%w: since must be before until", errInvalidWindowOption)
+	}
+
+	return window, nil
+}
+
+// parseWindowTime parses a relative or an absolute time of a message window, end moves a date to the end of its day.
+//
+// Returns false if value is neither.
+func parseWindowTime(value string, now time.Time, end bool) (time.Time, bool) {
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return at.UTC(), true
+	}
+
+	if day, err := time.Parse(time.DateOnly, value); err == nil {
+		if end {
+			day = day.AddDate(0, 0, 1)
+		}
+
+		return day, true
+	}
+
+	ago, ok := parseRelative(strings.ToLower(value))
+	if !ok {
+		return time.Time{}, false
+	}
+
+	return now.Add(-ago).UTC(), true
+}
+
+// parseRelative parses a positive duration like 7d, 2w or 36h.
+func parseRelative(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	var (
+		d   time.Duration
+		err error
+	)
+
+	if unit, ok := relativeUnits[value[len(value)-1]]; ok {
+		var n int
+
+		n, err = strconv.Atoi(value[:len(value)-1])
+		d = time.Duration(n) * unit
+	} else {
+		d, err = time.ParseDuration(value)
+	}
+
+	return d, err == nil && d > 0
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWindowOption(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		args    string
+		want    messageWindow
+		wantErr bool
+	}{
+		{name: "none", args: "format=csv"},
+		{name: "relative days", args: "since=7d", want: messageWindow{since: now.AddDate(0, 0, -7)}},
+		{name: "relative weeks", args: "SINCE=2W", want: messageWindow{since: now.AddDate(0, 0, -14)}},
+		{name: "relative hours", args: "since=36h until=12h", want: messageWindow{since: now.Add(-36 * time.Hour), until: now.Add(-12 * time.Hour)}},
+		{
+			name: "dates",
+			args: "since=2026-03-01 until=2026-03-10",
+			want: messageWindow{since: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), until: time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)},
+		},
+		{name: "rfc 3339", args: "until=2026-03-10T08:00:00+02:00", want: messageWindow{until: time.Date(2026, 3, 10, 6, 0, 0, 0, time.UTC)}},
+		{name: "same day", args: "since=2026-03-10 until=2026-03-10", want: messageWindow{
+			since: time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), until: time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC),
+		}},
+		{name: "unknown unit", args: "since=7y", wantErr: true},
+		{name: "negative", args: "since=-7d", wantErr: true},
+		{name: "empty", args: "since=", wantErr: true},
+		{name: "reversed", args: "since=2026-03-10 until=2026-03-01", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := windowOption(tt.args, now)
+			if tt.wantErr {
+				require.ErrorIs(t, err, errInvalidWindowOption)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, tt.want.since.Equal(got.since), "since: %s", got.since)
+			assert.True(t, tt.want.until.Equal(got.until), "until: %s", got.until)
+		})
+	}
+}
+
+func TestMessageWindow_Filter(t *testing.T) {
+	t.Parallel()
+
+	msgs := []slack.Message{
+		{Msg: slack.Msg{Timestamp: "1700000000.000100"}},
+		{Msg: slack.Msg{Timestamp: "1700000100.000100"}},
+		{Msg: slack.Msg{Timestamp: "1700000200.000100"}},
+	}
+
+	assert.Equal(t, msgs, messageWindow{}.filter(msgs))
+	assert.Equal(t, msgs[1:], messageWindow{since: time.Unix(1700000050, 0)}.filter(msgs))
+	assert.Equal(t, msgs[:1], messageWindow{until: time.Unix(1700000100, 0)}.filter(msgs))
+	assert.Equal(t, msgs[1:2], messageWindow{since: time.Unix(1700000100, 0), until: time.Unix(1700000150, 0)}.filter(msgs))
+	assert.Len(t, msgs, 3)
+}
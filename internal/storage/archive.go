@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ArchivedThread is the consolidated data of a closed thread.
+type ArchivedThread struct {
+	ClosedAt  time.Time      `json:"closed_at"`
+	ChannelID string         `json:"channel_id"`
+	ThreadTS  string         `json:"thread_ts"`
+	ClosedBy  string         `json:"closed_by,omitempty"`
+	Tracks    []IndexedTrack `json:"tracks"`
+}
+
+// archiveSchemaVersion is the schema version of the archived thread files, bumped on every breaking change of ArchivedThread.
+const archiveSchemaVersion = 1
+
+// archivedThreadFile is the layout of an archived thread file, the thread stamped with its schema version.
+type archivedThreadFile struct {
+	ArchivedThread
+
+	SchemaVersion int `json:"schema_version"`
+}
+
+// ArchiveSink keeps the data of closed threads outside of Slack.
+type ArchiveSink interface {
+	// Archive stores a closed thread, archiving the same thread again replaces it.
+	Archive(ctx context.Context, thread ArchivedThread) error
+}
+
+// DirArchive is an ArchiveSink that writes every thread to its own JSON file in a directory.
+type DirArchive struct {
+	dir string
+}
+
+var _ ArchiveSink = (*DirArchive)(nil)
+
+// Archive writes the thread to <dir>/<channel ID>-<thread timestamp>.json.
+func (a *DirArchive) Archive(_ context.Context, thread ArchivedThread) error {
+	raw, err := json.MarshalIndent(archivedThreadFile{ArchivedThread: thread, SchemaVersion: archiveSchemaVersion}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding archived thread: %w", err)
+	}
+
+	return writeFileAtomic(filepath.Join(a.dir, fmt.Sprintf("%s-%s.json", thread.ChannelID, thread.ThreadTS)), raw)
+}
+
+// NewDirArchive creates an archive in dir, creating the directory if it doesn't exist yet.
+//
+// Returns the archive or an error if the directory can't be created.
+func NewDirArchive(dir string) (*DirArchive, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("creating archive directory: %w", err)
+	}
+
+	return &DirArchive{dir: dir}, nil
+}
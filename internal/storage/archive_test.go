@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirArchive_Archive(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "archive")
+
+	a, err := NewDirArchive(dir)
+	require.NoError(t, err)
+
+	thread := ArchivedThread{
+		ClosedAt:  time.Date(2023, 11, 30, 18, 0, 0, 0, time.UTC),
+		ChannelID: "C123",
+		ThreadTS:  "1700000000.000100",
+		ClosedBy:  "U123",
+		Tracks:    []IndexedTrack{{Title: "Never Gonna Give You Up", URL: "https://www.youtube.com/watch?v=dQw4w9WgXcQ"}},
+	}
+	require.NoError(t, a.Archive(t.Context(), thread))
+
+	raw, err := os.ReadFile(filepath.Join(dir, "C123-1700000000.000100.json"))
+	require.NoError(t, err)
+
+	var got ArchivedThread
+	require.NoError(t, json.Unmarshal(raw, &got))
+	assert.Equal(t, thread, got)
+	assert.Contains(t, string(raw), `"schema_version": 1`)
+	// The tracks of an anonymous archive have no attribution left, not even zero values
+	assert.NotContains(t, string(raw), "shared_at")
+	assert.NotContains(t, string(raw), "message_ts")
+	assert.NotContains(t, string(raw), "user_id")
+}
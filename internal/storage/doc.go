@@ -0,0 +1,7 @@
+/*
+Package storage persists the state of the bot that has to survive restarts, like user preferences.
+
+Every store is safe for concurrent use, the file backed store keeps the whole state in memory
+and rewrites its file atomically on every change.
+*/
+package storage
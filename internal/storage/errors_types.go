@@ -0,0 +1,10 @@
+package storage
+
+import "errors"
+
+var (
+	// ErrCorruptState returned by NewFileStore if the state file exists but can't be decoded.
+	ErrCorruptState = errors.New("corrupt state file")
+	// ErrEmptyUserID returned by the preference store if the user ID is empty.
+	ErrEmptyUserID = errors.New("empty user id")
+)
@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// FailedTitle is a link of a summarized thread whose title couldn't be resolved.
+type FailedTitle struct {
+	MessageTS string `json:"message_ts"`
+	UserID    string `json:"user_id,omitempty"`
+	URL       string `json:"url"`
+	Provider  string `json:"provider"`
+	// Kind is the kind of the failure, like rate_limited or title_not_found.
+	Kind string `json:"kind"`
+}
+
+// FailureReport is the failed titles of the last summary of a thread, picked up by the retry-titles command.
+type FailureReport struct {
+	CreatedAt time.Time     `json:"created_at"`
+	Titles    []FailedTitle `json:"titles"`
+}
+
+// FailureReportStore remembers the failed titles of the summarized threads.
+type FailureReportStore interface {
+	// SetFailureReport replaces the failure report of a thread, a report without titles removes it.
+	SetFailureReport(ctx context.Context, channelID, threadTS string, report FailureReport) error
+	// FailureReport returns the failure report of a thread, the zero value if its last summary had no failed titles.
+	FailureReport(ctx context.Context, channelID, threadTS string) (FailureReport, error)
+}
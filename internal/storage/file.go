@@ -0,0 +1,692 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// fileState is the state kept in memory, and the layout of version 1 of the state file.
+type fileState struct {
+	Users map[string]UserPreferences `json:"users"`
+	// Tracks maps channel IDs to the tracks indexed in that channel.
+	Tracks map[string][]IndexedTrack `json:"tracks,omitempty"`
+	// History maps channel IDs to the sharing history of the unique tracks of that channel, keyed by their URL.
+	History map[string]map[string]TrackHistory `json:"track_history,omitempty"`
+	// ScheduledThreads maps channel IDs to the last thread the bot opened there.
+	ScheduledThreads map[string]ScheduledThread `json:"scheduled_threads,omitempty"`
+	// ClosedThreads maps channel IDs to the closed threads of that channel, keyed by their timestamp.
+	ClosedThreads map[string]map[string]ClosedThread `json:"closed_threads,omitempty"`
+	// FailureReports maps channel IDs to the failure reports of the threads of that channel, keyed by their timestamp.
+	FailureReports map[string]map[string]FailureReport `json:"failure_reports,omitempty"`
+	// Playlists maps channel IDs to the playlists created from the threads of that channel, keyed by thread/provider.
+	Playlists map[string]map[string]ThreadPlaylist `json:"playlists,omitempty"`
+	// Summaries maps channel IDs to the last summaries of the threads of that channel, keyed by their timestamp.
+	Summaries map[string]map[string]ThreadSummary `json:"summaries,omitempty"`
+	// Usage maps days in YYYY-MM-DD format to the usage of the bot on that day.
+	Usage   map[string]Usage `json:"usage,omitempty"`
+	Version int              `json:"version"`
+}
+
+// FileStore is a Store backed by a single JSON file.
+//
+// An empty path keeps the state in memory only, which is handy for tests and for running without a volume.
+type FileStore struct {
+	// quarantine holds the records of the state file this release can't read, they are written back untouched.
+	quarantine    []QuarantinedRecord
+	compatibility Compatibility
+	state         fileState
+	path          string
+	mu            sync.RWMutex
+	// usageDirty is set when usage was added since the state was last written.
+	usageDirty bool
+}
+
+var _ Store = (*FileStore)(nil)
+
+// NewFileStore creates a store backed by the file at path, loading its state if the file already exists.
+//
+// The state file goes through a compatibility check first: files of an older layout are migrated and rewritten,
+// records this release can't read are quarantined, and a file of a newer layout is moved aside to
+// <path>.v<version>.quarantine so the store starts empty instead of overwriting it.
+//
+// Returns the store or an error if the existing file can't be read.
+func NewFileStore(path string) (*FileStore, error) {
+	s := &FileStore{
+		path: path,
+		state: fileState{
+			Version:          stateVersion,
+			Users:            map[string]UserPreferences{},
+			Tracks:           map[string][]IndexedTrack{},
+			History:          map[string]map[string]TrackHistory{},
+			ScheduledThreads: map[string]ScheduledThread{},
+			ClosedThreads:    map[string]map[string]ClosedThread{},
+			FailureReports:   map[string]map[string]FailureReport{},
+			Playlists:        map[string]map[string]ThreadPlaylist{},
+			Summaries:        map[string]map[string]ThreadSummary{},
+			Usage:            map[string]Usage{},
+		},
+	}
+
+	if path == "" {
+		return s, nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("reading state file: %w", err)
+	}
+
+	if err = s.load(raw); err != nil {
+		return nil, err
+	}
+
+	if s.state.Users == nil {
+		s.state.Users = map[string]UserPreferences{}
+	}
+
+	if s.state.Tracks == nil {
+		s.state.Tracks = map[string][]IndexedTrack{}
+	}
+
+	// State files written before the history was stored only have the indexed tracks
+	if s.state.History == nil {
+		s.state.History = trackHistories(s.state.Tracks)
+	}
+
+	if s.state.ScheduledThreads == nil {
+		s.state.ScheduledThreads = map[string]ScheduledThread{}
+	}
+
+	if s.state.ClosedThreads == nil {
+		s.state.ClosedThreads = map[string]map[string]ClosedThread{}
+	}
+
+	if s.state.FailureReports == nil {
+		s.state.FailureReports = map[string]map[string]FailureReport{}
+	}
+
+	if s.state.Playlists == nil {
+		s.state.Playlists = map[string]map[string]ThreadPlaylist{}
+	}
+
+	if s.state.Summaries == nil {
+		s.state.Summaries = map[string]map[string]ThreadSummary{}
+	}
+
+	if s.state.Usage == nil {
+		s.state.Usage = map[string]Usage{}
+	}
+
+	if s.compatibility.Migrated {
+		if err = s.persist(); err != nil {
+			return nil, fmt.Errorf("migrating state file: %w", err)
+		}
+	}
+
+	return s, nil
+}
+
+// load decodes the raw state file according to the layout version it was written with.
+func (s *FileStore) load(raw []byte) error {
+	var header struct {
+		Version int `json:"version"`
+	}
+
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return fmt.Errorf("%w: %w", ErrCorruptState, err)
+	}
+
+	s.compatibility.FileVersion = header.Version
+
+	switch {
+	case header.Version > stateVersion:
+		quarantineFile := fmt.Sprintf("%s.v%d.quarantine", s.path, header.Version)
+		if err := os.Rename(s.path, quarantineFile); err != nil {
+			return fmt.Errorf("quarantining state file: %w", err)
+		}
+
+		s.compatibility.QuarantineFile = quarantineFile
+	case header.Version < stateVersion:
+		// Version 1 files, and the files written before the version was stored, hold the records as is
+		if err := json.Unmarshal(raw, &s.state); err != nil {
+			return fmt.Errorf("%w: %w", ErrCorruptState, err)
+		}
+
+		s.state.Version = stateVersion
+		s.compatibility.Migrated = true
+	default:
+		state, quarantine, err := decodeState(raw)
+		if err != nil {
+			return err
+		}
+
+		s.state, s.quarantine = state, quarantine
+		s.compatibility.Quarantined = len(quarantine)
+	}
+
+	return nil
+}
+
+// Compatibility returns the outcome of the compatibility check the state file went through when the store was created.
+func (s *FileStore) Compatibility() Compatibility {
+	return s.compatibility
+}
+
+// UserPreferences returns the preferences of a user, the zero value if the user hasn't set any.
+func (s *FileStore) UserPreferences(_ context.Context, userID string) (UserPreferences, error) {
+	if userID == "" {
+		return UserPreferences{}, ErrEmptyUserID
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.state.Users[userID], nil
+}
+
+// SetUserPreferences replaces the preferences of a user and persists the state.
+func (s *FileStore) SetUserPreferences(_ context.Context, userID string, prefs UserPreferences) error {
+	if userID == "" {
+		return ErrEmptyUserID
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.state.Users[userID]
+	s.state.Users[userID] = prefs
+
+	if err := s.persist(); err != nil {
+		// Keep the in-memory state in sync with the file
+		if existed {
+			s.state.Users[userID] = previous
+		} else {
+			delete(s.state.Users, userID)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// IndexTracks adds the tracks to the index of a channel and persists the state,
+// tracks of the same message with the same URL replace the indexed ones.
+//
+// Only the newly indexed tracks count as a share in the history, so summarizing a thread again doesn't inflate it.
+func (s *FileStore) IndexTracks(_ context.Context, channelID string, tracks []IndexedTrack) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, previousHistory := s.state.Tracks[channelID], s.state.History[channelID]
+	indexed, history := slices.Clone(previous), maps.Clone(previousHistory)
+
+	if history == nil {
+		history = map[string]TrackHistory{}
+	}
+
+	positions := trackPositions(indexed)
+
+	for _, track := range tracks {
+		track.ChannelID = channelID
+
+		i, ok := positions[track.key()]
+		if !ok {
+			positions[track.key()] = len(indexed)
+			indexed = append(indexed, track)
+			history[track.URL] = history[track.URL].record(track)
+
+			continue
+		}
+
+		indexed[i] = track
+	}
+
+	s.state.Tracks[channelID], s.state.History[channelID] = indexed, history
+
+	if err := s.persist(); err != nil {
+		// Keep the in-memory state in sync with the file
+		s.state.Tracks[channelID], s.state.History[channelID] = previous, previousHistory
+
+		return err
+	}
+
+	return nil
+}
+
+// trackPositions maps the key of every track to its position in tracks.
+func trackPositions(tracks []IndexedTrack) map[string]int {
+	positions := make(map[string]int, len(tracks))
+
+	for i, t := range tracks {
+		positions[t.key()] = i
+	}
+
+	return positions
+}
+
+// SearchTracks returns at most limit tracks of a channel whose artist and title fuzzy matches query,
+// best matches first, equally good matches are ordered by the time they were shared, newest first.
+func (s *FileStore) SearchTracks(_ context.Context, channelID, query string, limit int) ([]IndexedTrack, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	type match struct {
+		track IndexedTrack
+		score int
+	}
+
+	matches := []match{}
+
+	for _, t := range s.state.Tracks[channelID] {
+		if score, ok := matchScore(query, t.Artist+" "+t.Title); ok {
+			matches = append(matches, match{track: t, score: score})
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a, b match) int {
+		if a.score != b.score {
+			return cmp.Compare(b.score, a.score)
+		}
+
+		return b.track.SharedAt.Compare(a.track.SharedAt)
+	})
+
+	found := make([]IndexedTrack, 0, min(limit, len(matches)))
+	for _, m := range matches[:min(limit, len(matches))] {
+		found = append(found, m.track)
+	}
+
+	return found, nil
+}
+
+// ThreadTracks returns the indexed tracks of a thread in the order of their messages.
+func (s *FileStore) ThreadTracks(_ context.Context, channelID, threadTS string) ([]IndexedTrack, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tracks := []IndexedTrack{}
+
+	for _, t := range s.state.Tracks[channelID] {
+		if t.ThreadTS == threadTS {
+			tracks = append(tracks, t)
+		}
+	}
+
+	// Slack timestamps have a fixed width, so they order like the messages
+	slices.SortStableFunc(tracks, func(a, b IndexedTrack) int { return cmp.Compare(a.MessageTS, b.MessageTS) })
+
+	return tracks, nil
+}
+
+// TrendingTracks returns at most limit tracks of a channel last shared since from, most shared first,
+// equally shared tracks are ordered by their last share, newest first.
+func (s *FileStore) TrendingTracks(_ context.Context, channelID string, from time.Time, limit int) ([]TrackHistory, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	trending := []TrackHistory{}
+
+	for _, h := range s.state.History[channelID] {
+		if !h.LastSeen.Before(from) {
+			trending = append(trending, h)
+		}
+	}
+
+	slices.SortFunc(trending, byShareCount)
+
+	return trending[:min(limit, len(trending))], nil
+}
+
+// OnThisDay returns the tracks of a channel first shared on the month and day of day in an earlier year, oldest first.
+func (s *FileStore) OnThisDay(_ context.Context, channelID string, day time.Time) ([]TrackHistory, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	found := []TrackHistory{}
+
+	for _, h := range s.state.History[channelID] {
+		if h.FirstSeen.UTC().Year() < day.UTC().Year() && sameDayOfYear(h.FirstSeen, day) {
+			found = append(found, h)
+		}
+	}
+
+	slices.SortFunc(found, func(a, b TrackHistory) int {
+		return cmp.Or(a.FirstSeen.Compare(b.FirstSeen), cmp.Compare(a.URL, b.URL))
+	})
+
+	return found, nil
+}
+
+// TopPosters returns at most limit users of a channel who shared the most unique tracks in [from, until), see RankPosters,
+// zero times leave that end of the window open.
+func (s *FileStore) TopPosters(_ context.Context, channelID string, from, until time.Time, limit int) ([]PosterStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tracks := []IndexedTrack{}
+
+	for _, t := range s.state.Tracks[channelID] {
+		if t.SharedAt.Before(from) || (!until.IsZero() && !t.SharedAt.Before(until)) {
+			continue
+		}
+
+		tracks = append(tracks, t)
+	}
+
+	posters := RankPosters(tracks)
+
+	return posters[:min(limit, len(posters))], nil
+}
+
+// ScheduledThread returns the last scheduled thread of a channel, the zero value if there is none.
+func (s *FileStore) ScheduledThread(_ context.Context, channelID string) (ScheduledThread, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.state.ScheduledThreads[channelID], nil
+}
+
+// SetScheduledThread replaces the last scheduled thread of a channel and persists the state.
+func (s *FileStore) SetScheduledThread(_ context.Context, channelID string, thread ScheduledThread) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.state.ScheduledThreads[channelID]
+	s.state.ScheduledThreads[channelID] = thread
+
+	if err := s.persist(); err != nil {
+		// Keep the in-memory state in sync with the file
+		if existed {
+			s.state.ScheduledThreads[channelID] = previous
+		} else {
+			delete(s.state.ScheduledThreads, channelID)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// CloseThread marks a thread of a channel as closed and persists the state.
+func (s *FileStore) CloseThread(_ context.Context, channelID, threadTS string, thread ClosedThread) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	closed, ok := s.state.ClosedThreads[channelID]
+	if !ok {
+		closed = map[string]ClosedThread{}
+		s.state.ClosedThreads[channelID] = closed
+	}
+
+	previous, existed := closed[threadTS]
+	closed[threadTS] = thread
+
+	if err := s.persist(); err != nil {
+		// Keep the in-memory state in sync with the file
+		if existed {
+			closed[threadTS] = previous
+		} else {
+			delete(closed, threadTS)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// ClosedThread returns how a thread of a channel was closed and whether it's closed at all.
+func (s *FileStore) ClosedThread(_ context.Context, channelID, threadTS string) (ClosedThread, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	thread, ok := s.state.ClosedThreads[channelID][threadTS]
+
+	return thread, ok, nil
+}
+
+// SetFailureReport replaces the failure report of a thread and persists the state,
+// a report without titles removes the previous one.
+func (s *FileStore) SetFailureReport(_ context.Context, channelID, threadTS string, report FailureReport) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reports, ok := s.state.FailureReports[channelID]
+	if !ok {
+		reports = map[string]FailureReport{}
+		s.state.FailureReports[channelID] = reports
+	}
+
+	previous, existed := reports[threadTS]
+	if !existed && len(report.Titles) == 0 {
+		return nil
+	}
+
+	if len(report.Titles) == 0 {
+		delete(reports, threadTS)
+	} else {
+		reports[threadTS] = report
+	}
+
+	if err := s.persist(); err != nil {
+		// Keep the in-memory state in sync with the file
+		if existed {
+			reports[threadTS] = previous
+		} else {
+			delete(reports, threadTS)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// FailureReport returns the failure report of a thread, the zero value if its last summary had no failed titles.
+func (s *FileStore) FailureReport(_ context.Context, channelID, threadTS string) (FailureReport, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.state.FailureReports[channelID][threadTS], nil
+}
+
+// SetThreadPlaylist stores the playlist created from a thread on provider and persists the state.
+func (s *FileStore) SetThreadPlaylist(_ context.Context, channelID, threadTS, provider string, playlist ThreadPlaylist) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	playlists, ok := s.state.Playlists[channelID]
+	if !ok {
+		playlists = map[string]ThreadPlaylist{}
+		s.state.Playlists[channelID] = playlists
+	}
+
+	key := playlistKey(threadTS, provider)
+	previous, existed := playlists[key]
+	playlists[key] = playlist
+
+	if err := s.persist(); err != nil {
+		// Keep the in-memory state in sync with the file
+		if existed {
+			playlists[key] = previous
+		} else {
+			delete(playlists, key)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// ThreadPlaylist returns the playlist created from a thread on provider, false if there is none.
+func (s *FileStore) ThreadPlaylist(_ context.Context, channelID, threadTS, provider string) (ThreadPlaylist, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	playlist, ok := s.state.Playlists[channelID][playlistKey(threadTS, provider)]
+
+	return playlist, ok, nil
+}
+
+// SetThreadSummary replaces the last summary of a thread and persists the state.
+func (s *FileStore) SetThreadSummary(_ context.Context, channelID, threadTS string, summary ThreadSummary) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	summaries, ok := s.state.Summaries[channelID]
+	if !ok {
+		summaries = map[string]ThreadSummary{}
+		s.state.Summaries[channelID] = summaries
+	}
+
+	previous, existed := summaries[threadTS]
+	summaries[threadTS] = summary
+
+	if err := s.persist(); err != nil {
+		// Keep the in-memory state in sync with the file
+		if existed {
+			summaries[threadTS] = previous
+		} else {
+			delete(summaries, threadTS)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// ThreadSummary returns the last summary of a thread, false if it wasn't summarized yet.
+func (s *FileStore) ThreadSummary(_ context.Context, channelID, threadTS string) (ThreadSummary, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	summary, ok := s.state.Summaries[channelID][threadTS]
+
+	return summary, ok, nil
+}
+
+// AddUsage adds usage to the statistics of the day of at, the statistics older than the retention are dropped on the way.
+//
+// The statistics are only kept in memory, FlushUsage or any other write persists them.
+func (s *FileStore) AddUsage(_ context.Context, at time.Time, usage Usage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	day := at.UTC().Format(usageDayLayout)
+	s.state.Usage[day] = s.state.Usage[day].add(usage)
+
+	cutoff := at.UTC().Add(-usageRetention).Format(usageDayLayout)
+	maps.DeleteFunc(s.state.Usage, func(d string, _ Usage) bool { return d < cutoff })
+
+	s.usageDirty = true
+
+	return nil
+}
+
+// FlushUsage persists the state if usage was added since it was last written.
+func (s *FileStore) FlushUsage(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.usageDirty {
+		return nil
+	}
+
+	return s.persist()
+}
+
+// DailyUsage returns the statistics of the days since the day of from, oldest first, days without usage are left out.
+func (s *FileStore) DailyUsage(_ context.Context, from time.Time) ([]DailyUsage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	first := from.UTC().Format(usageDayLayout)
+	days := []DailyUsage{}
+
+	for _, d := range slices.Sorted(maps.Keys(s.state.Usage)) {
+		if d < first {
+			continue
+		}
+
+		day, err := time.Parse(usageDayLayout, d)
+		if err != nil {
+			return nil, fmt.Errorf("%w: usage day %q: %w", ErrCorruptState, d, err)
+		}
+
+		days = append(days, DailyUsage{Day: day, Usage: s.state.Usage[d]})
+	}
+
+	return days, nil
+}
+
+// persist writes the state file atomically. The caller must hold the write lock.
+func (s *FileStore) persist() error {
+	if s.path == "" {
+		return nil
+	}
+
+	disk, err := encodeState(s.state, s.quarantine)
+	if err != nil {
+		return err
+	}
+
+	raw, err := json.MarshalIndent(disk, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding state: %w", err)
+	}
+
+	if err := writeFileAtomic(s.path, raw); err != nil {
+		return err
+	}
+
+	// The usage is part of the state, it's written along with every other change
+	s.usageDirty = false
+
+	return nil
+}
+
+// writeFileAtomic writes raw to a temporary file and renames it over path,
+// so a crash never leaves a half written file behind.
+func writeFileAtomic(path string, raw []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating temporary file: %w", err)
+	}
+
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err = tmp.Write(raw); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("writing temporary file: %w", err)
+	}
+
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("closing temporary file: %w", err)
+	}
+
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("replacing file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,645 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore_PersistsAcrossRestarts(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	s, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	require.NoError(t, s.SetUserPreferences(t.Context(), "U123", UserPreferences{Format: "json"}))
+
+	reopened, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	got, err := reopened.UserPreferences(t.Context(), "U123")
+	require.NoError(t, err)
+	assert.Equal(t, UserPreferences{Format: "json"}, got)
+
+	got, err = reopened.UserPreferences(t.Context(), "U456")
+	require.NoError(t, err)
+	assert.Equal(t, UserPreferences{}, got)
+}
+
+func TestFileStore_InMemory(t *testing.T) {
+	t.Parallel()
+
+	s, err := NewFileStore("")
+	require.NoError(t, err)
+
+	require.NoError(t, s.SetUserPreferences(t.Context(), "U123", UserPreferences{Format: "csv"}))
+
+	got, err := s.UserPreferences(t.Context(), "U123")
+	require.NoError(t, err)
+	assert.Equal(t, "csv", got.Format)
+
+	require.ErrorIs(t, s.SetUserPreferences(t.Context(), "", UserPreferences{}), ErrEmptyUserID)
+}
+
+func TestNewFileStore_CorruptState(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(path, []byte("{not json"), 0o600))
+
+	_, err := NewFileStore(path)
+	require.ErrorIs(t, err, ErrCorruptState)
+}
+
+func TestFileStore_FailedWriteKeepsPreviousState(t *testing.T) {
+	t.Parallel()
+
+	s, err := NewFileStore(filepath.Join(t.TempDir(), "missing-dir", "state.json"))
+	require.NoError(t, err)
+
+	require.Error(t, s.SetUserPreferences(t.Context(), "U123", UserPreferences{Format: "json"}))
+
+	got, err := s.UserPreferences(t.Context(), "U123")
+	require.NoError(t, err)
+	assert.Equal(t, UserPreferences{}, got)
+}
+
+func TestFileStore_IndexAndSearchTracks(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	s, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	older := IndexedTrack{
+		SharedAt:  time.Unix(1_700_000_000, 0).UTC(),
+		ThreadTS:  "1700000000.000100",
+		MessageTS: "1700000000.000200",
+		Title:     "Never Gonna Give You Up",
+		Artist:    "Rick Astley",
+		URL:       "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		Provider:  "youtube",
+	}
+	newer := older
+	newer.SharedAt = older.SharedAt.Add(time.Hour)
+	newer.MessageTS = "1700003600.000100"
+	other := IndexedTrack{MessageTS: "1700000000.000300", Title: "Halo", Artist: "Beyoncé", URL: "https://example.com/halo"}
+
+	require.NoError(t, s.IndexTracks(t.Context(), "C1", []IndexedTrack{older, other}))
+	// Re-indexing the same message doesn't duplicate it
+	require.NoError(t, s.IndexTracks(t.Context(), "C1", []IndexedTrack{older, newer}))
+	require.NoError(t, s.IndexTracks(t.Context(), "C2", []IndexedTrack{other}))
+
+	reopened, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	got, err := reopened.SearchTracks(t.Context(), "C1", "astley never", 5)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, newer.MessageTS, got[0].MessageTS)
+	assert.Equal(t, "C1", got[0].ChannelID)
+	assert.Equal(t, older.MessageTS, got[1].MessageTS)
+
+	got, err = reopened.SearchTracks(t.Context(), "C1", "astley", 1)
+	require.NoError(t, err)
+	assert.Len(t, got, 1)
+
+	got, err = reopened.SearchTracks(t.Context(), "C2", "astley", 5)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestFileStore_IndexTracks_ReplacesIndexed(t *testing.T) {
+	t.Parallel()
+
+	s, err := NewFileStore("")
+	require.NoError(t, err)
+
+	tracks := make([]IndexedTrack, 0, 3)
+	for i := range 3 {
+		tracks = append(tracks, IndexedTrack{
+			ThreadTS:  "1700000000.000100",
+			MessageTS: fmt.Sprintf("1700000000.%06d", 200+i),
+			URL:       fmt.Sprintf("https://youtu.be/%d", i),
+			Title:     "Song",
+		})
+	}
+
+	require.NoError(t, s.IndexTracks(t.Context(), "C1", tracks))
+
+	renamed, again := tracks[1], tracks[2]
+	renamed.Title, again.Title = "Renamed", "Renamed twice"
+	repeated := again
+	repeated.Title = "Last wins"
+
+	// Tracks indexed before and repeated in the same batch are replaced, not added
+	require.NoError(t, s.IndexTracks(t.Context(), "C1", []IndexedTrack{renamed, again, repeated}))
+
+	got, err := s.ThreadTracks(t.Context(), "C1", "1700000000.000100")
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	assert.Equal(t, []string{"Song", "Renamed", "Last wins"}, []string{got[0].Title, got[1].Title, got[2].Title})
+}
+
+func BenchmarkFileStore_IndexTracks(b *testing.B) {
+	s, err := NewFileStore("")
+	require.NoError(b, err)
+
+	tracks := make([]IndexedTrack, 0, 5000)
+	for i := range cap(tracks) {
+		tracks = append(tracks, IndexedTrack{MessageTS: fmt.Sprintf("1700000000.%06d", i), URL: "https://youtu.be/dQw4w9WgXcQ"})
+	}
+
+	require.NoError(b, s.IndexTracks(b.Context(), "C1", tracks))
+
+	for b.Loop() {
+		require.NoError(b, s.IndexTracks(b.Context(), "C1", tracks))
+	}
+}
+
+func TestFileStore_TrackHistory(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	s, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	first := IndexedTrack{
+		SharedAt:  time.Date(2024, time.October, 17, 20, 0, 0, 0, time.UTC),
+		MessageTS: "1729195200.000100",
+		Title:     "Never Gonna Give You Up",
+		Artist:    "Rick Astley",
+		URL:       "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		Provider:  "youtube",
+	}
+	again := first
+	again.SharedAt = time.Date(2026, time.October, 10, 9, 0, 0, 0, time.UTC)
+	again.MessageTS = "1791622800.000100"
+	other := IndexedTrack{
+		SharedAt:  time.Date(2026, time.October, 12, 9, 0, 0, 0, time.UTC),
+		MessageTS: "1791795600.000100",
+		Title:     "Halo",
+		URL:       "https://example.com/halo",
+	}
+
+	require.NoError(t, s.IndexTracks(t.Context(), "C1", []IndexedTrack{first}))
+	require.NoError(t, s.IndexTracks(t.Context(), "C1", []IndexedTrack{again, other}))
+	// Summarizing a thread again doesn't count its tracks twice
+	require.NoError(t, s.IndexTracks(t.Context(), "C1", []IndexedTrack{again}))
+
+	reopened, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	now := time.Date(2026, time.October, 17, 12, 0, 0, 0, time.UTC)
+
+	trending, err := reopened.TrendingTracks(t.Context(), "C1", now.AddDate(0, 0, -30), 5)
+	require.NoError(t, err)
+	require.Len(t, trending, 2)
+	assert.Equal(t, TrackHistory{
+		FirstSeen:  first.SharedAt,
+		LastSeen:   again.SharedAt,
+		Title:      first.Title,
+		Artist:     first.Artist,
+		URL:        first.URL,
+		Provider:   first.Provider,
+		ShareCount: 2,
+	}, trending[0])
+	assert.Equal(t, other.URL, trending[1].URL)
+
+	trending, err = reopened.TrendingTracks(t.Context(), "C1", now.AddDate(0, 0, -4), 5)
+	require.NoError(t, err)
+	assert.Empty(t, trending)
+
+	onThisDay, err := reopened.OnThisDay(t.Context(), "C1", now)
+	require.NoError(t, err)
+	require.Len(t, onThisDay, 1)
+	assert.Equal(t, first.URL, onThisDay[0].URL)
+
+	onThisDay, err = reopened.OnThisDay(t.Context(), "C2", now)
+	require.NoError(t, err)
+	assert.Empty(t, onThisDay)
+}
+
+func TestNewFileStore_RebuildsTrackHistory(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version":2,"tracks":{"C1":[
+		{"v":1,"record":{"shared_at":"2026-10-12T09:00:00Z","message_ts":"2","url":"https://example.com/halo","title":"Halo"}},
+		{"v":1,"record":{"shared_at":"2024-10-17T20:00:00Z","message_ts":"1","url":"https://example.com/halo","title":"Halo"}}
+	]}}`), 0o600))
+
+	s, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	got, err := s.TrendingTracks(t.Context(), "C1", time.Time{}, 5)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, 2, got[0].ShareCount)
+	assert.Equal(t, time.Date(2024, time.October, 17, 20, 0, 0, 0, time.UTC), got[0].FirstSeen)
+	assert.Equal(t, time.Date(2026, time.October, 12, 9, 0, 0, 0, time.UTC), got[0].LastSeen)
+}
+
+func TestFileStore_TopPosters(t *testing.T) {
+	t.Parallel()
+
+	s, err := NewFileStore(filepath.Join(t.TempDir(), "state.json"))
+	require.NoError(t, err)
+
+	day := func(d int) time.Time { return time.Date(2026, time.October, d, 9, 0, 0, 0, time.UTC) }
+
+	require.NoError(t, s.IndexTracks(t.Context(), "C1", []IndexedTrack{
+		{SharedAt: day(10), MessageTS: "1", UserID: "U1", URL: "https://example.com/halo"},
+		// Sharing the same track again doesn't count
+		{SharedAt: day(11), MessageTS: "2", UserID: "U1", URL: "https://example.com/halo"},
+		{SharedAt: day(12), MessageTS: "3", UserID: "U2", URL: "https://example.com/halo"},
+		{SharedAt: day(13), MessageTS: "4", UserID: "U2", URL: "https://example.com/teardrop"},
+		{SharedAt: day(14), MessageTS: "5", UserID: "U3", URL: "https://example.com/angel"},
+		{SharedAt: day(15), MessageTS: "6", URL: "https://example.com/unknown-poster"},
+	}))
+
+	got, err := s.TopPosters(t.Context(), "C1", time.Time{}, time.Time{}, 5)
+	require.NoError(t, err)
+	assert.Equal(t, []PosterStats{
+		{UserID: "U2", UniqueTracks: 2, LastShared: day(13)},
+		{UserID: "U3", UniqueTracks: 1, LastShared: day(14)},
+		{UserID: "U1", UniqueTracks: 1, LastShared: day(11)},
+	}, got)
+
+	got, err = s.TopPosters(t.Context(), "C1", day(11), day(14), 1)
+	require.NoError(t, err)
+	assert.Equal(t, []PosterStats{{UserID: "U2", UniqueTracks: 2, LastShared: day(13)}}, got)
+
+	got, err = s.TopPosters(t.Context(), "C2", time.Time{}, time.Time{}, 5)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestFileStore_ScheduledThreads(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	s, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	got, err := s.ScheduledThread(t.Context(), "C123")
+	require.NoError(t, err)
+	assert.Zero(t, got)
+
+	thread := ScheduledThread{ThreadTS: "1700000000.000100", PostedAt: time.Date(2023, 11, 17, 9, 0, 0, 0, time.UTC)}
+	require.NoError(t, s.SetScheduledThread(t.Context(), "C123", thread))
+
+	reopened, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	got, err = reopened.ScheduledThread(t.Context(), "C123")
+	require.NoError(t, err)
+	assert.Equal(t, thread, got)
+}
+
+func TestFileStore_ClosedThreads(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	s, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	_, closed, err := s.ClosedThread(t.Context(), "C123", "1700000000.000100")
+	require.NoError(t, err)
+	assert.False(t, closed)
+
+	thread := ClosedThread{ClosedAt: time.Date(2023, 11, 30, 18, 0, 0, 0, time.UTC), ClosedBy: "U123"}
+	require.NoError(t, s.CloseThread(t.Context(), "C123", "1700000000.000100", thread))
+
+	reopened, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	got, closed, err := reopened.ClosedThread(t.Context(), "C123", "1700000000.000100")
+	require.NoError(t, err)
+	assert.True(t, closed)
+	assert.Equal(t, thread, got)
+
+	// Other channels are not affected
+	_, closed, err = reopened.ClosedThread(t.Context(), "C456", "1700000000.000100")
+	require.NoError(t, err)
+	assert.False(t, closed)
+}
+
+func TestFileStore_FailureReports(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	s, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	require.NoError(t, s.IndexTracks(t.Context(), "C1", []IndexedTrack{
+		{ThreadTS: "100", MessageTS: "102", URL: "https://example.com/b", Title: "B"},
+		{ThreadTS: "100", MessageTS: "101", URL: "https://example.com/a", Title: "A"},
+		{ThreadTS: "200", MessageTS: "201", URL: "https://example.com/c", Title: "C"},
+	}))
+
+	report := FailureReport{
+		CreatedAt: time.Date(2026, time.October, 17, 12, 0, 0, 0, time.UTC),
+		Titles:    []FailedTitle{{MessageTS: "103", URL: "https://example.com/d", Provider: "youtube", Kind: "timeout"}},
+	}
+	require.NoError(t, s.SetFailureReport(t.Context(), "C1", "100", report))
+
+	reopened, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	got, err := reopened.FailureReport(t.Context(), "C1", "100")
+	require.NoError(t, err)
+	assert.Equal(t, report, got)
+
+	tracks, err := reopened.ThreadTracks(t.Context(), "C1", "100")
+	require.NoError(t, err)
+	require.Len(t, tracks, 2)
+	assert.Equal(t, "A", tracks[0].Title)
+	assert.Equal(t, "B", tracks[1].Title)
+
+	// An empty report clears the previous one
+	require.NoError(t, reopened.SetFailureReport(t.Context(), "C1", "100", FailureReport{}))
+
+	got, err = reopened.FailureReport(t.Context(), "C1", "100")
+	require.NoError(t, err)
+	assert.Empty(t, got.Titles)
+}
+
+func TestFileStore_ThreadPlaylists(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	s, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	_, ok, err := s.ThreadPlaylist(t.Context(), "C1", "100", "spotify")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	playlist := ThreadPlaylist{
+		UpdatedAt: time.Date(2026, time.October, 17, 12, 0, 0, 0, time.UTC),
+		ID:        "abc",
+		URL:       "https://open.spotify.com/playlist/abc",
+	}
+	require.NoError(t, s.SetThreadPlaylist(t.Context(), "C1", "100", "spotify", playlist))
+
+	reopened, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	got, ok, err := reopened.ThreadPlaylist(t.Context(), "C1", "100", "spotify")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, playlist, got)
+
+	// Playlists are kept per provider and thread
+	_, ok, err = reopened.ThreadPlaylist(t.Context(), "C1", "100", "youtube")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, ok, err = reopened.ThreadPlaylist(t.Context(), "C1", "200", "spotify")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestFileStore_ThreadSummaries(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	s, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	_, ok, err := s.ThreadSummary(t.Context(), "C1", "100")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	summary := ThreadSummary{
+		SummarizedAt:  time.Date(2026, time.October, 17, 12, 0, 0, 0, time.UTC),
+		LastMessageTS: "100.500",
+		FileID:        "F1",
+		MessageTSs:    []string{"100.600"},
+	}
+	require.NoError(t, s.SetThreadSummary(t.Context(), "C1", "100", summary))
+
+	reopened, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	got, ok, err := reopened.ThreadSummary(t.Context(), "C1", "100")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, summary, got)
+
+	_, ok, err = reopened.ThreadSummary(t.Context(), "C2", "100")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestFileStore_Usage(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	s, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	day := time.Date(2026, time.October, 1, 23, 0, 0, 0, time.UTC)
+
+	require.NoError(t, s.AddUsage(t.Context(), day.AddDate(0, 0, -100), Usage{Commands: 7}))
+	require.NoError(t, s.AddUsage(t.Context(), day.AddDate(0, 0, -1), Usage{Commands: 1, Errors: 1}))
+	require.NoError(t, s.AddUsage(t.Context(), day, Usage{Commands: 1}))
+	require.NoError(t, s.AddUsage(t.Context(), day, Usage{Commands: 1, Summaries: 1, SummarizeTime: time.Second}))
+	require.NoError(t, s.FlushUsage(t.Context()))
+
+	reopened, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	got, err := reopened.DailyUsage(t.Context(), day.AddDate(0, 0, -200))
+	require.NoError(t, err)
+
+	// The usage of 100 days ago is past the retention
+	assert.Equal(t, []DailyUsage{
+		{Day: time.Date(2026, time.September, 30, 0, 0, 0, 0, time.UTC), Usage: Usage{Commands: 1, Errors: 1}},
+		{Day: time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC), Usage: Usage{Commands: 2, Summaries: 1, SummarizeTime: time.Second}},
+	}, got)
+
+	got, err = reopened.DailyUsage(t.Context(), day)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+}
+
+func TestFileStore_FlushUsage(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	s, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	day := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+
+	// Nothing to write yet, the state file isn't created
+	require.NoError(t, s.FlushUsage(t.Context()))
+	assert.NoFileExists(t, path)
+
+	require.NoError(t, s.AddUsage(t.Context(), day, Usage{Commands: 1}))
+	assert.NoFileExists(t, path)
+
+	require.NoError(t, s.FlushUsage(t.Context()))
+
+	reopened, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	got, err := reopened.DailyUsage(t.Context(), day)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, 1, got[0].Commands)
+
+	// Any other write persists the usage along with it
+	require.NoError(t, s.AddUsage(t.Context(), day, Usage{Commands: 1}))
+	require.NoError(t, s.IndexTracks(t.Context(), "C123", []IndexedTrack{
+		{MessageTS: "1700000000.000100", URL: "https://youtu.be/dQw4w9WgXcQ", Title: "Song"},
+	}))
+
+	reopened, err = NewFileStore(path)
+	require.NoError(t, err)
+
+	got, err = reopened.DailyUsage(t.Context(), day)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, 2, got[0].Commands)
+}
+
+func TestFileStore_ConcurrentWrites(t *testing.T) {
+	t.Parallel()
+
+	s, err := NewFileStore(filepath.Join(t.TempDir(), "state.json"))
+	require.NoError(t, err)
+
+	day := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+
+	var wg sync.WaitGroup
+
+	for i := range 20 {
+		wg.Go(func() {
+			assert.NoError(t, s.AddUsage(t.Context(), day, Usage{Commands: 1}))
+			assert.NoError(t, s.FlushUsage(t.Context()))
+			assert.NoError(t, s.IndexTracks(t.Context(), "C123", []IndexedTrack{
+				{MessageTS: fmt.Sprintf("1700000000.%06d", i), URL: "https://youtu.be/dQw4w9WgXcQ", Title: "Song"},
+			}))
+		})
+	}
+
+	wg.Wait()
+
+	usage, err := s.DailyUsage(t.Context(), day)
+	require.NoError(t, err)
+	require.Len(t, usage, 1)
+	assert.Equal(t, 20, usage[0].Commands)
+
+	tracks, err := s.SearchTracks(t.Context(), "C123", "song", 100)
+	require.NoError(t, err)
+	assert.Len(t, tracks, 20)
+}
+
+func TestNewFileStore_MigratesLegacyState(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{name: "version 1", raw: `{"version":1,"users":{"U123":{"format":"json"}}}`},
+		{name: "without version", raw: `{"users":{"U123":{"format":"json"}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "state.json")
+			require.NoError(t, os.WriteFile(path, []byte(tt.raw), 0o600))
+
+			s, err := NewFileStore(path)
+			require.NoError(t, err)
+			assert.True(t, s.Compatibility().Migrated)
+
+			reopened, err := NewFileStore(path)
+			require.NoError(t, err)
+			assert.Equal(t, Compatibility{FileVersion: stateVersion}, reopened.Compatibility())
+
+			got, err := reopened.UserPreferences(t.Context(), "U123")
+			require.NoError(t, err)
+			assert.Equal(t, UserPreferences{Format: "json"}, got)
+		})
+	}
+}
+
+func TestNewFileStore_QuarantinesUnknownRecords(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version":2,"users":{
+		"U123":{"v":1,"record":{"format":"json"}},
+		"U456":{"v":7,"record":{"format":"from the future"}}
+	}}`), 0o600))
+
+	s, err := NewFileStore(path)
+	require.NoError(t, err)
+	assert.Equal(t, Compatibility{FileVersion: 2, Quarantined: 1}, s.Compatibility())
+
+	got, err := s.UserPreferences(t.Context(), "U456")
+	require.NoError(t, err)
+	assert.Equal(t, UserPreferences{}, got)
+
+	// The quarantined record survives the next write untouched
+	require.NoError(t, s.SetUserPreferences(t.Context(), "U789", UserPreferences{Format: "csv"}))
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), "from the future")
+
+	reopened, err := NewFileStore(path)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reopened.Compatibility().Quarantined)
+
+	got, err = reopened.UserPreferences(t.Context(), "U123")
+	require.NoError(t, err)
+	assert.Equal(t, UserPreferences{Format: "json"}, got)
+}
+
+func TestNewFileStore_QuarantinesNewerStateFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	raw := []byte(`{"version":99,"users":{"U123":{"v":1,"record":{"format":"json"}}}}`)
+	require.NoError(t, os.WriteFile(path, raw, 0o600))
+
+	s, err := NewFileStore(path)
+	require.NoError(t, err)
+	assert.Equal(t, Compatibility{FileVersion: 99, QuarantineFile: path + ".v99.quarantine"}, s.Compatibility())
+
+	got, err := s.UserPreferences(t.Context(), "U123")
+	require.NoError(t, err)
+	assert.Equal(t, UserPreferences{}, got)
+
+	quarantined, err := os.ReadFile(path + ".v99.quarantine")
+	require.NoError(t, err)
+	assert.Equal(t, raw, quarantined)
+
+	_, err = os.Stat(path)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
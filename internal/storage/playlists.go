@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// ThreadPlaylist is a playlist created from the tracks of a thread, updated in place by the later playlist commands.
+type ThreadPlaylist struct {
+	UpdatedAt time.Time `json:"updated_at"`
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+}
+
+// PlaylistStore remembers the playlists created from the threads.
+type PlaylistStore interface {
+	// SetThreadPlaylist stores the playlist created from a thread on provider.
+	SetThreadPlaylist(ctx context.Context, channelID, threadTS, provider string, playlist ThreadPlaylist) error
+	// ThreadPlaylist returns the playlist created from a thread on provider, false if there is none.
+	ThreadPlaylist(ctx context.Context, channelID, threadTS, provider string) (ThreadPlaylist, bool, error)
+}
+
+// playlistKey is the key of the playlist of a thread on a provider in the playlists of a channel.
+func playlistKey(threadTS, provider string) string {
+	return threadTS + "/" + provider
+}
@@ -0,0 +1,17 @@
+package storage
+
+import "context"
+
+// UserPreferences contains the per-user overrides of the global settings, empty fields mean the global setting.
+type UserPreferences struct {
+	// Format is the preferred summary format of the user.
+	Format string `json:"format,omitempty"`
+}
+
+// PreferenceStore stores the preferences of every user.
+type PreferenceStore interface {
+	// UserPreferences returns the preferences of a user, the zero value if the user hasn't set any.
+	UserPreferences(ctx context.Context, userID string) (UserPreferences, error)
+	// SetUserPreferences replaces the preferences of a user.
+	SetUserPreferences(ctx context.Context, userID string, prefs UserPreferences) error
+}
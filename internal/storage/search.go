@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"strings"
+
+	"github.com/Shikachuu/wap-bot/pkg/musicextractors"
+)
+
+// Scores of a single query word, depending on how well it matches a word of the candidate.
+const (
+	scoreExact  = 3
+	scorePrefix = 2
+	scoreTypo   = 1
+
+	// minTypoLength is the minimum length of a query word that is matched with a typo,
+	// shorter words would match almost everything.
+	minTypoLength = 4
+)
+
+// matchScore fuzzy matches query against candidate, every query word has to match a word of the candidate
+// exactly, as a prefix or with a single typo. Case, whitespace and diacritics are ignored.
+//
+// Returns the score of the match, higher is better, and whether candidate matched at all.
+func matchScore(query, candidate string) (int, bool) {
+	queryWords := strings.Fields(musicextractors.TitleKey(query, true))
+	candidateWords := strings.Fields(musicextractors.TitleKey(candidate, true))
+
+	if len(queryWords) == 0 {
+		return 0, false
+	}
+
+	total := 0
+
+	for _, q := range queryWords {
+		best := 0
+
+		for _, c := range candidateWords {
+			switch {
+			case q == c:
+				best = max(best, scoreExact)
+			case strings.HasPrefix(c, q):
+				best = max(best, scorePrefix)
+			case len([]rune(q)) >= minTypoLength && withinOneEdit(q, c):
+				best = max(best, scoreTypo)
+			}
+		}
+
+		if best == 0 {
+			return 0, false
+		}
+
+		total += best
+	}
+
+	return total, true
+}
+
+// withinOneEdit reports whether a and b differ by at most one inserted, deleted or substituted rune.
+func withinOneEdit(a, b string) bool {
+	ra, rb := []rune(a), []rune(b)
+
+	if len(ra) < len(rb) {
+		ra, rb = rb, ra
+	}
+
+	if len(ra)-len(rb) > 1 {
+		return false
+	}
+
+	i, j, edits := 0, 0, 0
+
+	for i < len(ra) && j < len(rb) {
+		if ra[i] == rb[j] {
+			i++
+			j++
+
+			continue
+		}
+
+		edits++
+		if edits > 1 {
+			return false
+		}
+
+		// Substitution when the lengths are equal, otherwise skip the extra rune of the longer word
+		if len(ra) == len(rb) {
+			j++
+		}
+
+		i++
+	}
+
+	return edits+(len(ra)-i) <= 1
+}
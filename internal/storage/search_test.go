@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchScore(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		query     string
+		candidate string
+		wantOK    bool
+	}{
+		{name: "exact words", query: "never gonna", candidate: "Rick Astley Never Gonna Give You Up", wantOK: true},
+		{name: "prefix", query: "astl", candidate: "Rick Astley Never Gonna Give You Up", wantOK: true},
+		{name: "typo", query: "astly", candidate: "Rick Astley Never Gonna Give You Up", wantOK: true},
+		{name: "diacritics and case", query: "beyonce", candidate: "BEYONCÉ Halo", wantOK: true},
+		{name: "every word has to match", query: "rick roll", candidate: "Rick Astley Never Gonna Give You Up"},
+		{name: "short words need an exact or prefix match", query: "ric", candidate: "Rock"},
+		{name: "empty query", query: "  ", candidate: "Rick Astley"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, ok := matchScore(tt.query, tt.candidate)
+			assert.Equal(t, tt.wantOK, ok)
+		})
+	}
+}
+
+func TestMatchScore_ExactBeatsTypo(t *testing.T) {
+	t.Parallel()
+
+	exact, _ := matchScore("halo", "Beyoncé Halo")
+	typo, _ := matchScore("halo", "Hola")
+
+	assert.Greater(t, exact, typo)
+}
+
+func TestWithinOneEdit(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, withinOneEdit("astley", "astley"))
+	assert.True(t, withinOneEdit("astley", "astly"))
+	assert.True(t, withinOneEdit("astley", "asttley"))
+	assert.True(t, withinOneEdit("astley", "astlay"))
+	assert.False(t, withinOneEdit("astley", "stlay"))
+	assert.False(t, withinOneEdit("astley", "astleyyy"))
+}
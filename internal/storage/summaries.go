@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// ThreadSummary is the last summary of a thread, the next summary only resolves the messages posted since
+// and replaces its file and messages.
+type ThreadSummary struct {
+	SummarizedAt time.Time `json:"summarized_at"`
+	// LastMessageTS is the timestamp of the newest message of the thread the summary covered.
+	LastMessageTS string `json:"last_message_ts"`
+	// FileID is the ID of the uploaded summary file, empty for inline summaries.
+	FileID string `json:"file_id,omitempty"`
+	// MessageTSs are the timestamps of the messages the bot posted with the summary, like the inline summary
+	// and the follow-up buttons.
+	MessageTSs []string `json:"message_ts,omitempty"`
+}
+
+// ThreadSummaryStore remembers the last summary of the summarized threads.
+type ThreadSummaryStore interface {
+	// SetThreadSummary replaces the last summary of a thread.
+	SetThreadSummary(ctx context.Context, channelID, threadTS string, summary ThreadSummary) error
+	// ThreadSummary returns the last summary of a thread, false if it wasn't summarized yet.
+	ThreadSummary(ctx context.Context, channelID, threadTS string) (ThreadSummary, bool, error)
+}
@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// ScheduledThread is the last thread the bot opened on its own in a channel.
+type ScheduledThread struct {
+	PostedAt time.Time `json:"posted_at"`
+	ThreadTS string    `json:"thread_ts"`
+}
+
+// ScheduledThreadStore remembers the scheduled threads, so they can be summarized when the next one is opened.
+type ScheduledThreadStore interface {
+	// ScheduledThread returns the last scheduled thread of a channel, the zero value if there is none.
+	ScheduledThread(ctx context.Context, channelID string) (ScheduledThread, error)
+	// SetScheduledThread replaces the last scheduled thread of a channel.
+	SetScheduledThread(ctx context.Context, channelID string, thread ScheduledThread) error
+}
+
+// ClosedThread is a thread closed with the close command, new links shared in it are pointed elsewhere.
+type ClosedThread struct {
+	ClosedAt time.Time `json:"closed_at"`
+	ClosedBy string    `json:"closed_by,omitempty"`
+}
+
+// ClosedThreadStore remembers the closed threads.
+type ClosedThreadStore interface {
+	// CloseThread marks a thread of a channel as closed, closing it again replaces the previous record.
+	CloseThread(ctx context.Context, channelID, threadTS string, thread ClosedThread) error
+	// ClosedThread returns how a thread of a channel was closed and whether it's closed at all.
+	ClosedThread(ctx context.Context, channelID, threadTS string) (ClosedThread, bool, error)
+}
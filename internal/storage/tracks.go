@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"slices"
+	"time"
+)
+
+// IndexedTrack is a track shared in a channel, as stored in the track index.
+// The tracks of the anonymous archives have no SharedAt, MessageTS and UserID.
+type IndexedTrack struct {
+	SharedAt  time.Time `json:"shared_at,omitzero"`
+	ChannelID string    `json:"channel_id"`
+	ThreadTS  string    `json:"thread_ts"`
+	MessageTS string    `json:"message_ts,omitempty"`
+	UserID    string    `json:"user_id,omitempty"`
+	Title     string    `json:"title"`
+	Artist    string    `json:"artist,omitempty"`
+	URL       string    `json:"url"`
+	Provider  string    `json:"provider"`
+}
+
+// key identifies a track in the index, the same link of the same message is only stored once.
+func (t IndexedTrack) key() string {
+	return t.MessageTS + "|" + t.URL
+}
+
+// TrackHistory is the sharing history of a unique track in a channel, the track is identified by its URL.
+type TrackHistory struct {
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	Title     string    `json:"title"`
+	Artist    string    `json:"artist,omitempty"`
+	URL       string    `json:"url"`
+	Provider  string    `json:"provider"`
+	// ShareCount is the number of messages the track was shared in.
+	ShareCount int `json:"share_count"`
+}
+
+// record adds a new share of the track to the history, the title of the latest share wins.
+func (h TrackHistory) record(track IndexedTrack) TrackHistory {
+	if h.ShareCount == 0 || (!track.SharedAt.IsZero() && track.SharedAt.Before(h.FirstSeen)) {
+		h.FirstSeen = track.SharedAt
+	}
+
+	if track.SharedAt.After(h.LastSeen) {
+		h.LastSeen = track.SharedAt
+	}
+
+	h.ShareCount++
+	h.Title, h.Artist, h.URL, h.Provider = track.Title, track.Artist, track.URL, track.Provider
+
+	return h
+}
+
+// trackHistories builds the history of every unique track from the indexed tracks,
+// for the state files written before the history was stored.
+func trackHistories(tracks map[string][]IndexedTrack) map[string]map[string]TrackHistory {
+	histories := make(map[string]map[string]TrackHistory, len(tracks))
+
+	for channelID, indexed := range tracks {
+		history := make(map[string]TrackHistory, len(indexed))
+
+		for _, t := range slices.SortedStableFunc(slices.Values(indexed), func(a, b IndexedTrack) int {
+			return a.SharedAt.Compare(b.SharedAt)
+		}) {
+			history[t.URL] = history[t.URL].record(t)
+		}
+
+		histories[channelID] = history
+	}
+
+	return histories
+}
+
+// sameDayOfYear reports if a and b are on the same month and day in UTC, in any year.
+func sameDayOfYear(a, b time.Time) bool {
+	a, b = a.UTC(), b.UTC()
+
+	return a.Month() == b.Month() && a.Day() == b.Day()
+}
+
+// byShareCount orders the histories by their share count, most shared first, equally shared ones by their last share.
+func byShareCount(a, b TrackHistory) int {
+	if a.ShareCount != b.ShareCount {
+		return cmp.Compare(b.ShareCount, a.ShareCount)
+	}
+
+	if c := b.LastSeen.Compare(a.LastSeen); c != 0 {
+		return c
+	}
+
+	return cmp.Compare(a.URL, b.URL)
+}
+
+// PosterStats are the tracks a user shared, a row of the leaderboard.
+type PosterStats struct {
+	UserID string
+	// UniqueTracks is the number of different tracks the user shared, the tracks are identified by their URL.
+	UniqueTracks int
+	// LastShared is the latest share of the user.
+	LastShared time.Time
+}
+
+// RankPosters counts the unique tracks every user shared, most first, equally active users by their latest share,
+// newest first. Tracks without a user are left out.
+func RankPosters(tracks []IndexedTrack) []PosterStats {
+	urls := map[string]map[string]struct{}{}
+	posters := map[string]PosterStats{}
+
+	for _, t := range tracks {
+		if t.UserID == "" {
+			continue
+		}
+
+		if urls[t.UserID] == nil {
+			urls[t.UserID] = map[string]struct{}{}
+		}
+
+		p := posters[t.UserID]
+		p.UserID = t.UserID
+
+		if _, ok := urls[t.UserID][t.URL]; !ok {
+			urls[t.UserID][t.URL] = struct{}{}
+			p.UniqueTracks++
+		}
+
+		if t.SharedAt.After(p.LastShared) {
+			p.LastShared = t.SharedAt
+		}
+
+		posters[t.UserID] = p
+	}
+
+	return slices.SortedFunc(maps.Values(posters), func(a, b PosterStats) int {
+		if a.UniqueTracks != b.UniqueTracks {
+			return cmp.Compare(b.UniqueTracks, a.UniqueTracks)
+		}
+
+		return cmp.Or(b.LastShared.Compare(a.LastShared), cmp.Compare(a.UserID, b.UserID))
+	})
+}
+
+// TrackIndex stores every track found in summarized threads, so they can be searched later.
+type TrackIndex interface {
+	// IndexTracks adds the tracks to the index of a channel, tracks that are already indexed are replaced.
+	// Every newly indexed track is a new share in the history of its URL.
+	IndexTracks(ctx context.Context, channelID string, tracks []IndexedTrack) error
+	// SearchTracks returns at most limit tracks of a channel matching query, best matches first.
+	SearchTracks(ctx context.Context, channelID, query string, limit int) ([]IndexedTrack, error)
+	// ThreadTracks returns the indexed tracks of a thread in the order of their messages.
+	ThreadTracks(ctx context.Context, channelID, threadTS string) ([]IndexedTrack, error)
+	// TrendingTracks returns at most limit tracks of a channel shared since from, most shared first.
+	TrendingTracks(ctx context.Context, channelID string, from time.Time, limit int) ([]TrackHistory, error)
+	// OnThisDay returns the tracks of a channel first shared on the month and day of day in an earlier year, oldest first.
+	OnThisDay(ctx context.Context, channelID string, day time.Time) ([]TrackHistory, error)
+	// TopPosters returns at most limit users of a channel who shared the most unique tracks in [from, until),
+	// zero times leave that end of the window open.
+	TopPosters(ctx context.Context, channelID string, from, until time.Time, limit int) ([]PosterStats, error)
+}
+
+// Store is every store of the bot.
+type Store interface {
+	PreferenceStore
+	TrackIndex
+	ScheduledThreadStore
+	ClosedThreadStore
+	UsageStore
+	FailureReportStore
+	PlaylistStore
+	ThreadSummaryStore
+}
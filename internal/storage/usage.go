@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// usageDayLayout is the key of a day in the usage statistics.
+const usageDayLayout = time.DateOnly
+
+// usageRetention is how long the daily usage statistics are kept.
+const usageRetention = 90 * 24 * time.Hour
+
+// Usage is the usage of the bot during a day.
+type Usage struct {
+	// Commands is the number of handled commands.
+	Commands int `json:"commands"`
+	// Errors is the number of mentions that failed.
+	Errors int `json:"errors"`
+	// Summaries is the number of summarized threads, SummarizeTime is the total time they took.
+	Summaries     int           `json:"summaries"`
+	SummarizeTime time.Duration `json:"summarize_time"`
+}
+
+// add returns the sum of u and other.
+func (u Usage) add(other Usage) Usage {
+	return Usage{
+		Commands:      u.Commands + other.Commands,
+		Errors:        u.Errors + other.Errors,
+		Summaries:     u.Summaries + other.Summaries,
+		SummarizeTime: u.SummarizeTime + other.SummarizeTime,
+	}
+}
+
+// DailyUsage is the usage of a single day, Day is midnight UTC.
+type DailyUsage struct {
+	Day time.Time
+	Usage
+}
+
+// UsageStore keeps daily usage statistics of the bot, for workspaces without access to the metrics backend.
+type UsageStore interface {
+	// AddUsage adds usage to the statistics of the day of at, it may only be persisted by the next FlushUsage.
+	AddUsage(ctx context.Context, at time.Time, usage Usage) error
+	// FlushUsage persists the statistics added since the last flush.
+	FlushUsage(ctx context.Context) error
+	// DailyUsage returns the statistics of the days since the day of from, oldest first, days without usage are left out.
+	DailyUsage(ctx context.Context, from time.Time) ([]DailyUsage, error)
+}
@@ -0,0 +1,340 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// The versions of the state file:
+//
+//  1. the records are stored as is.
+//  2. every record is stamped with the schema version of its type,
+//     records a release can't read are quarantined instead of failing the whole file.
+const stateVersion = 2
+
+// recordVersion is the schema version of the records written by this release, shared by every record type.
+//
+// A breaking change of any record type bumps it, and older records have to be migrated in unstamp.
+const recordVersion = 1
+
+// The sections of the state file, used to tell where a quarantined record belongs.
+const (
+	sectionUsers            = "users"
+	sectionTracks           = "tracks"
+	sectionTrackHistory     = "track_history"
+	sectionScheduledThreads = "scheduled_threads"
+	sectionClosedThreads    = "closed_threads"
+	sectionFailureReports   = "failure_reports"
+	sectionPlaylists        = "playlists"
+	sectionSummaries        = "summaries"
+	sectionUsage            = "usage"
+)
+
+// stampedRecord is a record as stored in the state file, along with the schema version it was written with.
+type stampedRecord struct {
+	Record  json.RawMessage `json:"record"`
+	Version int             `json:"v"`
+}
+
+// QuarantinedRecord is a record of the state file this release can't read, like one written by a newer release.
+//
+// It's kept in the state file untouched, so a release that understands it can pick it up again.
+type QuarantinedRecord struct {
+	Section string `json:"section"`
+	// Key identifies the record in its section, the channel, user or day ID, channel/thread for the closed threads,
+	// the failure reports and the summaries, channel/thread/provider for the playlists, or channel/URL for the track history.
+	Key string `json:"key"`
+	stampedRecord
+}
+
+// Compatibility is the outcome of the compatibility check of the state file at startup.
+type Compatibility struct {
+	// QuarantineFile is where the state file was moved if its layout is newer than this release understands.
+	QuarantineFile string
+	// FileVersion is the version the state file was written with, zero if there was no state file.
+	FileVersion int
+	// Quarantined is the number of records kept aside because this release can't read them.
+	Quarantined int
+	// Migrated reports if the state file was written with an older layout and got rewritten with the current one.
+	Migrated bool
+}
+
+// diskState is the layout of version 2 of the state file, fileState with every record stamped.
+type diskState struct {
+	Users            map[string]stampedRecord            `json:"users"`
+	Tracks           map[string][]stampedRecord          `json:"tracks,omitempty"`
+	History          map[string]map[string]stampedRecord `json:"track_history,omitempty"`
+	ScheduledThreads map[string]stampedRecord            `json:"scheduled_threads,omitempty"`
+	ClosedThreads    map[string]map[string]stampedRecord `json:"closed_threads,omitempty"`
+	FailureReports   map[string]map[string]stampedRecord `json:"failure_reports,omitempty"`
+	Playlists        map[string]map[string]stampedRecord `json:"playlists,omitempty"`
+	Summaries        map[string]map[string]stampedRecord `json:"summaries,omitempty"`
+	Usage            map[string]stampedRecord            `json:"usage,omitempty"`
+	Quarantine       []QuarantinedRecord                 `json:"quarantine,omitempty"`
+	Version          int                                 `json:"version"`
+}
+
+// stamp encodes a record with the current schema version.
+func stamp[T any](record T) (stampedRecord, error) {
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return stampedRecord{}, fmt.Errorf("encoding record: %w", err)
+	}
+
+	return stampedRecord{Record: raw, Version: recordVersion}, nil
+}
+
+// unstamp decodes a record.
+//
+// Returns the record and true, or false if the record has a schema version this release can't read.
+func unstamp[T any](r stampedRecord) (T, bool, error) {
+	var record T
+
+	if r.Version != recordVersion {
+		return record, false, nil
+	}
+
+	if err := json.Unmarshal(r.Record, &record); err != nil {
+		return record, false, fmt.Errorf("%w: decoding record: %w", ErrCorruptState, err)
+	}
+
+	return record, true, nil
+}
+
+// stampMap stamps every record of a section.
+func stampMap[T any](records map[string]T) (map[string]stampedRecord, error) {
+	stamped := make(map[string]stampedRecord, len(records))
+
+	for key, record := range records {
+		r, err := stamp(record)
+		if err != nil {
+			return nil, err
+		}
+
+		stamped[key] = r
+	}
+
+	return stamped, nil
+}
+
+// unstampMap decodes every record of a section, the unreadable ones are added to the quarantine.
+func unstampMap[T any](section string, stamped map[string]stampedRecord, quarantine *[]QuarantinedRecord) (map[string]T, error) {
+	records := make(map[string]T, len(stamped))
+
+	for key, r := range stamped {
+		record, ok, err := unstamp[T](r)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			*quarantine = append(*quarantine, QuarantinedRecord{Section: section, Key: key, stampedRecord: r})
+			continue
+		}
+
+		records[key] = record
+	}
+
+	return records, nil
+}
+
+// encodeState converts the state to the current layout of the state file, the quarantined records are kept as is.
+func encodeState(state fileState, quarantine []QuarantinedRecord) (diskState, error) {
+	disk := diskState{
+		Tracks:         make(map[string][]stampedRecord, len(state.Tracks)),
+		History:        make(map[string]map[string]stampedRecord, len(state.History)),
+		ClosedThreads:  make(map[string]map[string]stampedRecord, len(state.ClosedThreads)),
+		FailureReports: make(map[string]map[string]stampedRecord, len(state.FailureReports)),
+		Playlists:      make(map[string]map[string]stampedRecord, len(state.Playlists)),
+		Summaries:      make(map[string]map[string]stampedRecord, len(state.Summaries)),
+		Quarantine:     quarantine,
+		Version:        stateVersion,
+	}
+
+	var err error
+
+	if disk.Users, err = stampMap(state.Users); err != nil {
+		return diskState{}, err
+	}
+
+	if disk.ScheduledThreads, err = stampMap(state.ScheduledThreads); err != nil {
+		return diskState{}, err
+	}
+
+	if disk.Usage, err = stampMap(state.Usage); err != nil {
+		return diskState{}, err
+	}
+
+	for channelID, closed := range state.ClosedThreads {
+		if disk.ClosedThreads[channelID], err = stampMap(closed); err != nil {
+			return diskState{}, err
+		}
+	}
+
+	for channelID, reports := range state.FailureReports {
+		if disk.FailureReports[channelID], err = stampMap(reports); err != nil {
+			return diskState{}, err
+		}
+	}
+
+	for channelID, playlists := range state.Playlists {
+		if disk.Playlists[channelID], err = stampMap(playlists); err != nil {
+			return diskState{}, err
+		}
+	}
+
+	for channelID, summaries := range state.Summaries {
+		if disk.Summaries[channelID], err = stampMap(summaries); err != nil {
+			return diskState{}, err
+		}
+	}
+
+	for channelID, history := range state.History {
+		if disk.History[channelID], err = stampMap(history); err != nil {
+			return diskState{}, err
+		}
+	}
+
+	for channelID, tracks := range state.Tracks {
+		stamped := make([]stampedRecord, 0, len(tracks))
+
+		for _, t := range tracks {
+			r, sErr := stamp(t)
+			if sErr != nil {
+				return diskState{}, sErr
+			}
+
+			stamped = append(stamped, r)
+		}
+
+		disk.Tracks[channelID] = stamped
+	}
+
+	return disk, nil
+}
+
+// decodeState reads the current layout of the state file, quarantining the records this release can't read.
+//
+// Returns the state and every quarantined record, including the ones quarantined by a previous start.
+func decodeState(raw []byte) (fileState, []QuarantinedRecord, error) {
+	var disk diskState
+	if err := json.Unmarshal(raw, &disk); err != nil {
+		return fileState{}, nil, fmt.Errorf("%w: %w", ErrCorruptState, err)
+	}
+
+	quarantine := disk.Quarantine
+	state := fileState{
+		Tracks:         make(map[string][]IndexedTrack, len(disk.Tracks)),
+		ClosedThreads:  make(map[string]map[string]ClosedThread, len(disk.ClosedThreads)),
+		FailureReports: make(map[string]map[string]FailureReport, len(disk.FailureReports)),
+		Playlists:      make(map[string]map[string]ThreadPlaylist, len(disk.Playlists)),
+		Summaries:      make(map[string]map[string]ThreadSummary, len(disk.Summaries)),
+		Version:        stateVersion,
+	}
+
+	var err error
+
+	if state.Users, err = unstampMap[UserPreferences](sectionUsers, disk.Users, &quarantine); err != nil {
+		return fileState{}, nil, err
+	}
+
+	if state.ScheduledThreads, err = unstampMap[ScheduledThread](sectionScheduledThreads, disk.ScheduledThreads, &quarantine); err != nil {
+		return fileState{}, nil, err
+	}
+
+	if state.Usage, err = unstampMap[Usage](sectionUsage, disk.Usage, &quarantine); err != nil {
+		return fileState{}, nil, err
+	}
+
+	for channelID, closed := range disk.ClosedThreads {
+		var threads []QuarantinedRecord
+
+		if state.ClosedThreads[channelID], err = unstampMap[ClosedThread](sectionClosedThreads, closed, &threads); err != nil {
+			return fileState{}, nil, err
+		}
+
+		for _, q := range threads {
+			q.Key = channelID + "/" + q.Key
+			quarantine = append(quarantine, q)
+		}
+	}
+
+	for channelID, reports := range disk.FailureReports {
+		var threads []QuarantinedRecord
+
+		if state.FailureReports[channelID], err = unstampMap[FailureReport](sectionFailureReports, reports, &threads); err != nil {
+			return fileState{}, nil, err
+		}
+
+		for _, q := range threads {
+			q.Key = channelID + "/" + q.Key
+			quarantine = append(quarantine, q)
+		}
+	}
+
+	for channelID, playlists := range disk.Playlists {
+		var threads []QuarantinedRecord
+
+		if state.Playlists[channelID], err = unstampMap[ThreadPlaylist](sectionPlaylists, playlists, &threads); err != nil {
+			return fileState{}, nil, err
+		}
+
+		for _, q := range threads {
+			q.Key = channelID + "/" + q.Key
+			quarantine = append(quarantine, q)
+		}
+	}
+
+	for channelID, summaries := range disk.Summaries {
+		var threads []QuarantinedRecord
+
+		if state.Summaries[channelID], err = unstampMap[ThreadSummary](sectionSummaries, summaries, &threads); err != nil {
+			return fileState{}, nil, err
+		}
+
+		for _, q := range threads {
+			q.Key = channelID + "/" + q.Key
+			quarantine = append(quarantine, q)
+		}
+	}
+
+	// The history is rebuilt from the tracks if the file was written before it was stored
+	if disk.History != nil {
+		state.History = make(map[string]map[string]TrackHistory, len(disk.History))
+	}
+
+	for channelID, history := range disk.History {
+		var tracks []QuarantinedRecord
+
+		if state.History[channelID], err = unstampMap[TrackHistory](sectionTrackHistory, history, &tracks); err != nil {
+			return fileState{}, nil, err
+		}
+
+		for _, q := range tracks {
+			q.Key = channelID + "/" + q.Key
+			quarantine = append(quarantine, q)
+		}
+	}
+
+	for channelID, stamped := range disk.Tracks {
+		tracks := make([]IndexedTrack, 0, len(stamped))
+
+		for _, r := range stamped {
+			t, ok, uErr := unstamp[IndexedTrack](r)
+			if uErr != nil {
+				return fileState{}, nil, uErr
+			}
+
+			if !ok {
+				quarantine = append(quarantine, QuarantinedRecord{Section: sectionTracks, Key: channelID, stampedRecord: r})
+				continue
+			}
+
+			tracks = append(tracks, t)
+		}
+
+		state.Tracks[channelID] = tracks
+	}
+
+	return state, quarantine, nil
+}
@@ -12,4 +12,4 @@
 //	defer shutdown(context.Background())
 //
 // OpenTelemetry exporters are configured via standard OTEL_* environment variables.
-package telemetry
\ No newline at end of file
+package telemetry
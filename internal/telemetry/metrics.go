@@ -0,0 +1,171 @@
+package telemetry
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// CardinalityBuckets is the number of distinct values a guarded metric attribute can take.
+const CardinalityBuckets = 32
+
+// Metrics contains every metric instrument of the application.
+type Metrics struct {
+	// Commands counts the handled bot commands.
+	Commands metric.Int64Counter
+	// Extractions counts the URL matches and title lookups of the providers by outcome.
+	Extractions metric.Int64Counter
+	// ExtractionDuration records how long the URL matches and title lookups of the providers take.
+	ExtractionDuration metric.Float64Histogram
+	// ExtractionConcurrencyLimit is the configured size of the extraction worker pool.
+	ExtractionConcurrencyLimit metric.Int64Gauge
+	// ExtractionWorkers is the number of running extraction workers, ExtractionBusyWorkers the ones processing a message.
+	ExtractionWorkers     metric.Int64UpDownCounter
+	ExtractionBusyWorkers metric.Int64UpDownCounter
+	// ExtractionQueueDepth is the number of messages waiting for an extraction worker.
+	ExtractionQueueDepth metric.Int64UpDownCounter
+	// ExtractionQueueWait records how long messages wait for an extraction worker.
+	ExtractionQueueWait metric.Float64Histogram
+	// SummaryLimit is the configured number of summaries a user or a channel runs at the same time, by scope.
+	SummaryLimit metric.Int64Gauge
+	// SummariesRateLimited counts the summaries rejected by the per-user and per-channel limits.
+	SummariesRateLimited metric.Int64Counter
+}
+
+// NewMetrics creates every metric instrument on the global Meter.
+func NewMetrics() (*Metrics, error) {
+	commands, err := Meter.Int64Counter(
+		"wapbot.commands",
+		metric.WithDescription("Number of handled bot commands"),
+		metric.WithUnit("{command}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating commands counter: %w", err)
+	}
+
+	extractions, err := Meter.Int64Counter(
+		"wapbot.extractions",
+		metric.WithDescription("Number of provider URL matches and title lookups"),
+		metric.WithUnit("{extraction}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating extractions counter: %w", err)
+	}
+
+	extractionDuration, err := Meter.Float64Histogram(
+		"wapbot.extraction.duration",
+		metric.WithDescription("Duration of provider URL matches and title lookups"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating extraction duration histogram: %w", err)
+	}
+
+	m := &Metrics{Commands: commands, Extractions: extractions, ExtractionDuration: extractionDuration}
+
+	if err = m.newPoolInstruments(); err != nil {
+		return nil, err
+	}
+
+	if err = m.newLimitInstruments(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// newPoolInstruments creates the instruments of the extraction worker pool.
+func (m *Metrics) newPoolInstruments() error {
+	var err error
+
+	m.ExtractionConcurrencyLimit, err = Meter.Int64Gauge(
+		"wapbot.extraction.concurrency.limit",
+		metric.WithDescription("Configured size of the extraction worker pool"),
+		metric.WithUnit("{worker}"),
+	)
+	if err != nil {
+		return fmt.Errorf("creating extraction concurrency limit gauge: %w", err)
+	}
+
+	m.ExtractionWorkers, err = Meter.Int64UpDownCounter(
+		"wapbot.extraction.workers",
+		metric.WithDescription("Number of running extraction workers"),
+		metric.WithUnit("{worker}"),
+	)
+	if err != nil {
+		return fmt.Errorf("creating extraction workers counter: %w", err)
+	}
+
+	m.ExtractionBusyWorkers, err = Meter.Int64UpDownCounter(
+		"wapbot.extraction.workers.busy",
+		metric.WithDescription("Number of extraction workers processing a message"),
+		metric.WithUnit("{worker}"),
+	)
+	if err != nil {
+		return fmt.Errorf("creating busy extraction workers counter: %w", err)
+	}
+
+	m.ExtractionQueueDepth, err = Meter.Int64UpDownCounter(
+		"wapbot.extraction.queue.depth",
+		metric.WithDescription("Number of messages waiting for an extraction worker"),
+		metric.WithUnit("{message}"),
+	)
+	if err != nil {
+		return fmt.Errorf("creating extraction queue depth counter: %w", err)
+	}
+
+	m.ExtractionQueueWait, err = Meter.Float64Histogram(
+		"wapbot.extraction.queue.wait",
+		metric.WithDescription("Time messages wait for an extraction worker"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return fmt.Errorf("creating extraction queue wait histogram: %w", err)
+	}
+
+	return nil
+}
+
+// newLimitInstruments creates the instruments of the per-user and per-channel summary limits.
+func (m *Metrics) newLimitInstruments() error {
+	var err error
+
+	m.SummaryLimit, err = Meter.Int64Gauge(
+		"wapbot.summaries.limit",
+		metric.WithDescription("Configured number of summaries a user or a channel runs at the same time"),
+		metric.WithUnit("{summary}"),
+	)
+	if err != nil {
+		return fmt.Errorf("creating summary limit gauge: %w", err)
+	}
+
+	m.SummariesRateLimited, err = Meter.Int64Counter(
+		"wapbot.summaries.rate_limited",
+		metric.WithDescription("Number of summaries rejected by the per-user and per-channel limits"),
+		metric.WithUnit("{summary}"),
+	)
+	if err != nil {
+		return fmt.Errorf("creating rate limited summaries counter: %w", err)
+	}
+
+	return nil
+}
+
+// GuardedAttribute creates a metric attribute for a high-cardinality value, like a channel or user ID.
+//
+// The value is hashed into one of CardinalityBuckets buckets, so every metric series stays bounded
+// no matter how many channels or users there are. Spans should keep using the raw value,
+// see SplitAttribute.
+func GuardedAttribute(key, value string) attribute.KeyValue {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(value)) // hash.Hash never returns an error
+
+	return attribute.String(key, fmt.Sprintf("bucket-%02d", h.Sum32()%CardinalityBuckets))
+}
+
+// SplitAttribute returns the raw attribute for traces and the guarded one for metrics of the same high-cardinality value.
+func SplitAttribute(key, value string) (traceAttr, metricAttr attribute.KeyValue) {
+	return attribute.String(key, value), GuardedAttribute(key, value)
+}
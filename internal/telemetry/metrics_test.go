@@ -0,0 +1,37 @@
+package telemetry
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuardedAttribute_BoundedCardinality(t *testing.T) {
+	t.Parallel()
+
+	seen := map[string]struct{}{}
+
+	for i := range 10_000 {
+		kv := GuardedAttribute("slack.channel_id", fmt.Sprintf("C%08d", i))
+		seen[kv.Value.AsString()] = struct{}{}
+	}
+
+	assert.Len(t, seen, CardinalityBuckets)
+}
+
+func TestGuardedAttribute_Stable(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, GuardedAttribute("slack.user_id", "U123"), GuardedAttribute("slack.user_id", "U123"))
+}
+
+func TestSplitAttribute(t *testing.T) {
+	t.Parallel()
+
+	traceAttr, metricAttr := SplitAttribute("slack.user_id", "U123")
+
+	assert.Equal(t, "U123", traceAttr.Value.AsString())
+	assert.NotEqual(t, traceAttr.Value, metricAttr.Value)
+	assert.Equal(t, traceAttr.Key, metricAttr.Key)
+}
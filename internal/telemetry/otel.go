@@ -21,7 +21,8 @@ var (
 	Meter = otel.Meter(name)
 )
 
-// SetupOTel creates a new open telemetry trace and metric provider and sets them on the global context.
+// SetupOTel creates a new open telemetry trace and metric provider and the text map propagator,
+// then sets them on the global context.
 //
 // ctx is the current context that we use to set these metrics up.
 //
@@ -29,6 +30,11 @@ var (
 func SetupOTel(ctx context.Context) (func(context.Context) error, error) {
 	res := resource.Default()
 
+	// W3C trace context and baggage by default, so outgoing requests made with NewHTTPClient carry our traces
+	if err := setupPropagator(); err != nil {
+		return nil, fmt.Errorf("propagator setup: %w", err)
+	}
+
 	se, err := autoexport.NewSpanExporter(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("span exporter creation: %w", err)
@@ -0,0 +1,91 @@
+package telemetry
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// ErrUnknownPropagator is returned by SetupOTel if OTEL_PROPAGATORS contains an unsupported propagator.
+var ErrUnknownPropagator = errors.New("unknown propagator")
+
+// defaultPropagators are used when OTEL_PROPAGATORS is unset.
+const defaultPropagators = "tracecontext,baggage"
+
+// newPropagator creates a composite text map propagator from a comma separated list of propagator names,
+// following the OTEL_PROPAGATORS specification.
+//
+// Supported names are tracecontext, baggage, b3 (single header), b3multi and none.
+func newPropagator(names string) (propagation.TextMapPropagator, error) {
+	if strings.TrimSpace(names) == "" {
+		names = defaultPropagators
+	}
+
+	propagators := []propagation.TextMapPropagator{}
+
+	for name := range strings.SplitSeq(names, ",") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "tracecontext":
+			propagators = append(propagators, propagation.TraceContext{})
+		case "baggage":
+			propagators = append(propagators, propagation.Baggage{})
+		case "b3":
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3SingleHeader)))
+		case "b3multi":
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)))
+		case "none":
+			return propagation.NewCompositeTextMapPropagator(), nil
+		default:
+			return nil, fmt.Errorf("%w: %q", ErrUnknownPropagator, name)
+		}
+	}
+
+	return propagation.NewCompositeTextMapPropagator(propagators...), nil
+}
+
+// setupPropagator sets the global text map propagator configured by the OTEL_PROPAGATORS environment variable.
+func setupPropagator() error {
+	p, err := newPropagator(os.Getenv("OTEL_PROPAGATORS"))
+	if err != nil {
+		return fmt.Errorf("OTEL_PROPAGATORS: %w", err)
+	}
+
+	otel.SetTextMapPropagator(p)
+
+	return nil
+}
+
+// PropagatingTransport is an http.RoundTripper that injects the trace context of the request's context
+// into the outgoing headers (traceparent, tracestate, baggage), so traces of downstream services
+// like webhook sinks stitch together with ours.
+type PropagatingTransport struct {
+	// Base is the underlying transport, http.DefaultTransport is used if nil.
+	Base http.RoundTripper
+}
+
+var _ http.RoundTripper = (*PropagatingTransport)(nil)
+
+// RoundTrip injects the trace context headers into a clone of the request and sends it with the Base transport.
+func (p *PropagatingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := p.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	r := req.Clone(req.Context())
+	otel.GetTextMapPropagator().Inject(r.Context(), propagation.HeaderCarrier(r.Header))
+
+	return base.RoundTrip(r) //nolint:wrapcheck // a transport must return the errors of the underlying transport as is
+}
+
+// NewHTTPClient creates an HTTP client that propagates the trace context of every request, use it for calls
+// to services that participate in our traces.
+func NewHTTPClient() *http.Client {
+	return &http.Client{Transport: &PropagatingTransport{}}
+}
@@ -0,0 +1,101 @@
+package telemetry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestPropagatingTransport_InjectsTraceparent(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "none")
+	t.Setenv("OTEL_METRICS_EXPORTER", "none")
+
+	shutdown, err := SetupOTel(t.Context())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = shutdown(t.Context()) })
+
+	var got string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("traceparent")
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx, span := sdktrace.NewTracerProvider().Tracer("test").Start(t.Context(), "webhook")
+	defer span.End()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL, http.NoBody)
+	require.NoError(t, err)
+
+	resp, err := NewHTTPClient().Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	require.NotEmpty(t, got)
+	assert.Contains(t, got, span.SpanContext().TraceID().String())
+	assert.Empty(t, req.Header.Get("traceparent"), "the original request must not be modified")
+}
+
+func TestNewPropagator(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr    error
+		name       string
+		names      string
+		wantFields []string
+	}{
+		{
+			name:       "default",
+			names:      "",
+			wantFields: []string{"traceparent", "tracestate", "baggage"},
+		},
+		{
+			name:       "b3 single header",
+			names:      "tracecontext, b3",
+			wantFields: []string{"traceparent", "tracestate", "b3"},
+		},
+		{
+			name:       "b3 multi header",
+			names:      "b3multi",
+			wantFields: []string{"x-b3-traceid", "x-b3-spanid", "x-b3-sampled", "x-b3-flags"},
+		},
+		{
+			name:       "none",
+			names:      "none",
+			wantFields: []string{},
+		},
+		{
+			name:    "unknown",
+			names:   "tracecontext,jaeger",
+			wantErr: ErrUnknownPropagator,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := newPropagator(tt.names)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+
+			for _, f := range tt.wantFields {
+				assert.Contains(t, p.Fields(), f)
+			}
+
+			if len(tt.wantFields) == 0 {
+				assert.Empty(t, p.Fields())
+			}
+		})
+	}
+}
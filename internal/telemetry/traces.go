@@ -2,7 +2,9 @@ package telemetry
 
 import (
 	"fmt"
+	"time"
 
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -22,10 +24,73 @@ const (
 	ProcessThreadEvent = "process_thread"
 	// GetConversationRepliesEvent represents fetching conversation replies.
 	GetConversationRepliesEvent = "get_conversation_replies"
+	// GetConversationRepliesPageEvent represents reading a page of the replies of a thread,
+	// it carries the page number and the number of messages read so far.
+	GetConversationRepliesPageEvent = "get_conversation_replies_page"
+	// GetPreviousSummaryEvent represents reading the tracks of the last summary of a thread for an incremental summary.
+	GetPreviousSummaryEvent = "get_previous_summary"
+	// SaveThreadSummaryEvent represents replacing the last summary of a thread with the one just posted.
+	SaveThreadSummaryEvent = "save_thread_summary"
+	// GetConversationHistoryEvent represents fetching the top-level messages of a channel for a channel summary.
+	GetConversationHistoryEvent = "get_conversation_history"
+	// GetConversationHistoryPageEvent represents reading a page of the history of a channel.
+	GetConversationHistoryPageEvent = "get_conversation_history_page"
+	// SummarizeChannelEvent represents the channel summarization event.
+	SummarizeChannelEvent = "summarize_channel"
 	// SummarizeThreadEvent represents the thread summarization event.
 	SummarizeThreadEvent = "summarize_thread"
 	// UploadFileV2Event represents the file upload event using v2 API.
 	UploadFileV2Event = "upload_file_v2"
+	// PostInlineSummaryEvent represents posting the messages of an inline summary to the thread.
+	PostInlineSummaryEvent = "post_inline_summary"
+	// ProbeProvidersEvent represents pinging the lookup endpoints of every enabled provider.
+	ProbeProvidersEvent = "probe_providers"
+	// PostSummaryActionsEvent represents posting the follow-up buttons under a summary.
+	PostSummaryActionsEvent = "post_summary_actions"
+	// HandleBlockActionEvent represents handling a button or select interaction.
+	HandleBlockActionEvent = "handle_block_action"
+	// HandleMessageShortcutEvent represents handling the "Summarize thread" and "Summarize with options" message shortcuts.
+	HandleMessageShortcutEvent = "handle_message_shortcut"
+	// OpenOptionsModalEvent represents opening the summary options modal of a thread.
+	OpenOptionsModalEvent = "open_options_modal"
+	// HandleViewSubmissionEvent represents handling the submission of the summary options modal.
+	HandleViewSubmissionEvent = "handle_view_submission"
+	// IndexTracksEvent represents adding the tracks of a summary to the track index.
+	IndexTracksEvent = "index_tracks"
+	// SearchTracksEvent represents searching the track index.
+	SearchTracksEvent = "search_tracks"
+	// PostScheduledThreadEvent represents opening a scheduled thread.
+	PostScheduledThreadEvent = "post_scheduled_thread"
+	// ArchiveThreadEvent represents writing a closed thread to the archive sink.
+	ArchiveThreadEvent = "archive_thread"
+	// GetUserGroupsEvent represents listing the usergroups to resolve the one mentioned in scheduled digests.
+	GetUserGroupsEvent = "get_user_groups"
+	// GetUserInfoEvent represents resolving the name of a user who shared a track in a summary.
+	GetUserInfoEvent = "get_user_info"
+	// GetUsageEvent represents reading the daily usage statistics for the usage command.
+	GetUsageEvent = "get_usage"
+	// GetTrackTrendsEvent represents reading the most shared and the on this day tracks of a channel for the usage command.
+	GetTrackTrendsEvent = "get_track_trends"
+	// GetFailureReportEvent represents reading the failed titles of the last summary of a thread for the retry-titles command.
+	GetFailureReportEvent = "get_failure_report"
+	// RetryTitlesEvent represents looking up the failed titles of a thread again.
+	RetryTitlesEvent = "retry_titles"
+	// ExtractTracksEvent represents resolving the tracks of a thread for the playlist, the leaderboard and the preview commands.
+	ExtractTracksEvent = "extract_tracks"
+	// SyncPlaylistEvent represents creating or updating the playlist of a thread on a provider.
+	SyncPlaylistEvent = "sync_playlist"
+	// GetTopPostersEvent represents ranking the users of a channel by their shared tracks for the leaderboard command.
+	GetTopPostersEvent = "get_top_posters"
+	// PostPlaylistEvent represents posting the link of the playlist of a thread.
+	PostPlaylistEvent = "post_playlist"
+	// PostHelpEvent represents posting the list of the commands of the bot.
+	PostHelpEvent = "post_help"
+	// PostProgressEvent represents posting the progress message of a long summary.
+	PostProgressEvent = "post_progress"
+	// DeleteProgressEvent represents deleting the progress message of a long summary once it's done.
+	DeleteProgressEvent = "delete_progress"
+	// AddReactionEvent represents reacting to the mention of a summary with its outcome.
+	AddReactionEvent = "add_reaction"
 )
 
 // StartEvent adds a start event marker to the given trace span with a stack trace.
@@ -40,6 +105,27 @@ func EndEvent(t trace.Span, evt EventType) {
 	t.AddEvent(string(evt) + "_end")
 }
 
+// Measure runs fn between the start and end markers of evt and records how long it took.
+//
+// The end marker is added even if fn returns early with an error or panics, and carries the duration
+// in the "duration_ms" attribute, the same duration is set on the span as "<evt>.duration_ms".
+//
+// Returns the error of fn untouched.
+func Measure(t trace.Span, evt EventType, fn func() error) error {
+	start := time.Now()
+
+	StartEvent(t, evt)
+
+	defer func() {
+		d := time.Since(start).Milliseconds()
+
+		t.AddEvent(string(evt)+"_end", trace.WithAttributes(attribute.Int64("duration_ms", d)))
+		t.SetAttributes(attribute.Int64(string(evt)+".duration_ms", d))
+	}()
+
+	return fn()
+}
+
 // WrapErrorWithTrace records an error in the trace span and optionally wraps it with context.
 //
 // Parameters:
@@ -0,0 +1,81 @@
+package telemetry
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var errMeasureTest = errors.New("measure test")
+
+func recordMeasure(t *testing.T, fn func() error) (sdktrace.ReadOnlySpan, error) {
+	t.Helper()
+
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+
+	_, span := tp.Tracer("test").Start(t.Context(), "test")
+
+	var err error
+
+	func() {
+		defer func() {
+			_ = recover()
+		}()
+
+		err = Measure(span, SummarizeThreadEvent, fn)
+	}()
+
+	span.End()
+
+	require.Len(t, sr.Ended(), 1)
+
+	return sr.Ended()[0], err
+}
+
+func eventNames(span sdktrace.ReadOnlySpan) []string {
+	names := []string{}
+	for _, e := range span.Events() {
+		names = append(names, e.Name)
+	}
+
+	return names
+}
+
+func TestMeasure_RecordsMarkersAndDuration(t *testing.T) {
+	t.Parallel()
+
+	span, err := recordMeasure(t, func() error { return nil })
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"summarize_thread_start", "summarize_thread_end"}, eventNames(span))
+	assert.Equal(t, "duration_ms", string(span.Events()[1].Attributes[0].Key))
+
+	keys := []string{}
+	for _, a := range span.Attributes() {
+		keys = append(keys, string(a.Key))
+	}
+
+	assert.Contains(t, keys, "summarize_thread.duration_ms")
+}
+
+func TestMeasure_ReturnsErrorAndEnds(t *testing.T) {
+	t.Parallel()
+
+	span, err := recordMeasure(t, func() error { return errMeasureTest })
+	require.ErrorIs(t, err, errMeasureTest)
+
+	assert.Equal(t, []string{"summarize_thread_start", "summarize_thread_end"}, eventNames(span))
+}
+
+func TestMeasure_EndsOnPanic(t *testing.T) {
+	t.Parallel()
+
+	span, _ := recordMeasure(t, func() error { panic("boom") })
+
+	assert.Equal(t, []string{"summarize_thread_start", "summarize_thread_end"}, eventNames(span))
+}
@@ -0,0 +1,63 @@
+package musicextractors
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// The patterns used to parse Amazon Music track pages.
+var (
+	// amazonMusicTrackIDRegex captures the ASIN of the track, either from the track path or the trackAsin parameter.
+	amazonMusicTrackIDRegex = regexp.MustCompile(`(?:/tracks/|[?&]trackAsin=)(\w+)`)
+	// amazonMusicTitleSuffixRegex matches the store name Amazon appends to the Open Graph titles.
+	amazonMusicTitleSuffixRegex = regexp.MustCompile(`\s+(?:on|bei|sur|en|su) Amazon Music(?: Unlimited)?$`)
+)
+
+// amazonMusicClient resolves track metadata from the Open Graph tags of Amazon Music pages.
+type amazonMusicClient struct {
+	httpClient *http.Client
+}
+
+// metadata fetches the track page and parses its "Title by Artist on Amazon Music" Open Graph title.
+func (c *amazonMusicClient) metadata(ctx context.Context, musicURL string) (TrackMetadata, error) {
+	html, err := fetchPage(ctx, c.httpClient, musicURL, "")
+	if err != nil {
+		return TrackMetadata{}, err
+	}
+
+	titleMatches := ogTitleRegex.FindStringSubmatch(html)
+	if len(titleMatches) < 2 {
+		return TrackMetadata{}, ErrNoTitleFound
+	}
+
+	title := amazonMusicTitleSuffixRegex.ReplaceAllString(strings.TrimSpace(titleMatches[1]), "")
+	if title == "" {
+		return TrackMetadata{}, ErrNoTitleFound
+	}
+
+	m := TrackMetadata{Title: title}
+
+	// Song titles can contain " by " too, the artist is always after the last one
+	if i := strings.LastIndex(title, " by "); i > 0 {
+		m.Title, m.Artist = title[:i], title[i+len(" by "):]
+	}
+
+	if idMatches := amazonMusicTrackIDRegex.FindStringSubmatch(musicURL); len(idMatches) == 2 {
+		m.ProviderID = idMatches[1]
+	}
+
+	if imageMatches := ogImageRegex.FindStringSubmatch(html); len(imageMatches) == 2 {
+		m.ArtworkURL = imageMatches[1]
+	}
+
+	return m, nil
+}
+
+// AmazonMusicMetadataExtractor fetches and extracts the track metadata from an Amazon Music URL using Open Graph meta tags.
+func AmazonMusicMetadataExtractor(ctx context.Context, musicURL string) (TrackMetadata, error) {
+	c := amazonMusicClient{httpClient: http.DefaultClient}
+
+	return c.metadata(ctx, musicURL)
+}
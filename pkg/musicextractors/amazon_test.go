@@ -0,0 +1,76 @@
+package musicextractors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAmazonMusicClient_Metadata(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr error
+		name    string
+		body    string
+		want    TrackMetadata
+		status  int
+	}{
+		{
+			name:   "track page",
+			status: http.StatusOK,
+			body: `<meta property="og:title" content="Never Gonna Give You Up by Rick Astley on Amazon Music">` +
+				`<meta property="og:image" content="https://m.media-amazon.com/images/I/cover.jpg">`,
+			want: TrackMetadata{
+				Title:      "Never Gonna Give You Up",
+				Artist:     "Rick Astley",
+				ProviderID: "B0026NT2S8",
+				ArtworkURL: "https://m.media-amazon.com/images/I/cover.jpg",
+			},
+		},
+		{
+			name:   "title containing by",
+			status: http.StatusOK,
+			body:   `<meta property="og:title" content="Stand by Me by Ben E. King on Amazon Music Unlimited">`,
+			want:   TrackMetadata{Title: "Stand by Me", Artist: "Ben E. King", ProviderID: "B0026NT2S8"},
+		},
+		{
+			name:    "no title",
+			status:  http.StatusOK,
+			body:    `<html></html>`,
+			wantErr: ErrNoTitleFound,
+		},
+		{
+			name:    "blocked request",
+			status:  http.StatusServiceUnavailable,
+			wantErr: ErrRequestFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			t.Cleanup(srv.Close)
+
+			c := amazonMusicClient{httpClient: srv.Client()}
+
+			got, err := c.metadata(t.Context(), srv.URL+"/albums/B0026NT2RE?trackAsin=B0026NT2S8")
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
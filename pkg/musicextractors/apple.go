@@ -0,0 +1,117 @@
+package musicextractors
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// The patterns used to parse Apple Music and Shazam track pages.
+var (
+	// appleMusicTrackIDRegex captures the ID of the song, either from the i parameter of album links or the song path.
+	appleMusicTrackIDRegex = regexp.MustCompile(`(?:[?&]i=|/song/(?:[^/?]+/)?)(\d+)`)
+	// appleMusicTitleSuffixRegex matches the store name Apple appends to the Open Graph titles,
+	// both the "on Apple Music" and the newer "- Apple Music" form.
+	appleMusicTitleSuffixRegex = regexp.MustCompile(`\s+(?:on|-) Apple Music$`)
+	// shazamTrackIDRegex captures the ID of the Shazam track from its path.
+	shazamTrackIDRegex = regexp.MustCompile(`/(?:track|song)/(\d+)`)
+	// shazamTitleSuffixRegex matches the description Shazam appends to the Open Graph titles.
+	shazamTitleSuffixRegex = regexp.MustCompile(`:\s+Song Lyrics,.*$`)
+)
+
+// appleMusicClient resolves track metadata from the Open Graph tags of Apple Music and Shazam pages.
+type appleMusicClient struct {
+	httpClient *http.Client
+}
+
+// ogTitle fetches the page and returns its trimmed Open Graph title, with the left-to-right mark Apple prefixes it with removed.
+func (c *appleMusicClient) ogTitle(ctx context.Context, pageURL string) (string, string, error) {
+	html, err := fetchPage(ctx, c.httpClient, pageURL, "")
+	if err != nil {
+		return "", "", err
+	}
+
+	titleMatches := ogTitleRegex.FindStringSubmatch(html)
+	if len(titleMatches) < 2 {
+		return "", "", ErrNoTitleFound
+	}
+
+	return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(titleMatches[1]), "\u200e")), html, nil
+}
+
+// metadata fetches the song page and parses its "Title - Song by Artist - Apple Music" Open Graph title.
+func (c *appleMusicClient) metadata(ctx context.Context, musicURL string) (TrackMetadata, error) {
+	title, html, err := c.ogTitle(ctx, musicURL)
+	if err != nil {
+		return TrackMetadata{}, err
+	}
+
+	title = appleMusicTitleSuffixRegex.ReplaceAllString(title, "")
+	if title == "" {
+		return TrackMetadata{}, ErrNoTitleFound
+	}
+
+	m := TrackMetadata{Title: title}
+
+	// Song titles can contain " by " too, the artist is always after the last one
+	if i := strings.LastIndex(title, " by "); i > 0 {
+		m.Title, m.Artist = strings.TrimSuffix(title[:i], " - Song"), title[i+len(" by "):]
+	}
+
+	if idMatches := appleMusicTrackIDRegex.FindStringSubmatch(musicURL); len(idMatches) == 2 {
+		m.ProviderID = idMatches[1]
+	}
+
+	if imageMatches := ogImageRegex.FindStringSubmatch(html); len(imageMatches) == 2 {
+		m.ArtworkURL = imageMatches[1]
+	}
+
+	m.DurationSeconds = pageDuration(html)
+
+	return m, nil
+}
+
+// shazamMetadata fetches the track page and parses its "Title - Artist: Song Lyrics, ..." Open Graph title.
+func (c *appleMusicClient) shazamMetadata(ctx context.Context, trackURL string) (TrackMetadata, error) {
+	title, html, err := c.ogTitle(ctx, trackURL)
+	if err != nil {
+		return TrackMetadata{}, err
+	}
+
+	title = shazamTitleSuffixRegex.ReplaceAllString(title, "")
+	if title == "" {
+		return TrackMetadata{}, ErrNoTitleFound
+	}
+
+	m := TrackMetadata{Title: title}
+
+	// Song titles can contain " - " too, the artist is always after the last one
+	if i := strings.LastIndex(title, " - "); i > 0 {
+		m.Title, m.Artist = title[:i], title[i+len(" - "):]
+	}
+
+	if idMatches := shazamTrackIDRegex.FindStringSubmatch(trackURL); len(idMatches) == 2 {
+		m.ProviderID = idMatches[1]
+	}
+
+	if imageMatches := ogImageRegex.FindStringSubmatch(html); len(imageMatches) == 2 {
+		m.ArtworkURL = imageMatches[1]
+	}
+
+	return m, nil
+}
+
+// AppleMusicMetadataExtractor fetches and extracts the track metadata from an Apple Music URL using Open Graph meta tags.
+func AppleMusicMetadataExtractor(ctx context.Context, musicURL string) (TrackMetadata, error) {
+	c := appleMusicClient{httpClient: http.DefaultClient}
+
+	return c.metadata(ctx, musicURL)
+}
+
+// ShazamMetadataExtractor fetches and extracts the track metadata from a Shazam URL using Open Graph meta tags.
+func ShazamMetadataExtractor(ctx context.Context, trackURL string) (TrackMetadata, error) {
+	c := appleMusicClient{httpClient: http.DefaultClient}
+
+	return c.shazamMetadata(ctx, trackURL)
+}
@@ -0,0 +1,94 @@
+package musicextractors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppleMusicClient_Metadata(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr error
+		name    string
+		body    string
+		want    TrackMetadata
+		status  int
+	}{
+		{
+			name:   "song page",
+			status: http.StatusOK,
+			body: `<meta property="og:title" content="Never Gonna Give You Up - Song by Rick Astley - Apple Music">` +
+				`<meta property="og:image" content="https://is1-ssl.mzstatic.com/image/cover.jpg">` +
+				`<meta property="music:duration" content="213">`,
+			want: TrackMetadata{
+				Title:           "Never Gonna Give You Up",
+				Artist:          "Rick Astley",
+				ProviderID:      "1558534271",
+				ArtworkURL:      "https://is1-ssl.mzstatic.com/image/cover.jpg",
+				DurationSeconds: 213,
+			},
+		},
+		{
+			name:   "left-to-right mark and legacy title",
+			status: http.StatusOK,
+			body:   "<meta property=\"og:title\" content=\"\u200eStand by Me by Ben E. King on Apple Music\">",
+			want:   TrackMetadata{Title: "Stand by Me", Artist: "Ben E. King", ProviderID: "1558534271"},
+		},
+		{
+			name:    "no title",
+			status:  http.StatusOK,
+			body:    `<html></html>`,
+			wantErr: ErrNoTitleFound,
+		},
+		{
+			name:    "missing page",
+			status:  http.StatusNotFound,
+			wantErr: ErrRequestFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			t.Cleanup(srv.Close)
+
+			c := appleMusicClient{httpClient: srv.Client()}
+
+			got, err := c.metadata(t.Context(), srv.URL+"/us/album/whenever-you-need-somebody/1558533900?i=1558534271")
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAppleMusicClient_ShazamMetadata(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`<meta property="og:title" content="Never Gonna Give You Up - Rick Astley: ` +
+			`Song Lyrics, Music Videos &amp; Concerts">`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c := appleMusicClient{httpClient: srv.Client()}
+
+	got, err := c.shazamMetadata(t.Context(), srv.URL+"/track/5933917/never-gonna-give-you-up")
+	require.NoError(t, err)
+	assert.Equal(t, TrackMetadata{Title: "Never Gonna Give You Up", Artist: "Rick Astley", ProviderID: "5933917"}, got)
+}
@@ -0,0 +1,125 @@
+package musicextractors
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of a CircuitBreaker.
+type CircuitState string
+
+const (
+	// CircuitClosed lets every call through, this is the healthy state.
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen rejects every call with ErrCircuitOpen until the cooldown passes.
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen lets a single trial call through, its outcome closes or re-opens the circuit.
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// CircuitBreaker stops calling a provider after consecutive request failures,
+// so an outage fails fast instead of stalling every lookup until it times out.
+//
+// Only ErrRequestFailed counts as a failure, a missing title means the provider is up and answering.
+type CircuitBreaker struct {
+	openedAt  time.Time
+	now       func() time.Time
+	state     CircuitState
+	threshold int
+	failures  int
+	cooldown  time.Duration
+	mu        sync.Mutex
+}
+
+// NewCircuitBreaker creates a closed breaker that opens after threshold consecutive failures
+// and lets a trial call through after cooldown.
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		now:       time.Now,
+		state:     CircuitClosed,
+		threshold: max(1, threshold),
+		cooldown:  cooldown,
+	}
+}
+
+// State returns the current state of the breaker.
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
+
+// allow reports whether a call may go through, moving an open breaker to half-open once the cooldown passed.
+func (b *CircuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitClosed:
+		return true
+	case CircuitOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+
+		b.state = CircuitHalfOpen
+
+		return true
+	case CircuitHalfOpen:
+		// A trial call is already in flight
+		return false
+	}
+
+	return false
+}
+
+// record updates the breaker with the outcome of a call.
+func (b *CircuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !errors.Is(err, ErrRequestFailed) {
+		b.state = CircuitClosed
+		b.failures = 0
+
+		return
+	}
+
+	b.failures++
+
+	if b.state == CircuitHalfOpen || b.failures >= b.threshold {
+		b.state = CircuitOpen
+		b.openedAt = b.now()
+	}
+}
+
+// WithCircuitBreaker wraps fn with b, returning ErrCircuitOpen without calling fn while the circuit is open.
+func WithCircuitBreaker(b *CircuitBreaker, fn MetadataExtractorFunc) MetadataExtractorFunc {
+	return func(ctx context.Context, url string) (TrackMetadata, error) {
+		if !b.allow() {
+			return TrackMetadata{}, ErrCircuitOpen
+		}
+
+		m, err := fn(ctx, url)
+		b.record(err)
+
+		return m, err
+	}
+}
+
+// WithTimeout wraps fn so every call is cancelled after d, a non-positive d returns fn as is.
+func WithTimeout(d time.Duration, fn MetadataExtractorFunc) MetadataExtractorFunc {
+	if d <= 0 {
+		return fn
+	}
+
+	return func(ctx context.Context, url string) (TrackMetadata, error) {
+		ctx, cancel := context.WithTimeout(ctx, d)
+		defer cancel()
+
+		return fn(ctx, url)
+	}
+}
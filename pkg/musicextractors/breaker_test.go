@@ -0,0 +1,89 @@
+package musicextractors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithCircuitBreaker_OpensAndRecovers(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_700_000_000, 0)
+	b := NewCircuitBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	calls := 0
+	failing := true
+
+	extract := WithCircuitBreaker(b, func(context.Context, string) (TrackMetadata, error) {
+		calls++
+
+		if failing {
+			return TrackMetadata{}, ErrRequestFailed
+		}
+
+		return TrackMetadata{Title: "Song"}, nil
+	})
+
+	for range 2 {
+		_, err := extract(t.Context(), "https://example.com")
+		require.ErrorIs(t, err, ErrRequestFailed)
+	}
+
+	assert.Equal(t, CircuitOpen, b.State())
+
+	_, err := extract(t.Context(), "https://example.com")
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 2, calls, "open circuit must not call the provider")
+
+	// The trial call after the cooldown fails, so the circuit opens again
+	now = now.Add(time.Minute)
+
+	_, err = extract(t.Context(), "https://example.com")
+	require.ErrorIs(t, err, ErrRequestFailed)
+	assert.Equal(t, CircuitOpen, b.State())
+
+	now = now.Add(time.Minute)
+	failing = false
+
+	m, err := extract(t.Context(), "https://example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "Song", m.Title)
+	assert.Equal(t, CircuitClosed, b.State())
+}
+
+func TestWithCircuitBreaker_MissingTitleIsNotAFailure(t *testing.T) {
+	t.Parallel()
+
+	b := NewCircuitBreaker(1, time.Minute)
+
+	extract := WithCircuitBreaker(b, func(context.Context, string) (TrackMetadata, error) {
+		return TrackMetadata{}, ErrNoTitleFound
+	})
+
+	for range 3 {
+		_, err := extract(t.Context(), "https://example.com")
+		require.ErrorIs(t, err, ErrNoTitleFound)
+	}
+
+	assert.Equal(t, CircuitClosed, b.State())
+}
+
+func TestWithTimeout_CancelsSlowCalls(t *testing.T) {
+	t.Parallel()
+
+	extract := WithTimeout(10*time.Millisecond, func(ctx context.Context, _ string) (TrackMetadata, error) {
+		<-ctx.Done()
+		return TrackMetadata{}, ErrRequestFailed
+	})
+
+	start := time.Now()
+
+	_, err := extract(t.Context(), "https://example.com")
+	require.ErrorIs(t, err, ErrRequestFailed)
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,95 @@
+package musicextractors
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+const odesliAPIURL = "https://api.song.link/v1-alpha.1/links"
+
+// odesliPlatforms maps the Odesli platform names to the implemented providers.
+var odesliPlatforms = map[string]ExtractProvider{
+	"spotify":      SpotifyProvider,
+	"youtube":      YouTubeProvider,
+	"youtubeMusic": YoutTubeMusicProvider,
+	"audiomack":    AudiomackProvider,
+	"amazonMusic":  AmazonMusicProvider,
+	"appleMusic":   AppleMusicProvider,
+}
+
+// CrossLinker finds the same track on other providers.
+type CrossLinker interface {
+	// CrossLink returns the URL of the track behind musicURL for every provider it's available on,
+	// including the provider of musicURL itself.
+	CrossLink(ctx context.Context, musicURL string) (map[ExtractProvider]string, error)
+}
+
+// OdesliCrossLinker is a CrossLinker backed by the Odesli (song.link) API.
+type OdesliCrossLinker struct {
+	httpClient *http.Client
+	apiKey     string
+	apiURL     string
+}
+
+var _ CrossLinker = (*OdesliCrossLinker)(nil)
+
+// CrossLink looks up musicURL on Odesli and returns its URL on every implemented provider.
+func (o *OdesliCrossLinker) CrossLink(ctx context.Context, musicURL string) (map[ExtractProvider]string, error) {
+	query := url.Values{}
+	query.Set("url", musicURL)
+
+	if o.apiKey != "" {
+		query.Set("key", o.apiKey)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, o.apiURL+"?"+query.Encode(), http.NoBody)
+	if err != nil {
+		return nil, ErrRequestFailed
+	}
+
+	resp, err := o.httpClient.Do(request)
+	if err != nil {
+		return nil, ErrRequestFailed
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp.StatusCode)
+	}
+
+	var result struct {
+		LinksByPlatform map[string]struct {
+			URL string `json:"url"`
+		} `json:"linksByPlatform"`
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, ErrRequestFailed
+	}
+
+	links := make(map[ExtractProvider]string, len(odesliPlatforms))
+
+	for platform, link := range result.LinksByPlatform {
+		if p, ok := odesliPlatforms[platform]; ok && link.URL != "" {
+			links[p] = link.URL
+		}
+	}
+
+	return links, nil
+}
+
+// NewOdesliCrossLinker creates a CrossLinker backed by the Odesli API.
+//
+// apiKey is optional, without it the API is limited to 10 requests per minute.
+func NewOdesliCrossLinker(apiKey string) *OdesliCrossLinker {
+	return &OdesliCrossLinker{
+		httpClient: http.DefaultClient,
+		apiKey:     apiKey,
+		apiURL:     odesliAPIURL,
+	}
+}
@@ -0,0 +1,44 @@
+package musicextractors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOdesliCrossLinker_CrossLink(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("url") != "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		assert.Equal(t, "key", r.URL.Query().Get("key"))
+
+		_, _ = w.Write([]byte(`{"linksByPlatform":{
+			"spotify":{"url":"https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"},
+			"youtube":{"url":"https://www.youtube.com/watch?v=dQw4w9WgXcQ"},
+			"youtubeMusic":{"url":"https://music.youtube.com/watch?v=dQw4w9WgXcQ"},
+			"deezer":{"url":"https://www.deezer.com/track/1"}
+		}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	o := &OdesliCrossLinker{httpClient: srv.Client(), apiKey: "key", apiURL: srv.URL}
+
+	links, err := o.CrossLink(t.Context(), "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT")
+	require.NoError(t, err)
+	assert.Equal(t, map[ExtractProvider]string{
+		SpotifyProvider:       "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT",
+		YouTubeProvider:       "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		YoutTubeMusicProvider: "https://music.youtube.com/watch?v=dQw4w9WgXcQ",
+	}, links)
+
+	_, err = o.CrossLink(t.Context(), "https://example.com")
+	require.ErrorIs(t, err, ErrRequestFailed)
+}
@@ -1,6 +1,11 @@
 package musicextractors
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
 
 var (
 	// ErrNoURLFound returned by MusicURLExtractorFunc if no URL was found in text.
@@ -12,4 +17,104 @@ var (
 	ErrNoTitleFound = errors.New("no title found in page")
 	// ErrRequestFailed returned by TitleExtractorFunc if it was unable to make the necessary API calls to determine the title.
 	ErrRequestFailed = errors.New("failed to fetch URL")
+	// ErrRateLimited returned by TitleExtractorFunc if the provider rejected the request with 429 Too Many Requests,
+	// it wraps ErrRequestFailed.
+	ErrRateLimited = fmt.Errorf("%w: rate limited", ErrRequestFailed)
+	// ErrInvalidURL returned by NormalizeURL if the input is not an absolute URL.
+	ErrInvalidURL = errors.New("invalid URL")
+	// ErrNoTrackID returned by TitleExtractorFunc if it was unable to find the provider's track ID in the URL.
+	ErrNoTrackID = errors.New("no track ID found in URL")
+	// ErrCircuitOpen returned by extractors wrapped with WithCircuitBreaker while the provider is considered down.
+	ErrCircuitOpen = errors.New("provider circuit breaker is open")
+	// ErrTooManyRedirects returned by RedirectResolver if a short URL doesn't leave the shortener hosts within the hop limit.
+	ErrTooManyRedirects = errors.New("too many redirects")
+	// ErrInvalidProvider returned by NewRegexProvider if the provider definition is invalid.
+	ErrInvalidProvider = errors.New("invalid provider definition")
+	// ErrNoPlaylistTracks returned by PlaylistCreator if none of the links is a track of its provider.
+	ErrNoPlaylistTracks = errors.New("no tracks of the provider to add to the playlist")
+	// ErrPlaylistNotFound returned by the playlist APIs if the playlist doesn't exist anymore.
+	ErrPlaylistNotFound = errors.New("playlist not found")
 )
+
+// statusError returns the error of an unexpected HTTP status, ErrRateLimited or ErrRequestFailed.
+func statusError(status int) error {
+	if status == http.StatusTooManyRequests {
+		return ErrRateLimited
+	}
+
+	return ErrRequestFailed
+}
+
+// ErrorKind classifies why the extraction of a link failed.
+type ErrorKind string
+
+const (
+	// ErrorKindNoURL means the text had no link of the provider.
+	ErrorKindNoURL ErrorKind = "no_url"
+	// ErrorKindMultipleURLs means the text had several links of the provider.
+	ErrorKindMultipleURLs ErrorKind = "multiple_urls"
+	// ErrorKindRateLimited means the provider rejected the lookup because of too many requests.
+	ErrorKindRateLimited ErrorKind = "rate_limited"
+	// ErrorKindUnavailable means the provider couldn't be reached or its circuit breaker is open.
+	ErrorKindUnavailable ErrorKind = "unavailable"
+	// ErrorKindTitleNotFound means the provider answered, but the title couldn't be parsed from it,
+	// usually because the page or the API response changed.
+	ErrorKindTitleNotFound ErrorKind = "title_not_found"
+	// ErrorKindUnknown is every other failure.
+	ErrorKindUnknown ErrorKind = "unknown"
+)
+
+// ExtractionError is a failed extraction with the provider and URL it happened with.
+//
+// It wraps one of the sentinel errors, so errors.Is keeps working on it.
+type ExtractionError struct {
+	Err      error
+	Provider ExtractProvider
+	// URL is empty if the URL extraction itself failed.
+	URL  string
+	Kind ErrorKind
+}
+
+// NewExtractionError wraps err with the provider and URL, classifying it by the sentinel error it wraps.
+//
+// Returns nil if err is nil.
+func NewExtractionError(provider ExtractProvider, url string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &ExtractionError{Err: err, Provider: provider, URL: url, Kind: errorKind(err)}
+}
+
+// errorKind classifies err by the sentinel error it wraps.
+func errorKind(err error) ErrorKind {
+	switch {
+	case errors.Is(err, ErrNoURLFound):
+		return ErrorKindNoURL
+	case errors.Is(err, ErrMultipleResult):
+		return ErrorKindMultipleURLs
+	// Checked before ErrRequestFailed, which it wraps
+	case errors.Is(err, ErrRateLimited):
+		return ErrorKindRateLimited
+	case errors.Is(err, ErrRequestFailed), errors.Is(err, ErrCircuitOpen), errors.Is(err, context.DeadlineExceeded):
+		return ErrorKindUnavailable
+	case errors.Is(err, ErrNoTitleFound), errors.Is(err, ErrNoTrackID):
+		return ErrorKindTitleNotFound
+	default:
+		return ErrorKindUnknown
+	}
+}
+
+// Error implements the error interface.
+func (e *ExtractionError) Error() string {
+	if e.URL == "" {
+		return fmt.Sprintf("%s: %s", e.Provider, e.Err)
+	}
+
+	return fmt.Sprintf("%s %s: %s", e.Provider, e.URL, e.Err)
+}
+
+// Unwrap returns the wrapped sentinel error.
+func (e *ExtractionError) Unwrap() error {
+	return e.Err
+}
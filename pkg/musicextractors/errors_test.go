@@ -0,0 +1,74 @@
+package musicextractors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewExtractionError_Kind(t *testing.T) {
+	t.Parallel()
+
+	errOther := errors.New("boom")
+
+	tests := []struct {
+		err  error
+		name string
+		want ErrorKind
+	}{
+		{name: "no url", err: ErrNoURLFound, want: ErrorKindNoURL},
+		{name: "multiple urls", err: ErrMultipleResult, want: ErrorKindMultipleURLs},
+		{name: "rate limited", err: ErrRateLimited, want: ErrorKindRateLimited},
+		{name: "request failed", err: ErrRequestFailed, want: ErrorKindUnavailable},
+		{name: "circuit open", err: ErrCircuitOpen, want: ErrorKindUnavailable},
+		{name: "timeout", err: fmt.Errorf("lookup: %w", context.DeadlineExceeded), want: ErrorKindUnavailable},
+		{name: "no title", err: ErrNoTitleFound, want: ErrorKindTitleNotFound},
+		{name: "no track id", err: ErrNoTrackID, want: ErrorKindTitleNotFound},
+		{name: "other", err: errOther, want: ErrorKindUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := NewExtractionError(SpotifyProvider, "https://open.spotify.com/track/abc", tt.err)
+
+			var extErr *ExtractionError
+			require.ErrorAs(t, err, &extErr)
+			assert.Equal(t, tt.want, extErr.Kind)
+			assert.Equal(t, SpotifyProvider, extErr.Provider)
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestNewExtractionError_Nil(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, NewExtractionError(SpotifyProvider, "", nil))
+}
+
+func TestExtractionError_Error(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "spotify: no URL found in text", NewExtractionError(SpotifyProvider, "", ErrNoURLFound).Error())
+	assert.Equal(t,
+		"youtube https://youtu.be/abc: no title found in page",
+		NewExtractionError(YouTubeProvider, "https://youtu.be/abc", ErrNoTitleFound).Error(),
+	)
+}
+
+func TestStatusError(t *testing.T) {
+	t.Parallel()
+
+	require.ErrorIs(t, statusError(http.StatusTooManyRequests), ErrRateLimited)
+	// Rate limits still count as failed requests, like for the circuit breaker
+	require.ErrorIs(t, statusError(http.StatusTooManyRequests), ErrRequestFailed)
+	require.NotErrorIs(t, statusError(http.StatusInternalServerError), ErrRateLimited)
+	require.ErrorIs(t, statusError(http.StatusInternalServerError), ErrRequestFailed)
+}
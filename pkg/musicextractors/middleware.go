@@ -0,0 +1,13 @@
+package musicextractors
+
+// TitleExtractorMiddleware decorates the title lookup of provider, like with tracing, metrics or caching.
+type TitleExtractorMiddleware func(provider ExtractProvider, next MetadataExtractorFunc) MetadataExtractorFunc
+
+// WrapTitleExtractor decorates the title lookup fn with every middleware, the first one is the outermost.
+func WrapTitleExtractor(provider ExtractProvider, fn MetadataExtractorFunc, mws ...TitleExtractorMiddleware) MetadataExtractorFunc {
+	for i := len(mws) - 1; i >= 0; i-- {
+		fn = mws[i](provider, fn)
+	}
+
+	return fn
+}
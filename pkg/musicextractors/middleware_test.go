@@ -0,0 +1,32 @@
+package musicextractors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrapTitleExtractor_Order(t *testing.T) {
+	t.Parallel()
+
+	suffix := func(s string) TitleExtractorMiddleware {
+		return func(_ ExtractProvider, next MetadataExtractorFunc) MetadataExtractorFunc {
+			return func(ctx context.Context, url string) (TrackMetadata, error) {
+				m, err := next(ctx, url)
+				m.Title += s
+
+				return m, err
+			}
+		}
+	}
+
+	fn := WrapTitleExtractor(YouTubeProvider, func(context.Context, string) (TrackMetadata, error) {
+		return TrackMetadata{Title: "Song"}, nil
+	}, suffix(" outer"), suffix(" inner"))
+
+	m, err := fn(t.Context(), "https://www.youtube.com/watch?v=dQw4w9WgXcQ")
+	require.NoError(t, err)
+	assert.Equal(t, "Song inner outer", m.Title)
+}
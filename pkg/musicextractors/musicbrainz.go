@@ -0,0 +1,172 @@
+package musicextractors
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	musicBrainzAPIURL = "https://musicbrainz.org/ws/2"
+	// musicBrainzUserAgent identifies the bot, MusicBrainz blocks requests without a meaningful user agent.
+	musicBrainzUserAgent = "wap-bot ( https://github.com/Shikachuu/wap-bot )"
+	// musicBrainzRateLimit is the minimum time between two requests allowed by MusicBrainz.
+	musicBrainzRateLimit = time.Second
+	// musicBrainzMaxGenres is the number of most voted tags kept as genres.
+	musicBrainzMaxGenres = 3
+)
+
+// Enricher adds extra information to already resolved track metadata.
+type Enricher interface {
+	// Enrich returns m extended with everything the enricher knows about the track.
+	Enrich(ctx context.Context, m TrackMetadata) (TrackMetadata, error)
+}
+
+// MusicBrainzEnricher is an Enricher that looks up the release year, genre tags and ISRC of tracks on MusicBrainz.
+//
+// Requests are spaced out to respect the one request per second rate limit of MusicBrainz.
+type MusicBrainzEnricher struct {
+	nextRequest time.Time
+	httpClient  *http.Client
+	apiURL      string
+	interval    time.Duration
+	mu          sync.Mutex
+}
+
+var _ Enricher = (*MusicBrainzEnricher)(nil)
+
+// musicBrainzTag is a user submitted tag of a MusicBrainz entity, count is the number of votes.
+type musicBrainzTag struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// musicBrainzRecording is the part of a MusicBrainz recording search result we use.
+type musicBrainzRecording struct {
+	FirstReleaseDate string           `json:"first-release-date"`
+	Tags             []musicBrainzTag `json:"tags"`
+	ISRCs            []string         `json:"isrcs"`
+}
+
+// wait blocks until the rate limit allows the next request or ctx is done.
+func (mb *MusicBrainzEnricher) wait(ctx context.Context) error {
+	mb.mu.Lock()
+	now := time.Now()
+
+	at := now
+	if mb.nextRequest.After(now) {
+		at = mb.nextRequest
+	}
+
+	mb.nextRequest = at.Add(mb.interval)
+	mb.mu.Unlock()
+
+	timer := time.NewTimer(at.Sub(now))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ErrRequestFailed
+	case <-timer.C:
+		return nil
+	}
+}
+
+// searchRecording returns the best matching recording of artist and title.
+func (mb *MusicBrainzEnricher) searchRecording(ctx context.Context, artist, title string) (musicBrainzRecording, error) {
+	if err := mb.wait(ctx); err != nil {
+		return musicBrainzRecording{}, err
+	}
+
+	query := url.Values{}
+	query.Set("query", fmt.Sprintf("artist:%q AND recording:%q", artist, title))
+	query.Set("fmt", "json")
+	query.Set("limit", "1")
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, mb.apiURL+"/recording?"+query.Encode(), http.NoBody)
+	if err != nil {
+		return musicBrainzRecording{}, ErrRequestFailed
+	}
+
+	request.Header.Set("User-Agent", musicBrainzUserAgent)
+	request.Header.Set("Accept", "application/json")
+
+	resp, err := mb.httpClient.Do(request)
+	if err != nil {
+		return musicBrainzRecording{}, ErrRequestFailed
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return musicBrainzRecording{}, statusError(resp.StatusCode)
+	}
+
+	var result struct {
+		Recordings []musicBrainzRecording `json:"recordings"`
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return musicBrainzRecording{}, ErrRequestFailed
+	}
+
+	if len(result.Recordings) == 0 {
+		return musicBrainzRecording{}, ErrNoTitleFound
+	}
+
+	return result.Recordings[0], nil
+}
+
+// Enrich looks up the track by its artist and title, and sets the release year, the most voted tags as genres
+// and the ISRC, unless the provider already knew it.
+//
+// Tracks without a known artist are returned as is, a title alone is too ambiguous to search for.
+func (mb *MusicBrainzEnricher) Enrich(ctx context.Context, m TrackMetadata) (TrackMetadata, error) {
+	if m.Artist == "" || m.Title == "" {
+		return m, nil
+	}
+
+	recording, err := mb.searchRecording(ctx, m.Artist, m.Title)
+	if err != nil {
+		return m, err
+	}
+
+	// The release date is either YYYY, YYYY-MM or YYYY-MM-DD
+	if year, yErr := strconv.Atoi(strings.SplitN(recording.FirstReleaseDate, "-", 2)[0]); yErr == nil {
+		m.ReleaseYear = year
+	}
+
+	if m.ISRC == "" && len(recording.ISRCs) > 0 {
+		m.ISRC = strings.ToUpper(recording.ISRCs[0])
+	}
+
+	tags := slices.Clone(recording.Tags)
+	slices.SortStableFunc(tags, func(a, b musicBrainzTag) int {
+		return cmp.Compare(b.Count, a.Count)
+	})
+
+	for _, tag := range tags[:min(musicBrainzMaxGenres, len(tags))] {
+		m.Genres = append(m.Genres, tag.Name)
+	}
+
+	return m, nil
+}
+
+// NewMusicBrainzEnricher creates an Enricher backed by the public MusicBrainz API.
+func NewMusicBrainzEnricher() *MusicBrainzEnricher {
+	return &MusicBrainzEnricher{
+		httpClient: http.DefaultClient,
+		apiURL:     musicBrainzAPIURL,
+		interval:   musicBrainzRateLimit,
+	}
+}
@@ -0,0 +1,74 @@
+package musicextractors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMusicBrainzEnricher_Enrich(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/recording", r.URL.Path)
+		assert.Contains(t, r.Header.Get("User-Agent"), "wap-bot")
+
+		if r.URL.Query().Get("query") != `artist:"Rick Astley" AND recording:"Never Gonna Give You Up"` {
+			_, _ = w.Write([]byte(`{"recordings":[]}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"recordings":[{
+			"first-release-date":"1987-07-27",
+			"isrcs":["gbarl9300135"],
+			"tags":[{"name":"dance-pop","count":2},{"name":"pop","count":5},{"name":"80s","count":1},{"name":"synth-pop","count":1}]
+		}]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	mb := &MusicBrainzEnricher{httpClient: srv.Client(), apiURL: srv.URL}
+
+	got, err := mb.Enrich(t.Context(), TrackMetadata{Title: "Never Gonna Give You Up", Artist: "Rick Astley"})
+	require.NoError(t, err)
+	assert.Equal(t, 1987, got.ReleaseYear)
+	assert.Equal(t, []string{"pop", "dance-pop", "80s"}, got.Genres)
+	assert.Equal(t, "GBARL9300135", got.ISRC)
+
+	// An ISRC known by the provider is kept
+	got, err = mb.Enrich(t.Context(), TrackMetadata{Title: "Never Gonna Give You Up", Artist: "Rick Astley", ISRC: "GBARL0000001"})
+	require.NoError(t, err)
+	assert.Equal(t, "GBARL0000001", got.ISRC)
+
+	_, err = mb.Enrich(t.Context(), TrackMetadata{Title: "Unknown", Artist: "Nobody"})
+	require.ErrorIs(t, err, ErrNoTitleFound)
+
+	// Without an artist the lookup is skipped
+	got, err = mb.Enrich(t.Context(), TrackMetadata{Title: "Never Gonna Give You Up"})
+	require.NoError(t, err)
+	assert.Zero(t, got.ReleaseYear)
+}
+
+func TestMusicBrainzEnricher_SpacesOutRequests(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"recordings":[{"first-release-date":"1987"}]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	mb := &MusicBrainzEnricher{httpClient: srv.Client(), apiURL: srv.URL, interval: 50 * time.Millisecond}
+	m := TrackMetadata{Title: "Never Gonna Give You Up", Artist: "Rick Astley"}
+
+	start := time.Now()
+
+	for range 3 {
+		_, err := mb.Enrich(t.Context(), m)
+		require.NoError(t, err)
+	}
+
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
@@ -0,0 +1,100 @@
+package musicextractors
+
+import (
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// transliterations covers the latin letters that do not decompose into a base letter and a combining mark.
+var transliterations = strings.NewReplacer(
+	"ß", "ss", "æ", "ae", "œ", "oe", "ø", "o", "ł", "l", "đ", "d", "ð", "d", "þ", "th", "ı", "i",
+)
+
+// spotifyPathPrefix matches the locale (intl-de) and embed player path segments of Spotify links.
+var spotifyPathPrefix = regexp.MustCompile(`^/(?:intl-[a-zA-Z\-]+/)?(?:embed/)?`)
+
+// trackingParams are query parameters that only identify the share, not the shared content.
+var trackingParams = []string{"si", "feature", "fbclid", "igshid", "gclid"}
+
+// NormalizeURL produces the canonical form of a music URL, so the same track shared with different
+// share tokens results in the same URL.
+//
+// It unwraps Slack's `<url>` and `<url|label>` link formatting, lowercases the scheme and host,
+// strips tracking query parameters (si, utm_* etc.), the fragment and trailing slashes.
+// Locale prefixed and embed player Spotify links are rewritten to the plain track link,
+// YouTube Shorts and live links to the standard watch link.
+func NormalizeURL(rawURL string) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	rawURL = strings.TrimPrefix(rawURL, "<")
+	rawURL = strings.TrimSuffix(rawURL, ">")
+	rawURL, _, _ = strings.Cut(rawURL, "|")
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "", ErrInvalidURL
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = ""
+
+	if u.Host == "spotify.com" || strings.HasSuffix(u.Host, ".spotify.com") {
+		u.Path = spotifyPathPrefix.ReplaceAllString(u.Path, "/")
+	}
+
+	query := u.Query()
+
+	if u.Host == "youtube.com" || u.Host == "www.youtube.com" || u.Host == "m.youtube.com" {
+		if matches := youTubeShortsOrLiveRegex.FindStringSubmatch(u.Path); len(matches) == 2 {
+			u.Host = "www.youtube.com"
+			u.Path = "/watch"
+			query.Set("v", matches[1])
+		}
+	}
+	for key := range query {
+		if strings.HasPrefix(strings.ToLower(key), "utm_") || slices.Contains(trackingParams, strings.ToLower(key)) {
+			query.Del(key)
+		}
+	}
+
+	// Encode sorts the parameters by key, so the order of the parameters doesn't matter either
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// NormalizeTitle returns the NFC normalized form of a title, this is the form that should be displayed.
+func NormalizeTitle(title string) string {
+	return norm.NFC.String(strings.TrimSpace(title))
+}
+
+// TitleKey returns a comparison key for a title that is meant for deduplication and never for display.
+//
+// The key is NFKC normalized (folding width variants and compatibility characters), case folded
+// and has its whitespace collapsed. When transliterate is true diacritics are stripped as well,
+// so "Beyoncé" and "Beyonce" share the same key.
+func TitleKey(title string, transliterate bool) string {
+	key := strings.ToLower(norm.NFKC.String(title))
+
+	if transliterate {
+		t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+		if stripped, _, err := transform.String(t, key); err == nil {
+			key = stripped
+		}
+
+		key = transliterations.Replace(key)
+	}
+
+	return strings.Join(strings.Fields(key), " ")
+}
@@ -0,0 +1,162 @@
+package musicextractors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTitle(t *testing.T) {
+	t.Parallel()
+
+	// "e" followed by a combining acute accent
+	assert.Equal(t, "Beyoncé - Halo", NormalizeTitle(" Beyoncé - Halo "))
+}
+
+func TestTitleKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		a             string
+		b             string
+		transliterate bool
+		wantEqual     bool
+	}{
+		{
+			name:      "combining characters",
+			a:         "Beyoncé - Halo",
+			b:         "Beyoncé - Halo",
+			wantEqual: true,
+		},
+		{
+			name:      "full width variants",
+			a:         "ＡＢＣ - Song",
+			b:         "abc - song",
+			wantEqual: true,
+		},
+		{
+			name:      "whitespace differences",
+			a:         "Artist  -   Song ",
+			b:         "artist - song",
+			wantEqual: true,
+		},
+		{
+			name:      "diacritics kept without transliteration",
+			a:         "Beyoncé - Halo",
+			b:         "Beyonce - Halo",
+			wantEqual: false,
+		},
+		{
+			name:          "diacritics stripped with transliteration",
+			a:             "Beyoncé - Halo",
+			b:             "Beyonce - Halo",
+			transliterate: true,
+			wantEqual:     true,
+		},
+		{
+			name:          "non decomposable letters",
+			a:             "Røyksopp - Straße",
+			b:             "Royksopp - Strasse",
+			transliterate: true,
+			wantEqual:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.wantEqual, TitleKey(tt.a, tt.transliterate) == TitleKey(tt.b, tt.transliterate))
+		})
+	}
+}
+
+func TestNormalizeURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr error
+		name    string
+		raw     string
+		want    string
+	}{
+		{
+			name: "spotify share token",
+			raw:  "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT?si=abc123",
+			want: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT",
+		},
+		{
+			name: "utm parameters",
+			raw:  "https://www.youtube.com/watch?v=dQw4w9WgXcQ&utm_source=slack&UTM_Medium=share",
+			want: "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		},
+		{
+			name: "slack angle brackets",
+			raw:  "<https://youtu.be/dQw4w9WgXcQ>",
+			want: "https://youtu.be/dQw4w9WgXcQ",
+		},
+		{
+			name: "slack labeled link",
+			raw:  "<https://youtu.be/dQw4w9WgXcQ?si=xyz|my favourite song>",
+			want: "https://youtu.be/dQw4w9WgXcQ",
+		},
+		{
+			name: "uppercase host and scheme",
+			raw:  "HTTPS://Open.Spotify.COM/track/4cOdK2wGLETKBW3PvgPWqT",
+			want: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT",
+		},
+		{
+			name: "parameter order, fragment and trailing slash",
+			raw:  "https://music.youtube.com/watch/?list=RD1&v=abc#t=10",
+			want: "https://music.youtube.com/watch?list=RD1&v=abc",
+		},
+		{
+			name: "spotify locale prefix",
+			raw:  "https://open.spotify.com/intl-de/track/4cOdK2wGLETKBW3PvgPWqT?si=abc",
+			want: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT",
+		},
+		{
+			name: "spotify embed player",
+			raw:  "https://open.spotify.com/embed/track/4cOdK2wGLETKBW3PvgPWqT?utm_source=generator",
+			want: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT",
+		},
+		{
+			name: "youtube shorts",
+			raw:  "https://youtube.com/shorts/dQw4w9WgXcQ?si=abc",
+			want: "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		},
+		{
+			name: "youtube live",
+			raw:  "https://m.youtube.com/live/dQw4w9WgXcQ?feature=share",
+			want: "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		},
+		{
+			name: "intl prefix outside of spotify is kept",
+			raw:  "https://example.com/intl-de/track/1",
+			want: "https://example.com/intl-de/track/1",
+		},
+		{
+			name:    "relative url",
+			raw:     "/track/123",
+			wantErr: ErrInvalidURL,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NormalizeURL(tt.raw)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package musicextractors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenExpiryLeeway is subtracted from the token lifetime, so we never send a token that expires mid-flight.
+const tokenExpiryLeeway = 30 * time.Second
+
+// oauthClient requests and caches the access tokens of an OAuth 2.0 API, using the client credentials flow,
+// or the refresh token flow on behalf of a user if refreshToken is set.
+type oauthClient struct {
+	expiresAt    time.Time
+	httpClient   *http.Client
+	clientID     string
+	clientSecret string
+	refreshToken string
+	tokenURL     string
+	token        string
+	mu           sync.Mutex
+}
+
+// accessToken returns a cached access token, or requests a new one if the cached one expired.
+func (c *oauthClient) accessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && time.Now().Before(c.expiresAt) {
+		return c.token, nil
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+
+	if c.refreshToken != "" {
+		form.Set("grant_type", "refresh_token")
+		form.Set("refresh_token", c.refreshToken)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", ErrRequestFailed
+	}
+
+	request.SetBasicAuth(c.clientID, c.clientSecret)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(request)
+	if err != nil {
+		return "", ErrRequestFailed
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", statusError(resp.StatusCode)
+	}
+
+	var result struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int    `json:"expires_in"`
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil || result.AccessToken == "" {
+		return "", ErrRequestFailed
+	}
+
+	// The provider may rotate the refresh token, the previous one stops working once it did
+	if c.refreshToken != "" && result.RefreshToken != "" {
+		c.refreshToken = result.RefreshToken
+	}
+
+	c.token = result.AccessToken
+	c.expiresAt = time.Now().Add(time.Duration(result.ExpiresIn)*time.Second - tokenExpiryLeeway)
+
+	return c.token, nil
+}
+
+// call sends an API request to rawURL with the JSON encoded body, decoding the response into out if it's not nil.
+//
+// It's only used for playlists, so a missing resource is reported as ErrPlaylistNotFound.
+func (c *oauthClient) call(ctx context.Context, method, rawURL string, body, out any) error {
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	payload := []byte{}
+
+	if body != nil {
+		if payload, err = json.Marshal(body); err != nil {
+			return ErrRequestFailed
+		}
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, rawURL, bytes.NewReader(payload))
+	if err != nil {
+		return ErrRequestFailed
+	}
+
+	request.Header.Set("Authorization", "Bearer "+token)
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(request)
+	if err != nil {
+		return ErrRequestFailed
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrPlaylistNotFound
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return statusError(resp.StatusCode)
+	case out == nil:
+		return nil
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return ErrRequestFailed
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package musicextractors
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	youTubeOEmbedURL     = "https://youtube.com/oembed"
+	mixcloudOEmbedURL    = "https://app.mixcloud.com/oembed/"
+	audiomackOEmbedURL   = "https://audiomack.com/oembed"
+	vimeoOEmbedURL       = "https://vimeo.com/api/oembed.json"
+	dailymotionOEmbedURL = "https://www.dailymotion.com/services/oembed"
+)
+
+// oEmbedClient resolves track metadata via the oEmbed endpoint of a provider.
+type oEmbedClient struct {
+	httpClient *http.Client
+	apiURL     string
+}
+
+// oEmbedResponse is the part of an oEmbed response we use.
+type oEmbedResponse struct {
+	Title        string `json:"title"`
+	AuthorName   string `json:"author_name"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	Image        string `json:"image"`
+	// Duration is in seconds, only some providers like Vimeo report it.
+	Duration int `json:"duration"`
+}
+
+// fetch requests the oEmbed data of musicURL.
+//
+// Returns the response with a trimmed title, or ErrNoTitleFound if the title is missing.
+func (c *oEmbedClient) fetch(ctx context.Context, musicURL string) (oEmbedResponse, error) {
+	query := url.Values{}
+	query.Set("format", "json")
+	query.Set("url", musicURL)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiURL+"?"+query.Encode(), http.NoBody)
+	if err != nil {
+		return oEmbedResponse{}, ErrRequestFailed
+	}
+
+	resp, err := c.httpClient.Do(request)
+	if err != nil {
+		return oEmbedResponse{}, ErrRequestFailed
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return oEmbedResponse{}, statusError(resp.StatusCode)
+	}
+
+	var result oEmbedResponse
+
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return oEmbedResponse{}, ErrNoTitleFound
+	}
+
+	result.Title = strings.TrimSpace(result.Title)
+	if result.Title == "" {
+		return oEmbedResponse{}, ErrNoTitleFound
+	}
+
+	return result, nil
+}
+
+// metadata fetches the oEmbed data of musicURL, the author is reported as the artist.
+//
+// Titles in the "Artist - Title" format are split, as uploaders often put the artist in the title as well.
+func (c *oEmbedClient) metadata(ctx context.Context, musicURL string) (TrackMetadata, error) {
+	result, err := c.fetch(ctx, musicURL)
+	if err != nil {
+		return TrackMetadata{}, err
+	}
+
+	m := TrackMetadata{
+		Title:           result.Title,
+		Artist:          strings.TrimSpace(result.AuthorName),
+		ArtworkURL:      result.ThumbnailURL,
+		DurationSeconds: result.Duration,
+	}
+
+	if m.ArtworkURL == "" {
+		m.ArtworkURL = result.Image
+	}
+
+	if artist, song, found := strings.Cut(result.Title, " - "); found {
+		m.Artist, m.Title = artist, song
+	}
+
+	return m, nil
+}
+
+// youTubeMetadata fetches the oEmbed data of a YouTube or YouTube Music video.
+//
+// The author of YouTube videos is the uploading channel, so it's only reported as the artist for YouTube Music links,
+// where it's the artist itself, without the " - Topic" suffix of auto-generated artist channels.
+func (c *oEmbedClient) youTubeMetadata(ctx context.Context, videoURL string) (TrackMetadata, error) {
+	result, err := c.fetch(ctx, videoURL)
+	if err != nil {
+		return TrackMetadata{}, err
+	}
+
+	// The video ID is only best-effort metadata, the title is still usable without it
+	videoID, _ := youTubeVideoID(videoURL)
+
+	m := TrackMetadata{Title: result.Title, ArtworkURL: result.ThumbnailURL, ProviderID: videoID}
+
+	if YouTubeMusicURLRegex.MatchString(videoURL) {
+		m.Artist = strings.TrimSuffix(strings.TrimSpace(result.AuthorName), " - Topic")
+		// Keeps the artist from showing up twice in the "Artist - Title" display title
+		m.Title = strings.TrimPrefix(m.Title, m.Artist+" - ")
+	}
+
+	return m, nil
+}
+
+// MixcloudMetadataExtractor fetches and extracts the show metadata from a Mixcloud URL using oEmbed API,
+// the uploader is reported as the artist.
+func MixcloudMetadataExtractor(ctx context.Context, showURL string) (TrackMetadata, error) {
+	c := oEmbedClient{httpClient: http.DefaultClient, apiURL: mixcloudOEmbedURL}
+
+	return c.metadata(ctx, showURL)
+}
+
+// AudiomackMetadataExtractor fetches and extracts the track metadata from an Audiomack URL using oEmbed API.
+func AudiomackMetadataExtractor(ctx context.Context, songURL string) (TrackMetadata, error) {
+	c := oEmbedClient{httpClient: http.DefaultClient, apiURL: audiomackOEmbedURL}
+
+	return c.metadata(ctx, songURL)
+}
+
+// VimeoMetadataExtractor fetches and extracts the video metadata from a Vimeo URL using oEmbed API,
+// the uploader is reported as the artist.
+func VimeoMetadataExtractor(ctx context.Context, videoURL string) (TrackMetadata, error) {
+	c := oEmbedClient{httpClient: http.DefaultClient, apiURL: vimeoOEmbedURL}
+
+	return c.metadata(ctx, videoURL)
+}
+
+// DailymotionMetadataExtractor fetches and extracts the video metadata from a Dailymotion URL using oEmbed API,
+// the uploader is reported as the artist.
+func DailymotionMetadataExtractor(ctx context.Context, videoURL string) (TrackMetadata, error) {
+	c := oEmbedClient{httpClient: http.DefaultClient, apiURL: dailymotionOEmbedURL}
+
+	return c.metadata(ctx, videoURL)
+}
@@ -0,0 +1,133 @@
+package musicextractors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOEmbedClient_Metadata(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr error
+		name    string
+		body    string
+		want    TrackMetadata
+		status  int
+	}{
+		{
+			name:   "mixcloud show",
+			status: http.StatusOK,
+			body:   `{"title":"Friday Night Mix","author_name":"DJ Example","image":"https://thumbnailer.mixcloud.com/show.jpg"}`,
+			want:   TrackMetadata{Title: "Friday Night Mix", Artist: "DJ Example", ArtworkURL: "https://thumbnailer.mixcloud.com/show.jpg"},
+		},
+		{
+			name:   "artist in the title",
+			status: http.StatusOK,
+			body:   `{"title":"Burna Boy - Last Last","author_name":"Uploader","thumbnail_url":"https://assets.audiomack.com/song.jpg"}`,
+			want:   TrackMetadata{Title: "Last Last", Artist: "Burna Boy", ArtworkURL: "https://assets.audiomack.com/song.jpg"},
+		},
+		{
+			name:   "vimeo video with duration",
+			status: http.StatusOK,
+			body:   `{"title":"Live at the Lot","author_name":"Band Example","thumbnail_url":"https://i.vimeocdn.com/video/1.jpg","duration":245}`,
+			want:   TrackMetadata{Title: "Live at the Lot", Artist: "Band Example", ArtworkURL: "https://i.vimeocdn.com/video/1.jpg", DurationSeconds: 245},
+		},
+		{
+			name:    "empty title",
+			status:  http.StatusOK,
+			body:    `{"title":""}`,
+			wantErr: ErrNoTitleFound,
+		},
+		{
+			name:    "not found",
+			status:  http.StatusNotFound,
+			wantErr: ErrRequestFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "json", r.URL.Query().Get("format"))
+				assert.Equal(t, "https://www.mixcloud.com/dj-example/friday-night-mix/", r.URL.Query().Get("url"))
+
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			t.Cleanup(srv.Close)
+
+			c := oEmbedClient{httpClient: srv.Client(), apiURL: srv.URL}
+
+			got, err := c.metadata(t.Context(), "https://www.mixcloud.com/dj-example/friday-night-mix/")
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestOEmbedClient_YouTubeMetadata(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		url  string
+		body string
+		want TrackMetadata
+	}{
+		{
+			name: "youtube video keeps the channel out",
+			url:  "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+			body: `{"title":"Rick Astley - Never Gonna Give You Up (Official Video)","author_name":"Rick Astley"}`,
+			want: TrackMetadata{Title: "Rick Astley - Never Gonna Give You Up (Official Video)", ProviderID: "dQw4w9WgXcQ"},
+		},
+		{
+			name: "youtube music topic channel",
+			url:  "https://music.youtube.com/watch?v=lYBUbBu4W08",
+			body: `{"title":"Never Gonna Give You Up","author_name":"Rick Astley - Topic","thumbnail_url":"https://i.ytimg.com/vi/lYBUbBu4W08/hqdefault.jpg"}`,
+			want: TrackMetadata{
+				Title:      "Never Gonna Give You Up",
+				Artist:     "Rick Astley",
+				ProviderID: "lYBUbBu4W08",
+				ArtworkURL: "https://i.ytimg.com/vi/lYBUbBu4W08/hqdefault.jpg",
+			},
+		},
+		{
+			name: "youtube music artist already in the title",
+			url:  "https://music.youtube.com/watch?v=dQw4w9WgXcQ",
+			body: `{"title":"Rick Astley - Never Gonna Give You Up","author_name":"Rick Astley"}`,
+			want: TrackMetadata{Title: "Never Gonna Give You Up", Artist: "Rick Astley", ProviderID: "dQw4w9WgXcQ"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, tt.url, r.URL.Query().Get("url"))
+
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			t.Cleanup(srv.Close)
+
+			c := oEmbedClient{httpClient: srv.Client(), apiURL: srv.URL}
+
+			got, err := c.youTubeMetadata(t.Context(), tt.url)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
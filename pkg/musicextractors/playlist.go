@@ -0,0 +1,212 @@
+package musicextractors
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultPlaylistLimit is the number of tracks a playlist is expanded into, the rest of the playlist is left out.
+	DefaultPlaylistLimit = 100
+
+	// spotifyPlaylistPageSize and youTubePlaylistPageSize are the largest pages the APIs return.
+	spotifyPlaylistPageSize = 100
+	youTubePlaylistPageSize = 50
+)
+
+var (
+	// SpotifyPlaylistURLRegex matches Spotify playlist links, including the locale prefixed and embed player links.
+	SpotifyPlaylistURLRegex = regexp.MustCompile(`https?://open\.spotify\.com/(?:intl-[a-zA-Z\-]+/)?(?:embed/)?playlist/\w+`)
+	// YouTubePlaylistURLRegex matches YouTube and YouTube Music playlist links.
+	YouTubePlaylistURLRegex = regexp.MustCompile(`https?://(?:www\.|music\.)?youtube\.com/playlist\?list=[\w\-]+`)
+
+	spotifyPlaylistIDRegex = regexp.MustCompile(`/playlist/(\w+)`)
+	youTubePlaylistIDRegex = regexp.MustCompile(`[?&]list=([\w\-]+)`)
+)
+
+// PlaylistExpander lists the tracks of shared playlists, so they can be summarized one by one.
+type PlaylistExpander interface {
+	// ExpandPlaylist returns the track links of the playlist link in text, ErrNoURLFound if text has none.
+	ExpandPlaylist(ctx context.Context, text string) ([]string, error)
+}
+
+// APIPlaylistExpander is a PlaylistExpander backed by the Spotify Web API and the YouTube Data API,
+// playlists of a provider without credentials are not expanded.
+type APIPlaylistExpander struct {
+	spotify *spotifyAPIClient
+	youTube *YouTubeDataAPIClient
+	limit   int
+}
+
+var _ PlaylistExpander = (*APIPlaylistExpander)(nil)
+
+// NewPlaylistExpander creates an expander listing at most limit tracks of every playlist.
+//
+// Empty Spotify credentials or YouTube API key disable the expansion of the playlists of that provider.
+func NewPlaylistExpander(spotifyClientID, spotifyClientSecret, youTubeAPIKey string, limit int) *APIPlaylistExpander {
+	e := &APIPlaylistExpander{limit: limit}
+
+	if spotifyClientID != "" && spotifyClientSecret != "" {
+		e.spotify = newSpotifyAPIClient(spotifyClientID, spotifyClientSecret)
+	}
+
+	if youTubeAPIKey != "" {
+		e.youTube = NewYouTubeDataAPIClient(youTubeAPIKey)
+	}
+
+	return e
+}
+
+// ExpandPlaylist returns the track links of the playlist link in text, ErrNoURLFound if text has none
+// or the playlist's provider has no credentials.
+func (e *APIPlaylistExpander) ExpandPlaylist(ctx context.Context, text string) ([]string, error) {
+	if e.spotify != nil {
+		if playlistURL, err := regexURLExtractor(text, SpotifyPlaylistURLRegex); err == nil {
+			return e.spotify.playlistTracks(ctx, playlistURL, e.limit)
+		}
+	}
+
+	if e.youTube != nil {
+		if playlistURL, err := regexURLExtractor(text, YouTubePlaylistURLRegex); err == nil {
+			return e.youTube.PlaylistVideos(ctx, playlistURL, e.limit)
+		}
+	}
+
+	return nil, ErrNoURLFound
+}
+
+// playlistTracks lists the links of at most limit tracks of a playlist, local files and episodes are left out.
+func (c *spotifyAPIClient) playlistTracks(ctx context.Context, playlistURL string, limit int) ([]string, error) {
+	matches := spotifyPlaylistIDRegex.FindStringSubmatch(playlistURL)
+	if len(matches) < 2 {
+		return nil, ErrNoTrackID
+	}
+
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(min(limit, spotifyPlaylistPageSize)))
+	query.Set("fields", "next,items(track(type,external_urls(spotify)))")
+
+	next := c.apiURL + "/playlists/" + matches[1] + "/tracks?" + query.Encode()
+	tracks := []string{}
+
+	// The next page is only followed on the API itself, so the token is never sent elsewhere
+	for next != "" && strings.HasPrefix(next, c.apiURL+"/") && len(tracks) < limit {
+		var page struct {
+			Next  string `json:"next"`
+			Items []struct {
+				Track *struct {
+					Type         string `json:"type"`
+					ExternalURLs struct {
+						Spotify string `json:"spotify"`
+					} `json:"external_urls"`
+				} `json:"track"`
+			} `json:"items"`
+		}
+
+		if err = getJSON(ctx, c.httpClient, next, "Bearer "+token, &page); err != nil {
+			return nil, err
+		}
+
+		for _, item := range page.Items {
+			if item.Track != nil && item.Track.Type == "track" && item.Track.ExternalURLs.Spotify != "" {
+				tracks = append(tracks, item.Track.ExternalURLs.Spotify)
+			}
+		}
+
+		next = page.Next
+	}
+
+	return tracks[:min(limit, len(tracks))], nil
+}
+
+// PlaylistVideos lists the watch links of at most limit videos of a YouTube or YouTube Music playlist,
+// YouTube Music playlists are listed with YouTube Music links.
+func (c *YouTubeDataAPIClient) PlaylistVideos(ctx context.Context, playlistURL string, limit int) ([]string, error) {
+	matches := youTubePlaylistIDRegex.FindStringSubmatch(playlistURL)
+	if len(matches) < 2 {
+		return nil, ErrNoTrackID
+	}
+
+	watchURL := youTubeWatchURL
+	if strings.Contains(playlistURL, "music.youtube.com") {
+		watchURL = func(videoID string) string { return "https://music.youtube.com/watch?v=" + videoID }
+	}
+
+	query := url.Values{}
+	query.Set("part", "contentDetails")
+	query.Set("playlistId", matches[1])
+	query.Set("maxResults", strconv.Itoa(min(limit, youTubePlaylistPageSize)))
+	query.Set("key", c.apiKey)
+
+	videos := []string{}
+
+	for len(videos) < limit {
+		var page struct {
+			NextPageToken string `json:"nextPageToken"`
+			Items         []struct {
+				ContentDetails struct {
+					VideoID string `json:"videoId"`
+				} `json:"contentDetails"`
+			} `json:"items"`
+		}
+
+		if err := getJSON(ctx, c.httpClient, c.apiURL+"/playlistItems?"+query.Encode(), "", &page); err != nil {
+			return nil, err
+		}
+
+		for _, item := range page.Items {
+			if item.ContentDetails.VideoID != "" {
+				videos = append(videos, watchURL(item.ContentDetails.VideoID))
+			}
+		}
+
+		if page.NextPageToken == "" {
+			break
+		}
+
+		query.Set("pageToken", page.NextPageToken)
+	}
+
+	return videos[:min(limit, len(videos))], nil
+}
+
+// getJSON requests apiURL with the given Authorization header, if any, and decodes the JSON answer into v.
+func getJSON(ctx context.Context, httpClient *http.Client, apiURL, authorization string, v any) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, http.NoBody)
+	if err != nil {
+		return ErrRequestFailed
+	}
+
+	if authorization != "" {
+		request.Header.Set("Authorization", authorization)
+	}
+
+	resp, err := httpClient.Do(request)
+	if err != nil {
+		return ErrRequestFailed
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp.StatusCode)
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return ErrRequestFailed
+	}
+
+	return nil
+}
@@ -0,0 +1,377 @@
+package musicextractors
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+const (
+	// spotifyPlaylistURL and youTubePlaylistURL are the prefixes of the public links of the playlists.
+	spotifyPlaylistURL = "https://open.spotify.com/playlist/"
+	youTubePlaylistURL = "https://www.youtube.com/playlist?list="
+
+	googleTokenURL = "https://oauth2.googleapis.com/token"
+)
+
+// Playlist is a playlist created or updated by a PlaylistCreator.
+type Playlist struct {
+	ID  string
+	URL string
+	// Tracks is the number of tracks in the playlist.
+	Tracks int
+}
+
+// PlaylistCreator creates playlists of the shared tracks on a streaming provider.
+type PlaylistCreator interface {
+	// Provider is the provider the playlists are created on, only the links of its tracks are added.
+	Provider() ExtractProvider
+	// SyncPlaylist makes the playlist with id contain the tracks of trackURLs without duplicates, the links of other
+	// providers are ignored. A new playlist named name is created if id is empty or the playlist doesn't exist anymore.
+	//
+	// Returns the playlist, ErrNoPlaylistTracks if none of the links is a track of the provider.
+	SyncPlaylist(ctx context.Context, id, name string, trackURLs []string) (Playlist, error)
+}
+
+// SpotifyPlaylistCreator creates playlists on the Spotify account that authorized the refresh token.
+type SpotifyPlaylistCreator struct {
+	api    *spotifyAPIClient
+	userID string
+	mu     sync.Mutex
+}
+
+var _ PlaylistCreator = (*SpotifyPlaylistCreator)(nil)
+
+// NewSpotifyPlaylistCreator creates a playlist creator authorized with the refresh token of a Spotify account,
+// issued to the Spotify app of clientID with the playlist-modify-public scope.
+func NewSpotifyPlaylistCreator(clientID, clientSecret, refreshToken string) *SpotifyPlaylistCreator {
+	api := newSpotifyAPIClient(clientID, clientSecret)
+	api.refreshToken = refreshToken
+
+	return &SpotifyPlaylistCreator{api: api}
+}
+
+// Provider returns SpotifyProvider.
+func (c *SpotifyPlaylistCreator) Provider() ExtractProvider {
+	return SpotifyProvider
+}
+
+// currentUser returns the ID of the account that authorized the refresh token, it's looked up once.
+func (c *SpotifyPlaylistCreator) currentUser(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.userID != "" {
+		return c.userID, nil
+	}
+
+	var user struct {
+		ID string `json:"id"`
+	}
+
+	if err := c.api.call(ctx, http.MethodGet, c.api.apiURL+"/me", nil, &user); err != nil {
+		return "", err
+	}
+
+	if user.ID == "" {
+		return "", ErrRequestFailed
+	}
+
+	c.userID = user.ID
+
+	return c.userID, nil
+}
+
+// createPlaylist creates an empty public playlist named name.
+func (c *SpotifyPlaylistCreator) createPlaylist(ctx context.Context, name string) (string, error) {
+	userID, err := c.currentUser(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var playlist struct {
+		ID string `json:"id"`
+	}
+
+	err = c.api.call(ctx, http.MethodPost, c.api.apiURL+"/users/"+userID+"/playlists", map[string]any{
+		"name":        name,
+		"description": "Tracks shared in a Slack thread",
+		"public":      true,
+	}, &playlist)
+	if err != nil {
+		return "", err
+	}
+
+	if playlist.ID == "" {
+		return "", ErrRequestFailed
+	}
+
+	return playlist.ID, nil
+}
+
+// replaceTracks replaces the tracks of the playlist, the first page replaces them and the rest are appended,
+// since the API takes at most spotifyPlaylistPageSize tracks per request.
+func (c *SpotifyPlaylistCreator) replaceTracks(ctx context.Context, id string, uris []string) error {
+	method := http.MethodPut
+
+	for start := 0; start < len(uris); start += spotifyPlaylistPageSize {
+		end := min(start+spotifyPlaylistPageSize, len(uris))
+
+		if err := c.api.call(ctx, method, c.api.apiURL+"/playlists/"+id+"/tracks", map[string]any{"uris": uris[start:end]}, nil); err != nil {
+			return err
+		}
+
+		method = http.MethodPost
+	}
+
+	return nil
+}
+
+// SyncPlaylist replaces the tracks of the playlist with id with the Spotify tracks of trackURLs,
+// creating a new playlist named name if id is empty or the playlist was deleted.
+func (c *SpotifyPlaylistCreator) SyncPlaylist(ctx context.Context, id, name string, trackURLs []string) (Playlist, error) {
+	uris := make([]string, 0, len(trackURLs))
+	seen := make(map[string]struct{}, len(trackURLs))
+
+	for _, u := range trackURLs {
+		provider, trackID, err := ExtractTrackID(u)
+		if err != nil || provider != SpotifyProvider {
+			continue
+		}
+
+		if _, ok := seen[trackID]; ok {
+			continue
+		}
+
+		seen[trackID] = struct{}{}
+		uris = append(uris, "spotify:track:"+trackID)
+	}
+
+	if len(uris) == 0 {
+		return Playlist{}, ErrNoPlaylistTracks
+	}
+
+	if id != "" {
+		err := c.replaceTracks(ctx, id, uris)
+		if err == nil {
+			return Playlist{ID: id, URL: spotifyPlaylistURL + id, Tracks: len(uris)}, nil
+		}
+
+		if !errors.Is(err, ErrPlaylistNotFound) {
+			return Playlist{}, err
+		}
+	}
+
+	id, err := c.createPlaylist(ctx, name)
+	if err != nil {
+		return Playlist{}, err
+	}
+
+	if err = c.replaceTracks(ctx, id, uris); err != nil {
+		return Playlist{}, err
+	}
+
+	return Playlist{ID: id, URL: spotifyPlaylistURL + id, Tracks: len(uris)}, nil
+}
+
+// YouTubePlaylistCreator creates playlists on the YouTube channel that authorized the refresh token.
+type YouTubePlaylistCreator struct {
+	api    *oauthClient
+	apiURL string
+}
+
+var _ PlaylistCreator = (*YouTubePlaylistCreator)(nil)
+
+// NewYouTubePlaylistCreator creates a playlist creator authorized with the refresh token of a YouTube channel,
+// issued to the Google OAuth client of clientID with the youtube scope.
+func NewYouTubePlaylistCreator(clientID, clientSecret, refreshToken string) *YouTubePlaylistCreator {
+	return &YouTubePlaylistCreator{
+		api: &oauthClient{
+			httpClient:   http.DefaultClient,
+			clientID:     clientID,
+			clientSecret: clientSecret,
+			refreshToken: refreshToken,
+			tokenURL:     googleTokenURL,
+		},
+		apiURL: youTubeDataAPIURL,
+	}
+}
+
+// Provider returns YouTubeProvider.
+func (c *YouTubePlaylistCreator) Provider() ExtractProvider {
+	return YouTubeProvider
+}
+
+// youTubePlaylistItem is a video of a playlist, ID identifies the item and not the video.
+type youTubePlaylistItem struct {
+	ID      string
+	VideoID string
+}
+
+// playlistItems lists every item of the playlist.
+func (c *YouTubePlaylistCreator) playlistItems(ctx context.Context, id string) ([]youTubePlaylistItem, error) {
+	var items []youTubePlaylistItem
+
+	pageToken := ""
+
+	for {
+		query := url.Values{}
+		query.Set("part", "snippet")
+		query.Set("playlistId", id)
+		query.Set("maxResults", "50")
+
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+
+		var page struct {
+			NextPageToken string `json:"nextPageToken"`
+			Items         []struct {
+				ID      string `json:"id"`
+				Snippet struct {
+					ResourceID struct {
+						VideoID string `json:"videoId"`
+					} `json:"resourceId"`
+				} `json:"snippet"`
+			} `json:"items"`
+		}
+
+		if err := c.api.call(ctx, http.MethodGet, c.apiURL+"/playlistItems?"+query.Encode(), nil, &page); err != nil {
+			return nil, err
+		}
+
+		for _, item := range page.Items {
+			items = append(items, youTubePlaylistItem{ID: item.ID, VideoID: item.Snippet.ResourceID.VideoID})
+		}
+
+		if page.NextPageToken == "" {
+			return items, nil
+		}
+
+		pageToken = page.NextPageToken
+	}
+}
+
+// createPlaylist creates an empty unlisted playlist named name.
+func (c *YouTubePlaylistCreator) createPlaylist(ctx context.Context, name string) (string, error) {
+	var playlist struct {
+		ID string `json:"id"`
+	}
+
+	err := c.api.call(ctx, http.MethodPost, c.apiURL+"/playlists?part=snippet,status", map[string]any{
+		"snippet": map[string]string{"title": name, "description": "Videos shared in a Slack thread"},
+		"status":  map[string]string{"privacyStatus": "unlisted"},
+	}, &playlist)
+	if err != nil {
+		return "", err
+	}
+
+	if playlist.ID == "" {
+		return "", ErrRequestFailed
+	}
+
+	return playlist.ID, nil
+}
+
+// insertVideo appends a video to the end of the playlist.
+func (c *YouTubePlaylistCreator) insertVideo(ctx context.Context, id, videoID string) error {
+	return c.api.call(ctx, http.MethodPost, c.apiURL+"/playlistItems?part=snippet", map[string]any{
+		"snippet": map[string]any{
+			"playlistId": id,
+			"resourceId": map[string]string{"kind": "youtube#video", "videoId": videoID},
+		},
+	}, nil)
+}
+
+// syncItems makes the playlist contain exactly videoIDs, the API has no way to replace every item at once and every
+// write costs quota, so the items of the videos that are kept stay in place and the new videos are appended.
+func (c *YouTubePlaylistCreator) syncItems(ctx context.Context, id string, videoIDs []string) error {
+	items, err := c.playlistItems(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	wanted := make(map[string]bool, len(videoIDs))
+	for _, v := range videoIDs {
+		wanted[v] = true
+	}
+
+	present := make(map[string]bool, len(items))
+
+	for _, item := range items {
+		if wanted[item.VideoID] && !present[item.VideoID] {
+			present[item.VideoID] = true
+
+			continue
+		}
+
+		if err = c.api.call(ctx, http.MethodDelete, c.apiURL+"/playlistItems?id="+url.QueryEscape(item.ID), nil, nil); err != nil {
+			return err
+		}
+	}
+
+	for _, v := range videoIDs {
+		if present[v] {
+			continue
+		}
+
+		if err = c.insertVideo(ctx, id, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SyncPlaylist makes the playlist with id contain the YouTube and YouTube Music videos of trackURLs,
+// creating a new playlist named name if id is empty or the playlist was deleted.
+//
+// Videos already in the playlist keep their place, so an updated thread only appends its new videos.
+func (c *YouTubePlaylistCreator) SyncPlaylist(ctx context.Context, id, name string, trackURLs []string) (Playlist, error) {
+	videoIDs := make([]string, 0, len(trackURLs))
+	seen := make(map[string]struct{}, len(trackURLs))
+
+	for _, u := range trackURLs {
+		videoID, err := youTubeVideoID(u)
+		if err != nil {
+			continue
+		}
+
+		if _, ok := seen[videoID]; ok {
+			continue
+		}
+
+		seen[videoID] = struct{}{}
+		videoIDs = append(videoIDs, videoID)
+	}
+
+	if len(videoIDs) == 0 {
+		return Playlist{}, ErrNoPlaylistTracks
+	}
+
+	if id != "" {
+		err := c.syncItems(ctx, id, videoIDs)
+		if err == nil {
+			return Playlist{ID: id, URL: youTubePlaylistURL + id, Tracks: len(videoIDs)}, nil
+		}
+
+		if !errors.Is(err, ErrPlaylistNotFound) {
+			return Playlist{}, err
+		}
+	}
+
+	id, err := c.createPlaylist(ctx, name)
+	if err != nil {
+		return Playlist{}, err
+	}
+
+	for _, v := range videoIDs {
+		if err = c.insertVideo(ctx, id, v); err != nil {
+			return Playlist{}, err
+		}
+	}
+
+	return Playlist{ID: id, URL: youTubePlaylistURL + id, Tracks: len(videoIDs)}, nil
+}
@@ -0,0 +1,339 @@
+package musicextractors
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSpotifyPlaylists is a Spotify Web API serving the playlist endpoints, with a single playlist "existing".
+type fakeSpotifyPlaylists struct {
+	playlists    map[string][]string
+	refreshToken string
+	created      int
+	mu           sync.Mutex
+}
+
+func newTestSpotifyPlaylistCreator(t *testing.T) (*SpotifyPlaylistCreator, *fakeSpotifyPlaylists) {
+	t.Helper()
+
+	fake := &fakeSpotifyPlaylists{playlists: map[string][]string{"existing": {"spotify:track:old"}}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("grant_type") != "refresh_token" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		fake.mu.Lock()
+		fake.refreshToken = r.FormValue("refresh_token")
+		fake.mu.Unlock()
+
+		_, _ = w.Write([]byte(`{"access_token":"tkn","expires_in":3600}`))
+	})
+	mux.HandleFunc("GET /v1/me", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"id":"user"}`))
+	})
+	mux.HandleFunc("POST /v1/users/user/playlists", func(w http.ResponseWriter, _ *http.Request) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+
+		fake.created++
+		fake.playlists["new"] = nil
+
+		_, _ = w.Write([]byte(`{"id":"new"}`))
+	})
+	mux.HandleFunc("/v1/playlists/{id}/tracks", func(w http.ResponseWriter, r *http.Request) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+
+		tracks, ok := fake.playlists[r.PathValue("id")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var body struct {
+			URIs []string `json:"uris"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.URIs) > spotifyPlaylistPageSize {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if r.Method == http.MethodPut {
+			tracks = nil
+		}
+
+		fake.playlists[r.PathValue("id")] = append(tracks, body.URIs...)
+
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	creator := NewSpotifyPlaylistCreator("id", "secret", "refresh")
+	creator.api.httpClient = srv.Client()
+	creator.api.tokenURL = srv.URL + "/token"
+	creator.api.apiURL = srv.URL + "/v1"
+
+	return creator, fake
+}
+
+func TestSpotifyPlaylistCreator_SyncPlaylist(t *testing.T) {
+	t.Parallel()
+
+	links := []string{
+		"https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT",
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		"https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT?si=abc",
+		"https://open.spotify.com/track/7ouMYWpwJ422jRcDASZB7P",
+	}
+	want := []string{"spotify:track:4cOdK2wGLETKBW3PvgPWqT", "spotify:track:7ouMYWpwJ422jRcDASZB7P"}
+
+	tests := []struct {
+		name        string
+		id          string
+		wantID      string
+		wantCreated int
+	}{
+		{name: "creates a new playlist", wantID: "new", wantCreated: 1},
+		{name: "replaces the tracks of an existing playlist", id: "existing", wantID: "existing"},
+		{name: "recreates a deleted playlist", id: "deleted", wantID: "new", wantCreated: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			creator, fake := newTestSpotifyPlaylistCreator(t)
+
+			got, err := creator.SyncPlaylist(t.Context(), tt.id, "Thread", links)
+			require.NoError(t, err)
+
+			assert.Equal(t, Playlist{ID: tt.wantID, URL: spotifyPlaylistURL + tt.wantID, Tracks: 2}, got)
+			assert.Equal(t, want, fake.playlists[tt.wantID])
+			assert.Equal(t, tt.wantCreated, fake.created)
+			assert.Equal(t, "refresh", fake.refreshToken)
+		})
+	}
+}
+
+func TestSpotifyPlaylistCreator_SyncPlaylist_Pages(t *testing.T) {
+	t.Parallel()
+
+	creator, fake := newTestSpotifyPlaylistCreator(t)
+
+	links := make([]string, 0, 250)
+
+	for i := range 250 {
+		links = append(links, "https://open.spotify.com/track/"+spotifyTestTrackID(i))
+	}
+
+	got, err := creator.SyncPlaylist(t.Context(), "existing", "Thread", links)
+	require.NoError(t, err)
+
+	assert.Equal(t, 250, got.Tracks)
+	require.Len(t, fake.playlists["existing"], 250)
+	assert.Equal(t, "spotify:track:"+spotifyTestTrackID(249), fake.playlists["existing"][249])
+}
+
+func TestSpotifyPlaylistCreator_SyncPlaylist_NoTracks(t *testing.T) {
+	t.Parallel()
+
+	creator, fake := newTestSpotifyPlaylistCreator(t)
+
+	_, err := creator.SyncPlaylist(t.Context(), "", "Thread", []string{"https://www.youtube.com/watch?v=dQw4w9WgXcQ"})
+	require.ErrorIs(t, err, ErrNoPlaylistTracks)
+
+	assert.Zero(t, fake.created)
+}
+
+// spotifyTestTrackID returns a distinct 22 character Spotify track ID for i.
+func spotifyTestTrackID(i int) string {
+	const alphabet = "abcdefghijklmnopqrstuvwxyz"
+
+	return "4cOdK2wGLETKBW3PvgP" + string(alphabet[i/26%26]) + string(alphabet[i%26]) + "T"
+}
+
+// fakeYouTubePlaylists is a YouTube Data API serving the playlist endpoints, with a single playlist "existing".
+type fakeYouTubePlaylists struct {
+	// playlists maps playlist IDs to their items, every item is itemID:videoID.
+	playlists map[string][]string
+	inserts   int
+	deletes   int
+	created   int
+	mu        sync.Mutex
+}
+
+func newTestYouTubePlaylistCreator(t *testing.T) (*YouTubePlaylistCreator, *fakeYouTubePlaylists) {
+	t.Helper()
+
+	fake := &fakeYouTubePlaylists{playlists: map[string][]string{"existing": {"i1:dQw4w9WgXcQ", "i2:removedVideo"}}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"access_token":"tkn","expires_in":3600}`))
+	})
+	mux.HandleFunc("POST /youtube/playlists", func(w http.ResponseWriter, _ *http.Request) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+
+		fake.created++
+		fake.playlists["new"] = nil
+
+		_, _ = w.Write([]byte(`{"id":"new"}`))
+	})
+	mux.HandleFunc("GET /youtube/playlistItems", func(w http.ResponseWriter, r *http.Request) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+
+		items, ok := fake.playlists[r.URL.Query().Get("playlistId")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		// One item per page, to go through the paging
+		page := 0
+		if token := r.URL.Query().Get("pageToken"); token != "" {
+			page = len(token)
+		}
+
+		body := map[string]any{"items": []any{}}
+
+		if page < len(items) {
+			itemID, videoID, _ := strings.Cut(items[page], ":")
+			body["items"] = []any{map[string]any{
+				"id":      itemID,
+				"snippet": map[string]any{"resourceId": map[string]string{"videoId": videoID}},
+			}}
+		}
+
+		if page+1 < len(items) {
+			body["nextPageToken"] = strings.Repeat("p", page+1)
+		}
+
+		_ = json.NewEncoder(w).Encode(body)
+	})
+	mux.HandleFunc("POST /youtube/playlistItems", func(w http.ResponseWriter, r *http.Request) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+
+		var body struct {
+			Snippet struct {
+				PlaylistID string `json:"playlistId"`
+				ResourceID struct {
+					VideoID string `json:"videoId"`
+				} `json:"resourceId"`
+			} `json:"snippet"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		fake.inserts++
+		id := body.Snippet.PlaylistID
+		fake.playlists[id] = append(fake.playlists[id], "n:"+body.Snippet.ResourceID.VideoID)
+	})
+	mux.HandleFunc("DELETE /youtube/playlistItems", func(w http.ResponseWriter, r *http.Request) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+
+		fake.deletes++
+
+		for id, items := range fake.playlists {
+			fake.playlists[id] = slices.DeleteFunc(items, func(item string) bool {
+				return strings.HasPrefix(item, r.URL.Query().Get("id")+":")
+			})
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	creator := NewYouTubePlaylistCreator("id", "secret", "refresh")
+	creator.api.httpClient = srv.Client()
+	creator.api.tokenURL = srv.URL + "/token"
+	creator.apiURL = srv.URL + "/youtube"
+
+	return creator, fake
+}
+
+// videoIDs returns the video IDs of the items of a fake playlist.
+func (f *fakeYouTubePlaylists) videoIDs(id string) []string {
+	ids := make([]string, 0, len(f.playlists[id]))
+
+	for _, item := range f.playlists[id] {
+		_, videoID, _ := strings.Cut(item, ":")
+		ids = append(ids, videoID)
+	}
+
+	return ids
+}
+
+func TestYouTubePlaylistCreator_SyncPlaylist(t *testing.T) {
+	t.Parallel()
+
+	links := []string{
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		"https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT",
+		"https://music.youtube.com/watch?v=9bZkp7q19f0",
+		"https://youtu.be/dQw4w9WgXcQ",
+	}
+
+	tests := []struct {
+		name        string
+		id          string
+		wantID      string
+		wantCreated int
+		wantInserts int
+		wantDeletes int
+	}{
+		{name: "creates a new playlist", wantID: "new", wantCreated: 1, wantInserts: 2},
+		{name: "keeps the videos of an existing playlist", id: "existing", wantID: "existing", wantInserts: 1, wantDeletes: 1},
+		{name: "recreates a deleted playlist", id: "deleted", wantID: "new", wantCreated: 1, wantInserts: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			creator, fake := newTestYouTubePlaylistCreator(t)
+
+			got, err := creator.SyncPlaylist(t.Context(), tt.id, "Thread", links)
+			require.NoError(t, err)
+
+			assert.Equal(t, Playlist{ID: tt.wantID, URL: youTubePlaylistURL + tt.wantID, Tracks: 2}, got)
+			assert.Equal(t, []string{"dQw4w9WgXcQ", "9bZkp7q19f0"}, fake.videoIDs(tt.wantID))
+			assert.Equal(t, tt.wantCreated, fake.created)
+			assert.Equal(t, tt.wantInserts, fake.inserts)
+			assert.Equal(t, tt.wantDeletes, fake.deletes)
+		})
+	}
+}
+
+func TestYouTubePlaylistCreator_SyncPlaylist_NoTracks(t *testing.T) {
+	t.Parallel()
+
+	creator, fake := newTestYouTubePlaylistCreator(t)
+
+	_, err := creator.SyncPlaylist(t.Context(), "", "Thread", []string{"https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT"})
+	require.ErrorIs(t, err, ErrNoPlaylistTracks)
+
+	assert.Zero(t, fake.created)
+}
@@ -0,0 +1,142 @@
+package musicextractors
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPlaylistExpander(t *testing.T, limit int) *APIPlaylistExpander {
+	t.Helper()
+
+	var srv *httptest.Server
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"access_token":"tkn","expires_in":3600}`))
+	})
+	mux.HandleFunc("GET /v1/playlists/{id}/tracks", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tkn" || r.PathValue("id") != "37i9dQZF1DXcBWIGoYBM5M" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if r.URL.Query().Get("offset") == "" {
+			fmt.Fprintf(w, `{"next":%q,"items":[
+				{"track":{"type":"track","external_urls":{"spotify":"https://open.spotify.com/track/1"}}},
+				{"track":{"type":"episode","external_urls":{"spotify":"https://open.spotify.com/episode/2"}}},
+				{"track":null}
+			]}`, srv.URL+"/v1/playlists/37i9dQZF1DXcBWIGoYBM5M/tracks?offset=3")
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"next":null,"items":[{"track":{"type":"track","external_urls":{"spotify":"https://open.spotify.com/track/3"}}}]}`))
+	})
+	mux.HandleFunc("GET /youtube/playlistItems", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("key") != "key" || r.URL.Query().Get("playlistId") != "PL123" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if r.URL.Query().Get("pageToken") == "" {
+			_, _ = w.Write([]byte(`{"nextPageToken":"p2","items":[{"contentDetails":{"videoId":"a"}},{"contentDetails":{"videoId":"b"}}]}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"items":[{"contentDetails":{"videoId":"c"}}]}`))
+	})
+
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return &APIPlaylistExpander{
+		spotify: &spotifyAPIClient{
+			oauthClient: oauthClient{
+				httpClient:   srv.Client(),
+				clientID:     "id",
+				clientSecret: "secret",
+				tokenURL:     srv.URL + "/token",
+			},
+			apiURL: srv.URL + "/v1",
+		},
+		youTube: &YouTubeDataAPIClient{httpClient: srv.Client(), apiKey: "key", apiURL: srv.URL + "/youtube"},
+		limit:   limit,
+	}
+}
+
+func TestAPIPlaylistExpander_ExpandPlaylist(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr error
+		name    string
+		text    string
+		want    []string
+		limit   int
+	}{
+		{
+			name:  "spotify playlist across pages",
+			text:  "new mix https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M?si=abc",
+			limit: DefaultPlaylistLimit,
+			want:  []string{"https://open.spotify.com/track/1", "https://open.spotify.com/track/3"},
+		},
+		{
+			name:  "spotify playlist over the limit",
+			text:  "https://open.spotify.com/intl-de/playlist/37i9dQZF1DXcBWIGoYBM5M",
+			limit: 1,
+			want:  []string{"https://open.spotify.com/track/1"},
+		},
+		{
+			name:  "youtube playlist across pages",
+			text:  "https://www.youtube.com/playlist?list=PL123",
+			limit: DefaultPlaylistLimit,
+			want: []string{
+				"https://www.youtube.com/watch?v=a",
+				"https://www.youtube.com/watch?v=b",
+				"https://www.youtube.com/watch?v=c",
+			},
+		},
+		{
+			name:  "youtube music playlist keeps youtube music links",
+			text:  "https://music.youtube.com/playlist?list=PL123",
+			limit: 2,
+			want:  []string{"https://music.youtube.com/watch?v=a", "https://music.youtube.com/watch?v=b"},
+		},
+		{
+			name:    "track link is not a playlist",
+			text:    "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT",
+			limit:   DefaultPlaylistLimit,
+			wantErr: ErrNoURLFound,
+		},
+		{
+			name:    "unknown playlist",
+			text:    "https://www.youtube.com/playlist?list=PL404",
+			limit:   DefaultPlaylistLimit,
+			wantErr: ErrRequestFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := newTestPlaylistExpander(t, tt.limit).ExpandPlaylist(t.Context(), tt.text)
+			require.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewPlaylistExpander_WithoutCredentials(t *testing.T) {
+	t.Parallel()
+
+	got, err := NewPlaylistExpander("", "", "", DefaultPlaylistLimit).
+		ExpandPlaylist(t.Context(), "https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M")
+	require.ErrorIs(t, err, ErrNoURLFound)
+	assert.Empty(t, got)
+}
@@ -0,0 +1,190 @@
+package musicextractors
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// probeTimeout limits every probe request.
+const probeTimeout = 10 * time.Second
+
+// ErrProviderDown returned by extractors wrapped with WithProviderHealth while the last probe of the provider failed,
+// it wraps ErrCircuitOpen so the link is handled like one of a provider with an open circuit.
+var ErrProviderDown = fmt.Errorf("%w: provider is down", ErrCircuitOpen)
+
+// Probe is a lightweight availability check of a provider, a single request that has to answer with 2xx.
+type Probe struct {
+	// Method is the HTTP method of the request, GET if empty.
+	Method string
+	URL    string
+}
+
+// DefaultProbes are the checks of the built-in providers, an oEmbed lookup of a known track
+// where the provider has an oEmbed endpoint, a HEAD of the site otherwise.
+var DefaultProbes = map[ExtractProvider]Probe{
+	SpotifyProvider:       {Method: http.MethodHead, URL: "https://open.spotify.com/"},
+	YouTubeProvider:       {URL: youTubeOEmbedURL + "?format=json&url=https://www.youtube.com/watch?v=dQw4w9WgXcQ"},
+	YoutTubeMusicProvider: {URL: youTubeOEmbedURL + "?format=json&url=https://music.youtube.com/watch?v=dQw4w9WgXcQ"},
+	MixcloudProvider:      {URL: mixcloudOEmbedURL + "?format=json&url=https://www.mixcloud.com/spartacus/party-time/"},
+	AudiomackProvider:     {URL: audiomackOEmbedURL + "?format=json&url=https://audiomack.com/burna-boy/song/last-last"},
+	AmazonMusicProvider:   {Method: http.MethodHead, URL: "https://music.amazon.com/"},
+	AppleMusicProvider:    {Method: http.MethodHead, URL: "https://music.apple.com/"},
+	ShazamProvider:        {Method: http.MethodHead, URL: "https://www.shazam.com/"},
+	VimeoProvider:         {URL: vimeoOEmbedURL + "?url=https://vimeo.com/76979871"},
+	DailymotionProvider:   {Method: http.MethodHead, URL: "https://www.dailymotion.com/"},
+	LastFMProvider:        {Method: http.MethodHead, URL: "https://www.last.fm/"},
+	DiscogsProvider:       {Method: http.MethodHead, URL: "https://www.discogs.com/"},
+}
+
+// ProbeResult is the outcome of probing a provider.
+type ProbeResult struct {
+	// Err is nil if the provider is available, ErrRateLimited or ErrRequestFailed otherwise.
+	Err      error
+	Provider ExtractProvider
+	Latency  time.Duration
+}
+
+// Healthy reports if the provider answered the probe.
+func (r ProbeResult) Healthy() bool {
+	return r.Err == nil
+}
+
+// Prober checks the availability of a set of providers.
+type Prober struct {
+	httpClient *http.Client
+	probes     map[ExtractProvider]Probe
+}
+
+// NewProber creates a prober running the given probes.
+func NewProber(probes map[ExtractProvider]Probe) *Prober {
+	return &Prober{httpClient: &http.Client{Timeout: probeTimeout}, probes: probes}
+}
+
+// Probe runs the probe of every provider concurrently.
+//
+// Returns the results ordered by provider name.
+func (p *Prober) Probe(ctx context.Context) []ProbeResult {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make([]ProbeResult, 0, len(p.probes))
+	)
+
+	for provider, probe := range p.probes {
+		wg.Go(func() {
+			latency, err := p.probe(ctx, probe)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			results = append(results, ProbeResult{Provider: provider, Latency: latency, Err: err})
+		})
+	}
+
+	wg.Wait()
+
+	slices.SortFunc(results, func(a, b ProbeResult) int {
+		return strings.Compare(string(a.Provider), string(b.Provider))
+	})
+
+	return results
+}
+
+// probe sends the request of a single probe and measures how long it took to answer.
+func (p *Prober) probe(ctx context.Context, probe Probe) (time.Duration, error) {
+	method := probe.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, probe.URL, http.NoBody)
+	if err != nil {
+		return 0, ErrRequestFailed
+	}
+
+	start := time.Now()
+
+	resp, err := p.httpClient.Do(request)
+	if err != nil {
+		return time.Since(start), ErrRequestFailed
+	}
+
+	latency := time.Since(start)
+
+	defer func() {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return latency, statusError(resp.StatusCode)
+	}
+
+	return latency, nil
+}
+
+// ProbeProviders runs the DefaultProbes of every built-in provider.
+//
+// Returns the results ordered by provider name.
+func ProbeProviders(ctx context.Context) []ProbeResult {
+	return NewProber(DefaultProbes).Probe(ctx)
+}
+
+// ProviderHealth remembers the failed probes of the providers, so the lookups of a provider that is known
+// to be down can be skipped instead of waiting for them to time out.
+//
+// A failed probe is only trusted for a while, so a provider isn't skipped for good if it's not probed again.
+type ProviderHealth struct {
+	now    func() time.Time
+	downAt map[ExtractProvider]time.Time
+	ttl    time.Duration
+	mu     sync.RWMutex
+}
+
+// NewProviderHealth creates a health registry where every provider is up,
+// failed probes are trusted for ttl.
+func NewProviderHealth(ttl time.Duration) *ProviderHealth {
+	return &ProviderHealth{now: time.Now, downAt: map[ExtractProvider]time.Time{}, ttl: ttl}
+}
+
+// Update records the results of a probe run, providers missing from results keep their state.
+func (h *ProviderHealth) Update(results []ProbeResult) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, r := range results {
+		if r.Healthy() {
+			delete(h.downAt, r.Provider)
+			continue
+		}
+
+		h.downAt[r.Provider] = h.now()
+	}
+}
+
+// Down reports if the last probe of provider failed within the ttl.
+func (h *ProviderHealth) Down(provider ExtractProvider) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	at, ok := h.downAt[provider]
+
+	return ok && h.now().Sub(at) < h.ttl
+}
+
+// WithProviderHealth wraps fn so it returns ErrProviderDown without calling fn while provider is down.
+func WithProviderHealth(h *ProviderHealth, provider ExtractProvider, fn MetadataExtractorFunc) MetadataExtractorFunc {
+	return func(ctx context.Context, url string) (TrackMetadata, error) {
+		if h.Down(provider) {
+			return TrackMetadata{}, ErrProviderDown
+		}
+
+		return fn(ctx, url)
+	}
+}
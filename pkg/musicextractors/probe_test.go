@@ -0,0 +1,137 @@
+package musicextractors
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProber_Probe(t *testing.T) {
+	t.Parallel()
+
+	var method string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/head":
+			method = r.Method
+		case "/limited":
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "/broken":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	results := NewProber(map[ExtractProvider]Probe{
+		YouTubeProvider:     {URL: srv.URL + "/broken"},
+		SpotifyProvider:     {Method: http.MethodHead, URL: srv.URL + "/head"},
+		AmazonMusicProvider: {URL: srv.URL + "/limited"},
+	}).Probe(t.Context())
+
+	require.Len(t, results, 3)
+
+	assert.Equal(t, AmazonMusicProvider, results[0].Provider)
+	require.ErrorIs(t, results[0].Err, ErrRateLimited)
+	assert.False(t, results[0].Healthy())
+
+	assert.Equal(t, SpotifyProvider, results[1].Provider)
+	require.NoError(t, results[1].Err)
+	assert.True(t, results[1].Healthy())
+	assert.Equal(t, http.MethodHead, method)
+
+	assert.Equal(t, YouTubeProvider, results[2].Provider)
+	require.ErrorIs(t, results[2].Err, ErrRequestFailed)
+}
+
+func TestProber_Unreachable(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	results := NewProber(map[ExtractProvider]Probe{SpotifyProvider: {URL: srv.URL}}).Probe(t.Context())
+
+	require.Len(t, results, 1)
+	require.ErrorIs(t, results[0].Err, ErrRequestFailed)
+}
+
+func TestDefaultProbes_CoverBuiltInProviders(t *testing.T) {
+	t.Parallel()
+
+	for _, p := range []ExtractProvider{
+		SpotifyProvider,
+		YouTubeProvider,
+		YoutTubeMusicProvider,
+		MixcloudProvider,
+		AudiomackProvider,
+		AmazonMusicProvider,
+	} {
+		assert.Contains(t, DefaultProbes, p)
+	}
+}
+
+func TestProviderHealth_DownExpires(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_700_000_000, 0)
+	h := NewProviderHealth(time.Minute)
+	h.now = func() time.Time { return now }
+
+	h.Update([]ProbeResult{
+		{Provider: SpotifyProvider, Err: ErrRequestFailed},
+		{Provider: YouTubeProvider},
+	})
+
+	assert.True(t, h.Down(SpotifyProvider))
+	assert.False(t, h.Down(YouTubeProvider))
+	assert.False(t, h.Down(MixcloudProvider), "providers never probed are up")
+
+	now = now.Add(time.Minute)
+
+	assert.False(t, h.Down(SpotifyProvider), "failed probes are only trusted for the ttl")
+}
+
+func TestProviderHealth_Recovers(t *testing.T) {
+	t.Parallel()
+
+	h := NewProviderHealth(time.Minute)
+
+	h.Update([]ProbeResult{{Provider: SpotifyProvider, Err: ErrRequestFailed}})
+	require.True(t, h.Down(SpotifyProvider))
+
+	h.Update([]ProbeResult{{Provider: YouTubeProvider}})
+	assert.True(t, h.Down(SpotifyProvider), "providers missing from the results keep their state")
+
+	h.Update([]ProbeResult{{Provider: SpotifyProvider}})
+	assert.False(t, h.Down(SpotifyProvider))
+}
+
+func TestWithProviderHealth_SkipsDownProvider(t *testing.T) {
+	t.Parallel()
+
+	h := NewProviderHealth(time.Minute)
+	calls := 0
+
+	extract := WithProviderHealth(h, SpotifyProvider, func(context.Context, string) (TrackMetadata, error) {
+		calls++
+
+		return TrackMetadata{Title: "Song"}, nil
+	})
+
+	m, err := extract(t.Context(), "https://open.spotify.com/track/1")
+	require.NoError(t, err)
+	assert.Equal(t, "Song", m.Title)
+
+	h.Update([]ProbeResult{{Provider: SpotifyProvider, Err: ErrRequestFailed}})
+
+	_, err = extract(t.Context(), "https://open.spotify.com/track/1")
+	require.ErrorIs(t, err, ErrProviderDown)
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 1, calls, "down provider must not be called")
+}
@@ -0,0 +1,124 @@
+package musicextractors
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The patterns used to parse Last.fm track and Discogs release pages.
+var (
+	// lastFMTitleSuffixRegex matches the site name Last.fm appends to the Open Graph titles.
+	lastFMTitleSuffixRegex = regexp.MustCompile(`\s*\|\s+Last\.fm$`)
+	// discogsReleaseIDRegex captures the ID of the release from its path.
+	discogsReleaseIDRegex = regexp.MustCompile(`/release/(\d+)`)
+	// discogsTitleSuffixRegex matches the site name Discogs appends to the Open Graph titles,
+	// both the "- Discogs" and the "| Releases | Discogs" form.
+	discogsTitleSuffixRegex = regexp.MustCompile(`\s+(?:[-|–]|\|\s+Releases\s+\|)\s+Discogs$`)
+	// discogsReleaseDetailsRegex matches the "(1987, Vinyl)" release details after the title, capturing the year.
+	discogsReleaseDetailsRegex = regexp.MustCompile(`\s+\((\d{4})(?:,[^)]*)?\)$`)
+)
+
+// referenceClient resolves track metadata from the Open Graph tags of the reference sites,
+// Last.fm track pages and Discogs releases, shared in crate-digging channels instead of streaming links.
+type referenceClient struct {
+	httpClient *http.Client
+}
+
+// ogTitle fetches the page and returns its trimmed Open Graph title without the site name matched by suffix.
+func (c *referenceClient) ogTitle(ctx context.Context, pageURL string, suffix *regexp.Regexp) (string, string, error) {
+	html, err := fetchPage(ctx, c.httpClient, pageURL, "")
+	if err != nil {
+		return "", "", err
+	}
+
+	titleMatches := ogTitleRegex.FindStringSubmatch(html)
+	if len(titleMatches) < 2 {
+		return "", "", ErrNoTitleFound
+	}
+
+	title := suffix.ReplaceAllString(strings.TrimSpace(titleMatches[1]), "")
+	if title == "" {
+		return "", "", ErrNoTitleFound
+	}
+
+	return title, html, nil
+}
+
+// lastFMMetadata fetches the track page and parses its "Title — Artist | Last.fm" Open Graph title.
+func (c *referenceClient) lastFMMetadata(ctx context.Context, trackURL string) (TrackMetadata, error) {
+	title, html, err := c.ogTitle(ctx, trackURL, lastFMTitleSuffixRegex)
+	if err != nil {
+		return TrackMetadata{}, err
+	}
+
+	m := TrackMetadata{Title: title}
+
+	// Song titles can contain dashes too, the artist is always after the last one
+	if i := strings.LastIndex(title, " — "); i > 0 {
+		m.Title, m.Artist = title[:i], title[i+len(" — "):]
+	}
+
+	if imageMatches := ogImageRegex.FindStringSubmatch(html); len(imageMatches) == 2 {
+		m.ArtworkURL = imageMatches[1]
+	}
+
+	m.DurationSeconds = pageDuration(html)
+
+	return m, nil
+}
+
+// discogsMetadata fetches the release page and parses its "Artist - Title (1987, Vinyl) - Discogs" Open Graph title.
+func (c *referenceClient) discogsMetadata(ctx context.Context, releaseURL string) (TrackMetadata, error) {
+	title, html, err := c.ogTitle(ctx, releaseURL, discogsTitleSuffixRegex)
+	if err != nil {
+		return TrackMetadata{}, err
+	}
+
+	m := TrackMetadata{}
+
+	if detailMatches := discogsReleaseDetailsRegex.FindStringSubmatch(title); len(detailMatches) == 2 {
+		m.ReleaseYear, _ = strconv.Atoi(detailMatches[1])
+		title = title[:len(title)-len(detailMatches[0])]
+	}
+
+	if title == "" {
+		return TrackMetadata{}, ErrNoTitleFound
+	}
+
+	m.Title = title
+
+	// Discogs separates the artist with an en dash on newer pages, artist names rarely contain either of them
+	for _, sep := range []string{" – ", " - "} {
+		if artist, release, found := strings.Cut(title, sep); found && artist != "" {
+			m.Title, m.Artist = release, artist
+			break
+		}
+	}
+
+	if idMatches := discogsReleaseIDRegex.FindStringSubmatch(releaseURL); len(idMatches) == 2 {
+		m.ProviderID = idMatches[1]
+	}
+
+	if imageMatches := ogImageRegex.FindStringSubmatch(html); len(imageMatches) == 2 {
+		m.ArtworkURL = imageMatches[1]
+	}
+
+	return m, nil
+}
+
+// LastFMMetadataExtractor fetches and extracts the track metadata from a Last.fm URL using Open Graph meta tags.
+func LastFMMetadataExtractor(ctx context.Context, trackURL string) (TrackMetadata, error) {
+	c := referenceClient{httpClient: http.DefaultClient}
+
+	return c.lastFMMetadata(ctx, trackURL)
+}
+
+// DiscogsMetadataExtractor fetches and extracts the release metadata from a Discogs URL using Open Graph meta tags.
+func DiscogsMetadataExtractor(ctx context.Context, releaseURL string) (TrackMetadata, error) {
+	c := referenceClient{httpClient: http.DefaultClient}
+
+	return c.discogsMetadata(ctx, releaseURL)
+}
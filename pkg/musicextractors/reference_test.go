@@ -0,0 +1,120 @@
+package musicextractors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReferenceClient_LastFMMetadata(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr error
+		name    string
+		body    string
+		want    TrackMetadata
+		status  int
+	}{
+		{
+			name:   "track page",
+			status: http.StatusOK,
+			body: `<meta property="og:title" content="Never Gonna Give You Up — Rick Astley | Last.fm">` +
+				`<meta property="og:image" content="https://lastfm.freetls.fastly.net/i/u/ar0/cover.jpg">`,
+			want: TrackMetadata{
+				Title:      "Never Gonna Give You Up",
+				Artist:     "Rick Astley",
+				ArtworkURL: "https://lastfm.freetls.fastly.net/i/u/ar0/cover.jpg",
+			},
+		},
+		{
+			name:   "title without artist",
+			status: http.StatusOK,
+			body:   `<meta property="og:title" content="Never Gonna Give You Up">`,
+			want:   TrackMetadata{Title: "Never Gonna Give You Up"},
+		},
+		{
+			name:    "only the site name",
+			status:  http.StatusOK,
+			body:    `<meta property="og:title" content=" | Last.fm">`,
+			wantErr: ErrNoTitleFound,
+		},
+		{
+			name:    "missing page",
+			status:  http.StatusNotFound,
+			wantErr: ErrRequestFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			t.Cleanup(srv.Close)
+
+			c := referenceClient{httpClient: srv.Client()}
+
+			got, err := c.lastFMMetadata(t.Context(), srv.URL+"/music/Rick+Astley/_/Never+Gonna+Give+You+Up")
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReferenceClient_DiscogsMetadata(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		body string
+		want TrackMetadata
+	}{
+		{
+			name: "release with details",
+			body: `<meta property="og:title" content="Rick Astley - Never Gonna Give You Up (1987, Vinyl) - Discogs">` +
+				`<meta property="og:image" content="https://i.discogs.com/cover.jpg">`,
+			want: TrackMetadata{
+				Title:       "Never Gonna Give You Up",
+				Artist:      "Rick Astley",
+				ProviderID:  "249504",
+				ArtworkURL:  "https://i.discogs.com/cover.jpg",
+				ReleaseYear: 1987,
+			},
+		},
+		{
+			name: "en dash and releases suffix",
+			body: `<meta property="og:title" content="Rick Astley – Never Gonna Give You Up | Releases | Discogs">`,
+			want: TrackMetadata{Title: "Never Gonna Give You Up", Artist: "Rick Astley", ProviderID: "249504"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			t.Cleanup(srv.Close)
+
+			c := referenceClient{httpClient: srv.Client()}
+
+			got, err := c.discogsMetadata(t.Context(), srv.URL+"/release/249504-Rick-Astley-Never-Gonna-Give-You-Up")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package musicextractors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// TitleStrategy selects how a regex provider resolves the metadata of its links.
+type TitleStrategy string
+
+const (
+	// TitleStrategyOpenGraph fetches the linked page and reads its og:title, og:image and duration meta tags.
+	TitleStrategyOpenGraph TitleStrategy = "opengraph"
+	// TitleStrategyNone skips the lookup and uses the URL itself as the title.
+	TitleStrategyNone TitleStrategy = "none"
+	// titleStrategyOEmbedPrefix is followed by the oEmbed endpoint of the provider, e.g. oembed:https://example.com/oembed.
+	titleStrategyOEmbedPrefix = "oembed:"
+)
+
+// OEmbedTitleStrategy returns the strategy that resolves the metadata via the given oEmbed endpoint.
+func OEmbedTitleStrategy(endpoint string) TitleStrategy {
+	return TitleStrategy(titleStrategyOEmbedPrefix + endpoint)
+}
+
+// RegexProvider is a provider defined by configuration instead of code.
+type RegexProvider struct {
+	URLExtractor      MusicURLExtractorFunc
+	MetadataExtractor MetadataExtractorFunc
+	Name              ExtractProvider
+}
+
+// openGraphMetadata fetches a page and builds the metadata from its Open Graph tags,
+// titles in the "Artist - Title" format are split.
+func openGraphMetadata(ctx context.Context, httpClient *http.Client, pageURL string) (TrackMetadata, error) {
+	html, err := fetchPage(ctx, httpClient, pageURL, "")
+	if err != nil {
+		return TrackMetadata{}, err
+	}
+
+	titleMatches := ogTitleRegex.FindStringSubmatch(html)
+	if len(titleMatches) < 2 || strings.TrimSpace(titleMatches[1]) == "" {
+		return TrackMetadata{}, ErrNoTitleFound
+	}
+
+	m := TrackMetadata{Title: strings.TrimSpace(titleMatches[1])}
+
+	if artist, song, found := strings.Cut(m.Title, " - "); found {
+		m.Artist, m.Title = artist, song
+	}
+
+	if imageMatches := ogImageRegex.FindStringSubmatch(html); len(imageMatches) == 2 {
+		m.ArtworkURL = imageMatches[1]
+	}
+
+	m.DurationSeconds = pageDuration(html)
+
+	return m, nil
+}
+
+// metadataExtractor returns the extractor implementing the strategy, using httpClient for the lookups.
+func (ts TitleStrategy) metadataExtractor(httpClient *http.Client) (MetadataExtractorFunc, error) {
+	switch {
+	case ts == TitleStrategyOpenGraph:
+		return func(ctx context.Context, url string) (TrackMetadata, error) {
+			return openGraphMetadata(ctx, httpClient, url)
+		}, nil
+	case ts == TitleStrategyNone:
+		return func(_ context.Context, url string) (TrackMetadata, error) {
+			return TrackMetadata{Title: url}, nil
+		}, nil
+	case strings.HasPrefix(string(ts), titleStrategyOEmbedPrefix):
+		endpoint := strings.TrimPrefix(string(ts), titleStrategyOEmbedPrefix)
+		if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+			return nil, fmt.Errorf("%w: oembed endpoint %q is not an http URL", ErrInvalidProvider, endpoint)
+		}
+
+		c := &oEmbedClient{httpClient: httpClient, apiURL: endpoint}
+
+		return c.metadata, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown title strategy %q", ErrInvalidProvider, ts)
+	}
+}
+
+// NewRegexProvider creates a provider named name, whose links are matched by urlPattern
+// and whose metadata is resolved with titleStrategy.
+//
+// Returns the provider or ErrInvalidProvider if the name is empty, the pattern doesn't compile
+// or the strategy is unknown.
+func NewRegexProvider(name ExtractProvider, urlPattern string, titleStrategy TitleStrategy) (RegexProvider, error) {
+	if strings.TrimSpace(string(name)) == "" {
+		return RegexProvider{}, fmt.Errorf("%w: empty name", ErrInvalidProvider)
+	}
+
+	re, err := regexp.Compile(urlPattern)
+	if err != nil {
+		return RegexProvider{}, fmt.Errorf("%w: %s: %w", ErrInvalidProvider, name, err)
+	}
+
+	metadata, err := titleStrategy.metadataExtractor(http.DefaultClient)
+	if err != nil {
+		return RegexProvider{}, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return RegexProvider{
+		Name: name,
+		URLExtractor: func(text string) (string, ExtractProvider, error) {
+			url, uErr := regexURLExtractor(text, re)
+
+			return url, name, uErr
+		},
+		MetadataExtractor: metadata,
+	}, nil
+}
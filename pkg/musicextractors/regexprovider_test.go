@@ -0,0 +1,115 @@
+package musicextractors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRegexProvider(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr  error
+		name     string
+		provider ExtractProvider
+		pattern  string
+		strategy TitleStrategy
+	}{
+		{name: "open graph", provider: "plex", pattern: `https://plex\.example\.com/track/\d+`, strategy: TitleStrategyOpenGraph},
+		{name: "oembed", provider: "bandcamp", pattern: `https://\w+\.bandcamp\.com/track/[\w\-]+`, strategy: OEmbedTitleStrategy("https://bandcamp.com/oembed")},
+		{name: "no lookup", provider: "jellyfin", pattern: `https://media\.internal/items/\w+`, strategy: TitleStrategyNone},
+		{name: "empty name", pattern: `https://example\.com`, strategy: TitleStrategyNone, wantErr: ErrInvalidProvider},
+		{name: "broken pattern", provider: "broken", pattern: `https://(example`, strategy: TitleStrategyNone, wantErr: ErrInvalidProvider},
+		{name: "unknown strategy", provider: "plex", pattern: `https://example\.com`, strategy: "scrape", wantErr: ErrInvalidProvider},
+		{name: "oembed without endpoint", provider: "plex", pattern: `https://example\.com`, strategy: OEmbedTitleStrategy(""), wantErr: ErrInvalidProvider},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := NewRegexProvider(tt.provider, tt.pattern, tt.strategy)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.provider, p.Name)
+		})
+	}
+}
+
+func TestRegexProvider_URLExtractor(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewRegexProvider("jellyfin", `https://media\.internal/items/\w+`, TitleStrategyNone)
+	require.NoError(t, err)
+
+	url, provider, err := p.URLExtractor("from our server https://media.internal/items/abc123 enjoy")
+	require.NoError(t, err)
+	assert.Equal(t, "https://media.internal/items/abc123", url)
+	assert.Equal(t, ExtractProvider("jellyfin"), provider)
+
+	_, _, err = p.URLExtractor("https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT")
+	require.ErrorIs(t, err, ErrNoURLFound)
+
+	m, err := p.MetadataExtractor(t.Context(), "https://media.internal/items/abc123")
+	require.NoError(t, err)
+	assert.Equal(t, TrackMetadata{Title: "https://media.internal/items/abc123"}, m)
+}
+
+func TestTitleStrategy_OpenGraph(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`<meta property="og:title" content="Rick Astley - Never Gonna Give You Up">` +
+			`<meta property="og:image" content="https://plex.example.com/cover.jpg">` +
+			`<meta property="og:video:duration" content="213">`))
+	}))
+	t.Cleanup(srv.Close)
+
+	extract, err := TitleStrategyOpenGraph.metadataExtractor(srv.Client())
+	require.NoError(t, err)
+
+	got, err := extract(t.Context(), srv.URL+"/track/1")
+	require.NoError(t, err)
+	assert.Equal(t, TrackMetadata{
+		Title:           "Never Gonna Give You Up",
+		Artist:          "Rick Astley",
+		ArtworkURL:      "https://plex.example.com/cover.jpg",
+		DurationSeconds: 213,
+	}, got)
+}
+
+func TestPageDuration(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		html string
+		want int
+	}{
+		{name: "music duration", html: `<meta property="music:duration" content="213">`, want: 213},
+		{name: "video duration", html: `<meta property="og:video:duration" content="3725">`, want: 3725},
+		{
+			name: "music duration wins",
+			html: `<meta property="og:video:duration" content="220"><meta name="music:duration" content="213">`,
+			want: 213,
+		},
+		{name: "missing", html: `<meta property="og:title" content="Song">`, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, pageDuration(tt.html))
+		})
+	}
+}
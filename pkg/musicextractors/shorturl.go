@@ -0,0 +1,146 @@
+package musicextractors
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"time"
+)
+
+const (
+	// defaultMaxHops is the number of redirects a short URL is followed through.
+	defaultMaxHops = 5
+	// resolveTimeout limits every request to a shortener.
+	resolveTimeout = 10 * time.Second
+)
+
+// ShortenerHosts are the URL shorteners resolved by default, including the hosts they redirect through.
+//
+// youtu.be is not listed, the YouTube extractor matches it directly.
+var ShortenerHosts = []string{"spotify.link", "spotify.app.link", "deezer.page.link", "apple.co", "shz.am", "t.co", "bit.ly"}
+
+// linkCandidateRegex matches every http(s) link in a text, the resolver only follows the ones of a shortener host.
+var linkCandidateRegex = regexp.MustCompile(`https?://[^\s<>|"]+`)
+
+// ShortURLResolver replaces short links with the URL they redirect to.
+type ShortURLResolver interface {
+	// ResolveText replaces every short link in text with the URL it redirects to,
+	// links that can't be resolved are kept as is.
+	ResolveText(ctx context.Context, text string) string
+}
+
+// RedirectResolver is a ShortURLResolver following the redirects of the shortener hosts.
+//
+// Only https requests to the allowlisted shortener hosts are made, the redirect chain stops at the first
+// URL of another host without requesting it, so a short link can't make the bot call arbitrary hosts.
+type RedirectResolver struct {
+	httpClient *http.Client
+	hosts      []string
+	maxHops    int
+}
+
+var _ ShortURLResolver = (*RedirectResolver)(nil)
+
+// ResolveText replaces every short link in text with the URL it redirects to,
+// links that can't be resolved are kept as is.
+func (r *RedirectResolver) ResolveText(ctx context.Context, text string) string {
+	return linkCandidateRegex.ReplaceAllStringFunc(text, func(link string) string {
+		if !r.isShortener(link) {
+			return link
+		}
+
+		resolved, err := r.Resolve(ctx, link)
+		if err != nil {
+			return link
+		}
+
+		return resolved
+	})
+}
+
+// isShortener reports if link points to one of the allowlisted shortener hosts over https.
+func (r *RedirectResolver) isShortener(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+
+	return u.Scheme == "https" && slices.Contains(r.hosts, u.Host)
+}
+
+// Resolve follows the redirects of shortURL until it leaves the shortener hosts.
+//
+// Returns the first URL outside the shortener hosts, ErrTooManyRedirects if it's not reached within the hop limit,
+// ErrInvalidURL if shortURL is not a short link or a redirect has no valid location, and ErrRequestFailed if
+// a shortener doesn't redirect.
+func (r *RedirectResolver) Resolve(ctx context.Context, shortURL string) (string, error) {
+	current := shortURL
+
+	for range r.maxHops {
+		if !r.isShortener(current) {
+			if current == shortURL {
+				return "", ErrInvalidURL
+			}
+
+			return current, nil
+		}
+
+		next, err := r.follow(ctx, current)
+		if err != nil {
+			return "", err
+		}
+
+		current = next
+	}
+
+	if r.isShortener(current) {
+		return "", ErrTooManyRedirects
+	}
+
+	return current, nil
+}
+
+// follow requests a short link and returns the absolute location it redirects to.
+func (r *RedirectResolver) follow(ctx context.Context, link string) (string, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, link, http.NoBody)
+	if err != nil {
+		return "", ErrRequestFailed
+	}
+
+	resp, err := r.httpClient.Do(request)
+	if err != nil {
+		return "", ErrRequestFailed
+	}
+
+	defer func() {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < http.StatusMultipleChoices || resp.StatusCode >= http.StatusBadRequest {
+		return "", statusError(resp.StatusCode)
+	}
+
+	location, err := resp.Location()
+	if err != nil {
+		return "", ErrInvalidURL
+	}
+
+	return location.String(), nil
+}
+
+// NewRedirectResolver creates a resolver of the ShortenerHosts.
+func NewRedirectResolver() *RedirectResolver {
+	return &RedirectResolver{
+		httpClient: &http.Client{
+			Timeout: resolveTimeout,
+			// Every hop is checked against the allowlist before it's requested
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		hosts:   ShortenerHosts,
+		maxHops: defaultMaxHops,
+	}
+}
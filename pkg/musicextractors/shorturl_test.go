@@ -0,0 +1,127 @@
+package musicextractors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRedirectResolver starts a TLS shortener redirecting with the given handler,
+// the resolver only allows the host of the test server.
+func newTestRedirectResolver(t *testing.T, handler http.HandlerFunc) (*RedirectResolver, string) {
+	t.Helper()
+
+	srv := httptest.NewTLSServer(handler)
+	t.Cleanup(srv.Close)
+
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	client := srv.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+
+	return &RedirectResolver{httpClient: client, hosts: []string{u.Host}, maxHops: 3}, srv.URL
+}
+
+func TestRedirectResolver_Resolve(t *testing.T) {
+	t.Parallel()
+
+	const target = "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT?si=abc"
+
+	r, base := newTestRedirectResolver(t, func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/short":
+			// Relative locations are resolved against the shortener
+			http.Redirect(w, req, "/hop", http.StatusMovedPermanently)
+		case "/hop":
+			http.Redirect(w, req, target, http.StatusTemporaryRedirect)
+		case "/loop":
+			http.Redirect(w, req, "/loop", http.StatusFound)
+		case "/page":
+			w.WriteHeader(http.StatusOK)
+		case "/limited":
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	})
+
+	tests := []struct {
+		wantErr error
+		name    string
+		link    string
+		want    string
+	}{
+		{name: "redirect chain", link: base + "/short", want: target},
+		{name: "redirect loop", link: base + "/loop", wantErr: ErrTooManyRedirects},
+		{name: "no redirect", link: base + "/page", wantErr: ErrRequestFailed},
+		{name: "rate limited", link: base + "/limited", wantErr: ErrRateLimited},
+		{name: "not a shortener", link: "https://example.com/short", wantErr: ErrInvalidURL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := r.Resolve(t.Context(), tt.link)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRedirectResolver_Resolve_DoesNotRequestOtherHosts(t *testing.T) {
+	t.Parallel()
+
+	var internalCalls atomic.Int32
+
+	internal := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		internalCalls.Add(1)
+	}))
+	t.Cleanup(internal.Close)
+
+	r, base := newTestRedirectResolver(t, func(w http.ResponseWriter, req *http.Request) {
+		http.Redirect(w, req, internal.URL+"/admin", http.StatusFound)
+	})
+
+	got, err := r.Resolve(t.Context(), base+"/short")
+	require.NoError(t, err)
+	assert.Equal(t, internal.URL+"/admin", got)
+	assert.Zero(t, internalCalls.Load())
+}
+
+func TestRedirectResolver_ResolveText(t *testing.T) {
+	t.Parallel()
+
+	r, base := newTestRedirectResolver(t, func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		http.Redirect(w, req, "https://www.deezer.com/track/3135556", http.StatusFound)
+	})
+
+	got := r.ResolveText(t.Context(), "listen "+base+"/abc and https://youtu.be/dQw4w9WgXcQ, "+base+"/broken")
+
+	assert.Equal(t, "listen https://www.deezer.com/track/3135556 and https://youtu.be/dQw4w9WgXcQ, "+base+"/broken", got)
+}
+
+func TestRedirectResolver_IsShortener_RequiresHTTPS(t *testing.T) {
+	t.Parallel()
+
+	r := NewRedirectResolver()
+
+	assert.True(t, r.isShortener("https://spotify.link/abc123"))
+	assert.False(t, r.isShortener("http://spotify.link/abc123"))
+	assert.False(t, r.isShortener("https://spotify.link.evil.com/abc123"))
+}
@@ -0,0 +1,23 @@
+package musicextractors
+
+import (
+	"regexp"
+	"strings"
+)
+
+// slackLinkRegex matches Slack mrkdwn links like <https://...> and <https://...|label>, capturing the URL.
+var slackLinkRegex = regexp.MustCompile(`<(https?://[^|>\s]+)(?:\|[^>]*)?>`)
+
+// slackEntities are the HTML entities Slack escapes in message text.
+var slackEntities = strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">")
+
+// UnwrapSlackLinks replaces Slack formatted links in a message text with their bare URL,
+// so the URL extractors don't pick up the surrounding brackets or the label.
+//
+// Only http(s) links are unwrapped, user (<@U123>) and channel (<#C123>) references are kept as is.
+// The &amp; entity Slack uses for & in query strings is unescaped as well.
+func UnwrapSlackLinks(text string) string {
+	return slackLinkRegex.ReplaceAllStringFunc(text, func(link string) string {
+		return slackEntities.Replace(slackLinkRegex.FindStringSubmatch(link)[1])
+	})
+}
@@ -0,0 +1,76 @@
+package musicextractors
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnwrapSlackLinks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "bare link",
+			text: "Listen <https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT>",
+			want: "Listen https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT",
+		},
+		{
+			name: "labeled link",
+			text: "<https://youtu.be/dQw4w9WgXcQ|my favourite song> is a banger",
+			want: "https://youtu.be/dQw4w9WgXcQ is a banger",
+		},
+		{
+			name: "escaped ampersand in query",
+			text: "<https://music.youtube.com/watch?v=dQw4w9WgXcQ&amp;list=RD1>",
+			want: "https://music.youtube.com/watch?v=dQw4w9WgXcQ&list=RD1",
+		},
+		{
+			name: "user and channel references are kept",
+			text: "<@U123> check <#C456|music> <https://youtu.be/dQw4w9WgXcQ>",
+			want: "<@U123> check <#C456|music> https://youtu.be/dQw4w9WgXcQ",
+		},
+		{
+			name: "plain text",
+			text: "no links here",
+			want: "no links here",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, UnwrapSlackLinks(tt.text))
+		})
+	}
+}
+
+func FuzzUnwrapSlackLinks(f *testing.F) {
+	for _, seed := range []string{
+		"",
+		"<https://youtu.be/dQw4w9WgXcQ|my favourite song>",
+		"<@U123> <#C456|music> <https://a.b/?x=1&amp;y=2>",
+		"<<https://a.b>|>>",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, text string) {
+		got := UnwrapSlackLinks(text)
+
+		// Unwrapping only ever drops the brackets, the labels and the escaping
+		if len(got) > len(text) {
+			t.Fatalf("unwrapped %q into the longer %q", text, got)
+		}
+
+		if !strings.Contains(text, "<http") && got != text {
+			t.Fatalf("changed %q without links into %q", text, got)
+		}
+	})
+}
@@ -0,0 +1,133 @@
+package musicextractors
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const (
+	spotifyTokenURL = "https://accounts.spotify.com/api/token"
+	spotifyAPIURL   = "https://api.spotify.com/v1"
+)
+
+var spotifyTrackIDRegex = regexp.MustCompile(`spotify\.com/(?:intl-[a-zA-Z\-]+/)?(?:embed/)?track/(\w+)`)
+
+// spotifyAPIClient talks to the Spotify Web API using the client credentials flow,
+// or the refresh token flow on behalf of a user if refreshToken is set.
+type spotifyAPIClient struct {
+	oauthClient
+	apiURL string
+}
+
+// trackMetadata fetches the track from the Web API.
+func (c *spotifyAPIClient) trackMetadata(ctx context.Context, musicURL string) (TrackMetadata, error) {
+	matches := spotifyTrackIDRegex.FindStringSubmatch(musicURL)
+	if len(matches) < 2 {
+		return TrackMetadata{}, ErrNoTrackID
+	}
+
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return TrackMetadata{}, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiURL+"/tracks/"+matches[1], http.NoBody)
+	if err != nil {
+		return TrackMetadata{}, ErrRequestFailed
+	}
+
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := c.httpClient.Do(request)
+	if err != nil {
+		return TrackMetadata{}, ErrRequestFailed
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return TrackMetadata{}, statusError(resp.StatusCode)
+	}
+
+	var track struct {
+		ID      string `json:"id"`
+		Name    string `json:"name"`
+		Artists []struct {
+			Name string `json:"name"`
+		} `json:"artists"`
+		Album struct {
+			Name   string `json:"name"`
+			Images []struct {
+				URL string `json:"url"`
+			} `json:"images"`
+		} `json:"album"`
+		ExternalIDs struct {
+			ISRC string `json:"isrc"`
+		} `json:"external_ids"`
+		DurationMS int `json:"duration_ms"`
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(&track); err != nil || track.Name == "" {
+		return TrackMetadata{}, ErrNoTitleFound
+	}
+
+	artists := make([]string, 0, len(track.Artists))
+	for _, a := range track.Artists {
+		artists = append(artists, a.Name)
+	}
+
+	m := TrackMetadata{
+		Title:           track.Name,
+		Artist:          strings.Join(artists, ", "),
+		Album:           track.Album.Name,
+		ProviderID:      track.ID,
+		ISRC:            strings.ToUpper(track.ExternalIDs.ISRC),
+		DurationSeconds: track.DurationMS / 1000,
+	}
+
+	// Spotify orders the album images by size, the first one is the largest
+	if len(track.Album.Images) > 0 {
+		m.ArtworkURL = track.Album.Images[0].URL
+	}
+
+	return m, nil
+}
+
+// trackTitle fetches the track from the Web API and formats it as "Artist(s) - Title".
+func (c *spotifyAPIClient) trackTitle(musicURL string) (string, error) {
+	return ToTitleExtractor(c.trackMetadata)(musicURL)
+}
+
+// newSpotifyAPIClient creates a Web API client with the default Spotify endpoints.
+func newSpotifyAPIClient(clientID, clientSecret string) *spotifyAPIClient {
+	return &spotifyAPIClient{
+		oauthClient: oauthClient{
+			httpClient:   http.DefaultClient,
+			clientID:     clientID,
+			clientSecret: clientSecret,
+			tokenURL:     spotifyTokenURL,
+		},
+		apiURL: spotifyAPIURL,
+	}
+}
+
+// NewSpotifyAPIMetadataExtractor creates a MetadataExtractorFunc that resolves Spotify tracks through the Spotify Web API.
+//
+// clientID and clientSecret are the credentials of a Spotify app, used for the client credentials flow.
+// The access token is cached and refreshed on expiry, so the returned func is safe for concurrent use.
+func NewSpotifyAPIMetadataExtractor(clientID, clientSecret string) MetadataExtractorFunc {
+	return newSpotifyAPIClient(clientID, clientSecret).trackMetadata
+}
+
+// NewSpotifyAPITitleExtractor creates a TitleExtractorFunc that resolves Spotify track titles through the Spotify Web API.
+//
+// clientID and clientSecret are the credentials of a Spotify app, used for the client credentials flow.
+// The access token is cached and refreshed on expiry, so the returned func is safe for concurrent use.
+func NewSpotifyAPITitleExtractor(clientID, clientSecret string) TitleExtractorFunc {
+	return newSpotifyAPIClient(clientID, clientSecret).trackTitle
+}
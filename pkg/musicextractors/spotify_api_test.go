@@ -0,0 +1,139 @@
+package musicextractors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSpotifyAPI(t *testing.T, trackStatus int, trackBody string) (*spotifyAPIClient, *atomic.Int32) {
+	t.Helper()
+
+	tokenCalls := &atomic.Int32{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		tokenCalls.Add(1)
+
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "id" || secret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"access_token":"tkn","expires_in":3600}`))
+	})
+	mux.HandleFunc("GET /v1/tracks/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tkn" || r.PathValue("id") != "4cOdK2wGLETKBW3PvgPWqT" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(trackStatus)
+		_, _ = w.Write([]byte(trackBody))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return &spotifyAPIClient{
+		oauthClient: oauthClient{
+			httpClient:   srv.Client(),
+			clientID:     "id",
+			clientSecret: "secret",
+			tokenURL:     srv.URL + "/token",
+		},
+		apiURL: srv.URL + "/v1",
+	}, tokenCalls
+}
+
+func TestSpotifyAPIClient_TrackTitle(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr     error
+		name        string
+		url         string
+		trackBody   string
+		want        string
+		trackStatus int
+	}{
+		{
+			name:        "multiple artists",
+			url:         "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT?si=abc",
+			trackStatus: http.StatusOK,
+			trackBody:   `{"name":"Song","artists":[{"name":"A"},{"name":"B"}]}`,
+			want:        "A, B - Song",
+		},
+		{
+			name:        "no artists",
+			url:         "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT",
+			trackStatus: http.StatusOK,
+			trackBody:   `{"name":"Song","artists":[]}`,
+			want:        "Song",
+		},
+		{
+			name:        "empty name",
+			url:         "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT",
+			trackStatus: http.StatusOK,
+			trackBody:   `{"name":""}`,
+			wantErr:     ErrNoTitleFound,
+		},
+		{
+			name:        "api error",
+			url:         "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT",
+			trackStatus: http.StatusTooManyRequests,
+			wantErr:     ErrRequestFailed,
+		},
+		{
+			name:    "not a track url",
+			url:     "https://open.spotify.com/album/4LH4d3cOWNNsVw41Gqt2kv",
+			wantErr: ErrNoTrackID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c, _ := newTestSpotifyAPI(t, tt.trackStatus, tt.trackBody)
+
+			got, err := c.trackTitle(tt.url)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, got)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSpotifyAPIClient_TrackTitle_CachesToken(t *testing.T) {
+	t.Parallel()
+
+	c, tokenCalls := newTestSpotifyAPI(t, http.StatusOK, `{"name":"Song","artists":[{"name":"A"}]}`)
+
+	for range 3 {
+		_, err := c.trackTitle("https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT")
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(1), tokenCalls.Load())
+}
+
+func TestSpotifyAPIClient_TrackMetadata_ISRC(t *testing.T) {
+	t.Parallel()
+
+	c, _ := newTestSpotifyAPI(t, http.StatusOK, `{"id":"4cOdK2wGLETKBW3PvgPWqT","name":"Song","external_ids":{"isrc":"gbarl9300135"}}`)
+
+	got, err := c.trackMetadata(t.Context(), "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT")
+	require.NoError(t, err)
+	assert.Equal(t, "GBARL9300135", got.ISRC)
+}
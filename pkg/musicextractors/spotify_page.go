@@ -0,0 +1,118 @@
+package musicextractors
+
+import (
+	"context"
+	"html"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// The patterns used to parse Spotify track pages.
+var (
+	// spotifyMusicianRegex matches the artist meta tag of track pages, it's the same in every locale.
+	spotifyMusicianRegex = regexp.MustCompile(`<meta\s+(?:property|name)="music:musician_description"\s+content="([^"]+)"`)
+	// spotifyListenPrefixRegex matches the "Listen to <title> on Spotify." sentence newer descriptions start with,
+	// localized like "Escucha <title> en Spotify." or "Hör dir <title> auf Spotify an.".
+	spotifyListenPrefixRegex = regexp.MustCompile(`(?i)^.*spotify[^.·•。]*[.。]\s*`)
+)
+
+// spotifyItemTypes are the localized names of the item type listed in the descriptions of track pages.
+var spotifyItemTypes = map[string]struct{}{
+	"song": {}, "single": {}, "canción": {}, "canção": {}, "música": {}, "chanson": {}, "titre": {}, "brano": {},
+	"titel": {}, "nummer": {}, "låt": {}, "sang": {}, "kappale": {}, "utwór": {}, "skladba": {}, "dal": {},
+	"песня": {}, "трек": {}, "şarkı": {}, "lagu": {}, "曲": {}, "歌曲": {}, "노래": {}, "เพลง": {},
+}
+
+// spotifyPageClient resolves track metadata from the Open Graph tags of Spotify track pages.
+type spotifyPageClient struct {
+	httpClient *http.Client
+	// language is sent as the Accept-Language of the requests, empty leaves the choice to Spotify.
+	language string
+}
+
+// metadata fetches the track page and parses its title, artist, artwork and duration.
+func (c *spotifyPageClient) metadata(ctx context.Context, musicURL string) (TrackMetadata, error) {
+	page, err := fetchPage(ctx, c.httpClient, musicURL, c.language)
+	if err != nil {
+		return TrackMetadata{}, err
+	}
+
+	// FindStringSubmatch returns the full match, then the capture groups themselves,
+	// hence why we check for the 2. element
+	titleMatches := ogTitleRegex.FindStringSubmatch(page)
+	if len(titleMatches) < 2 {
+		return TrackMetadata{}, ErrNoTitleFound
+	}
+
+	m := TrackMetadata{Title: strings.TrimSpace(titleMatches[1])}
+
+	if idMatches := spotifyTrackIDRegex.FindStringSubmatch(musicURL); len(idMatches) == 2 {
+		m.ProviderID = idMatches[1]
+	}
+
+	if imageMatches := ogImageRegex.FindStringSubmatch(page); len(imageMatches) == 2 {
+		m.ArtworkURL = imageMatches[1]
+	}
+
+	m.DurationSeconds = pageDuration(page)
+
+	if musicianMatches := spotifyMusicianRegex.FindStringSubmatch(page); len(musicianMatches) == 2 {
+		m.Artist = strings.TrimSpace(html.UnescapeString(musicianMatches[1]))
+		return m, nil
+	}
+
+	if descMatches := ogDescriptionRegex.FindStringSubmatch(page); len(descMatches) == 2 {
+		m.Artist = spotifyDescriptionArtist(html.UnescapeString(descMatches[1]), html.UnescapeString(m.Title))
+	}
+
+	return m, nil
+}
+
+// spotifyDescriptionArtist finds the artist in the og:description of a track page, in any locale.
+//
+// Spotify used "Artist · Album · Song · Year" descriptions, newer pages use "Listen to Title on Spotify. Song · Artist · Year",
+// both localized and with varying separators. The listen sentence, the title, the item type and the year are dropped,
+// the artist is the first remaining part. Descriptions without separators are returned as is.
+func spotifyDescriptionArtist(description, title string) string {
+	description = strings.TrimSpace(description)
+
+	parts := strings.FieldsFunc(description, func(r rune) bool { return r == '·' || r == '•' })
+	if len(parts) < 2 {
+		return description
+	}
+
+	parts[0] = spotifyListenPrefixRegex.ReplaceAllString(parts[0], "")
+
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+
+		if p == "" || strings.EqualFold(p, title) || isYear(p) {
+			continue
+		}
+
+		if _, isType := spotifyItemTypes[strings.ToLower(p)]; isType {
+			continue
+		}
+
+		return p
+	}
+
+	return ""
+}
+
+// isYear reports if s is a four digit year.
+func isYear(s string) bool {
+	if len(s) != 4 {
+		return false
+	}
+
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+
+	return true
+}
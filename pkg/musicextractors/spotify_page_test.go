@@ -0,0 +1,89 @@
+package musicextractors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpotifyPageClient_Metadata(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		language string
+		body     string
+		want     TrackMetadata
+	}{
+		{
+			name:     "musician tag",
+			language: "en",
+			body: `<meta property="og:title" content="Never Gonna Give You Up">` +
+				`<meta property="og:description" content="Escucha Never Gonna Give You Up en Spotify. Canción · Rick Astley · 1987">` +
+				`<meta name="music:musician_description" content="Rick Astley">` +
+				`<meta property="og:image" content="https://i.scdn.co/image/cover">` +
+				`<meta name="music:duration" content="213">`,
+			want: TrackMetadata{
+				Title:           "Never Gonna Give You Up",
+				Artist:          "Rick Astley",
+				ArtworkURL:      "https://i.scdn.co/image/cover",
+				DurationSeconds: 213,
+			},
+		},
+		{
+			name: "localized description",
+			body: `<meta property="og:title" content="Never Gonna Give You Up">` +
+				`<meta property="og:description" content="Hör dir Never Gonna Give You Up auf Spotify an. Song · Rick Astley · 1987">`,
+			want: TrackMetadata{Title: "Never Gonna Give You Up", Artist: "Rick Astley"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, tt.language, r.Header.Get("Accept-Language"))
+
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			t.Cleanup(srv.Close)
+
+			c := spotifyPageClient{httpClient: srv.Client(), language: tt.language}
+
+			got, err := c.metadata(t.Context(), srv.URL+"/track/4cOdK2wGLETKBW3PvgPWqT")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSpotifyDescriptionArtist(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		description string
+		want        string
+	}{
+		{name: "legacy format", description: "Rick Astley · Never Gonna Give You Up · Song · 1987", want: "Rick Astley"},
+		{name: "english", description: "Listen to Never Gonna Give You Up on Spotify. Song · Rick Astley · 1987", want: "Rick Astley"},
+		{name: "spanish", description: "Escucha Never Gonna Give You Up en Spotify. Canción · Rick Astley · 1987", want: "Rick Astley"},
+		{name: "german", description: "Hör dir Never Gonna Give You Up auf Spotify an. Song · Rick Astley · 1987", want: "Rick Astley"},
+		{name: "japanese", description: "Spotifyで聴こう。曲 · Rick Astley · 1987", want: "Rick Astley"},
+		{name: "bullet separator", description: "Listen on Spotify. Titre • Rick Astley • 1987", want: "Rick Astley"},
+		{name: "artist with a dot", description: "Mr. Oizo · Flat Beat · Song · 1999", want: "Mr. Oizo"},
+		{name: "no separator", description: "Rick Astley", want: "Rick Astley"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, spotifyDescriptionArtist(tt.description, "Never Gonna Give You Up"))
+		})
+	}
+}
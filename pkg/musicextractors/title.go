@@ -2,22 +2,49 @@ package musicextractors
 
 import (
 	"context"
-	"encoding/json"
 	"io"
 	"net/http"
-	"net/url"
 	"regexp"
-	"strings"
+	"strconv"
 )
 
-// SpotifyTitleExtractor fetches and extracts the title from a Spotify URL using Open Graph meta tags.
-func SpotifyTitleExtractor(musicURL string) (string, error) {
-	request, err := http.NewRequestWithContext(context.TODO(), http.MethodGet, musicURL, http.NoBody)
+// The Open Graph meta tag patterns of the scraped track pages.
+var (
+	ogTitleRegex       = regexp.MustCompile(`<meta\s+property="og:title"\s+content="([^"]+)"`)
+	ogImageRegex       = regexp.MustCompile(`<meta\s+property="og:image"\s+content="([^"]+)"`)
+	ogDescriptionRegex = regexp.MustCompile(`<meta\s+property="og:description"\s+content="([^"]+)"`)
+	musicDurationRegex = regexp.MustCompile(`<meta\s+(?:property|name)="music:duration"\s+content="(\d+)"`)
+	videoDurationRegex = regexp.MustCompile(`<meta\s+(?:property|name)="og:video:duration"\s+content="(\d+)"`)
+)
+
+// pageDuration returns the duration of the page's track in seconds from its music:duration
+// or og:video:duration meta tag, or 0 if it has neither.
+func pageDuration(html string) int {
+	for _, re := range []*regexp.Regexp{musicDurationRegex, videoDurationRegex} {
+		if matches := re.FindStringSubmatch(html); len(matches) == 2 {
+			if seconds, err := strconv.Atoi(matches[1]); err == nil {
+				return seconds
+			}
+		}
+	}
+
+	return 0
+}
+
+// fetchPage downloads the HTML of a page, used by the extractors parsing Open Graph meta tags.
+//
+// A non-empty language is sent as the Accept-Language of the request.
+func fetchPage(ctx context.Context, httpClient *http.Client, pageURL, language string) (string, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, http.NoBody)
 	if err != nil {
 		return "", ErrRequestFailed
 	}
 
-	resp, err := http.DefaultClient.Do(request)
+	if language != "" {
+		request.Header.Set("Accept-Language", language)
+	}
+
+	resp, err := httpClient.Do(request)
 	if err != nil {
 		return "", ErrRequestFailed
 	}
@@ -27,7 +54,7 @@ func SpotifyTitleExtractor(musicURL string) (string, error) {
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", ErrRequestFailed
+		return "", statusError(resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -35,86 +62,38 @@ func SpotifyTitleExtractor(musicURL string) (string, error) {
 		return "", ErrRequestFailed
 	}
 
-	html := string(body)
-
-	// Extract og:title for song title
-	titleRegex := regexp.MustCompile(`<meta\s+property="og:title"\s+content="([^"]+)"`)
-	titleMatches := titleRegex.FindStringSubmatch(html)
-
-	// FindStringSubmatch returns the full match, then the capture groups themselves,
-	// hence why we check for the 2. element
-	if len(titleMatches) < 2 {
-		return "", ErrNoTitleFound
-	}
+	return string(body), nil
+}
 
-	songTitle := strings.TrimSpace(titleMatches[1])
+// SpotifyMetadataExtractor fetches and extracts the track metadata from a Spotify URL using Open Graph meta tags.
+func SpotifyMetadataExtractor(ctx context.Context, musicURL string) (TrackMetadata, error) {
+	c := spotifyPageClient{httpClient: http.DefaultClient}
 
-	// Extract og:description for artist info
-	descRegex := regexp.MustCompile(`<meta\s+property="og:description"\s+content="([^"]+)"`)
-	descMatches := descRegex.FindStringSubmatch(html)
+	return c.metadata(ctx, musicURL)
+}
 
-	if len(descMatches) < 2 {
-		// If no description found, just return the title
-		return songTitle, nil
-	}
+// NewSpotifyMetadataExtractor returns a SpotifyMetadataExtractor requesting the track pages in the given language,
+// an Accept-Language value like "en" or "de-DE, en;q=0.8". An empty language leaves the choice to Spotify.
+func NewSpotifyMetadataExtractor(language string) MetadataExtractorFunc {
+	c := &spotifyPageClient{httpClient: http.DefaultClient, language: language}
 
-	description := strings.TrimSpace(descMatches[1])
+	return c.metadata
+}
 
-	// Description format: "Artist(s) · Album/Song · Type · Year"
-	// Split by " · " and take only the first part (artists)
-	// We use SplitN here, so we only do the first split, cause we only interested in the first element
-	artistParts := strings.SplitN(description, " · ", 2)
+// SpotifyTitleExtractor fetches and extracts the title from a Spotify URL using Open Graph meta tags.
+func SpotifyTitleExtractor(musicURL string) (string, error) {
+	return ToTitleExtractor(SpotifyMetadataExtractor)(musicURL)
+}
 
-	// A short-circuit in case of a spotify html schema cahange
-	if len(artistParts) < 2 {
-		return description + " - " + songTitle, nil
-	}
+// YouTubeMetadataExtractor fetches and extracts the track metadata from a YouTube URL using oEmbed API,
+// for YouTube Music links the artist is extracted as well.
+func YouTubeMetadataExtractor(ctx context.Context, videoURL string) (TrackMetadata, error) {
+	c := oEmbedClient{httpClient: http.DefaultClient, apiURL: youTubeOEmbedURL}
 
-	return artistParts[0] + " - " + songTitle, nil
+	return c.youTubeMetadata(ctx, videoURL)
 }
 
 // YouTubeTitleExtractor fetches and extracts the title from a YouTube URL using oEmbed API.
 func YouTubeTitleExtractor(videoURL string) (string, error) {
-	// Use YouTube's oEmbed API for faster title extraction
-	oembed := url.URL{
-		Scheme: "https",
-		Host:   "youtube.com",
-		Path:   "oembed",
-	}
-	query := oembed.Query()
-	query.Add("format", "json")
-	query.Add("url", videoURL)
-	oembed.RawQuery = query.Encode()
-
-	request, err := http.NewRequestWithContext(context.TODO(), http.MethodGet, oembed.String(), http.NoBody)
-	if err != nil {
-		return "", ErrRequestFailed
-	}
-
-	resp, err := http.DefaultClient.Do(request)
-	if err != nil {
-		return "", ErrRequestFailed
-	}
-
-	defer func() {
-		_ = resp.Body.Close()
-	}()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", ErrRequestFailed
-	}
-
-	var result struct {
-		Title string `json:"title"`
-	}
-
-	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", ErrNoTitleFound
-	}
-
-	if result.Title == "" {
-		return "", ErrNoTitleFound
-	}
-
-	return result.Title, nil
+	return ToTitleExtractor(YouTubeMetadataExtractor)(videoURL)
 }
@@ -0,0 +1,25 @@
+package musicextractors
+
+// ExtractTrackID returns the provider and the provider's track ID of a music URL.
+//
+// YouTube Music shares YouTube's video ID namespace, so youtu.be, youtube.com/watch and
+// music.youtube.com links of the same video are all reported as YouTubeProvider with the same ID,
+// which makes the returned pair usable as a deduplication key.
+//
+// Returns ErrNoTrackID if the URL doesn't point to a single track of an implemented provider.
+func ExtractTrackID(rawURL string) (ExtractProvider, string, error) {
+	canonical, err := NormalizeURL(rawURL)
+	if err != nil {
+		return "", "", ErrNoTrackID
+	}
+
+	if matches := spotifyTrackIDRegex.FindStringSubmatch(canonical); len(matches) == 2 {
+		return SpotifyProvider, matches[1], nil
+	}
+
+	if videoID, yErr := youTubeVideoID(canonical); yErr == nil {
+		return YouTubeProvider, videoID, nil
+	}
+
+	return "", "", ErrNoTrackID
+}
@@ -0,0 +1,101 @@
+package musicextractors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractTrackID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr      error
+		name         string
+		url          string
+		wantProvider ExtractProvider
+		wantID       string
+	}{
+		{
+			name:         "spotify track",
+			url:          "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT?si=abc",
+			wantProvider: SpotifyProvider,
+			wantID:       "4cOdK2wGLETKBW3PvgPWqT",
+		},
+		{
+			name:         "youtube watch",
+			url:          "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+			wantProvider: YouTubeProvider,
+			wantID:       "dQw4w9WgXcQ",
+		},
+		{
+			name:         "youtu.be short link",
+			url:          "https://youtu.be/dQw4w9WgXcQ?si=share",
+			wantProvider: YouTubeProvider,
+			wantID:       "dQw4w9WgXcQ",
+		},
+		{
+			name:         "youtube music with playlist context",
+			url:          "https://music.youtube.com/watch?v=dQw4w9WgXcQ&list=RDAMVMdQw4w9WgXcQ",
+			wantProvider: YouTubeProvider,
+			wantID:       "dQw4w9WgXcQ",
+		},
+		{
+			name:         "youtube shorts",
+			url:          "https://www.youtube.com/shorts/dQw4w9WgXcQ",
+			wantProvider: YouTubeProvider,
+			wantID:       "dQw4w9WgXcQ",
+		},
+		{
+			name:         "slack formatted uppercase host",
+			url:          "<https://WWW.YOUTUBE.COM/watch?v=dQw4w9WgXcQ|song>",
+			wantProvider: YouTubeProvider,
+			wantID:       "dQw4w9WgXcQ",
+		},
+		{
+			name:         "spotify locale prefixed track",
+			url:          "https://open.spotify.com/intl-fr/track/4cOdK2wGLETKBW3PvgPWqT",
+			wantProvider: SpotifyProvider,
+			wantID:       "4cOdK2wGLETKBW3PvgPWqT",
+		},
+		{
+			name:         "spotify embed player",
+			url:          "https://open.spotify.com/embed/track/4cOdK2wGLETKBW3PvgPWqT",
+			wantProvider: SpotifyProvider,
+			wantID:       "4cOdK2wGLETKBW3PvgPWqT",
+		},
+		{
+			name:    "spotify album",
+			url:     "https://open.spotify.com/album/4LH4d3cOWNNsVw41Gqt2kv",
+			wantErr: ErrNoTrackID,
+		},
+		{
+			name:    "unknown provider",
+			url:     "https://example.com/track/123",
+			wantErr: ErrNoTrackID,
+		},
+		{
+			name:    "not an url",
+			url:     "plain text",
+			wantErr: ErrNoTrackID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			provider, id, err := ExtractTrackID(tt.url)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantProvider, provider)
+			assert.Equal(t, tt.wantID, id)
+		})
+	}
+}
@@ -1,6 +1,11 @@
 // Package musicextractors contains the reusable logic for extracting different music URLs from long texts
 package musicextractors
 
+import (
+	"context"
+	"strings"
+)
+
 // ExtractProvider stands for the implemented URL and Title extract providers.
 type ExtractProvider string
 
@@ -11,8 +16,44 @@ const (
 	YouTubeProvider ExtractProvider = "youtube"
 	// YoutTubeMusicProvider that implements both URL and music title extractor funcs.
 	YoutTubeMusicProvider ExtractProvider = "youtube-music"
+	// MixcloudProvider that implements both URL and music title extractor funcs, for shows and DJ mixes.
+	MixcloudProvider ExtractProvider = "mixcloud"
+	// AudiomackProvider that implements both URL and music title extractor funcs.
+	AudiomackProvider ExtractProvider = "audiomack"
+	// AmazonMusicProvider that implements both URL and music title extractor funcs.
+	AmazonMusicProvider ExtractProvider = "amazon-music"
+	// AppleMusicProvider that implements both URL and music title extractor funcs.
+	AppleMusicProvider ExtractProvider = "apple-music"
+	// ShazamProvider that implements both URL and music title extractor funcs.
+	ShazamProvider ExtractProvider = "shazam"
+	// VimeoProvider that implements both URL and music title extractor funcs, for music videos.
+	VimeoProvider ExtractProvider = "vimeo"
+	// DailymotionProvider that implements both URL and music title extractor funcs, for music videos.
+	DailymotionProvider ExtractProvider = "dailymotion"
+	// LastFMProvider that implements both URL and music title extractor funcs, for Last.fm track pages.
+	LastFMProvider ExtractProvider = "lastfm"
+	// DiscogsProvider that implements both URL and music title extractor funcs, for Discogs releases.
+	DiscogsProvider ExtractProvider = "discogs"
 )
 
+// Providers returns every built-in provider.
+func Providers() []ExtractProvider {
+	return []ExtractProvider{
+		SpotifyProvider,
+		YouTubeProvider,
+		YoutTubeMusicProvider,
+		MixcloudProvider,
+		AudiomackProvider,
+		AmazonMusicProvider,
+		AppleMusicProvider,
+		ShazamProvider,
+		VimeoProvider,
+		DailymotionProvider,
+		LastFMProvider,
+		DiscogsProvider,
+	}
+}
+
 // MusicURLExtractorFunc is extracting music links from text messages
 //
 // text is the input text that possibly contains a link for an implemented provider
@@ -26,3 +67,65 @@ type MusicURLExtractorFunc func(text string) (string, ExtractProvider, error)
 //
 // returns the extracted title and an error if any.
 type TitleExtractorFunc func(url string) (string, error)
+
+// TrackMetadata contains every information a provider could tell about a single track.
+//
+// Only Title is guaranteed to be set, every other field is best-effort and depends on the provider.
+type TrackMetadata struct {
+	Title      string
+	Artist     string
+	Album      string
+	ArtworkURL string
+	ProviderID string
+	// ISRC is the International Standard Recording Code, the same recording has the same ISRC on every provider.
+	ISRC            string
+	Genres          []string
+	DurationSeconds int
+	ReleaseYear     int
+}
+
+// DisplayTitle formats the metadata as "Artist - Title", or just the title if the artist is unknown.
+func (m TrackMetadata) DisplayTitle() string {
+	if m.Artist == "" {
+		return m.Title
+	}
+
+	return m.Artist + " - " + m.Title
+}
+
+// MetadataExtractorFunc is extracting every available track metadata from music urls
+//
+// url is the input url that we have to fetch the metadata for
+//
+// returns the extracted metadata and an error if any.
+type MetadataExtractorFunc func(ctx context.Context, url string) (TrackMetadata, error)
+
+// FromTitleExtractor adapts a TitleExtractorFunc to a MetadataExtractorFunc.
+//
+// Titles in the "Artist - Title" format are split into the Artist and Title fields.
+func FromTitleExtractor(te TitleExtractorFunc) MetadataExtractorFunc {
+	return func(_ context.Context, url string) (TrackMetadata, error) {
+		title, err := te(url)
+		if err != nil {
+			return TrackMetadata{}, err
+		}
+
+		if artist, song, found := strings.Cut(title, " - "); found {
+			return TrackMetadata{Title: song, Artist: artist}, nil
+		}
+
+		return TrackMetadata{Title: title}, nil
+	}
+}
+
+// ToTitleExtractor adapts a MetadataExtractorFunc to a TitleExtractorFunc that returns the DisplayTitle.
+func ToTitleExtractor(me MetadataExtractorFunc) TitleExtractorFunc {
+	return func(url string) (string, error) {
+		m, err := me(context.TODO(), url)
+		if err != nil {
+			return "", err
+		}
+
+		return m.DisplayTitle(), nil
+	}
+}
@@ -0,0 +1,56 @@
+package musicextractors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromTitleExtractor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		title string
+		want  TrackMetadata
+	}{
+		{
+			name:  "artist and title",
+			title: "Daft Punk - One More Time",
+			want:  TrackMetadata{Artist: "Daft Punk", Title: "One More Time"},
+		},
+		{
+			name:  "title with dash",
+			title: "Daft Punk - One More Time - Radio Edit",
+			want:  TrackMetadata{Artist: "Daft Punk", Title: "One More Time - Radio Edit"},
+		},
+		{
+			name:  "title only",
+			title: "One More Time",
+			want:  TrackMetadata{Title: "One More Time"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			me := FromTitleExtractor(func(string) (string, error) { return tt.title, nil })
+
+			got, err := me(t.Context(), "https://example.com")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.title, got.DisplayTitle())
+		})
+	}
+}
+
+func TestFromTitleExtractor_Error(t *testing.T) {
+	t.Parallel()
+
+	me := FromTitleExtractor(func(string) (string, error) { return "", ErrNoTitleFound })
+
+	_, err := me(t.Context(), "https://example.com")
+	require.ErrorIs(t, err, ErrNoTitleFound)
+}
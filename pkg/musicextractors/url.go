@@ -2,11 +2,89 @@ package musicextractors
 
 import (
 	"regexp"
+	"slices"
+	"strings"
+	"unicode"
 )
 
+// maxURLLength is the length of the longest word of a message that is searched for links,
+// longer words are way past any link of the supported providers and only make the regexes work harder.
+const maxURLLength = 2048
+
+// The patterns used by the URL extractors, compiled once at package init.
+var (
+	// SpotifyURLRegex matches Spotify track links, including the locale prefixed and embed player links.
+	SpotifyURLRegex = regexp.MustCompile(`https?://(?:open\.)?spotify\.com/(?:intl-[a-zA-Z\-]+/)?(?:embed/)?track/[\w\-?=&]+`)
+	// YouTubeURLRegex matches YouTube watch, youtu.be, Shorts and live links.
+	YouTubeURLRegex = regexp.MustCompile(`https?://(?:www\.)?(?:youtube\.com/(?:watch\?v=|shorts/|live/)|youtu\.be/)[\w\-]+`)
+	// YouTubeMusicURLRegex matches YouTube Music watch links.
+	YouTubeMusicURLRegex = regexp.MustCompile(`https?://music\.youtube\.com/watch\?v=[\w\-]+(?:&[\w=&\-]+)?`)
+	// MixcloudURLRegex matches Mixcloud show links in the mixcloud.com/<user>/<show>/ format,
+	// the show may contain percent-encoded characters but only complete escapes.
+	MixcloudURLRegex = regexp.MustCompile(`https?://(?:www\.|m\.)?mixcloud\.com/[\w\-]+/(?:[\w\-]|%[0-9A-Fa-f]{2})+/?`)
+	// AudiomackURLRegex matches Audiomack song links, both the audiomack.com/<artist>/song/<song>
+	// and the legacy audiomack.com/song/<artist>/<song> format.
+	AudiomackURLRegex = regexp.MustCompile(`https?://(?:www\.)?audiomack\.com/(?:[\w\-]+/song|song/[\w\-]+)/[\w\-]+`)
+	// AmazonMusicURLRegex matches Amazon Music track links and album links pointing to a track
+	// with the trackAsin parameter, on every regional domain.
+	AmazonMusicURLRegex = regexp.MustCompile(
+		`https?://music\.amazon\.[a-z]{2,3}(?:\.[a-z]{2})?/(?:tracks/\w+|albums/\w+\?(?:[\w\-]+=[\w\-]*&)*trackAsin=\w+)`,
+	)
+	// AppleMusicURLRegex matches Apple Music song links and album links pointing to a song with the i parameter,
+	// both with the region code (music.apple.com/us/...) of the share sheet.
+	AppleMusicURLRegex = regexp.MustCompile(
+		`https?://(?:geo\.)?music\.apple\.com/[a-z]{2}/` +
+			`(?:song/(?:(?:[\w\-]|%[0-9A-Fa-f]{2})+/)?\d+|album/(?:(?:[\w\-]|%[0-9A-Fa-f]{2})+/)?\d+\?(?:[\w\-]+=[\w\-]*&)*i=\d+)`,
+	)
+	// ShazamURLRegex matches Shazam track links, with or without the locale prefix and the song slug.
+	ShazamURLRegex = regexp.MustCompile(`https?://(?:www\.)?shazam\.com/(?:[a-z]{2}(?:-[a-z]{2})?/)?(?:track|song)/\d+(?:/[\w\-]+)?`)
+	// VimeoURLRegex matches Vimeo video links, including the channel, player and unlisted (vimeo.com/<id>/<hash>) links.
+	VimeoURLRegex = regexp.MustCompile(`https?://(?:www\.|player\.)?vimeo\.com/(?:channels/[\w\-]+/|video/)?\d+(?:/[0-9a-f]+)?`)
+	// DailymotionURLRegex matches Dailymotion video links and their dai.ly short links.
+	DailymotionURLRegex = regexp.MustCompile(`https?://(?:(?:www\.)?dailymotion\.com/video/|dai\.ly/)[a-zA-Z0-9]+`)
+	// LastFMURLRegex matches Last.fm track pages (last.fm/music/<artist>/_/<track>), with or without the language prefix,
+	// the names may contain percent-encoded characters but only complete escapes.
+	LastFMURLRegex = regexp.MustCompile(
+		`https?://(?:www\.)?last\.fm/(?:[a-z]{2}/)?music/(?:[\w\-+]|%[0-9A-Fa-f]{2})+/_/(?:[\w\-+]|%[0-9A-Fa-f]{2})+`,
+	)
+	// DiscogsURLRegex matches Discogs release links, both the discogs.com/release/<id>-<name>
+	// and the legacy discogs.com/<name>/release/<id> format, with or without the language prefix.
+	DiscogsURLRegex = regexp.MustCompile(
+		`https?://(?:www\.)?discogs\.com/(?:[a-z]{2}/)?(?:(?:[\w\-]|%[0-9A-Fa-f]{2})+/)?release/\d+(?:-(?:[\w\-]|%[0-9A-Fa-f]{2})+)?`,
+	)
+
+	// youTubeShortsOrLiveRegex matches the end of YouTube Shorts and live stream links, capturing the video ID.
+	youTubeShortsOrLiveRegex = regexp.MustCompile(`/(?:shorts|live)/([\w\-]+)$`)
+)
+
+// mixcloudReservedPaths are the first path segments of Mixcloud pages that are not user profiles,
+// and mixcloudProfileTabs are the tabs of profiles, neither of them are shows.
+var (
+	mixcloudReservedPaths = []string{"discover", "live", "select", "categories", "upload", "dashboard", "settings"}
+	mixcloudProfileTabs   = []string{"uploads", "favorites", "listens", "playlists", "followers", "following", "stream", "reposts"}
+)
+
+// linkCandidates splits a message text into the words that may contain a link.
+//
+// Words longer than maxURLLength or with embedded control characters are dropped,
+// links never contain them, so they are either garbage or crafted to trip up the extractors.
+func linkCandidates(text string) []string {
+	words := strings.Fields(text)
+
+	return slices.DeleteFunc(words, func(w string) bool {
+		return len(w) > maxURLLength || strings.ContainsFunc(w, unicode.IsControl)
+	})
+}
+
 // regexURLExtractor extracts the given URL regex from a text message.
+//
+// The regex is only run against the link candidates of the text, see linkCandidates.
 func regexURLExtractor(text string, re *regexp.Regexp) (string, error) {
-	matches := re.FindAllString(text, -1)
+	var matches []string
+
+	for _, w := range linkCandidates(text) {
+		matches = append(matches, re.FindAllString(w, -1)...)
+	}
 
 	if matches == nil {
 		return "", ErrNoURLFound
@@ -19,35 +97,138 @@ func regexURLExtractor(text string, re *regexp.Regexp) (string, error) {
 	return matches[0], nil
 }
 
-// SpotifyURLExtractor finds spotify track links in a given text
+// SpotifyURLExtractor finds spotify track links in a given text,
+// including the locale prefixed (open.spotify.com/intl-de/track/...) and embed player links.
 //
 // returns the found url, the type of ExtractProvider and an error if any.
 func SpotifyURLExtractor(text string) (string, ExtractProvider, error) {
-	spotifyRegex := regexp.MustCompile(`https?://(?:open\.)?spotify\.com/track/[\w\-?=&]+`)
-
-	url, err := regexURLExtractor(text, spotifyRegex)
+	url, err := regexURLExtractor(text, SpotifyURLRegex)
 
 	return url, SpotifyProvider, err
 }
 
-// YouTubeURLExtractor finds youtube watch links in a given text
+// youTubeWatchURL returns the standard watch URL of a video.
+func youTubeWatchURL(videoID string) string {
+	return "https://www.youtube.com/watch?v=" + videoID
+}
+
+// YouTubeURLExtractor finds youtube watch links in a given text,
+// including Shorts (youtube.com/shorts/<id>) and live (youtube.com/live/<id>) links,
+// which are returned as the standard watch URL.
 //
 // returns the found url, the type of ExtractProvider and an error if any.
 func YouTubeURLExtractor(text string) (string, ExtractProvider, error) {
-	youtubeRegex := regexp.MustCompile(`https?://(?:www\.)?(?:youtube\.com/watch\?v=|youtu\.be/)[\w\-]+`)
+	url, err := regexURLExtractor(text, YouTubeURLRegex)
+	if err != nil {
+		return url, YouTubeProvider, err
+	}
 
-	url, err := regexURLExtractor(text, youtubeRegex)
+	if matches := youTubeShortsOrLiveRegex.FindStringSubmatch(url); len(matches) == 2 {
+		url = youTubeWatchURL(matches[1])
+	}
 
-	return url, YouTubeProvider, err
+	return url, YouTubeProvider, nil
 }
 
 // YouTubeMusicURLExtractor finds youtube music watch links in a given text
 //
 // returns the found url, the type of ExtractProvider and an error if any.
 func YouTubeMusicURLExtractor(text string) (string, ExtractProvider, error) {
-	youtubeMusicRegex := regexp.MustCompile(`https?://music\.youtube\.com/watch\?v=[\w\-]+(?:&[\w=&\-]+)?`)
-
-	url, err := regexURLExtractor(text, youtubeMusicRegex)
+	url, err := regexURLExtractor(text, YouTubeMusicURLRegex)
 
 	return url, YoutTubeMusicProvider, err
 }
+
+// MixcloudURLExtractor finds Mixcloud show links in a given text, profile pages and their tabs are ignored.
+//
+// returns the found url, the type of ExtractProvider and an error if any.
+func MixcloudURLExtractor(text string) (string, ExtractProvider, error) {
+	url, err := regexURLExtractor(text, MixcloudURLRegex)
+	if err != nil {
+		return "", MixcloudProvider, err
+	}
+
+	// The regex guarantees the host and both path segments
+	_, path, _ := strings.Cut(url, "mixcloud.com/")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	if slices.Contains(mixcloudReservedPaths, segments[0]) || slices.Contains(mixcloudProfileTabs, segments[1]) {
+		return "", MixcloudProvider, ErrNoURLFound
+	}
+
+	return url, MixcloudProvider, nil
+}
+
+// AudiomackURLExtractor finds Audiomack song links in a given text
+//
+// returns the found url, the type of ExtractProvider and an error if any.
+func AudiomackURLExtractor(text string) (string, ExtractProvider, error) {
+	url, err := regexURLExtractor(text, AudiomackURLRegex)
+
+	return url, AudiomackProvider, err
+}
+
+// AmazonMusicURLExtractor finds Amazon Music track links in a given text,
+// including album links with a trackAsin parameter (music.amazon.de/albums/<id>?trackAsin=<id>).
+//
+// returns the found url, the type of ExtractProvider and an error if any.
+func AmazonMusicURLExtractor(text string) (string, ExtractProvider, error) {
+	url, err := regexURLExtractor(text, AmazonMusicURLRegex)
+
+	return url, AmazonMusicProvider, err
+}
+
+// AppleMusicURLExtractor finds Apple Music song links in a given text,
+// including album links with an i parameter (music.apple.com/us/album/<name>/<id>?i=<id>).
+//
+// returns the found url, the type of ExtractProvider and an error if any.
+func AppleMusicURLExtractor(text string) (string, ExtractProvider, error) {
+	url, err := regexURLExtractor(text, AppleMusicURLRegex)
+
+	return url, AppleMusicProvider, err
+}
+
+// ShazamURLExtractor finds Shazam track links in a given text
+//
+// returns the found url, the type of ExtractProvider and an error if any.
+func ShazamURLExtractor(text string) (string, ExtractProvider, error) {
+	url, err := regexURLExtractor(text, ShazamURLRegex)
+
+	return url, ShazamProvider, err
+}
+
+// VimeoURLExtractor finds Vimeo video links in a given text
+//
+// returns the found url, the type of ExtractProvider and an error if any.
+func VimeoURLExtractor(text string) (string, ExtractProvider, error) {
+	url, err := regexURLExtractor(text, VimeoURLRegex)
+
+	return url, VimeoProvider, err
+}
+
+// DailymotionURLExtractor finds Dailymotion video links in a given text, including dai.ly short links
+//
+// returns the found url, the type of ExtractProvider and an error if any.
+func DailymotionURLExtractor(text string) (string, ExtractProvider, error) {
+	url, err := regexURLExtractor(text, DailymotionURLRegex)
+
+	return url, DailymotionProvider, err
+}
+
+// LastFMURLExtractor finds Last.fm track links in a given text
+//
+// returns the found url, the type of ExtractProvider and an error if any.
+func LastFMURLExtractor(text string) (string, ExtractProvider, error) {
+	url, err := regexURLExtractor(text, LastFMURLRegex)
+
+	return url, LastFMProvider, err
+}
+
+// DiscogsURLExtractor finds Discogs release links in a given text
+//
+// returns the found url, the type of ExtractProvider and an error if any.
+func DiscogsURLExtractor(text string) (string, ExtractProvider, error) {
+	url, err := regexURLExtractor(text, DiscogsURLRegex)
+
+	return url, DiscogsProvider, err
+}
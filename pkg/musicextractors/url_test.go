@@ -1,8 +1,11 @@
 package musicextractors
 
 import (
+	"net/url"
 	"regexp"
+	"strings"
 	"testing"
+	"unicode"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -109,6 +112,30 @@ func TestSpotifyURLExtractor(t *testing.T) {
 			want:         "http://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT",
 			wantProvider: SpotifyProvider,
 		},
+		{
+			name:         "locale prefixed track URL",
+			text:         "Hör mal https://open.spotify.com/intl-de/track/4cOdK2wGLETKBW3PvgPWqT?si=abc123",
+			want:         "https://open.spotify.com/intl-de/track/4cOdK2wGLETKBW3PvgPWqT?si=abc123",
+			wantProvider: SpotifyProvider,
+		},
+		{
+			name:         "region locale prefixed track URL",
+			text:         "Ouça https://open.spotify.com/intl-pt-BR/track/4cOdK2wGLETKBW3PvgPWqT",
+			want:         "https://open.spotify.com/intl-pt-BR/track/4cOdK2wGLETKBW3PvgPWqT",
+			wantProvider: SpotifyProvider,
+		},
+		{
+			name:         "embed player URL",
+			text:         "Embedded https://open.spotify.com/embed/track/4cOdK2wGLETKBW3PvgPWqT?utm_source=generator",
+			want:         "https://open.spotify.com/embed/track/4cOdK2wGLETKBW3PvgPWqT?utm_source=generator",
+			wantProvider: SpotifyProvider,
+		},
+		{
+			name:         "locale prefixed playlist URL should fail",
+			text:         "My playlist https://open.spotify.com/intl-de/playlist/37i9dQZF1DXcBWIGoYBM5M",
+			wantProvider: SpotifyProvider,
+			wantErr:      ErrNoURLFound,
+		},
 		{
 			name:         "playlist URL should fail",
 			text:         "My playlist https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M",
@@ -200,6 +227,18 @@ func TestYouTubeURLExtractor(t *testing.T) {
 			want:         "https://youtu.be/dQw4w9WgXcQ",
 			wantProvider: YouTubeProvider,
 		},
+		{
+			name:         "shorts URL is returned as watch URL",
+			text:         "Check out https://www.youtube.com/shorts/dQw4w9WgXcQ?feature=share",
+			want:         "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+			wantProvider: YouTubeProvider,
+		},
+		{
+			name:         "live URL is returned as watch URL",
+			text:         "Live now https://youtube.com/live/dQw4w9WgXcQ",
+			want:         "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+			wantProvider: YouTubeProvider,
+		},
 		{
 			name:         "video ID with hyphen",
 			text:         "Watch https://youtu.be/dQw4w9Wg-cQ",
@@ -359,3 +398,599 @@ func TestYouTubeMusicURLExtractor(t *testing.T) {
 		})
 	}
 }
+
+func BenchmarkSpotifyURLExtractor(b *testing.B) {
+	text := "Hör mal https://open.spotify.com/intl-de/track/4cOdK2wGLETKBW3PvgPWqT?si=abc123 it's great"
+
+	for b.Loop() {
+		_, _, _ = SpotifyURLExtractor(text)
+	}
+}
+
+func BenchmarkYouTubeURLExtractor(b *testing.B) {
+	text := "Check out https://www.youtube.com/watch?v=dQw4w9WgXcQ it's great"
+
+	for b.Loop() {
+		_, _, _ = YouTubeURLExtractor(text)
+	}
+}
+
+func BenchmarkYouTubeMusicURLExtractor(b *testing.B) {
+	text := "Check out https://music.youtube.com/watch?v=dQw4w9WgXcQ&list=RD1 it's great"
+
+	for b.Loop() {
+		_, _, _ = YouTubeMusicURLExtractor(text)
+	}
+}
+
+func TestMixcloudURLExtractor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr error
+		name    string
+		text    string
+		want    string
+	}{
+		{
+			name: "show URL",
+			text: "Tonight's mix https://www.mixcloud.com/dj-example/friday-night-mix/ enjoy",
+			want: "https://www.mixcloud.com/dj-example/friday-night-mix/",
+		},
+		{
+			name: "mobile URL without trailing slash",
+			text: "https://m.mixcloud.com/dj-example/friday-night-mix",
+			want: "https://m.mixcloud.com/dj-example/friday-night-mix",
+		},
+		{
+			name:    "profile page",
+			text:    "Follow https://www.mixcloud.com/dj-example/",
+			wantErr: ErrNoURLFound,
+		},
+		{
+			name:    "profile tab",
+			text:    "https://www.mixcloud.com/dj-example/uploads/",
+			wantErr: ErrNoURLFound,
+		},
+		{
+			name:    "discover page",
+			text:    "https://www.mixcloud.com/discover/house/",
+			wantErr: ErrNoURLFound,
+		},
+		{
+			name:    "multiple shows",
+			text:    "https://www.mixcloud.com/a/one/ and https://www.mixcloud.com/b/two/",
+			wantErr: ErrMultipleResult,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, provider, err := MixcloudURLExtractor(tt.text)
+
+			assert.Equal(t, MixcloudProvider, provider)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, got)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAudiomackURLExtractor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr error
+		name    string
+		text    string
+		want    string
+	}{
+		{
+			name: "song URL",
+			text: "New one https://audiomack.com/burna-boy/song/last-last !",
+			want: "https://audiomack.com/burna-boy/song/last-last",
+		},
+		{
+			name: "legacy song URL",
+			text: "https://www.audiomack.com/song/burna-boy/last-last",
+			want: "https://www.audiomack.com/song/burna-boy/last-last",
+		},
+		{
+			name:    "album URL",
+			text:    "https://audiomack.com/burna-boy/album/love-damini",
+			wantErr: ErrNoURLFound,
+		},
+		{
+			name:    "artist page",
+			text:    "https://audiomack.com/burna-boy",
+			wantErr: ErrNoURLFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, provider, err := AudiomackURLExtractor(tt.text)
+
+			assert.Equal(t, AudiomackProvider, provider)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, got)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAmazonMusicURLExtractor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr error
+		name    string
+		text    string
+		want    string
+	}{
+		{
+			name: "track URL",
+			text: "Listen https://music.amazon.com/tracks/B0026NT2S8 now",
+			want: "https://music.amazon.com/tracks/B0026NT2S8",
+		},
+		{
+			name: "album URL with track",
+			text: "https://music.amazon.de/albums/B0026NT2RE?trackAsin=B0026NT2S8",
+			want: "https://music.amazon.de/albums/B0026NT2RE?trackAsin=B0026NT2S8",
+		},
+		{
+			name: "album URL with track after other parameters",
+			text: "https://music.amazon.co.uk/albums/B0026NT2RE?marketplaceId=A1F83G8C2ARO7P&trackAsin=B0026NT2S8",
+			want: "https://music.amazon.co.uk/albums/B0026NT2RE?marketplaceId=A1F83G8C2ARO7P&trackAsin=B0026NT2S8",
+		},
+		{
+			name:    "album URL without track",
+			text:    "https://music.amazon.com/albums/B0026NT2RE",
+			wantErr: ErrNoURLFound,
+		},
+		{
+			name:    "amazon store URL",
+			text:    "https://www.amazon.com/dp/B0026NT2S8",
+			wantErr: ErrNoURLFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, provider, err := AmazonMusicURLExtractor(tt.text)
+
+			assert.Equal(t, AmazonMusicProvider, provider)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, got)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRegexURLExtractor_RejectsPathologicalWords(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr error
+		name    string
+		text    string
+		want    string
+	}{
+		{
+			name:    "word longer than a link",
+			text:    "https://open.spotify.com/track/" + strings.Repeat("a", maxURLLength),
+			wantErr: ErrNoURLFound,
+		},
+		{
+			name:    "embedded control character",
+			text:    "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT\x00https://open.spotify.com/track/7GhIk7Il098yCjg4BQjzvb",
+			wantErr: ErrNoURLFound,
+		},
+		{
+			name: "pathological word next to a link",
+			text: strings.Repeat("x", 10*maxURLLength) + " https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT",
+			want: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT",
+		},
+		{
+			name: "link on its own line",
+			text: "listen\nhttps://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT\tnow",
+			want: "https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, _, err := SpotifyURLExtractor(tt.text)
+			require.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAppleMusicURLExtractor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr error
+		name    string
+		text    string
+		want    string
+	}{
+		{
+			name: "album URL with song",
+			text: "Listen https://music.apple.com/us/album/whenever-you-need-somebody/1558533900?i=1558534271 now",
+			want: "https://music.apple.com/us/album/whenever-you-need-somebody/1558533900?i=1558534271",
+		},
+		{
+			name: "album URL with song after other parameters",
+			text: "https://music.apple.com/gb/album/whenever-you-need-somebody/1558533900?ls=1&i=1558534271",
+			want: "https://music.apple.com/gb/album/whenever-you-need-somebody/1558533900?ls=1&i=1558534271",
+		},
+		{
+			name: "song URL",
+			text: "https://music.apple.com/de/song/never-gonna-give-you-up/1558534271",
+			want: "https://music.apple.com/de/song/never-gonna-give-you-up/1558534271",
+		},
+		{
+			name: "song URL without name",
+			text: "https://geo.music.apple.com/us/song/1558534271",
+			want: "https://geo.music.apple.com/us/song/1558534271",
+		},
+		{
+			name:    "album URL without song",
+			text:    "https://music.apple.com/us/album/whenever-you-need-somebody/1558533900",
+			wantErr: ErrNoURLFound,
+		},
+		{
+			name:    "artist URL",
+			text:    "https://music.apple.com/us/artist/rick-astley/669771",
+			wantErr: ErrNoURLFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, provider, err := AppleMusicURLExtractor(tt.text)
+
+			assert.Equal(t, AppleMusicProvider, provider)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, got)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestShazamURLExtractor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr error
+		name    string
+		text    string
+		want    string
+	}{
+		{
+			name: "track URL",
+			text: "Shazamed it https://www.shazam.com/track/5933917/never-gonna-give-you-up",
+			want: "https://www.shazam.com/track/5933917/never-gonna-give-you-up",
+		},
+		{
+			name: "locale prefixed song URL",
+			text: "https://www.shazam.com/en-us/song/5933917/never-gonna-give-you-up",
+			want: "https://www.shazam.com/en-us/song/5933917/never-gonna-give-you-up",
+		},
+		{
+			name: "track URL without name",
+			text: "https://shazam.com/track/5933917",
+			want: "https://shazam.com/track/5933917",
+		},
+		{
+			name:    "artist URL",
+			text:    "https://www.shazam.com/artist/rick-astley/5930432",
+			wantErr: ErrNoURLFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, provider, err := ShazamURLExtractor(tt.text)
+
+			assert.Equal(t, ShazamProvider, provider)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, got)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestVimeoURLExtractor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr error
+		name    string
+		text    string
+		want    string
+	}{
+		{
+			name: "video URL",
+			text: "Live session https://vimeo.com/76979871 enjoy",
+			want: "https://vimeo.com/76979871",
+		},
+		{
+			name: "unlisted video URL",
+			text: "https://vimeo.com/76979871/8272103f6e",
+			want: "https://vimeo.com/76979871/8272103f6e",
+		},
+		{
+			name: "channel video URL",
+			text: "https://vimeo.com/channels/staffpicks/76979871",
+			want: "https://vimeo.com/channels/staffpicks/76979871",
+		},
+		{
+			name: "player URL",
+			text: "https://player.vimeo.com/video/76979871",
+			want: "https://player.vimeo.com/video/76979871",
+		},
+		{
+			name:    "profile URL",
+			text:    "https://vimeo.com/someartist",
+			wantErr: ErrNoURLFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, provider, err := VimeoURLExtractor(tt.text)
+
+			assert.Equal(t, VimeoProvider, provider)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, got)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDailymotionURLExtractor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr error
+		name    string
+		text    string
+		want    string
+	}{
+		{
+			name: "video URL",
+			text: "Check https://www.dailymotion.com/video/x7tgad0 out",
+			want: "https://www.dailymotion.com/video/x7tgad0",
+		},
+		{
+			name: "short URL",
+			text: "https://dai.ly/x7tgad0",
+			want: "https://dai.ly/x7tgad0",
+		},
+		{
+			name:    "user URL",
+			text:    "https://www.dailymotion.com/someartist",
+			wantErr: ErrNoURLFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, provider, err := DailymotionURLExtractor(tt.text)
+
+			assert.Equal(t, DailymotionProvider, provider)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, got)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLastFMURLExtractor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr error
+		name    string
+		text    string
+		want    string
+	}{
+		{
+			name: "track page",
+			text: "Digging https://www.last.fm/music/Rick+Astley/_/Never+Gonna+Give+You+Up today",
+			want: "https://www.last.fm/music/Rick+Astley/_/Never+Gonna+Give+You+Up",
+		},
+		{
+			name: "language prefixed track page",
+			text: "https://www.last.fm/de/music/Bj%C3%B6rk/_/Army+of+Me",
+			want: "https://www.last.fm/de/music/Bj%C3%B6rk/_/Army+of+Me",
+		},
+		{
+			name:    "artist page",
+			text:    "https://www.last.fm/music/Rick+Astley",
+			wantErr: ErrNoURLFound,
+		},
+		{
+			name:    "album page",
+			text:    "https://www.last.fm/music/Rick+Astley/Whenever+You+Need+Somebody",
+			wantErr: ErrNoURLFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, provider, err := LastFMURLExtractor(tt.text)
+
+			assert.Equal(t, LastFMProvider, provider)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, got)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDiscogsURLExtractor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr error
+		name    string
+		text    string
+		want    string
+	}{
+		{
+			name: "release URL",
+			text: "Found it https://www.discogs.com/release/249504-Rick-Astley-Never-Gonna-Give-You-Up on vinyl",
+			want: "https://www.discogs.com/release/249504-Rick-Astley-Never-Gonna-Give-You-Up",
+		},
+		{
+			name: "legacy release URL",
+			text: "https://www.discogs.com/Rick-Astley-Never-Gonna-Give-You-Up/release/249504",
+			want: "https://www.discogs.com/Rick-Astley-Never-Gonna-Give-You-Up/release/249504",
+		},
+		{
+			name: "language prefixed release URL without name",
+			text: "https://www.discogs.com/de/release/249504",
+			want: "https://www.discogs.com/de/release/249504",
+		},
+		{
+			name:    "artist URL",
+			text:    "https://www.discogs.com/artist/72872-Rick-Astley",
+			wantErr: ErrNoURLFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, provider, err := DiscogsURLExtractor(tt.text)
+
+			assert.Equal(t, DiscogsProvider, provider)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, got)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+// builtInURLExtractors are the URL extractors of the built-in providers, the targets of FuzzURLExtractors.
+var builtInURLExtractors = []MusicURLExtractorFunc{
+	SpotifyURLExtractor,
+	YouTubeURLExtractor,
+	YouTubeMusicURLExtractor,
+	MixcloudURLExtractor,
+	AudiomackURLExtractor,
+	AmazonMusicURLExtractor,
+	AppleMusicURLExtractor,
+	ShazamURLExtractor,
+	VimeoURLExtractor,
+	DailymotionURLExtractor,
+	LastFMURLExtractor,
+	DiscogsURLExtractor,
+}
+
+func FuzzURLExtractors(f *testing.F) {
+	for _, seed := range []string{
+		"",
+		"https://open.spotify.com/intl-de/track/4cOdK2wGLETKBW3PvgPWqT?si=abc",
+		"https://youtube.com/shorts/dQw4w9WgXcQ and more",
+		"<https://music.youtube.com/watch?v=dQw4w9WgXcQ&amp;list=RD1|label>",
+		"https://www.mixcloud.com/spartacus/party-time/",
+		"https://audiomack.com/burna-boy/song/last-last",
+		"https://music.amazon.de/albums/B0?trackAsin=B1&x=y",
+		"https://music.apple.com/us/album/%E3%81%82/1?ls=1&i=2",
+		"https://www.shazam.com/en-us/track/1/a-b",
+		"https://vimeo.com/channels/a-b/1/abc",
+		"https://dai.ly/x7tgad0?start=1",
+		"https://www.last.fm/de/music/A+%C3%B6/_/B%2",
+		"https://www.discogs.com/de/A-B/release/1-%E3%81",
+		"https://open.spotify.com/track/a\x00b",
+		"https://youtu.be/" + strings.Repeat("a", maxURLLength),
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, text string) {
+		for _, extract := range builtInURLExtractors {
+			got, _, err := extract(UnwrapSlackLinks(text))
+			if err != nil {
+				continue
+			}
+
+			if len(got) > maxURLLength || strings.ContainsFunc(got, unicode.IsControl) || strings.ContainsFunc(got, unicode.IsSpace) {
+				t.Fatalf("extracted a pathological link %q from %q", got, text)
+			}
+
+			if _, pErr := url.Parse(got); pErr != nil {
+				t.Fatalf("extracted an unparsable link %q from %q: %v", got, text, pErr)
+			}
+		}
+	})
+}
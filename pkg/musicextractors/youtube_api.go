@@ -0,0 +1,178 @@
+package musicextractors
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const youTubeDataAPIURL = "https://www.googleapis.com/youtube/v3"
+
+var (
+	youTubeVideoIDRegex = regexp.MustCompile(`(?:youtube\.com/watch\?(?:[\w=&\-]*&)?v=|youtube\.com/(?:shorts|live)/|youtu\.be/)([\w\-]+)`)
+	isoDurationRegex    = regexp.MustCompile(`^P(?:(\d+)D)?T?(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?$`)
+)
+
+// YouTubeVideo contains the metadata of a single video returned by the YouTube Data API.
+type YouTubeVideo struct {
+	ID           string
+	Title        string
+	ChannelTitle string
+	ThumbnailURL string
+	Duration     time.Duration
+}
+
+// YouTubeDataAPIClient fetches video metadata from the YouTube Data API v3.
+type YouTubeDataAPIClient struct {
+	httpClient *http.Client
+	apiKey     string
+	apiURL     string
+}
+
+// youTubeVideoID returns the video ID of a youtube.com, youtu.be or music.youtube.com link.
+func youTubeVideoID(videoURL string) (string, error) {
+	matches := youTubeVideoIDRegex.FindStringSubmatch(videoURL)
+	if len(matches) < 2 {
+		return "", ErrNoTrackID
+	}
+
+	return matches[1], nil
+}
+
+// parseISODuration parses the ISO 8601 durations used by the YouTube Data API, like PT1H2M3S.
+func parseISODuration(raw string) time.Duration {
+	matches := isoDurationRegex.FindStringSubmatch(raw)
+	if matches == nil {
+		return 0
+	}
+
+	units := []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second}
+
+	var d time.Duration
+
+	for i, unit := range units {
+		if matches[i+1] == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(matches[i+1])
+		if err != nil {
+			return 0
+		}
+
+		d += time.Duration(n) * unit
+	}
+
+	return d
+}
+
+// Video fetches the title, channel name and duration of the video behind videoURL.
+func (c *YouTubeDataAPIClient) Video(ctx context.Context, videoURL string) (YouTubeVideo, error) {
+	videoID, err := youTubeVideoID(videoURL)
+	if err != nil {
+		return YouTubeVideo{}, err
+	}
+
+	query := url.Values{}
+	query.Set("part", "snippet,contentDetails")
+	query.Set("id", videoID)
+	query.Set("key", c.apiKey)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiURL+"/videos?"+query.Encode(), http.NoBody)
+	if err != nil {
+		return YouTubeVideo{}, ErrRequestFailed
+	}
+
+	resp, err := c.httpClient.Do(request)
+	if err != nil {
+		return YouTubeVideo{}, ErrRequestFailed
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return YouTubeVideo{}, statusError(resp.StatusCode)
+	}
+
+	var result struct {
+		Items []struct {
+			Snippet struct {
+				Title        string `json:"title"`
+				ChannelTitle string `json:"channelTitle"`
+				Thumbnails   map[string]struct {
+					URL string `json:"url"`
+				} `json:"thumbnails"`
+			} `json:"snippet"`
+			ContentDetails struct {
+				Duration string `json:"duration"`
+			} `json:"contentDetails"`
+		} `json:"items"`
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil || len(result.Items) == 0 {
+		return YouTubeVideo{}, ErrNoTitleFound
+	}
+
+	item := result.Items[0]
+	if item.Snippet.Title == "" {
+		return YouTubeVideo{}, ErrNoTitleFound
+	}
+
+	return YouTubeVideo{
+		ID:           videoID,
+		Title:        item.Snippet.Title,
+		ChannelTitle: item.Snippet.ChannelTitle,
+		ThumbnailURL: item.Snippet.Thumbnails["high"].URL,
+		Duration:     parseISODuration(item.ContentDetails.Duration),
+	}, nil
+}
+
+// MetadataExtractor returns a MetadataExtractorFunc backed by the Data API, a drop-in replacement for YouTubeMetadataExtractor.
+func (c *YouTubeDataAPIClient) MetadataExtractor() MetadataExtractorFunc {
+	return func(ctx context.Context, videoURL string) (TrackMetadata, error) {
+		v, err := c.Video(ctx, videoURL)
+		if err != nil {
+			return TrackMetadata{}, err
+		}
+
+		return TrackMetadata{
+			Title:           v.Title,
+			ArtworkURL:      v.ThumbnailURL,
+			ProviderID:      v.ID,
+			DurationSeconds: int(v.Duration.Seconds()),
+		}, nil
+	}
+}
+
+// TitleExtractor returns a TitleExtractorFunc backed by the Data API, a drop-in replacement for YouTubeTitleExtractor.
+func (c *YouTubeDataAPIClient) TitleExtractor() TitleExtractorFunc {
+	return func(videoURL string) (string, error) {
+		v, err := c.Video(context.TODO(), videoURL)
+		if err != nil {
+			return "", err
+		}
+
+		return v.Title, nil
+	}
+}
+
+// NewYouTubeDataAPIClient creates a new YouTube Data API v3 client authenticated with the given API key.
+func NewYouTubeDataAPIClient(apiKey string) *YouTubeDataAPIClient {
+	return &YouTubeDataAPIClient{
+		httpClient: http.DefaultClient,
+		apiKey:     apiKey,
+		apiURL:     youTubeDataAPIURL,
+	}
+}
+
+// YouTubeDataAPITitleExtractor creates a TitleExtractorFunc that resolves YouTube and YouTube Music titles
+// through the YouTube Data API v3, which is not affected by the region blocks of the oEmbed endpoint.
+func YouTubeDataAPITitleExtractor(apiKey string) TitleExtractorFunc {
+	return NewYouTubeDataAPIClient(apiKey).TitleExtractor()
+}
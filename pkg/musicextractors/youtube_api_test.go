@@ -0,0 +1,116 @@
+package musicextractors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseISODuration(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		raw  string
+		want time.Duration
+	}{
+		{name: "minutes and seconds", raw: "PT4M13S", want: 4*time.Minute + 13*time.Second},
+		{name: "hours", raw: "PT1H2M3S", want: time.Hour + 2*time.Minute + 3*time.Second},
+		{name: "days", raw: "P1DT1S", want: 24*time.Hour + time.Second},
+		{name: "seconds only", raw: "PT59S", want: 59 * time.Second},
+		{name: "live stream", raw: "P0D", want: 0},
+		{name: "garbage", raw: "4:13", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, parseISODuration(tt.raw))
+		})
+	}
+}
+
+func TestYouTubeDataAPIClient_Video(t *testing.T) {
+	t.Parallel()
+
+	rickRoll := YouTubeVideo{
+		ID:           "dQw4w9WgXcQ",
+		Title:        "Never Gonna Give You Up",
+		ChannelTitle: "Rick Astley",
+		ThumbnailURL: "https://i.ytimg.com/vi/dQw4w9WgXcQ/hqdefault.jpg",
+		Duration:     3*time.Minute + 33*time.Second,
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("key") != "key" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		if r.URL.Query().Get("id") != "dQw4w9WgXcQ" {
+			_, _ = w.Write([]byte(`{"items":[]}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"items":[{"snippet":{"title":"Never Gonna Give You Up","channelTitle":"Rick Astley",` +
+			`"thumbnails":{"high":{"url":"https://i.ytimg.com/vi/dQw4w9WgXcQ/hqdefault.jpg"}}},` +
+			`"contentDetails":{"duration":"PT3M33S"}}]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	tests := []struct {
+		wantErr error
+		name    string
+		url     string
+		want    YouTubeVideo
+	}{
+		{
+			name: "watch url",
+			url:  "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+			want: rickRoll,
+		},
+		{
+			name: "short url",
+			url:  "https://youtu.be/dQw4w9WgXcQ",
+			want: rickRoll,
+		},
+		{
+			name: "youtube music url with extra params",
+			url:  "https://music.youtube.com/watch?feature=share&v=dQw4w9WgXcQ",
+			want: rickRoll,
+		},
+		{
+			name:    "unknown video",
+			url:     "https://youtu.be/unknown",
+			wantErr: ErrNoTitleFound,
+		},
+		{
+			name:    "no video id",
+			url:     "https://www.youtube.com/playlist?list=PL123",
+			wantErr: ErrNoTrackID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := &YouTubeDataAPIClient{httpClient: srv.Client(), apiKey: "key", apiURL: srv.URL}
+
+			got, err := c.Video(t.Context(), tt.url)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
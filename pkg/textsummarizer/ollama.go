@@ -0,0 +1,86 @@
+package textsummarizer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+const (
+	ollamaAPIURL = "http://localhost:11434"
+	// ollamaDefaultModel is a small model that runs on modest hardware.
+	ollamaDefaultModel = "llama3.2"
+)
+
+// OllamaSummarizer is a TextSummarizer backed by the chat API of a self-hosted Ollama server.
+type OllamaSummarizer struct {
+	httpClient *http.Client
+	apiURL     string
+	model      string
+}
+
+var _ TextSummarizer = (*OllamaSummarizer)(nil)
+
+// Summarize asks the model for a recap of the conversation, waiting for the whole answer instead of streaming it.
+func (o *OllamaSummarizer) Summarize(ctx context.Context, conversation string) (string, error) {
+	body, err := json.Marshal(struct {
+		Model    string        `json:"model"`
+		Messages []chatMessage `json:"messages"`
+		Stream   bool          `json:"stream"`
+	}{Model: o.model, Messages: chatMessages(conversation)})
+	if err != nil {
+		return "", ErrRequestFailed
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, o.apiURL+"/api/chat", bytes.NewReader(body))
+	if err != nil {
+		return "", ErrRequestFailed
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := o.httpClient.Do(request)
+	if err != nil {
+		return "", ErrRequestFailed
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", statusError(resp.StatusCode)
+	}
+
+	var result struct {
+		Message chatMessage `json:"message"`
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", ErrRequestFailed
+	}
+
+	return recap(result.Message.Content)
+}
+
+// NewOllamaSummarizer creates a TextSummarizer backed by an Ollama server.
+//
+// apiURL is optional, a local server on the default port is used without it.
+// model is optional, llama3.2 is used without it, it has to be pulled on the server.
+func NewOllamaSummarizer(apiURL, model string) *OllamaSummarizer {
+	if apiURL == "" {
+		apiURL = ollamaAPIURL
+	}
+
+	if model == "" {
+		model = ollamaDefaultModel
+	}
+
+	return &OllamaSummarizer{
+		httpClient: http.DefaultClient,
+		apiURL:     strings.TrimSuffix(apiURL, "/"),
+		model:      model,
+	}
+}
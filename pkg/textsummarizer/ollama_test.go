@@ -0,0 +1,50 @@
+package textsummarizer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOllamaSummarizer_Summarize(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		var body struct {
+			Model    string        `json:"model"`
+			Messages []chatMessage `json:"messages"`
+			Stream   *bool         `json:"stream"`
+		}
+
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "mistral", body.Model)
+		assert.Equal(t, chatMessages("U1: hi"), body.Messages)
+		// Ollama streams by default, the whole answer has to be asked for
+		if assert.NotNil(t, body.Stream) {
+			assert.False(t, *body.Stream)
+		}
+
+		_, _ = w.Write([]byte(`{"message":{"role":"assistant","content":"A quiet thread."},"done":true}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	o := NewOllamaSummarizer(srv.URL, "mistral")
+	o.httpClient = srv.Client()
+
+	got, err := o.Summarize(t.Context(), "U1: hi")
+	require.NoError(t, err)
+	assert.Equal(t, "A quiet thread.", got)
+
+	_, err = (&OllamaSummarizer{httpClient: srv.Client(), apiURL: srv.URL + "/missing", model: "mistral"}).Summarize(t.Context(), "U1: hi")
+	require.ErrorIs(t, err, ErrRequestFailed)
+}
@@ -0,0 +1,98 @@
+package textsummarizer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+const (
+	openAIAPIURL = "https://api.openai.com/v1"
+	// openAIDefaultModel is a small, cheap model, a recap doesn't need more.
+	openAIDefaultModel = "gpt-4o-mini"
+)
+
+// OpenAISummarizer is a TextSummarizer backed by the chat completions API of OpenAI,
+// or of any service compatible with it.
+type OpenAISummarizer struct {
+	httpClient *http.Client
+	apiKey     string
+	apiURL     string
+	model      string
+}
+
+var _ TextSummarizer = (*OpenAISummarizer)(nil)
+
+// Summarize asks the model for a recap of the conversation.
+func (o *OpenAISummarizer) Summarize(ctx context.Context, conversation string) (string, error) {
+	body, err := json.Marshal(struct {
+		Model    string        `json:"model"`
+		Messages []chatMessage `json:"messages"`
+	}{Model: o.model, Messages: chatMessages(conversation)})
+	if err != nil {
+		return "", ErrRequestFailed
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, o.apiURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", ErrRequestFailed
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	if o.apiKey != "" {
+		request.Header.Set("Authorization", "Bearer "+o.apiKey)
+	}
+
+	resp, err := o.httpClient.Do(request)
+	if err != nil {
+		return "", ErrRequestFailed
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", statusError(resp.StatusCode)
+	}
+
+	var result struct {
+		Choices []struct {
+			Message chatMessage `json:"message"`
+		} `json:"choices"`
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", ErrRequestFailed
+	}
+
+	if len(result.Choices) == 0 {
+		return "", ErrEmptySummary
+	}
+
+	return recap(result.Choices[0].Message.Content)
+}
+
+// NewOpenAISummarizer creates a TextSummarizer backed by the OpenAI chat completions API.
+//
+// apiURL is optional, it points the summarizer to a compatible API instead, like a self-hosted gateway.
+// model is optional, gpt-4o-mini is used without it.
+func NewOpenAISummarizer(apiKey, apiURL, model string) *OpenAISummarizer {
+	if apiURL == "" {
+		apiURL = openAIAPIURL
+	}
+
+	if model == "" {
+		model = openAIDefaultModel
+	}
+
+	return &OpenAISummarizer{
+		httpClient: http.DefaultClient,
+		apiKey:     apiKey,
+		apiURL:     strings.TrimSuffix(apiURL, "/"),
+		model:      model,
+	}
+}
@@ -0,0 +1,73 @@
+package textsummarizer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAISummarizer_Summarize(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+
+		var body struct {
+			Model    string        `json:"model"`
+			Messages []chatMessage `json:"messages"`
+		}
+
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "gpt-4o-mini", body.Model)
+		assert.Equal(t, chatMessages("U1: https://open.spotify.com/track/abc what a tune"), body.Messages)
+
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"  Everyone loved the tune.\n"}}]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	o := NewOpenAISummarizer("key", srv.URL+"/v1/", "")
+	o.httpClient = srv.Client()
+
+	got, err := o.Summarize(t.Context(), "U1: https://open.spotify.com/track/abc what a tune")
+	require.NoError(t, err)
+	assert.Equal(t, "Everyone loved the tune.", got)
+}
+
+func TestOpenAISummarizer_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr error
+	}{
+		{name: "rate limited", status: http.StatusTooManyRequests, wantErr: ErrRateLimited},
+		{name: "server error", status: http.StatusInternalServerError, wantErr: ErrRequestFailed},
+		{name: "invalid json", status: http.StatusOK, body: "{", wantErr: ErrRequestFailed},
+		{name: "no choices", status: http.StatusOK, body: `{"choices":[]}`, wantErr: ErrEmptySummary},
+		{name: "blank answer", status: http.StatusOK, body: `{"choices":[{"message":{"content":" "}}]}`, wantErr: ErrEmptySummary},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			t.Cleanup(srv.Close)
+
+			o := &OpenAISummarizer{httpClient: srv.Client(), apiURL: srv.URL, model: "test"}
+
+			_, err := o.Summarize(t.Context(), "U1: hi")
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
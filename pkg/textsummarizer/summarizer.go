@@ -0,0 +1,65 @@
+// Package textsummarizer writes short prose recaps of conversations with large language models.
+package textsummarizer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// systemPrompt tells the model what the recap is about and how long it can be.
+const systemPrompt = "You summarize Slack threads where people share and discuss music. " +
+	"Write a recap of the discussion in two to four plain sentences: the moods, genres and artists that came up, " +
+	"the tracks people reacted to and any plans they made. Don't list every link, don't use Markdown, " +
+	"and answer in the language of the thread."
+
+var (
+	// ErrRequestFailed returned by TextSummarizer if the model couldn't be reached or answered with an error.
+	ErrRequestFailed = errors.New("failed to request summary")
+	// ErrRateLimited returned by TextSummarizer if the API rejected the request with 429 Too Many Requests,
+	// it wraps ErrRequestFailed.
+	ErrRateLimited = fmt.Errorf("%w: rate limited", ErrRequestFailed)
+	// ErrEmptySummary returned by TextSummarizer if the model answered without any text.
+	ErrEmptySummary = errors.New("model returned an empty summary")
+)
+
+// TextSummarizer writes a short prose recap of a conversation.
+type TextSummarizer interface {
+	// Summarize returns a few sentences recapping the conversation, one message per line like "author: text".
+	Summarize(ctx context.Context, conversation string) (string, error)
+}
+
+// chatMessage is a message of the chat APIs of OpenAI and Ollama, which share the format.
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// chatMessages returns the system prompt and the conversation as the messages of a chat request.
+func chatMessages(conversation string) []chatMessage {
+	return []chatMessage{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: conversation},
+	}
+}
+
+// statusError returns the error of an unexpected HTTP status, ErrRateLimited or ErrRequestFailed.
+func statusError(status int) error {
+	if status == http.StatusTooManyRequests {
+		return ErrRateLimited
+	}
+
+	return fmt.Errorf("%w: status %d", ErrRequestFailed, status)
+}
+
+// recap trims the answer of a model, ErrEmptySummary if there's nothing left.
+func recap(answer string) (string, error) {
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return "", ErrEmptySummary
+	}
+
+	return answer, nil
+}